@@ -0,0 +1,364 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package v2 provides bindings to the Alertmanager v2 HTTP API.
+//
+// The API uses an api.Client for the HTTP transport, so that authentication,
+// TLS, retries, and per-request headers are configured the same way as for
+// the Prometheus API in package api/prometheus/v1.
+package v2
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/prometheus/client_golang/api"
+)
+
+const (
+	apiPrefix = "/api/v2"
+
+	epAlerts   = apiPrefix + "/alerts"
+	epSilences = apiPrefix + "/silences"
+	epSilence  = apiPrefix + "/silence/:id"
+	epStatus   = apiPrefix + "/status"
+)
+
+// API provides bindings for the Alertmanager v2 API.
+type API interface {
+	// Alerts returns the alerts matching the filter.
+	Alerts(ctx context.Context, filter AlertsFilter) ([]GettableAlert, error)
+	// PostAlerts sends alerts to the Alertmanager.
+	PostAlerts(ctx context.Context, alerts ...Alert) error
+	// Silences returns the silences matching all of the provided matchers,
+	// e.g. `alertname="Foo"`, or all silences if there are none.
+	Silences(ctx context.Context, matchers ...string) ([]GettableSilence, error)
+	// Silence returns the silence with the provided ID.
+	Silence(ctx context.Context, id string) (GettableSilence, error)
+	// CreateSilence creates a silence and returns its ID. If the ID of the
+	// silence is set, the existing silence with that ID is updated instead.
+	CreateSilence(ctx context.Context, silence Silence) (string, error)
+	// DeleteSilence expires the silence with the provided ID.
+	DeleteSilence(ctx context.Context, id string) error
+	// Status returns the status of the Alertmanager and its cluster.
+	Status(ctx context.Context) (Status, error)
+}
+
+// Error is an error response of the API.
+type Error struct {
+	StatusCode int
+	Msg        string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("alertmanager: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Msg)
+}
+
+// Alert is an alert as sent to the Alertmanager.
+type Alert struct {
+	Labels       model.LabelSet `json:"labels"`
+	Annotations  model.LabelSet `json:"annotations,omitempty"`
+	StartsAt     time.Time      `json:"startsAt,omitzero"`
+	EndsAt       time.Time      `json:"endsAt,omitzero"`
+	GeneratorURL string         `json:"generatorURL,omitempty"`
+}
+
+// AlertState is the state of an alert in the Alertmanager.
+type AlertState string
+
+// Possible values for AlertState.
+const (
+	AlertStateUnprocessed AlertState = "unprocessed"
+	AlertStateActive      AlertState = "active"
+	AlertStateSuppressed  AlertState = "suppressed"
+)
+
+// AlertStatus is the status of an alert in the Alertmanager.
+type AlertStatus struct {
+	State       AlertState `json:"state"`
+	SilencedBy  []string   `json:"silencedBy"`
+	InhibitedBy []string   `json:"inhibitedBy"`
+	MutedBy     []string   `json:"mutedBy,omitempty"`
+}
+
+// Receiver is a receiver of alerts.
+type Receiver struct {
+	Name string `json:"name"`
+}
+
+// GettableAlert is an alert as returned by the Alertmanager.
+type GettableAlert struct {
+	Alert
+	Fingerprint string      `json:"fingerprint"`
+	Receivers   []Receiver  `json:"receivers"`
+	Status      AlertStatus `json:"status"`
+	UpdatedAt   time.Time   `json:"updatedAt"`
+}
+
+// AlertsFilter selects the alerts returned by API.Alerts. The zero value
+// selects all alerts.
+type AlertsFilter struct {
+	// Matchers are label matchers the alerts must match, e.g.
+	// `severity=~"warning|critical"`.
+	Matchers []string
+	// Receiver is a regular expression the receiver of the alerts must
+	// match.
+	Receiver string
+
+	ExcludeActive      bool
+	ExcludeSilenced    bool
+	ExcludeInhibited   bool
+	ExcludeUnprocessed bool
+}
+
+func (f AlertsFilter) values() url.Values {
+	q := url.Values{}
+	for _, m := range f.Matchers {
+		q.Add("filter", m)
+	}
+	if f.Receiver != "" {
+		q.Set("receiver", f.Receiver)
+	}
+	for name, exclude := range map[string]bool{
+		"active":      f.ExcludeActive,
+		"silenced":    f.ExcludeSilenced,
+		"inhibited":   f.ExcludeInhibited,
+		"unprocessed": f.ExcludeUnprocessed,
+	} {
+		if exclude {
+			q.Set(name, "false")
+		}
+	}
+	return q
+}
+
+// MatchType is the type of a Matcher.
+type MatchType string
+
+// Possible values for MatchType.
+const (
+	MatchEqual     MatchType = "="
+	MatchNotEqual  MatchType = "!="
+	MatchRegexp    MatchType = "=~"
+	MatchNotRegexp MatchType = "!~"
+)
+
+// Matcher selects the alerts a silence applies to. An empty Type is treated
+// as MatchEqual.
+type Matcher struct {
+	Type  MatchType
+	Name  string
+	Value string
+}
+
+type jsonMatcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+	IsEqual *bool  `json:"isEqual,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (m Matcher) MarshalJSON() ([]byte, error) {
+	isEqual := m.Type != MatchNotEqual && m.Type != MatchNotRegexp
+	return json.Marshal(jsonMatcher{
+		Name:    m.Name,
+		Value:   m.Value,
+		IsRegex: m.Type == MatchRegexp || m.Type == MatchNotRegexp,
+		IsEqual: &isEqual,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (m *Matcher) UnmarshalJSON(b []byte) error {
+	var jm jsonMatcher
+	if err := json.Unmarshal(b, &jm); err != nil {
+		return err
+	}
+	// Alertmanager versions before 0.22 don't support isEqual.
+	isEqual := jm.IsEqual == nil || *jm.IsEqual
+	switch {
+	case jm.IsRegex && isEqual:
+		m.Type = MatchRegexp
+	case jm.IsRegex:
+		m.Type = MatchNotRegexp
+	case isEqual:
+		m.Type = MatchEqual
+	default:
+		m.Type = MatchNotEqual
+	}
+	m.Name, m.Value = jm.Name, jm.Value
+	return nil
+}
+
+// Silence is a silence as sent to the Alertmanager.
+type Silence struct {
+	// ID is only set to update an existing silence.
+	ID        string    `json:"id,omitempty"`
+	Matchers  []Matcher `json:"matchers"`
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	CreatedBy string    `json:"createdBy"`
+	Comment   string    `json:"comment"`
+}
+
+// SilenceState is the state of a silence.
+type SilenceState string
+
+// Possible values for SilenceState.
+const (
+	SilenceStateExpired SilenceState = "expired"
+	SilenceStateActive  SilenceState = "active"
+	SilenceStatePending SilenceState = "pending"
+)
+
+// GettableSilence is a silence as returned by the Alertmanager.
+type GettableSilence struct {
+	Silence
+	Status struct {
+		State SilenceState `json:"state"`
+	} `json:"status"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Status is the status of an Alertmanager.
+type Status struct {
+	Cluster     ClusterStatus     `json:"cluster"`
+	VersionInfo map[string]string `json:"versionInfo"`
+	Config      struct {
+		Original string `json:"original"`
+	} `json:"config"`
+	Uptime time.Time `json:"uptime"`
+}
+
+// ClusterStatus is the status of an Alertmanager cluster.
+type ClusterStatus struct {
+	Name   string       `json:"name"`
+	Status string       `json:"status"`
+	Peers  []PeerStatus `json:"peers"`
+}
+
+// PeerStatus is the status of a peer in an Alertmanager cluster.
+type PeerStatus struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+// NewAPI returns a new API for the client.
+//
+// It is safe to use the returned API from multiple goroutines.
+func NewAPI(c api.Client) API {
+	return &httpAPI{client: c}
+}
+
+type httpAPI struct {
+	client api.Client
+}
+
+func (h *httpAPI) Alerts(ctx context.Context, filter AlertsFilter) ([]GettableAlert, error) {
+	u := h.client.URL(epAlerts, nil)
+	u.RawQuery = filter.values().Encode()
+	var res []GettableAlert
+	return res, h.do(ctx, http.MethodGet, u, nil, &res)
+}
+
+func (h *httpAPI) PostAlerts(ctx context.Context, alerts ...Alert) error {
+	if alerts == nil {
+		alerts = []Alert{}
+	}
+	return h.do(ctx, http.MethodPost, h.client.URL(epAlerts, nil), alerts, nil)
+}
+
+func (h *httpAPI) Silences(ctx context.Context, matchers ...string) ([]GettableSilence, error) {
+	u := h.client.URL(epSilences, nil)
+	q := u.Query()
+	for _, m := range matchers {
+		q.Add("filter", m)
+	}
+	u.RawQuery = q.Encode()
+	var res []GettableSilence
+	return res, h.do(ctx, http.MethodGet, u, nil, &res)
+}
+
+func (h *httpAPI) Silence(ctx context.Context, id string) (GettableSilence, error) {
+	var res GettableSilence
+	return res, h.do(ctx, http.MethodGet, h.client.URL(epSilence, map[string]string{"id": url.PathEscape(id)}), nil, &res)
+}
+
+func (h *httpAPI) CreateSilence(ctx context.Context, silence Silence) (string, error) {
+	var res struct {
+		SilenceID string `json:"silenceID"`
+	}
+	err := h.do(ctx, http.MethodPost, h.client.URL(epSilences, nil), silence, &res)
+	return res.SilenceID, err
+}
+
+func (h *httpAPI) DeleteSilence(ctx context.Context, id string) error {
+	return h.do(ctx, http.MethodDelete, h.client.URL(epSilence, map[string]string{"id": url.PathEscape(id)}), nil, nil)
+}
+
+func (h *httpAPI) Status(ctx context.Context) (Status, error) {
+	var res Status
+	return res, h.do(ctx, http.MethodGet, h.client.URL(epStatus, nil), nil, &res)
+}
+
+// do sends a request with in encoded as JSON body, if not nil, and decodes
+// the JSON response into out, if not nil.
+func (h *httpAPI) do(ctx context.Context, method string, u *url.URL, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, respBody, err := h.client.Do(ctx, req)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		return &Error{StatusCode: resp.StatusCode, Msg: errorMessage(respBody)}
+	}
+	if out == nil || len(respBody) == 0 {
+		return nil
+	}
+	return json.Unmarshal(respBody, out)
+}
+
+// errorMessage returns the message of an error response, which the
+// Alertmanager usually sends as JSON string.
+func errorMessage(body []byte) string {
+	var msg string
+	if err := json.Unmarshal(body, &msg); err == nil {
+		return msg
+	}
+	return strings.TrimSpace(string(body))
+}
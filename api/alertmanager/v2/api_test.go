@@ -0,0 +1,191 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/prometheus/client_golang/api"
+)
+
+type request struct {
+	method, path, query, body string
+}
+
+func newTestAPI(t *testing.T, responses map[string]string) (API, *[]request) {
+	t.Helper()
+	var requests []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, request{r.Method, r.URL.Path, r.URL.RawQuery, string(body)})
+		resp, ok := responses[r.Method+" "+r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`"silence not found"`))
+			return
+		}
+		w.Write([]byte(resp))
+	}))
+	t.Cleanup(server.Close)
+	client, err := api.NewClient(api.Config{Address: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewAPI(client), &requests
+}
+
+func TestAlerts(t *testing.T) {
+	amAPI, requests := newTestAPI(t, map[string]string{
+		"GET /api/v2/alerts": `[{
+			"labels": {"alertname": "Foo", "severity": "critical"},
+			"annotations": {"summary": "foo"},
+			"startsAt": "2026-01-02T03:04:05Z",
+			"endsAt": "2026-01-02T04:04:05Z",
+			"generatorURL": "http://prometheus/graph",
+			"fingerprint": "abc",
+			"receivers": [{"name": "team-a"}],
+			"status": {"state": "suppressed", "silencedBy": ["s1"], "inhibitedBy": []},
+			"updatedAt": "2026-01-02T03:04:06Z"
+		}]`,
+		"POST /api/v2/alerts": ``,
+	})
+
+	alerts, err := amAPI.Alerts(context.Background(), AlertsFilter{Matchers: []string{`severity="critical"`}, ExcludeInhibited: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	startsAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	want := []GettableAlert{{
+		Alert: Alert{
+			Labels:       model.LabelSet{"alertname": "Foo", "severity": "critical"},
+			Annotations:  model.LabelSet{"summary": "foo"},
+			StartsAt:     startsAt,
+			EndsAt:       startsAt.Add(time.Hour),
+			GeneratorURL: "http://prometheus/graph",
+		},
+		Fingerprint: "abc",
+		Receivers:   []Receiver{{Name: "team-a"}},
+		Status:      AlertStatus{State: AlertStateSuppressed, SilencedBy: []string{"s1"}, InhibitedBy: []string{}},
+		UpdatedAt:   startsAt.Add(time.Second),
+	}}
+	if !reflect.DeepEqual(alerts, want) {
+		t.Errorf("got %+v, want %+v", alerts, want)
+	}
+	if got, want := (*requests)[0].query, "filter=severity%3D%22critical%22&inhibited=false"; got != want {
+		t.Errorf("got query %s, want %s", got, want)
+	}
+
+	err = amAPI.PostAlerts(context.Background(), Alert{Labels: model.LabelSet{"alertname": "Bar"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := (*requests)[1].body, `[{"labels":{"alertname":"Bar"}}]`; got != want {
+		t.Errorf("got body %s, want %s", got, want)
+	}
+}
+
+func TestSilences(t *testing.T) {
+	amAPI, requests := newTestAPI(t, map[string]string{
+		"GET /api/v2/silences": `[{
+			"id": "s1",
+			"matchers": [{"name": "job", "value": "a.*", "isRegex": true, "isEqual": false}, {"name": "env", "value": "dev", "isRegex": false}],
+			"startsAt": "2026-01-02T03:04:05Z",
+			"endsAt": "2026-01-02T04:04:05Z",
+			"createdBy": "me",
+			"comment": "maintenance",
+			"status": {"state": "active"},
+			"updatedAt": "2026-01-02T03:04:05Z"
+		}]`,
+		"POST /api/v2/silences":     `{"silenceID": "s2"}`,
+		"DELETE /api/v2/silence/s2": ``,
+	})
+
+	silences, err := amAPI.Silences(context.Background(), `env="dev"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(silences) != 1 || silences[0].ID != "s1" || silences[0].Status.State != SilenceStateActive {
+		t.Fatalf("got unexpected silences %+v", silences)
+	}
+	wantMatchers := []Matcher{{Type: MatchNotRegexp, Name: "job", Value: "a.*"}, {Type: MatchEqual, Name: "env", Value: "dev"}}
+	if !reflect.DeepEqual(silences[0].Matchers, wantMatchers) {
+		t.Errorf("got matchers %+v, want %+v", silences[0].Matchers, wantMatchers)
+	}
+
+	id, err := amAPI.CreateSilence(context.Background(), Silence{
+		Matchers:  []Matcher{{Name: "job", Value: "a"}, {Type: MatchNotEqual, Name: "env", Value: "prod"}},
+		StartsAt:  silences[0].StartsAt,
+		EndsAt:    silences[0].EndsAt,
+		CreatedBy: "me",
+		Comment:   "maintenance",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "s2" {
+		t.Errorf("got silence ID %s, want s2", id)
+	}
+	var posted map[string]any
+	if err := json.Unmarshal([]byte((*requests)[1].body), &posted); err != nil {
+		t.Fatal(err)
+	}
+	wantPosted := []any{
+		map[string]any{"name": "job", "value": "a", "isRegex": false, "isEqual": true},
+		map[string]any{"name": "env", "value": "prod", "isRegex": false, "isEqual": false},
+	}
+	if !reflect.DeepEqual(posted["matchers"], wantPosted) {
+		t.Errorf("got posted matchers %v, want %v", posted["matchers"], wantPosted)
+	}
+
+	if err := amAPI.DeleteSilence(context.Background(), "s2"); err != nil {
+		t.Fatal(err)
+	}
+	if got := (*requests)[2]; got.method != http.MethodDelete || got.path != "/api/v2/silence/s2" {
+		t.Errorf("got request %+v", got)
+	}
+
+	_, err = amAPI.Silence(context.Background(), "unknown")
+	var amErr *Error
+	if !errors.As(err, &amErr) || amErr.StatusCode != http.StatusNotFound || amErr.Msg != "silence not found" {
+		t.Errorf("got error %v, want not found error", err)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	amAPI, _ := newTestAPI(t, map[string]string{
+		"GET /api/v2/status": `{
+			"cluster": {"name": "c1", "status": "ready", "peers": [{"name": "p1", "address": "10.0.0.1:9094"}]},
+			"versionInfo": {"version": "0.28.0"},
+			"config": {"original": "route: {}"},
+			"uptime": "2026-01-02T03:04:05Z"
+		}`,
+	})
+	status, err := amAPI.Status(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Cluster.Status != "ready" || len(status.Cluster.Peers) != 1 || status.VersionInfo["version"] != "0.28.0" || status.Config.Original != "route: {}" {
+		t.Errorf("got unexpected status %+v", status)
+	}
+}
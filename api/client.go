@@ -24,6 +24,10 @@ import (
 	"path"
 	"strings"
 	"time"
+
+	"github.com/prometheus/common/config"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // DefaultRoundTripper is used if no RoundTripper is set in Config.
@@ -48,13 +52,89 @@ type Config struct {
 	// RoundTripper is used by the Client to drive HTTP requests. If not
 	// provided, DefaultRoundTripper will be used.
 	RoundTripper http.RoundTripper
+
+	// RetryPolicy, if not nil, makes the Client retry failed idempotent
+	// requests, see RetryPolicy for details.
+	RetryPolicy *RetryPolicy
+
+	// Registerer, if not nil, is used to register metrics about the
+	// requests of the Client, partitioned by endpoint. Clients using the
+	// same Registerer share their metrics.
+	Registerer prometheus.Registerer
+
+	// The following fields tune the transport created for the Client. They
+	// can't be combined with Client or RoundTripper. Zero values keep the
+	// settings of DefaultRoundTripper.
+	//
+	// Clients sending many concurrent requests to the same server should
+	// raise MaxIdleConnsPerHost, which defaults to 2, so that connections
+	// are reused rather than closed after each request, which can exhaust
+	// the ephemeral ports of the host.
+
+	// EnableHTTP2 makes the transport attempt HTTP/2 connections.
+	EnableHTTP2 bool
+	// MaxIdleConns limits the number of idle connections to all hosts.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost limits the number of idle connections per host.
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost limits the number of connections per host, including
+	// connections in use. Requests exceeding the limit wait for a
+	// connection.
+	MaxConnsPerHost int
+	// IdleConnTimeout is the time after which idle connections are closed.
+	IdleConnTimeout time.Duration
+	// ProxyConfig configures the proxy to use, like the proxy settings of
+	// config.HTTPClientConfig. If nil, the proxy is taken from the
+	// environment.
+	ProxyConfig *config.ProxyConfig
 }
 
 func (cfg *Config) roundTripper() http.RoundTripper {
-	if cfg.RoundTripper == nil {
+	if cfg.RoundTripper != nil {
+		return cfg.RoundTripper
+	}
+	if !cfg.tuneTransport() {
 		return DefaultRoundTripper
 	}
-	return cfg.RoundTripper
+	var t *http.Transport
+	if dt, ok := DefaultRoundTripper.(*http.Transport); ok {
+		t = dt.Clone()
+	} else {
+		t = &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+		}
+	}
+	if cfg.EnableHTTP2 {
+		t.ForceAttemptHTTP2 = true
+	}
+	if cfg.MaxIdleConns > 0 {
+		t.MaxIdleConns = cfg.MaxIdleConns
+	}
+	if cfg.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	}
+	if cfg.MaxConnsPerHost > 0 {
+		t.MaxConnsPerHost = cfg.MaxConnsPerHost
+	}
+	if cfg.IdleConnTimeout > 0 {
+		t.IdleConnTimeout = cfg.IdleConnTimeout
+	}
+	if cfg.ProxyConfig != nil {
+		t.Proxy = cfg.ProxyConfig.Proxy()
+		t.ProxyConnectHeader = cfg.ProxyConfig.GetProxyConnectHeader()
+	}
+	return t
+}
+
+// tuneTransport reports whether any of the transport settings is set.
+func (cfg *Config) tuneTransport() bool {
+	return cfg.EnableHTTP2 || cfg.MaxIdleConns > 0 || cfg.MaxIdleConnsPerHost > 0 ||
+		cfg.MaxConnsPerHost > 0 || cfg.IdleConnTimeout > 0 || cfg.ProxyConfig != nil
 }
 
 func (cfg *Config) client() http.Client {
@@ -70,6 +150,12 @@ func (cfg *Config) validate() error {
 	if cfg.Client != nil && cfg.RoundTripper != nil {
 		return errors.New("api.Config.RoundTripper and api.Config.Client are mutually exclusive")
 	}
+	if cfg.tuneTransport() && (cfg.Client != nil || cfg.RoundTripper != nil) {
+		return errors.New("api.Config transport settings can't be combined with api.Config.RoundTripper or api.Config.Client")
+	}
+	if cfg.ProxyConfig != nil {
+		return cfg.ProxyConfig.Validate()
+	}
 	return nil
 }
 
@@ -79,13 +165,25 @@ type Client interface {
 	Do(context.Context, *http.Request) (*http.Response, []byte, error)
 }
 
+// StreamingClient is implemented by Clients that can return responses without
+// buffering their bodies, which allows to decode large responses incrementally.
+// The Client returned by NewClient implements StreamingClient.
+type StreamingClient interface {
+	Client
+	// DoStream sends the request and returns the response as is. The
+	// caller is responsible for closing the response body.
+	DoStream(context.Context, *http.Request) (*http.Response, error)
+}
+
 type CloseIdler interface {
 	CloseIdleConnections()
 }
 
 // NewClient returns a new Client.
 //
-// It is safe to use the returned Client from multiple goroutines.
+// It is safe to use the returned Client from multiple goroutines. Headers added
+// to the context of a request with WithHeader or WithTenant are sent along
+// with the request.
 func NewClient(cfg Config) (Client, error) {
 	u, err := url.Parse(cfg.Address)
 	if err != nil {
@@ -97,15 +195,26 @@ func NewClient(cfg Config) (Client, error) {
 		return nil, err
 	}
 
-	return &httpClient{
+	c := &httpClient{
 		endpoint: u,
 		client:   cfg.client(),
-	}, nil
+	}
+	if cfg.RetryPolicy != nil {
+		c.retry = cfg.RetryPolicy.withDefaults()
+	}
+	if cfg.Registerer != nil {
+		if c.metrics, err = newClientMetrics(cfg.Registerer); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
 }
 
 type httpClient struct {
 	endpoint *url.URL
 	client   http.Client
+	retry    *RetryPolicy
+	metrics  *clientMetrics
 }
 
 func (c *httpClient) URL(ep string, args map[string]string) *url.URL {
@@ -126,11 +235,34 @@ func (c *httpClient) CloseIdleConnections() {
 	c.client.CloseIdleConnections()
 }
 
+func (c *httpClient) DoStream(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if ctx != nil {
+		req = req.WithContext(ctx)
+	}
+	start := time.Now()
+	resp, err := c.retry.do(&c.client, setContextHeader(req))
+	c.metrics.observeRequest(c.endpointLabel(req), resp, -1, time.Since(start))
+	return resp, err
+}
+
+// ObserveDecode implements DecodeObserver.
+func (c *httpClient) ObserveDecode(req *http.Request, d time.Duration) {
+	c.metrics.observeDecode(c.endpointLabel(req), d)
+}
+
+func (c *httpClient) endpointLabel(req *http.Request) string {
+	if c.metrics == nil {
+		return ""
+	}
+	return endpoint(c.endpoint.Path, req.URL.Path)
+}
+
 func (c *httpClient) Do(ctx context.Context, req *http.Request) (*http.Response, []byte, error) {
 	if ctx != nil {
 		req = req.WithContext(ctx)
 	}
-	resp, err := c.client.Do(req)
+	start := time.Now()
+	resp, err := c.retry.do(&c.client, setContextHeader(req))
 	defer func() {
 		if resp != nil {
 			resp.Body.Close()
@@ -138,6 +270,7 @@ func (c *httpClient) Do(ctx context.Context, req *http.Request) (*http.Response,
 	}()
 
 	if err != nil {
+		c.metrics.observeRequest(c.endpointLabel(req), nil, -1, time.Since(start))
 		return nil, nil, err
 	}
 
@@ -159,6 +292,7 @@ func (c *httpClient) Do(ctx context.Context, req *http.Request) (*http.Response,
 		}
 	case <-done:
 	}
+	c.metrics.observeRequest(c.endpointLabel(req), resp, len(body), time.Since(start))
 
 	return resp, body, err
 }
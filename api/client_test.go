@@ -21,6 +21,9 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
+
+	"github.com/prometheus/common/config"
 )
 
 func TestConfig(t *testing.T) {
@@ -30,6 +33,39 @@ func TestConfig(t *testing.T) {
 	}
 }
 
+func TestConfigTransport(t *testing.T) {
+	proxyURL, _ := url.Parse("http://proxy:3128")
+	c := Config{
+		EnableHTTP2:         true,
+		MaxIdleConnsPerHost: 100,
+		MaxConnsPerHost:     200,
+		IdleConnTimeout:     time.Minute,
+		ProxyConfig:         &config.ProxyConfig{ProxyURL: config.URL{URL: proxyURL}},
+	}
+	if err := c.validate(); err != nil {
+		t.Fatal(err)
+	}
+	tr, ok := c.roundTripper().(*http.Transport)
+	if !ok || tr == DefaultRoundTripper {
+		t.Fatalf("expected new transport, got %v", c.roundTripper())
+	}
+	if !tr.ForceAttemptHTTP2 || tr.MaxIdleConnsPerHost != 100 || tr.MaxConnsPerHost != 200 || tr.IdleConnTimeout != time.Minute {
+		t.Errorf("transport settings not applied: %+v", tr)
+	}
+	if tr.TLSHandshakeTimeout != 10*time.Second {
+		t.Errorf("expected settings of DefaultRoundTripper to be kept, got TLS handshake timeout %v", tr.TLSHandshakeTimeout)
+	}
+	req, _ := http.NewRequest(http.MethodGet, "http://prometheus:9090", nil)
+	if u, err := tr.Proxy(req); err != nil || u.String() != "http://proxy:3128" {
+		t.Errorf("got proxy %v (%v), want http://proxy:3128", u, err)
+	}
+
+	c.RoundTripper = http.DefaultTransport
+	if err := c.validate(); err == nil {
+		t.Error("expected error for transport settings with RoundTripper")
+	}
+}
+
 func TestClientURL(t *testing.T) {
 	tests := []struct {
 		address  string
@@ -0,0 +1,77 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// TenantHeader is the header used by multi-tenant Prometheus-compatible
+// backends like Cortex, Mimir, Thanos, and Loki to identify the tenant.
+const TenantHeader = "X-Scope-OrgID"
+
+type headerKey struct{}
+
+// WithHeader returns a copy of ctx that makes the Client returned by
+// NewClient add the provided header to all requests sent with the context,
+// e.g. for per-request authentication. The values replace those of headers
+// with the same name already set on the request. Headers added by previous
+// calls of WithHeader on the parent context are retained unless they are
+// replaced.
+func WithHeader(ctx context.Context, header http.Header) context.Context {
+	merged := HeaderFromContext(ctx).Clone()
+	if merged == nil {
+		merged = make(http.Header, len(header))
+	}
+	for name, values := range header {
+		merged[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+	}
+	return context.WithValue(ctx, headerKey{}, merged)
+}
+
+// WithTenant returns a copy of ctx that makes the Client returned by
+// NewClient send requests on behalf of the provided tenant, see TenantHeader.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return WithHeader(ctx, http.Header{TenantHeader: []string{tenant}})
+}
+
+// HeaderFromContext returns the header added to ctx by WithHeader and
+// WithTenant, or nil if there is none. The returned header must not be
+// modified.
+func HeaderFromContext(ctx context.Context) http.Header {
+	if ctx == nil {
+		return nil
+	}
+	h, _ := ctx.Value(headerKey{}).(http.Header)
+	return h
+}
+
+// setContextHeader returns req with the header of its context added.
+func setContextHeader(req *http.Request) *http.Request {
+	header := HeaderFromContext(req.Context())
+	if len(header) == 0 {
+		return req
+	}
+	// Don't modify the header of the caller's request.
+	r := *req
+	r.Header = req.Header.Clone()
+	if r.Header == nil {
+		r.Header = make(http.Header, len(header))
+	}
+	for name, values := range header {
+		r.Header[name] = values
+	}
+	return &r
+}
@@ -0,0 +1,66 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestContextHeader(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{Address: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := WithHeader(context.Background(), http.Header{"authorization": []string{"Bearer a"}, "X-Foo": []string{"1"}})
+	ctx = WithTenant(ctx, "team-a")
+	ctx = WithHeader(ctx, http.Header{"X-Foo": []string{"2"}})
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("X-Foo", "0")
+	req.Header.Set("X-Bar", "3")
+	if _, _, err := client.Do(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{
+		"Authorization": "Bearer a",
+		"X-Scope-Orgid": "team-a",
+		"X-Foo":         "2",
+		"X-Bar":         "3",
+	} {
+		if v := got.Get(name); v != want {
+			t.Errorf("got header %s=%q, want %q", name, v, want)
+		}
+	}
+	if v := req.Header.Get("X-Foo"); v != "0" {
+		t.Errorf("request header of the caller was modified to %q", v)
+	}
+
+	// Requests without context headers are sent as is.
+	if _, _, err := client.Do(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if v := got.Get(TenantHeader); v != "" {
+		t.Errorf("got unexpected tenant %q", v)
+	}
+}
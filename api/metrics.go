@@ -0,0 +1,153 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DecodeObserver is implemented by the Client returned by NewClient. Packages
+// decoding API responses use it to report the time spent decoding the
+// response to req, which is recorded if Config.Registerer is set. Package
+// api/prometheus/v1 reports the decoding of query results.
+type DecodeObserver interface {
+	ObserveDecode(req *http.Request, d time.Duration)
+}
+
+// endpointTemplates are the API paths containing parameters. They are used
+// as endpoint label values instead of the actual paths to bound the
+// cardinality of the client metrics.
+var endpointTemplates = []string{
+	"/api/v1/label/:name/values",
+	"/api/v2/silence/:id",
+}
+
+// clientMetrics are the metrics a Client reports about its requests, shared by
+// all Clients registering with the same Registerer. A nil *clientMetrics is
+// valid and records nothing.
+type clientMetrics struct {
+	requests     *prometheus.CounterVec
+	duration     *prometheus.HistogramVec
+	responseSize *prometheus.HistogramVec
+	decode       *prometheus.HistogramVec
+}
+
+func newClientMetrics(reg prometheus.Registerer) (*clientMetrics, error) {
+	m := &clientMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "prometheus_api_client_requests_total",
+			Help: "Total number of HTTP requests sent by the API client by endpoint and status code.",
+		}, []string{"endpoint", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "prometheus_api_client_request_duration_seconds",
+			Help:    "Duration of HTTP requests sent by the API client, including retries and reading the response body.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"endpoint"}),
+		responseSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "prometheus_api_client_response_size_bytes",
+			Help:    "Size of the response bodies received by the API client.",
+			Buckets: prometheus.ExponentialBuckets(256, 4, 10),
+		}, []string{"endpoint"}),
+		decode: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "prometheus_api_client_decode_duration_seconds",
+			Help:    "Duration of decoding the responses received by the API client.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"endpoint"}),
+	}
+	var err error
+	if m.requests, err = register(reg, m.requests); err != nil {
+		return nil, err
+	}
+	if m.duration, err = register(reg, m.duration); err != nil {
+		return nil, err
+	}
+	if m.responseSize, err = register(reg, m.responseSize); err != nil {
+		return nil, err
+	}
+	if m.decode, err = register(reg, m.decode); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// register registers c with reg. If an equal collector is already registered
+// (e.g. by another Client), the existing one is returned instead.
+func register[T prometheus.Collector](reg prometheus.Registerer, c T) (T, error) {
+	if err := reg.Register(c); err != nil {
+		are := &prometheus.AlreadyRegisteredError{}
+		if errors.As(err, are) {
+			if existing, ok := are.ExistingCollector.(T); ok {
+				return existing, nil
+			}
+		}
+		return c, err
+	}
+	return c, nil
+}
+
+// observeRequest records a request. A negative size means that the size of
+// the response body is unknown.
+func (m *clientMetrics) observeRequest(endpoint string, resp *http.Response, size int, d time.Duration) {
+	if m == nil {
+		return
+	}
+	code := "error"
+	if resp != nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	m.requests.WithLabelValues(endpoint, code).Inc()
+	m.duration.WithLabelValues(endpoint).Observe(d.Seconds())
+	if size >= 0 {
+		m.responseSize.WithLabelValues(endpoint).Observe(float64(size))
+	}
+}
+
+func (m *clientMetrics) observeDecode(endpoint string, d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.decode.WithLabelValues(endpoint).Observe(d.Seconds())
+}
+
+// endpoint returns the endpoint label value for a request path, which is the
+// path relative to the address of the client, with parameters replaced as in
+// endpointTemplates.
+func endpoint(basePath, p string) string {
+	p = "/" + strings.TrimLeft(strings.TrimPrefix(p, basePath), "/")
+	segments := strings.Split(p, "/")
+	for _, t := range endpointTemplates {
+		if matchTemplate(strings.Split(t, "/"), segments) {
+			return t
+		}
+	}
+	return p
+}
+
+func matchTemplate(template, segments []string) bool {
+	if len(template) != len(segments) {
+		return false
+	}
+	for i, s := range template {
+		if !strings.HasPrefix(s, ":") && s != segments[i] {
+			return false
+		}
+	}
+	return true
+}
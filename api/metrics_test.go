@@ -0,0 +1,76 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestClientMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/prom/api/v1/query" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		w.Write([]byte("12345"))
+	}))
+	defer server.Close()
+
+	reg := prometheus.NewRegistry()
+	client, err := NewClient(Config{Address: server.URL + "/prom", Registerer: reg})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A second client shares the metrics.
+	if _, err := NewClient(Config{Address: server.URL, Registerer: reg}); err != nil {
+		t.Fatal(err)
+	}
+
+	do := func(ep string, args map[string]string) *http.Request {
+		req, _ := http.NewRequest(http.MethodGet, client.URL(ep, args).String(), nil)
+		if _, _, err := client.Do(context.Background(), req); err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+	do("/api/v1/query", nil)
+	do("/api/v1/label/:name/values", map[string]string{"name": "job"})
+	req := do("/api/v1/label/:name/values", map[string]string{"name": "instance"})
+	client.(DecodeObserver).ObserveDecode(req, time.Millisecond)
+
+	m := client.(*httpClient).metrics
+	for _, s := range []struct {
+		c    prometheus.Collector
+		want float64
+	}{
+		{m.requests.WithLabelValues("/api/v1/query", "400"), 1},
+		{m.requests.WithLabelValues("/api/v1/label/:name/values", "200"), 2},
+	} {
+		if got := testutil.ToFloat64(s.c); got != s.want {
+			t.Errorf("got %v, want %v", got, s.want)
+		}
+	}
+	if got := testutil.CollectAndCount(m.responseSize); got != 2 {
+		t.Errorf("got %d response size series, want 2", got)
+	}
+	if got := testutil.CollectAndCount(m.decode); got != 1 {
+		t.Errorf("got %d decode series, want 1", got)
+	}
+}
@@ -380,6 +380,7 @@ const (
 	epBuildinfo       = apiPrefix + "/status/buildinfo"
 	epRuntimeinfo     = apiPrefix + "/status/runtimeinfo"
 	epTSDB            = apiPrefix + "/status/tsdb"
+	epTSDBBlocks      = apiPrefix + "/status/tsdb/blocks"
 	epWalReplay       = apiPrefix + "/status/walreplay"
 )
 
@@ -415,6 +416,9 @@ const (
 	ErrBadResponse ErrorType = "bad_response"
 	ErrServer      ErrorType = "server_error"
 	ErrClient      ErrorType = "client_error"
+	ErrInternal    ErrorType = "internal"
+	ErrUnavailable ErrorType = "unavailable"
+	ErrNotFound    ErrorType = "not_found"
 
 	// Possible values for HealthStatus.
 	HealthGood    HealthStatus = "up"
@@ -452,6 +456,42 @@ func (e *Error) Error() string {
 	return fmt.Sprintf("%s: %s", e.Type, e.Msg)
 }
 
+// The following errors categorize the errors returned by the API, so that
+// callers can branch on the kind of failure with errors.Is, e.g.
+//
+//	if errors.Is(err, v1.ErrQueryTimeout) {
+//		// Retry with a smaller time range.
+//	}
+var (
+	// ErrQueryTimeout matches the errors the server returns if the
+	// evaluation of a query exceeded its timeout, see WithTimeout.
+	ErrQueryTimeout = errors.New("query timed out")
+	// ErrQueryCanceled matches the errors the server returns if the
+	// evaluation of a query was canceled.
+	ErrQueryCanceled = errors.New("query canceled")
+	// ErrInvalidRequest matches the errors the server returns for invalid
+	// requests, e.g. a query that doesn't parse or an invalid time range.
+	ErrInvalidRequest = errors.New("invalid request")
+	// ErrServerUnavailable matches the errors the server returns if it
+	// can't serve the request temporarily, e.g. while starting up.
+	ErrServerUnavailable = errors.New("server unavailable")
+)
+
+var errorCategories = map[error]ErrorType{
+	ErrQueryTimeout:      ErrTimeout,
+	ErrQueryCanceled:     ErrCanceled,
+	ErrInvalidRequest:    ErrBadData,
+	ErrServerUnavailable: ErrUnavailable,
+}
+
+// Is makes errors.Is report whether e belongs to the category of target, which
+// is one of ErrQueryTimeout, ErrQueryCanceled, ErrInvalidRequest, and
+// ErrServerUnavailable.
+func (e *Error) Is(target error) bool {
+	t, ok := errorCategories[target]
+	return ok && e.Type == t
+}
+
 // Range represents a sliced time range.
 type Range struct {
 	// The boundaries of the time range.
@@ -482,8 +522,12 @@ type API interface {
 	Query(ctx context.Context, query string, ts time.Time, opts ...Option) (model.Value, Warnings, error)
 	// QueryRange performs a query for the given range.
 	QueryRange(ctx context.Context, query string, r Range, opts ...Option) (model.Value, Warnings, error)
+	// QueryRangeStream performs a query for the given range like QueryRange,
+	// but decodes the resulting series incrementally.
+	QueryRangeStream(ctx context.Context, query string, r Range, opts ...Option) (*SeriesIterator, error)
 	// QueryExemplars performs a query for exemplars by the given query and time range.
-	QueryExemplars(ctx context.Context, query string, startTime, endTime time.Time) ([]ExemplarQueryResult, error)
+	// WithLimit and WithExemplarFilter can be used to restrict the returned exemplars.
+	QueryExemplars(ctx context.Context, query string, startTime, endTime time.Time, opts ...Option) ([]ExemplarQueryResult, error)
 	// Buildinfo returns various build information properties about the Prometheus server
 	Buildinfo(ctx context.Context) (BuildinfoResult, error)
 	// Runtimeinfo returns the various runtime information properties about the Prometheus server.
@@ -503,6 +547,8 @@ type API interface {
 	Metadata(ctx context.Context, metric, limit string) (map[string][]Metadata, error)
 	// TSDB returns the cardinality statistics.
 	TSDB(ctx context.Context, opts ...Option) (TSDBResult, error)
+	// TSDBBlocks returns the metadata of the persisted TSDB blocks.
+	TSDBBlocks(ctx context.Context) (TSDBBlocksResult, error)
 	// WalReplay returns the current replay status of the wal.
 	WalReplay(ctx context.Context) (WalReplayStatus, error)
 }
@@ -670,6 +716,8 @@ type queryResult struct {
 
 	// The decoded value.
 	v model.Value
+	// The statistics, if requested.
+	stats *QueryStats
 }
 
 // TSDBResult contains the result from querying the tsdb endpoint.
@@ -690,6 +738,39 @@ type TSDBHeadStats struct {
 	MaxTime       int `json:"maxTime"`
 }
 
+// TSDBBlocksResult contains the result from querying the tsdb blocks endpoint.
+type TSDBBlocksResult struct {
+	Blocks []BlockMeta `json:"blocks"`
+}
+
+// BlockMeta contains the metadata of a TSDB block.
+type BlockMeta struct {
+	ULID       string          `json:"ulid"`
+	MinTime    int64           `json:"minTime"`
+	MaxTime    int64           `json:"maxTime"`
+	Stats      BlockStats      `json:"stats"`
+	Compaction BlockCompaction `json:"compaction"`
+	Version    int             `json:"version"`
+}
+
+// BlockStats contains the number of samples, series, and chunks of a TSDB
+// block.
+type BlockStats struct {
+	NumSamples          uint64 `json:"numSamples"`
+	NumFloatSamples     uint64 `json:"numFloatSamples"`
+	NumHistogramSamples uint64 `json:"numHistogramSamples"`
+	NumSeries           uint64 `json:"numSeries"`
+	NumChunks           uint64 `json:"numChunks"`
+	NumTombstones       uint64 `json:"numTombstones"`
+}
+
+// BlockCompaction contains the compaction history of a TSDB block.
+type BlockCompaction struct {
+	Level   int      `json:"level"`
+	Sources []string `json:"sources"`
+	Failed  bool     `json:"failed"`
+}
+
 // WalReplayStatus represents the wal replay status.
 type WalReplayStatus struct {
 	Min     int `json:"min"`
@@ -819,30 +900,39 @@ func (r *RecordingRule) UnmarshalJSON(b []byte) error {
 }
 
 func (qr *queryResult) UnmarshalJSON(b []byte) error {
+	return qr.decode(b, json.Unmarshal)
+}
+
+// decode decodes a query result with the provided unmarshal function.
+func (qr *queryResult) decode(b []byte, unmarshal func([]byte, any) error) error {
 	v := struct {
 		Type   model.ValueType `json:"resultType"`
 		Result json.RawMessage `json:"result"`
+		Stats  *QueryStats     `json:"stats"`
 	}{}
 
+	// The envelope is always decoded with json-iterator, as it contains a
+	// json.RawMessage.
 	err := json.Unmarshal(b, &v)
 	if err != nil {
 		return err
 	}
+	qr.stats = v.Stats
 
 	switch v.Type {
 	case model.ValScalar:
 		var sv model.Scalar
-		err = json.Unmarshal(v.Result, &sv)
+		err = unmarshal(v.Result, &sv)
 		qr.v = &sv
 
 	case model.ValVector:
 		var vv model.Vector
-		err = json.Unmarshal(v.Result, &vv)
+		err = unmarshal(v.Result, &vv)
 		qr.v = vv
 
 	case model.ValMatrix:
 		var mv model.Matrix
-		err = json.Unmarshal(v.Result, &mv)
+		err = unmarshal(v.Result, &mv)
 		qr.v = mv
 
 	default:
@@ -866,16 +956,59 @@ type ExemplarQueryResult struct {
 // NewAPI returns a new API for the client.
 //
 // It is safe to use the returned API from multiple goroutines.
-func NewAPI(c api.Client) API {
-	return &httpAPI{
+func NewAPI(c api.Client, opts ...APIOption) API {
+	h := &httpAPI{
 		client: &apiClientImpl{
 			client: c,
 		},
 	}
+	for _, o := range opts {
+		o(h)
+	}
+	return h
+}
+
+// APIOption configures the API returned by NewAPI.
+type APIOption func(h *httpAPI)
+
+// Decoder decodes the JSON data of API responses.
+type Decoder interface {
+	// Unmarshal decodes data into v like json.Unmarshal from the standard
+	// library, including support for json.Unmarshaler.
+	Unmarshal(data []byte, v any) error
+}
+
+// DecoderFunc is an adapter to use a function like json.Unmarshal as Decoder.
+type DecoderFunc func(data []byte, v any) error
+
+// Unmarshal implements Decoder.
+func (f DecoderFunc) Unmarshal(data []byte, v any) error {
+	return f(data, v)
+}
+
+// WithDecoder makes the API decode the data of responses with d instead of
+// the default json-iterator based decoder, e.g. to use a faster JSON library
+// for large query results. Query results are decoded with d into model.Vector,
+// model.Matrix, or model.Scalar. The response envelope is always decoded by
+// the API itself. For decoding range queries without holding the whole
+// response in memory, see QueryRangeStream.
+func WithDecoder(d Decoder) APIOption {
+	return func(h *httpAPI) {
+		h.decoder = d
+	}
 }
 
 type httpAPI struct {
-	client apiClient
+	client  apiClient
+	decoder Decoder
+}
+
+// unmarshal decodes the data of a response with the configured Decoder.
+func (h *httpAPI) unmarshal(data []byte, v any) error {
+	if h.decoder == nil {
+		return json.Unmarshal(data, v)
+	}
+	return h.decoder.Unmarshal(data, v)
 }
 
 func (h *httpAPI) Alerts(ctx context.Context) (AlertsResult, error) {
@@ -892,7 +1025,7 @@ func (h *httpAPI) Alerts(ctx context.Context) (AlertsResult, error) {
 	}
 
 	var res AlertsResult
-	err = json.Unmarshal(body, &res)
+	err = h.unmarshal(body, &res)
 	return res, err
 }
 
@@ -910,7 +1043,7 @@ func (h *httpAPI) AlertManagers(ctx context.Context) (AlertManagersResult, error
 	}
 
 	var res AlertManagersResult
-	err = json.Unmarshal(body, &res)
+	err = h.unmarshal(body, &res)
 	return res, err
 }
 
@@ -940,7 +1073,7 @@ func (h *httpAPI) Config(ctx context.Context) (ConfigResult, error) {
 	}
 
 	var res ConfigResult
-	err = json.Unmarshal(body, &res)
+	err = h.unmarshal(body, &res)
 	return res, err
 }
 
@@ -984,7 +1117,7 @@ func (h *httpAPI) Flags(ctx context.Context) (FlagsResult, error) {
 	}
 
 	var res FlagsResult
-	err = json.Unmarshal(body, &res)
+	err = h.unmarshal(body, &res)
 	return res, err
 }
 
@@ -1002,7 +1135,7 @@ func (h *httpAPI) Buildinfo(ctx context.Context) (BuildinfoResult, error) {
 	}
 
 	var res BuildinfoResult
-	err = json.Unmarshal(body, &res)
+	err = h.unmarshal(body, &res)
 	return res, err
 }
 
@@ -1020,7 +1153,7 @@ func (h *httpAPI) Runtimeinfo(ctx context.Context) (RuntimeinfoResult, error) {
 	}
 
 	var res RuntimeinfoResult
-	err = json.Unmarshal(body, &res)
+	err = h.unmarshal(body, &res)
 	return res, err
 }
 
@@ -1038,13 +1171,13 @@ func (h *httpAPI) LabelNames(ctx context.Context, matches []string, startTime, e
 		q.Add("match[]", m)
 	}
 
-	_, body, w, err := h.client.DoGetFallback(ctx, u, q)
+	_, body, a, err := h.client.DoGetFallback(ctx, u, q)
 	if err != nil {
-		return nil, w, err
+		return nil, warnings(a, opts), err
 	}
 	var labelNames []string
-	err = json.Unmarshal(body, &labelNames)
-	return labelNames, w, err
+	err = h.unmarshal(body, &labelNames)
+	return labelNames, warnings(a, opts), err
 }
 
 func (h *httpAPI) LabelValues(ctx context.Context, label string, matches []string, startTime, endTime time.Time, opts ...Option) (model.LabelValues, Warnings, error) {
@@ -1067,24 +1200,33 @@ func (h *httpAPI) LabelValues(ctx context.Context, label string, matches []strin
 	if err != nil {
 		return nil, nil, err
 	}
-	_, body, w, err := h.client.Do(ctx, req)
+	_, body, a, err := h.client.Do(ctx, req)
 	if err != nil {
-		return nil, w, err
+		return nil, warnings(a, opts), err
 	}
 	var labelValues model.LabelValues
-	err = json.Unmarshal(body, &labelValues)
-	return labelValues, w, err
+	err = h.unmarshal(body, &labelValues)
+	return labelValues, warnings(a, opts), err
 }
 
 type apiOptions struct {
-	timeout time.Duration
-	limit   uint64
+	timeout        time.Duration
+	limit          uint64
+	stats          *QueryStats
+	exemplarFilter func(Exemplar) bool
+	params         url.Values
+	annotations    *Annotations
 }
 
 type Option func(c *apiOptions)
 
 // WithTimeout can be used to provide an optional query evaluation timeout for Query and QueryRange.
 // https://prometheus.io/docs/prometheus/latest/querying/api/#instant-queries
+//
+// Unlike a context deadline, which aborts the HTTP request, the timeout is
+// enforced by the server, which reports an exceeded timeout with an error
+// matching ErrQueryTimeout. The server caps the timeout at its own
+// -query.timeout flag.
 func WithTimeout(timeout time.Duration) Option {
 	return func(o *apiOptions) {
 		o.timeout = timeout
@@ -1099,20 +1241,44 @@ func WithLimit(limit uint64) Option {
 	}
 }
 
-func addOptionalURLParams(q url.Values, opts []Option) url.Values {
+// WithExemplarFilter makes QueryExemplars only return the exemplars for which
+// filter returns true, e.g. to select exemplars by trace ID. Series without
+// matching exemplars are omitted.
+func WithExemplarFilter(filter func(Exemplar) bool) Option {
+	return func(o *apiOptions) {
+		o.exemplarFilter = filter
+	}
+}
+
+func newAPIOptions(opts []Option) *apiOptions {
 	opt := &apiOptions{}
 	for _, o := range opts {
 		o(opt)
 	}
+	return opt
+}
+
+func addOptionalURLParams(q url.Values, opts []Option) url.Values {
+	opt := newAPIOptions(opts)
+
+	for k, vs := range opt.params {
+		q[k] = append(q[k], vs...)
+	}
 
 	if opt.timeout > 0 {
-		q.Set("timeout", opt.timeout.String())
+		// Prometheus doesn't accept fractional units like "1.5s", so the
+		// timeout is sent in seconds.
+		q.Set("timeout", strconv.FormatFloat(opt.timeout.Seconds(), 'f', -1, 64))
 	}
 
 	if opt.limit > 0 {
 		q.Set("limit", strconv.FormatUint(opt.limit, 10))
 	}
 
+	if opt.stats != nil {
+		q.Set("stats", "all")
+	}
+
 	return q
 }
 
@@ -1125,16 +1291,46 @@ func (h *httpAPI) Query(ctx context.Context, query string, ts time.Time, opts ..
 		q.Set("time", formatTime(ts))
 	}
 
-	_, body, warnings, err := h.client.DoGetFallback(ctx, u, q)
+	resp, body, a, err := h.client.DoGetFallback(ctx, u, q)
 	if err != nil {
-		return nil, warnings, err
+		return nil, warnings(a, opts), err
 	}
 
-	var qres queryResult
-	return qres.v, warnings, json.Unmarshal(body, &qres)
+	v, err := h.decodeQueryResult(resp, body, opts)
+	return v, warnings(a, opts), err
 }
 
 func (h *httpAPI) QueryRange(ctx context.Context, query string, r Range, opts ...Option) (model.Value, Warnings, error) {
+	u, q := h.queryRangeRequest(query, r, opts)
+
+	resp, body, a, err := h.client.DoGetFallback(ctx, u, q)
+	if err != nil {
+		return nil, warnings(a, opts), err
+	}
+
+	v, err := h.decodeQueryResult(resp, body, opts)
+	return v, warnings(a, opts), err
+}
+
+// decodeQueryResult decodes the result of a query, stores its statistics if
+// requested with WithStats, and reports the time spent to the underlying
+// api.Client if it implements api.DecodeObserver.
+func (h *httpAPI) decodeQueryResult(resp *http.Response, body []byte, opts []Option) (model.Value, error) {
+	start := time.Now()
+	var qres queryResult
+	err := qres.decode(body, h.unmarshal)
+	if stats := newAPIOptions(opts).stats; stats != nil && qres.stats != nil {
+		*stats = *qres.stats
+	}
+	if impl, ok := h.client.(*apiClientImpl); ok && resp != nil && resp.Request != nil {
+		if o, ok := impl.client.(api.DecodeObserver); ok {
+			o.ObserveDecode(resp.Request, time.Since(start))
+		}
+	}
+	return qres.v, err
+}
+
+func (h *httpAPI) queryRangeRequest(query string, r Range, opts []Option) (*url.URL, url.Values) {
 	u := h.client.URL(epQueryRange, nil)
 	q := addOptionalURLParams(u.Query(), opts)
 
@@ -1142,14 +1338,7 @@ func (h *httpAPI) QueryRange(ctx context.Context, query string, r Range, opts ..
 	q.Set("start", formatTime(r.Start))
 	q.Set("end", formatTime(r.End))
 	q.Set("step", strconv.FormatFloat(r.Step.Seconds(), 'f', -1, 64))
-
-	_, body, warnings, err := h.client.DoGetFallback(ctx, u, q)
-	if err != nil {
-		return nil, warnings, err
-	}
-
-	var qres queryResult
-	return qres.v, warnings, json.Unmarshal(body, &qres)
+	return u, q
 }
 
 func (h *httpAPI) Series(ctx context.Context, matches []string, startTime, endTime time.Time, opts ...Option) ([]model.LabelSet, Warnings, error) {
@@ -1167,13 +1356,13 @@ func (h *httpAPI) Series(ctx context.Context, matches []string, startTime, endTi
 		q.Set("end", formatTime(endTime))
 	}
 
-	_, body, warnings, err := h.client.DoGetFallback(ctx, u, q)
+	_, body, a, err := h.client.DoGetFallback(ctx, u, q)
 	if err != nil {
-		return nil, warnings, err
+		return nil, warnings(a, opts), err
 	}
 
 	var mset []model.LabelSet
-	return mset, warnings, json.Unmarshal(body, &mset)
+	return mset, warnings(a, opts), h.unmarshal(body, &mset)
 }
 
 func (h *httpAPI) Snapshot(ctx context.Context, skipHead bool) (SnapshotResult, error) {
@@ -1195,7 +1384,7 @@ func (h *httpAPI) Snapshot(ctx context.Context, skipHead bool) (SnapshotResult,
 	}
 
 	var res SnapshotResult
-	err = json.Unmarshal(body, &res)
+	err = h.unmarshal(body, &res)
 	return res, err
 }
 
@@ -1213,7 +1402,7 @@ func (h *httpAPI) Rules(ctx context.Context) (RulesResult, error) {
 	}
 
 	var res RulesResult
-	err = json.Unmarshal(body, &res)
+	err = h.unmarshal(body, &res)
 	return res, err
 }
 
@@ -1231,7 +1420,7 @@ func (h *httpAPI) Targets(ctx context.Context) (TargetsResult, error) {
 	}
 
 	var res TargetsResult
-	err = json.Unmarshal(body, &res)
+	err = h.unmarshal(body, &res)
 	return res, err
 }
 
@@ -1256,7 +1445,7 @@ func (h *httpAPI) TargetsMetadata(ctx context.Context, matchTarget, metric, limi
 	}
 
 	var res []MetricMetadata
-	err = json.Unmarshal(body, &res)
+	err = h.unmarshal(body, &res)
 	return res, err
 }
 
@@ -1280,7 +1469,7 @@ func (h *httpAPI) Metadata(ctx context.Context, metric, limit string) (map[strin
 	}
 
 	var res map[string][]Metadata
-	err = json.Unmarshal(body, &res)
+	err = h.unmarshal(body, &res)
 	return res, err
 }
 
@@ -1300,7 +1489,25 @@ func (h *httpAPI) TSDB(ctx context.Context, opts ...Option) (TSDBResult, error)
 	}
 
 	var res TSDBResult
-	err = json.Unmarshal(body, &res)
+	err = h.unmarshal(body, &res)
+	return res, err
+}
+
+func (h *httpAPI) TSDBBlocks(ctx context.Context) (TSDBBlocksResult, error) {
+	u := h.client.URL(epTSDBBlocks, nil)
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return TSDBBlocksResult{}, err
+	}
+
+	_, body, _, err := h.client.Do(ctx, req)
+	if err != nil {
+		return TSDBBlocksResult{}, err
+	}
+
+	var res TSDBBlocksResult
+	err = h.unmarshal(body, &res)
 	return res, err
 }
 
@@ -1318,13 +1525,17 @@ func (h *httpAPI) WalReplay(ctx context.Context) (WalReplayStatus, error) {
 	}
 
 	var res WalReplayStatus
-	err = json.Unmarshal(body, &res)
+	err = h.unmarshal(body, &res)
 	return res, err
 }
 
-func (h *httpAPI) QueryExemplars(ctx context.Context, query string, startTime, endTime time.Time) ([]ExemplarQueryResult, error) {
+func (h *httpAPI) QueryExemplars(ctx context.Context, query string, startTime, endTime time.Time, opts ...Option) ([]ExemplarQueryResult, error) {
 	u := h.client.URL(epQueryExemplars, nil)
 	q := u.Query()
+	opt := newAPIOptions(opts)
+	if opt.limit > 0 {
+		q.Set("limit", strconv.FormatUint(opt.limit, 10))
+	}
 
 	q.Set("query", query)
 	if !startTime.IsZero() {
@@ -1340,19 +1551,125 @@ func (h *httpAPI) QueryExemplars(ctx context.Context, query string, startTime, e
 	}
 
 	var res []ExemplarQueryResult
-	err = json.Unmarshal(body, &res)
-	return res, err
+	if err := h.unmarshal(body, &res); err != nil {
+		return nil, err
+	}
+	return filterExemplars(res, opt), nil
+}
+
+// filterExemplars applies the exemplar filter and limit of opt to res. The
+// limit is enforced by the client too, as not all servers support it.
+func filterExemplars(res []ExemplarQueryResult, opt *apiOptions) []ExemplarQueryResult {
+	if opt.exemplarFilter == nil && opt.limit == 0 {
+		return res
+	}
+	var (
+		filtered []ExemplarQueryResult
+		n        uint64
+	)
+	for _, r := range res {
+		var exemplars []Exemplar
+		for _, e := range r.Exemplars {
+			if opt.limit > 0 && n >= opt.limit {
+				break
+			}
+			if opt.exemplarFilter == nil || opt.exemplarFilter(e) {
+				exemplars = append(exemplars, e)
+				n++
+			}
+		}
+		if len(exemplars) > 0 {
+			filtered = append(filtered, ExemplarQueryResult{SeriesLabels: r.SeriesLabels, Exemplars: exemplars})
+		}
+	}
+	return filtered
 }
 
 // Warnings is an array of non critical errors
 type Warnings []string
 
+// AnnotationLevel models the level of an annotation.
+type AnnotationLevel string
+
+// Possible values for AnnotationLevel.
+const (
+	AnnotationLevelWarning AnnotationLevel = "warning"
+	AnnotationLevelInfo    AnnotationLevel = "info"
+)
+
+// Annotation is a non critical message of the server about a response, e.g.
+// a warning that a query result is incomplete, or an informational note that
+// a function is applied to a metric that might not be a counter.
+type Annotation struct {
+	Level AnnotationLevel
+	Msg   string
+}
+
+func (a Annotation) String() string {
+	return a.Msg
+}
+
+// Annotations are the annotations of a response, warnings first.
+type Annotations []Annotation
+
+func newAnnotations(warnings, infos []string) Annotations {
+	if len(warnings)+len(infos) == 0 {
+		return nil
+	}
+	a := make(Annotations, 0, len(warnings)+len(infos))
+	for _, w := range warnings {
+		a = append(a, Annotation{Level: AnnotationLevelWarning, Msg: w})
+	}
+	for _, i := range infos {
+		a = append(a, Annotation{Level: AnnotationLevelInfo, Msg: i})
+	}
+	return a
+}
+
+// Warnings returns the messages of the warning annotations.
+func (a Annotations) Warnings() Warnings {
+	return a.messages(AnnotationLevelWarning)
+}
+
+// Infos returns the messages of the info annotations.
+func (a Annotations) Infos() []string {
+	return a.messages(AnnotationLevelInfo)
+}
+
+func (a Annotations) messages(level AnnotationLevel) []string {
+	var msgs []string
+	for _, an := range a {
+		if an.Level == level {
+			msgs = append(msgs, an.Msg)
+		}
+	}
+	return msgs
+}
+
+// WithAnnotations stores all annotations of the response in a, including the
+// info annotations, which aren't part of the returned Warnings. It is
+// supported by the methods returning Warnings.
+func WithAnnotations(a *Annotations) Option {
+	return func(o *apiOptions) {
+		o.annotations = a
+	}
+}
+
+// warnings stores the annotations if requested with WithAnnotations and
+// returns the warnings among them.
+func warnings(a Annotations, opts []Option) Warnings {
+	if dst := newAPIOptions(opts).annotations; dst != nil {
+		*dst = a
+	}
+	return a.Warnings()
+}
+
 // apiClient wraps a regular client and processes successful API responses.
 // Successful also includes responses that errored at the API level.
 type apiClient interface {
 	URL(ep string, args map[string]string) *url.URL
-	Do(context.Context, *http.Request) (*http.Response, []byte, Warnings, error)
-	DoGetFallback(ctx context.Context, u *url.URL, args url.Values) (*http.Response, []byte, Warnings, error)
+	Do(context.Context, *http.Request) (*http.Response, []byte, Annotations, error)
+	DoGetFallback(ctx context.Context, u *url.URL, args url.Values) (*http.Response, []byte, Annotations, error)
 }
 
 type apiClientImpl struct {
@@ -1365,6 +1682,7 @@ type apiResponse struct {
 	ErrorType ErrorType       `json:"errorType"`
 	Error     string          `json:"error"`
 	Warnings  []string        `json:"warnings,omitempty"`
+	Infos     []string        `json:"infos,omitempty"`
 }
 
 func apiError(code int) bool {
@@ -1386,15 +1704,30 @@ func (h *apiClientImpl) URL(ep string, args map[string]string) *url.URL {
 	return h.client.URL(ep, args)
 }
 
-func (h *apiClientImpl) Do(ctx context.Context, req *http.Request) (*http.Response, []byte, Warnings, error) {
+func (h *apiClientImpl) Do(ctx context.Context, req *http.Request) (*http.Response, []byte, Annotations, error) {
 	resp, body, err := h.client.Do(ctx, req)
 	if err != nil {
 		return resp, body, nil, err
 	}
+	return processResponse(resp, body)
+}
 
+// processResponse checks the status code of resp and decodes the API envelope
+// in body.
+func processResponse(resp *http.Response, body []byte) (*http.Response, []byte, Annotations, error) {
+	var err error
 	code := resp.StatusCode
 
 	if code/100 != 2 && !apiError(code) {
+		// Prometheus reports query timeouts and cancellations with a 503
+		// status code, but the usual error envelope.
+		var result apiResponse
+		if code == http.StatusServiceUnavailable && json.Unmarshal(body, &result) == nil && result.Status == "error" && result.ErrorType != "" {
+			return resp, []byte(result.Data), newAnnotations(result.Warnings, result.Infos), &Error{
+				Type: result.ErrorType,
+				Msg:  result.Error,
+			}
+		}
 		errorType, errorMsg := errorTypeAndMsgFor(resp)
 		return resp, body, nil, &Error{
 			Type:   errorType,
@@ -1428,17 +1761,36 @@ func (h *apiClientImpl) Do(ctx context.Context, req *http.Request) (*http.Respon
 		}
 	}
 
-	return resp, []byte(result.Data), result.Warnings, err
+	return resp, []byte(result.Data), newAnnotations(result.Warnings, result.Infos), err
 }
 
 // DoGetFallback will attempt to do the request as-is, and on a 405 or 501 it
 // will fallback to a GET request.
-func (h *apiClientImpl) DoGetFallback(ctx context.Context, u *url.URL, args url.Values) (*http.Response, []byte, Warnings, error) {
+func (h *apiClientImpl) DoGetFallback(ctx context.Context, u *url.URL, args url.Values) (*http.Response, []byte, Annotations, error) {
 	encodedArgs := args.Encode()
-	req, err := http.NewRequest(http.MethodPost, u.String(), strings.NewReader(encodedArgs))
+	req, err := newPostRequest(u, encodedArgs)
 	if err != nil {
 		return nil, nil, nil, err
 	}
+
+	resp, body, annotations, err := h.Do(ctx, req)
+	if resp != nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		u.RawQuery = encodedArgs
+		req, err = http.NewRequest(http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, nil, annotations, err
+		}
+		return h.Do(ctx, req)
+	}
+	return resp, body, annotations, err
+}
+
+// newPostRequest creates a POST request with the form-encoded args as body.
+func newPostRequest(u *url.URL, encodedArgs string) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodPost, u.String(), strings.NewReader(encodedArgs))
+	if err != nil {
+		return nil, err
+	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	// Following comment originates from https://pkg.go.dev/net/http#Transport
 	// Transport only retries a request upon encountering a network error if the request is
@@ -1448,17 +1800,7 @@ func (h *apiClientImpl) DoGetFallback(ctx context.Context, u *url.URL, args url.
 	// idempotency key value is a zero-length slice, the request is treated as idempotent but
 	// the header is not sent on the wire.
 	req.Header["Idempotency-Key"] = nil
-
-	resp, body, warnings, err := h.Do(ctx, req)
-	if resp != nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
-		u.RawQuery = encodedArgs
-		req, err = http.NewRequest(http.MethodGet, u.String(), nil)
-		if err != nil {
-			return nil, nil, warnings, err
-		}
-		return h.Do(ctx, req)
-	}
-	return resp, body, warnings, err
+	return req, nil
 }
 
 func formatTime(t time.Time) string {
@@ -15,7 +15,9 @@ package v1
 
 import (
 	"context"
+	stdjson "encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"math"
 	"net/http"
@@ -30,6 +32,8 @@ import (
 	json "github.com/json-iterator/go"
 
 	"github.com/prometheus/common/model"
+
+	"github.com/prometheus/client_golang/api"
 )
 
 type apiTest struct {
@@ -62,7 +66,7 @@ func (c *apiTestClient) URL(ep string, args map[string]string) *url.URL {
 	return u
 }
 
-func (c *apiTestClient) Do(_ context.Context, req *http.Request) (*http.Response, []byte, Warnings, error) {
+func (c *apiTestClient) Do(_ context.Context, req *http.Request) (*http.Response, []byte, Annotations, error) {
 	test := c.curTest
 
 	if req.URL.Path != test.reqPath {
@@ -86,10 +90,10 @@ func (c *apiTestClient) Do(_ context.Context, req *http.Request) (*http.Response
 		resp.StatusCode = http.StatusOK
 	}
 
-	return resp, b, test.inWarnings, test.inErr
+	return resp, b, newAnnotations(test.inWarnings, nil), test.inErr
 }
 
-func (c *apiTestClient) DoGetFallback(ctx context.Context, u *url.URL, args url.Values) (*http.Response, []byte, Warnings, error) {
+func (c *apiTestClient) DoGetFallback(ctx context.Context, u *url.URL, args url.Values) (*http.Response, []byte, Annotations, error) {
 	req, err := http.NewRequest(http.MethodPost, u.String(), strings.NewReader(args.Encode()))
 	if err != nil {
 		return nil, nil, nil, err
@@ -226,6 +230,13 @@ func TestAPIs(t *testing.T) {
 		}
 	}
 
+	doTSDBBlocks := func() func() (interface{}, Warnings, error) {
+		return func() (interface{}, Warnings, error) {
+			v, err := promAPI.TSDBBlocks(context.Background())
+			return v, nil, err
+		}
+	}
+
 	doWalReply := func() func() (interface{}, Warnings, error) {
 		return func() (interface{}, Warnings, error) {
 			v, err := promAPI.WalReplay(context.Background())
@@ -1123,6 +1134,60 @@ func TestAPIs(t *testing.T) {
 			},
 		},
 
+		{
+			do:        doTSDBBlocks(),
+			reqMethod: "GET",
+			reqPath:   "/api/v1/status/tsdb/blocks",
+			inErr:     errors.New("some error"),
+			err:       errors.New("some error"),
+		},
+
+		{
+			do:        doTSDBBlocks(),
+			reqMethod: "GET",
+			reqPath:   "/api/v1/status/tsdb/blocks",
+			inRes: map[string]interface{}{
+				"blocks": []interface{}{
+					map[string]interface{}{
+						"ulid":    "01JGZ4Z4WQ8A1Q7D3XHDRVKSE6",
+						"minTime": 1735689600000,
+						"maxTime": 1735696800000,
+						"stats": map[string]interface{}{
+							"numSamples":      120,
+							"numFloatSamples": 100,
+							"numSeries":       2,
+							"numChunks":       3,
+						},
+						"compaction": map[string]interface{}{
+							"level":   1,
+							"sources": []string{"01JGZ4Z4WQ8A1Q7D3XHDRVKSE6"},
+						},
+						"version": 1,
+					},
+				},
+			},
+			res: TSDBBlocksResult{
+				Blocks: []BlockMeta{
+					{
+						ULID:    "01JGZ4Z4WQ8A1Q7D3XHDRVKSE6",
+						MinTime: 1735689600000,
+						MaxTime: 1735696800000,
+						Stats: BlockStats{
+							NumSamples:      120,
+							NumFloatSamples: 100,
+							NumSeries:       2,
+							NumChunks:       3,
+						},
+						Compaction: BlockCompaction{
+							Level:   1,
+							Sources: []string{"01JGZ4Z4WQ8A1Q7D3XHDRVKSE6"},
+						},
+						Version: 1,
+					},
+				},
+			},
+		},
+
 		{
 			do:        doWalReply(),
 			reqMethod: "GET",
@@ -1327,6 +1392,18 @@ func TestAPIClientDo(t *testing.T) {
 			},
 			expectedBody: `test`,
 		},
+		{
+			code: http.StatusServiceUnavailable,
+			response: &apiResponse{
+				Status:    "error",
+				ErrorType: ErrTimeout,
+				Error:     "query timed out in expression evaluation",
+			},
+			expectedErr: &Error{
+				Type: ErrTimeout,
+				Msg:  "query timed out in expression evaluation",
+			},
+		},
 		{
 			code:     http.StatusInternalServerError,
 			response: "500 error details",
@@ -1433,7 +1510,8 @@ func TestAPIClientDo(t *testing.T) {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
 			tc.ch <- test
 
-			_, body, warnings, err := client.Do(context.Background(), tc.req)
+			_, body, annotations, err := client.Do(context.Background(), tc.req)
+			warnings := annotations.Warnings()
 
 			if test.expectedWarnings != nil {
 				if !reflect.DeepEqual(test.expectedWarnings, warnings) {
@@ -1478,6 +1556,126 @@ func TestAPIClientDo(t *testing.T) {
 	}
 }
 
+func TestQueryTimeout(t *testing.T) {
+	var timeout string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		req.ParseForm()
+		timeout = req.Form.Get("timeout")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"status":"error","errorType":"timeout","error":"query timed out in query execution"}`))
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(api.Config{Address: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = NewAPI(client).Query(context.Background(), "up", time.Now(), WithTimeout(1500*time.Millisecond))
+	if timeout != "1.5" {
+		t.Errorf("got timeout parameter %q, want 1.5", timeout)
+	}
+	if !errors.Is(err, ErrQueryTimeout) {
+		t.Errorf("got error %v, want query timeout", err)
+	}
+	if errors.Is(&Error{Type: ErrServer}, ErrQueryTimeout) {
+		t.Error("server error must not match ErrQueryTimeout")
+	}
+}
+
+func TestErrorCategories(t *testing.T) {
+	for _, tc := range []struct {
+		errType ErrorType
+		want    error
+	}{
+		{ErrTimeout, ErrQueryTimeout},
+		{ErrCanceled, ErrQueryCanceled},
+		{ErrBadData, ErrInvalidRequest},
+		{ErrUnavailable, ErrServerUnavailable},
+	} {
+		err := fmt.Errorf("wrapped: %w", &Error{Type: tc.errType})
+		for _, target := range []error{ErrQueryTimeout, ErrQueryCanceled, ErrInvalidRequest, ErrServerUnavailable} {
+			if got := errors.Is(err, target); got != (target == tc.want) {
+				t.Errorf("%s: errors.Is(err, %v) = %t", tc.errType, target, got)
+			}
+		}
+	}
+}
+
+func TestAnnotations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]},"warnings":["result is incomplete"],"infos":["metric might not be a counter"]}`))
+	}))
+	defer server.Close()
+	client, err := api.NewClient(api.Config{Address: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var annotations Annotations
+	_, warnings, err := NewAPI(client).Query(context.Background(), "rate(up[5m])", time.Now(), WithAnnotations(&annotations))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Warnings{"result is incomplete"}); !reflect.DeepEqual(warnings, want) {
+		t.Errorf("got warnings %v, want %v", warnings, want)
+	}
+	want := Annotations{
+		{Level: AnnotationLevelWarning, Msg: "result is incomplete"},
+		{Level: AnnotationLevelInfo, Msg: "metric might not be a counter"},
+	}
+	if !reflect.DeepEqual(annotations, want) {
+		t.Errorf("got annotations %v, want %v", annotations, want)
+	}
+	if infos := annotations.Infos(); !reflect.DeepEqual(infos, []string{"metric might not be a counter"}) {
+		t.Errorf("got infos %v", infos)
+	}
+}
+
+func TestWithDecoder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/api/v1/query_range":
+			w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"job":"a"},"values":[[1,"1"],[2,"NaN"]],"histograms":[[3,{"count":"1","sum":"2","buckets":[[0,"1","2","1"]]}]]}]}}`))
+		case "/api/v1/labels":
+			w.Write([]byte(`{"status":"success","data":["__name__","job"]}`))
+		}
+	}))
+	defer server.Close()
+	client, err := api.NewClient(api.Config{Address: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var calls int
+	decoder := DecoderFunc(func(data []byte, v any) error {
+		calls++
+		return stdjson.Unmarshal(data, v)
+	})
+	r := Range{Start: time.Unix(1, 0), End: time.Unix(3, 0), Step: time.Second}
+	want, _, err := NewAPI(client).QueryRange(context.Background(), "up", r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	promAPI := NewAPI(client, WithDecoder(decoder))
+	got, _, err := promAPI.QueryRange(context.Background(), "up", r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != want.String() {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	names, _, err := promAPI.LabelNames(context.Background(), nil, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []string{"__name__", "job"}) {
+		t.Errorf("got label names %v", names)
+	}
+	if calls != 2 {
+		t.Errorf("got %d decoder calls, want 2", calls)
+	}
+}
+
 func TestSamplesJSONSerialization(t *testing.T) {
 	tests := []struct {
 		point    model.SamplePair
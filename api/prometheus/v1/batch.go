@@ -0,0 +1,108 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+const defaultBatchConcurrency = 4
+
+// BatchQuery is a query executed by a Batch.
+type BatchQuery struct {
+	Query string
+	// Time is the evaluation time of an instant query. It is ignored for
+	// range queries.
+	Time time.Time
+	// Range makes the query a range query if not nil.
+	Range   *Range
+	Options []Option
+}
+
+// BatchResult is the result of a BatchQuery. Err is set if the query failed
+// or wasn't executed before the batch was canceled.
+type BatchResult struct {
+	Value    model.Value
+	Warnings Warnings
+	Err      error
+}
+
+// Batch executes many queries concurrently, e.g. to refresh a dashboard.
+type Batch struct {
+	API API
+	// Concurrency is the maximum number of queries executed at the same
+	// time. Defaults to 4.
+	Concurrency int
+	// Timeout, if positive, is the deadline for the whole batch. Queries
+	// that haven't completed by then fail with context.DeadlineExceeded.
+	Timeout time.Duration
+}
+
+// Run executes the queries and returns their results in the same order. The
+// results are always returned, so that the successful queries can be used
+// even if others failed. If any query failed, the returned error joins the
+// errors of the failed queries.
+func (b *Batch) Run(ctx context.Context, queries ...BatchQuery) ([]BatchResult, error) {
+	if b.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.Timeout)
+		defer cancel()
+	}
+	concurrency := b.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+
+	results := make([]BatchResult, len(queries))
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, concurrency)
+	)
+	for i, q := range queries {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			r := &results[i]
+			if q.Range != nil {
+				r.Value, r.Warnings, r.Err = b.API.QueryRange(ctx, q.Query, *q.Range, q.Options...)
+			} else {
+				r.Value, r.Warnings, r.Err = b.API.Query(ctx, q.Query, q.Time, q.Options...)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var errs []error
+	for i, r := range results {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("query %d (%s): %w", i, queries[i].Query, r.Err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// batchAPI answers queries with a scalar, failing queries named "fail" and
+// blocking queries named "slow" until the context is done.
+type batchAPI struct {
+	API
+	running, maxRunning atomic.Int32
+}
+
+func (a *batchAPI) do(ctx context.Context, query string) (model.Value, Warnings, error) {
+	n := a.running.Add(1)
+	defer a.running.Add(-1)
+	for {
+		m := a.maxRunning.Load()
+		if n <= m || a.maxRunning.CompareAndSwap(m, n) {
+			break
+		}
+	}
+	time.Sleep(time.Millisecond)
+	switch query {
+	case "fail":
+		return nil, nil, errors.New("boom")
+	case "slow":
+		<-ctx.Done()
+		return nil, nil, ctx.Err()
+	}
+	return &model.Scalar{Value: 1}, Warnings{query}, nil
+}
+
+func (a *batchAPI) Query(ctx context.Context, query string, _ time.Time, _ ...Option) (model.Value, Warnings, error) {
+	return a.do(ctx, query)
+}
+
+func (a *batchAPI) QueryRange(ctx context.Context, query string, _ Range, _ ...Option) (model.Value, Warnings, error) {
+	return a.do(ctx, "range:"+query)
+}
+
+func TestBatch(t *testing.T) {
+	a := &batchAPI{}
+	b := &Batch{API: a, Concurrency: 2}
+	var queries []BatchQuery
+	for range 10 {
+		queries = append(queries, BatchQuery{Query: "up"})
+	}
+	queries = append(queries, BatchQuery{Query: "fail"}, BatchQuery{Query: "up", Range: &Range{}})
+
+	results, err := b.Run(context.Background(), queries...)
+	if err == nil {
+		t.Error("expected error for failed query")
+	}
+	if len(results) != len(queries) {
+		t.Fatalf("got %d results, want %d", len(results), len(queries))
+	}
+	for i, r := range results[:10] {
+		if r.Err != nil || len(r.Warnings) != 1 || r.Warnings[0] != "up" {
+			t.Errorf("result %d: got %+v", i, r)
+		}
+	}
+	if results[10].Err == nil {
+		t.Error("expected error in the result of the failed query")
+	}
+	if w := results[11].Warnings; len(w) != 1 || w[0] != "range:up" {
+		t.Errorf("expected range query, got %+v", results[11])
+	}
+	if m := a.maxRunning.Load(); m > 2 {
+		t.Errorf("got %d concurrent queries, want at most 2", m)
+	}
+
+	// Queries that don't complete in time fail with the deadline, but the
+	// other results are still returned.
+	b = &Batch{API: a, Concurrency: 1, Timeout: 50 * time.Millisecond}
+	results, err = b.Run(context.Background(), BatchQuery{Query: "up"}, BatchQuery{Query: "slow"}, BatchQuery{Query: "up"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want deadline exceeded", err)
+	}
+	if results[0].Err != nil || results[0].Value == nil {
+		t.Errorf("got %+v, want successful result", results[0])
+	}
+	for _, r := range results[1:] {
+		if !errors.Is(r.Err, context.DeadlineExceeded) {
+			t.Errorf("got %+v, want deadline exceeded", r)
+		}
+	}
+}
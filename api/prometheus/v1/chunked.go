@@ -0,0 +1,132 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+const defaultChunkRetryBackoff = time.Second
+
+// RangeChunker executes long range queries as a sequence of shorter range
+// queries, e.g. to stay below the maximum number of samples a Prometheus
+// server loads for a single query.
+type RangeChunker struct {
+	API API
+	// ChunkDuration is the maximum time range of a single request. It is
+	// rounded down to a multiple of the query step. Mandatory.
+	ChunkDuration time.Duration
+	// MaxRetries is the number of times a failed request is retried. Only
+	// network, server, and timeout errors are retried.
+	MaxRetries int
+	// RetryBackoff is the time to wait before retrying a failed request.
+	// It is doubled for every further retry. Defaults to one second.
+	RetryBackoff time.Duration
+}
+
+// QueryRange performs the range query like API.QueryRange, splitting the range
+// into chunks of at most ChunkDuration, which are requested one after another.
+// The chunks are aligned to the step of the range, so that the stitched
+// result contains the same timestamps as an unsplit query would. The result
+// must be a matrix. The warnings of all requests are merged.
+func (c *RangeChunker) QueryRange(ctx context.Context, query string, r Range, opts ...Option) (model.Matrix, Warnings, error) {
+	if r.Step <= 0 {
+		return nil, nil, errors.New("range query step must be positive")
+	}
+	if c.ChunkDuration <= 0 {
+		return nil, nil, errors.New("chunk duration must be positive")
+	}
+	// Each chunk contains steps timestamps.
+	steps := max(int64(c.ChunkDuration/r.Step), 1)
+
+	var (
+		result   model.Matrix
+		series   = map[model.Fingerprint]*model.SampleStream{}
+		warnings Warnings
+		seenWarn = map[string]struct{}{}
+	)
+	for start := r.Start; !start.After(r.End); start = start.Add(time.Duration(steps) * r.Step) {
+		end := start.Add(time.Duration(steps-1) * r.Step)
+		if end.After(r.End) {
+			end = r.End
+		}
+		v, w, err := c.queryRange(ctx, query, Range{Start: start, End: end, Step: r.Step}, opts)
+		for _, warning := range w {
+			if _, ok := seenWarn[warning]; !ok {
+				seenWarn[warning] = struct{}{}
+				warnings = append(warnings, warning)
+			}
+		}
+		if err != nil {
+			return nil, warnings, err
+		}
+		m, ok := v.(model.Matrix)
+		if !ok {
+			return nil, warnings, fmt.Errorf("unexpected value type %q of range query result", v.Type())
+		}
+		for _, ss := range m {
+			fp := ss.Metric.Fingerprint()
+			if existing, ok := series[fp]; ok {
+				existing.Values = append(existing.Values, ss.Values...)
+				existing.Histograms = append(existing.Histograms, ss.Histograms...)
+				continue
+			}
+			series[fp] = ss
+			result = append(result, ss)
+		}
+	}
+	sort.Sort(result)
+	return result, warnings, nil
+}
+
+// queryRange performs a single range query with retries.
+func (c *RangeChunker) queryRange(ctx context.Context, query string, r Range, opts []Option) (model.Value, Warnings, error) {
+	backoff := c.RetryBackoff
+	if backoff <= 0 {
+		backoff = defaultChunkRetryBackoff
+	}
+	for retry := 0; ; retry++ {
+		v, w, err := c.API.QueryRange(ctx, query, r, opts...)
+		if err == nil || retry >= c.MaxRetries || !retryableChunkError(ctx, err) {
+			return v, w, err
+		}
+		t := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, w, ctx.Err()
+		case <-t.C:
+		}
+		backoff *= 2
+	}
+}
+
+// retryableChunkError reports whether a failed request may succeed when
+// retried.
+func retryableChunkError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Type == ErrServer || apiErr.Type == ErrTimeout
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
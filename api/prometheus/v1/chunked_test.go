@@ -0,0 +1,96 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// chunkedAPI returns a sample per step for series "a", and for series "b"
+// only after the first hour. The first request fails with a server error.
+type chunkedAPI struct {
+	API
+	ranges []Range
+	failed bool
+}
+
+func (a *chunkedAPI) QueryRange(_ context.Context, _ string, r Range, _ ...Option) (model.Value, Warnings, error) {
+	if !a.failed {
+		a.failed = true
+		return nil, nil, &Error{Type: ErrServer, Msg: "server error: 503"}
+	}
+	a.ranges = append(a.ranges, r)
+	var m model.Matrix
+	for _, name := range []model.LabelValue{"b", "a"} {
+		ss := &model.SampleStream{Metric: model.Metric{"name": name}}
+		for ts := r.Start; !ts.After(r.End); ts = ts.Add(r.Step) {
+			if name == "b" && ts.Sub(time.Unix(0, 0)) < time.Hour {
+				continue
+			}
+			ss.Values = append(ss.Values, model.SamplePair{Timestamp: model.TimeFromUnixNano(ts.UnixNano()), Value: 1})
+		}
+		if len(ss.Values) > 0 {
+			m = append(m, ss)
+		}
+	}
+	return m, Warnings{"w"}, nil
+}
+
+func TestRangeChunker(t *testing.T) {
+	a := &chunkedAPI{}
+	c := &RangeChunker{API: a, ChunkDuration: 50 * time.Minute, MaxRetries: 1, RetryBackoff: time.Millisecond}
+	start := time.Unix(0, 0)
+	r := Range{Start: start, End: start.Add(2 * time.Hour), Step: 15 * time.Minute}
+
+	m, warnings, err := c.QueryRange(context.Background(), "up", r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantRanges := []Range{
+		{Start: start, End: start.Add(30 * time.Minute), Step: r.Step},
+		{Start: start.Add(45 * time.Minute), End: start.Add(75 * time.Minute), Step: r.Step},
+		{Start: start.Add(90 * time.Minute), End: start.Add(120 * time.Minute), Step: r.Step},
+	}
+	if !reflect.DeepEqual(a.ranges, wantRanges) {
+		t.Errorf("got ranges %v, want %v", a.ranges, wantRanges)
+	}
+	if !reflect.DeepEqual(warnings, Warnings{"w"}) {
+		t.Errorf("got warnings %v", warnings)
+	}
+	if len(m) != 2 || m[0].Metric["name"] != "a" || m[1].Metric["name"] != "b" {
+		t.Fatalf("got unexpected matrix %v", m)
+	}
+	if got := len(m[0].Values); got != 9 {
+		t.Errorf("got %d samples for a, want 9", got)
+	}
+	if got := len(m[1].Values); got != 5 {
+		t.Errorf("got %d samples for b, want 5", got)
+	}
+	for i := 1; i < len(m[0].Values); i++ {
+		if m[0].Values[i].Timestamp.Sub(m[0].Values[i-1].Timestamp) != r.Step {
+			t.Fatalf("samples are not contiguous: %v", m[0].Values)
+		}
+	}
+
+	// Without retries the server error is returned.
+	c = &RangeChunker{API: &chunkedAPI{}, ChunkDuration: time.Hour}
+	if _, _, err := c.QueryRange(context.Background(), "up", r); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	json "github.com/json-iterator/go"
+
+	"github.com/prometheus/client_golang/api"
+)
+
+// WithParams adds the provided parameters to the requests of the API methods
+// accepting options, e.g. vendor-specific parameters like Thanos' "dedup" or
+// "partial_response". It must not be used for the parameters the method sets
+// itself.
+func WithParams(params url.Values) Option {
+	return func(o *apiOptions) {
+		if o.params == nil {
+			o.params = url.Values{}
+		}
+		for k, vs := range params {
+			o.params[k] = append(o.params[k], vs...)
+		}
+	}
+}
+
+// Do sends a request to an endpoint of a Prometheus-compatible API that isn't
+// covered by API, e.g. a vendor extension of Thanos, Cortex, or Mimir, and
+// decodes the data of the response into result, unless result is nil.
+//
+// The endpoint ep is relative to the address of the client and may contain
+// parameters like ":name", which are replaced by the values in args, see
+// api.Client.URL. The params are sent in the URL for GET and DELETE requests
+// and as form-encoded body otherwise. The response must use the envelope of
+// the Prometheus API, i.e. contain "status" and "data" fields. Errors and
+// warnings are handled like for the methods of API, so that the client's
+// authentication, retries, and per-request headers apply as well.
+func Do(ctx context.Context, client api.Client, method, ep string, args map[string]string, params url.Values, result any) (Warnings, error) {
+	c := &apiClientImpl{client: client}
+	u := c.URL(ep, args)
+
+	var (
+		req *http.Request
+		err error
+	)
+	switch method {
+	case http.MethodGet, http.MethodDelete, http.MethodHead:
+		u.RawQuery = params.Encode()
+		req, err = http.NewRequest(method, u.String(), nil)
+	default:
+		req, err = http.NewRequest(method, u.String(), strings.NewReader(params.Encode()))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, body, annotations, err := c.Do(ctx, req)
+	if err != nil || result == nil || len(body) == 0 {
+		return annotations.Warnings(), err
+	}
+	return annotations.Warnings(), json.Unmarshal(body, result)
+}
@@ -0,0 +1,87 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/api"
+)
+
+func TestExtensions(t *testing.T) {
+	var (
+		method string
+		form   url.Values
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		method = req.Method
+		req.ParseForm()
+		form = req.Form
+		switch req.URL.Path {
+		case "/api/v1/stores":
+			w.Write([]byte(`{"status":"success","data":{"sidecar":[{"name":"10.0.0.1:10901"}]},"warnings":["partial"]}`))
+		case "/api/v1/query":
+			w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+		case "/api/v1/rules/team-a":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"invalid namespace"}`))
+		}
+	}))
+	defer server.Close()
+	client, err := api.NewClient(api.Config{Address: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stores map[string][]struct {
+		Name string `json:"name"`
+	}
+	warnings, err := Do(context.Background(), client, http.MethodGet, "/api/v1/stores", nil, url.Values{"type": {"sidecar"}}, &stores)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if method != http.MethodGet || form.Get("type") != "sidecar" {
+		t.Errorf("got %s request with parameters %v", method, form)
+	}
+	if len(stores["sidecar"]) != 1 || stores["sidecar"][0].Name != "10.0.0.1:10901" {
+		t.Errorf("got unexpected result %v", stores)
+	}
+	if !reflect.DeepEqual(warnings, Warnings{"partial"}) {
+		t.Errorf("got warnings %v", warnings)
+	}
+
+	_, err = Do(context.Background(), client, http.MethodPost, "/api/v1/rules/:namespace", map[string]string{"namespace": "team-a"}, url.Values{"x": {"y"}}, nil)
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Type != ErrBadData {
+		t.Errorf("got error %v, want bad data error", err)
+	}
+	if method != http.MethodPost || form.Get("x") != "y" {
+		t.Errorf("got %s request with parameters %v", method, form)
+	}
+
+	_, _, err = NewAPI(client).Query(context.Background(), "up", time.Time{}, WithParams(url.Values{"dedup": {"true"}, "partial_response": {"false"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if form.Get("dedup") != "true" || form.Get("partial_response") != "false" || form.Get("query") != "up" {
+		t.Errorf("got unexpected parameters %v", form)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"github.com/prometheus/common/model"
+)
+
+// Histogram is a native histogram sample of a query result.
+//
+// Query and QueryRange decode native histograms into the Histogram field of
+// model.Sample (for vectors) and the Histograms field of model.SampleStream
+// (for matrices), while the float Value and Values fields remain empty for
+// them. Histograms converts these into the Histogram type, which is easier to
+// work with.
+type Histogram struct {
+	Timestamp model.Time
+	Count     float64
+	Sum       float64
+	// Buckets are the populated buckets, ordered by their boundaries.
+	// Exponential histograms have buckets around zero (the zero bucket)
+	// and below zero, histograms with custom buckets (schema -53) have a
+	// first bucket with a lower boundary of -Inf.
+	Buckets []HistogramBucket
+}
+
+// HistogramBucket is a bucket of a Histogram.
+type HistogramBucket struct {
+	Lower          float64
+	Upper          float64
+	LowerInclusive bool
+	UpperInclusive bool
+	Count          float64
+}
+
+// HistogramSeries is a series of a query result with its native histogram
+// samples.
+type HistogramSeries struct {
+	Metric     model.Metric
+	Histograms []Histogram
+}
+
+// NewHistogram converts a histogram sample of a query result.
+func NewHistogram(p model.SampleHistogramPair) Histogram {
+	h := Histogram{Timestamp: p.Timestamp}
+	if p.Histogram == nil {
+		return h
+	}
+	h.Count = float64(p.Histogram.Count)
+	h.Sum = float64(p.Histogram.Sum)
+	if len(p.Histogram.Buckets) > 0 {
+		h.Buckets = make([]HistogramBucket, 0, len(p.Histogram.Buckets))
+	}
+	for _, b := range p.Histogram.Buckets {
+		// See marshalHistogram for the meaning of the boundaries.
+		h.Buckets = append(h.Buckets, HistogramBucket{
+			Lower:          float64(b.Lower),
+			Upper:          float64(b.Upper),
+			LowerInclusive: b.Boundaries == 1 || b.Boundaries == 3,
+			UpperInclusive: b.Boundaries == 0 || b.Boundaries == 3,
+			Count:          float64(b.Count),
+		})
+	}
+	return h
+}
+
+// Histograms returns the series of a vector or matrix query result that
+// contain native histogram samples. Float samples are ignored, and series
+// without any native histogram samples are omitted. For other value types,
+// Histograms returns nil.
+func Histograms(v model.Value) []HistogramSeries {
+	var series []HistogramSeries
+	switch v := v.(type) {
+	case model.Vector:
+		for _, s := range v {
+			if s.Histogram == nil {
+				continue
+			}
+			series = append(series, HistogramSeries{
+				Metric:     s.Metric,
+				Histograms: []Histogram{NewHistogram(model.SampleHistogramPair{Timestamp: s.Timestamp, Histogram: s.Histogram})},
+			})
+		}
+	case model.Matrix:
+		for _, ss := range v {
+			if len(ss.Histograms) == 0 {
+				continue
+			}
+			hs := HistogramSeries{Metric: ss.Metric, Histograms: make([]Histogram, 0, len(ss.Histograms))}
+			for _, p := range ss.Histograms {
+				hs.Histograms = append(hs.Histograms, NewHistogram(p))
+			}
+			series = append(series, hs)
+		}
+	}
+	return series
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/prometheus/client_golang/api"
+)
+
+func TestQueryHistograms(t *testing.T) {
+	// Responses as returned by Prometheus 3 for an exponential histogram
+	// and a histogram with custom buckets, mixed with a float series.
+	responses := map[string]string{
+		"/api/v1/query": `{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"__name__":"rpc_duration_seconds","job":"a"},"histogram":[1700000000.5,{"count":"6","sum":"3.25","buckets":[[3,"-0.001","0.001","1"],[0,"0.5","0.7071067811865475","2"],[0,"1","1.414213562373095","3"]]}]},
+			{"metric":{"__name__":"up","job":"a"},"value":[1700000000.5,"1"]}
+		]}}`,
+		"/api/v1/query_range": `{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"__name__":"rpc_duration_seconds","job":"b"},"histograms":[[1700000000,{"count":"2","sum":"0.75","buckets":[[0,"-Inf","0.1","1"],[0,"0.5","+Inf","1"]]}],[1700000015,{"count":"0","sum":"0"}]]},
+			{"metric":{"__name__":"up","job":"b"},"values":[[1700000000,"1"]]}
+		]}}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(responses[req.URL.Path]))
+	}))
+	defer server.Close()
+	client, err := api.NewClient(api.Config{Address: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	promAPI := NewAPI(client)
+	ts := time.Unix(1700000000, 0)
+
+	v, _, err := promAPI.Query(context.Background(), "rpc_duration_seconds", ts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []HistogramSeries{{
+		Metric: model.Metric{"__name__": "rpc_duration_seconds", "job": "a"},
+		Histograms: []Histogram{{
+			Timestamp: 1700000000500,
+			Count:     6,
+			Sum:       3.25,
+			Buckets: []HistogramBucket{
+				{Lower: -0.001, Upper: 0.001, LowerInclusive: true, UpperInclusive: true, Count: 1},
+				{Lower: 0.5, Upper: 0.7071067811865475, UpperInclusive: true, Count: 2},
+				{Lower: 1, Upper: 1.414213562373095, UpperInclusive: true, Count: 3},
+			},
+		}},
+	}}
+	if got := Histograms(v); !reflect.DeepEqual(got, want) {
+		t.Errorf("vector: got %+v, want %+v", got, want)
+	}
+
+	v, _, err = promAPI.QueryRange(context.Background(), "rpc_duration_seconds", Range{Start: ts, End: ts.Add(15 * time.Second), Step: 15 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []HistogramSeries{{
+		Metric: model.Metric{"__name__": "rpc_duration_seconds", "job": "b"},
+		Histograms: []Histogram{
+			{
+				Timestamp: 1700000000000,
+				Count:     2,
+				Sum:       0.75,
+				Buckets: []HistogramBucket{
+					{Lower: math.Inf(-1), Upper: 0.1, UpperInclusive: true, Count: 1},
+					{Lower: 0.5, Upper: math.Inf(1), UpperInclusive: true, Count: 1},
+				},
+			},
+			{Timestamp: 1700000015000},
+		},
+	}}
+	if got := Histograms(v); !reflect.DeepEqual(got, want) {
+		t.Errorf("matrix: got %+v, want %+v", got, want)
+	}
+
+	if got := Histograms(&model.Scalar{}); got != nil {
+		t.Errorf("scalar: got %+v, want nil", got)
+	}
+}
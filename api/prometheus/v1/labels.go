@@ -0,0 +1,110 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/prometheus/client_golang/api/promql"
+)
+
+// LabelExplorer fetches label names and values of the series selected by
+// typed matchers, e.g. for building UIs to explore the label space:
+//
+//	e := &v1.LabelExplorer{API: promAPI}
+//	labels, _, err := e.All(ctx, start, end, promql.Eq("job", "node"))
+//
+// Zero start and end times select the default time range of the server.
+type LabelExplorer struct {
+	API API
+	// Concurrency is the maximum number of requests All sends at the same
+	// time. Defaults to 4.
+	Concurrency int
+}
+
+// Names returns the label names of the series matching all matchers, see
+// API.LabelNames.
+func (e *LabelExplorer) Names(ctx context.Context, startTime, endTime time.Time, matchers ...promql.Matcher) ([]string, Warnings, error) {
+	return e.API.LabelNames(ctx, seriesMatches(matchers), startTime, endTime)
+}
+
+// Values returns the values of the label of the series matching all matchers,
+// see API.LabelValues.
+func (e *LabelExplorer) Values(ctx context.Context, label string, startTime, endTime time.Time, matchers ...promql.Matcher) (model.LabelValues, Warnings, error) {
+	return e.API.LabelValues(ctx, label, seriesMatches(matchers), startTime, endTime)
+}
+
+// All returns the label names of the series matching all matchers, each with
+// its values. The values of the labels are fetched concurrently. If fetching
+// the values of some labels fails, the values of the other labels are returned
+// along with an error joining the errors of the failed labels.
+func (e *LabelExplorer) All(ctx context.Context, startTime, endTime time.Time, matchers ...promql.Matcher) (map[string]model.LabelValues, Warnings, error) {
+	names, warnings, err := e.Names(ctx, startTime, endTime, matchers...)
+	if err != nil {
+		return nil, warnings, err
+	}
+	concurrency := e.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		sem    = make(chan struct{}, concurrency)
+		errs   []error
+		result = make(map[string]model.LabelValues, len(names))
+	)
+	for _, name := range names {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return result, warnings, ctx.Err()
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			values, w, err := e.Values(ctx, name, startTime, endTime, matchers...)
+			mu.Lock()
+			defer mu.Unlock()
+			warnings = append(warnings, w...)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("label %s: %w", name, err))
+				return
+			}
+			result[name] = values
+		}()
+	}
+	wg.Wait()
+	return result, warnings, errors.Join(errs...)
+}
+
+// seriesMatches returns the series selector consisting of the matchers, or
+// nil to select all series if there are no matchers.
+func seriesMatches(matchers []promql.Matcher) []string {
+	if len(matchers) == 0 {
+		return nil
+	}
+	return []string{promql.NewSelector("").Where(matchers...).String()}
+}
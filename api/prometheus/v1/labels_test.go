@@ -0,0 +1,91 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/prometheus/client_golang/api/promql"
+)
+
+// labelsAPI serves fixed label values and records the matchers of the
+// requests.
+type labelsAPI struct {
+	API
+	values map[string]model.LabelValues
+
+	mu      sync.Mutex
+	matches [][]string
+}
+
+func (a *labelsAPI) LabelNames(_ context.Context, matches []string, _, _ time.Time, _ ...Option) ([]string, Warnings, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.matches = append(a.matches, matches)
+	return []string{"broken", "instance", "job"}, Warnings{"names warning"}, nil
+}
+
+func (a *labelsAPI) LabelValues(_ context.Context, label string, matches []string, _, _ time.Time, _ ...Option) (model.LabelValues, Warnings, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.matches = append(a.matches, matches)
+	values, ok := a.values[label]
+	if !ok {
+		return nil, nil, errors.New("unavailable")
+	}
+	return values, nil, nil
+}
+
+func TestLabelExplorer(t *testing.T) {
+	a := &labelsAPI{values: map[string]model.LabelValues{
+		"instance": {"a:9100", "b:9100"},
+		"job":      {"node"},
+	}}
+	e := &LabelExplorer{API: a, Concurrency: 2}
+
+	all, warnings, err := e.All(context.Background(), time.Time{}, time.Time{}, promql.Eq("job", "node"), promql.Re("instance", ".+"))
+	if err == nil {
+		t.Error("expected error for label broken")
+	}
+	want := map[string]model.LabelValues{
+		"instance": {"a:9100", "b:9100"},
+		"job":      {"node"},
+	}
+	if !reflect.DeepEqual(all, want) {
+		t.Errorf("got %v, want %v", all, want)
+	}
+	if !reflect.DeepEqual(warnings, Warnings{"names warning"}) {
+		t.Errorf("got warnings %v", warnings)
+	}
+	for _, m := range a.matches {
+		if want := []string{`{job="node", instance=~".+"}`}; !reflect.DeepEqual(m, want) {
+			t.Errorf("got matches %q, want %q", m, want)
+		}
+	}
+
+	a.matches = nil
+	if _, _, err := e.Values(context.Background(), "job", time.Time{}, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if a.matches[0] != nil {
+		t.Errorf("got matches %q without matchers, want nil", a.matches[0])
+	}
+}
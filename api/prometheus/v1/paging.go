@@ -0,0 +1,124 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// truncationWarning is the warning Prometheus (v2.49+) adds to responses that
+// were truncated because of the limit parameter.
+const truncationWarning = "results truncated due to limit"
+
+const defaultMinWindow = time.Minute
+
+// Truncated reports whether the warnings of a response indicate that the
+// response was truncated because of a limit, see WithLimit.
+func Truncated(warnings Warnings) bool {
+	for _, w := range warnings {
+		if w == truncationWarning {
+			return true
+		}
+	}
+	return false
+}
+
+// Pager fetches the complete result of the Series endpoint without any single
+// response exceeding PageSize series, for working with very large numbers of
+// series.
+//
+// As Prometheus doesn't support pagination cursors, the Pager requests the
+// provided time range with WithLimit(PageSize) and, if the response was
+// truncated, splits the range in halves and requests each of them, until no
+// response is truncated anymore. The Pager returns an error if a range of
+// MinWindow still has more than PageSize series. A server that doesn't support
+// the limit parameter returns all series at once.
+//
+// The Pager doesn't support the LabelNames and LabelValues endpoints: Prometheus
+// only filters label names and values by time with the granularity of its TSDB
+// blocks (usually 2h), so splitting the time range doesn't shrink their
+// results.
+type Pager struct {
+	API API
+	// PageSize is the maximum number of series per response. Mandatory.
+	PageSize uint64
+	// MinWindow is the smallest time range the Pager requests. Defaults to
+	// one minute.
+	MinWindow time.Duration
+}
+
+// Series returns all series matching matches in the time range, see
+// API.Series. The series are deduplicated and sorted, and the warnings of all
+// responses are merged.
+func (p *Pager) Series(ctx context.Context, matches []string, startTime, endTime time.Time) ([]model.LabelSet, Warnings, error) {
+	if p.PageSize == 0 {
+		return nil, nil, errors.New("page size must be positive")
+	}
+	if startTime.IsZero() || endTime.IsZero() {
+		return nil, nil, errors.New("paging requires a start and end time")
+	}
+	minWindow := p.MinWindow
+	if minWindow <= 0 {
+		minWindow = defaultMinWindow
+	}
+
+	var (
+		series   []model.LabelSet
+		seen     = map[model.Fingerprint]struct{}{}
+		warnings Warnings
+		seenWarn = map[string]struct{}{}
+		page     func(start, end time.Time) error
+	)
+	// page fetches the time range from start to end, splitting it
+	// recursively while the responses are truncated.
+	page = func(start, end time.Time) error {
+		result, w, err := p.API.Series(ctx, matches, start, end, WithLimit(p.PageSize))
+		if err != nil {
+			return err
+		}
+		if Truncated(w) {
+			if end.Sub(start) <= minWindow {
+				return fmt.Errorf("more than %d series between %s and %s, increase the page size", p.PageSize, start.Format(time.RFC3339), end.Format(time.RFC3339))
+			}
+			mid := start.Add(end.Sub(start) / 2)
+			if err := page(start, mid); err != nil {
+				return err
+			}
+			return page(mid, end)
+		}
+		for _, warning := range w {
+			if _, ok := seenWarn[warning]; !ok {
+				seenWarn[warning] = struct{}{}
+				warnings = append(warnings, warning)
+			}
+		}
+		for _, ls := range result {
+			fp := ls.Fingerprint()
+			if _, ok := seen[fp]; !ok {
+				seen[fp] = struct{}{}
+				series = append(series, ls)
+			}
+		}
+		return nil
+	}
+	err := page(startTime, endTime)
+	sort.Slice(series, func(i, j int) bool { return series[i].Before(series[j]) })
+	return series, warnings, err
+}
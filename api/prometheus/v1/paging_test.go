@@ -0,0 +1,101 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// pagedAPI serves series that exist at a given time, truncating responses to
+// the limit like Prometheus does.
+type pagedAPI struct {
+	API
+	series   map[model.LabelValue]time.Time
+	requests int
+}
+
+func (a *pagedAPI) Series(_ context.Context, _ []string, start, end time.Time, opts ...Option) ([]model.LabelSet, Warnings, error) {
+	a.requests++
+	o := &apiOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	var series []model.LabelSet
+	for instance, ts := range a.series {
+		if !ts.Before(start) && !ts.After(end) {
+			series = append(series, model.LabelSet{model.MetricNameLabel: "up", "instance": instance})
+		}
+	}
+	warnings := Warnings{"some warning"}
+	if o.limit > 0 && uint64(len(series)) > o.limit {
+		series = series[:o.limit]
+		warnings = append(warnings, truncationWarning)
+	}
+	return series, warnings, nil
+}
+
+func TestPagerSeries(t *testing.T) {
+	start := time.Unix(0, 0)
+	a := &pagedAPI{series: map[model.LabelValue]time.Time{
+		"a": start,
+		"b": start.Add(time.Hour),
+		"c": start.Add(2 * time.Hour),
+		"d": start.Add(3 * time.Hour),
+		"e": start.Add(4 * time.Hour),
+	}}
+	p := &Pager{API: a, PageSize: 2}
+
+	series, warnings, err := p.Series(context.Background(), []string{"up"}, start, start.Add(4*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var instances []model.LabelValue
+	for _, ls := range series {
+		instances = append(instances, ls["instance"])
+	}
+	if want := []model.LabelValue{"a", "b", "c", "d", "e"}; !reflect.DeepEqual(instances, want) {
+		t.Errorf("got instances %v, want %v", instances, want)
+	}
+	if want := (Warnings{"some warning"}); !reflect.DeepEqual(warnings, want) {
+		t.Errorf("got warnings %v, want %v", warnings, want)
+	}
+	if a.requests < 3 {
+		t.Errorf("got %d requests, want the range to be split", a.requests)
+	}
+
+	// Too many series at the same time can't be paged.
+	a.series["f"] = start
+	a.series["g"] = start
+	if _, _, err := p.Series(context.Background(), []string{"up"}, start, start.Add(4*time.Hour)); err == nil {
+		t.Error("expected error for too many series in the minimum window")
+	}
+
+	if _, _, err := p.Series(context.Background(), []string{"up"}, time.Time{}, start); err == nil {
+		t.Error("expected error for missing start time")
+	}
+}
+
+func TestTruncated(t *testing.T) {
+	if Truncated(Warnings{"foo"}) {
+		t.Error("unexpected truncation")
+	}
+	if !Truncated(Warnings{"foo", "results truncated due to limit"}) {
+		t.Error("expected truncation")
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"errors"
+	"strconv"
+
+	json "github.com/json-iterator/go"
+	"github.com/prometheus/common/model"
+)
+
+// QueryStats are the statistics Prometheus reports about the evaluation of a
+// query, see WithStats.
+type QueryStats struct {
+	Timings QueryTimings `json:"timings"`
+	Samples QuerySamples `json:"samples"`
+}
+
+// QueryTimings are the times in seconds spent in the stages of a query
+// evaluation.
+type QueryTimings struct {
+	EvalTotalTime        float64 `json:"evalTotalTime"`
+	ResultSortTime       float64 `json:"resultSortTime"`
+	QueryPreparationTime float64 `json:"queryPreparationTime"`
+	InnerEvalTime        float64 `json:"innerEvalTime"`
+	ExecQueueTime        float64 `json:"execQueueTime"`
+	ExecTotalTime        float64 `json:"execTotalTime"`
+}
+
+// QuerySamples are the numbers of samples loaded by a query evaluation.
+type QuerySamples struct {
+	TotalQueryableSamples int64 `json:"totalQueryableSamples"`
+	PeakSamples           int64 `json:"peakSamples"`
+	// TotalQueryableSamplesPerStep is only reported by range queries.
+	TotalQueryableSamplesPerStep []StepStat `json:"totalQueryableSamplesPerStep,omitempty"`
+}
+
+// StepStat is a statistic of a step of a range query.
+type StepStat struct {
+	Timestamp model.Time
+	Value     int64
+}
+
+// UnmarshalJSON decodes a step statistic from [timestamp, value].
+func (s *StepStat) UnmarshalJSON(b []byte) error {
+	var v [2]json.RawMessage
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	if v[0] == nil || v[1] == nil {
+		return errors.New("step statistic must be [timestamp, value]")
+	}
+	if err := s.Timestamp.UnmarshalJSON(v[0]); err != nil {
+		return err
+	}
+	value, err := strconv.ParseInt(string(v[1]), 10, 64)
+	if err != nil {
+		return err
+	}
+	s.Value = value
+	return nil
+}
+
+// MarshalJSON encodes a step statistic as [timestamp, value].
+func (s StepStat) MarshalJSON() ([]byte, error) {
+	b := []byte{'['}
+	b = append(b, s.Timestamp.String()...)
+	b = append(b, ',')
+	b = strconv.AppendInt(b, s.Value, 10)
+	return append(b, ']'), nil
+}
+
+// WithStats makes Query and QueryRange request all query statistics from
+// the server and store them in stats. stats is left unchanged if the server
+// doesn't report statistics.
+func WithStats(stats *QueryStats) Option {
+	return func(o *apiOptions) {
+		o.stats = stats
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/prometheus/client_golang/api"
+)
+
+func TestQueryStats(t *testing.T) {
+	var form map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		req.ParseForm()
+		form = req.Form
+		w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[],"stats":{
+			"timings":{"evalTotalTime":0.5,"resultSortTime":0,"queryPreparationTime":0.25,"innerEvalTime":0.125,"execQueueTime":0.0625,"execTotalTime":1},
+			"samples":{"totalQueryableSamples":30,"peakSamples":12,"totalQueryableSamplesPerStep":[[1700000000,10],[1700000015.5,20]]}}}}`))
+	}))
+	defer server.Close()
+	client, err := api.NewClient(api.Config{Address: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	promAPI := NewAPI(client)
+
+	var stats QueryStats
+	ts := time.Unix(1700000000, 0)
+	if _, _, err := promAPI.QueryRange(context.Background(), "up", Range{Start: ts, End: ts.Add(15 * time.Second), Step: 15 * time.Second}, WithStats(&stats)); err != nil {
+		t.Fatal(err)
+	}
+	if got := form["stats"]; !reflect.DeepEqual(got, []string{"all"}) {
+		t.Errorf("got stats parameter %v, want all", got)
+	}
+	want := QueryStats{
+		Timings: QueryTimings{EvalTotalTime: 0.5, QueryPreparationTime: 0.25, InnerEvalTime: 0.125, ExecQueueTime: 0.0625, ExecTotalTime: 1},
+		Samples: QuerySamples{
+			TotalQueryableSamples:        30,
+			PeakSamples:                  12,
+			TotalQueryableSamplesPerStep: []StepStat{{Timestamp: 1700000000000, Value: 10}, {Timestamp: 1700000015500, Value: 20}},
+		},
+	}
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("got stats %+v, want %+v", stats, want)
+	}
+
+	// Without WithStats, no statistics are requested.
+	if _, _, err := promAPI.Query(context.Background(), "up", ts); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := form["stats"]; ok {
+		t.Errorf("got unexpected stats parameter %v", got)
+	}
+}
+
+func TestFilterExemplars(t *testing.T) {
+	res := []ExemplarQueryResult{
+		{SeriesLabels: model.LabelSet{"job": "a"}, Exemplars: []Exemplar{{Labels: model.LabelSet{"trace_id": "1"}}, {Labels: model.LabelSet{"trace_id": "2"}}}},
+		{SeriesLabels: model.LabelSet{"job": "b"}, Exemplars: []Exemplar{{Labels: model.LabelSet{"trace_id": "3"}}, {Labels: model.LabelSet{"trace_id": "2"}}}},
+	}
+	traceID := func(id model.LabelValue) Option {
+		return WithExemplarFilter(func(e Exemplar) bool { return e.Labels["trace_id"] == id })
+	}
+
+	if got := filterExemplars(res, newAPIOptions(nil)); !reflect.DeepEqual(got, res) {
+		t.Errorf("got %v, want unfiltered result", got)
+	}
+	want := []ExemplarQueryResult{
+		{SeriesLabels: model.LabelSet{"job": "a"}, Exemplars: []Exemplar{{Labels: model.LabelSet{"trace_id": "2"}}}},
+		{SeriesLabels: model.LabelSet{"job": "b"}, Exemplars: []Exemplar{{Labels: model.LabelSet{"trace_id": "2"}}}},
+	}
+	if got := filterExemplars(res, newAPIOptions([]Option{traceID("2")})); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	want = []ExemplarQueryResult{
+		{SeriesLabels: model.LabelSet{"job": "a"}, Exemplars: []Exemplar{{Labels: model.LabelSet{"trace_id": "1"}}, {Labels: model.LabelSet{"trace_id": "2"}}}},
+		{SeriesLabels: model.LabelSet{"job": "b"}, Exemplars: []Exemplar{{Labels: model.LabelSet{"trace_id": "3"}}}},
+	}
+	if got := filterExemplars(res, newAPIOptions([]Option{WithLimit(3)})); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
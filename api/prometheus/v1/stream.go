@@ -0,0 +1,283 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	json "github.com/json-iterator/go"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/prometheus/client_golang/api"
+)
+
+// streamBufferSize is the size of the read buffer for streamed responses.
+const streamBufferSize = 32 * 1024
+
+// SeriesIterator iterates over the series of a range query result, decoding
+// one series at a time, so that memory usage is bounded by the largest series
+// rather than the whole result. Use QueryRangeStream to create one:
+//
+//	it, err := promAPI.QueryRangeStream(ctx, query, r)
+//	if err != nil {
+//		return err
+//	}
+//	defer it.Close()
+//	for it.Next() {
+//		series := it.At()
+//		// ...
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+//
+// The response is only decoded incrementally if the underlying api.Client
+// implements api.StreamingClient (as the one returned by api.NewClient does).
+// Otherwise, the response body is buffered as usual, and only the decoding of
+// the series happens one at a time.
+type SeriesIterator struct {
+	iter *json.Iterator
+	body io.Closer
+	// envelope is true if iter reads the complete API response rather than
+	// just its data.
+	envelope bool
+
+	cur         *model.SampleStream
+	annotations Annotations
+	err         error
+	done        bool
+
+	// Fields of the API response envelope.
+	status, errorType, errorMsg string
+}
+
+func (h *httpAPI) QueryRangeStream(ctx context.Context, query string, r Range, opts ...Option) (*SeriesIterator, error) {
+	u, q := h.queryRangeRequest(query, r, opts)
+
+	if impl, ok := h.client.(*apiClientImpl); ok {
+		if sc, ok := impl.client.(api.StreamingClient); ok {
+			resp, err := doStreamGetFallback(ctx, sc, u, q)
+			if err != nil {
+				return nil, err
+			}
+			if resp.StatusCode/100 != 2 {
+				defer resp.Body.Close()
+				body, err := io.ReadAll(resp.Body)
+				if err != nil {
+					return nil, err
+				}
+				if _, _, _, err := processResponse(resp, body); err != nil {
+					return nil, err
+				}
+				return nil, &Error{
+					Type: ErrBadResponse,
+					Msg:  fmt.Sprintf("bad response code %d", resp.StatusCode),
+				}
+			}
+			it := &SeriesIterator{
+				iter:     json.Parse(json.ConfigDefault, resp.Body, streamBufferSize),
+				body:     resp.Body,
+				envelope: true,
+			}
+			it.start()
+			return it, nil
+		}
+	}
+
+	_, body, annotations, err := h.client.DoGetFallback(ctx, u, q)
+	if err != nil {
+		return nil, err
+	}
+	it := &SeriesIterator{
+		iter:        json.ParseBytes(json.ConfigDefault, body),
+		annotations: annotations,
+	}
+	it.start()
+	return it, nil
+}
+
+// doStreamGetFallback is like apiClientImpl.DoGetFallback, but returns the
+// response with its body unread.
+func doStreamGetFallback(ctx context.Context, c api.StreamingClient, u *url.URL, args url.Values) (*http.Response, error) {
+	encodedArgs := args.Encode()
+	req, err := newPostRequest(u, encodedArgs)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.DoStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented {
+		io.Copy(io.Discard, resp.Body) //nolint:errcheck // Only draining for connection reuse.
+		resp.Body.Close()
+		u.RawQuery = encodedArgs
+		req, err = http.NewRequest(http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		return c.DoStream(ctx, req)
+	}
+	return resp, nil
+}
+
+// start positions the iterator at the beginning of the result array.
+func (it *SeriesIterator) start() {
+	if it.envelope {
+		if !it.readEnvelopeUntil("data") {
+			it.finish()
+			return
+		}
+	}
+	for key := it.iter.ReadObject(); key != ""; key = it.iter.ReadObject() {
+		switch key {
+		case "resultType":
+			if t := it.iter.ReadString(); t != model.ValMatrix.String() {
+				it.fail(fmt.Errorf("unexpected value type %q", t))
+				return
+			}
+		case "result":
+			return
+		default:
+			it.iter.Skip()
+		}
+	}
+	it.fail(errors.New("no result in response"))
+}
+
+// readEnvelopeUntil reads the fields of the API response envelope until the
+// field with the provided name. It returns false if the envelope doesn't
+// contain the field.
+func (it *SeriesIterator) readEnvelopeUntil(field string) bool {
+	for key := it.iter.ReadObject(); key != ""; key = it.iter.ReadObject() {
+		switch key {
+		case field:
+			return true
+		case "status":
+			it.status = it.iter.ReadString()
+		case "errorType":
+			it.errorType = it.iter.ReadString()
+		case "error":
+			it.errorMsg = it.iter.ReadString()
+		case "warnings":
+			for it.iter.ReadArray() {
+				it.annotations = append(it.annotations, Annotation{Level: AnnotationLevelWarning, Msg: it.iter.ReadString()})
+			}
+		case "infos":
+			for it.iter.ReadArray() {
+				it.annotations = append(it.annotations, Annotation{Level: AnnotationLevelInfo, Msg: it.iter.ReadString()})
+			}
+		default:
+			it.iter.Skip()
+		}
+	}
+	return false
+}
+
+// Next advances the iterator to the next series. It returns false if there
+// are no more series or an error occurred, see Err.
+func (it *SeriesIterator) Next() bool {
+	if it.done {
+		return false
+	}
+	if !it.iter.ReadArray() {
+		if it.iter.Error != nil {
+			it.fail(it.iter.Error)
+			return false
+		}
+		// Read the rest of the data and the envelope.
+		for key := it.iter.ReadObject(); key != ""; key = it.iter.ReadObject() {
+			it.iter.Skip()
+		}
+		if it.envelope {
+			it.readEnvelopeUntil("")
+		}
+		it.finish()
+		return false
+	}
+	ss := &model.SampleStream{}
+	it.iter.ReadVal(ss)
+	if it.iter.Error != nil {
+		it.fail(it.iter.Error)
+		return false
+	}
+	it.cur = ss
+	return true
+}
+
+// At returns the current series.
+func (it *SeriesIterator) At() *model.SampleStream {
+	return it.cur
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *SeriesIterator) Err() error {
+	return it.err
+}
+
+// Warnings returns the warnings of the response. As Prometheus sends them after
+// the result, they are only complete once Next has returned false.
+func (it *SeriesIterator) Warnings() Warnings {
+	return it.annotations.Warnings()
+}
+
+// Annotations returns all annotations of the response, including the info
+// annotations. Like the warnings, they are only complete once Next has
+// returned false.
+func (it *SeriesIterator) Annotations() Annotations {
+	return it.annotations
+}
+
+// Close closes the underlying response body. It is safe to call Close multiple
+// times and after the iteration has finished.
+func (it *SeriesIterator) Close() error {
+	it.done = true
+	it.cur = nil
+	if it.body == nil {
+		return nil
+	}
+	err := it.body.Close()
+	it.body = nil
+	return err
+}
+
+// finish ends the iteration, checking the iterator and envelope for errors.
+func (it *SeriesIterator) finish() {
+	if it.iter.Error != nil && !errors.Is(it.iter.Error, io.EOF) {
+		it.fail(it.iter.Error)
+		return
+	}
+	if it.envelope && it.status == "error" {
+		it.fail(&Error{Type: ErrorType(it.errorType), Msg: it.errorMsg})
+		return
+	}
+	it.Close()
+}
+
+func (it *SeriesIterator) fail(err error) {
+	if it.err == nil {
+		it.err = &Error{Type: ErrBadResponse, Msg: err.Error()}
+		var apiErr *Error
+		if errors.As(err, &apiErr) {
+			it.err = apiErr
+		}
+	}
+	it.Close()
+}
@@ -0,0 +1,144 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/prometheus/client_golang/api"
+)
+
+func TestQueryRangeStream(t *testing.T) {
+	const matrixResponse = `{
+		"status": "success",
+		"data": {
+			"resultType": "matrix",
+			"result": [
+				{"metric": {"__name__": "up", "job": "a"}, "values": [[1, "1"], [2, "0"]]},
+				{"metric": {"__name__": "up", "job": "b"}, "values": [[1, "1"]]}
+			]
+		},
+		"warnings": ["something is fishy"]
+	}`
+	responses := map[string]struct {
+		code int
+		body string
+	}{
+		"/api/v1/query_range":           {http.StatusOK, matrixResponse},
+		"/get/api/v1/query_range":       {http.StatusOK, matrixResponse},
+		"/vector/api/v1/query_range":    {http.StatusOK, `{"status": "success", "data": {"resultType": "vector", "result": []}}`},
+		"/error/api/v1/query_range":     {http.StatusUnprocessableEntity, `{"status": "error", "errorType": "execution", "error": "boom"}`},
+		"/truncated/api/v1/query_range": {http.StatusOK, matrixResponse[:150]},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/get/api/v1/query_range" && req.Method == http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		resp := responses[req.URL.Path]
+		w.WriteHeader(resp.code)
+		w.Write([]byte(resp.body))
+	}))
+	defer server.Close()
+
+	wantSeries := []*model.SampleStream{
+		{
+			Metric: model.Metric{"__name__": "up", "job": "a"},
+			Values: []model.SamplePair{{Timestamp: 1000, Value: 1}, {Timestamp: 2000, Value: 0}},
+		},
+		{
+			Metric: model.Metric{"__name__": "up", "job": "b"},
+			Values: []model.SamplePair{{Timestamp: 1000, Value: 1}},
+		},
+	}
+	r := Range{Start: time.Unix(1, 0), End: time.Unix(2, 0), Step: time.Second}
+
+	newAPI := func(path string, streaming bool) API {
+		if !streaming {
+			return &httpAPI{client: &apiClientImpl{client: &bufferingClient{server.URL + path}}}
+		}
+		client, err := api.NewClient(api.Config{Address: server.URL + path})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return NewAPI(client)
+	}
+
+	for _, streaming := range []bool{true, false} {
+		for _, path := range []string{"", "/get"} {
+			it, err := newAPI(path, streaming).QueryRangeStream(context.Background(), "up", r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []*model.SampleStream
+			for it.Next() {
+				got = append(got, it.At())
+			}
+			if err := it.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, wantSeries) {
+				t.Errorf("streaming=%v: got series %v, want %v", streaming, got, wantSeries)
+			}
+			if want := (Warnings{"something is fishy"}); !reflect.DeepEqual(it.Warnings(), want) {
+				t.Errorf("streaming=%v: got warnings %v, want %v", streaming, it.Warnings(), want)
+			}
+			if err := it.Close(); err != nil {
+				t.Error(err)
+			}
+		}
+	}
+
+	if _, err := newAPI("/error", true).QueryRangeStream(context.Background(), "up", r); err == nil {
+		t.Error("expected error for error response")
+	} else if apiErr := (*Error)(nil); !errors.As(err, &apiErr) || apiErr.Type != ErrExec {
+		t.Errorf("got error %v, want execution error", err)
+	}
+
+	for _, path := range []string{"/vector", "/truncated"} {
+		it, err := newAPI(path, true).QueryRangeStream(context.Background(), "up", r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for it.Next() {
+		}
+		if it.Err() == nil {
+			t.Errorf("%s: expected error", path)
+		}
+	}
+}
+
+// bufferingClient is an api.Client that doesn't implement api.StreamingClient.
+type bufferingClient struct {
+	address string
+}
+
+func (c *bufferingClient) URL(ep string, args map[string]string) *url.URL {
+	client, _ := api.NewClient(api.Config{Address: c.address})
+	return client.URL(ep, args)
+}
+
+func (c *bufferingClient) Do(ctx context.Context, req *http.Request) (*http.Response, []byte, error) {
+	client, _ := api.NewClient(api.Config{Address: c.address})
+	return client.Do(ctx, req)
+}
@@ -0,0 +1,250 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+var (
+	metricType          = reflect.TypeOf(model.Metric{})
+	labelSetType        = reflect.TypeOf(model.LabelSet{})
+	timeType            = reflect.TypeOf(time.Time{})
+	modelTimeType       = reflect.TypeOf(model.Time(0))
+	samplePairsType     = reflect.TypeOf([]model.SamplePair{})
+	sampleHistogramType = reflect.TypeOf(&model.SampleHistogram{})
+)
+
+// UnmarshalValue stores the samples of a vector or matrix query result in dst,
+// which must be a pointer to a slice of structs or of pointers to structs.
+// The struct fields are mapped with the "prom" key in the field tag:
+//
+//	// Field is set to the value of the label "instance".
+//	Field string `prom:"instance"`
+//	// Field is set to the sample value.
+//	Field float64 `prom:",value"`
+//	// Field is set to the sample timestamp.
+//	Field time.Time `prom:",timestamp"`
+//	// Field is set to the native histogram of the sample, if any.
+//	Field *model.SampleHistogram `prom:",histogram"`
+//	// Field is set to all labels of the series.
+//	Field model.Metric `prom:",metric"`
+//	// Field is set to all float samples of a matrix series.
+//	Field []model.SamplePair `prom:",values"`
+//
+// Label fields may be of any string, integer, float, or boolean type, in which
+// case the label value is parsed accordingly. Fields for missing labels are
+// left at their zero value. Value fields may be of any float type
+// (e.g. model.SampleValue), timestamp fields may be time.Time or model.Time.
+// Fields without a "prom" tag are ignored.
+//
+// For a vector, each sample results in one element of dst. For a matrix,
+// each series results in one element if the struct has a values field, and
+// each float sample results in one element otherwise.
+func UnmarshalValue(v model.Value, dst any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return errors.New("destination must be a non-nil pointer to a slice")
+	}
+	slice := rv.Elem()
+	elemType := slice.Type().Elem()
+	structType := elemType
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("slice element type %s is not a struct", elemType)
+	}
+	fields, err := structFields(structType)
+	if err != nil {
+		return err
+	}
+
+	appendElem := func(metric model.Metric, set func(s reflect.Value)) error {
+		s := reflect.New(structType).Elem()
+		if err := fields.setMetric(s, metric); err != nil {
+			return err
+		}
+		set(s)
+		if elemType.Kind() == reflect.Pointer {
+			s = s.Addr()
+		}
+		slice.Set(reflect.Append(slice, s))
+		return nil
+	}
+
+	switch v := v.(type) {
+	case model.Vector:
+		for _, sample := range v {
+			err := appendElem(sample.Metric, func(s reflect.Value) {
+				fields.setSample(s, sample.Timestamp, sample.Value, sample.Histogram)
+			})
+			if err != nil {
+				return err
+			}
+		}
+	case model.Matrix:
+		for _, ss := range v {
+			if fields.values >= 0 {
+				err := appendElem(ss.Metric, func(s reflect.Value) {
+					s.Field(fields.values).Set(reflect.ValueOf(ss.Values))
+				})
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			for _, p := range ss.Values {
+				err := appendElem(ss.Metric, func(s reflect.Value) {
+					fields.setSample(s, p.Timestamp, p.Value, nil)
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+	default:
+		return fmt.Errorf("cannot unmarshal value of type %T, only vectors and matrices are supported", v)
+	}
+	return nil
+}
+
+// fieldMapping contains the indexes of the mapped fields of a struct, with -1
+// for fields that don't exist.
+type fieldMapping struct {
+	labels                                      map[int]model.LabelName
+	value, timestamp, histogram, metric, values int
+}
+
+func structFields(t reflect.Type) (*fieldMapping, error) {
+	m := &fieldMapping{labels: map[int]model.LabelName{}, value: -1, timestamp: -1, histogram: -1, metric: -1, values: -1}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup("prom")
+		if !ok || tag == "-" || !f.IsExported() {
+			continue
+		}
+		name, option, _ := strings.Cut(tag, ",")
+		if name != "" {
+			if option != "" {
+				return nil, fmt.Errorf("field %s: label fields don't support options", f.Name)
+			}
+			switch f.Type.Kind() {
+			case reflect.String, reflect.Bool,
+				reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+				reflect.Float32, reflect.Float64:
+			default:
+				return nil, fmt.Errorf("field %s: unsupported type %s for label", f.Name, f.Type)
+			}
+			m.labels[i] = model.LabelName(name)
+			continue
+		}
+		var idx *int
+		switch option {
+		case "value":
+			idx, ok = &m.value, f.Type.Kind() == reflect.Float64 || f.Type.Kind() == reflect.Float32
+		case "timestamp":
+			idx, ok = &m.timestamp, f.Type == timeType || f.Type == modelTimeType
+		case "histogram":
+			idx, ok = &m.histogram, f.Type == sampleHistogramType
+		case "metric":
+			idx, ok = &m.metric, f.Type == metricType || f.Type == labelSetType
+		case "values":
+			idx, ok = &m.values, f.Type == samplePairsType
+		default:
+			return nil, fmt.Errorf("field %s: unknown option %q", f.Name, option)
+		}
+		if !ok {
+			return nil, fmt.Errorf("field %s: unsupported type %s for %s", f.Name, f.Type, option)
+		}
+		if *idx >= 0 {
+			return nil, fmt.Errorf("field %s: duplicate %s field", f.Name, option)
+		}
+		*idx = i
+	}
+	return m, nil
+}
+
+func (m *fieldMapping) setMetric(s reflect.Value, metric model.Metric) error {
+	if m.metric >= 0 {
+		s.Field(m.metric).Set(reflect.ValueOf(metric).Convert(s.Field(m.metric).Type()))
+	}
+	for i, name := range m.labels {
+		value, ok := metric[name]
+		if !ok {
+			continue
+		}
+		if err := setLabelField(s.Field(i), string(value)); err != nil {
+			return fmt.Errorf("label %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (m *fieldMapping) setSample(s reflect.Value, ts model.Time, value model.SampleValue, h *model.SampleHistogram) {
+	if m.value >= 0 {
+		s.Field(m.value).SetFloat(float64(value))
+	}
+	if m.timestamp >= 0 {
+		f := s.Field(m.timestamp)
+		if f.Type() == timeType {
+			f.Set(reflect.ValueOf(ts.Time()))
+		} else {
+			f.SetInt(int64(ts))
+		}
+	}
+	if m.histogram >= 0 && h != nil {
+		s.Field(m.histogram).Set(reflect.ValueOf(h))
+	}
+}
+
+func setLabelField(f reflect.Value, value string) error {
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		fl, err := strconv.ParseFloat(value, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetFloat(fl)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+func TestUnmarshalValue(t *testing.T) {
+	type target struct {
+		Instance string            `prom:"instance"`
+		Code     int               `prom:"code"`
+		Missing  model.LabelValue  `prom:"missing"`
+		Value    model.SampleValue `prom:",value"`
+		Time     time.Time         `prom:",timestamp"`
+		Ignored  string
+	}
+	vector := model.Vector{
+		{Metric: model.Metric{"instance": "a:9090", "code": "200"}, Value: 1, Timestamp: 1000},
+		{Metric: model.Metric{"instance": "b:9090", "code": "500"}, Value: 2, Timestamp: 2000},
+	}
+	var got []target
+	if err := UnmarshalValue(vector, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := []target{
+		{Instance: "a:9090", Code: 200, Value: 1, Time: time.UnixMilli(1000)},
+		{Instance: "b:9090", Code: 500, Value: 2, Time: time.UnixMilli(2000)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("vector: got %+v, want %+v", got, want)
+	}
+
+	matrix := model.Matrix{
+		{Metric: model.Metric{"job": "a"}, Values: []model.SamplePair{{Timestamp: 1000, Value: 1}, {Timestamp: 2000, Value: 2}}},
+	}
+	type sample struct {
+		Job  string     `prom:"job"`
+		Time model.Time `prom:",timestamp"`
+	}
+	var samples []*sample
+	if err := UnmarshalValue(matrix, &samples); err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 2 || *samples[1] != (sample{Job: "a", Time: 2000}) {
+		t.Errorf("matrix: got %+v", samples)
+	}
+
+	type series struct {
+		Metric model.Metric       `prom:",metric"`
+		Values []model.SamplePair `prom:",values"`
+	}
+	var s []series
+	if err := UnmarshalValue(matrix, &s); err != nil {
+		t.Fatal(err)
+	}
+	if want := []series{{Metric: matrix[0].Metric, Values: matrix[0].Values}}; !reflect.DeepEqual(s, want) {
+		t.Errorf("matrix series: got %+v, want %+v", s, want)
+	}
+
+	for name, dst := range map[string]any{
+		"not a pointer": got,
+		"not a struct":  &[]string{},
+		"unknown option": &[]struct {
+			F float64 `prom:",foo"`
+		}{},
+		"wrong value type": &[]struct {
+			F string `prom:",value"`
+		}{},
+		"wrong label type": &[]struct {
+			F []string `prom:"job"`
+		}{},
+		"invalid label int": &[]struct {
+			F int `prom:"instance"`
+		}{},
+	} {
+		if err := UnmarshalValue(vector, dst); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if err := UnmarshalValue(&model.Scalar{}, &got); err == nil {
+		t.Error("scalar: expected error")
+	}
+}
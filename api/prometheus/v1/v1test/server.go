@@ -0,0 +1,288 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package v1test provides a fake Prometheus HTTP API server for testing code
+// that uses package api/prometheus/v1.
+package v1test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/prometheus/client_golang/api"
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+)
+
+type queryResponse struct {
+	value    model.Value
+	warnings []string
+}
+
+// Server is a fake Prometheus server serving canned responses for the query,
+// query_range, series, labels, label values, and rules endpoints. The
+// responses use the same envelope, status codes, and warnings as Prometheus.
+//
+// The canned responses can be changed at any time. It is safe to use a Server
+// from multiple goroutines.
+type Server struct {
+	srv *httptest.Server
+
+	mu       sync.Mutex
+	queries  map[string]queryResponse
+	series   []model.LabelSet
+	rules    []v1.RuleGroup
+	errors   map[string]*v1.Error
+	requests []*http.Request
+}
+
+// NewServer starts and returns a new Server without any canned responses.
+// The caller should call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		queries: map[string]queryResponse{},
+		errors:  map[string]*v1.Error{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/query", s.handleQuery)
+	mux.HandleFunc("/api/v1/query_range", s.handleQuery)
+	mux.HandleFunc("/api/v1/series", s.handleSeries)
+	mux.HandleFunc("/api/v1/labels", s.handleLabels)
+	mux.HandleFunc("/api/v1/label/{name}/values", s.handleLabelValues)
+	mux.HandleFunc("/api/v1/rules", s.handleRules)
+	s.srv = httptest.NewServer(s.record(mux))
+	return s
+}
+
+// URL returns the address of the server, e.g. for api.Config.
+func (s *Server) URL() string {
+	return s.srv.URL
+}
+
+// API returns an API using the server.
+func (s *Server) API() v1.API {
+	client, err := api.NewClient(api.Config{Address: s.srv.URL})
+	if err != nil {
+		panic(err) // The URL of the server is always valid.
+	}
+	return v1.NewAPI(client)
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// SetQueryResult sets the result of the query for both instant and range
+// queries, independent of the requested time range. Queries without a result
+// fail with a bad_data error.
+func (s *Server) SetQueryResult(query string, value model.Value, warnings ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queries[query] = queryResponse{value: value, warnings: warnings}
+}
+
+// SetSeries sets the series returned by the series endpoint. The label names
+// and values endpoints return the labels of these series. Matchers and time
+// ranges in requests are ignored.
+func (s *Server) SetSeries(series ...model.LabelSet) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.series = series
+}
+
+// SetRules sets the rule groups returned by the rules endpoint.
+func (s *Server) SetRules(groups ...v1.RuleGroup) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules = groups
+}
+
+// SetError makes requests to the endpoint (e.g. "/api/v1/query") fail with the
+// provided error type and message, using the status code Prometheus uses for
+// that error type. An empty error type removes the error.
+func (s *Server) SetError(endpoint string, errType v1.ErrorType, msg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if errType == "" {
+		delete(s.errors, endpoint)
+		return
+	}
+	s.errors[endpoint] = &v1.Error{Type: errType, Msg: msg}
+}
+
+// Requests returns the requests received so far. Their form values are
+// parsed.
+func (s *Server) Requests() []*http.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*http.Request(nil), s.requests...)
+}
+
+func (s *Server) record(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			writeError(w, &v1.Error{Type: v1.ErrBadData, Msg: err.Error()})
+			return
+		}
+		s.mu.Lock()
+		s.requests = append(s.requests, r)
+		apiErr := s.errors[r.URL.Path]
+		s.mu.Unlock()
+		if apiErr != nil {
+			writeError(w, apiErr)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
+	query := r.Form.Get("query")
+	s.mu.Lock()
+	resp, ok := s.queries[query]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, &v1.Error{Type: v1.ErrBadData, Msg: fmt.Sprintf("v1test: no result for query %q", query)})
+		return
+	}
+	writeData(w, map[string]any{
+		"resultType": resp.value.Type().String(),
+		"result":     resp.value,
+	}, resp.warnings)
+}
+
+func (s *Server) handleSeries(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	series := append([]model.LabelSet{}, s.series...)
+	s.mu.Unlock()
+	writeData(w, series, nil)
+}
+
+func (s *Server) handleLabels(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen := map[model.LabelName]struct{}{}
+	names := []string{}
+	for _, ls := range s.series {
+		for name := range ls {
+			if _, ok := seen[name]; !ok {
+				seen[name] = struct{}{}
+				names = append(names, string(name))
+			}
+		}
+	}
+	sort.Strings(names)
+	writeData(w, names, nil)
+}
+
+func (s *Server) handleLabelValues(w http.ResponseWriter, r *http.Request) {
+	name := model.LabelName(r.PathValue("name"))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen := map[model.LabelValue]struct{}{}
+	values := model.LabelValues{}
+	for _, ls := range s.series {
+		if v, ok := ls[name]; ok {
+			if _, ok := seen[v]; !ok {
+				seen[v] = struct{}{}
+				values = append(values, v)
+			}
+		}
+	}
+	sort.Sort(values)
+	writeData(w, values, nil)
+}
+
+func (s *Server) handleRules(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	groups := make([]map[string]any, 0, len(s.rules))
+	for _, g := range s.rules {
+		rules := make([]map[string]any, 0, len(g.Rules))
+		for _, rule := range g.Rules {
+			var typ v1.RuleType
+			switch rule.(type) {
+			case v1.AlertingRule:
+				typ = v1.RuleTypeAlerting
+			case v1.RecordingRule:
+				typ = v1.RuleTypeRecording
+			default:
+				continue
+			}
+			// Add the type field, which the client requires to tell
+			// alerting and recording rules apart.
+			b, err := json.Marshal(rule)
+			if err != nil {
+				writeError(w, &v1.Error{Type: v1.ErrServer, Msg: err.Error()})
+				return
+			}
+			var m map[string]any
+			if err := json.Unmarshal(b, &m); err != nil {
+				writeError(w, &v1.Error{Type: v1.ErrServer, Msg: err.Error()})
+				return
+			}
+			m["type"] = typ
+			rules = append(rules, m)
+		}
+		groups = append(groups, map[string]any{
+			"name":     g.Name,
+			"file":     g.File,
+			"interval": g.Interval,
+			"rules":    rules,
+		})
+	}
+	writeData(w, map[string]any{"groups": groups}, nil)
+}
+
+// response is the envelope of all API responses.
+type response struct {
+	Status    string       `json:"status"`
+	Data      any          `json:"data,omitempty"`
+	ErrorType v1.ErrorType `json:"errorType,omitempty"`
+	Error     string       `json:"error,omitempty"`
+	Warnings  []string     `json:"warnings,omitempty"`
+}
+
+func writeData(w http.ResponseWriter, data any, warnings []string) {
+	writeJSON(w, http.StatusOK, response{Status: "success", Data: data, Warnings: warnings})
+}
+
+func writeError(w http.ResponseWriter, apiErr *v1.Error) {
+	code := http.StatusInternalServerError
+	switch apiErr.Type {
+	case v1.ErrBadData:
+		code = http.StatusBadRequest
+	case v1.ErrExec:
+		code = http.StatusUnprocessableEntity
+	case v1.ErrCanceled, v1.ErrTimeout:
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, response{Status: "error", ErrorType: apiErr.Type, Error: apiErr.Msg})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		code = http.StatusInternalServerError
+		b = fmt.Appendf(nil, `{"status":"error","errorType":"server_error","error":%q}`, err.Error())
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(b)
+}
@@ -0,0 +1,117 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+)
+
+func TestServer(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	promAPI := s.API()
+	ctx := context.Background()
+
+	vector := model.Vector{{Metric: model.Metric{"job": "a"}, Value: 1, Timestamp: 1000}}
+	s.SetQueryResult("up", vector, "something is fishy")
+	v, warnings, err := promAPI.Query(ctx, "up", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(v, vector) {
+		t.Errorf("got %v, want %v", v, vector)
+	}
+	if !reflect.DeepEqual(warnings, v1.Warnings{"something is fishy"}) {
+		t.Errorf("got warnings %v", warnings)
+	}
+
+	matrix := model.Matrix{{Metric: model.Metric{"job": "a"}, Values: []model.SamplePair{{Timestamp: 1000, Value: 1}}}}
+	s.SetQueryResult("rate(x[5m])", matrix)
+	v, _, err = promAPI.QueryRange(ctx, "rate(x[5m])", v1.Range{Start: time.Unix(0, 0), End: time.Unix(60, 0), Step: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(v, matrix) {
+		t.Errorf("got %v, want %v", v, matrix)
+	}
+
+	if _, _, err := promAPI.Query(ctx, "unknown", time.Now()); err == nil {
+		t.Error("expected error for unknown query")
+	}
+	s.SetError("/api/v1/query", v1.ErrTimeout, "query timed out")
+	if _, _, err := promAPI.Query(ctx, "up", time.Now()); !errors.Is(err, v1.ErrQueryTimeout) {
+		t.Errorf("got error %v, want query timeout", err)
+	}
+	s.SetError("/api/v1/query", "", "")
+	if _, _, err := promAPI.Query(ctx, "up", time.Now()); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	s.SetSeries(model.LabelSet{"__name__": "up", "job": "b"}, model.LabelSet{"__name__": "up", "job": "a", "instance": "x"})
+	names, _, err := promAPI.LabelNames(ctx, nil, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"__name__", "instance", "job"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("got label names %v, want %v", names, want)
+	}
+	values, _, err := promAPI.LabelValues(ctx, "job", nil, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (model.LabelValues{"a", "b"}); !reflect.DeepEqual(values, want) {
+		t.Errorf("got label values %v, want %v", values, want)
+	}
+	series, _, err := promAPI.Series(ctx, []string{"up"}, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(series) != 2 {
+		t.Errorf("got series %v", series)
+	}
+
+	s.SetRules(v1.RuleGroup{
+		Name:     "group",
+		Interval: 60,
+		Rules: v1.Rules{
+			v1.AlertingRule{Name: "HighErrorRate", Query: "errors > 1", Health: v1.RuleHealthGood, Labels: model.LabelSet{}, Annotations: model.LabelSet{}, Alerts: []*v1.Alert{}},
+			v1.RecordingRule{Name: "job:up:sum", Query: "sum by (job) (up)", Health: v1.RuleHealthGood},
+		},
+	})
+	rules, err := promAPI.Rules(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules.Groups) != 1 || len(rules.Groups[0].Rules) != 2 {
+		t.Fatalf("got rules %+v", rules)
+	}
+	if _, ok := rules.Groups[0].Rules[0].(v1.AlertingRule); !ok {
+		t.Errorf("got %T, want alerting rule", rules.Groups[0].Rules[0])
+	}
+	if _, ok := rules.Groups[0].Rules[1].(v1.RecordingRule); !ok {
+		t.Errorf("got %T, want recording rule", rules.Groups[0].Rules[1])
+	}
+
+	if got := len(s.Requests()); got != 9 {
+		t.Errorf("got %d requests, want 9", got)
+	}
+}
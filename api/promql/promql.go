@@ -0,0 +1,218 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package promql provides helpers to construct PromQL selectors safely, with
+// proper quoting of label values and names, instead of assembling them with
+// fmt.Sprintf:
+//
+//	sel := promql.NewSelector("http_requests_total").
+//		Where(promql.Eq("job", job), promql.ReAny("code", "500", "503")).
+//		Range(5 * time.Minute)
+//	query := "rate(" + sel.String() + ")"
+//
+// The resulting strings can be used as queries or as series matchers in the
+// API client of package api/prometheus/v1.
+package promql
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// MatchType is the operator of a label Matcher.
+type MatchType string
+
+// Possible MatchTypes.
+const (
+	MatchEqual     MatchType = "="
+	MatchNotEqual  MatchType = "!="
+	MatchRegexp    MatchType = "=~"
+	MatchNotRegexp MatchType = "!~"
+)
+
+// Matcher matches the value of the label with the given name.
+type Matcher struct {
+	Type  MatchType
+	Name  string
+	Value string
+}
+
+// Eq returns a Matcher for label values equal to value.
+func Eq(name, value string) Matcher {
+	return Matcher{Type: MatchEqual, Name: name, Value: value}
+}
+
+// Neq returns a Matcher for label values not equal to value.
+func Neq(name, value string) Matcher {
+	return Matcher{Type: MatchNotEqual, Name: name, Value: value}
+}
+
+// Re returns a Matcher for label values matching the regular expression re,
+// which is anchored at both ends by Prometheus. Use ReAny or EscapeRegexp to
+// match literal values.
+func Re(name, re string) Matcher {
+	return Matcher{Type: MatchRegexp, Name: name, Value: re}
+}
+
+// Nre returns a Matcher for label values not matching the regular expression
+// re.
+func Nre(name, re string) Matcher {
+	return Matcher{Type: MatchNotRegexp, Name: name, Value: re}
+}
+
+// ReAny returns a Matcher for label values equal to any of the provided
+// values, which are escaped as literals. Without values, the Matcher matches
+// nothing but the empty value, i.e. series without the label.
+func ReAny(name string, values ...string) Matcher {
+	escaped := make([]string, len(values))
+	for i, v := range values {
+		escaped[i] = EscapeRegexp(v)
+	}
+	return Re(name, strings.Join(escaped, "|"))
+}
+
+// String returns the Matcher in PromQL syntax. Label names that are not valid
+// in the legacy character set are quoted, which requires Prometheus v3.
+func (m Matcher) String() string {
+	return quoteLabelName(m.Name) + string(m.Type) + Quote(m.Value)
+}
+
+// Quote returns s as a PromQL string literal, with all special characters
+// escaped.
+func Quote(s string) string {
+	return strconv.Quote(s)
+}
+
+// EscapeRegexp escapes all regular expression metacharacters in s, so that the
+// result matches s literally.
+func EscapeRegexp(s string) string {
+	return regexp.QuoteMeta(s)
+}
+
+func quoteLabelName(name string) string {
+	if model.LabelName(name).IsValidLegacy() {
+		return name
+	}
+	return Quote(name)
+}
+
+// keywords are identifiers that have a special meaning in PromQL and thus
+// cannot be used as metric names in the short selector syntax.
+var keywords = map[string]struct{}{
+	"and": {}, "or": {}, "unless": {}, "atan2": {}, "bool": {}, "by": {}, "without": {},
+	"on": {}, "ignoring": {}, "group_left": {}, "group_right": {}, "offset": {},
+	"sum": {}, "avg": {}, "count": {}, "min": {}, "max": {}, "group": {}, "stddev": {},
+	"stdvar": {}, "topk": {}, "bottomk": {}, "count_values": {}, "quantile": {},
+	"limitk": {}, "limit_ratio": {}, "inf": {}, "nan": {},
+}
+
+// Selector is a builder for a vector selector, or a range vector selector if
+// a range is set. The zero value is an empty selector, which has to be given
+// at least one Matcher to be valid.
+type Selector struct {
+	name     string
+	matchers []Matcher
+	rng      time.Duration
+	offset   time.Duration
+	at       *time.Time
+}
+
+// NewSelector returns a Selector for the metric with the provided name. An
+// empty name results in a Selector that only consists of the Matchers added
+// with Where.
+func NewSelector(metricName string) *Selector {
+	return &Selector{name: metricName}
+}
+
+// Where adds Matchers to the Selector. For convenience, this method returns a
+// pointer to the Selector itself.
+func (s *Selector) Where(matchers ...Matcher) *Selector {
+	s.matchers = append(s.matchers, matchers...)
+	return s
+}
+
+// Range turns the Selector into a range vector selector over the provided
+// duration. For convenience, this method returns a pointer to the Selector
+// itself.
+func (s *Selector) Range(d time.Duration) *Selector {
+	s.rng = d
+	return s
+}
+
+// Offset sets the offset modifier of the Selector. A negative offset selects
+// data after the evaluation time. For convenience, this method returns a
+// pointer to the Selector itself.
+func (s *Selector) Offset(d time.Duration) *Selector {
+	s.offset = d
+	return s
+}
+
+// At sets the @ modifier of the Selector, evaluating it at the provided time
+// (with millisecond precision) instead of the evaluation time. For
+// convenience, this method returns a pointer to the Selector itself.
+func (s *Selector) At(t time.Time) *Selector {
+	s.at = &t
+	return s
+}
+
+// String returns the Selector in PromQL syntax. Metric names that can't be
+// written in the short syntax (like names with dots or PromQL keywords) are
+// matched with a __name__ matcher instead, which works with all Prometheus
+// versions.
+func (s *Selector) String() string {
+	var b strings.Builder
+	matchers := s.matchers
+	if s.name != "" {
+		if _, isKeyword := keywords[strings.ToLower(s.name)]; model.IsValidLegacyMetricName(s.name) && !isKeyword {
+			b.WriteString(s.name)
+		} else {
+			matchers = append([]Matcher{Eq(model.MetricNameLabel, s.name)}, matchers...)
+		}
+	}
+	if len(matchers) > 0 || b.Len() == 0 {
+		b.WriteByte('{')
+		for i, m := range matchers {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(m.String())
+		}
+		b.WriteByte('}')
+	}
+	if s.rng > 0 {
+		b.WriteString("[" + formatDuration(s.rng) + "]")
+	}
+	if s.offset != 0 {
+		b.WriteString(" offset ")
+		if s.offset < 0 {
+			b.WriteByte('-')
+		}
+		b.WriteString(formatDuration(s.offset.Abs()))
+	}
+	if s.at != nil {
+		b.WriteString(" @ " + strconv.FormatFloat(float64(s.at.UnixMilli())/1e3, 'f', -1, 64))
+	}
+	return b.String()
+}
+
+// formatDuration formats d as a PromQL duration, with millisecond precision.
+func formatDuration(d time.Duration) string {
+	if d < time.Millisecond {
+		d = time.Millisecond
+	}
+	return model.Duration(d).String()
+}
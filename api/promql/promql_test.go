@@ -0,0 +1,53 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promql
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSelector(t *testing.T) {
+	scenarios := []struct {
+		sel  *Selector
+		want string
+	}{
+		{NewSelector("up"), `up`},
+		{&Selector{}, `{}`},
+		{NewSelector("up").Where(Eq("job", `a"b\c`)), `up{job="a\"b\\c"}`},
+		{NewSelector("up").Where(Eq("job", "line\nbreak")), `up{job="line\nbreak"}`},
+		{NewSelector("up").Where(Neq("job", "a"), Re("instance", "b.*"), Nre("env", "dev")), `up{job!="a", instance=~"b.*", env!~"dev"}`},
+		{NewSelector("up").Where(ReAny("code", "5.0", "a|b")), `up{code=~"5\\.0|a\\|b"}`},
+		{NewSelector("my.metric").Where(Eq("my.label", "x")), `{__name__="my.metric", "my.label"="x"}`},
+		{NewSelector("sum"), `{__name__="sum"}`},
+		{NewSelector("").Where(Eq("job", "a")), `{job="a"}`},
+		{NewSelector("http_requests_total").Range(5 * time.Minute), `http_requests_total[5m]`},
+		{NewSelector("up").Range(90 * time.Second).Offset(-time.Hour), `up[1m30s] offset -1h`},
+		{NewSelector("up").At(time.UnixMilli(1700000000500)), `up @ 1700000000.5`},
+	}
+	for _, s := range scenarios {
+		if got := s.sel.String(); got != s.want {
+			t.Errorf("got %s, want %s", got, s.want)
+		}
+	}
+}
+
+func ExampleSelector() {
+	sel := NewSelector("http_requests_total").
+		Where(Eq("job", `api "v2"`), ReAny("code", "500", "503")).
+		Range(5 * time.Minute)
+	fmt.Println("rate(" + sel.String() + ")")
+	// Output: rate(http_requests_total{job="api \"v2\"", code=~"500|503"}[5m])
+}
@@ -0,0 +1,234 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remoteread
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/prometheus/common/model"
+)
+
+// ChunkEncoding is the encoding of a Chunk.
+type ChunkEncoding int
+
+// Chunk encodings of the Prometheus TSDB.
+const (
+	ChunkUnknown        ChunkEncoding = 0
+	ChunkXOR            ChunkEncoding = 1
+	ChunkHistogram      ChunkEncoding = 2
+	ChunkFloatHistogram ChunkEncoding = 3
+)
+
+func (e ChunkEncoding) String() string {
+	switch e {
+	case ChunkXOR:
+		return "XOR"
+	case ChunkHistogram:
+		return "histogram"
+	case ChunkFloatHistogram:
+		return "float histogram"
+	default:
+		return fmt.Sprintf("<unknown: %d>", int(e))
+	}
+}
+
+// Chunk is a chunk of samples of a series as stored in the TSDB of
+// Prometheus.
+type Chunk struct {
+	MinTime, MaxTime model.Time
+	Encoding         ChunkEncoding
+	// Data is the raw chunk, which can be decoded with Samples for XOR
+	// chunks.
+	Data []byte
+}
+
+// Samples decodes the float samples of an XOR chunk. Chunks with other
+// encodings result in an error.
+func (c Chunk) Samples() ([]model.SamplePair, error) {
+	if c.Encoding != ChunkXOR {
+		return nil, fmt.Errorf("cannot decode samples of %s chunk", c.Encoding)
+	}
+	return decodeXOR(c.Data)
+}
+
+// decodeXOR decodes a chunk in the XOR encoding of the Prometheus TSDB, which
+// starts with the number of samples as big-endian uint16 followed by the
+// Gorilla-compressed samples: Timestamps are delta-of-delta encoded with
+// variable-length buckets, and values are XORed with their predecessor.
+func decodeXOR(b []byte) ([]model.SamplePair, error) {
+	if len(b) < 2 {
+		return nil, errors.New("XOR chunk too short")
+	}
+	var (
+		n        = int(binary.BigEndian.Uint16(b))
+		r        = &bitReader{b: b[2:]}
+		samples  = make([]model.SamplePair, 0, n)
+		t        int64
+		tDelta   int64
+		v        float64
+		leading  uint8
+		trailing uint8
+		err      error
+	)
+	for i := 0; i < n; i++ {
+		switch i {
+		case 0:
+			if t, err = binary.ReadVarint(r); err != nil {
+				return nil, err
+			}
+			bits, err := r.readBits(64)
+			if err != nil {
+				return nil, err
+			}
+			v = math.Float64frombits(bits)
+		case 1:
+			d, err := binary.ReadUvarint(r)
+			if err != nil {
+				return nil, err
+			}
+			tDelta = int64(d)
+			t += tDelta
+			if err := r.readXORValue(&v, &leading, &trailing); err != nil {
+				return nil, err
+			}
+		default:
+			dod, err := r.readDoD()
+			if err != nil {
+				return nil, err
+			}
+			tDelta += dod
+			t += tDelta
+			if err := r.readXORValue(&v, &leading, &trailing); err != nil {
+				return nil, err
+			}
+		}
+		samples = append(samples, model.SamplePair{Timestamp: model.Time(t), Value: model.SampleValue(v)})
+	}
+	return samples, nil
+}
+
+// bitReader reads a stream of bits, most significant bit first.
+type bitReader struct {
+	b   []byte
+	pos uint // Position in bits.
+}
+
+func (r *bitReader) readBit() (bool, error) {
+	if r.pos >= uint(len(r.b))*8 {
+		return false, io.ErrUnexpectedEOF
+	}
+	bit := r.b[r.pos/8]&(0x80>>(r.pos%8)) != 0
+	r.pos++
+	return bit, nil
+}
+
+func (r *bitReader) readBits(n uint8) (uint64, error) {
+	var v uint64
+	for i := uint8(0); i < n; i++ {
+		bit, err := r.readBit()
+		if err != nil {
+			return 0, err
+		}
+		v <<= 1
+		if bit {
+			v |= 1
+		}
+	}
+	return v, nil
+}
+
+// ReadByte implements io.ByteReader for reading varints.
+func (r *bitReader) ReadByte() (byte, error) {
+	v, err := r.readBits(8)
+	return byte(v), err
+}
+
+// readDoD reads a delta of timestamp deltas, which is prefixed with 0, 10, 110,
+// 1110, or 1111 for a zero delta or one with 14, 17, 20, or 64 bits,
+// respectively.
+func (r *bitReader) readDoD() (int64, error) {
+	var prefix int
+	for ; prefix < 4; prefix++ {
+		bit, err := r.readBit()
+		if err != nil {
+			return 0, err
+		}
+		if !bit {
+			break
+		}
+	}
+	var size uint8
+	switch prefix {
+	case 0:
+		return 0, nil
+	case 1:
+		size = 14
+	case 2:
+		size = 17
+	case 3:
+		size = 20
+	default:
+		bits, err := r.readBits(64)
+		return int64(bits), err
+	}
+	bits, err := r.readBits(size)
+	if err != nil {
+		return 0, err
+	}
+	// Negative numbers are stored in two's complement of the given size.
+	if bits > 1<<(size-1) {
+		bits -= 1 << size
+	}
+	return int64(bits), nil
+}
+
+// readXORValue reads a value XORed with the previous value v. A 0 bit means
+// the value didn't change. Otherwise, the meaningful bits of the XOR follow,
+// either within the previous leading and trailing zeros (prefix 10), or with
+// new numbers of leading zeros and meaningful bits (prefix 11).
+func (r *bitReader) readXORValue(v *float64, leading, trailing *uint8) error {
+	bit, err := r.readBit()
+	if err != nil || !bit {
+		return err
+	}
+	if bit, err = r.readBit(); err != nil {
+		return err
+	}
+	if bit {
+		l, err := r.readBits(5)
+		if err != nil {
+			return err
+		}
+		m, err := r.readBits(6)
+		if err != nil {
+			return err
+		}
+		// Zero meaningful bits would be pointless and are used to
+		// encode 64 bits instead.
+		if m == 0 {
+			m = 64
+		}
+		*leading, *trailing = uint8(l), uint8(64-l-m)
+	}
+	bits, err := r.readBits(64 - *leading - *trailing)
+	if err != nil {
+		return err
+	}
+	*v = math.Float64frombits(math.Float64bits(*v) ^ bits<<*trailing)
+	return nil
+}
@@ -0,0 +1,323 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package remoteread provides a client for the Prometheus remote-read
+// protocol, which returns the raw series matching label matchers without
+// evaluating PromQL. Both response types are supported: Read returns sampled
+// responses, which are fully buffered, while ReadStream returns streamed
+// responses of XOR chunks, which are decoded incrementally.
+package remoteread
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/prometheus/client_golang/api"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+const (
+	versionHeader = "X-Prometheus-Remote-Read-Version"
+	version       = "0.1.0"
+
+	streamedContentType = "application/x-streamed-protobuf"
+
+	// maxFrameSize limits the size of a frame of a streamed response to
+	// protect against corrupted size prefixes. Prometheus sends frames of
+	// about 1MiB.
+	maxFrameSize = 64 << 20
+)
+
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// MatchType is the type of a label Matcher.
+type MatchType int
+
+// Possible MatchTypes.
+const (
+	MatchEqual MatchType = iota
+	MatchNotEqual
+	MatchRegexp
+	MatchNotRegexp
+)
+
+// Matcher matches the values of the label with the provided name.
+type Matcher struct {
+	Type  MatchType
+	Name  string
+	Value string
+}
+
+// Query selects the series matching all Matchers in the time range from
+// Start to End (both inclusive).
+type Query struct {
+	Start, End time.Time
+	Matchers   []Matcher
+	// Hints optionally describe the intended use of the data, which some
+	// backends use to downsample or pre-aggregate it.
+	Hints *Hints
+}
+
+// Hints describe the PromQL context of a Query, see Query.Hints.
+type Hints struct {
+	Step       time.Duration
+	Func       string
+	Start, End time.Time
+	Grouping   []string
+	By         bool
+	Range      time.Duration
+}
+
+// Series is a series of a sampled response.
+type Series struct {
+	Metric     model.Metric
+	Samples    []model.SamplePair
+	Histograms []HistogramSample
+}
+
+// HistogramSample is a native histogram sample. The histogram is represented
+// by the equivalent fields of the exposition format.
+type HistogramSample struct {
+	Timestamp model.Time
+	Histogram *dto.Histogram
+}
+
+// ChunkedSeries is a series of a streamed response. A series may be split
+// across several ChunkedSeries with the same Metric.
+type ChunkedSeries struct {
+	Metric model.Metric
+	Chunks []Chunk
+	// QueryIndex is the index of the Query the series belongs to.
+	QueryIndex int
+}
+
+// Config defines configuration parameters for a new Client.
+type Config struct {
+	// URL is the full URL of the remote-read endpoint, e.g.
+	// “http://prometheus:9090/api/v1/read”. Mandatory.
+	URL string
+
+	// Client is used to send the requests. If not provided, a client based
+	// on api.DefaultRoundTripper will be used.
+	Client *http.Client
+
+	// Header contains additional headers to set on every request, e.g. for
+	// authentication or tenancy.
+	Header http.Header
+}
+
+// Client reads series from a remote-read endpoint. It is safe to use a Client
+// from multiple goroutines.
+type Client struct {
+	url    string
+	client *http.Client
+	header http.Header
+}
+
+// NewClient returns a new Client.
+func NewClient(cfg Config) (*Client, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("remote-read URL is missing")
+	}
+	c := &Client{url: cfg.URL, client: cfg.Client, header: cfg.Header}
+	if c.client == nil {
+		c.client = &http.Client{Transport: api.DefaultRoundTripper}
+	}
+	return c, nil
+}
+
+// Read sends the queries and returns the series of a sampled response, with
+// one slice of series per query.
+func (c *Client) Read(ctx context.Context, queries ...Query) ([][]*Series, error) {
+	resp, err := c.do(ctx, queries, responseTypeSamples)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	compressed, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	b, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return nil, fmt.Errorf("decompressing response: %w", err)
+	}
+	results, err := decodeReadResponse(b)
+	if err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	if len(results) != len(queries) {
+		return nil, fmt.Errorf("got %d results for %d queries", len(results), len(queries))
+	}
+	return results, nil
+}
+
+// ReadStream sends the queries and returns an iterator over the series of a
+// streamed response of XOR chunks. The endpoint has to support streamed
+// responses, as Prometheus does since v2.13. The caller has to close the
+// iterator.
+func (c *Client) ReadStream(ctx context.Context, queries ...Query) (*ChunkedSeriesIterator, error) {
+	resp, err := c.do(ctx, queries, responseTypeStreamedXORChunks)
+	if err != nil {
+		return nil, err
+	}
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || mediaType != streamedContentType {
+		resp.Body.Close()
+		return nil, fmt.Errorf("remote-read endpoint doesn't support streamed responses, got content type %q", resp.Header.Get("Content-Type"))
+	}
+	return &ChunkedSeriesIterator{r: bufio.NewReader(resp.Body), body: resp.Body}, nil
+}
+
+// do sends a ReadRequest and returns the response if it is successful.
+func (c *Client) do(ctx context.Context, queries []Query, responseType uint64) (*http.Response, error) {
+	body := snappy.Encode(nil, encodeReadRequest(queries, responseType))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range c.header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set(versionHeader, version)
+	req.Header.Set("User-Agent", "client_golang/remoteread")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(resp.Body) // Ignore any further error as this is for an error message only.
+		return nil, fmt.Errorf("unexpected status code %d while reading from %s: %s", resp.StatusCode, c.url, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
+
+// ChunkedSeriesIterator iterates over the series of a streamed response,
+// decoding one frame at a time:
+//
+//	it, err := client.ReadStream(ctx, query)
+//	if err != nil {
+//		return err
+//	}
+//	defer it.Close()
+//	for it.Next() {
+//		series := it.At()
+//		// ...
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+type ChunkedSeriesIterator struct {
+	r    *bufio.Reader
+	body io.Closer
+
+	frame []*ChunkedSeries
+	cur   *ChunkedSeries
+	err   error
+}
+
+// Next advances the iterator to the next series. It returns false if there
+// are no more series or an error occurred, see Err.
+func (it *ChunkedSeriesIterator) Next() bool {
+	for len(it.frame) == 0 {
+		if it.body == nil {
+			return false
+		}
+		if err := it.readFrame(); err != nil {
+			if !errors.Is(err, io.EOF) {
+				it.err = err
+			}
+			it.Close()
+			return false
+		}
+	}
+	it.cur, it.frame = it.frame[0], it.frame[1:]
+	return true
+}
+
+// readFrame reads the next frame, which consists of its size as uvarint, its
+// CRC32 (Castagnoli) checksum as big-endian uint32, and a ChunkedReadResponse.
+func (it *ChunkedSeriesIterator) readFrame() error {
+	size, err := binary.ReadUvarint(it.r)
+	if err != nil {
+		return err
+	}
+	if size > maxFrameSize {
+		return fmt.Errorf("frame of %d bytes exceeds the maximum frame size of %d bytes", size, maxFrameSize)
+	}
+	var crc [4]byte
+	if _, err := io.ReadFull(it.r, crc[:]); err != nil {
+		return noEOF(err)
+	}
+	b := make([]byte, size)
+	if _, err := io.ReadFull(it.r, b); err != nil {
+		return noEOF(err)
+	}
+	if got, want := crc32.Checksum(b, castagnoliTable), binary.BigEndian.Uint32(crc[:]); got != want {
+		return fmt.Errorf("frame checksum mismatch: got %08x, want %08x", got, want)
+	}
+	it.frame, err = decodeChunkedReadResponse(b)
+	if err != nil {
+		return fmt.Errorf("decoding frame: %w", err)
+	}
+	return nil
+}
+
+// noEOF turns io.EOF in the middle of a frame into io.ErrUnexpectedEOF.
+func noEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// At returns the current series.
+func (it *ChunkedSeriesIterator) At() *ChunkedSeries {
+	return it.cur
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *ChunkedSeriesIterator) Err() error {
+	return it.err
+}
+
+// Close closes the underlying response body. It is safe to call Close multiple
+// times and after the iteration has finished.
+func (it *ChunkedSeriesIterator) Close() error {
+	it.frame = nil
+	if it.body == nil {
+		return nil
+	}
+	err := it.body.Close()
+	it.body = nil
+	return err
+}
@@ -0,0 +1,278 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remoteread
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"math"
+	"math/bits"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/prometheus/common/model"
+)
+
+func TestDecodeXOR(t *testing.T) {
+	want := []model.SamplePair{
+		{Timestamp: 1000, Value: 1},
+		{Timestamp: 2000, Value: 1},
+		{Timestamp: 3000, Value: 2.5},
+		{Timestamp: 3500, Value: -3},
+		{Timestamp: 3600, Value: -3},
+		{Timestamp: 200000, Value: 1e10},
+		{Timestamp: 300000, Value: 0.1},
+		{Timestamp: 1 << 40, Value: 0},
+	}
+	got, err := decodeXOR(encodeXOR(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got samples %v, want %v", got, want)
+	}
+
+	if _, err := decodeXOR(encodeXOR(want)[:10]); err == nil {
+		t.Error("expected error for truncated chunk")
+	}
+}
+
+func TestClientRead(t *testing.T) {
+	samples := []model.SamplePair{{Timestamp: 1000, Value: 1}, {Timestamp: 2000, Value: 2}}
+
+	var request []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		compressed, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if request, err = snappy.Decode(nil, compressed); err != nil {
+			t.Fatal(err)
+		}
+
+		series := appendLabel(nil, chunkedSeriesLabels, "__name__", "up")
+		series = appendLabel(series, chunkedSeriesLabels, "job", "a")
+
+		var accepted uint64
+		forEachField(request, func(num protowire.Number, _ []byte, x uint64) error {
+			if num == readRequestAcceptedResponseTypes {
+				accepted = x
+			}
+			return nil
+		})
+		if accepted == responseTypeSamples {
+			for _, s := range samples {
+				sb := appendVarint(nil, sampleTimestamp, uint64(s.Timestamp))
+				sb = protowire.AppendTag(sb, sampleValue, protowire.Fixed64Type)
+				sb = protowire.AppendFixed64(sb, math.Float64bits(float64(s.Value)))
+				series = appendMessage(series, timeSeriesSamples, sb)
+			}
+			result := appendMessage(nil, queryResultTimeseries, series)
+			w.Header().Set("Content-Type", "application/x-protobuf")
+			w.Write(snappy.Encode(nil, appendMessage(nil, readResponseResults, result)))
+			return
+		}
+
+		chunk := appendVarint(nil, chunkMinTimeMs, 1000)
+		chunk = appendVarint(chunk, chunkMaxTimeMs, 2000)
+		chunk = appendVarint(chunk, chunkType, uint64(ChunkXOR))
+		chunk = appendMessage(chunk, chunkData, encodeXOR(samples))
+		series = appendMessage(series, chunkedSeriesChunks, chunk)
+		frame := appendMessage(nil, chunkedReadResponseChunkedSeries, series)
+		frame = appendVarint(frame, chunkedReadResponseQueryIndex, 0)
+
+		w.Header().Set("Content-Type", "application/x-streamed-protobuf; proto=prometheus.ChunkedReadResponse")
+		for i := 0; i < 2; i++ {
+			w.Write(binary.AppendUvarint(nil, uint64(len(frame))))
+			w.Write(binary.BigEndian.AppendUint32(nil, crc32.Checksum(frame, castagnoliTable)))
+			w.Write(frame)
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewClient(Config{URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := Query{
+		Start:    time.Unix(1, 0),
+		End:      time.Unix(2, 0),
+		Matchers: []Matcher{{Type: MatchEqual, Name: "__name__", Value: "up"}},
+	}
+	wantMetric := model.Metric{"__name__": "up", "job": "a"}
+
+	results, err := c.Read(context.Background(), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || len(results[0]) != 1 {
+		t.Fatalf("got results %v, want one series", results)
+	}
+	if s := results[0][0]; !reflect.DeepEqual(s.Metric, wantMetric) || !reflect.DeepEqual(s.Samples, samples) {
+		t.Errorf("got series %v %v, want %v %v", s.Metric, s.Samples, wantMetric, samples)
+	}
+
+	var matchers int
+	forEachField(request, func(num protowire.Number, v []byte, _ uint64) error {
+		if num == readRequestQueries {
+			forEachField(v, func(num protowire.Number, _ []byte, x uint64) error {
+				switch num {
+				case queryStartTimestampMs:
+					if x != 1000 {
+						t.Errorf("got start %d, want 1000", x)
+					}
+				case queryMatchers:
+					matchers++
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if matchers != 1 {
+		t.Errorf("got %d matchers, want 1", matchers)
+	}
+
+	it, err := c.ReadStream(context.Background(), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer it.Close()
+	var n int
+	for it.Next() {
+		n++
+		s := it.At()
+		if !reflect.DeepEqual(s.Metric, wantMetric) || len(s.Chunks) != 1 {
+			t.Fatalf("got series %v with %d chunks", s.Metric, len(s.Chunks))
+		}
+		got, err := s.Chunks[0].Samples()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, samples) {
+			t.Errorf("got samples %v, want %v", got, samples)
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("got %d series, want 2", n)
+	}
+}
+
+func appendLabel(b []byte, num protowire.Number, name, value string) []byte {
+	lb := appendString(nil, labelName, name)
+	lb = appendString(lb, labelValue, value)
+	return appendMessage(b, num, lb)
+}
+
+// encodeXOR encodes samples as XOR chunk like the Prometheus TSDB does.
+func encodeXOR(samples []model.SamplePair) []byte {
+	w := &bitWriter{b: binary.BigEndian.AppendUint16(nil, uint16(len(samples)))}
+	var (
+		t, tDelta         int64
+		v                 float64
+		leading, trailing uint8 = 0xff, 0
+	)
+	for i, s := range samples {
+		st, sv := int64(s.Timestamp), float64(s.Value)
+		switch i {
+		case 0:
+			for _, b := range binary.AppendVarint(nil, st) {
+				w.writeBits(uint64(b), 8)
+			}
+			w.writeBits(math.Float64bits(sv), 64)
+		case 1:
+			tDelta = st - t
+			for _, b := range binary.AppendUvarint(nil, uint64(tDelta)) {
+				w.writeBits(uint64(b), 8)
+			}
+			w.writeXOR(sv, v, &leading, &trailing)
+		default:
+			d := st - t
+			dod := d - tDelta
+			tDelta = d
+			switch {
+			case dod == 0:
+				w.writeBits(0, 1)
+			case -(1<<13-1) <= dod && dod <= 1<<13:
+				w.writeBits(0b10, 2)
+				w.writeBits(uint64(dod), 14)
+			case -(1<<16-1) <= dod && dod <= 1<<16:
+				w.writeBits(0b110, 3)
+				w.writeBits(uint64(dod), 17)
+			case -(1<<19-1) <= dod && dod <= 1<<19:
+				w.writeBits(0b1110, 4)
+				w.writeBits(uint64(dod), 20)
+			default:
+				w.writeBits(0b1111, 4)
+				w.writeBits(uint64(dod), 64)
+			}
+			w.writeXOR(sv, v, &leading, &trailing)
+		}
+		t, v = st, sv
+	}
+	return w.b
+}
+
+type bitWriter struct {
+	b     []byte
+	count uint8 // Free bits in the last byte.
+}
+
+func (w *bitWriter) writeBits(v uint64, n int) {
+	for i := n - 1; i >= 0; i-- {
+		if w.count == 0 {
+			w.b = append(w.b, 0)
+			w.count = 8
+		}
+		if v&(1<<i) != 0 {
+			w.b[len(w.b)-1] |= 1 << (w.count - 1)
+		}
+		w.count--
+	}
+}
+
+func (w *bitWriter) writeXOR(v, prev float64, leading, trailing *uint8) {
+	delta := math.Float64bits(v) ^ math.Float64bits(prev)
+	if delta == 0 {
+		w.writeBits(0, 1)
+		return
+	}
+	w.writeBits(1, 1)
+	newLeading, newTrailing := uint8(bits.LeadingZeros64(delta)), uint8(bits.TrailingZeros64(delta))
+	if newLeading >= 32 {
+		newLeading = 31
+	}
+	if *leading != 0xff && newLeading >= *leading && newTrailing >= *trailing {
+		w.writeBits(0, 1)
+		w.writeBits(delta>>*trailing, 64-int(*leading)-int(*trailing))
+		return
+	}
+	*leading, *trailing = newLeading, newTrailing
+	w.writeBits(1, 1)
+	w.writeBits(uint64(newLeading), 5)
+	sigbits := 64 - newLeading - newTrailing
+	w.writeBits(uint64(sigbits), 6)
+	w.writeBits(delta>>newTrailing, int(sigbits))
+}
@@ -0,0 +1,394 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remoteread
+
+import (
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/prometheus/common/model"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Field numbers of the remote-read protobuf messages, see
+// https://github.com/prometheus/prometheus/blob/main/prompb/remote.proto and
+// https://github.com/prometheus/prometheus/blob/main/prompb/types.proto.
+const (
+	readRequestQueries               = 1
+	readRequestAcceptedResponseTypes = 2
+
+	queryStartTimestampMs = 1
+	queryEndTimestampMs   = 2
+	queryMatchers         = 3
+	queryHints            = 4
+
+	matcherType  = 1
+	matcherName  = 2
+	matcherValue = 3
+
+	hintsStepMs   = 1
+	hintsFunc     = 2
+	hintsStartMs  = 3
+	hintsEndMs    = 4
+	hintsGrouping = 5
+	hintsBy       = 6
+	hintsRangeMs  = 7
+
+	readResponseResults   = 1
+	queryResultTimeseries = 1
+
+	timeSeriesLabels     = 1
+	timeSeriesSamples    = 2
+	timeSeriesHistograms = 4
+
+	labelName  = 1
+	labelValue = 2
+
+	sampleValue     = 1
+	sampleTimestamp = 2
+
+	chunkedReadResponseChunkedSeries = 1
+	chunkedReadResponseQueryIndex    = 2
+
+	chunkedSeriesLabels = 1
+	chunkedSeriesChunks = 2
+
+	chunkMinTimeMs = 1
+	chunkMaxTimeMs = 2
+	chunkType      = 3
+	chunkData      = 4
+
+	histogramCountInt       = 1
+	histogramCountFloat     = 2
+	histogramSum            = 3
+	histogramSchema         = 4
+	histogramZeroThreshold  = 5
+	histogramZeroCountInt   = 6
+	histogramZeroCountFloat = 7
+	histogramNegativeSpans  = 8
+	histogramNegativeDeltas = 9
+	histogramNegativeCounts = 10
+	histogramPositiveSpans  = 11
+	histogramPositiveDeltas = 12
+	histogramPositiveCounts = 13
+	histogramTimestamp      = 15
+
+	bucketSpanOffset = 1
+	bucketSpanLength = 2
+
+	responseTypeSamples           = 0
+	responseTypeStreamedXORChunks = 1
+)
+
+// encodeReadRequest encodes a ReadRequest for the provided queries accepting
+// the provided response type.
+func encodeReadRequest(queries []Query, responseType uint64) []byte {
+	var b []byte
+	for _, q := range queries {
+		qb := appendVarint(nil, queryStartTimestampMs, uint64(q.Start.UnixMilli()))
+		qb = appendVarint(qb, queryEndTimestampMs, uint64(q.End.UnixMilli()))
+		for _, m := range q.Matchers {
+			mb := appendVarint(nil, matcherType, uint64(m.Type))
+			mb = appendString(mb, matcherName, m.Name)
+			mb = appendString(mb, matcherValue, m.Value)
+			qb = appendMessage(qb, queryMatchers, mb)
+		}
+		if h := q.Hints; h != nil {
+			hb := appendVarint(nil, hintsStepMs, uint64(h.Step.Milliseconds()))
+			hb = appendString(hb, hintsFunc, h.Func)
+			hb = appendVarint(hb, hintsStartMs, uint64(h.Start.UnixMilli()))
+			hb = appendVarint(hb, hintsEndMs, uint64(h.End.UnixMilli()))
+			for _, g := range h.Grouping {
+				hb = appendString(hb, hintsGrouping, g)
+			}
+			if h.By {
+				hb = appendVarint(hb, hintsBy, 1)
+			}
+			hb = appendVarint(hb, hintsRangeMs, uint64(h.Range.Milliseconds()))
+			qb = appendMessage(qb, queryHints, hb)
+		}
+		b = appendMessage(b, readRequestQueries, qb)
+	}
+	return appendVarint(b, readRequestAcceptedResponseTypes, responseType)
+}
+
+// decodeReadResponse decodes a ReadResponse into the series of each query.
+func decodeReadResponse(b []byte) ([][]*Series, error) {
+	var results [][]*Series
+	err := forEachField(b, func(num protowire.Number, v []byte, _ uint64) error {
+		if num != readResponseResults {
+			return nil
+		}
+		var result []*Series
+		err := forEachField(v, func(num protowire.Number, v []byte, _ uint64) error {
+			if num != queryResultTimeseries {
+				return nil
+			}
+			s, err := decodeTimeSeries(v)
+			result = append(result, s)
+			return err
+		})
+		results = append(results, result)
+		return err
+	})
+	return results, err
+}
+
+func decodeTimeSeries(b []byte) (*Series, error) {
+	s := &Series{Metric: model.Metric{}}
+	err := forEachField(b, func(num protowire.Number, v []byte, _ uint64) error {
+		switch num {
+		case timeSeriesLabels:
+			return decodeLabel(v, s.Metric)
+		case timeSeriesSamples:
+			var sp model.SamplePair
+			err := forEachField(v, func(num protowire.Number, _ []byte, x uint64) error {
+				switch num {
+				case sampleValue:
+					sp.Value = model.SampleValue(math.Float64frombits(x))
+				case sampleTimestamp:
+					sp.Timestamp = model.Time(x)
+				}
+				return nil
+			})
+			s.Samples = append(s.Samples, sp)
+			return err
+		case timeSeriesHistograms:
+			h, err := decodeHistogram(v)
+			s.Histograms = append(s.Histograms, h)
+			return err
+		}
+		return nil
+	})
+	return s, err
+}
+
+func decodeLabel(b []byte, m model.Metric) error {
+	var name, value string
+	err := forEachField(b, func(num protowire.Number, v []byte, _ uint64) error {
+		switch num {
+		case labelName:
+			name = string(v)
+		case labelValue:
+			value = string(v)
+		}
+		return nil
+	})
+	m[model.LabelName(name)] = model.LabelValue(value)
+	return err
+}
+
+// decodeHistogram decodes a Histogram message into the equivalent fields of
+// the exposition format.
+func decodeHistogram(b []byte) (HistogramSample, error) {
+	var (
+		hs HistogramSample
+		h  = &dto.Histogram{}
+	)
+	err := forEachField(b, func(num protowire.Number, v []byte, x uint64) error {
+		switch num {
+		case histogramCountInt:
+			h.SampleCount = proto.Uint64(x)
+		case histogramCountFloat:
+			h.SampleCountFloat = proto.Float64(math.Float64frombits(x))
+		case histogramSum:
+			h.SampleSum = proto.Float64(math.Float64frombits(x))
+		case histogramSchema:
+			h.Schema = proto.Int32(int32(protowire.DecodeZigZag(x)))
+		case histogramZeroThreshold:
+			h.ZeroThreshold = proto.Float64(math.Float64frombits(x))
+		case histogramZeroCountInt:
+			h.ZeroCount = proto.Uint64(x)
+		case histogramZeroCountFloat:
+			h.ZeroCountFloat = proto.Float64(math.Float64frombits(x))
+		case histogramNegativeSpans, histogramPositiveSpans:
+			span := &dto.BucketSpan{}
+			err := forEachField(v, func(num protowire.Number, _ []byte, x uint64) error {
+				switch num {
+				case bucketSpanOffset:
+					span.Offset = proto.Int32(int32(protowire.DecodeZigZag(x)))
+				case bucketSpanLength:
+					span.Length = proto.Uint32(uint32(x))
+				}
+				return nil
+			})
+			if num == histogramNegativeSpans {
+				h.NegativeSpan = append(h.NegativeSpan, span)
+			} else {
+				h.PositiveSpan = append(h.PositiveSpan, span)
+			}
+			return err
+		case histogramNegativeDeltas:
+			return decodeRepeated(v, x, protowire.VarintType, func(x uint64) {
+				h.NegativeDelta = append(h.NegativeDelta, protowire.DecodeZigZag(x))
+			})
+		case histogramPositiveDeltas:
+			return decodeRepeated(v, x, protowire.VarintType, func(x uint64) {
+				h.PositiveDelta = append(h.PositiveDelta, protowire.DecodeZigZag(x))
+			})
+		case histogramNegativeCounts:
+			return decodeRepeated(v, x, protowire.Fixed64Type, func(x uint64) {
+				h.NegativeCount = append(h.NegativeCount, math.Float64frombits(x))
+			})
+		case histogramPositiveCounts:
+			return decodeRepeated(v, x, protowire.Fixed64Type, func(x uint64) {
+				h.PositiveCount = append(h.PositiveCount, math.Float64frombits(x))
+			})
+		case histogramTimestamp:
+			hs.Timestamp = model.Time(x)
+		}
+		return nil
+	})
+	hs.Histogram = h
+	return hs, err
+}
+
+// decodeRepeated calls fn for each element of a repeated numeric field, which
+// is either packed into v or a single element x (if v is nil).
+func decodeRepeated(v []byte, x uint64, typ protowire.Type, fn func(x uint64)) error {
+	if v == nil {
+		fn(x)
+		return nil
+	}
+	for len(v) > 0 {
+		var n int
+		if typ == protowire.Fixed64Type {
+			x, n = protowire.ConsumeFixed64(v)
+		} else {
+			x, n = protowire.ConsumeVarint(v)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		fn(x)
+		v = v[n:]
+	}
+	return nil
+}
+
+// decodeChunkedReadResponse decodes a ChunkedReadResponse frame.
+func decodeChunkedReadResponse(b []byte) ([]*ChunkedSeries, error) {
+	var (
+		series     []*ChunkedSeries
+		queryIndex int
+	)
+	err := forEachField(b, func(num protowire.Number, v []byte, x uint64) error {
+		switch num {
+		case chunkedReadResponseChunkedSeries:
+			s, err := decodeChunkedSeries(v)
+			series = append(series, s)
+			return err
+		case chunkedReadResponseQueryIndex:
+			queryIndex = int(x)
+		}
+		return nil
+	})
+	for _, s := range series {
+		s.QueryIndex = queryIndex
+	}
+	return series, err
+}
+
+func decodeChunkedSeries(b []byte) (*ChunkedSeries, error) {
+	s := &ChunkedSeries{Metric: model.Metric{}}
+	err := forEachField(b, func(num protowire.Number, v []byte, _ uint64) error {
+		switch num {
+		case chunkedSeriesLabels:
+			return decodeLabel(v, s.Metric)
+		case chunkedSeriesChunks:
+			var c Chunk
+			err := forEachField(v, func(num protowire.Number, v []byte, x uint64) error {
+				switch num {
+				case chunkMinTimeMs:
+					c.MinTime = model.Time(x)
+				case chunkMaxTimeMs:
+					c.MaxTime = model.Time(x)
+				case chunkType:
+					c.Encoding = ChunkEncoding(x)
+				case chunkData:
+					c.Data = v
+				}
+				return nil
+			})
+			s.Chunks = append(s.Chunks, c)
+			return err
+		}
+		return nil
+	})
+	return s, err
+}
+
+// forEachField calls fn for each field of the protobuf message b with either
+// the bytes of a length-delimited field or the value of a numeric field.
+func forEachField(b []byte, fn func(num protowire.Number, v []byte, x uint64) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		var err error
+		switch typ {
+		case protowire.BytesType:
+			var v []byte
+			v, n = protowire.ConsumeBytes(b)
+			if n >= 0 {
+				err = fn(num, v, 0)
+			}
+		case protowire.VarintType:
+			var x uint64
+			x, n = protowire.ConsumeVarint(b)
+			if n >= 0 {
+				err = fn(num, nil, x)
+			}
+		case protowire.Fixed64Type:
+			var x uint64
+			x, n = protowire.ConsumeFixed64(b)
+			if n >= 0 {
+				err = fn(num, nil, x)
+			}
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		if err != nil {
+			return err
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+func appendMessage(b []byte, num protowire.Number, msg []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
+
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+func appendVarint(b []byte, num protowire.Number, v uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
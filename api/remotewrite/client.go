@@ -0,0 +1,377 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package remotewrite provides a client for the Prometheus remote-write
+// protocol (versions 1.0 and 2.0), which sends metrics collected with the
+// prometheus package to Prometheus or any other remote-write receiver. The
+// Exporter builds on the Client to push the metrics of a Gatherer
+// periodically, with staleness markers for disappeared series. Conversely, the
+// Receiver accepts remote-write requests and exposes the received series as
+// metrics of a registry.
+package remotewrite
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/prometheus/client_golang/api"
+	"github.com/prometheus/client_golang/prometheus"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Compression is the algorithm used to compress request bodies.
+type Compression string
+
+const (
+	// Snappy is the block format of snappy, which is what the remote-write
+	// protocol mandates and all receivers support.
+	Snappy Compression = "snappy"
+	// Zstd is zstd, which compresses better but is only supported by some
+	// receivers.
+	Zstd Compression = "zstd"
+)
+
+const (
+	defaultInitialBackoff = 30 * time.Millisecond
+	defaultMaxBackoff     = 5 * time.Second
+
+	versionHeader           = "X-Prometheus-Remote-Write-Version"
+	samplesWrittenHeader    = "X-Prometheus-Remote-Write-Samples-Written"
+	histogramsWrittenHeader = "X-Prometheus-Remote-Write-Histograms-Written"
+	exemplarsWrittenHeader  = "X-Prometheus-Remote-Write-Exemplars-Written"
+)
+
+// Config defines configuration parameters for a new Client.
+type Config struct {
+	// URL is the full URL of the remote-write endpoint, e.g.
+	// “http://prometheus:9090/api/v1/write”. Mandatory.
+	URL string
+
+	// Client is used to send the requests. If not provided, a client based
+	// on api.DefaultRoundTripper will be used.
+	Client *http.Client
+
+	// ProtoMsg selects the protocol version. Defaults to WriteV1, which all
+	// receivers support.
+	ProtoMsg ProtoMsg
+
+	// Compression of the request bodies. Defaults to Snappy.
+	Compression Compression
+
+	// ExternalLabels are added to every series that doesn't already have a
+	// label with the same name.
+	ExternalLabels map[string]string
+
+	// Header contains additional headers to set on every request, e.g. for
+	// authentication or tenancy.
+	Header http.Header
+
+	// Retry configures retries of failed requests. The zero value disables
+	// retries.
+	Retry RetryConfig
+
+	// Registerer, if not nil, is used to register metrics about the
+	// operation of the Client, see NewClient.
+	Registerer prometheus.Registerer
+}
+
+// RetryConfig defines how a Client retries failed requests.
+//
+// A request is retried if it failed with a network error, or if the receiver
+// responded with status code 429 (Too Many Requests) or any 5xx status code.
+// Other status codes indicate that the data is invalid, so that retrying it is
+// pointless. Requests are never retried once the context of the write has
+// expired.
+type RetryConfig struct {
+	// MaxRetries is the maximum number of retries after the initial
+	// attempt. Zero disables retries.
+	MaxRetries int
+	// InitialBackoff is the time to wait before the first retry. It is
+	// doubled for every further retry (with some jitter). Defaults to 30ms.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the time to wait between two attempts. Defaults to
+	// 5s. A Retry-After header in a 429 or 503 response overrides the
+	// backoff (but not MaxBackoff).
+	MaxBackoff time.Duration
+}
+
+// Client sends metrics to a remote-write endpoint. It is safe to use a Client
+// from multiple goroutines.
+type Client struct {
+	url         string
+	client      *http.Client
+	msg         ProtoMsg
+	compression Compression
+	extLabels   map[string]string
+	header      http.Header
+	retry       RetryConfig
+	metrics     *clientMetrics
+	zstdEncoder *zstd.Encoder
+}
+
+// NewClient returns a new Client. If cfg.Registerer is set, the following
+// metrics are registered with it:
+//
+// remote_write_client_requests_total{code}: HTTP requests sent (including
+// retries) by status code. The code is "error" if no response was received.
+//
+// remote_write_client_request_duration_seconds: Histogram of the duration of
+// HTTP requests.
+//
+// remote_write_client_sent_bytes_total: Compressed bytes sent.
+//
+// remote_write_client_samples_sent_total,
+// remote_write_client_histograms_sent_total,
+// remote_write_client_exemplars_sent_total: Float samples, native histogram
+// samples, and exemplars successfully written.
+//
+// remote_write_client_retries_total: Retried requests.
+func NewClient(cfg Config) (*Client, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("remote-write URL is missing")
+	}
+	if cfg.ProtoMsg == "" {
+		cfg.ProtoMsg = WriteV1
+	}
+	if err := cfg.ProtoMsg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.Compression == "" {
+		cfg.Compression = Snappy
+	}
+	if cfg.Retry.InitialBackoff <= 0 {
+		cfg.Retry.InitialBackoff = defaultInitialBackoff
+	}
+	if cfg.Retry.MaxBackoff <= 0 {
+		cfg.Retry.MaxBackoff = defaultMaxBackoff
+	}
+	c := &Client{
+		url:         cfg.URL,
+		client:      cfg.Client,
+		msg:         cfg.ProtoMsg,
+		compression: cfg.Compression,
+		extLabels:   cfg.ExternalLabels,
+		header:      cfg.Header,
+		retry:       cfg.Retry,
+	}
+	if c.client == nil {
+		c.client = &http.Client{Transport: api.DefaultRoundTripper}
+	}
+	switch cfg.Compression {
+	case Snappy:
+	case Zstd:
+		enc, err := zstd.NewWriter(nil)
+		if err != nil {
+			return nil, err
+		}
+		c.zstdEncoder = enc
+	default:
+		return nil, fmt.Errorf("unsupported compression %q", string(cfg.Compression))
+	}
+	if cfg.Registerer != nil {
+		m, err := newClientMetrics(cfg.Registerer)
+		if err != nil {
+			return nil, err
+		}
+		c.metrics = m
+	}
+	return c, nil
+}
+
+// Write sends the provided metric families in a single request. It returns
+// the numbers of written samples, histograms, and exemplars, as confirmed by
+// the receiver if it supports remote write 2.0.
+func (c *Client) Write(ctx context.Context, mfs []*dto.MetricFamily) (WriteStats, error) {
+	return c.write(ctx, mfs, time.Now(), nil)
+}
+
+// write is like Write, but additionally sends staleness markers for the stale
+// series, timestamped with now like the samples without explicit timestamp.
+func (c *Client) write(ctx context.Context, mfs []*dto.MetricFamily, now time.Time, stale [][]label) (WriteStats, error) {
+	buf, stats, err := marshal(c.msg, mfs, toLabels(c.extLabels), now.UnixMilli(), stale)
+	if err != nil {
+		return WriteStats{}, err
+	}
+	body := c.compress(buf)
+	resp, err := c.send(ctx, body)
+	if err != nil {
+		return WriteStats{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(resp.Body) // Ignore any further error as this is for an error message only.
+		return WriteStats{}, fmt.Errorf("unexpected status code %d while writing to %s: %s", resp.StatusCode, c.url, strings.TrimSpace(string(msg)))
+	}
+	io.Copy(io.Discard, resp.Body) //nolint:errcheck // Only draining for connection reuse.
+	if confirmed, ok := writtenStats(resp.Header); ok {
+		stats = confirmed
+	}
+	c.metrics.observeWrite(stats)
+	return stats, nil
+}
+
+// WriteGatherer gathers g and writes the result, see Write.
+func (c *Client) WriteGatherer(ctx context.Context, g prometheus.Gatherer) (WriteStats, error) {
+	mfs, err := g.Gather()
+	if err != nil {
+		return WriteStats{}, err
+	}
+	return c.Write(ctx, mfs)
+}
+
+// WriteMetrics writes the provided metrics, see Write. The metrics are checked
+// for consistency like in a Registry, e.g. metrics with the same name must
+// have the same type and help text.
+func (c *Client) WriteMetrics(ctx context.Context, metrics ...prometheus.Metric) (WriteStats, error) {
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(metricsCollector(metrics)); err != nil {
+		return WriteStats{}, err
+	}
+	return c.WriteGatherer(ctx, reg)
+}
+
+// metricsCollector is an unchecked Collector of a fixed set of metrics.
+type metricsCollector []prometheus.Metric
+
+func (metricsCollector) Describe(chan<- *prometheus.Desc) {}
+
+func (mc metricsCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, m := range mc {
+		ch <- m
+	}
+}
+
+func (c *Client) compress(buf []byte) []byte {
+	if c.zstdEncoder != nil {
+		return c.zstdEncoder.EncodeAll(buf, nil)
+	}
+	return snappy.Encode(nil, buf)
+}
+
+// send sends body to the endpoint, retrying according to the RetryConfig. The
+// caller is responsible for closing the body of the returned response.
+func (c *Client) send(ctx context.Context, body []byte) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		for name, values := range c.header {
+			req.Header[name] = values
+		}
+		req.Header.Set("Content-Type", c.msg.ContentType())
+		req.Header.Set("Content-Encoding", string(c.compression))
+		req.Header.Set(versionHeader, c.msg.Version())
+		req.Header.Set("User-Agent", "client_golang/remotewrite")
+
+		start := time.Now()
+		resp, err := c.client.Do(req)
+		c.metrics.observeRequest(resp, len(body), time.Since(start))
+		if attempt >= c.retry.MaxRetries || !retryable(ctx, resp, err) {
+			return resp, err
+		}
+		wait := c.retry.backoff(attempt+1, resp)
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body) //nolint:errcheck // Only draining for connection reuse.
+			resp.Body.Close()
+		}
+		c.metrics.observeRetry()
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// retryable reports whether a request that resulted in the provided response
+// or error should be retried.
+func retryable(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode/100 == 5
+}
+
+// backoff returns the time to wait before the provided retry (starting at 1).
+func (r *RetryConfig) backoff(retry int, resp *http.Response) time.Duration {
+	if resp != nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) {
+		if d, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			return min(d, r.MaxBackoff)
+		}
+	}
+	d := r.InitialBackoff
+	for i := 1; i < retry && d < r.MaxBackoff; i++ {
+		d *= 2
+	}
+	// Add up to 20% jitter to avoid many clients retrying in lockstep.
+	d = time.Duration(float64(d) * (1 + 0.2*rand.Float64()))
+	return min(d, r.MaxBackoff)
+}
+
+// retryAfter parses the value of a Retry-After header, which may contain
+// either a number of seconds or an HTTP date.
+func retryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(max(secs, 0)) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+// writtenStats returns the numbers of written samples, histograms, and
+// exemplars reported by a remote write 2.0 receiver in the response headers.
+func writtenStats(h http.Header) (WriteStats, bool) {
+	stats := WriteStats{Confirmed: true}
+	for _, f := range []struct {
+		header string
+		n      *int
+	}{
+		{samplesWrittenHeader, &stats.Samples},
+		{histogramsWrittenHeader, &stats.Histograms},
+		{exemplarsWrittenHeader, &stats.Exemplars},
+	} {
+		v := h.Get(f.header)
+		if v == "" {
+			return WriteStats{}, false
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return WriteStats{}, false
+		}
+		*f.n = n
+	}
+	return stats, true
+}
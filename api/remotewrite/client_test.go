@@ -0,0 +1,357 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotewrite
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestClientWrite(t *testing.T) {
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "requests_total",
+		Help: "Total requests.",
+	}, []string{"instance"})
+	counter.WithLabelValues("a").Add(3)
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:                        "latency_seconds",
+		Help:                        "Latency.",
+		Buckets:                     []float64{1},
+		NativeHistogramBucketFactor: 1.1,
+	})
+	histogram.Observe(0.5)
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(counter, histogram)
+
+	want := []string{
+		`latency_seconds_bucket{env="prod",instance="ignored",le="+Inf"} 1`,
+		`latency_seconds_bucket{env="prod",instance="ignored",le="1"} 1`,
+		`latency_seconds_count{env="prod",instance="ignored"} 1`,
+		`latency_seconds_sum{env="prod",instance="ignored"} 0.5`,
+		`requests_total{env="prod",instance="a"} 3`,
+	}
+
+	for _, tc := range []struct {
+		msg         ProtoMsg
+		compression Compression
+	}{
+		{WriteV1, Snappy},
+		{WriteV2, Snappy},
+		{WriteV2, Zstd},
+	} {
+		var (
+			header http.Header
+			body   []byte
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header = r.Header
+			compressed, err := io.ReadAll(r.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.compression == Zstd {
+				dec, _ := zstd.NewReader(nil)
+				body, err = dec.DecodeAll(compressed, nil)
+			} else {
+				body, err = snappy.Decode(nil, compressed)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.msg == WriteV2 {
+				w.Header().Set(samplesWrittenHeader, "4")
+				w.Header().Set(histogramsWrittenHeader, "1")
+				w.Header().Set(exemplarsWrittenHeader, "0")
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}))
+
+		c, err := NewClient(Config{
+			URL:            server.URL,
+			ProtoMsg:       tc.msg,
+			Compression:    tc.compression,
+			ExternalLabels: map[string]string{"env": "prod", "instance": "ignored"},
+			Header:         http.Header{"X-Scope-Orgid": []string{"tenant"}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		stats, err := c.WriteGatherer(context.Background(), reg)
+		server.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := header.Get("Content-Type"); got != tc.msg.ContentType() {
+			t.Errorf("%s: got content type %q, want %q", tc.msg, got, tc.msg.ContentType())
+		}
+		if got := header.Get("Content-Encoding"); got != string(tc.compression) {
+			t.Errorf("%s: got content encoding %q, want %q", tc.msg, got, tc.compression)
+		}
+		if got := header.Get("X-Scope-Orgid"); got != "tenant" {
+			t.Errorf("%s: got tenant header %q", tc.msg, got)
+		}
+		wantStats := WriteStats{Samples: 5, Histograms: 1}
+		if tc.msg == WriteV2 {
+			wantStats = WriteStats{Samples: 4, Histograms: 1, Confirmed: true}
+		}
+		if stats != wantStats {
+			t.Errorf("%s: got stats %+v, want %+v", tc.msg, stats, wantStats)
+		}
+
+		var samples []string
+		if tc.msg == WriteV1 {
+			samples = decodeV1(t, body)
+		} else {
+			samples = decodeV2(t, body)
+		}
+		if strings.Join(samples, "\n") != strings.Join(want, "\n") {
+			t.Errorf("%s: got samples\n%s\nwant\n%s", tc.msg, strings.Join(samples, "\n"), strings.Join(want, "\n"))
+		}
+	}
+}
+
+func TestClientRetry(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch attempts.Add(1) {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	reg := prometheus.NewPedanticRegistry()
+	c, err := NewClient(Config{
+		URL:        server.URL,
+		Retry:      RetryConfig{MaxRetries: 2, InitialBackoff: time.Millisecond},
+		Registerer: reg,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gauge := prometheus.MustNewConstMetric(
+		prometheus.NewDesc("temperature_celsius", "Temperature.", nil, nil),
+		prometheus.GaugeValue, 21,
+	)
+	if _, err := c.WriteMetrics(context.Background(), gauge); err != nil {
+		t.Fatal(err)
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("got %d attempts, want 3", got)
+	}
+
+	for _, tc := range []struct {
+		name string
+		c    prometheus.Collector
+		want float64
+	}{
+		{"requests with code 429", c.metrics.requests.WithLabelValues("429"), 1},
+		{"requests with code 503", c.metrics.requests.WithLabelValues("503"), 1},
+		{"requests with code 204", c.metrics.requests.WithLabelValues("204"), 1},
+		{"retries", c.metrics.retries, 2},
+		{"samples", c.metrics.samples, 1},
+	} {
+		if got := testutil.ToFloat64(tc.c); got != tc.want {
+			t.Errorf("got %v %s, want %v", got, tc.name, tc.want)
+		}
+	}
+
+	// Client errors are not retried.
+	attempts.Store(0)
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		http.Error(w, "out of order sample", http.StatusBadRequest)
+	}))
+	defer bad.Close()
+	c, err = NewClient(Config{URL: bad.URL, Retry: RetryConfig{MaxRetries: 2}, Registerer: reg})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.WriteMetrics(context.Background(), gauge); err == nil || !strings.Contains(err.Error(), "out of order sample") {
+		t.Errorf("got error %v, want one containing the response body", err)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("got %d attempts, want 1", got)
+	}
+}
+
+func TestNewClientErrors(t *testing.T) {
+	for _, cfg := range []Config{
+		{},
+		{URL: "http://example.org", ProtoMsg: "foo"},
+		{URL: "http://example.org", Compression: "gzip"},
+	} {
+		if _, err := NewClient(cfg); err == nil {
+			t.Errorf("expected error for config %+v", cfg)
+		}
+	}
+}
+
+// decodeV1 decodes the float samples of a remote-write 1.0 request in a
+// text-like representation, sorted for comparison.
+func decodeV1(t *testing.T, b []byte) []string {
+	var samples []string
+	forEachField(t, b, func(num protowire.Number, v []byte, _ uint64) {
+		if num != v1WriteRequestTimeseries {
+			return
+		}
+		var (
+			labels []label
+			value  *float64
+		)
+		forEachField(t, v, func(num protowire.Number, v []byte, _ uint64) {
+			switch num {
+			case v1TimeSeriesLabels:
+				var l label
+				forEachField(t, v, func(num protowire.Number, v []byte, _ uint64) {
+					if num == v1LabelName {
+						l.name = string(v)
+					} else {
+						l.value = string(v)
+					}
+				})
+				labels = append(labels, l)
+			case v1TimeSeriesSamples:
+				value = decodeSampleValue(t, v)
+			}
+		})
+		if value != nil {
+			samples = append(samples, formatSample(labels, *value))
+		}
+	})
+	sort.Strings(samples)
+	return samples
+}
+
+// decodeV2 is like decodeV1 for remote-write 2.0 requests.
+func decodeV2(t *testing.T, b []byte) []string {
+	var (
+		symbols []string
+		series  [][]byte
+		samples []string
+	)
+	forEachField(t, b, func(num protowire.Number, v []byte, _ uint64) {
+		switch num {
+		case v2RequestSymbols:
+			symbols = append(symbols, string(v))
+		case v2RequestTimeseries:
+			series = append(series, v)
+		}
+	})
+	if len(symbols) == 0 || symbols[0] != "" {
+		t.Fatalf("first symbol must be empty, got %q", symbols)
+	}
+	for _, s := range series {
+		var (
+			labels []label
+			value  *float64
+		)
+		forEachField(t, s, func(num protowire.Number, v []byte, _ uint64) {
+			switch num {
+			case v2TimeSeriesLabelsRefs:
+				var refs []uint64
+				for len(v) > 0 {
+					ref, n := protowire.ConsumeVarint(v)
+					refs = append(refs, ref)
+					v = v[n:]
+				}
+				for i := 0; i+1 < len(refs); i += 2 {
+					labels = append(labels, label{symbols[refs[i]], symbols[refs[i+1]]})
+				}
+			case v2TimeSeriesSamples:
+				value = decodeSampleValue(t, v)
+			}
+		})
+		if value != nil {
+			samples = append(samples, formatSample(labels, *value))
+		}
+	}
+	sort.Strings(samples)
+	return samples
+}
+
+func decodeSampleValue(t *testing.T, b []byte) *float64 {
+	var value float64
+	forEachField(t, b, func(num protowire.Number, _ []byte, x uint64) {
+		if num == v1SampleValue {
+			value = math.Float64frombits(x)
+		}
+	})
+	return &value
+}
+
+func formatSample(labels []label, value float64) string {
+	var (
+		name string
+		ls   []string
+	)
+	for _, l := range labels {
+		if l.name == "__name__" {
+			name = l.value
+		} else {
+			ls = append(ls, l.name+`="`+l.value+`"`)
+		}
+	}
+	return name + "{" + strings.Join(ls, ",") + "} " + formatFloat(value)
+}
+
+// forEachField calls fn for each field of the protobuf message b with either
+// the bytes of a length-delimited field or the value of a numeric field.
+func forEachField(t *testing.T, b []byte, fn func(num protowire.Number, v []byte, x uint64)) {
+	t.Helper()
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			t.Fatal(protowire.ParseError(n))
+		}
+		b = b[n:]
+		n = protowire.ConsumeFieldValue(num, typ, b)
+		if n < 0 {
+			t.Fatal(protowire.ParseError(n))
+		}
+		switch typ {
+		case protowire.BytesType:
+			v, _ := protowire.ConsumeBytes(b)
+			fn(num, v, 0)
+		case protowire.Fixed64Type:
+			x, _ := protowire.ConsumeFixed64(b)
+			fn(num, nil, x)
+		case protowire.VarintType:
+			x, _ := protowire.ConsumeVarint(b)
+			fn(num, nil, x)
+		}
+		b = b[n:]
+	}
+}
@@ -0,0 +1,281 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotewrite
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// writeRequest is a decoded remote-write request of either version, limited
+// to what a Receiver needs.
+type writeRequest struct {
+	series []receivedSeries
+	// metadata of remote write 1.0 by metric family name.
+	metadata map[string]metadata
+}
+
+// receivedSeries is a decoded series with its float samples. Native histogram
+// samples and exemplars are only counted.
+type receivedSeries struct {
+	labels     []label
+	samples    []sample
+	histograms int
+	exemplars  int
+	// metadata of remote write 2.0, if any.
+	metadata *metadata
+}
+
+type sample struct {
+	value     float64
+	timestamp int64
+}
+
+type metadata struct {
+	typ  uint64
+	help string
+}
+
+// decodeWriteV1 decodes a remote-write 1.0 WriteRequest.
+func decodeWriteV1(b []byte) (*writeRequest, error) {
+	req := &writeRequest{metadata: map[string]metadata{}}
+	err := consumeFields(b, func(num protowire.Number, v []byte) error {
+		switch num {
+		case v1WriteRequestTimeseries:
+			s, err := decodeWriteV1Series(v)
+			if err != nil {
+				return err
+			}
+			req.series = append(req.series, s)
+		case v1WriteRequestMetadata:
+			var (
+				name string
+				md   metadata
+			)
+			err := consumeFields(v, func(num protowire.Number, v []byte) error {
+				switch num {
+				case v1MetadataType:
+					md.typ = varintValue(v)
+				case v1MetadataFamilyName:
+					name = string(v)
+				case v1MetadataHelp:
+					md.help = string(v)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			req.metadata[name] = md
+		}
+		return nil
+	})
+	return req, err
+}
+
+func decodeWriteV1Series(b []byte) (receivedSeries, error) {
+	var s receivedSeries
+	err := consumeFields(b, func(num protowire.Number, v []byte) error {
+		switch num {
+		case v1TimeSeriesLabels:
+			var l label
+			if err := consumeFields(v, func(num protowire.Number, v []byte) error {
+				switch num {
+				case v1LabelName:
+					l.name = string(v)
+				case v1LabelValue:
+					l.value = string(v)
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			s.labels = append(s.labels, l)
+		case v1TimeSeriesSamples:
+			smpl, err := consumeSample(v, v1SampleValue, v1SampleTimestamp)
+			if err != nil {
+				return err
+			}
+			s.samples = append(s.samples, smpl)
+		case v1TimeSeriesExemplars:
+			s.exemplars++
+		case v1TimeSeriesHistograms:
+			s.histograms++
+		}
+		return nil
+	})
+	return s, err
+}
+
+// decodeWriteV2 decodes a remote-write 2.0 Request, resolving all symbol
+// references.
+func decodeWriteV2(b []byte) (*writeRequest, error) {
+	// The symbols may follow the series, so collect them first.
+	var (
+		symbols []string
+		series  [][]byte
+	)
+	err := consumeFields(b, func(num protowire.Number, v []byte) error {
+		switch num {
+		case v2RequestSymbols:
+			symbols = append(symbols, string(v))
+		case v2RequestTimeseries:
+			series = append(series, v)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	symbol := func(ref uint64) (string, error) {
+		if ref >= uint64(len(symbols)) {
+			return "", fmt.Errorf("symbol reference %d out of range", ref)
+		}
+		return symbols[ref], nil
+	}
+
+	req := &writeRequest{series: make([]receivedSeries, 0, len(series))}
+	for _, b := range series {
+		var (
+			s    receivedSeries
+			refs []uint64
+		)
+		err := consumeFields(b, func(num protowire.Number, v []byte) error {
+			switch num {
+			case v2TimeSeriesLabelsRefs:
+				var err error
+				if refs, err = appendPackedVarints(refs, v); err != nil {
+					return err
+				}
+			case v2TimeSeriesSamples:
+				smpl, err := consumeSample(v, v2SampleValue, v2SampleTimestamp)
+				if err != nil {
+					return err
+				}
+				s.samples = append(s.samples, smpl)
+			case v2TimeSeriesHistograms:
+				s.histograms++
+			case v2TimeSeriesExemplars:
+				s.exemplars++
+			case v2TimeSeriesMetadata:
+				md := &metadata{}
+				if err := consumeFields(v, func(num protowire.Number, v []byte) error {
+					switch num {
+					case v2MetadataType:
+						md.typ = varintValue(v)
+					case v2MetadataHelpRef:
+						var err error
+						md.help, err = symbol(varintValue(v))
+						return err
+					}
+					return nil
+				}); err != nil {
+					return err
+				}
+				s.metadata = md
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(refs)%2 != 0 {
+			return nil, errors.New("odd number of label references")
+		}
+		for i := 0; i < len(refs); i += 2 {
+			name, err := symbol(refs[i])
+			if err != nil {
+				return nil, err
+			}
+			value, err := symbol(refs[i+1])
+			if err != nil {
+				return nil, err
+			}
+			s.labels = append(s.labels, label{name, value})
+		}
+		req.series = append(req.series, s)
+	}
+	return req, nil
+}
+
+// consumeSample decodes a Sample message of either version.
+func consumeSample(b []byte, valueNum, timestampNum protowire.Number) (sample, error) {
+	var s sample
+	err := consumeFields(b, func(num protowire.Number, v []byte) error {
+		switch num {
+		case valueNum:
+			bits, n := protowire.ConsumeFixed64(v)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			s.value = math.Float64frombits(bits)
+		case timestampNum:
+			s.timestamp = int64(varintValue(v))
+		}
+		return nil
+	})
+	return s, err
+}
+
+// consumeFields calls fn with the number and the value of each field of the
+// protobuf message b. The value of a length-delimited field is its content,
+// the value of any other field is its encoded value.
+func consumeFields(b []byte, fn func(num protowire.Number, v []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		n = protowire.ConsumeFieldValue(num, typ, b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		v := b[:n]
+		if typ == protowire.BytesType {
+			v, _ = protowire.ConsumeBytes(v)
+		}
+		if err := fn(num, v); err != nil {
+			return err
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+// varintValue returns the value of an encoded varint, or 0 if it is invalid.
+func varintValue(b []byte) uint64 {
+	v, n := protowire.ConsumeVarint(b)
+	if n < 0 {
+		return 0
+	}
+	return v
+}
+
+// appendPackedVarints appends the varints of a packed repeated field, or of a
+// single unpacked one, to vs.
+func appendPackedVarints(vs []uint64, b []byte) ([]uint64, error) {
+	for len(b) > 0 {
+		v, n := protowire.ConsumeVarint(b)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		vs = append(vs, v)
+		b = b[n:]
+	}
+	return vs, nil
+}
@@ -0,0 +1,414 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotewrite
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// ProtoMsg is the fully qualified name of the protobuf message of a
+// remote-write request, which determines the protocol version.
+type ProtoMsg string
+
+const (
+	// WriteV1 is the WriteRequest message of remote write 1.0.
+	WriteV1 ProtoMsg = "prometheus.WriteRequest"
+	// WriteV2 is the Request message of remote write 2.0, which interns all
+	// strings in a symbol table and carries metadata per series.
+	WriteV2 ProtoMsg = "io.prometheus.write.v2.Request"
+)
+
+// Validate returns an error if m is not a supported message.
+func (m ProtoMsg) Validate() error {
+	switch m {
+	case WriteV1, WriteV2:
+		return nil
+	default:
+		return fmt.Errorf("unknown remote-write protobuf message %q", string(m))
+	}
+}
+
+// ContentType returns the Content-Type header of a request with message m.
+// For WriteV1, it is the plain type mandated by remote write 1.0 to not
+// confuse older receivers.
+func (m ProtoMsg) ContentType() string {
+	if m == WriteV1 {
+		return "application/x-protobuf"
+	}
+	return "application/x-protobuf;proto=" + string(m)
+}
+
+// Version returns the X-Prometheus-Remote-Write-Version header of a request
+// with message m.
+func (m ProtoMsg) Version() string {
+	if m == WriteV1 {
+		return "0.1.0"
+	}
+	return "2.0.0"
+}
+
+// WriteStats are the numbers of float samples, native histogram samples, and
+// exemplars in a remote-write request.
+type WriteStats struct {
+	Samples    int
+	Histograms int
+	Exemplars  int
+	// Confirmed is true if the numbers were reported by the receiver (as
+	// remote write 2.0 receivers do) rather than counted by the sender.
+	Confirmed bool
+}
+
+// MarshalOpts are options for Marshal.
+type MarshalOpts struct {
+	// ExternalLabels are added to every series that doesn't already have a
+	// label with the same name.
+	ExternalLabels map[string]string
+	// Timestamp is used for samples of metrics without an explicit
+	// timestamp. Defaults to the current time.
+	Timestamp time.Time
+}
+
+// Marshal encodes the metric families as the uncompressed body of a
+// remote-write request with message msg. Summaries and classic histograms
+// are split into their series like in the text format, while native
+// histograms are sent as histogram samples. A histogram with both classic
+// and native buckets is sent both ways.
+func Marshal(msg ProtoMsg, mfs []*dto.MetricFamily, opts MarshalOpts) ([]byte, WriteStats, error) {
+	now := opts.Timestamp
+	if now.IsZero() {
+		now = time.Now()
+	}
+	return marshal(msg, mfs, toLabels(opts.ExternalLabels), now.UnixMilli(), nil)
+}
+
+// marshal is like Marshal, but additionally encodes a staleness marker
+// sample for each of the stale series.
+func marshal(msg ProtoMsg, mfs []*dto.MetricFamily, extLabels []label, now int64, stale [][]label) ([]byte, WriteStats, error) {
+	if err := msg.Validate(); err != nil {
+		return nil, WriteStats{}, err
+	}
+	if msg == WriteV1 {
+		b, stats := encodeV1(mfs, extLabels, now, stale)
+		return b, stats, nil
+	}
+	b, stats := encodeV2(mfs, extLabels, now, stale)
+	return b, stats, nil
+}
+
+type label struct {
+	name, value string
+}
+
+// toLabels returns the labels of m in undefined order.
+func toLabels(m map[string]string) []label {
+	labels := make([]label, 0, len(m))
+	for ln, lv := range m {
+		labels = append(labels, label{ln, lv})
+	}
+	return labels
+}
+
+// series is a single series derived from a metric, carrying either a float
+// sample or a native histogram sample.
+type series struct {
+	labels []label
+	value  float64
+	// histogram is set for native histogram samples.
+	histogram *dto.Histogram
+	gauge     bool
+	timestamp int64
+	// created is the created timestamp of the metric in milliseconds, or
+	// zero if unknown.
+	created  int64
+	exemplar *dto.Exemplar
+}
+
+// forEachSeries calls fn for each series of the metric family mf.
+func forEachSeries(mf *dto.MetricFamily, extLabels []label, now int64, fn func(s *series)) {
+	name := mf.GetName()
+	for _, m := range mf.GetMetric() {
+		ts := now
+		if m.TimestampMs != nil {
+			ts = m.GetTimestampMs()
+		}
+		labels := make([]label, 0, len(m.GetLabel())+len(extLabels))
+		for _, lp := range m.GetLabel() {
+			labels = append(labels, label{lp.GetName(), lp.GetValue()})
+		}
+	ext:
+		for _, el := range extLabels {
+			for _, l := range labels {
+				if l.name == el.name {
+					continue ext
+				}
+			}
+			labels = append(labels, el)
+		}
+		var created int64
+		sample := func(suffix string, extra *label, v float64, e *dto.Exemplar) {
+			fn(&series{
+				labels:    seriesLabels(name+suffix, labels, extra),
+				value:     v,
+				timestamp: ts,
+				created:   created,
+				exemplar:  e,
+			})
+		}
+
+		switch mf.GetType() {
+		case dto.MetricType_COUNTER:
+			created = createdMs(m.GetCounter().GetCreatedTimestamp())
+			sample("", nil, m.GetCounter().GetValue(), m.GetCounter().GetExemplar())
+		case dto.MetricType_GAUGE:
+			sample("", nil, m.GetGauge().GetValue(), nil)
+		case dto.MetricType_SUMMARY:
+			s := m.GetSummary()
+			created = createdMs(s.GetCreatedTimestamp())
+			for _, q := range s.GetQuantile() {
+				sample("", &label{"quantile", formatFloat(q.GetQuantile())}, q.GetValue(), nil)
+			}
+			sample("_sum", nil, s.GetSampleSum(), nil)
+			sample("_count", nil, float64(s.GetSampleCount()), nil)
+		case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+			h := m.GetHistogram()
+			created = createdMs(h.GetCreatedTimestamp())
+			isNative := h.GetZeroThreshold() > 0 || h.GetZeroCount() > 0 || h.GetZeroCountFloat() > 0 ||
+				len(h.GetPositiveSpan()) > 0 || len(h.GetNegativeSpan()) > 0
+			if isNative {
+				var e *dto.Exemplar
+				if exemplars := h.GetExemplars(); len(exemplars) > 0 {
+					e = exemplars[len(exemplars)-1]
+				}
+				fn(&series{
+					labels:    seriesLabels(name, labels, nil),
+					histogram: h,
+					gauge:     mf.GetType() == dto.MetricType_GAUGE_HISTOGRAM,
+					timestamp: ts,
+					created:   created,
+					exemplar:  e,
+				})
+				if len(h.GetBucket()) == 0 {
+					continue
+				}
+			}
+			sawInf := false
+			for _, bucket := range h.GetBucket() {
+				if math.IsInf(bucket.GetUpperBound(), +1) {
+					sawInf = true
+				}
+				count := float64(bucket.GetCumulativeCount())
+				if bucket.CumulativeCountFloat != nil {
+					count = bucket.GetCumulativeCountFloat()
+				}
+				sample("_bucket", &label{"le", formatFloat(bucket.GetUpperBound())}, count, bucket.GetExemplar())
+			}
+			count := float64(h.GetSampleCount())
+			if h.SampleCountFloat != nil {
+				count = h.GetSampleCountFloat()
+			}
+			if !sawInf {
+				sample("_bucket", &label{"le", "+Inf"}, count, nil)
+			}
+			sample("_sum", nil, h.GetSampleSum(), nil)
+			sample("_count", nil, count, nil)
+		default:
+			sample("", nil, m.GetUntyped().GetValue(), nil)
+		}
+	}
+}
+
+// createdMs returns ts in milliseconds, or zero if ts is nil.
+func createdMs(ts *timestamppb.Timestamp) int64 {
+	if ts == nil {
+		return 0
+	}
+	return ts.AsTime().UnixMilli()
+}
+
+// seriesLabels returns the sorted labels of a series with the provided name,
+// the provided labels, and an optional extra label.
+func seriesLabels(name string, labels []label, extra *label) []label {
+	ls := make([]label, 0, len(labels)+2)
+	ls = append(ls, label{"__name__", name})
+	ls = append(ls, labels...)
+	if extra != nil {
+		ls = append(ls, *extra)
+	}
+	sort.Slice(ls, func(i, j int) bool { return ls[i].name < ls[j].name })
+	return ls
+}
+
+// count adds s to the stats.
+func (st *WriteStats) count(s *series) {
+	if s.histogram != nil {
+		st.Histograms++
+	} else {
+		st.Samples++
+	}
+	if s.exemplar != nil {
+		st.Exemplars++
+	}
+}
+
+// metricTypes maps the metric types to the MetricType enums of remote write,
+// which have the same values in both versions.
+var metricTypes = map[dto.MetricType]uint64{
+	dto.MetricType_COUNTER:         1,
+	dto.MetricType_GAUGE:           2,
+	dto.MetricType_HISTOGRAM:       3,
+	dto.MetricType_GAUGE_HISTOGRAM: 4,
+	dto.MetricType_SUMMARY:         5,
+}
+
+// Field numbers of the Histogram and BucketSpan messages, which are the same
+// in both versions.
+const (
+	histogramCountInt       = 1
+	histogramCountFloat     = 2
+	histogramSum            = 3
+	histogramSchema         = 4
+	histogramZeroThreshold  = 5
+	histogramZeroCountInt   = 6
+	histogramZeroCountFloat = 7
+	histogramNegativeSpans  = 8
+	histogramNegativeDeltas = 9
+	histogramNegativeCounts = 10
+	histogramPositiveSpans  = 11
+	histogramPositiveDeltas = 12
+	histogramPositiveCounts = 13
+	histogramResetHint      = 14
+	histogramTimestamp      = 15
+
+	bucketSpanOffset = 1
+	bucketSpanLength = 2
+
+	resetHintGauge = 3
+)
+
+// appendHistogram appends the native histogram sample of s as a Histogram
+// message.
+func appendHistogram(b []byte, num protowire.Number, s *series) []byte {
+	h := s.histogram
+	var hb []byte
+	isFloat := h.SampleCountFloat != nil
+	if isFloat {
+		hb = appendDouble(hb, histogramCountFloat, h.GetSampleCountFloat())
+	} else {
+		hb = appendVarint(hb, histogramCountInt, h.GetSampleCount())
+	}
+	hb = appendDouble(hb, histogramSum, h.GetSampleSum())
+	hb = appendVarint(hb, histogramSchema, protowire.EncodeZigZag(int64(h.GetSchema())))
+	hb = appendDouble(hb, histogramZeroThreshold, h.GetZeroThreshold())
+	if isFloat {
+		hb = appendDouble(hb, histogramZeroCountFloat, h.GetZeroCountFloat())
+	} else {
+		hb = appendVarint(hb, histogramZeroCountInt, h.GetZeroCount())
+	}
+	hb = appendSpans(hb, histogramNegativeSpans, h.GetNegativeSpan())
+	hb = appendDeltas(hb, histogramNegativeDeltas, h.GetNegativeDelta())
+	hb = appendCounts(hb, histogramNegativeCounts, h.GetNegativeCount())
+	hb = appendSpans(hb, histogramPositiveSpans, h.GetPositiveSpan())
+	hb = appendDeltas(hb, histogramPositiveDeltas, h.GetPositiveDelta())
+	hb = appendCounts(hb, histogramPositiveCounts, h.GetPositiveCount())
+	if s.gauge {
+		hb = appendVarint(hb, histogramResetHint, resetHintGauge)
+	}
+	hb = appendVarint(hb, histogramTimestamp, uint64(s.timestamp))
+	return appendMessage(b, num, hb)
+}
+
+// exemplarTimestamp returns the timestamp of e, or ts if e has none.
+func exemplarTimestamp(e *dto.Exemplar, ts int64) int64 {
+	if e.Timestamp != nil {
+		return e.GetTimestamp().AsTime().UnixMilli()
+	}
+	return ts
+}
+
+func appendSpans(b []byte, num protowire.Number, spans []*dto.BucketSpan) []byte {
+	for _, s := range spans {
+		sb := appendVarint(nil, bucketSpanOffset, protowire.EncodeZigZag(int64(s.GetOffset())))
+		sb = appendVarint(sb, bucketSpanLength, uint64(s.GetLength()))
+		b = appendMessage(b, num, sb)
+	}
+	return b
+}
+
+// appendDeltas appends deltas as packed sint64 field.
+func appendDeltas(b []byte, num protowire.Number, deltas []int64) []byte {
+	if len(deltas) == 0 {
+		return b
+	}
+	var packed []byte
+	for _, d := range deltas {
+		packed = protowire.AppendVarint(packed, protowire.EncodeZigZag(d))
+	}
+	return appendMessage(b, num, packed)
+}
+
+// appendCounts appends counts as packed double field.
+func appendCounts(b []byte, num protowire.Number, counts []float64) []byte {
+	if len(counts) == 0 {
+		return b
+	}
+	var packed []byte
+	for _, c := range counts {
+		packed = protowire.AppendFixed64(packed, math.Float64bits(c))
+	}
+	return appendMessage(b, num, packed)
+}
+
+func appendMessage(b []byte, num protowire.Number, msg []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
+
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+func appendDouble(b []byte, num protowire.Number, v float64) []byte {
+	b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, math.Float64bits(v))
+}
+
+func appendVarint(b []byte, num protowire.Number, v uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+// formatFloat formats a float like the text format does for label values.
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, +1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+}
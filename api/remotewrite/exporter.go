@@ -0,0 +1,215 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotewrite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const defaultExportInterval = 15 * time.Second
+
+// staleNaN is the special NaN value Prometheus uses as staleness marker,
+// which ends a series immediately instead of after the lookback delta.
+var staleNaN = math.Float64frombits(0x7ff0000000000002)
+
+// ExporterConfig defines configuration parameters for a new Exporter.
+type ExporterConfig struct {
+	// Gatherer is the source of the exported metrics. Defaults to
+	// prometheus.DefaultGatherer.
+	Gatherer prometheus.Gatherer
+
+	// Interval between two exports. Defaults to 15s.
+	Interval time.Duration
+
+	// Timeout of a single export, including retries. Defaults to Interval.
+	Timeout time.Duration
+
+	// ErrorLog, if not nil, is used to log failed exports of Run.
+	ErrorLog Logger
+}
+
+// Logger is the minimal interface Exporter needs for logging. Note that
+// log.Logger from the standard library implements this interface, and it is
+// easy to implement by custom loggers, if they don't do so already anyway.
+type Logger interface {
+	Println(v ...interface{})
+}
+
+// Exporter periodically writes the metrics of a Gatherer to a remote-write
+// endpoint, which allows to push metrics without running a Prometheus server
+// or agent that scrapes them.
+//
+// Like a Prometheus server scraping the metrics, the Exporter writes a
+// staleness marker for every series that disappeared since the last
+// successful export, so that the series ends immediately rather than after
+// the lookback delta of the receiver. It is safe to use an Exporter from
+// multiple goroutines.
+type Exporter struct {
+	client   *Client
+	g        prometheus.Gatherer
+	interval time.Duration
+	timeout  time.Duration
+	errorLog Logger
+
+	mtx sync.Mutex
+	// last are the series of the last successful export by their key.
+	last map[string][]label
+}
+
+// NewExporter returns a new Exporter that writes with the provided Client.
+func NewExporter(c *Client, cfg ExporterConfig) (*Exporter, error) {
+	if c == nil {
+		return nil, errors.New("remote-write client is missing")
+	}
+	e := &Exporter{
+		client:   c,
+		g:        cfg.Gatherer,
+		interval: cfg.Interval,
+		timeout:  cfg.Timeout,
+		errorLog: cfg.ErrorLog,
+	}
+	if e.g == nil {
+		e.g = prometheus.DefaultGatherer
+	}
+	if e.interval <= 0 {
+		e.interval = defaultExportInterval
+	}
+	if e.timeout <= 0 {
+		e.timeout = e.interval
+	}
+	return e, nil
+}
+
+// Run exports the metrics at the configured interval until ctx is canceled.
+// Failed exports are logged to the ErrorLog. Before returning, Run writes
+// staleness markers for all series of the last successful export, as the
+// series end with the Exporter.
+func (e *Exporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(ctx, e.timeout)
+			_, err := e.Export(ctx)
+			cancel()
+			if err != nil && e.errorLog != nil {
+				e.errorLog.Println("error exporting metrics:", err)
+			}
+		case <-ctx.Done():
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), e.timeout)
+			err := e.MarkStale(ctx)
+			cancel()
+			if err != nil && e.errorLog != nil {
+				e.errorLog.Println("error writing staleness markers:", err)
+			}
+			return
+		}
+	}
+}
+
+// Export gathers the metrics and writes them, together with staleness markers
+// for the series that disappeared since the last successful export. If
+// gathering fails, nothing is written, so that series missing due to the
+// failure aren't marked stale.
+func (e *Exporter) Export(ctx context.Context) (WriteStats, error) {
+	mfs, err := e.g.Gather()
+	if err != nil {
+		return WriteStats{}, fmt.Errorf("gathering metrics failed: %w", err)
+	}
+
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	now := time.Now()
+	current := e.seriesByKey(mfs, now.UnixMilli())
+	stats, err := e.client.write(ctx, mfs, now, staleSeries(e.last, current))
+	if err != nil {
+		// Keep the last series, so that the staleness markers are
+		// written with the next successful export.
+		return stats, err
+	}
+	e.last = current
+	return stats, nil
+}
+
+// MarkStale writes staleness markers for all series of the last successful
+// export, e.g. before shutting down, and forgets them.
+func (e *Exporter) MarkStale(ctx context.Context) error {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	stale := staleSeries(e.last, nil)
+	if len(stale) == 0 {
+		return nil
+	}
+	if _, err := e.client.write(ctx, nil, time.Now(), stale); err != nil {
+		return err
+	}
+	e.last = nil
+	return nil
+}
+
+// seriesByKey returns the labels of all series of the metric families as
+// written by the client, by their key.
+func (e *Exporter) seriesByKey(mfs []*dto.MetricFamily, now int64) map[string][]label {
+	extLabels := toLabels(e.client.extLabels)
+	byKey := map[string][]label{}
+	for _, mf := range mfs {
+		forEachSeries(mf, extLabels, now, func(s *series) {
+			byKey[seriesKey(s.labels)] = s.labels
+		})
+	}
+	return byKey
+}
+
+// staleSeries returns the series in last that are missing in current, sorted
+// by key.
+func staleSeries(last, current map[string][]label) [][]label {
+	keys := make([]string, 0, len(last))
+	for k := range last {
+		if _, ok := current[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	stale := make([][]label, 0, len(keys))
+	for _, k := range keys {
+		stale = append(stale, last[k])
+	}
+	return stale
+}
+
+// seriesKey returns a key identifying the sorted labels.
+func seriesKey(labels []label) string {
+	var sb strings.Builder
+	for _, l := range labels {
+		sb.WriteString(l.name)
+		sb.WriteByte('\xff')
+		sb.WriteString(l.value)
+		sb.WriteByte('\xff')
+	}
+	return sb.String()
+}
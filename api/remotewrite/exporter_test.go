@@ -0,0 +1,179 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotewrite
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// recordingServer returns a server that records the samples of each request
+// of the provided protocol version and responds with status.
+func recordingServer(t *testing.T, msg ProtoMsg, status *int) (*httptest.Server, func() [][]string) {
+	var (
+		mtx      sync.Mutex
+		requests [][]string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		compressed, _ := io.ReadAll(r.Body)
+		body, err := snappy.Decode(nil, compressed)
+		if err != nil {
+			t.Error(err)
+		}
+		mtx.Lock()
+		defer mtx.Unlock()
+		if *status/100 == 2 {
+			if msg == WriteV1 {
+				requests = append(requests, decodeV1(t, body))
+			} else {
+				requests = append(requests, decodeV2(t, body))
+			}
+		}
+		w.WriteHeader(*status)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() [][]string {
+		mtx.Lock()
+		defer mtx.Unlock()
+		return requests
+	}
+}
+
+func TestExporterStaleness(t *testing.T) {
+	for _, msg := range []ProtoMsg{WriteV1, WriteV2} {
+		t.Run(string(msg), func(t *testing.T) {
+			status := http.StatusNoContent
+			server, requests := recordingServer(t, msg, &status)
+
+			gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+				Name: "queue_length",
+				Help: "Queue length.",
+			}, []string{"queue"})
+			gauge.WithLabelValues("a").Set(1)
+			gauge.WithLabelValues("b").Set(2)
+			reg := prometheus.NewRegistry()
+			reg.MustRegister(gauge)
+
+			c, err := NewClient(Config{
+				URL:            server.URL,
+				ProtoMsg:       msg,
+				ExternalLabels: map[string]string{"job": "batch"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			e, err := NewExporter(c, ExporterConfig{Gatherer: reg})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := context.Background()
+
+			if _, err := e.Export(ctx); err != nil {
+				t.Fatal(err)
+			}
+			gauge.DeleteLabelValues("a")
+			// A failed export must not lose the staleness marker.
+			status = http.StatusBadRequest
+			if _, err := e.Export(ctx); err == nil {
+				t.Fatal("expected export to fail")
+			}
+			status = http.StatusNoContent
+			stats, err := e.Export(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stats.Samples != 2 {
+				t.Errorf("got %d samples, want 2", stats.Samples)
+			}
+			if _, err := e.Export(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if err := e.MarkStale(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			want := [][]string{
+				{`queue_length{job="batch",queue="a"} 1`, `queue_length{job="batch",queue="b"} 2`},
+				{`queue_length{job="batch",queue="a"} NaN`, `queue_length{job="batch",queue="b"} 2`},
+				{`queue_length{job="batch",queue="b"} 2`},
+				{`queue_length{job="batch",queue="b"} NaN`},
+			}
+			got := requests()
+			if len(got) != len(want) {
+				t.Fatalf("got %d requests, want %d: %q", len(got), len(want), got)
+			}
+			for i := range want {
+				if strings.Join(got[i], "\n") != strings.Join(want[i], "\n") {
+					t.Errorf("request %d: got\n%s\nwant\n%s", i, strings.Join(got[i], "\n"), strings.Join(want[i], "\n"))
+				}
+			}
+		})
+	}
+}
+
+func TestExporterRun(t *testing.T) {
+	status := http.StatusNoContent
+	server, requests := recordingServer(t, WriteV1, &status)
+
+	reg := prometheus.NewRegistry()
+	up := prometheus.NewGauge(prometheus.GaugeOpts{Name: "up", Help: "Up."})
+	up.Set(1)
+	reg.MustRegister(up)
+
+	c, err := NewClient(Config{URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := NewExporter(c, ExporterConfig{Gatherer: reg, Interval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		e.Run(ctx)
+		close(done)
+	}()
+	for len(requests()) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	got := requests()
+	if last := strings.Join(got[len(got)-1], "\n"); last != `up{} NaN` {
+		t.Errorf("got last request %q, want staleness marker", last)
+	}
+	for _, r := range got[:len(got)-1] {
+		if s := strings.Join(r, "\n"); s != `up{} 1` {
+			t.Errorf("got request %q, want sample", s)
+		}
+	}
+}
+
+func TestNewExporterWithoutClient(t *testing.T) {
+	if _, err := NewExporter(nil, ExporterConfig{}); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotewrite
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// clientMetrics are the metrics a Client reports about itself, shared by all
+// Clients registering with the same Registerer. A nil *clientMetrics is valid
+// and records nothing.
+type clientMetrics struct {
+	requests   *prometheus.CounterVec
+	duration   prometheus.Histogram
+	sentBytes  prometheus.Counter
+	samples    prometheus.Counter
+	histograms prometheus.Counter
+	exemplars  prometheus.Counter
+	retries    prometheus.Counter
+}
+
+func newClientMetrics(reg prometheus.Registerer) (*clientMetrics, error) {
+	m := &clientMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "remote_write_client_requests_total",
+			Help: "Total number of HTTP requests sent to the remote-write endpoint by status code.",
+		}, []string{"code"}),
+		duration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "remote_write_client_request_duration_seconds",
+			Help:    "Duration of HTTP requests sent to the remote-write endpoint.",
+			Buckets: prometheus.DefBuckets,
+		}),
+		sentBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "remote_write_client_sent_bytes_total",
+			Help: "Total number of compressed bytes sent to the remote-write endpoint.",
+		}),
+		samples: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "remote_write_client_samples_sent_total",
+			Help: "Total number of float samples written to the remote-write endpoint.",
+		}),
+		histograms: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "remote_write_client_histograms_sent_total",
+			Help: "Total number of native histogram samples written to the remote-write endpoint.",
+		}),
+		exemplars: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "remote_write_client_exemplars_sent_total",
+			Help: "Total number of exemplars written to the remote-write endpoint.",
+		}),
+		retries: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "remote_write_client_retries_total",
+			Help: "Total number of retried requests to the remote-write endpoint.",
+		}),
+	}
+	var err error
+	if m.requests, err = register(reg, m.requests); err != nil {
+		return nil, err
+	}
+	if m.duration, err = register(reg, m.duration); err != nil {
+		return nil, err
+	}
+	if m.sentBytes, err = register(reg, m.sentBytes); err != nil {
+		return nil, err
+	}
+	if m.samples, err = register(reg, m.samples); err != nil {
+		return nil, err
+	}
+	if m.histograms, err = register(reg, m.histograms); err != nil {
+		return nil, err
+	}
+	if m.exemplars, err = register(reg, m.exemplars); err != nil {
+		return nil, err
+	}
+	if m.retries, err = register(reg, m.retries); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// register registers c with reg. If an equal collector is already registered
+// (e.g. by another Client), the existing one is returned instead.
+func register[T prometheus.Collector](reg prometheus.Registerer, c T) (T, error) {
+	if err := reg.Register(c); err != nil {
+		are := &prometheus.AlreadyRegisteredError{}
+		if errors.As(err, are) {
+			if existing, ok := are.ExistingCollector.(T); ok {
+				return existing, nil
+			}
+		}
+		return c, err
+	}
+	return c, nil
+}
+
+func (m *clientMetrics) observeRequest(resp *http.Response, size int, d time.Duration) {
+	if m == nil {
+		return
+	}
+	code := "error"
+	if resp != nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	m.requests.WithLabelValues(code).Inc()
+	m.duration.Observe(d.Seconds())
+	m.sentBytes.Add(float64(size))
+}
+
+func (m *clientMetrics) observeRetry() {
+	if m == nil {
+		return
+	}
+	m.retries.Inc()
+}
+
+func (m *clientMetrics) observeWrite(stats WriteStats) {
+	if m == nil {
+		return
+	}
+	m.samples.Add(float64(stats.Samples))
+	m.histograms.Add(float64(stats.Histograms))
+	m.exemplars.Add(float64(stats.Exemplars))
+}
@@ -0,0 +1,347 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotewrite
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	defaultReceiverTTL         = 5 * time.Minute
+	defaultReceiverMaxBodySize = 32 << 20
+)
+
+// ReceiverOpts defines the behavior of a Receiver created with NewReceiver.
+type ReceiverOpts struct {
+	// TTL is the time after which a received series is dropped if it
+	// hasn't been updated. Defaults to 5m.
+	TTL time.Duration
+	// If true, the series are exposed with the timestamp of their most
+	// recent sample. Otherwise, they are exposed without timestamp, like
+	// other metrics of the registry.
+	KeepTimestamps bool
+	// MaxBodySize is the maximum size of a request body, both compressed
+	// and decompressed. Larger requests are rejected with 413 Request
+	// Entity Too Large. Defaults to 32MiB.
+	MaxBodySize int64
+}
+
+// Receiver is an http.Handler accepting remote-write requests, and a
+// prometheus.Collector exposing the most recent sample of each received series
+// as a const metric. Registered with a registry whose metrics are exposed, it
+// turns a program into a lightweight aggregation point for the metrics its
+// satellite processes push, e.g. short-lived jobs or processes without an
+// exposition endpoint. It is safe to use a Receiver from multiple goroutines.
+//
+// Both remote write 1.0 and 2.0 requests are accepted, compressed with snappy
+// (as the protocol mandates) or zstd. The metric types and help strings of the
+// metadata are used for the exposed metrics, which are untyped if there is no
+// metadata. Native histogram samples and exemplars are not supported and
+// ignored; the headers of a remote write 2.0 response report them as not
+// written. A staleness marker removes its series right away. A request with
+// an invalid series is rejected as a whole with 400 Bad Request.
+//
+// The Receiver is an unchecked Collector, as the received metrics are not
+// known in advance. Metrics received with the same names as metrics of other
+// collectors of the registry make gathering fail.
+type Receiver struct {
+	ttl            time.Duration
+	keepTimestamps bool
+	maxBodySize    int64
+	now            func() time.Time
+
+	mtx    sync.Mutex
+	series map[string]*storedSeries
+	// families are the help strings and types from the metadata by metric
+	// name.
+	families map[string]family
+}
+
+type storedSeries struct {
+	name                    string
+	labelNames, labelValues []string
+	value                   float64
+	timestamp               int64
+	received                time.Time
+}
+
+type family struct {
+	help string
+	typ  prometheus.ValueType
+}
+
+// NewReceiver returns a new Receiver.
+func NewReceiver(opts ReceiverOpts) *Receiver {
+	r := &Receiver{
+		ttl:            opts.TTL,
+		keepTimestamps: opts.KeepTimestamps,
+		maxBodySize:    opts.MaxBodySize,
+		now:            time.Now,
+		series:         map[string]*storedSeries{},
+		families:       map[string]family{},
+	}
+	if r.ttl <= 0 {
+		r.ttl = defaultReceiverTTL
+	}
+	if r.maxBodySize <= 0 {
+		r.maxBodySize = defaultReceiverMaxBodySize
+	}
+	return r
+}
+
+// ServeHTTP implements http.Handler.
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	decode, err := requestDecoder(req.Header.Get("Content-Type"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, r.maxBodySize+1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if int64(len(body)) > r.maxBodySize {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	body, code, err := r.decompress(req.Header.Get("Content-Encoding"), body)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	wr, err := decode(body)
+	if err != nil {
+		http.Error(w, "decoding request failed: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	samples, err := r.apply(wr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set(samplesWrittenHeader, strconv.Itoa(samples))
+	w.Header().Set(histogramsWrittenHeader, "0")
+	w.Header().Set(exemplarsWrittenHeader, "0")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// requestDecoder returns the decoder for a request with the provided
+// Content-Type header.
+func requestDecoder(contentType string) (func([]byte) (*writeRequest, error), error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "application/x-protobuf" {
+		return nil, fmt.Errorf("unsupported content type %q", contentType)
+	}
+	switch ProtoMsg(params["proto"]) {
+	case "", WriteV1:
+		return decodeWriteV1, nil
+	case WriteV2:
+		return decodeWriteV2, nil
+	default:
+		return nil, fmt.Errorf("unsupported protobuf message %q", params["proto"])
+	}
+}
+
+// decompress decompresses a request body. It returns the HTTP status code to
+// respond with upon an error.
+func (r *Receiver) decompress(encoding string, body []byte) ([]byte, int, error) {
+	switch Compression(encoding) {
+	case Snappy, "":
+		n, err := snappy.DecodedLen(body)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		if int64(n) > r.maxBodySize {
+			return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("decompressed request body too large")
+		}
+		body, err = snappy.Decode(nil, body)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		return body, 0, nil
+	case Zstd:
+		dec, err := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(uint64(r.maxBodySize)), zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		defer dec.Close()
+		body, err = dec.DecodeAll(body, nil)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		return body, 0, nil
+	default:
+		return nil, http.StatusUnsupportedMediaType, fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+}
+
+// apply stores the series of a request, if they are all valid, and returns
+// the number of float samples written.
+func (r *Receiver) apply(wr *writeRequest) (int, error) {
+	type update struct {
+		key    string
+		series *storedSeries
+		sample sample
+	}
+	updates := make([]update, 0, len(wr.series))
+	samples := 0
+	for _, rs := range wr.series {
+		s, err := newStoredSeries(rs.labels)
+		if err != nil {
+			return 0, err
+		}
+		if len(rs.samples) == 0 {
+			continue
+		}
+		samples += len(rs.samples)
+		latest := rs.samples[0]
+		for _, smpl := range rs.samples[1:] {
+			if smpl.timestamp >= latest.timestamp {
+				latest = smpl
+			}
+		}
+		updates = append(updates, update{seriesKey(rs.labels), s, latest})
+	}
+
+	now := r.now()
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	for name, md := range wr.metadata {
+		r.setFamily(name, md)
+	}
+	for _, rs := range wr.series {
+		if rs.metadata != nil {
+			r.setFamily(metricName(rs.labels), *rs.metadata)
+		}
+	}
+	for _, u := range updates {
+		if math.Float64bits(u.sample.value) == math.Float64bits(staleNaN) {
+			delete(r.series, u.key)
+			continue
+		}
+		if old, ok := r.series[u.key]; ok && old.timestamp > u.sample.timestamp {
+			// Out of order.
+			continue
+		}
+		u.series.value = u.sample.value
+		u.series.timestamp = u.sample.timestamp
+		u.series.received = now
+		r.series[u.key] = u.series
+	}
+	return samples, nil
+}
+
+// setFamily sets the help string and type of the metric with the provided
+// name from the metadata.
+func (r *Receiver) setFamily(name string, md metadata) {
+	if name == "" {
+		return
+	}
+	f := family{help: md.help, typ: prometheus.UntypedValue}
+	switch md.typ {
+	case metricTypes[dto.MetricType_COUNTER]:
+		f.typ = prometheus.CounterValue
+	case metricTypes[dto.MetricType_GAUGE]:
+		f.typ = prometheus.GaugeValue
+	}
+	r.families[name] = f
+}
+
+// newStoredSeries returns a series with the provided labels, or an error if
+// they don't form a valid metric.
+func newStoredSeries(labels []label) (*storedSeries, error) {
+	s := &storedSeries{}
+	sorted := append([]label(nil), labels...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].name < sorted[j].name })
+	for _, l := range sorted {
+		if l.name == "__name__" {
+			s.name = l.value
+			continue
+		}
+		s.labelNames = append(s.labelNames, l.name)
+		s.labelValues = append(s.labelValues, l.value)
+	}
+	if s.name == "" {
+		return nil, fmt.Errorf("series without metric name: %v", labels)
+	}
+	desc := prometheus.NewDesc(s.name, "", s.labelNames, nil)
+	if _, err := prometheus.NewConstMetric(desc, prometheus.UntypedValue, 0, s.labelValues...); err != nil {
+		return nil, fmt.Errorf("invalid series %v: %w", labels, err)
+	}
+	return s, nil
+}
+
+// metricName returns the value of the __name__ label.
+func metricName(labels []label) string {
+	for _, l := range labels {
+		if l.name == "__name__" {
+			return l.value
+		}
+	}
+	return ""
+}
+
+// Describe implements prometheus.Collector. It sends no descriptors, which
+// makes the Receiver an unchecked Collector.
+func (r *Receiver) Describe(chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector. It drops the series that haven't
+// been updated within the TTL.
+func (r *Receiver) Collect(ch chan<- prometheus.Metric) {
+	expired := r.now().Add(-r.ttl)
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	for key, s := range r.series {
+		if s.received.Before(expired) {
+			delete(r.series, key)
+			continue
+		}
+		f, ok := r.families[s.name]
+		if !ok {
+			f.typ = prometheus.UntypedValue
+		}
+		desc := prometheus.NewDesc(s.name, f.help, s.labelNames, nil)
+		m, err := prometheus.NewConstMetric(desc, f.typ, s.value, s.labelValues...)
+		if err != nil {
+			ch <- prometheus.NewInvalidMetric(desc, err)
+			continue
+		}
+		if r.keepTimestamps {
+			m = prometheus.NewMetricWithTimestamp(time.UnixMilli(s.timestamp), m)
+		}
+		ch <- m
+	}
+}
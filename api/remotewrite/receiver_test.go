@@ -0,0 +1,167 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotewrite
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestReceiver(t *testing.T) {
+	for _, tc := range []struct {
+		msg         ProtoMsg
+		compression Compression
+	}{
+		{WriteV1, Snappy},
+		{WriteV2, Snappy},
+		{WriteV2, Zstd},
+	} {
+		t.Run(string(tc.msg)+"/"+string(tc.compression), func(t *testing.T) {
+			r := NewReceiver(ReceiverOpts{})
+			local := prometheus.NewRegistry()
+			local.MustRegister(r)
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			remote := prometheus.NewRegistry()
+			jobs := prometheus.NewCounterVec(prometheus.CounterOpts{
+				Name: "jobs_total",
+				Help: "Jobs processed.",
+			}, []string{"queue"})
+			jobs.WithLabelValues("a").Add(3)
+			jobs.WithLabelValues("b").Add(1)
+			temp := prometheus.NewGauge(prometheus.GaugeOpts{Name: "temperature_celsius", Help: "Temperature."})
+			temp.Set(21.5)
+			remote.MustRegister(jobs, temp)
+
+			c, err := NewClient(Config{
+				URL:            srv.URL,
+				ProtoMsg:       tc.msg,
+				Compression:    tc.compression,
+				ExternalLabels: map[string]string{"instance": "satellite"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			e, err := NewExporter(c, ExporterConfig{Gatherer: remote})
+			if err != nil {
+				t.Fatal(err)
+			}
+			stats, err := e.Export(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.msg == WriteV2 && (!stats.Confirmed || stats.Samples != 3) {
+				t.Errorf("got stats %+v, want 3 confirmed samples", stats)
+			}
+
+			expected := `# HELP jobs_total Jobs processed.
+# TYPE jobs_total counter
+jobs_total{instance="satellite",queue="a"} 3
+jobs_total{instance="satellite",queue="b"} 1
+# HELP temperature_celsius Temperature.
+# TYPE temperature_celsius gauge
+temperature_celsius{instance="satellite"} 21.5
+`
+			if err := testutil.GatherAndCompare(local, strings.NewReader(expected)); err != nil {
+				t.Fatal(err)
+			}
+
+			// A series that disappears is marked stale and removed.
+			jobs.DeleteLabelValues("b")
+			temp.Set(22)
+			if _, err := e.Export(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			expected = `# HELP jobs_total Jobs processed.
+# TYPE jobs_total counter
+jobs_total{instance="satellite",queue="a"} 3
+# HELP temperature_celsius Temperature.
+# TYPE temperature_celsius gauge
+temperature_celsius{instance="satellite"} 22
+`
+			if err := testutil.GatherAndCompare(local, strings.NewReader(expected)); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestReceiverTTL(t *testing.T) {
+	r := NewReceiver(ReceiverOpts{TTL: time.Minute, KeepTimestamps: true})
+	now := time.Unix(1000, 0)
+	r.now = func() time.Time { return now }
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	c, err := NewClient(Config{URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := prometheus.NewGauge(prometheus.GaugeOpts{Name: "g", Help: "A gauge."})
+	g.Set(1)
+	if _, err := c.WriteMetrics(context.Background(), g); err != nil {
+		t.Fatal(err)
+	}
+	if got := testutil.CollectAndCount(r); got != 1 {
+		t.Fatalf("got %d metrics, want 1", got)
+	}
+	now = now.Add(2 * time.Minute)
+	if got := testutil.CollectAndCount(r); got != 0 {
+		t.Errorf("got %d metrics after the TTL, want 0", got)
+	}
+}
+
+func TestReceiverErrors(t *testing.T) {
+	r := NewReceiver(ReceiverOpts{MaxBodySize: 1 << 10})
+	for _, tc := range []struct {
+		name        string
+		contentType string
+		encoding    string
+		body        []byte
+		want        int
+	}{
+		{"content type", "application/json", "", nil, http.StatusUnsupportedMediaType},
+		{"protobuf message", "application/x-protobuf;proto=foo", "", nil, http.StatusUnsupportedMediaType},
+		{"encoding", "application/x-protobuf", "gzip", nil, http.StatusUnsupportedMediaType},
+		{"corrupt body", "application/x-protobuf", "snappy", []byte("garbage"), http.StatusBadRequest},
+		{"too large", "application/x-protobuf", "snappy", snappy.Encode(nil, make([]byte, 2<<10)), http.StatusRequestEntityTooLarge},
+		{
+			"invalid series", "application/x-protobuf", "snappy",
+			snappy.Encode(nil, appendMessage(nil, v1WriteRequestTimeseries, appendV1Labels(nil, v1TimeSeriesLabels, []label{{"__name__", "x"}, {"__reserved", "y"}}))),
+			http.StatusBadRequest,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(tc.body))
+			req.Header.Set("Content-Type", tc.contentType)
+			req.Header.Set("Content-Encoding", tc.encoding)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			if rec.Code != tc.want {
+				t.Errorf("got status %d, want %d: %s", rec.Code, tc.want, rec.Body)
+			}
+		})
+	}
+}
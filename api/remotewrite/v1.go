@@ -0,0 +1,105 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotewrite
+
+import (
+	"google.golang.org/protobuf/encoding/protowire"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Field numbers of the remote-write 1.0 protobuf messages, see
+// https://github.com/prometheus/prometheus/blob/main/prompb/types.proto.
+const (
+	v1WriteRequestTimeseries = 1
+	v1WriteRequestMetadata   = 3
+
+	v1TimeSeriesLabels     = 1
+	v1TimeSeriesSamples    = 2
+	v1TimeSeriesExemplars  = 3
+	v1TimeSeriesHistograms = 4
+
+	v1LabelName  = 1
+	v1LabelValue = 2
+
+	v1SampleValue     = 1
+	v1SampleTimestamp = 2
+
+	v1ExemplarLabels    = 1
+	v1ExemplarValue     = 2
+	v1ExemplarTimestamp = 3
+
+	v1MetadataType       = 1
+	v1MetadataFamilyName = 2
+	v1MetadataHelp       = 4
+	v1MetadataUnit       = 5
+)
+
+// encodeV1 encodes the metric families as a remote-write 1.0 WriteRequest,
+// with one MetricMetadata entry per family, followed by a staleness marker for
+// each of the stale series.
+func encodeV1(mfs []*dto.MetricFamily, extLabels []label, now int64, stale [][]label) ([]byte, WriteStats) {
+	var (
+		b     []byte
+		stats WriteStats
+	)
+	for _, mf := range mfs {
+		forEachSeries(mf, extLabels, now, func(s *series) {
+			stats.count(s)
+			ts := appendV1Labels(nil, v1TimeSeriesLabels, s.labels)
+			if s.histogram != nil {
+				ts = appendHistogram(ts, v1TimeSeriesHistograms, s)
+			} else {
+				sb := appendDouble(nil, v1SampleValue, s.value)
+				sb = appendVarint(sb, v1SampleTimestamp, uint64(s.timestamp))
+				ts = appendMessage(ts, v1TimeSeriesSamples, sb)
+			}
+			if e := s.exemplar; e != nil {
+				labels := make([]label, 0, len(e.GetLabel()))
+				for _, lp := range e.GetLabel() {
+					labels = append(labels, label{lp.GetName(), lp.GetValue()})
+				}
+				eb := appendV1Labels(nil, v1ExemplarLabels, labels)
+				eb = appendDouble(eb, v1ExemplarValue, e.GetValue())
+				eb = appendVarint(eb, v1ExemplarTimestamp, uint64(exemplarTimestamp(e, s.timestamp)))
+				ts = appendMessage(ts, v1TimeSeriesExemplars, eb)
+			}
+			b = appendMessage(b, v1WriteRequestTimeseries, ts)
+		})
+
+		md := appendVarint(nil, v1MetadataType, metricTypes[mf.GetType()])
+		md = appendString(md, v1MetadataFamilyName, mf.GetName())
+		md = appendString(md, v1MetadataHelp, mf.GetHelp())
+		md = appendString(md, v1MetadataUnit, mf.GetUnit())
+		b = appendMessage(b, v1WriteRequestMetadata, md)
+	}
+	for _, labels := range stale {
+		stats.Samples++
+		ts := appendV1Labels(nil, v1TimeSeriesLabels, labels)
+		sb := appendDouble(nil, v1SampleValue, staleNaN)
+		sb = appendVarint(sb, v1SampleTimestamp, uint64(now))
+		ts = appendMessage(ts, v1TimeSeriesSamples, sb)
+		b = appendMessage(b, v1WriteRequestTimeseries, ts)
+	}
+	return b, stats
+}
+
+func appendV1Labels(b []byte, num protowire.Number, labels []label) []byte {
+	for _, l := range labels {
+		lb := appendString(nil, v1LabelName, l.name)
+		lb = appendString(lb, v1LabelValue, l.value)
+		b = appendMessage(b, num, lb)
+	}
+	return b
+}
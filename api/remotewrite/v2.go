@@ -0,0 +1,145 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotewrite
+
+import (
+	"google.golang.org/protobuf/encoding/protowire"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Field numbers of the remote-write 2.0 protobuf messages, see
+// https://github.com/prometheus/prometheus/blob/main/prompb/io/prometheus/write/v2/types.proto.
+const (
+	v2RequestSymbols    = 4
+	v2RequestTimeseries = 5
+
+	v2TimeSeriesLabelsRefs       = 1
+	v2TimeSeriesSamples          = 2
+	v2TimeSeriesHistograms       = 3
+	v2TimeSeriesExemplars        = 4
+	v2TimeSeriesMetadata         = 5
+	v2TimeSeriesCreatedTimestamp = 6
+
+	v2ExemplarLabelsRefs = 1
+	v2ExemplarValue      = 2
+	v2ExemplarTimestamp  = 3
+
+	v2SampleValue     = 1
+	v2SampleTimestamp = 2
+
+	v2MetadataType    = 1
+	v2MetadataHelpRef = 3
+	v2MetadataUnitRef = 4
+)
+
+// symbolTable interns the strings of a remote-write 2.0 request. The empty
+// string always has reference 0, as required by the protocol.
+type symbolTable struct {
+	refs    map[string]uint64
+	symbols []string
+}
+
+func newSymbolTable() *symbolTable {
+	return &symbolTable{
+		refs:    map[string]uint64{"": 0},
+		symbols: []string{""},
+	}
+}
+
+func (t *symbolTable) ref(s string) uint64 {
+	if ref, ok := t.refs[s]; ok {
+		return ref
+	}
+	ref := uint64(len(t.symbols))
+	t.refs[s] = ref
+	t.symbols = append(t.symbols, s)
+	return ref
+}
+
+// appendLabelRefs appends the references of the names and values of labels as a
+// packed uint32 field.
+func (t *symbolTable) appendLabelRefs(b []byte, num protowire.Number, labels []label) []byte {
+	if len(labels) == 0 {
+		return b
+	}
+	var packed []byte
+	for _, l := range labels {
+		packed = protowire.AppendVarint(packed, t.ref(l.name))
+		packed = protowire.AppendVarint(packed, t.ref(l.value))
+	}
+	return appendMessage(b, num, packed)
+}
+
+// encodeV2 encodes the metric families as a remote-write 2.0 Request. The
+// metadata of a family is attached to each of its series. The staleness
+// markers of the stale series follow without metadata.
+func encodeV2(mfs []*dto.MetricFamily, extLabels []label, now int64, stale [][]label) ([]byte, WriteStats) {
+	var (
+		b     []byte
+		stats WriteStats
+		st    = newSymbolTable()
+	)
+	for _, mf := range mfs {
+		md := appendVarint(nil, v2MetadataType, metricTypes[mf.GetType()])
+		if help := mf.GetHelp(); help != "" {
+			md = appendVarint(md, v2MetadataHelpRef, st.ref(help))
+		}
+		if unit := mf.GetUnit(); unit != "" {
+			md = appendVarint(md, v2MetadataUnitRef, st.ref(unit))
+		}
+		forEachSeries(mf, extLabels, now, func(s *series) {
+			stats.count(s)
+			ts := st.appendLabelRefs(nil, v2TimeSeriesLabelsRefs, s.labels)
+			if s.histogram != nil {
+				ts = appendHistogram(ts, v2TimeSeriesHistograms, s)
+			} else {
+				sb := appendDouble(nil, v2SampleValue, s.value)
+				sb = appendVarint(sb, v2SampleTimestamp, uint64(s.timestamp))
+				ts = appendMessage(ts, v2TimeSeriesSamples, sb)
+			}
+			if e := s.exemplar; e != nil {
+				labels := make([]label, 0, len(e.GetLabel()))
+				for _, lp := range e.GetLabel() {
+					labels = append(labels, label{lp.GetName(), lp.GetValue()})
+				}
+				eb := st.appendLabelRefs(nil, v2ExemplarLabelsRefs, labels)
+				eb = appendDouble(eb, v2ExemplarValue, e.GetValue())
+				eb = appendVarint(eb, v2ExemplarTimestamp, uint64(exemplarTimestamp(e, s.timestamp)))
+				ts = appendMessage(ts, v2TimeSeriesExemplars, eb)
+			}
+			ts = appendMessage(ts, v2TimeSeriesMetadata, md)
+			if s.created != 0 {
+				ts = appendVarint(ts, v2TimeSeriesCreatedTimestamp, uint64(s.created))
+			}
+			b = appendMessage(b, v2RequestTimeseries, ts)
+		})
+	}
+	for _, labels := range stale {
+		stats.Samples++
+		ts := st.appendLabelRefs(nil, v2TimeSeriesLabelsRefs, labels)
+		sb := appendDouble(nil, v2SampleValue, staleNaN)
+		sb = appendVarint(sb, v2SampleTimestamp, uint64(now))
+		ts = appendMessage(ts, v2TimeSeriesSamples, sb)
+		b = appendMessage(b, v2RequestTimeseries, ts)
+	}
+
+	// The symbols are only known after encoding all series, but the order of
+	// fields doesn't matter in protobuf.
+	for _, s := range st.symbols {
+		b = protowire.AppendTag(b, v2RequestSymbols, protowire.BytesType)
+		b = protowire.AppendString(b, s)
+	}
+	return b, stats
+}
@@ -0,0 +1,195 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultInitialBackoff = 100 * time.Millisecond
+	defaultMaxBackoff     = 10 * time.Second
+)
+
+var defaultRetryStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy defines how a Client retries failed requests, see
+// Config.RetryPolicy.
+//
+// A request is retried if it failed with a network error or with one of the
+// StatusCodes, but only if it is idempotent and its body can be sent again.
+// Like in net/http, requests are idempotent if their method is GET, HEAD,
+// OPTIONS, or TRACE, or if their header contains an "Idempotency-Key" or
+// "X-Idempotency-Key" entry (possibly nil, so that it isn't sent). The query
+// requests of package api/prometheus/v1 are marked as idempotent that way,
+// even if they are sent via POST. Requests are never retried once their
+// context has expired.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of retries after the initial
+	// attempt. Zero disables retries.
+	MaxRetries int
+	// InitialBackoff is the time to wait before the first retry. It is
+	// doubled for every further retry. Defaults to 100ms.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the time to wait between two attempts, including
+	// any wait time requested via a Retry-After header. Defaults to 10s.
+	MaxBackoff time.Duration
+	// Jitter is the fraction of the backoff that is randomized to avoid
+	// many clients retrying in lockstep, e.g. 0.2 results in a backoff
+	// between 80% and 120% of the nominal value. Values are clamped to
+	// the range [0, 1].
+	Jitter float64
+	// Budget, if positive, limits the time spent on retries: No retry is
+	// attempted if the time since the first attempt plus the backoff
+	// would exceed the budget.
+	Budget time.Duration
+	// StatusCodes are the status codes that cause a retry. Defaults to
+	// 429, 502, 503, and 504. A Retry-After header in a 429 or 503
+	// response is always honored (still capped by MaxBackoff).
+	StatusCodes []int
+}
+
+// withDefaults returns a copy of the policy with defaults applied.
+func (r RetryPolicy) withDefaults() *RetryPolicy {
+	if r.InitialBackoff <= 0 {
+		r.InitialBackoff = defaultInitialBackoff
+	}
+	if r.MaxBackoff <= 0 {
+		r.MaxBackoff = defaultMaxBackoff
+	}
+	if r.StatusCodes == nil {
+		r.StatusCodes = defaultRetryStatusCodes
+	}
+	r.Jitter = min(max(r.Jitter, 0), 1)
+	return &r
+}
+
+// do sends req with client, retrying according to the policy. A nil policy
+// sends the request once.
+func (r *RetryPolicy) do(client *http.Client, req *http.Request) (*http.Response, error) {
+	if r == nil || r.MaxRetries <= 0 || !retriable(req) {
+		return client.Do(req)
+	}
+	ctx := req.Context()
+	start := time.Now()
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 {
+			attemptReq = req.Clone(ctx)
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				attemptReq.Body = body
+			}
+		}
+		resp, err := client.Do(attemptReq)
+		if attempt >= r.MaxRetries || !r.retryable(ctx, resp, err) {
+			return resp, err
+		}
+		wait := r.backoff(attempt+1, resp)
+		if r.Budget > 0 && time.Since(start)+wait > r.Budget {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body) //nolint:errcheck // Only draining for connection reuse.
+			resp.Body.Close()
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// retriable reports whether req may be sent more than once.
+func retriable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	_, ok := req.Header["Idempotency-Key"]
+	if !ok {
+		_, ok = req.Header["X-Idempotency-Key"]
+	}
+	return ok
+}
+
+// retryable reports whether a request that resulted in the provided response
+// or error should be retried.
+func (r *RetryPolicy) retryable(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	return slices.Contains(r.StatusCodes, resp.StatusCode)
+}
+
+// backoff returns the time to wait before the provided retry (starting at 1),
+// taking into account a Retry-After header of resp.
+func (r *RetryPolicy) backoff(retry int, resp *http.Response) time.Duration {
+	d := r.InitialBackoff
+	for i := 1; i < retry && d < r.MaxBackoff; i++ {
+		d *= 2
+	}
+	if r.Jitter > 0 {
+		d = time.Duration(float64(d) * (1 + r.Jitter*(2*rand.Float64()-1)))
+	}
+	if resp != nil {
+		if ra, ok := retryAfter(resp); ok {
+			d = ra
+		}
+	}
+	return min(d, r.MaxBackoff)
+}
+
+// retryAfter parses the Retry-After header of a 429 or 503 response, which
+// may contain either a number of seconds or an HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(max(secs, 0)) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
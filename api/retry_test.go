@@ -0,0 +1,129 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientRetry(t *testing.T) {
+	var (
+		attempts atomic.Int32
+		bodies   []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		switch attempts.Add(1) {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{
+		Address:     server.URL,
+		RetryPolicy: &RetryPolicy{MaxRetries: 3, InitialBackoff: time.Millisecond},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name         string
+		req          func() *http.Request
+		wantAttempts int32
+		wantCode     int
+	}{
+		{
+			name: "GET",
+			req: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+				return req
+			},
+			wantAttempts: 3,
+			wantCode:     http.StatusOK,
+		},
+		{
+			name: "idempotent POST",
+			req: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("query=up"))
+				req.Header["Idempotency-Key"] = nil
+				return req
+			},
+			wantAttempts: 3,
+			wantCode:     http.StatusOK,
+		},
+		{
+			name: "non-idempotent POST",
+			req: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("query=up"))
+				return req
+			},
+			wantAttempts: 1,
+			wantCode:     http.StatusTooManyRequests,
+		},
+	}
+	for _, s := range scenarios {
+		attempts.Store(0)
+		bodies = nil
+		resp, body, err := client.Do(context.Background(), s.req())
+		if err != nil {
+			t.Fatalf("%s: %v", s.name, err)
+		}
+		if got := attempts.Load(); got != s.wantAttempts {
+			t.Errorf("%s: got %d attempts, want %d", s.name, got, s.wantAttempts)
+		}
+		if resp.StatusCode != s.wantCode {
+			t.Errorf("%s: got status code %d, want %d", s.name, resp.StatusCode, s.wantCode)
+		}
+		if s.wantCode == http.StatusOK && string(body) != "ok" {
+			t.Errorf("%s: got body %q", s.name, body)
+		}
+		for _, b := range bodies {
+			if b != bodies[0] {
+				t.Errorf("%s: got request bodies %q, want the same body in every attempt", s.name, bodies)
+			}
+		}
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	r := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}.withDefaults()
+	for retry, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second} {
+		if got := r.backoff(retry, nil); got != want {
+			t.Errorf("retry %d: got backoff %v, want %v", retry, got, want)
+		}
+	}
+	resp := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{"Retry-After": []string{"3"}}}
+	if got := r.backoff(1, resp); got != 3*time.Second {
+		t.Errorf("got backoff %v, want 3s from Retry-After", got)
+	}
+	resp.Header.Set("Retry-After", "60")
+	if got := r.backoff(1, resp); got != 5*time.Second {
+		t.Errorf("got backoff %v, want it capped at 5s", got)
+	}
+}
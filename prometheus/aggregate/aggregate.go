@@ -0,0 +1,394 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package aggregate provides a Gatherer that aggregates away selected labels
+// of the gathered metrics before they are exposed, e.g. a "shard" or
+// "connection_id" label. This lets a program keep fine-grained labels for
+// debugging, e.g. with a separate unaggregated debug endpoint, while exposing
+// series of bounded cardinality to Prometheus.
+package aggregate
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/internal"
+)
+
+// Op is the aggregation applied to the values of gauges and untyped metrics.
+type Op int
+
+const (
+	// Sum sums the values.
+	Sum Op = iota
+	// Avg averages the values.
+	Avg
+	// Min takes the minimum value.
+	Min
+	// Max takes the maximum value.
+	Max
+	// Count counts the aggregated series.
+	Count
+)
+
+func (o Op) String() string {
+	switch o {
+	case Sum:
+		return "sum"
+	case Avg:
+		return "avg"
+	case Min:
+		return "min"
+	case Max:
+		return "max"
+	case Count:
+		return "count"
+	default:
+		return fmt.Sprintf("Op(%d)", int(o))
+	}
+}
+
+// Rule selects the labels to aggregate away for a metric family.
+type Rule struct {
+	// Metric is the name of the metric family the rule applies to. If
+	// empty, the rule applies to all metric families.
+	Metric string
+	// Without are the names of the labels to aggregate away. The series
+	// that only differ in these labels are aggregated into one.
+	Without []string
+	// Op is the aggregation of the values of gauges and untyped metrics.
+	// Counters, histograms, and summaries are always summed, as no other
+	// aggregation of them is meaningful. Defaults to Sum.
+	Op Op
+}
+
+// NewGatherer returns a Gatherer that gathers from g and aggregates away the
+// labels selected by the rules. The first rule matching a metric family
+// applies. Metric families without any of the labels of their rule are
+// returned unchanged.
+//
+// Counters and the counts and sums of histograms and summaries are summed,
+// and the earliest created timestamp is kept. The quantiles of summaries
+// cannot be aggregated and are dropped. Classic histogram buckets are summed
+// for the upper bounds all aggregated histograms have. Native histogram
+// buckets are merged at the lowest schema and the widest zero bucket of the
+// aggregated histograms, as long as all of them have native buckets with
+// integer counts; otherwise, the native buckets are dropped. Exemplars are
+// dropped. Metrics with a timestamp keep the latest one.
+func NewGatherer(g prometheus.Gatherer, rules ...Rule) prometheus.Gatherer {
+	return prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		mfs, err := g.Gather()
+		out := make([]*dto.MetricFamily, 0, len(mfs))
+		for _, mf := range mfs {
+			out = append(out, aggregateFamily(mf, rules))
+		}
+		return out, err
+	})
+}
+
+// aggregateFamily returns mf with the labels of the first matching rule
+// aggregated away.
+func aggregateFamily(mf *dto.MetricFamily, rules []Rule) *dto.MetricFamily {
+	var rule *Rule
+	for i := range rules {
+		if rules[i].Metric == "" || rules[i].Metric == mf.GetName() {
+			rule = &rules[i]
+			break
+		}
+	}
+	if rule == nil || !hasAnyLabel(mf, rule.Without) {
+		return mf
+	}
+	without := make(map[string]struct{}, len(rule.Without))
+	for _, name := range rule.Without {
+		without[name] = struct{}{}
+	}
+
+	var (
+		keys   []string
+		groups = map[string][]*dto.Metric{}
+		labels = map[string][]*dto.LabelPair{}
+	)
+	for _, m := range mf.GetMetric() {
+		kept := make([]*dto.LabelPair, 0, len(m.GetLabel()))
+		var sb strings.Builder
+		for _, lp := range m.GetLabel() {
+			if _, ok := without[lp.GetName()]; ok {
+				continue
+			}
+			kept = append(kept, lp)
+			sb.WriteString(lp.GetName())
+			sb.WriteByte('\xff')
+			sb.WriteString(lp.GetValue())
+			sb.WriteByte('\xff')
+		}
+		key := sb.String()
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+			labels[key] = kept
+		}
+		groups[key] = append(groups[key], m)
+	}
+
+	out := &dto.MetricFamily{
+		Name:   mf.Name,
+		Help:   mf.Help,
+		Type:   mf.Type,
+		Unit:   mf.Unit,
+		Metric: make([]*dto.Metric, 0, len(keys)),
+	}
+	for _, key := range keys {
+		ms := groups[key]
+		m := &dto.Metric{Label: labels[key], TimestampMs: latestTimestamp(ms)}
+		switch mf.GetType() {
+		case dto.MetricType_COUNTER:
+			m.Counter = sumCounters(ms)
+		case dto.MetricType_GAUGE:
+			m.Gauge = &dto.Gauge{Value: proto.Float64(aggregateValues(ms, rule.Op, gaugeValue))}
+		case dto.MetricType_UNTYPED:
+			m.Untyped = &dto.Untyped{Value: proto.Float64(aggregateValues(ms, rule.Op, untypedValue))}
+		case dto.MetricType_SUMMARY:
+			m.Summary = sumSummaries(ms)
+		case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+			m.Histogram = sumHistograms(ms)
+		}
+		out.Metric = append(out.Metric, m)
+	}
+	sort.Sort(internal.MetricSorter(out.Metric))
+	return out
+}
+
+// hasAnyLabel returns whether any metric of mf has any of the labels.
+func hasAnyLabel(mf *dto.MetricFamily, names []string) bool {
+	for _, m := range mf.GetMetric() {
+		for _, lp := range m.GetLabel() {
+			for _, name := range names {
+				if lp.GetName() == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func aggregateValues(ms []*dto.Metric, op Op, value func(*dto.Metric) float64) float64 {
+	if op == Count {
+		return float64(len(ms))
+	}
+	v := value(ms[0])
+	for _, m := range ms[1:] {
+		switch op {
+		case Sum, Avg:
+			v += value(m)
+		case Min:
+			v = math.Min(v, value(m))
+		case Max:
+			v = math.Max(v, value(m))
+		}
+	}
+	if op == Avg {
+		v /= float64(len(ms))
+	}
+	return v
+}
+
+func gaugeValue(m *dto.Metric) float64   { return m.GetGauge().GetValue() }
+func untypedValue(m *dto.Metric) float64 { return m.GetUntyped().GetValue() }
+
+// latestTimestamp returns the latest timestamp of the metrics, or nil if none
+// of them has a timestamp.
+func latestTimestamp(ms []*dto.Metric) *int64 {
+	var ts *int64
+	for _, m := range ms {
+		if m.TimestampMs != nil && (ts == nil || m.GetTimestampMs() > *ts) {
+			ts = proto.Int64(m.GetTimestampMs())
+		}
+	}
+	return ts
+}
+
+// earliest returns the earlier of the created timestamps, ignoring nil ones.
+func earliest(a, b *timestamppb.Timestamp) *timestamppb.Timestamp {
+	if a == nil || (b != nil && b.AsTime().Before(a.AsTime())) {
+		return b
+	}
+	return a
+}
+
+func sumCounters(ms []*dto.Metric) *dto.Counter {
+	c := &dto.Counter{Value: proto.Float64(0)}
+	for _, m := range ms {
+		*c.Value += m.GetCounter().GetValue()
+		c.CreatedTimestamp = earliest(c.CreatedTimestamp, m.GetCounter().GetCreatedTimestamp())
+	}
+	return c
+}
+
+func sumSummaries(ms []*dto.Metric) *dto.Summary {
+	s := &dto.Summary{SampleCount: proto.Uint64(0), SampleSum: proto.Float64(0)}
+	for _, m := range ms {
+		*s.SampleCount += m.GetSummary().GetSampleCount()
+		*s.SampleSum += m.GetSummary().GetSampleSum()
+		s.CreatedTimestamp = earliest(s.CreatedTimestamp, m.GetSummary().GetCreatedTimestamp())
+	}
+	return s
+}
+
+func sumHistograms(ms []*dto.Metric) *dto.Histogram {
+	h := &dto.Histogram{SampleCount: proto.Uint64(0), SampleSum: proto.Float64(0)}
+	for _, m := range ms {
+		ph := m.GetHistogram()
+		*h.SampleCount += ph.GetSampleCount()
+		*h.SampleSum += ph.GetSampleSum()
+		h.CreatedTimestamp = earliest(h.CreatedTimestamp, ph.GetCreatedTimestamp())
+	}
+	h.Bucket = sumBuckets(ms)
+	mergeNative(h, ms)
+	return h
+}
+
+// sumBuckets sums the classic buckets of the histograms for the upper bounds
+// all of them have.
+func sumBuckets(ms []*dto.Metric) []*dto.Bucket {
+	counts := map[float64]uint64{}
+	seen := map[float64]int{}
+	for _, m := range ms {
+		for _, b := range m.GetHistogram().GetBucket() {
+			counts[b.GetUpperBound()] += b.GetCumulativeCount()
+			seen[b.GetUpperBound()]++
+		}
+	}
+	var buckets []*dto.Bucket
+	for ub, n := range seen {
+		if n == len(ms) {
+			buckets = append(buckets, &dto.Bucket{
+				UpperBound:      proto.Float64(ub),
+				CumulativeCount: proto.Uint64(counts[ub]),
+			})
+		}
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].GetUpperBound() < buckets[j].GetUpperBound() })
+	return buckets
+}
+
+// mergeNative sets the native buckets of h to the merged native buckets of
+// the histograms, if all of them have native buckets with integer counts.
+func mergeNative(h *dto.Histogram, ms []*dto.Metric) {
+	var (
+		schema    int32 = math.MaxInt32
+		threshold float64
+	)
+	for _, m := range ms {
+		ph := m.GetHistogram()
+		if ph.Schema == nil || len(ph.PositiveCount) > 0 || len(ph.NegativeCount) > 0 || ph.GetZeroCountFloat() > 0 {
+			return
+		}
+		schema = min(schema, ph.GetSchema())
+		threshold = math.Max(threshold, ph.GetZeroThreshold())
+	}
+
+	var (
+		zero     uint64
+		pos, neg = map[int32]int64{}, map[int32]int64{}
+	)
+	for _, m := range ms {
+		ph := m.GetHistogram()
+		zero += ph.GetZeroCount()
+		downscale := ph.GetSchema() - schema
+		zero += addBuckets(pos, ph.GetPositiveSpan(), ph.GetPositiveDelta(), downscale, schema, threshold)
+		zero += addBuckets(neg, ph.GetNegativeSpan(), ph.GetNegativeDelta(), downscale, schema, threshold)
+	}
+	h.Schema = proto.Int32(schema)
+	h.ZeroThreshold = proto.Float64(threshold)
+	h.ZeroCount = proto.Uint64(zero)
+	h.PositiveSpan, h.PositiveDelta = encodeBuckets(pos)
+	h.NegativeSpan, h.NegativeDelta = encodeBuckets(neg)
+	if len(h.PositiveSpan) == 0 && len(h.NegativeSpan) == 0 && zero == 0 && threshold == 0 {
+		// Like client_golang's histograms, add a no-op span to mark
+		// the histogram as native.
+		h.PositiveSpan = []*dto.BucketSpan{{Offset: proto.Int32(0), Length: proto.Uint32(0)}}
+	}
+}
+
+// addBuckets adds the counts of the native buckets, converted to the target
+// schema, to buckets. The counts of buckets within the zero bucket of the
+// threshold are returned instead.
+func addBuckets(buckets map[int32]int64, spans []*dto.BucketSpan, deltas []int64, downscale, schema int32, threshold float64) uint64 {
+	var (
+		idx   int32
+		count int64
+		d     int
+		zero  uint64
+	)
+	for _, span := range spans {
+		idx += span.GetOffset()
+		for range span.GetLength() {
+			if d < len(deltas) {
+				count += deltas[d]
+				d++
+			}
+			target := ((idx - 1) >> downscale) + 1
+			if upperBound(target, schema) <= threshold {
+				zero += uint64(count)
+			} else {
+				buckets[target] += count
+			}
+			idx++
+		}
+	}
+	return zero
+}
+
+// upperBound returns the upper bound of the native bucket with the provided
+// index and schema.
+func upperBound(idx, schema int32) float64 {
+	return math.Exp2(float64(idx) / math.Exp2(float64(schema)))
+}
+
+// encodeBuckets returns the spans and delta-encoded counts of the non-empty
+// native buckets.
+func encodeBuckets(buckets map[int32]int64) ([]*dto.BucketSpan, []int64) {
+	idxs := make([]int32, 0, len(buckets))
+	for idx, count := range buckets {
+		if count > 0 {
+			idxs = append(idxs, idx)
+		}
+	}
+	sort.Slice(idxs, func(i, j int) bool { return idxs[i] < idxs[j] })
+
+	var (
+		spans  []*dto.BucketSpan
+		deltas = make([]int64, 0, len(idxs))
+		last   int64
+	)
+	for i, idx := range idxs {
+		switch {
+		case i == 0:
+			spans = append(spans, &dto.BucketSpan{Offset: proto.Int32(idx), Length: proto.Uint32(0)})
+		case idx != idxs[i-1]+1:
+			spans = append(spans, &dto.BucketSpan{Offset: proto.Int32(idx - idxs[i-1] - 1), Length: proto.Uint32(0)})
+		}
+		*spans[len(spans)-1].Length++
+		deltas = append(deltas, buckets[idx]-last)
+		last = buckets[idx]
+	}
+	return spans, deltas
+}
@@ -0,0 +1,162 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestGatherer(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "requests_total",
+		Help: "Requests handled.",
+	}, []string{"code", "shard"})
+	queue := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "queue_length",
+		Help: "Queue length.",
+	}, []string{"shard"})
+	latency := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "latency_seconds",
+		Help:    "Latency.",
+		Buckets: []float64{0.1, 1},
+	}, []string{"connection_id"})
+	other := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "other",
+		Help: "Not aggregated.",
+	}, []string{"code"})
+	reg.MustRegister(requests, queue, latency, other)
+
+	requests.WithLabelValues("200", "1").Add(3)
+	requests.WithLabelValues("200", "2").Add(4)
+	requests.WithLabelValues("500", "2").Add(1)
+	queue.WithLabelValues("1").Set(5)
+	queue.WithLabelValues("2").Set(9)
+	latency.WithLabelValues("a").Observe(0.05)
+	latency.WithLabelValues("b").Observe(0.5)
+	latency.WithLabelValues("b").Observe(2)
+	other.WithLabelValues("200").Set(1)
+
+	g := NewGatherer(reg,
+		Rule{Metric: "queue_length", Without: []string{"shard"}, Op: Max},
+		Rule{Without: []string{"shard", "connection_id"}},
+	)
+	expected := `# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.1"} 1
+latency_seconds_bucket{le="1"} 2
+latency_seconds_bucket{le="+Inf"} 3
+latency_seconds_sum 2.55
+latency_seconds_count 3
+# HELP other Not aggregated.
+# TYPE other gauge
+other{code="200"} 1
+# HELP queue_length Queue length.
+# TYPE queue_length gauge
+queue_length 9
+# HELP requests_total Requests handled.
+# TYPE requests_total counter
+requests_total{code="200"} 7
+requests_total{code="500"} 1
+`
+	if err := testutil.GatherAndCompare(g, strings.NewReader(expected)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAggregateValues(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	gv := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "g", Help: "A gauge."}, []string{"shard"})
+	reg.MustRegister(gv)
+	for i, v := range []float64{2, 6, 4} {
+		gv.WithLabelValues(string(rune('a' + i))).Set(v)
+	}
+	for op, want := range map[Op]float64{Sum: 12, Avg: 4, Min: 2, Max: 6, Count: 3} {
+		mfs, err := NewGatherer(reg, Rule{Without: []string{"shard"}, Op: op}).Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := mfs[0].GetMetric()[0].GetGauge().GetValue(); got != want {
+			t.Errorf("%s: got %v, want %v", op, got, want)
+		}
+	}
+}
+
+func TestAggregateNativeHistograms(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	fine := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:                        "h",
+		Help:                        "A histogram.",
+		ConstLabels:                 prometheus.Labels{"shard": "fine"},
+		NativeHistogramBucketFactor: 1.1,
+	})
+	coarse := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:                        "h",
+		Help:                        "A histogram.",
+		ConstLabels:                 prometheus.Labels{"shard": "coarse"},
+		NativeHistogramBucketFactor: 2,
+	})
+	reg.MustRegister(fine, coarse)
+	for _, v := range []float64{0, 1.5, 3, -3} {
+		fine.Observe(v)
+		coarse.Observe(v)
+	}
+
+	mfs, err := NewGatherer(reg, Rule{Without: []string{"shard"}}).Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := mfs[0].GetMetric()[0].GetHistogram()
+	if got, want := h.GetSchema(), int32(0); got != want {
+		t.Errorf("got schema %d, want %d", got, want)
+	}
+	if got, want := h.GetZeroCount(), uint64(2); got != want {
+		t.Errorf("got zero count %d, want %d", got, want)
+	}
+	pos, neg := bucketCounts(h.GetPositiveDelta()), bucketCounts(h.GetNegativeDelta())
+	if want := []int64{2, 2}; !equal(pos, want) {
+		t.Errorf("got positive bucket counts %v, want %v", pos, want)
+	}
+	if want := []int64{2}; !equal(neg, want) {
+		t.Errorf("got negative bucket counts %v, want %v", neg, want)
+	}
+}
+
+func bucketCounts(deltas []int64) []int64 {
+	var (
+		counts []int64
+		count  int64
+	)
+	for _, d := range deltas {
+		count += d
+		counts = append(counts, count)
+	}
+	return counts
+}
+
+func equal(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
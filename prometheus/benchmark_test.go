@@ -14,8 +14,12 @@
 package prometheus
 
 import (
+	"fmt"
+	"runtime"
 	"sync"
 	"testing"
+
+	"github.com/prometheus/common/model"
 )
 
 func BenchmarkCounter(b *testing.B) {
@@ -144,6 +148,18 @@ func BenchmarkCounterNoLabels(b *testing.B) {
 	}
 }
 
+func BenchmarkIntCounterNoLabels(b *testing.B) {
+	m := NewIntCounter(CounterOpts{
+		Name: "benchmark_counter",
+		Help: "A counter to benchmark it.",
+	})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Add(3)
+	}
+}
+
 func BenchmarkGaugeWithLabelValues(b *testing.B) {
 	m := NewGaugeVec(
 		GaugeOpts{
@@ -242,3 +258,88 @@ func BenchmarkParallelCounter(b *testing.B) {
 		}
 	})
 }
+
+func BenchmarkParallelHistogram(b *testing.B) {
+	for _, stripes := range []int{0, runtime.GOMAXPROCS(0)} {
+		b.Run(fmt.Sprintf("stripes=%d", stripes), func(b *testing.B) {
+			h := NewHistogram(HistogramOpts{
+				Name:    "benchmark_histogram",
+				Help:    "A histogram to benchmark it.",
+				Stripes: stripes,
+			})
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					h.Observe(3.1415)
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkParallelSummary(b *testing.B) {
+	for _, stripes := range []int{0, runtime.GOMAXPROCS(0)} {
+		b.Run(fmt.Sprintf("stripes=%d", stripes), func(b *testing.B) {
+			s := NewSummary(SummaryOpts{
+				Name:       "benchmark_summary",
+				Help:       "A summary to benchmark it.",
+				Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+				Stripes:    stripes,
+			})
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					s.Observe(3.1415)
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkNewDesc(b *testing.B) {
+	constLabels := Labels{"instance": "localhost:9090", "job": "prometheus"}
+	variableLabels := []string{"code", "method", "handler"}
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := NewDesc("http_requests_total", "Total HTTP requests.", variableLabels, constLabels).err; err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCheckLabelName(b *testing.B) {
+	defer func(scheme model.ValidationScheme) { model.NameValidationScheme = scheme }(model.NameValidationScheme)
+	for _, scheme := range []model.ValidationScheme{model.UTF8Validation, model.LegacyValidation} {
+		model.NameValidationScheme = scheme
+		for _, name := range []string{"code", "http_request_method", "label.with.dots"} {
+			b.Run(scheme.String()+"/"+name, func(b *testing.B) {
+				for b.Loop() {
+					checkLabelName(name)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkNewConstMetric(b *testing.B) {
+	desc := NewDesc("http_requests_total", "Total HTTP requests.", []string{"code", "method", "handler"}, Labels{"job": "prometheus"})
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := NewConstMetric(desc, CounterValue, 42, "200", "GET", "/api/v1/query"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkConstMetricTemplate(b *testing.B) {
+	desc := NewDesc("http_requests_total", "Total HTTP requests.", []string{"code", "method", "handler"}, Labels{"job": "prometheus"})
+	template := MustNewConstMetricTemplate(desc, CounterValue)
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := template.New(42, "200", "GET", "/api/v1/query"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
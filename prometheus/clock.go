@@ -0,0 +1,33 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import "time"
+
+// Clock provides the current time to metrics that depend on it, e.g. for
+// created timestamps, exemplar timestamps, the age of Summary observations,
+// and the reset of native histograms. It is meant for tests that need to
+// control the time deterministically, see testutil.FakeClock. Implementations
+// must be safe for concurrent use.
+type Clock interface {
+	Now() time.Time
+}
+
+// nowFunc returns the Now method of c, or time.Now if c is nil.
+func nowFunc(c Clock) func() time.Time {
+	if c == nil {
+		return time.Now
+	}
+	return c.Now
+}
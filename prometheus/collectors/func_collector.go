@@ -0,0 +1,80 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collectors
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type funcCollector struct {
+	desc *prometheus.Desc
+	fn   func() (map[string]float64, error)
+}
+
+// NewFuncCollector returns a collector that exports the values returned by fn
+// as a gauge metric family with the given name and help string. Each key of
+// the returned map becomes the value of the label with the provided labelName,
+// and the corresponding map value becomes the sample value.
+//
+// fn is called upon each collection. If it returns an error, the error is
+// reported as an invalid metric (see prometheus.NewInvalidMetric) and no
+// samples are collected. Map keys that are not valid label values are reported
+// the same way, while the remaining samples are still collected.
+//
+// The metric name and the label name are validated when the collector is
+// registered, so that a malformed name is reported as a registration error
+// rather than failing later during collection. The same applies to a nil fn.
+//
+// This collector is meant for the common "quick export" case. For anything
+// beyond a single label dimension, or where the metric type is not a gauge,
+// implement a prometheus.Collector directly.
+func NewFuncCollector(name, help string, fn func() (map[string]float64, error), labelName string) prometheus.Collector {
+	c := &funcCollector{fn: fn}
+	if fn == nil {
+		err := fmt.Errorf("nil function for metric %q", name)
+		c.desc = prometheus.NewInvalidDesc(err)
+		c.fn = func() (map[string]float64, error) { return nil, err }
+		return c
+	}
+	if labelName == "" {
+		c.desc = prometheus.NewInvalidDesc(fmt.Errorf("empty label name for metric %q", name))
+		return c
+	}
+	c.desc = prometheus.NewDesc(name, help, []string{labelName}, nil)
+	return c
+}
+
+// Describe implements Collector.
+func (c *funcCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect implements Collector.
+func (c *funcCollector) Collect(ch chan<- prometheus.Metric) {
+	values, err := c.fn()
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+	for lv, v := range values {
+		m, err := prometheus.NewConstMetric(c.desc, prometheus.GaugeValue, v, lv)
+		if err != nil {
+			ch <- prometheus.NewInvalidMetric(c.desc, err)
+			continue
+		}
+		ch <- m
+	}
+}
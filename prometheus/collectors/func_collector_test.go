@@ -0,0 +1,104 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collectors
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestFuncCollector(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	c := NewFuncCollector("queue_length", "Length of the queues.", func() (map[string]float64, error) {
+		return map[string]float64{"high": 3, "low": 7}, nil
+	}, "priority")
+	if err := reg.Register(c); err != nil {
+		t.Fatal(err)
+	}
+
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mfs) != 1 {
+		t.Fatalf("got %d metric families, want 1", len(mfs))
+	}
+	mf := mfs[0]
+	if got, want := mf.GetName(), "queue_length"; got != want {
+		t.Errorf("got name %q, want %q", got, want)
+	}
+	want := map[string]float64{"high": 3, "low": 7}
+	if len(mf.GetMetric()) != len(want) {
+		t.Fatalf("got %d metrics, want %d", len(mf.GetMetric()), len(want))
+	}
+	for _, m := range mf.GetMetric() {
+		lp := m.GetLabel()[0]
+		if lp.GetName() != "priority" {
+			t.Errorf("got label name %q, want %q", lp.GetName(), "priority")
+		}
+		if got := m.GetGauge().GetValue(); got != want[lp.GetValue()] {
+			t.Errorf("got value %v for %q, want %v", got, lp.GetValue(), want[lp.GetValue()])
+		}
+	}
+}
+
+func TestFuncCollectorErrors(t *testing.T) {
+	for name, c := range map[string]prometheus.Collector{
+		"empty metric name": NewFuncCollector("", "help", func() (map[string]float64, error) {
+			return nil, nil
+		}, "label"),
+		"invalid label name": NewFuncCollector("valid_name", "help", func() (map[string]float64, error) {
+			return nil, nil
+		}, "__reserved"),
+		"empty label name": NewFuncCollector("valid_name", "help", func() (map[string]float64, error) {
+			return nil, nil
+		}, ""),
+		"nil function": NewFuncCollector("valid_name", "help", nil, "label"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if err := prometheus.NewPedanticRegistry().Register(c); err == nil {
+				t.Error("expected registration error, got nil")
+			}
+		})
+	}
+
+	t.Run("nil function unchecked", func(t *testing.T) {
+		// Collecting must not panic even if the registration error is
+		// bypassed.
+		reg := prometheus.NewPedanticRegistry()
+		reg.MustRegister(uncheckedCollector{NewFuncCollector("valid_name", "help", nil, "label")})
+		if _, err := reg.Gather(); err == nil {
+			t.Error("expected gather error, got nil")
+		}
+	})
+
+	t.Run("callback error", func(t *testing.T) {
+		reg := prometheus.NewPedanticRegistry()
+		reg.MustRegister(NewFuncCollector("valid_name", "help", func() (map[string]float64, error) {
+			return nil, errors.New("boom")
+		}, "label"))
+		if _, err := reg.Gather(); err == nil {
+			t.Error("expected gather error, got nil")
+		}
+	})
+}
+
+// uncheckedCollector wraps a Collector but its Describe method yields no Desc.
+type uncheckedCollector struct {
+	prometheus.Collector
+}
+
+func (uncheckedCollector) Describe(chan<- *prometheus.Desc) {}
@@ -29,7 +29,8 @@ var (
 	// MetricsGC allows only GC metrics to be collected from Go runtime.
 	// e.g. go_gc_cycles_automatic_gc_cycles_total
 	// NOTE: This does not include new class of "/cpu/classes/gc/..." metrics.
-	// Use custom metric rule to access those.
+	// Use custom metric rule to access those, or WithGoCollectorGCCPUPressureMetrics
+	// for ready-to-alert gauges derived from them.
 	MetricsGC = GoRuntimeMetricsRule{regexp.MustCompile(`^/gc/.*`)}
 	// MetricsMemory allows only memory metrics to be collected from Go runtime.
 	// e.g. go_memory_classes_heap_free_bytes
@@ -87,6 +88,37 @@ func WithGoCollectorMemStatsMetricsDisabled() func(options *internal.GoCollector
 	}
 }
 
+// WithGoCollectorGCCPUPressureMetrics enables gauges about the CPU time spent on
+// garbage collection, derived from the "/cpu/classes/..." runtime/metrics:
+//
+// go_gc_cpu_fraction
+// go_gc_assist_cpu_fraction
+// go_gc_idle_cpu_fraction
+//
+// Each gauge is the fraction of the available CPU time (GOMAXPROCS integrated
+// over wall-clock time) spent on the respective GC activity since the previous
+// collection. In particular, go_gc_assist_cpu_fraction is the time user
+// goroutines were forced to spend helping the GC instead of doing their own
+// work, which is usually the best signal for GC pressure slowing down the
+// application.
+//
+// NOTE: As the fractions are calculated between collections, they are best
+// suited for a collector that is scraped by a single Prometheus server. Every
+// collection starts a new interval, no matter who collects. With several
+// scrapers, e.g. an HA pair of Prometheus servers or a push in addition to a
+// scrape, each scraper sees the fractions since the previous collection by any
+// of them, i.e. over a different and shorter interval than its own scrape
+// interval. The
+// underlying runtime/metrics values are only updated by the runtime at certain
+// points (such as the end of a GC cycle), so very short scrape intervals
+// produce noisy values. Use WithGoCollectorRuntimeMetrics with a rule matching
+// "/cpu/classes/gc/.*" if you prefer to calculate rates from the raw counters.
+func WithGoCollectorGCCPUPressureMetrics() func(options *internal.GoCollectorOptions) {
+	return func(o *internal.GoCollectorOptions) {
+		o.EnableGCCPUPressureMetrics = true
+	}
+}
+
 // GoRuntimeMetricsRule allow enabling and configuring particular group of runtime/metrics.
 // TODO(bwplotka): Consider adding ability to adjust buckets.
 type GoRuntimeMetricsRule struct {
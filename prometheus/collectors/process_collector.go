@@ -34,6 +34,16 @@ type ProcessCollectorOpts struct {
 	// metrics are nice to have, but failing to collect them should not
 	// disrupt the collection of the remaining metrics.
 	ReportErrors bool
+	// If true, the collector additionally exports the number of open file
+	// descriptors broken down by their type ("socket", "pipe", "file",
+	// "eventfd", or "other") as process_open_fds_by_type. This is useful to
+	// find out what kind of file descriptor is leaking when the number of
+	// open file descriptors approaches its limit. Determining the type
+	// requires resolving every open file descriptor upon each collection,
+	// which is considerably more expensive than just counting them. This
+	// option is only supported on Linux and ignored on other operating
+	// systems.
+	FDsByType bool
 }
 
 // NewProcessCollector returns a collector which exports the current state of
@@ -52,5 +62,6 @@ func NewProcessCollector(opts ProcessCollectorOpts) prometheus.Collector {
 		PidFn:        opts.PidFn,
 		Namespace:    opts.Namespace,
 		ReportErrors: opts.ReportErrors,
+		FDsByType:    opts.FDsByType,
 	})
 }
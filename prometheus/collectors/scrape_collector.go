@@ -0,0 +1,258 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collectors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	defaultScrapeTimeout = 10 * time.Second
+
+	// scrapeAcceptHeader prefers the protobuf format, which is the only one
+	// carrying native histograms.
+	scrapeAcceptHeader = `application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited;q=0.7,text/plain;version=0.0.4;q=0.3`
+)
+
+// ScrapeCollectorOpts defines the behavior of a scrape collector created with
+// NewScrapeCollector.
+type ScrapeCollectorOpts struct {
+	// Targets are the URLs of the metrics endpoints to scrape, e.g.
+	// "http://localhost:9100/metrics".
+	Targets []string
+	// Client is the HTTP client used for scraping. Defaults to
+	// http.DefaultClient.
+	Client *http.Client
+	// Timeout of scraping a single target. Defaults to 10s.
+	Timeout time.Duration
+	// If not nil, only the metric families for whose name Filter returns
+	// true are re-exposed.
+	Filter func(name string) bool
+	// If not nil, Relabel is called with the name and labels of every
+	// re-exposed metric. It may modify the labels in place. If it returns
+	// false, the metric is dropped.
+	Relabel func(name string, labels prometheus.Labels) bool
+	// If non-empty, a label with this name and the URL of the target as
+	// value is added to every re-exposed metric, overwriting a label of
+	// the same name. It is required to tell the series apart if several
+	// targets expose the same series.
+	TargetLabel string
+}
+
+type scrapeCollector struct {
+	targets     []string
+	client      *http.Client
+	timeout     time.Duration
+	filter      func(string) bool
+	relabel     func(string, prometheus.Labels) bool
+	targetLabel string
+	up          *prometheus.Desc
+}
+
+// NewScrapeCollector returns a collector that scrapes the metrics endpoints of
+// other processes upon each collection and re-exposes their metrics, e.g. to
+// federate the metrics of a sidecar's neighbors through a single endpoint.
+// The targets are scraped concurrently. Metric families are requested in the
+// protobuf format, with a fallback to the text format. The OpenMetrics format
+// is not supported by the parser, and gauge histograms are re-exposed as
+// histograms. Timestamps of the scraped metrics are kept.
+//
+// In addition, the collector exports the metric scrape_target_up with the
+// label "target" for each target, which is 1 if the target was scraped
+// successfully and 0 otherwise. A failed scrape doesn't fail the collection
+// of the other targets.
+//
+// The collector is unchecked, as the metrics are not known in advance, so it
+// cannot be combined with a pedantic registry's descriptor checks. If a
+// metric family is exposed by several targets, the help string and type of
+// the first target in the order of Targets win, and metrics of a mismatching
+// type are dropped. Duplicate series across targets result in a gathering
+// error; use TargetLabel to avoid them.
+func NewScrapeCollector(opts ScrapeCollectorOpts) prometheus.Collector {
+	c := &scrapeCollector{
+		targets:     opts.Targets,
+		client:      opts.Client,
+		timeout:     opts.Timeout,
+		filter:      opts.Filter,
+		relabel:     opts.Relabel,
+		targetLabel: opts.TargetLabel,
+		up: prometheus.NewDesc(
+			"scrape_target_up",
+			"Whether the last scrape of the target was successful.",
+			[]string{"target"}, nil,
+		),
+	}
+	if c.client == nil {
+		c.client = http.DefaultClient
+	}
+	if c.timeout <= 0 {
+		c.timeout = defaultScrapeTimeout
+	}
+	return c
+}
+
+// Describe implements Collector. It sends no descriptors, which makes the
+// collector unchecked.
+func (c *scrapeCollector) Describe(chan<- *prometheus.Desc) {}
+
+// Collect implements Collector.
+func (c *scrapeCollector) Collect(ch chan<- prometheus.Metric) {
+	results := make([][]*dto.MetricFamily, len(c.targets))
+	errs := make([]error, len(c.targets))
+	var wg sync.WaitGroup
+	for i, target := range c.targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = c.scrape(target)
+		}()
+	}
+	wg.Wait()
+
+	families := map[string]*dto.MetricFamily{}
+	descs := map[string]*prometheus.Desc{}
+	for i, target := range c.targets {
+		up := 1.0
+		if errs[i] != nil {
+			up = 0
+		}
+		ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, up, target)
+
+		for _, mf := range results[i] {
+			name := mf.GetName()
+			if c.filter != nil && !c.filter(name) {
+				continue
+			}
+			first, ok := families[name]
+			if !ok {
+				families[name] = mf
+				descs[name] = prometheus.NewDesc(name, mf.GetHelp(), nil, nil)
+			} else if !sameType(first.GetType(), mf.GetType()) {
+				continue
+			}
+			for _, m := range mf.GetMetric() {
+				if sm, ok := c.scrapedMetric(descs[name], name, m, target); ok {
+					ch <- sm
+				}
+			}
+		}
+	}
+}
+
+// scrape scrapes a single target.
+func (c *scrapeCollector) scrape(target string) ([]*dto.MetricFamily, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", scrapeAcceptHeader)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d scraping %s", resp.StatusCode, target)
+	}
+
+	format := expfmt.ResponseFormat(resp.Header)
+	if format.FormatType() == expfmt.TypeUnknown {
+		format = expfmt.NewFormat(expfmt.TypeTextPlain)
+	}
+	dec := expfmt.NewDecoder(resp.Body, format)
+	var mfs []*dto.MetricFamily
+	for {
+		mf := &dto.MetricFamily{}
+		if err := dec.Decode(mf); err != nil {
+			if errors.Is(err, io.EOF) {
+				return mfs, nil
+			}
+			return nil, fmt.Errorf("decoding metrics of %s failed: %w", target, err)
+		}
+		mfs = append(mfs, mf)
+	}
+}
+
+// scrapedMetric returns the metric to re-expose for m, or false if it is
+// dropped by relabeling.
+func (c *scrapeCollector) scrapedMetric(desc *prometheus.Desc, name string, m *dto.Metric, target string) (prometheus.Metric, bool) {
+	labels := make(prometheus.Labels, len(m.GetLabel())+1)
+	for _, lp := range m.GetLabel() {
+		labels[lp.GetName()] = lp.GetValue()
+	}
+	if c.targetLabel != "" {
+		labels[c.targetLabel] = target
+	}
+	if c.relabel != nil && !c.relabel(name, labels) {
+		return nil, false
+	}
+
+	pairs := make([]*dto.LabelPair, 0, len(labels))
+	for n, v := range labels {
+		pairs = append(pairs, &dto.LabelPair{Name: proto.String(n), Value: proto.String(v)})
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].GetName() < pairs[j].GetName() })
+
+	out := proto.Clone(m).(*dto.Metric)
+	out.Label = pairs
+	return &scrapedMetric{desc: desc, m: out}, true
+}
+
+// sameType returns whether metrics of the types a and b are exposed with the
+// same type, considering that gauge histograms become histograms.
+func sameType(a, b dto.MetricType) bool {
+	if a == dto.MetricType_GAUGE_HISTOGRAM {
+		a = dto.MetricType_HISTOGRAM
+	}
+	if b == dto.MetricType_GAUGE_HISTOGRAM {
+		b = dto.MetricType_HISTOGRAM
+	}
+	return a == b
+}
+
+// scrapedMetric is a prometheus.Metric re-exposing a scraped metric as is.
+type scrapedMetric struct {
+	desc *prometheus.Desc
+	m    *dto.Metric
+}
+
+func (m *scrapedMetric) Desc() *prometheus.Desc {
+	return m.desc
+}
+
+func (m *scrapedMetric) Write(out *dto.Metric) error {
+	out.Label = m.m.Label
+	out.Gauge = m.m.Gauge
+	out.Counter = m.m.Counter
+	out.Summary = m.m.Summary
+	out.Untyped = m.m.Untyped
+	out.Histogram = m.m.Histogram
+	out.TimestampMs = m.m.TimestampMs
+	return nil
+}
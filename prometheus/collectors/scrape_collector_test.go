@@ -0,0 +1,106 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collectors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestScrapeCollector(t *testing.T) {
+	// A target serving the protobuf format.
+	remote := prometheus.NewRegistry()
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "requests_total",
+		Help: "Number of requests.",
+	}, []string{"code"})
+	requests.WithLabelValues("200").Add(3)
+	requests.WithLabelValues("500").Add(1)
+	remote.MustRegister(requests)
+	remote.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "ignored",
+		Help: "Filtered out.",
+	}, func() float64 { return 1 }))
+	protoSrv := httptest.NewServer(promhttp.HandlerFor(remote, promhttp.HandlerOpts{}))
+	defer protoSrv.Close()
+
+	// A target serving the text format only.
+	textSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		w.Write([]byte("# HELP requests_total Other help.\n# TYPE requests_total counter\nrequests_total{code=\"200\"} 5\n"))
+	}))
+	defer textSrv.Close()
+
+	failingSrv := httptest.NewServer(http.NotFoundHandler())
+	defer failingSrv.Close()
+
+	c := NewScrapeCollector(ScrapeCollectorOpts{
+		Targets:     []string{protoSrv.URL, textSrv.URL, failingSrv.URL},
+		Filter:      func(name string) bool { return name != "ignored" },
+		TargetLabel: "instance",
+		Relabel: func(name string, labels prometheus.Labels) bool {
+			if labels["code"] == "500" {
+				return false
+			}
+			labels["code"] = "2xx"
+			return true
+		},
+	})
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(c)
+
+	expected := `# HELP requests_total Number of requests.
+# TYPE requests_total counter
+requests_total{code="2xx",instance="` + protoSrv.URL + `"} 3
+requests_total{code="2xx",instance="` + textSrv.URL + `"} 5
+# HELP scrape_target_up Whether the last scrape of the target was successful.
+# TYPE scrape_target_up gauge
+scrape_target_up{target="` + failingSrv.URL + `"} 0
+scrape_target_up{target="` + protoSrv.URL + `"} 1
+scrape_target_up{target="` + textSrv.URL + `"} 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScrapeCollectorTypeMismatch(t *testing.T) {
+	counter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("# TYPE x counter\nx 1\n"))
+	}))
+	defer counter.Close()
+	gauge := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("# TYPE x gauge\nx{a=\"b\"} 2\n"))
+	}))
+	defer gauge.Close()
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(NewScrapeCollector(ScrapeCollectorOpts{
+		Targets: []string{counter.URL, gauge.URL},
+		Filter:  func(name string) bool { return name == "x" },
+	}))
+	expected := `# HELP x
+# TYPE x counter
+x 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "x"); err != nil {
+		t.Fatal(err)
+	}
+}
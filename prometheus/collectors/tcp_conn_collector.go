@@ -0,0 +1,109 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collectors
+
+import (
+	"os"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TCPConnCollectorOpts defines the behavior of a TCP connection collector
+// created with NewTCPConnCollector.
+type TCPConnCollectorOpts struct {
+	// PidFn returns the PID of the process the collector collects metrics
+	// for. It is called upon each collection. By default, the PID of the
+	// current process is used, as determined on construction time by
+	// calling os.Getpid().
+	PidFn func() (int, error)
+	// If non-empty, the collected metric is prefixed by the provided
+	// string and an underscore ("_").
+	Namespace string
+	// If true, any error encountered during collection is reported as an
+	// invalid metric (see prometheus.NewInvalidMetric). Otherwise, errors
+	// are ignored and no metrics are collected.
+	ReportErrors bool
+}
+
+type tcpConnCollector struct {
+	pidFn        func() (int, error)
+	reportErrors bool
+	conns        *prometheus.Desc
+}
+
+// NewTCPConnCollector returns a collector which exports the number of TCP
+// connections of a process by connection state, as the metric
+// process_tcp_connections with the label "state" (e.g. "established",
+// "time_wait", or "close_wait"). All states are exported, including those
+// without any connection. A growing number of connections in "close_wait",
+// for example, usually means that the application doesn't close connections
+// that were closed by the peer.
+//
+// Connections are attributed to the process via the socket file descriptors
+// it holds. Connections in "time_wait" are no longer associated with any file
+// descriptor. Those are attributed to the process if their local port is a
+// port the process is listening on, which covers the common case of a server
+// closing connections. Connections in "time_wait" that were initiated by the
+// process as a client cannot be attributed and are not counted.
+//
+// The collector reads the TCP tables of the network namespace of the process,
+// which requires resolving every open file descriptor and scanning all TCP
+// sockets of the namespace upon each collection. This can be expensive for
+// processes or hosts with a very large number of connections, which is why
+// this collector is not part of the default process collector.
+//
+// The collector only works on Linux. On other operating systems, it will not
+// collect any metrics.
+func NewTCPConnCollector(opts TCPConnCollectorOpts) prometheus.Collector {
+	ns := ""
+	if len(opts.Namespace) > 0 {
+		ns = opts.Namespace + "_"
+	}
+
+	c := &tcpConnCollector{
+		pidFn:        opts.PidFn,
+		reportErrors: opts.ReportErrors,
+		conns: prometheus.NewDesc(
+			ns+"process_tcp_connections",
+			"Number of TCP connections of the process by state.",
+			[]string{"state"}, nil,
+		),
+	}
+	if c.pidFn == nil {
+		pid := os.Getpid()
+		c.pidFn = func() (int, error) {
+			return pid, nil
+		}
+	}
+	return c
+}
+
+// Describe implements Collector.
+func (c *tcpConnCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.conns
+}
+
+// Collect implements Collector.
+func (c *tcpConnCollector) Collect(ch chan<- prometheus.Metric) {
+	counts, err := c.tcpConnsByState()
+	if err != nil {
+		if c.reportErrors {
+			ch <- prometheus.NewInvalidMetric(c.conns, err)
+		}
+		return
+	}
+	for state, n := range counts {
+		ch <- prometheus.MustNewConstMetric(c.conns, prometheus.GaugeValue, float64(n), state)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package collectors
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/procfs"
+)
+
+// tcpStates maps the TCP states as used in /proc/net/tcp (see
+// include/net/tcp_states.h in the Linux source) to label values.
+var tcpStates = map[uint64]string{
+	1:  "established",
+	2:  "syn_sent",
+	3:  "syn_recv",
+	4:  "fin_wait1",
+	5:  "fin_wait2",
+	6:  "time_wait",
+	7:  "close",
+	8:  "close_wait",
+	9:  "last_ack",
+	10: "listen",
+	11: "closing",
+}
+
+const (
+	tcpStateTimeWait = 6
+	tcpStateListen   = 10
+)
+
+func (c *tcpConnCollector) tcpConnsByState() (map[string]int, error) {
+	pid, err := c.pidFn()
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := procfs.NewProc(pid)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := p.FileDescriptorTargets()
+	if err != nil {
+		return nil, err
+	}
+	inodes := make(map[uint64]struct{}, len(targets))
+	for _, t := range targets {
+		if !strings.HasPrefix(t, "socket:[") {
+			continue
+		}
+		inode, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(t, "socket:["), "]"), 10, 64)
+		if err != nil {
+			continue
+		}
+		inodes[inode] = struct{}{}
+	}
+
+	// Use the TCP tables as seen by the process, which might live in a
+	// different network namespace than the collecting process.
+	netFS, err := procfs.NewFS(filepath.Join(procfs.DefaultMountPoint, strconv.Itoa(pid)))
+	if err != nil {
+		return nil, err
+	}
+	var lines procfs.NetTCP
+	//nolint:staticcheck // Ignore SA1019, there is no netlink client in our dependencies.
+	for _, read := range []func() (procfs.NetTCP, error){netFS.NetTCP, netFS.NetTCP6} {
+		l, err := read()
+		if err != nil {
+			// IPv6 might be disabled.
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		lines = append(lines, l...)
+	}
+
+	counts := make(map[string]int, len(tcpStates))
+	for _, state := range tcpStates {
+		counts[state] = 0
+	}
+	listenPorts := map[uint64]struct{}{}
+	for _, l := range lines {
+		if _, ok := inodes[l.Inode]; !ok {
+			continue
+		}
+		if l.St == tcpStateListen {
+			listenPorts[l.LocalPort] = struct{}{}
+		}
+		if state, ok := tcpStates[l.St]; ok {
+			counts[state]++
+		}
+	}
+	// Sockets in TIME_WAIT are not owned by any file descriptor anymore.
+	for _, l := range lines {
+		if l.St != tcpStateTimeWait || l.Inode != 0 {
+			continue
+		}
+		if _, ok := listenPorts[l.LocalPort]; ok {
+			counts[tcpStates[tcpStateTimeWait]]++
+		}
+	}
+	return counts, nil
+}
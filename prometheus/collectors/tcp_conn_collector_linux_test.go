@@ -0,0 +1,104 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package collectors
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/prometheus/procfs"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestTCPConnCollector(t *testing.T) {
+	if _, err := procfs.Self(); err != nil {
+		t.Skipf("skipping TestTCPConnCollector, procfs not available: %s", err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("skipping TestTCPConnCollector, cannot listen: %s", err)
+	}
+	defer ln.Close()
+
+	accepted := make(chan net.Conn)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			close(accepted)
+			return
+		}
+		accepted <- conn
+	}()
+	client, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	server, ok := <-accepted
+	if !ok {
+		t.Fatal("failed to accept connection")
+	}
+	defer server.Close()
+
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(NewTCPConnCollector(TCPConnCollectorOpts{ReportErrors: true})); err != nil {
+		t.Fatal(err)
+	}
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mfs) != 1 || mfs[0].GetName() != "process_tcp_connections" {
+		t.Fatalf("unexpected metric families: %v", mfs)
+	}
+
+	got := map[string]float64{}
+	for _, m := range mfs[0].GetMetric() {
+		got[m.GetLabel()[0].GetValue()] = m.GetGauge().GetValue()
+	}
+	if len(got) != len(tcpStates) {
+		t.Errorf("got %d states, want %d", len(got), len(tcpStates))
+	}
+	if got["established"] < 2 {
+		t.Errorf("got %v established connections, want at least 2", got["established"])
+	}
+	if got["listen"] < 1 {
+		t.Errorf("got %v listening sockets, want at least 1", got["listen"])
+	}
+}
+
+func TestTCPConnCollectorError(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(NewTCPConnCollector(TCPConnCollectorOpts{
+		PidFn:        func() (int, error) { return 0, errors.New("boo") },
+		ReportErrors: true,
+	}))
+	if _, err := reg.Gather(); err == nil {
+		t.Error("expected gather error, got nil")
+	}
+
+	reg = prometheus.NewPedanticRegistry()
+	reg.MustRegister(NewTCPConnCollector(TCPConnCollectorOpts{
+		PidFn: func() (int, error) { return 0, errors.New("boo") },
+	}))
+	if _, err := reg.Gather(); err != nil {
+		t.Errorf("unexpected gather error: %s", err)
+	}
+}
@@ -92,7 +92,7 @@ func NewCounter(opts CounterOpts) Counter {
 		opts.ConstLabels,
 	)
 	if opts.now == nil {
-		opts.now = time.Now
+		opts.now = nowFunc(opts.Clock)
 	}
 	result := &counter{desc: desc, labelPairs: desc.constLabelPairs, now: opts.now}
 	result.init(result) // Init self-collection.
@@ -165,6 +165,15 @@ func (c *counter) Write(out *dto.Metric) error {
 	return populateMetric(CounterValue, val, c.labelPairs, exemplar, out, c.createdTs)
 }
 
+func (c *counter) appendProto(b []byte) ([]byte, dto.MetricType, []*dto.LabelPair, bool) {
+	if c.exemplar.Load() != nil {
+		// Leave encoding the exemplar to Write.
+		return b, 0, nil, false
+	}
+	b, t, ok := appendValueProto(b, CounterValue, c.get(), c.createdTs)
+	return b, t, c.labelPairs, ok
+}
+
 func (c *counter) updateExemplar(v float64, l Labels) {
 	if l == nil {
 		return
@@ -203,9 +212,9 @@ func (v2) NewCounterVec(opts CounterVecOpts) *CounterVec {
 		opts.ConstLabels,
 	)
 	if opts.now == nil {
-		opts.now = time.Now
+		opts.now = nowFunc(opts.Clock)
 	}
-	return &CounterVec{
+	v := &CounterVec{
 		MetricVec: NewMetricVec(desc, func(lvs ...string) Metric {
 			if len(lvs) != len(desc.variableLabels.names) {
 				panic(makeInconsistentCardinalityError(desc.fqName, desc.variableLabels.names, lvs))
@@ -216,6 +225,8 @@ func (v2) NewCounterVec(opts CounterVecOpts) *CounterVec {
 			return result
 		}),
 	}
+	v.internLabelValues = opts.InternLabelValues
+	return v
 }
 
 // GetMetricWithLabelValues returns the Counter for the given slice of label
@@ -282,6 +293,24 @@ func (v *CounterVec) WithLabelValues(lvs ...string) Counter {
 	return c
 }
 
+// WithLabelValuesBatch works as GetMetricWithLabelValues for each of the
+// provided slices of label values, but creates all the Counters at once, see
+// MetricVec.GetMetricWithLabelValuesBatch. Unlike WithLabelValues, it returns
+// an error rather than panicking, as the label values are usually generated,
+// e.g. to pre-create the Counters of all combinations of label values at
+// startup.
+func (v *CounterVec) WithLabelValuesBatch(lvss [][]string) ([]Counter, error) {
+	metrics, err := v.MetricVec.GetMetricWithLabelValuesBatch(lvss)
+	if err != nil {
+		return nil, err
+	}
+	counters := make([]Counter, len(metrics))
+	for i, m := range metrics {
+		counters[i] = m.(Counter)
+	}
+	return counters, nil
+}
+
 // With works as GetMetricWith, but panics where GetMetricWithLabels would have
 // returned an error. Not returning an error allows shortcuts like
 //
@@ -352,3 +381,196 @@ func NewCounterFunc(opts CounterOpts, function func() float64) CounterFunc {
 		opts.ConstLabels,
 	), CounterValue, function)
 }
+
+// IntCounter is a Counter-like Metric that only counts in whole numbers. It is
+// meant for event counting in very hot execution paths: Inc and Add are a
+// single atomic addition, without the check for negative values and the
+// floating-point handling Counter's Add method has to perform. Unlike
+// Counter, an IntCounter doesn't support exemplars.
+//
+// The value wraps around to 0 once it exceeds math.MaxUint64, which a
+// Prometheus server would interpret as a counter reset. The value is exposed
+// as a float64 like all other metric values, so it loses precision above
+// 2^53.
+//
+// To create IntCounter instances, use NewIntCounter.
+type IntCounter interface {
+	Metric
+	Collector
+
+	// Inc increments the counter by 1.
+	Inc()
+	// Add adds the given value to the counter.
+	Add(uint64)
+}
+
+// NewIntCounter creates a new IntCounter based on the provided CounterOpts.
+func NewIntCounter(opts CounterOpts) IntCounter {
+	desc := NewDesc(
+		BuildFQName(opts.Namespace, opts.Subsystem, opts.Name),
+		opts.Help,
+		nil,
+		opts.ConstLabels,
+	)
+	if opts.now == nil {
+		opts.now = nowFunc(opts.Clock)
+	}
+	result := &intCounter{desc: desc, labelPairs: desc.constLabelPairs}
+	result.init(result) // Init self-collection.
+	result.createdTs = timestamppb.New(opts.now())
+	return result
+}
+
+type intCounter struct {
+	// val has to go first in the struct to guarantee alignment for atomic
+	// operations. http://golang.org/pkg/sync/atomic/#pkg-note-BUG
+	val uint64
+
+	selfCollector
+	desc *Desc
+
+	createdTs  *timestamppb.Timestamp
+	labelPairs []*dto.LabelPair
+}
+
+func (c *intCounter) Desc() *Desc {
+	return c.desc
+}
+
+func (c *intCounter) Inc() {
+	atomic.AddUint64(&c.val, 1)
+}
+
+func (c *intCounter) Add(v uint64) {
+	atomic.AddUint64(&c.val, v)
+}
+
+func (c *intCounter) Write(out *dto.Metric) error {
+	val := float64(atomic.LoadUint64(&c.val))
+	return populateMetric(CounterValue, val, c.labelPairs, nil, out, c.createdTs)
+}
+
+func (c *intCounter) appendProto(b []byte) ([]byte, dto.MetricType, []*dto.LabelPair, bool) {
+	b, t, ok := appendValueProto(b, CounterValue, float64(atomic.LoadUint64(&c.val)), c.createdTs)
+	return b, t, c.labelPairs, ok
+}
+
+// IntCounterVec is a Collector that bundles a set of IntCounters that all share
+// the same Desc, but have different values for their variable labels. It works
+// like CounterVec. Create instances with NewIntCounterVec.
+type IntCounterVec struct {
+	*MetricVec
+}
+
+// NewIntCounterVec creates a new IntCounterVec based on the provided
+// CounterOpts and partitioned by the given label names.
+func NewIntCounterVec(opts CounterOpts, labelNames []string) *IntCounterVec {
+	return V2.NewIntCounterVec(CounterVecOpts{
+		CounterOpts:    opts,
+		VariableLabels: UnconstrainedLabels(labelNames),
+	})
+}
+
+// NewIntCounterVec creates a new IntCounterVec based on the provided
+// CounterVecOpts.
+func (v2) NewIntCounterVec(opts CounterVecOpts) *IntCounterVec {
+	desc := V2.NewDesc(
+		BuildFQName(opts.Namespace, opts.Subsystem, opts.Name),
+		opts.Help,
+		opts.VariableLabels,
+		opts.ConstLabels,
+	)
+	if opts.now == nil {
+		opts.now = nowFunc(opts.Clock)
+	}
+	v := &IntCounterVec{
+		MetricVec: NewMetricVec(desc, func(lvs ...string) Metric {
+			if len(lvs) != len(desc.variableLabels.names) {
+				panic(makeInconsistentCardinalityError(desc.fqName, desc.variableLabels.names, lvs))
+			}
+			result := &intCounter{desc: desc, labelPairs: MakeLabelPairs(desc, lvs)}
+			result.init(result) // Init self-collection.
+			result.createdTs = timestamppb.New(opts.now())
+			return result
+		}),
+	}
+	v.internLabelValues = opts.InternLabelValues
+	return v
+}
+
+// GetMetricWithLabelValues works as CounterVec.GetMetricWithLabelValues but
+// returns an IntCounter.
+func (v *IntCounterVec) GetMetricWithLabelValues(lvs ...string) (IntCounter, error) {
+	metric, err := v.MetricVec.GetMetricWithLabelValues(lvs...)
+	if metric != nil {
+		return metric.(IntCounter), err
+	}
+	return nil, err
+}
+
+// GetMetricWith works as CounterVec.GetMetricWith but returns an IntCounter.
+func (v *IntCounterVec) GetMetricWith(labels Labels) (IntCounter, error) {
+	metric, err := v.MetricVec.GetMetricWith(labels)
+	if metric != nil {
+		return metric.(IntCounter), err
+	}
+	return nil, err
+}
+
+// WithLabelValues works as GetMetricWithLabelValues, but panics where
+// GetMetricWithLabelValues would have returned an error. Not returning an
+// error allows shortcuts like
+//
+//	myVec.WithLabelValues("404", "GET").Add(42)
+func (v *IntCounterVec) WithLabelValues(lvs ...string) IntCounter {
+	c, err := v.GetMetricWithLabelValues(lvs...)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// WithLabelValuesBatch works as CounterVec.WithLabelValuesBatch but returns
+// IntCounters.
+func (v *IntCounterVec) WithLabelValuesBatch(lvss [][]string) ([]IntCounter, error) {
+	metrics, err := v.MetricVec.GetMetricWithLabelValuesBatch(lvss)
+	if err != nil {
+		return nil, err
+	}
+	counters := make([]IntCounter, len(metrics))
+	for i, m := range metrics {
+		counters[i] = m.(IntCounter)
+	}
+	return counters, nil
+}
+
+// With works as GetMetricWith, but panics where GetMetricWithLabels would have
+// returned an error. Not returning an error allows shortcuts like
+//
+//	myVec.With(prometheus.Labels{"code": "404", "method": "GET"}).Add(42)
+func (v *IntCounterVec) With(labels Labels) IntCounter {
+	c, err := v.GetMetricWith(labels)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// CurryWith works as CounterVec.CurryWith but returns an IntCounterVec.
+func (v *IntCounterVec) CurryWith(labels Labels) (*IntCounterVec, error) {
+	vec, err := v.MetricVec.CurryWith(labels)
+	if vec != nil {
+		return &IntCounterVec{vec}, err
+	}
+	return nil, err
+}
+
+// MustCurryWith works as CurryWith but panics where CurryWith would have
+// returned an error.
+func (v *IntCounterVec) MustCurryWith(labels Labels) *IntCounterVec {
+	vec, err := v.CurryWith(labels)
+	if err != nil {
+		panic(err)
+	}
+	return vec
+}
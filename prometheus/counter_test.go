@@ -78,6 +78,71 @@ func TestCounterAdd(t *testing.T) {
 	}
 }
 
+func TestIntCounter(t *testing.T) {
+	now := time.Now()
+
+	vec := NewIntCounterVec(CounterOpts{
+		Name:        "test",
+		Help:        "test help",
+		ConstLabels: Labels{"a": "1"},
+		now:         func() time.Time { return now },
+	}, []string{"b"})
+	counter := vec.WithLabelValues("2")
+	counter.Inc()
+	counter.Add(41)
+	if c := vec.MustCurryWith(Labels{"b": "2"}).With(nil); c != counter {
+		t.Errorf("got different IntCounter %v from curried vector, want %v", c, counter)
+	}
+	// Values beyond the range of exact integers in a float64 are still added
+	// up exactly.
+	large := vec.WithLabelValues("3")
+	large.Add(1 << 60)
+	large.Add(1)
+
+	for _, s := range []struct {
+		c    IntCounter
+		want *dto.Metric
+	}{
+		{
+			c: counter,
+			want: &dto.Metric{
+				Label: []*dto.LabelPair{
+					{Name: proto.String("a"), Value: proto.String("1")},
+					{Name: proto.String("b"), Value: proto.String("2")},
+				},
+				Counter: &dto.Counter{
+					Value:            proto.Float64(42),
+					CreatedTimestamp: timestamppb.New(now),
+				},
+			},
+		},
+		{
+			c: large,
+			want: &dto.Metric{
+				Label: []*dto.LabelPair{
+					{Name: proto.String("a"), Value: proto.String("1")},
+					{Name: proto.String("b"), Value: proto.String("3")},
+				},
+				Counter: &dto.Counter{
+					Value:            proto.Float64(1<<60 + 1),
+					CreatedTimestamp: timestamppb.New(now),
+				},
+			},
+		},
+	} {
+		m := &dto.Metric{}
+		if err := s.c.Write(m); err != nil {
+			t.Fatal(err)
+		}
+		if !proto.Equal(s.want, m) {
+			t.Errorf("expected %q, got %q", s.want, m)
+		}
+	}
+	if got := large.(*intCounter).val; got != 1<<60+1 {
+		t.Errorf("got value %d, want %d", got, uint64(1<<60+1))
+	}
+}
+
 func decreaseCounter(c *counter) (err error) {
 	defer func() {
 		if e := recover(); e != nil {
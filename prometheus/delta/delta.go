@@ -0,0 +1,374 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package delta converts the cumulative counters and histograms of Prometheus
+// metrics into delta temporality, i.e. into the increase since the previous
+// conversion, for backends that require delta semantics.
+//
+// The start of the interval a delta covers is stored as the created timestamp
+// of the converted counter or histogram, which is where exporters like the
+// OTLP bridge take the start time of a data point from. Counter resets are
+// detected by decreasing values and changed created timestamps, in which case
+// the delta is the value since the reset.
+package delta
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Converter converts cumulative metric families into delta temporality. It
+// keeps the cumulative values of the last committed conversion of every
+// series. It is safe to use a Converter from multiple goroutines.
+type Converter struct {
+	mtx sync.Mutex
+	// last is the time of the last committed conversion.
+	last time.Time
+	// series are the cumulative values of the last committed conversion by
+	// series key.
+	series map[string]cumulative
+}
+
+// cumulative is the cumulative value of a series.
+type cumulative struct {
+	created   time.Time // Zero if unknown.
+	counter   float64
+	histogram *dto.Histogram
+}
+
+// NewConverter returns a new Converter.
+func NewConverter() *Converter {
+	return &Converter{series: map[string]cumulative{}}
+}
+
+// Convert returns the metric families with the values of counters and the
+// counts and sums of histograms replaced by their increase since the last
+// committed conversion, and the time of that conversion as created timestamp.
+// Other metric types are returned unchanged. The returned metric families
+// share unchanged parts with mfs.
+//
+// A series that is new since the last committed conversion is converted into
+// its whole value since its created timestamp. If it has none, it is assumed
+// to have been created after the last committed conversion. Upon the first
+// conversion, though, such series are omitted, as the interval their value
+// covers is unknown. Float histograms, which client_golang doesn't produce,
+// are omitted, too.
+//
+// The values are only used as the base of the next conversion once the
+// returned commit function is called, which should happen once the deltas
+// have been exported successfully. Until then, the next conversion covers the
+// interval of this one, too, so that no increase is lost upon a failed export.
+func (c *Converter) Convert(mfs []*dto.MetricFamily, now time.Time) (deltas []*dto.MetricFamily, commit func()) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	next := make(map[string]cumulative, len(c.series))
+	deltas = make([]*dto.MetricFamily, 0, len(mfs))
+	for _, mf := range mfs {
+		if mf.GetType() != dto.MetricType_COUNTER && mf.GetType() != dto.MetricType_HISTOGRAM {
+			deltas = append(deltas, mf)
+			continue
+		}
+		d := &dto.MetricFamily{
+			Name: mf.Name,
+			Help: mf.Help,
+			Type: mf.Type,
+			Unit: mf.Unit,
+		}
+		for _, m := range mf.GetMetric() {
+			key := seriesKey(mf.GetName(), m.GetLabel())
+			var dm *dto.Metric
+			if mf.GetType() == dto.MetricType_COUNTER {
+				dm = c.counter(key, m, next)
+			} else {
+				dm = c.histogram(key, m, next)
+			}
+			if dm != nil {
+				d.Metric = append(d.Metric, dm)
+			}
+		}
+		if len(d.Metric) > 0 {
+			deltas = append(deltas, d)
+		}
+	}
+
+	return deltas, func() {
+		c.mtx.Lock()
+		defer c.mtx.Unlock()
+		// Don't let a late commit of an earlier conversion win.
+		if now.After(c.last) {
+			c.last = now
+			c.series = next
+		}
+	}
+}
+
+// counter converts a counter and adds its cumulative value to next. It
+// returns nil if the counter is omitted.
+func (c *Converter) counter(key string, m *dto.Metric, next map[string]cumulative) *dto.Metric {
+	pc := m.GetCounter()
+	cur := cumulative{created: createdTime(pc.GetCreatedTimestamp()), counter: pc.GetValue()}
+	next[key] = cur
+
+	value := cur.counter
+	prev, known := c.series[key]
+	reset := known && (cur.counter < prev.counter || !cur.created.Equal(prev.created))
+	if known && !reset {
+		value -= prev.counter
+	}
+	start, ok := c.start(known, reset, cur.created)
+	if !ok {
+		return nil
+	}
+	return &dto.Metric{
+		Label: m.Label,
+		Counter: &dto.Counter{
+			Value:            &value,
+			Exemplar:         pc.Exemplar,
+			CreatedTimestamp: timestamppb.New(start),
+		},
+	}
+}
+
+// histogram converts a histogram and adds its cumulative value to next. It
+// returns nil if the histogram is omitted.
+func (c *Converter) histogram(key string, m *dto.Metric, next map[string]cumulative) *dto.Metric {
+	ph := m.GetHistogram()
+	if isFloat(ph) {
+		return nil
+	}
+	cur := cumulative{created: createdTime(ph.GetCreatedTimestamp()), histogram: ph}
+	next[key] = cur
+
+	prev, known := c.series[key]
+	reset := known && !cur.created.Equal(prev.created)
+	var h *dto.Histogram
+	if known && !reset {
+		var ok bool
+		if h, ok = histogramDelta(ph, prev.histogram); !ok {
+			reset = true
+		}
+	}
+	if h == nil {
+		h = histogramDelta0(ph)
+	}
+	start, ok := c.start(known, reset, cur.created)
+	if !ok {
+		return nil
+	}
+	h.CreatedTimestamp = timestamppb.New(start)
+	return &dto.Metric{Label: m.Label, Histogram: h}
+}
+
+// start returns the start of the interval of a delta, or false if it is
+// unknown.
+func (c *Converter) start(known, reset bool, created time.Time) (time.Time, bool) {
+	switch {
+	case known && !reset:
+		return c.last, true
+	case !created.IsZero():
+		return created, true
+	case !c.last.IsZero():
+		return c.last, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// histogramDelta returns the increase of the histogram cur since prev, or
+// false if cur has been reset since prev.
+func histogramDelta(cur, prev *dto.Histogram) (*dto.Histogram, bool) {
+	if cur.GetSampleCount() < prev.GetSampleCount() || len(cur.Bucket) != len(prev.Bucket) {
+		return nil, false
+	}
+	count := cur.GetSampleCount() - prev.GetSampleCount()
+	sum := cur.GetSampleSum() - prev.GetSampleSum()
+	h := &dto.Histogram{
+		SampleCount: &count,
+		SampleSum:   &sum,
+		Bucket:      make([]*dto.Bucket, 0, len(cur.Bucket)),
+		Exemplars:   cur.Exemplars,
+	}
+	for i, b := range cur.Bucket {
+		pb := prev.Bucket[i]
+		if b.GetUpperBound() != pb.GetUpperBound() || b.GetCumulativeCount() < pb.GetCumulativeCount() {
+			return nil, false
+		}
+		bc := b.GetCumulativeCount() - pb.GetCumulativeCount()
+		h.Bucket = append(h.Bucket, &dto.Bucket{
+			CumulativeCount: &bc,
+			UpperBound:      b.UpperBound,
+			Exemplar:        b.Exemplar,
+		})
+	}
+
+	if cur.Schema == nil {
+		return h, true
+	}
+	// A schema can only be reduced without a reset, as client_golang does
+	// to limit the number of buckets.
+	if prev.Schema == nil || cur.GetSchema() > prev.GetSchema() ||
+		cur.GetZeroThreshold() != prev.GetZeroThreshold() || cur.GetZeroCount() < prev.GetZeroCount() {
+		return nil, false
+	}
+	downscale := prev.GetSchema() - cur.GetSchema()
+	zero := cur.GetZeroCount() - prev.GetZeroCount()
+	h.Schema = cur.Schema
+	h.ZeroThreshold = cur.ZeroThreshold
+	h.ZeroCount = &zero
+	var ok bool
+	if h.PositiveSpan, h.PositiveDelta, ok = nativeDelta(
+		nativeBuckets(cur.PositiveSpan, cur.PositiveDelta, 0),
+		nativeBuckets(prev.PositiveSpan, prev.PositiveDelta, downscale),
+	); !ok {
+		return nil, false
+	}
+	if h.NegativeSpan, h.NegativeDelta, ok = nativeDelta(
+		nativeBuckets(cur.NegativeSpan, cur.NegativeDelta, 0),
+		nativeBuckets(prev.NegativeSpan, prev.NegativeDelta, downscale),
+	); !ok {
+		return nil, false
+	}
+	return h, true
+}
+
+// histogramDelta0 returns the increase of the histogram h since it was
+// created, i.e. a copy of h.
+func histogramDelta0(h *dto.Histogram) *dto.Histogram {
+	return &dto.Histogram{
+		SampleCount:   h.SampleCount,
+		SampleSum:     h.SampleSum,
+		Bucket:        h.Bucket,
+		Schema:        h.Schema,
+		ZeroThreshold: h.ZeroThreshold,
+		ZeroCount:     h.ZeroCount,
+		NegativeSpan:  h.NegativeSpan,
+		NegativeDelta: h.NegativeDelta,
+		PositiveSpan:  h.PositiveSpan,
+		PositiveDelta: h.PositiveDelta,
+		Exemplars:     h.Exemplars,
+	}
+}
+
+// nativeBuckets decodes the spans and delta-encoded counts of native buckets
+// into counts by bucket index. The indexes are converted to a schema reduced
+// by downscale.
+func nativeBuckets(spans []*dto.BucketSpan, deltas []int64, downscale int32) map[int32]int64 {
+	buckets := map[int32]int64{}
+	var (
+		idx   int32
+		count int64
+		d     int
+	)
+	for _, span := range spans {
+		idx += span.GetOffset()
+		for range span.GetLength() {
+			if d < len(deltas) {
+				count += deltas[d]
+				d++
+			}
+			buckets[((idx-1)>>downscale)+1] += count
+			idx++
+		}
+	}
+	return buckets
+}
+
+// nativeDelta returns the spans and delta-encoded counts of the increase of
+// the native buckets cur since prev, or false if any bucket decreased.
+func nativeDelta(cur, prev map[int32]int64) ([]*dto.BucketSpan, []int64, bool) {
+	for idx, count := range prev {
+		if cur[idx] < count {
+			return nil, nil, false
+		}
+	}
+	idxs := make([]int32, 0, len(cur))
+	for idx, count := range cur {
+		if count > prev[idx] {
+			idxs = append(idxs, idx)
+		}
+	}
+	sort.Slice(idxs, func(i, j int) bool { return idxs[i] < idxs[j] })
+
+	var (
+		spans  []*dto.BucketSpan
+		deltas = make([]int64, 0, len(idxs))
+		last   int64
+	)
+	for i, idx := range idxs {
+		switch {
+		case i == 0:
+			spans = append(spans, &dto.BucketSpan{Offset: &idx, Length: new(uint32)})
+		case idx != idxs[i-1]+1:
+			offset := idx - idxs[i-1] - 1
+			spans = append(spans, &dto.BucketSpan{Offset: &offset, Length: new(uint32)})
+		}
+		*spans[len(spans)-1].Length++
+		count := cur[idx] - prev[idx]
+		deltas = append(deltas, count-last)
+		last = count
+	}
+	return spans, deltas, true
+}
+
+// isFloat returns whether h is a float histogram.
+func isFloat(h *dto.Histogram) bool {
+	return h.GetSampleCountFloat() > 0 || h.GetZeroCountFloat() > 0 ||
+		len(h.PositiveCount) > 0 || len(h.NegativeCount) > 0
+}
+
+// createdTime returns the time of a created timestamp, or the zero time if
+// there is none.
+func createdTime(ts *timestamppb.Timestamp) time.Time {
+	if ts.GetSeconds() == 0 && ts.GetNanos() == 0 {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+// seriesKey returns a key identifying the series with the provided name and
+// labels, which are sorted as gathered from a Gatherer.
+func seriesKey(name string, labels []*dto.LabelPair) string {
+	var sb strings.Builder
+	sb.WriteString(name)
+	for _, lp := range labels {
+		sb.WriteByte('\xff')
+		sb.WriteString(lp.GetName())
+		sb.WriteByte('\xff')
+		sb.WriteString(lp.GetValue())
+	}
+	return sb.String()
+}
+
+// NewGatherer returns a Gatherer that converts the metric families gathered
+// from g into delta temporality with a Converter. Every conversion is
+// committed right away, so that the deltas of a gathering are lost if they
+// cannot be exported. Use it as the Gatherer of an exporter that doesn't
+// support delta temporality itself, e.g. a remotewrite.Exporter writing to a
+// backend that interprets counters as deltas.
+func NewGatherer(g prometheus.Gatherer) prometheus.Gatherer {
+	c := NewConverter()
+	return prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		mfs, err := g.Gather()
+		deltas, commit := c.Convert(mfs, time.Now())
+		commit()
+		return deltas, err
+	})
+}
@@ -0,0 +1,233 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package delta
+
+import (
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func counterFamily(name string, created time.Time, values ...float64) *dto.MetricFamily {
+	mf := &dto.MetricFamily{Name: proto.String(name), Type: dto.MetricType_COUNTER.Enum()}
+	for i, v := range values {
+		c := &dto.Counter{Value: proto.Float64(v)}
+		if !created.IsZero() {
+			c.CreatedTimestamp = timestamppb.New(created)
+		}
+		mf.Metric = append(mf.Metric, &dto.Metric{
+			Label:   []*dto.LabelPair{{Name: proto.String("i"), Value: proto.String(string(rune('a' + i)))}},
+			Counter: c,
+		})
+	}
+	return mf
+}
+
+type sample struct {
+	value float64
+	start time.Time
+}
+
+func counterSamples(mfs []*dto.MetricFamily) map[string]sample {
+	samples := map[string]sample{}
+	for _, mf := range mfs {
+		for _, m := range mf.GetMetric() {
+			samples[mf.GetName()+"/"+m.GetLabel()[0].GetValue()] = sample{
+				value: m.GetCounter().GetValue(),
+				start: m.GetCounter().GetCreatedTimestamp().AsTime(),
+			}
+		}
+	}
+	return samples
+}
+
+func TestConvertCounters(t *testing.T) {
+	var (
+		created = time.Unix(100, 0).UTC()
+		t1      = time.Unix(200, 0).UTC()
+		t2      = time.Unix(300, 0).UTC()
+		t3      = time.Unix(400, 0).UTC()
+		t4      = time.Unix(500, 0).UTC()
+	)
+	c := NewConverter()
+
+	// The first conversion omits the counter without created timestamp.
+	deltas, commit := c.Convert([]*dto.MetricFamily{
+		counterFamily("with_created", created, 10),
+		counterFamily("without_created", time.Time{}, 5),
+		{Name: proto.String("gauge"), Type: dto.MetricType_GAUGE.Enum(), Metric: []*dto.Metric{{Gauge: &dto.Gauge{Value: proto.Float64(7)}}}},
+	}, t1)
+	commit()
+	if len(deltas) != 2 || deltas[1].GetName() != "gauge" {
+		t.Fatalf("unexpected metric families %v", deltas)
+	}
+	assertSamples(t, counterSamples(deltas[:1]), map[string]sample{
+		"with_created/a": {10, created},
+	})
+
+	// A regular increase, and a new series.
+	deltas, commit = c.Convert([]*dto.MetricFamily{
+		counterFamily("with_created", created, 15),
+		counterFamily("without_created", time.Time{}, 8, 2),
+	}, t2)
+	assertSamples(t, counterSamples(deltas), map[string]sample{
+		"with_created/a":    {5, t1},
+		"without_created/a": {3, t1},
+		"without_created/b": {2, t1},
+	})
+
+	// Without committing, the next conversion covers both intervals. A
+	// decreased value is a reset.
+	deltas, commit = c.Convert([]*dto.MetricFamily{
+		counterFamily("with_created", created, 20),
+		counterFamily("without_created", time.Time{}, 1, 4),
+	}, t3)
+	commit()
+	assertSamples(t, counterSamples(deltas), map[string]sample{
+		"with_created/a":    {10, t1},
+		"without_created/a": {1, t1},
+		"without_created/b": {4, t1},
+	})
+
+	// A changed created timestamp is a reset, too.
+	deltas, _ = c.Convert([]*dto.MetricFamily{
+		counterFamily("with_created", t3.Add(time.Second), 3),
+	}, t4)
+	assertSamples(t, counterSamples(deltas), map[string]sample{
+		"with_created/a": {3, t3.Add(time.Second)},
+	})
+}
+
+func assertSamples(t *testing.T, got, want map[string]sample) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("got %d samples, want %d: %v", len(got), len(want), got)
+	}
+	for k, w := range want {
+		g, ok := got[k]
+		if !ok {
+			t.Errorf("missing sample %s", k)
+			continue
+		}
+		if g.value != w.value || !g.start.Equal(w.start) {
+			t.Errorf("got %s = %v since %v, want %v since %v", k, g.value, g.start, w.value, w.start)
+		}
+	}
+}
+
+func TestConvertHistograms(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	h := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:                           "h",
+		Help:                           "A histogram.",
+		Buckets:                        []float64{1, 10},
+		NativeHistogramBucketFactor:    1.1,
+		NativeHistogramMaxBucketNumber: 4,
+	})
+	reg.MustRegister(h)
+	c := NewConverter()
+	convert := func(now time.Time) *dto.Histogram {
+		t.Helper()
+		mfs, err := reg.Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+		deltas, commit := c.Convert(mfs, now)
+		commit()
+		return deltas[0].GetMetric()[0].GetHistogram()
+	}
+
+	h.Observe(0.5)
+	h.Observe(5)
+	convert(time.Unix(100, 0))
+
+	// The observations exceed the maximum number of native buckets, so
+	// that the schema is reduced.
+	for _, v := range []float64{5, 20, 2, 3, 4} {
+		h.Observe(v)
+	}
+	d := convert(time.Unix(200, 0))
+	if got, want := d.GetSampleCount(), uint64(5); got != want {
+		t.Errorf("got count %d, want %d", got, want)
+	}
+	if got, want := d.GetSampleSum(), 34.0; got != want {
+		t.Errorf("got sum %v, want %v", got, want)
+	}
+	var buckets []uint64
+	for _, b := range d.GetBucket() {
+		buckets = append(buckets, b.GetCumulativeCount())
+	}
+	if len(buckets) != 2 || buckets[0] != 0 || buckets[1] != 4 {
+		t.Errorf("got cumulative bucket counts %v, want [0 4]", buckets)
+	}
+	if !d.GetCreatedTimestamp().AsTime().Equal(time.Unix(100, 0)) {
+		t.Errorf("got start %v, want %v", d.GetCreatedTimestamp().AsTime(), time.Unix(100, 0))
+	}
+	if d.GetSchema() >= 3 {
+		t.Errorf("got schema %d, want the schema to be reduced from 3", d.GetSchema())
+	}
+	var native int64
+	for _, b := range nativeBuckets(d.GetPositiveSpan(), d.GetPositiveDelta(), 0) {
+		native += b
+	}
+	if native != 5 {
+		t.Errorf("got %d observations in native buckets, want 5", native)
+	}
+}
+
+func TestNativeDelta(t *testing.T) {
+	cur := map[int32]int64{-2: 1, 0: 3, 1: 5, 4: 2}
+	prev := map[int32]int64{0: 1, 1: 5}
+	spans, deltas, ok := nativeDelta(cur, prev)
+	if !ok {
+		t.Fatal("unexpected decrease")
+	}
+	got := nativeBuckets(spans, deltas, 0)
+	want := map[int32]int64{-2: 1, 0: 2, 4: 2}
+	if len(got) != len(want) {
+		t.Fatalf("got buckets %v, want %v", got, want)
+	}
+	for idx, count := range want {
+		if got[idx] != count {
+			t.Errorf("got buckets %v, want %v", got, want)
+		}
+	}
+
+	if _, _, ok := nativeDelta(prev, cur); ok {
+		t.Error("decrease not detected")
+	}
+}
+
+func TestNewGatherer(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	cnt := prometheus.NewCounter(prometheus.CounterOpts{Name: "c_total", Help: "A counter."})
+	reg.MustRegister(cnt)
+	g := NewGatherer(reg)
+
+	cnt.Add(3)
+	for _, want := range []float64{3, 0} {
+		mfs, err := g.Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := mfs[0].GetMetric()[0].GetCounter().GetValue(); got != want {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	}
+}
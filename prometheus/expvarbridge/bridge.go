@@ -0,0 +1,233 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package expvarbridge provides a bridge that publishes Prometheus metrics as
+// expvar variables, so that tooling reading /debug/vars keeps working while a
+// code base migrates its instrumentation to client_golang. It is the reverse
+// of collectors.NewExpvarCollector.
+//
+// Each exported metric family becomes one expvar variable, which gathers the
+// metrics whenever it is read. A family with a single series without labels
+// is published as its value. Otherwise, the label values form nested JSON
+// objects, ordered by label name, whose leaves are the values, which is the
+// structure collectors.NewExpvarCollector expects. Histograms and summaries are
+// published as objects with "count", "sum", and "buckets" or "quantiles"
+// fields. NaN and infinite values, which JSON cannot represent as numbers, are
+// published as the strings "NaN", "+Inf", and "-Inf".
+package expvarbridge
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"math"
+	"strconv"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Config defines the expvar bridge config.
+type Config struct {
+	// Exports maps the names of the metric families to publish to the names
+	// of their expvar variables. Required.
+	Exports map[string]string
+
+	// The Gatherer to use for metrics. Defaults to prometheus.DefaultGatherer.
+	Gatherer prometheus.Gatherer
+
+	// The logger that gathering errors are written to. As expvar variables
+	// cannot return errors, the successfully gathered metrics are published
+	// regardless. Defaults to no logging.
+	Logger Logger
+}
+
+// Logger is the minimal interface Bridge needs for logging. Note that
+// log.Logger from the standard library implements this interface, and it is
+// easy to implement by custom loggers, if they don't do so already anyway.
+type Logger interface {
+	Println(v ...any)
+}
+
+// Bridge publishes Prometheus metrics as expvar variables.
+type Bridge struct {
+	exports map[string]string
+	logger  Logger
+
+	g prometheus.Gatherer
+}
+
+// NewBridge returns a pointer to a new Bridge struct. Call Publish to publish
+// the variables.
+func NewBridge(c *Config) (*Bridge, error) {
+	if len(c.Exports) == 0 {
+		return nil, errors.New("no metrics to export")
+	}
+	for metric, name := range c.Exports {
+		if metric == "" || name == "" {
+			return nil, fmt.Errorf("invalid export %q -> %q", metric, name)
+		}
+	}
+	b := &Bridge{
+		exports: c.Exports,
+		logger:  c.Logger,
+		g:       c.Gatherer,
+	}
+	if b.g == nil {
+		b.g = prometheus.DefaultGatherer
+	}
+	return b, nil
+}
+
+// Publish publishes the exported metric families with expvar.Publish. As
+// expvar doesn't allow to unpublish variables, Publish must only be called
+// once per variable name. It returns an error, and publishes nothing, if any
+// of the names is already in use.
+func (b *Bridge) Publish() error {
+	for _, name := range b.exports {
+		if expvar.Get(name) != nil {
+			return fmt.Errorf("expvar variable %q already published", name)
+		}
+	}
+	for metric, name := range b.exports {
+		expvar.Publish(name, b.Var(metric))
+	}
+	return nil
+}
+
+// Var returns an expvar.Var for the metric family with the provided name,
+// e.g. to add it to an expvar.Map instead of publishing it at the top level.
+// The family doesn't need to be listed in Config.Exports. A missing family is
+// published as null.
+func (b *Bridge) Var(metric string) expvar.Var {
+	return variable{b: b, metric: metric}
+}
+
+type variable struct {
+	b      *Bridge
+	metric string
+}
+
+// String implements expvar.Var.
+func (v variable) String() string {
+	mfs, err := v.b.g.Gather()
+	if err != nil && v.b.logger != nil {
+		v.b.logger.Println("error gathering metrics:", err)
+	}
+	for _, mf := range mfs {
+		if mf.GetName() != v.metric {
+			continue
+		}
+		out, err := json.Marshal(familyValue(mf))
+		if err != nil {
+			if v.b.logger != nil {
+				v.b.logger.Println("error encoding metric family", v.metric+":", err)
+			}
+			return "null"
+		}
+		return string(out)
+	}
+	return "null"
+}
+
+// familyValue returns the JSON structure of the metric family.
+func familyValue(mf *dto.MetricFamily) any {
+	metrics := mf.GetMetric()
+	if len(metrics) == 1 && len(metrics[0].GetLabel()) == 0 {
+		return metricValue(metrics[0])
+	}
+	root := map[string]any{}
+	for _, m := range metrics {
+		labels := m.GetLabel()
+		if len(labels) == 0 {
+			// Can't be represented alongside labeled series.
+			continue
+		}
+		node := root
+		for _, lp := range labels[:len(labels)-1] {
+			child, ok := node[lp.GetValue()].(map[string]any)
+			if !ok {
+				if _, exists := node[lp.GetValue()]; exists {
+					// Inconsistent label names, skip.
+					node = nil
+					break
+				}
+				child = map[string]any{}
+				node[lp.GetValue()] = child
+			}
+			node = child
+		}
+		if node != nil {
+			node[labels[len(labels)-1].GetValue()] = metricValue(m)
+		}
+	}
+	return root
+}
+
+// metricValue returns the JSON structure of a single metric.
+func metricValue(m *dto.Metric) any {
+	switch {
+	case m.Counter != nil:
+		return number(m.GetCounter().GetValue())
+	case m.Gauge != nil:
+		return number(m.GetGauge().GetValue())
+	case m.Untyped != nil:
+		return number(m.GetUntyped().GetValue())
+	case m.Summary != nil:
+		s := m.GetSummary()
+		quantiles := make(map[string]any, len(s.GetQuantile()))
+		for _, q := range s.GetQuantile() {
+			quantiles[strconv.FormatFloat(q.GetQuantile(), 'g', -1, 64)] = number(q.GetValue())
+		}
+		return map[string]any{
+			"count":     s.GetSampleCount(),
+			"sum":       number(s.GetSampleSum()),
+			"quantiles": quantiles,
+		}
+	case m.Histogram != nil:
+		h := m.GetHistogram()
+		count := float64(h.GetSampleCount())
+		if h.SampleCountFloat != nil {
+			count = h.GetSampleCountFloat()
+		}
+		buckets := make(map[string]any, len(h.GetBucket()))
+		for _, bucket := range h.GetBucket() {
+			bc := float64(bucket.GetCumulativeCount())
+			if bucket.CumulativeCountFloat != nil {
+				bc = bucket.GetCumulativeCountFloat()
+			}
+			buckets[strconv.FormatFloat(bucket.GetUpperBound(), 'g', -1, 64)] = number(bc)
+		}
+		return map[string]any{
+			"count":   number(count),
+			"sum":     number(h.GetSampleSum()),
+			"buckets": buckets,
+		}
+	}
+	return nil
+}
+
+// number returns f, or its string representation if JSON can't represent it.
+func number(f float64) any {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	}
+	return f
+}
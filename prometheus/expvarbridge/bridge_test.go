@@ -0,0 +1,126 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expvarbridge
+
+import (
+	"expvar"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestVar(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	up := prometheus.NewGauge(prometheus.GaugeOpts{Name: "up", Help: "Up."})
+	up.Set(1)
+	nan := prometheus.NewGauge(prometheus.GaugeOpts{Name: "nan", Help: "NaN."})
+	nan.Set(math.NaN())
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "requests_total",
+		Help: "Requests.",
+	}, []string{"method", "code"})
+	requests.WithLabelValues("get", "200").Add(3)
+	requests.WithLabelValues("get", "500").Inc()
+	requests.WithLabelValues("post", "200").Add(2)
+	latency := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "latency_seconds",
+		Help:    "Latency.",
+		Buckets: []float64{0.5, 1},
+	})
+	latency.Observe(0.25)
+	latency.Observe(0.75)
+	reg.MustRegister(up, nan, requests, latency)
+
+	b, err := NewBridge(&Config{Exports: map[string]string{"up": "up"}, Gatherer: reg})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		metric, want string
+	}{
+		{metric: "up", want: `1`},
+		{metric: "nan", want: `"NaN"`},
+		{metric: "missing", want: `null`},
+		{metric: "requests_total", want: `{"200":{"get":3,"post":2},"500":{"get":1}}`},
+		{metric: "latency_seconds", want: `{"buckets":{"0.5":1,"1":2},"count":2,"sum":1}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.metric, func(t *testing.T) {
+			if got := b.Var(tc.metric).String(); got != tc.want {
+				t.Errorf("got %s, want %s", got, tc.want)
+			}
+		})
+	}
+
+	// Values are gathered on access.
+	up.Set(0)
+	if got := b.Var("up").String(); got != "0" {
+		t.Errorf("got %s after update, want 0", got)
+	}
+}
+
+func TestPublishRoundTrip(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "roundtrip_requests_total",
+		Help: "Requests.",
+	}, []string{"code"})
+	requests.WithLabelValues("200").Add(3)
+	reg.MustRegister(requests)
+
+	b, err := NewBridge(&Config{
+		Exports:  map[string]string{"roundtrip_requests_total": "expvarbridge_test_requests"},
+		Gatherer: reg,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Publish(); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Publish(); err == nil {
+		t.Error("expected error publishing the same variable twice")
+	}
+	if expvar.Get("expvarbridge_test_requests") == nil {
+		t.Fatal("variable not published")
+	}
+
+	// The expvar collector reads the published variable back.
+	c := collectors.NewExpvarCollector(map[string]*prometheus.Desc{
+		"expvarbridge_test_requests": prometheus.NewDesc("requests_total", "Requests.", []string{"code"}, nil),
+	})
+	want := `
+# HELP requests_total Requests.
+# TYPE requests_total untyped
+requests_total{code="200"} 3
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNewBridgeValidation(t *testing.T) {
+	for _, c := range []*Config{
+		{},
+		{Exports: map[string]string{"up": ""}},
+	} {
+		if _, err := NewBridge(c); err == nil {
+			t.Errorf("expected error for exports %v", c.Exports)
+		}
+	}
+}
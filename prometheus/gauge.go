@@ -134,6 +134,12 @@ func (g *gauge) Write(out *dto.Metric) error {
 	return populateMetric(GaugeValue, val, g.labelPairs, nil, out, nil)
 }
 
+func (g *gauge) appendProto(b []byte) ([]byte, dto.MetricType, []*dto.LabelPair, bool) {
+	val := math.Float64frombits(atomic.LoadUint64(&g.valBits))
+	b, t, ok := appendValueProto(b, GaugeValue, val, nil)
+	return b, t, g.labelPairs, ok
+}
+
 // GaugeVec is a Collector that bundles a set of Gauges that all share the same
 // Desc, but have different values for their variable labels. This is used if
 // you want to count the same thing partitioned by various dimensions
@@ -160,7 +166,7 @@ func (v2) NewGaugeVec(opts GaugeVecOpts) *GaugeVec {
 		opts.VariableLabels,
 		opts.ConstLabels,
 	)
-	return &GaugeVec{
+	v := &GaugeVec{
 		MetricVec: NewMetricVec(desc, func(lvs ...string) Metric {
 			if len(lvs) != len(desc.variableLabels.names) {
 				panic(makeInconsistentCardinalityError(desc.fqName, desc.variableLabels.names, lvs))
@@ -170,6 +176,8 @@ func (v2) NewGaugeVec(opts GaugeVecOpts) *GaugeVec {
 			return result
 		}),
 	}
+	v.internLabelValues = opts.InternLabelValues
+	return v
 }
 
 // GetMetricWithLabelValues returns the Gauge for the given slice of label
@@ -236,6 +244,24 @@ func (v *GaugeVec) WithLabelValues(lvs ...string) Gauge {
 	return g
 }
 
+// WithLabelValuesBatch works as GetMetricWithLabelValues for each of the
+// provided slices of label values, but creates all the Gauges at once, see
+// MetricVec.GetMetricWithLabelValuesBatch. Unlike WithLabelValues, it returns
+// an error rather than panicking, as the label values are usually generated,
+// e.g. to pre-create the Gauges of all combinations of label values at
+// startup.
+func (v *GaugeVec) WithLabelValuesBatch(lvss [][]string) ([]Gauge, error) {
+	metrics, err := v.MetricVec.GetMetricWithLabelValuesBatch(lvss)
+	if err != nil {
+		return nil, err
+	}
+	gauges := make([]Gauge, len(metrics))
+	for i, m := range metrics {
+		gauges[i] = m.(Gauge)
+	}
+	return gauges, nil
+}
+
 // With works as GetMetricWith, but panics where GetMetricWithLabels would have
 // returned an error. Not returning an error allows shortcuts like
 //
@@ -53,6 +53,10 @@ const (
 	goMemoryClassesProfilingBucketsBytes    = "/memory/classes/profiling/buckets:bytes"
 	goMemoryClassesMetadataOtherBytes       = "/memory/classes/metadata/other:bytes"
 	goMemoryClassesOtherBytes               = "/memory/classes/other:bytes"
+	goCPUClassesGCMarkAssistCPUSeconds      = "/cpu/classes/gc/mark/assist:cpu-seconds"
+	goCPUClassesGCMarkIdleCPUSeconds        = "/cpu/classes/gc/mark/idle:cpu-seconds"
+	goCPUClassesGCTotalCPUSeconds           = "/cpu/classes/gc/total:cpu-seconds"
+	goCPUClassesTotalCPUSeconds             = "/cpu/classes/total:cpu-seconds"
 )
 
 // rmNamesForMemStatsMetrics represents runtime/metrics names required to populate goRuntimeMemStats from like logic.
@@ -79,6 +83,15 @@ var rmNamesForMemStatsMetrics = []string{
 	goMemoryClassesOtherBytes,
 }
 
+// rmNamesForGCCPUPressureMetrics represents runtime/metrics names required to
+// populate the GC CPU pressure metrics.
+var rmNamesForGCCPUPressureMetrics = []string{
+	goCPUClassesGCMarkAssistCPUSeconds,
+	goCPUClassesGCMarkIdleCPUSeconds,
+	goCPUClassesGCTotalCPUSeconds,
+	goCPUClassesTotalCPUSeconds,
+}
+
 func bestEffortLookupRM(lookup []string) []metrics.Description {
 	ret := make([]metrics.Description, 0, len(lookup))
 	for _, rm := range metrics.All() {
@@ -117,6 +130,10 @@ type goCollector struct {
 	// as well.
 	msMetrics        memStatsMetrics
 	msMetricsEnabled bool
+
+	// gcCPU holds the state required to derive the GC CPU pressure
+	// metrics. It is nil if those metrics are not enabled.
+	gcCPU *gcCPUPressureMetrics
 }
 
 type rmMetricDesc struct {
@@ -195,7 +212,7 @@ func NewGoCollector(opts ...func(o *internal.GoCollectorOptions)) Collector {
 	metricSet := make([]collectorMetric, 0, len(exposedDescriptions))
 	// SampleBuf is used for reading from runtime/metrics.
 	// We are assuming the largest case to have stable pointers for sampleMap purposes.
-	sampleBuf := make([]metrics.Sample, 0, len(exposedDescriptions)+len(opt.RuntimeMetricSumForHist)+len(rmNamesForMemStatsMetrics))
+	sampleBuf := make([]metrics.Sample, 0, len(exposedDescriptions)+len(opt.RuntimeMetricSumForHist)+len(rmNamesForMemStatsMetrics)+len(rmNamesForGCCPUPressureMetrics))
 	sampleMap := make(map[string]*metrics.Sample, len(exposedDescriptions))
 	for _, d := range exposedDescriptions {
 		namespace, subsystem, name, ok := internal.RuntimeMetricsToProm(&d.Description)
@@ -276,6 +293,18 @@ func NewGoCollector(opts ...func(o *internal.GoCollectorOptions)) Collector {
 		}
 	}
 
+	var gcCPU *gcCPUPressureMetrics
+	if opt.EnableGCCPUPressureMetrics {
+		gcCPU = newGCCPUPressureMetrics()
+		for _, d := range bestEffortLookupRM(rmNamesForGCCPUPressureMetrics) {
+			if _, ok := sampleMap[d.Name]; ok {
+				continue
+			}
+			sampleBuf = append(sampleBuf, metrics.Sample{Name: d.Name})
+			sampleMap[d.Name] = &sampleBuf[len(sampleBuf)-1]
+		}
+	}
+
 	return &goCollector{
 		base:                 newBaseGoCollector(),
 		sampleBuf:            sampleBuf,
@@ -284,6 +313,7 @@ func NewGoCollector(opts ...func(o *internal.GoCollectorOptions)) Collector {
 		rmExactSumMapForHist: opt.RuntimeMetricSumForHist,
 		msMetrics:            msMetrics,
 		msMetricsEnabled:     !opt.DisableMemStatsLikeMetrics,
+		gcCPU:                gcCPU,
 	}
 }
 
@@ -300,6 +330,9 @@ func (c *goCollector) Describe(ch chan<- *Desc) {
 	for _, m := range c.rmExposedMetrics {
 		ch <- m.Desc()
 	}
+	if c.gcCPU != nil {
+		c.gcCPU.describe(ch)
+	}
 }
 
 // Collect returns the current state of all metrics of the collector.
@@ -367,6 +400,10 @@ func (c *goCollector) Collect(ch chan<- Metric) {
 			ch <- MustNewConstMetric(i.desc, i.valType, i.eval(&ms))
 		}
 	}
+
+	if c.gcCPU != nil {
+		c.gcCPU.collect(ch, c.sampleMap)
+	}
 }
 
 // unwrapScalarRMValue unwraps a runtime/metrics value that is assumed
@@ -458,6 +495,89 @@ func memStatsFromRM(ms *runtime.MemStats, rm map[string]*metrics.Sample) {
 	ms.GCCPUFraction = 0
 }
 
+// gcCPUPressureMetrics derives ready-to-alert gauges about the CPU time spent
+// on garbage collection from the cumulative /cpu/classes runtime/metrics.
+//
+// Unlike the (intentionally omitted) MemStats GCCPUFraction, which is an
+// average over the lifetime of the process, the fractions are calculated over
+// the interval since the previous collection that observed an update of the
+// underlying values. The first collection covers the interval since the
+// process started. As every collection starts a new interval, each of several
+// scrapers (e.g. an HA pair of Prometheus servers, or a push in addition to a
+// scrape) gets the fractions over a different and shorter interval than its
+// own scrape interval.
+type gcCPUPressureMetrics struct {
+	gcFraction, assistFraction, idleFraction *Desc
+
+	// Values of the previous update and the fractions calculated then.
+	// Protected by goCollector.mu.
+	lastGC, lastAssist, lastIdle, lastTotal float64
+	gc, assist, idle                        float64
+}
+
+func newGCCPUPressureMetrics() *gcCPUPressureMetrics {
+	return &gcCPUPressureMetrics{
+		gcFraction: NewDesc(
+			"go_gc_cpu_fraction",
+			"Fraction of the available CPU time spent on garbage collection since the previous collection, including GC assists, background mark workers, idle mark workers, and pauses. Every collection, by any scraper, starts a new interval.",
+			nil, nil,
+		),
+		assistFraction: NewDesc(
+			"go_gc_assist_cpu_fraction",
+			"Fraction of the available CPU time goroutines spent performing GC assists since the previous collection. A high value indicates that the allocation rate is slowing down the application. Every collection, by any scraper, starts a new interval.",
+			nil, nil,
+		),
+		idleFraction: NewDesc(
+			"go_gc_idle_cpu_fraction",
+			"Fraction of the available CPU time spent on GC marking in otherwise idle processors since the previous collection. Every collection, by any scraper, starts a new interval.",
+			nil, nil,
+		),
+	}
+}
+
+func (m *gcCPUPressureMetrics) describe(ch chan<- *Desc) {
+	ch <- m.gcFraction
+	ch <- m.assistFraction
+	ch <- m.idleFraction
+}
+
+// collect must be called with goCollector.mu held, after metrics.Read has
+// populated the samples in rm.
+func (m *gcCPUPressureMetrics) collect(ch chan<- Metric, rm map[string]*metrics.Sample) {
+	lookupOrZero := func(name string) float64 {
+		if s, ok := rm[name]; ok {
+			return unwrapScalarRMValue(s.Value)
+		}
+		return 0
+	}
+
+	gc := lookupOrZero(goCPUClassesGCTotalCPUSeconds)
+	assist := lookupOrZero(goCPUClassesGCMarkAssistCPUSeconds)
+	idle := lookupOrZero(goCPUClassesGCMarkIdleCPUSeconds)
+	total := lookupOrZero(goCPUClassesTotalCPUSeconds)
+
+	// The /cpu/classes metrics are only updated by the runtime at certain
+	// points (e.g. at the end of a GC cycle). If they haven't moved since
+	// the previous update, keep reporting the previous fractions rather than
+	// zero, and extend the interval for the next update accordingly.
+	if dt := total - m.lastTotal; dt > 0 {
+		fraction := func(cur, last float64) float64 {
+			if cur < last {
+				return 0
+			}
+			return (cur - last) / dt
+		}
+		m.gc = fraction(gc, m.lastGC)
+		m.assist = fraction(assist, m.lastAssist)
+		m.idle = fraction(idle, m.lastIdle)
+		m.lastGC, m.lastAssist, m.lastIdle, m.lastTotal = gc, assist, idle, total
+	}
+
+	ch <- MustNewConstMetric(m.gcFraction, GaugeValue, m.gc)
+	ch <- MustNewConstMetric(m.assistFraction, GaugeValue, m.assist)
+	ch <- MustNewConstMetric(m.idleFraction, GaugeValue, m.idle)
+}
+
 // batchHistogram is a mutable histogram that is updated
 // in batches.
 type batchHistogram struct {
@@ -17,6 +17,7 @@
 package prometheus
 
 import (
+	"fmt"
 	"math"
 	"reflect"
 	"regexp"
@@ -237,6 +238,7 @@ func collectGoMetrics(t *testing.T, opts internal.GoCollectorOptions) []Metric {
 		o.DisableMemStatsLikeMetrics = opts.DisableMemStatsLikeMetrics
 		o.RuntimeMetricSumForHist = opts.RuntimeMetricSumForHist
 		o.RuntimeMetricRules = opts.RuntimeMetricRules
+		o.EnableGCCPUPressureMetrics = opts.EnableGCCPUPressureMetrics
 	}).(*goCollector)
 
 	// Collect all metrics.
@@ -258,6 +260,46 @@ func collectGoMetrics(t *testing.T, opts internal.GoCollectorOptions) []Metric {
 	return metrics
 }
 
+func TestGCCPUPressureMetrics(t *testing.T) {
+	// Make sure the runtime has updated the /cpu/classes metrics at least once.
+	runtime.GC()
+
+	for _, disableMemStatsLikeMetrics := range []bool{false, true} {
+		t.Run(fmt.Sprintf("DisableMemStatsLikeMetrics=%v", disableMemStatsLikeMetrics), func(t *testing.T) {
+			goMetrics := collectGoMetrics(t, internal.GoCollectorOptions{
+				DisableMemStatsLikeMetrics: disableMemStatsLikeMetrics,
+				EnableGCCPUPressureMetrics: true,
+			})
+
+			want := map[string]bool{
+				"go_gc_cpu_fraction":        false,
+				"go_gc_assist_cpu_fraction": false,
+				"go_gc_idle_cpu_fraction":   false,
+			}
+			for _, m := range goMetrics {
+				name := m.Desc().fqName
+				if _, ok := want[name]; !ok {
+					continue
+				}
+				want[name] = true
+
+				pb := &dto.Metric{}
+				if err := m.Write(pb); err != nil {
+					t.Fatal(err)
+				}
+				if v := pb.GetGauge().GetValue(); v < 0 || v > 1 {
+					t.Errorf("%s: got %v, want value in [0, 1]", name, v)
+				}
+			}
+			for name, found := range want {
+				if !found {
+					t.Errorf("%s not collected", name)
+				}
+			}
+		})
+	}
+}
+
 func TestMemStatsEquivalence(t *testing.T) {
 	var msReal, msFake runtime.MemStats
 	descs := bestEffortLookupRM(rmNamesForMemStatsMetrics)
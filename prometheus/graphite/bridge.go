@@ -17,12 +17,15 @@ package graphite
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/prometheus/common/expfmt"
@@ -35,6 +38,8 @@ import (
 
 const (
 	defaultInterval       = 15 * time.Second
+	defaultMinBackoff     = 100 * time.Millisecond
+	defaultMaxBackoff     = 5 * time.Second
 	millisecondsPerSecond = 1000
 )
 
@@ -54,7 +59,9 @@ const (
 
 // Config defines the Graphite bridge config.
 type Config struct {
-	// Whether to use Graphite tags or not. Defaults to false.
+	// Whether to use Graphite tags or not. Defaults to false. If true, labels
+	// are pushed as tags in the form "name;tag1=value1;tag2=value2", sorted by
+	// tag name, instead of being flattened into the metric path.
 	UseTags bool
 
 	// The url to push data to. Required.
@@ -69,6 +76,22 @@ type Config struct {
 	// The timeout for pushing metrics to Graphite. Defaults to 15 seconds.
 	Timeout time.Duration
 
+	// The TLS configuration for connecting to Graphite. If nil, a plain TCP
+	// connection is used. Servers requiring authentication by client
+	// certificate are supported by setting Certificates or
+	// GetClientCertificate.
+	TLSConfig *tls.Config
+
+	// The number of times a push is retried after failing to connect to
+	// Graphite or to write the metrics, with a new connection each time.
+	// Defaults to 0, i.e. no retries.
+	MaxRetries int
+
+	// The backoff before the first retry, which is doubled for each further
+	// retry up to MaxBackoff. Defaults to 100 milliseconds and 5 seconds,
+	// respectively.
+	MinBackoff, MaxBackoff time.Duration
+
 	// The Gatherer to use for metrics. Defaults to prometheus.DefaultGatherer.
 	Gatherer prometheus.Gatherer
 
@@ -83,11 +106,15 @@ type Config struct {
 
 // Bridge pushes metrics to the configured Graphite server.
 type Bridge struct {
-	useTags  bool
-	url      string
-	prefix   string
-	interval time.Duration
-	timeout  time.Duration
+	useTags    bool
+	url        string
+	prefix     string
+	interval   time.Duration
+	timeout    time.Duration
+	tlsConfig  *tls.Config
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
 
 	errorHandling HandlerErrorHandling
 	logger        Logger
@@ -140,6 +167,18 @@ func NewBridge(c *Config) (*Bridge, error) {
 		b.timeout = c.Timeout
 	}
 
+	b.tlsConfig = c.TLSConfig
+	b.maxRetries = c.MaxRetries
+
+	b.minBackoff = c.MinBackoff
+	if b.minBackoff == z {
+		b.minBackoff = defaultMinBackoff
+	}
+	b.maxBackoff = c.MaxBackoff
+	if b.maxBackoff == z {
+		b.maxBackoff = defaultMaxBackoff
+	}
+
 	b.errorHandling = c.ErrorHandling
 
 	return b, nil
@@ -153,7 +192,7 @@ func (b *Bridge) Run(ctx context.Context) {
 	for {
 		select {
 		case <-ticker.C:
-			if err := b.Push(); err != nil && b.logger != nil {
+			if err := b.push(ctx); err != nil && b.logger != nil {
 				b.logger.Println("error pushing to Graphite:", err)
 			}
 		case <-ctx.Done():
@@ -164,6 +203,11 @@ func (b *Bridge) Run(ctx context.Context) {
 
 // Push pushes Prometheus metrics to the configured Graphite server.
 func (b *Bridge) Push() error {
+	return b.push(context.Background())
+}
+
+// push implements Push. It stops retrying once ctx is done.
+func (b *Bridge) push(ctx context.Context) error {
 	mfs, err := b.g.Gather()
 	if err != nil || len(mfs) == 0 {
 		switch b.errorHandling {
@@ -178,13 +222,53 @@ func (b *Bridge) Push() error {
 		}
 	}
 
-	conn, err := net.DialTimeout("tcp", b.url, b.timeout)
+	var buf bytes.Buffer
+	if err := writeMetrics(&buf, mfs, b.useTags, b.prefix, model.Now()); err != nil {
+		return err
+	}
+
+	backoff := b.minBackoff
+	for attempt := 0; ; attempt++ {
+		err = b.send(buf.Bytes())
+		if err == nil || attempt >= b.maxRetries {
+			return err
+		}
+		if b.logger != nil {
+			b.logger.Println("reconnecting to Graphite after error:", err)
+		}
+		t := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+		backoff = min(2*backoff, b.maxBackoff)
+	}
+}
+
+// send connects to Graphite and writes the metrics in b.
+func (b *Bridge) send(metrics []byte) error {
+	dialer := &net.Dialer{Timeout: b.timeout}
+	var (
+		conn net.Conn
+		err  error
+	)
+	if b.tlsConfig != nil {
+		conn, err = tls.DialWithDialer(dialer, "tcp", b.url, b.tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", b.url)
+	}
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 
-	return writeMetrics(conn, mfs, b.useTags, b.prefix, model.Now())
+	if err := conn.SetWriteDeadline(time.Now().Add(b.timeout)); err != nil {
+		return err
+	}
+	_, err = conn.Write(metrics)
+	return err
 }
 
 func writeMetrics(w io.Writer, mfs []*dto.MetricFamily, useTags bool, prefix string, now model.Time) error {
@@ -246,22 +330,61 @@ func writeMetric(buf *bufio.Writer, m model.Metric, useTags bool) error {
 	return nil
 }
 
+// writeTags writes the labels of m other than the metric name as tags, sorted
+// by tag name.
 func writeTags(buf *bufio.Writer, m model.Metric) error {
+	type tag struct{ name, value string }
+	tags := make([]tag, 0, len(m))
 	for label, value := range m {
 		if label != model.MetricNameLabel {
-			buf.WriteRune(';')
-			if _, err := buf.WriteString(string(label)); err != nil {
-				return err
-			}
-			buf.WriteRune('=')
-			if _, err := buf.WriteString(string(value)); err != nil {
-				return err
-			}
+			tags = append(tags, tag{sanitizeTag(string(label)), sanitizeTagValue(string(value))})
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].name < tags[j].name })
+	for _, t := range tags {
+		if err := buf.WriteByte(';'); err != nil {
+			return err
+		}
+		if _, err := buf.WriteString(t.name); err != nil {
+			return err
+		}
+		if err := buf.WriteByte('='); err != nil {
+			return err
+		}
+		if _, err := buf.WriteString(t.value); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
+// sanitizeTag replaces the characters Graphite doesn't allow in tag names.
+func sanitizeTag(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ';', '!', '^', '=', ' ':
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+// sanitizeTagValue replaces the characters Graphite doesn't allow in tag
+// values. Values must not be empty or start with a tilde.
+func sanitizeTagValue(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case ';', ' ':
+			return '_'
+		}
+		return r
+	}, s)
+	if s == "" || s[0] == '~' {
+		s = "_" + s
+	}
+	return s
+}
+
 func writeLabels(buf *bufio.Writer, m model.Metric, numLabels int) error {
 	labelStrings := make([]string, 0, numLabels)
 	for label, value := range m {
@@ -17,11 +17,14 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"reflect"
 	"regexp"
@@ -468,3 +471,132 @@ func ExampleBridge() {
 	// Start pushing metrics to Graphite in the Run() loop.
 	b.Run(ctx)
 }
+
+func TestWriteTagsSanitized(t *testing.T) {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	m := model.Metric{model.MetricNameLabel: "name", "b": "x;y", "a": "~tilde", "a1": "1", "c": ""}
+	if err := writeMetric(w, m, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "name;a=_~tilde;a1=1;b=x_y;c=_"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestPushTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(nil)
+	defer srv.Close()
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", srv.TLS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	readc := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var b bytes.Buffer
+		io.Copy(&b, conn)
+		readc <- b.String()
+	}()
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(prometheus.NewGauge(prometheus.GaugeOpts{Name: "name", ConstLabels: prometheus.Labels{"a": "b"}}))
+	b, err := NewBridge(&Config{
+		URL:       ln.Addr().String(),
+		Gatherer:  reg,
+		UseTags:   true,
+		TLSConfig: srv.Client().Transport.(*http.Transport).TLSClientConfig,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Push(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case got := <-readc:
+		if !strings.HasPrefix(got, "name;a=b 0 ") {
+			t.Errorf("got unexpected push %q", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no result from graphite server")
+	}
+}
+
+type countingLogger struct{ n int }
+
+func (l *countingLogger) Println(...interface{}) { l.n++ }
+
+func TestPushRetries(t *testing.T) {
+	// Get a free port nothing listens on.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(prometheus.NewGauge(prometheus.GaugeOpts{Name: "name"}))
+	logger := &countingLogger{}
+	b, err := NewBridge(&Config{
+		URL:        addr,
+		Gatherer:   reg,
+		MaxRetries: 2,
+		MinBackoff: time.Millisecond,
+		Logger:     logger,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Push(); err == nil {
+		t.Fatal("expected error")
+	}
+	if logger.n != 2 {
+		t.Errorf("got %d retries, want 2", logger.n)
+	}
+}
+
+func TestRunStopsRetrying(t *testing.T) {
+	// Get a free port nothing listens on.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(prometheus.NewGauge(prometheus.GaugeOpts{Name: "name"}))
+	b, err := NewBridge(&Config{
+		URL:        addr,
+		Gatherer:   reg,
+		Interval:   time.Millisecond,
+		MaxRetries: 10,
+		MinBackoff: time.Hour,
+		MaxBackoff: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		b.Run(ctx)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Run didn't return while retrying a push after its context was done")
+	}
+}
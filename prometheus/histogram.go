@@ -495,6 +495,27 @@ type HistogramOpts struct {
 	// 5m is used. To always delete the oldest exemplar, set it to a negative value.
 	NativeHistogramExemplarTTL time.Duration
 
+	// If Stripes is greater than one, observations are spread over that
+	// number of independent sets of bucket counts (“stripes”), which are
+	// merged when the Histogram is collected. This avoids contention on
+	// the counts of a Histogram observed at very high rates from many
+	// goroutines at the same time, at the cost of the memory of one
+	// Histogram per stripe and of collecting becoming proportionally more
+	// expensive. A good value is usually runtime.GOMAXPROCS(0). Only use
+	// this if a benchmark or profile shows contention on the Histogram.
+	//
+	// Stripes is currently only supported for classic histograms. It is
+	// ignored if NativeHistogramBucketFactor is greater than one.
+	Stripes int
+
+	// InternLabelValues interns the label values of new metrics in a
+	// vector created with these options. See Opts.InternLabelValues.
+	InternLabelValues bool
+
+	// Clock, if not nil, provides the current time to the metric instead
+	// of the system clock. It is meant for tests, see Clock.
+	Clock Clock
+
 	// now is for testing purposes, by default it's time.Now.
 	now func() time.Time
 
@@ -549,11 +570,14 @@ func newHistogram(desc *Desc, opts HistogramOpts, labelValues ...string) Histogr
 	}
 
 	if opts.now == nil {
-		opts.now = time.Now
+		opts.now = nowFunc(opts.Clock)
 	}
 	if opts.afterFunc == nil {
 		opts.afterFunc = time.AfterFunc
 	}
+	if opts.Stripes > 1 && opts.NativeHistogramBucketFactor <= 1 {
+		return newStripedHistogram(desc, opts, labelValues...)
+	}
 
 	h := &histogram{
 		desc:                            desc,
@@ -1191,11 +1215,13 @@ func (v2) NewHistogramVec(opts HistogramVecOpts) *HistogramVec {
 		opts.VariableLabels,
 		opts.ConstLabels,
 	)
-	return &HistogramVec{
+	v := &HistogramVec{
 		MetricVec: NewMetricVec(desc, func(lvs ...string) Metric {
 			return newHistogram(desc, opts.HistogramOpts, lvs...)
 		}),
 	}
+	v.internLabelValues = opts.InternLabelValues
+	return v
 }
 
 // GetMetricWithLabelValues returns the Histogram for the given slice of label
@@ -1263,6 +1289,24 @@ func (v *HistogramVec) WithLabelValues(lvs ...string) Observer {
 	return h
 }
 
+// WithLabelValuesBatch works as GetMetricWithLabelValues for each of the
+// provided slices of label values, but creates all the Histograms at once, see
+// MetricVec.GetMetricWithLabelValuesBatch. Unlike WithLabelValues, it returns
+// an error rather than panicking, as the label values are usually generated,
+// e.g. to pre-create the Histograms of all combinations of label values at
+// startup.
+func (v *HistogramVec) WithLabelValuesBatch(lvss [][]string) ([]Observer, error) {
+	metrics, err := v.MetricVec.GetMetricWithLabelValuesBatch(lvss)
+	if err != nil {
+		return nil, err
+	}
+	observers := make([]Observer, len(metrics))
+	for i, m := range metrics {
+		observers[i] = m.(Observer)
+	}
+	return observers, nil
+}
+
 // With works as GetMetricWith but panics where GetMetricWithLabels would have
 // returned an error. Not returning an error allows shortcuts like
 //
@@ -0,0 +1,134 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"math"
+	"math/rand/v2"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+)
+
+// stripedHistogram is a classic Histogram that spreads its observations over
+// several histograms, the stripes, to avoid contention between goroutines
+// observing at the same time. See HistogramOpts.Stripes.
+type stripedHistogram struct {
+	selfCollector
+	desc *Desc
+
+	stripes     []*histogram
+	upperBounds []float64
+	labelPairs  []*dto.LabelPair
+}
+
+func newStripedHistogram(desc *Desc, opts HistogramOpts, labelValues ...string) *stripedHistogram {
+	stripes := opts.Stripes
+	opts.Stripes = 0
+	h := &stripedHistogram{
+		desc:    desc,
+		stripes: make([]*histogram, stripes),
+	}
+	for i := range h.stripes {
+		h.stripes[i] = newHistogram(desc, opts, labelValues...).(*histogram)
+	}
+	// All stripes have the same (validated) upper bounds and label pairs.
+	h.upperBounds = h.stripes[0].upperBounds
+	h.labelPairs = h.stripes[0].labelPairs
+	h.init(h) // Init self-collection.
+	return h
+}
+
+func (h *stripedHistogram) Desc() *Desc {
+	return h.desc
+}
+
+func (h *stripedHistogram) Observe(v float64) {
+	h.stripe().Observe(v)
+}
+
+func (h *stripedHistogram) ObserveWithExemplar(v float64, e Labels) {
+	h.stripe().ObserveWithExemplar(v, e)
+}
+
+// stripe returns a random stripe. Random selection is cheap and spreads
+// concurrent observations well without knowing the observing goroutine or P.
+func (h *stripedHistogram) stripe() *histogram {
+	return h.stripes[rand.IntN(len(h.stripes))]
+}
+
+// Write merges the stripes. Each observation is in exactly one stripe, so the
+// result is consistent, even though the stripes are not written at exactly
+// the same time. For each bucket, the most recent exemplar of all stripes is
+// used.
+func (h *stripedHistogram) Write(out *dto.Metric) error {
+	var (
+		count     uint64
+		sum       float64
+		cumCounts = make([]uint64, len(h.upperBounds))
+		exemplars = make([]*dto.Exemplar, len(h.upperBounds)+1)
+		stripeOut dto.Metric
+		his       = &dto.Histogram{Bucket: make([]*dto.Bucket, len(h.upperBounds))}
+	)
+	for i, s := range h.stripes {
+		if err := s.Write(&stripeOut); err != nil {
+			return err
+		}
+		stripeHis := stripeOut.GetHistogram()
+		if i == 0 {
+			// Classic histograms are never reset, so all stripes
+			// have the creation time as created timestamp.
+			his.CreatedTimestamp = stripeHis.GetCreatedTimestamp()
+		}
+		count += stripeHis.GetSampleCount()
+		sum += stripeHis.GetSampleSum()
+		for i, b := range stripeHis.GetBucket() {
+			// A stripe has an additional +Inf bucket if it has an
+			// exemplar for it.
+			if i < len(cumCounts) {
+				cumCounts[i] += b.GetCumulativeCount()
+			}
+			exemplars[i] = laterExemplar(exemplars[i], b.GetExemplar())
+		}
+	}
+
+	his.SampleCount = proto.Uint64(count)
+	his.SampleSum = proto.Float64(sum)
+	for i, upperBound := range h.upperBounds {
+		his.Bucket[i] = &dto.Bucket{
+			CumulativeCount: proto.Uint64(cumCounts[i]),
+			UpperBound:      proto.Float64(upperBound),
+			Exemplar:        exemplars[i],
+		}
+	}
+	if e := exemplars[len(h.upperBounds)]; e != nil {
+		his.Bucket = append(his.Bucket, &dto.Bucket{
+			CumulativeCount: proto.Uint64(count),
+			UpperBound:      proto.Float64(math.Inf(1)),
+			Exemplar:        e,
+		})
+	}
+	out.Histogram = his
+	out.Label = h.labelPairs
+	return nil
+}
+
+// laterExemplar returns the exemplar with the later timestamp, treating nil as
+// the earliest.
+func laterExemplar(a, b *dto.Exemplar) *dto.Exemplar {
+	if a == nil || (b != nil && b.GetTimestamp().AsTime().After(a.GetTimestamp().AsTime())) {
+		return b
+	}
+	return a
+}
@@ -19,6 +19,7 @@ import (
 	"reflect"
 	"runtime"
 	"sort"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -1198,6 +1199,78 @@ func TestGetLe(t *testing.T) {
 	}
 }
 
+func TestStripedHistogram(t *testing.T) {
+	var (
+		mtx sync.Mutex
+		now = time.Now()
+	)
+	tick := func() time.Time {
+		mtx.Lock()
+		defer mtx.Unlock()
+		now = now.Add(time.Second)
+		return now
+	}
+	opts := HistogramOpts{
+		Name:    "test",
+		Help:    "test help",
+		Buckets: []float64{1, 2, 3, 4},
+		now:     tick,
+	}
+	plain := NewHistogram(opts)
+	opts.Stripes = 4
+	striped := NewHistogram(opts)
+	if _, ok := striped.(*stripedHistogram); !ok {
+		t.Fatalf("got %T, want *stripedHistogram", striped)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(seed))
+			for j := 0; j < 1000; j++ {
+				// Integer values keep the sums exact regardless of
+				// the order of additions.
+				v := float64(r.Intn(6))
+				plain.Observe(v)
+				striped.Observe(v)
+			}
+		}(int64(i))
+	}
+	wg.Wait()
+	for _, v := range []float64{1.5, 1.6, 4.5} {
+		l := Labels{"v": strconv.FormatFloat(v, 'g', -1, 64)}
+		plain.(ExemplarObserver).ObserveWithExemplar(v, l)
+		striped.(ExemplarObserver).ObserveWithExemplar(v, l)
+	}
+
+	var want, got dto.Metric
+	if err := plain.Write(&want); err != nil {
+		t.Fatal(err)
+	}
+	if err := striped.Write(&got); err != nil {
+		t.Fatal(err)
+	}
+	// The exemplars of both histograms have different timestamps.
+	for _, m := range []*dto.Metric{&want, &got} {
+		m.Histogram.CreatedTimestamp = nil
+		for _, b := range m.Histogram.Bucket {
+			if b.Exemplar != nil {
+				b.Exemplar.Timestamp = nil
+			}
+		}
+	}
+	if !proto.Equal(&got, &want) {
+		t.Errorf("got %v, want %v", &got, &want)
+	}
+
+	opts.NativeHistogramBucketFactor = 1.1
+	if h := NewHistogram(opts); reflect.TypeOf(h) != reflect.TypeOf(&histogram{}) {
+		t.Errorf("got %T for a native histogram, want *histogram", h)
+	}
+}
+
 func TestHistogramCreatedTimestamp(t *testing.T) {
 	now := time.Now()
 
@@ -0,0 +1,261 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package influxdb provides a bridge to push Prometheus metrics to an InfluxDB
+// or Telegraf endpoint in the InfluxDB line protocol.
+package influxdb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const defaultInterval = 15 * time.Second
+
+// MeasurementFunc maps the name of a sample to the measurement and the field
+// the sample value is written to. The sample names of histograms and
+// summaries have the suffixes "_bucket", "_sum", and "_count" as in the text
+// exposition format.
+type MeasurementFunc func(name string) (measurement, field string)
+
+// MetricMeasurement is a MeasurementFunc that writes each sample to a
+// measurement named like the sample, with the field "value". It is the
+// default.
+func MetricMeasurement(name string) (measurement, field string) {
+	return name, "value"
+}
+
+// SingleMeasurement returns a MeasurementFunc that writes all samples to the
+// provided measurement, with the sample name as field, like the "prometheus"
+// measurement of Telegraf's Prometheus input.
+func SingleMeasurement(measurement string) MeasurementFunc {
+	return func(name string) (string, string) {
+		return measurement, name
+	}
+}
+
+// Config defines the InfluxDB bridge config.
+type Config struct {
+	// The URL of the write endpoint, including its parameters, e.g.
+	// "http://localhost:8086/api/v2/write?org=example&bucket=metrics" for
+	// InfluxDB 2 or "http://localhost:8186/write" for Telegraf's HTTP
+	// listener. Required.
+	URL string
+
+	// Additional headers sent with each request, e.g. "Authorization" with
+	// an InfluxDB token.
+	Header http.Header
+
+	// Maps sample names to measurements and fields. Defaults to
+	// MetricMeasurement.
+	Measurement MeasurementFunc
+
+	// The interval to use for pushing metrics. Defaults to 15 seconds.
+	Interval time.Duration
+
+	// The timeout for pushing metrics. Defaults to the interval.
+	Timeout time.Duration
+
+	// The Gatherer to use for metrics. Defaults to prometheus.DefaultGatherer.
+	Gatherer prometheus.Gatherer
+
+	// The HTTP client to push with. Defaults to http.DefaultClient.
+	Client *http.Client
+
+	// The logger that messages are written to. Defaults to no logging.
+	Logger Logger
+}
+
+// Logger is the minimal interface Bridge needs for logging. Note that
+// log.Logger from the standard library implements this interface, and it is
+// easy to implement by custom loggers, if they don't do so already anyway.
+type Logger interface {
+	Println(v ...any)
+}
+
+// Bridge pushes metrics to the configured InfluxDB endpoint.
+type Bridge struct {
+	url         string
+	header      http.Header
+	measurement MeasurementFunc
+	interval    time.Duration
+	timeout     time.Duration
+	client      *http.Client
+	logger      Logger
+
+	g prometheus.Gatherer
+}
+
+// NewBridge returns a pointer to a new Bridge struct.
+func NewBridge(c *Config) (*Bridge, error) {
+	if c.URL == "" {
+		return nil, errors.New("missing URL")
+	}
+	b := &Bridge{
+		url:         c.URL,
+		header:      c.Header,
+		measurement: c.Measurement,
+		interval:    c.Interval,
+		timeout:     c.Timeout,
+		client:      c.Client,
+		logger:      c.Logger,
+		g:           c.Gatherer,
+	}
+	if b.measurement == nil {
+		b.measurement = MetricMeasurement
+	}
+	if b.interval == 0 {
+		b.interval = defaultInterval
+	}
+	if b.timeout == 0 {
+		b.timeout = b.interval
+	}
+	if b.client == nil {
+		b.client = http.DefaultClient
+	}
+	if b.g == nil {
+		b.g = prometheus.DefaultGatherer
+	}
+	return b, nil
+}
+
+// Run starts the event loop that pushes Prometheus metrics to InfluxDB at the
+// configured interval.
+func (b *Bridge) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := b.Push(ctx); err != nil && b.logger != nil {
+				b.logger.Println("error pushing to InfluxDB:", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Push gathers the metrics and pushes them to the configured endpoint.
+func (b *Bridge) Push(ctx context.Context) error {
+	mfs, err := b.g.Gather()
+	if err != nil {
+		return fmt.Errorf("gathering metrics failed: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := writeMetrics(&buf, mfs, b.measurement, model.Now()); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.url, &buf)
+	if err != nil {
+		return err
+	}
+	for name, values := range b.header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status code %d while pushing to %s: %s", resp.StatusCode, b.url, body)
+	}
+	return nil
+}
+
+// writeMetrics writes the samples of the metric families in the line protocol,
+// with the labels as tags and nanosecond timestamps. Samples with NaN or
+// infinite values are skipped, as InfluxDB doesn't support them.
+func writeMetrics(w io.Writer, mfs []*dto.MetricFamily, measurement MeasurementFunc, now model.Time) error {
+	vec, err := expfmt.ExtractSamples(&expfmt.DecodeOptions{
+		Timestamp: now,
+	}, mfs...)
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	for _, s := range vec {
+		v := float64(s.Value)
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		m, field := measurement(string(s.Metric[model.MetricNameLabel]))
+		sb.Reset()
+		sb.WriteString(escape(m, ", "))
+
+		tags := make([]string, 0, len(s.Metric))
+		for name, value := range s.Metric {
+			if name == model.MetricNameLabel || value == "" {
+				continue
+			}
+			tags = append(tags, escape(string(name), ",= ")+"="+escape(string(value), ",= "))
+		}
+		sort.Strings(tags)
+		for _, tag := range tags {
+			sb.WriteByte(',')
+			sb.WriteString(tag)
+		}
+
+		sb.WriteByte(' ')
+		sb.WriteString(escape(field, ",= "))
+		sb.WriteByte('=')
+		sb.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+		sb.WriteByte(' ')
+		sb.WriteString(strconv.FormatInt(int64(s.Timestamp)*int64(time.Millisecond), 10))
+		sb.WriteByte('\n')
+		if _, err := io.WriteString(w, sb.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// escape escapes the characters in chars and backslashes with a backslash.
+// Newlines, which cannot be escaped, are replaced by spaces first.
+func escape(s, chars string) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	if !strings.ContainsAny(s, chars+`\`) {
+		return s
+	}
+	var sb strings.Builder
+	for _, r := range s {
+		if r == '\\' || strings.ContainsRune(chars, r) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,172 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package influxdb
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestEscape(t *testing.T) {
+	testCases := []struct {
+		in, chars, out string
+	}{
+		{in: "hello", chars: ",= ", out: "hello"},
+		{in: "a b,c=d", chars: ",= ", out: `a\ b\,c\=d`},
+		{in: "a=b", chars: ", ", out: "a=b"},
+		{in: `c:\dir`, chars: ",= ", out: `c:\\dir`},
+		{in: "two\nlines", chars: ",= ", out: `two\ lines`},
+	}
+	for _, tc := range testCases {
+		if got := escape(tc.in, tc.chars); got != tc.out {
+			t.Errorf("escape(%q, %q) = %q, want %q", tc.in, tc.chars, got, tc.out)
+		}
+	}
+}
+
+func testRegistry(t *testing.T) *prometheus.Registry {
+	t.Helper()
+	reg := prometheus.NewRegistry()
+
+	cv := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "requests_total",
+		Help: "Requests.",
+	}, []string{"code", "path"})
+	cv.WithLabelValues("200", "/a b").Add(3)
+	cv.WithLabelValues("500", "").Inc()
+	reg.MustRegister(cv)
+
+	h := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "latency_seconds",
+		Help:    "Latency.",
+		Buckets: []float64{0.5},
+	})
+	h.Observe(0.25)
+	reg.MustRegister(h)
+
+	g := prometheus.NewGauge(prometheus.GaugeOpts{Name: "broken", Help: "NaN."})
+	g.Set(math.NaN())
+	reg.MustRegister(g)
+	return reg
+}
+
+func TestWriteMetrics(t *testing.T) {
+	reg := testRegistry(t)
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name        string
+		measurement MeasurementFunc
+		want        string
+	}{
+		{
+			name:        "metric measurement",
+			measurement: MetricMeasurement,
+			want: `latency_seconds_bucket,le=+Inf value=1 1000000
+latency_seconds_bucket,le=0.5 value=1 1000000
+latency_seconds_count value=1 1000000
+latency_seconds_sum value=0.25 1000000
+requests_total,code=200,path=/a\ b value=3 1000000
+requests_total,code=500 value=1 1000000
+`,
+		},
+		{
+			name:        "single measurement",
+			measurement: SingleMeasurement("prom metrics"),
+			want: `prom\ metrics latency_seconds_count=1 1000000
+prom\ metrics latency_seconds_sum=0.25 1000000
+prom\ metrics,code=200,path=/a\ b requests_total=3 1000000
+prom\ metrics,code=500 requests_total=1 1000000
+prom\ metrics,le=+Inf latency_seconds_bucket=1 1000000
+prom\ metrics,le=0.5 latency_seconds_bucket=1 1000000
+`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeMetrics(&buf, mfs, tc.measurement, 1); err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+			// The order of samples within a family is not defined.
+			sort.Strings(lines)
+			if got := strings.Join(lines, "\n") + "\n"; got != tc.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPush(t *testing.T) {
+	var (
+		gotBody   string
+		gotHeader http.Header
+		status    = http.StatusNoContent
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBody, gotHeader = string(body), r.Header
+		w.WriteHeader(status)
+		if status != http.StatusNoContent {
+			io.WriteString(w, "bucket not found")
+		}
+	}))
+	defer srv.Close()
+
+	b, err := NewBridge(&Config{
+		URL:      srv.URL + "/api/v2/write?bucket=metrics",
+		Header:   http.Header{"Authorization": []string{"Token secret"}},
+		Gatherer: testRegistry(t),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Push(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := gotHeader.Get("Authorization"), "Token secret"; got != want {
+		t.Errorf("got Authorization %q, want %q", got, want)
+	}
+	if got, want := gotHeader.Get("Content-Type"), "text/plain; charset=utf-8"; got != want {
+		t.Errorf("got Content-Type %q, want %q", got, want)
+	}
+	if !strings.Contains(gotBody, "requests_total,code=200,path=/a\\ b value=3 ") {
+		t.Errorf("unexpected body:\n%s", gotBody)
+	}
+
+	status = http.StatusNotFound
+	err = b.Push(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "404") || !strings.Contains(err.Error(), "bucket not found") {
+		t.Errorf("got error %v, want status 404 with response body", err)
+	}
+}
+
+func TestNewBridgeMissingURL(t *testing.T) {
+	if _, err := NewBridge(&Config{}); err == nil {
+		t.Error("expected error for missing URL")
+	}
+}
@@ -29,6 +29,7 @@ type GoCollectorOptions struct {
 	DisableMemStatsLikeMetrics bool
 	RuntimeMetricSumForHist    map[string]string
 	RuntimeMetricRules         []GoCollectorRule
+	EnableGCCPUPressureMetrics bool
 }
 
 var GoCollectorDefaultRuntimeMetrics = regexp.MustCompile(`/gc/gogc:percent|/gc/gomemlimit:bytes|/sched/gomaxprocs:threads`)
@@ -147,6 +147,8 @@ func makeInconsistentCardinalityError(fqName string, labels, labelValues []strin
 
 func validateValuesInLabels(labels Labels, expectedNumberOfValues int) error {
 	if len(labels) != expectedNumberOfValues {
+		// The call below makes labels escape, copy them to avoid that.
+		labels := copyLabels(labels)
 		return fmt.Errorf(
 			"%w: expected %d label values but got %d in %#v",
 			errInconsistentCardinality, expectedNumberOfValues,
@@ -163,6 +165,16 @@ func validateValuesInLabels(labels Labels, expectedNumberOfValues int) error {
 	return nil
 }
 
+// copyLabels returns a copy of labels. Unlike maps.Clone, it doesn't make
+// labels escape.
+func copyLabels(labels Labels) Labels {
+	c := make(Labels, len(labels))
+	for name, val := range labels {
+		c[name] = val
+	}
+	return c
+}
+
 func validateLabelValues(vals []string, expectedNumberOfValues int) error {
 	if len(vals) != expectedNumberOfValues {
 		// The call below makes vals escape, copy them to avoid that.
@@ -183,6 +195,77 @@ func validateLabelValues(vals []string, expectedNumberOfValues int) error {
 	return nil
 }
 
+// checkLabelName reports whether l is a label name that is valid according to
+// model.NameValidationScheme and not reserved.
 func checkLabelName(l string) bool {
-	return model.LabelName(l).IsValid() && !strings.HasPrefix(l, reservedLabelPrefix)
+	return isValidName(l, false) && !strings.HasPrefix(l, reservedLabelPrefix)
+}
+
+// checkMetricName reports whether fqName is a metric name that is valid
+// according to model.NameValidationScheme.
+func checkMetricName(fqName string) bool {
+	return isValidName(fqName, true)
+}
+
+// isValidName is the fast path of model.IsValidMetricName (if colons is true)
+// and model.LabelName.IsValid, as validating names shows up in profiles of
+// programs creating const metrics for each scrape. It validates the names of
+// the known validation schemes directly, scanning the bytes of legacy names
+// instead of decoding runes.
+func isValidName(name string, colons bool) bool {
+	switch model.NameValidationScheme {
+	case model.UTF8Validation:
+		return len(name) > 0 && utf8.ValidString(name)
+	case model.LegacyValidation:
+		return isLegacyName(name, colons)
+	}
+	// Leave other schemes (including an unset one) to model.
+	if colons {
+		return model.IsValidMetricName(model.LabelValue(name))
+	}
+	return model.LabelName(name).IsValid()
+}
+
+// Classes of the bytes of legacy names, see isLegacyName.
+const (
+	legacyNameStart = 1 << iota // Letters and underscores.
+	legacyNameDigit
+	legacyNameColon
+)
+
+var legacyNameBytes = func() (t [256]uint8) {
+	for b := 'a'; b <= 'z'; b++ {
+		t[b] = legacyNameStart
+		t[b-'a'+'A'] = legacyNameStart
+	}
+	t['_'] = legacyNameStart
+	for b := '0'; b <= '9'; b++ {
+		t[b] = legacyNameDigit
+	}
+	t[':'] = legacyNameColon
+	return t
+}()
+
+// isLegacyName reports whether name is a valid name according to the legacy
+// validation scheme, i.e. it is not empty and consists of ASCII letters,
+// underscores, colons (only if colons is true), and digits (except at the
+// start).
+func isLegacyName(name string, colons bool) bool {
+	if len(name) == 0 {
+		return false
+	}
+	valid := uint8(legacyNameStart)
+	if colons {
+		valid |= legacyNameColon
+	}
+	if legacyNameBytes[name[0]]&valid == 0 {
+		return false
+	}
+	valid |= legacyNameDigit
+	for i := 1; i < len(name); i++ {
+		if legacyNameBytes[name[i]]&valid == 0 {
+			return false
+		}
+	}
+	return true
 }
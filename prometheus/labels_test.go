@@ -0,0 +1,44 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/common/model"
+)
+
+func TestCheckNames(t *testing.T) {
+	defer func(scheme model.ValidationScheme) { model.NameValidationScheme = scheme }(model.NameValidationScheme)
+
+	names := []string{
+		"", "a", "Z", "_", ":", "0", "9a", "a9", "abc_DEF_123", "a:b", ":a", "a-b",
+		"a.b", "__name__", "_a", "Ü", "aÜ", "a b", "\xff", "a\xff", "a\x00",
+		strings.Repeat("long_name_", 20),
+	}
+	for _, scheme := range []model.ValidationScheme{model.UTF8Validation, model.LegacyValidation} {
+		model.NameValidationScheme = scheme
+		for _, name := range names {
+			want := model.LabelName(name).IsValid() && !strings.HasPrefix(name, reservedLabelPrefix)
+			if got := checkLabelName(name); got != want {
+				t.Errorf("%s: checkLabelName(%q) = %t, want %t", scheme, name, got, want)
+			}
+			want = model.IsValidMetricName(model.LabelValue(name))
+			if got := checkMetricName(name); got != want {
+				t.Errorf("%s: checkMetricName(%q) = %t, want %t", scheme, name, got, want)
+			}
+		}
+	}
+}
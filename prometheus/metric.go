@@ -93,6 +93,22 @@ type Opts struct {
 	// https://prometheus.io/docs/instrumenting/writing_exporters/#target-labels-not-static-scraped-labels
 	ConstLabels Labels
 
+	// If InternLabelValues is true, the label values of new metrics in
+	// a vector created with these options (e.g. a CounterVec) are
+	// interned, i.e. equal label values share their memory across all
+	// metrics and vectors with interning enabled instead of each metric
+	// holding its own copy. This reduces the heap size if the same
+	// dynamic label values (e.g. request paths or tenant IDs built anew
+	// for each request) recur in many metrics, at the cost of a lookup
+	// whenever a metric is created. Interned values are freed once no
+	// metric uses them anymore. It is ignored for metrics that are not
+	// part of a vector.
+	InternLabelValues bool
+
+	// Clock, if not nil, provides the current time to the metric instead
+	// of the system clock. It is meant for tests, see Clock.
+	Clock Clock
+
 	// now is for testing purposes, by default it's time.Now.
 	now func() time.Time
 }
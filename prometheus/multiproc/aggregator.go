@@ -0,0 +1,288 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package multiproc aggregates the metrics of multiple processes on the same
+// host, e.g. of the workers of a supervisor or pre-fork server, into one set
+// of metrics exposed by the parent process. Each worker runs a Reporter that
+// sends snapshots of its metrics over a Unix socket to the Aggregator of the
+// parent, which merges them, either with a label identifying the process or
+// summed across processes.
+//
+// This is a simpler alternative to sharing metric values via memory-mapped
+// files, at the cost of the metrics being as old as the last snapshot.
+package multiproc
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/aggregate"
+	"github.com/prometheus/client_golang/prometheus/internal"
+)
+
+const defaultProcessLabel = "process"
+
+// AggregatorOpts defines the behavior of an Aggregator created with
+// NewAggregator.
+type AggregatorOpts struct {
+	// ProcessLabel is the name of the label identifying the process a
+	// metric was reported by. Defaults to "process".
+	ProcessLabel string
+	// If true, the metrics of all processes are aggregated by removing the
+	// process label, as with an aggregate.Gatherer. Counters, histograms,
+	// and summaries are summed (dropping the quantiles of summaries), while
+	// gauges and untyped metrics are aggregated with GaugeOp.
+	Aggregate bool
+	// GaugeOp is the aggregation of gauges and untyped metrics if
+	// Aggregate is true. Defaults to aggregate.Sum.
+	GaugeOp aggregate.Op
+	// Retain is the time the last snapshot of a process is kept after the
+	// process disconnected. By default, the metrics of a process are
+	// dropped as soon as it disconnects. Retaining them avoids aggregated
+	// counters going down when a worker exits.
+	Retain time.Duration
+}
+
+// Aggregator receives snapshots of the metrics of processes from their
+// Reporters, and is a prometheus.Gatherer returning the merged metrics. Serve
+// its metrics with e.g. promhttp.HandlerFor, or together with the parent's own
+// metrics with a prometheus.Gatherers.
+//
+// Each metric reported by a process gets the process label with the ID of the
+// process, which must not already be a label of the metric. Metric families
+// with the same name must have the same type in all processes; the families
+// conflicting with the first process (ordered by ID) are dropped and reported
+// as an error upon gathering. If several processes connect with the same ID,
+// their snapshots overwrite each other.
+type Aggregator struct {
+	label   string
+	retain  time.Duration
+	gatherG prometheus.Gatherer
+
+	mtx   sync.Mutex
+	procs map[string]*process
+}
+
+type process struct {
+	mfs          []*dto.MetricFamily
+	conns        int
+	disconnected time.Time
+}
+
+// NewAggregator returns a new Aggregator.
+func NewAggregator(opts AggregatorOpts) *Aggregator {
+	a := &Aggregator{
+		label:  opts.ProcessLabel,
+		retain: opts.Retain,
+		procs:  map[string]*process{},
+	}
+	if a.label == "" {
+		a.label = defaultProcessLabel
+	}
+	a.gatherG = prometheus.GathererFunc(a.gatherPerProcess)
+	if opts.Aggregate {
+		a.gatherG = aggregate.NewGatherer(a.gatherG, aggregate.Rule{Without: []string{a.label}, Op: opts.GaugeOp})
+	}
+	return a
+}
+
+// ListenAndServe listens on the Unix socket at the provided path and serves
+// Reporters until ctx is done. A stale socket file at the path, e.g. of a
+// previous run, is removed first.
+func (a *Aggregator) ListenAndServe(ctx context.Context, path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+	err = a.Serve(l)
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// Serve accepts connections of Reporters on the provided listener until it is
+// closed, and returns the error of accepting.
+func (a *Aggregator) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go a.handle(conn)
+	}
+}
+
+// handle receives the snapshots of a Reporter until the connection is closed.
+func (a *Aggregator) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	id, err := readHello(r)
+	if err != nil {
+		return
+	}
+	a.mtx.Lock()
+	p, ok := a.procs[id]
+	if !ok {
+		p = &process{}
+		a.procs[id] = p
+	}
+	p.conns++
+	a.mtx.Unlock()
+
+	defer func() {
+		a.mtx.Lock()
+		defer a.mtx.Unlock()
+		p.conns--
+		if p.conns > 0 {
+			return
+		}
+		if a.retain <= 0 {
+			delete(a.procs, id)
+			return
+		}
+		p.disconnected = time.Now()
+	}()
+	for {
+		mfs, err := readSnapshot(r)
+		if err != nil {
+			return
+		}
+		a.mtx.Lock()
+		p.mfs = mfs
+		a.mtx.Unlock()
+	}
+}
+
+// Gather implements prometheus.Gatherer.
+func (a *Aggregator) Gather() ([]*dto.MetricFamily, error) {
+	return a.gatherG.Gather()
+}
+
+// gatherPerProcess returns the metrics of all processes with the process
+// label.
+func (a *Aggregator) gatherPerProcess() ([]*dto.MetricFamily, error) {
+	a.mtx.Lock()
+	ids := make([]string, 0, len(a.procs))
+	snapshots := make(map[string][]*dto.MetricFamily, len(a.procs))
+	for id, p := range a.procs {
+		if p.conns == 0 && time.Since(p.disconnected) > a.retain {
+			delete(a.procs, id)
+			continue
+		}
+		ids = append(ids, id)
+		snapshots[id] = p.mfs
+	}
+	a.mtx.Unlock()
+	sort.Strings(ids)
+
+	var (
+		errs   prometheus.MultiError
+		byName = map[string]*dto.MetricFamily{}
+	)
+	for _, id := range ids {
+		for _, mf := range snapshots[id] {
+			merged, ok := byName[mf.GetName()]
+			if !ok {
+				merged = &dto.MetricFamily{Name: mf.Name, Help: mf.Help, Type: mf.Type, Unit: mf.Unit}
+				byName[mf.GetName()] = merged
+			} else if merged.GetType() != mf.GetType() {
+				errs = append(errs, fmt.Errorf("metric family %s of process %s has type %s, other processes have %s", mf.GetName(), id, mf.GetType(), merged.GetType()))
+				continue
+			}
+			for _, m := range mf.GetMetric() {
+				m, err := a.withProcessLabel(m, id)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("metric family %s of process %s: %w", mf.GetName(), id, err))
+					continue
+				}
+				merged.Metric = append(merged.Metric, m)
+			}
+		}
+	}
+	return internal.NormalizeMetricFamilies(byName), errs.MaybeUnwrap()
+}
+
+// withProcessLabel returns a copy of m with the process label.
+func (a *Aggregator) withProcessLabel(m *dto.Metric, id string) (*dto.Metric, error) {
+	for _, lp := range m.GetLabel() {
+		if lp.GetName() == a.label {
+			return nil, fmt.Errorf("metric already has the label %q", a.label)
+		}
+	}
+	// The snapshot is shared with other gathers, so don't modify it.
+	c := &dto.Metric{
+		Label:       append(append(make([]*dto.LabelPair, 0, len(m.Label)+1), m.Label...), &dto.LabelPair{Name: proto.String(a.label), Value: proto.String(id)}),
+		Gauge:       m.Gauge,
+		Counter:     m.Counter,
+		Summary:     m.Summary,
+		Untyped:     m.Untyped,
+		Histogram:   m.Histogram,
+		TimestampMs: m.TimestampMs,
+	}
+	sort.Sort(internal.LabelPairSorter(c.Label))
+	return c, nil
+}
+
+// readHello reads the ID a Reporter sends upon connecting.
+func readHello(r *bufio.Reader) (string, error) {
+	b, err := readFrame(r)
+	if err != nil {
+		return "", err
+	}
+	if len(b) == 0 {
+		return "", errors.New("empty process ID")
+	}
+	return string(b), nil
+}
+
+// readSnapshot reads a snapshot of metric families, which is sent as the
+// number of families followed by the families, each encoded as a frame.
+func readSnapshot(r *bufio.Reader) ([]*dto.MetricFamily, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	mfs := make([]*dto.MetricFamily, 0, min(n, 1024))
+	for range n {
+		b, err := readFrame(r)
+		if err != nil {
+			return nil, err
+		}
+		mf := &dto.MetricFamily{}
+		if err := proto.Unmarshal(b, mf); err != nil {
+			return nil, err
+		}
+		mfs = append(mfs, mf)
+	}
+	return mfs, nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package multiproc
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// worker returns a registry with a counter and a gauge, as registered by
+// each worker process.
+func worker(requests, connections float64) *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	c := prometheus.NewCounter(prometheus.CounterOpts{Name: "requests_total", Help: "Requests handled."})
+	c.Add(requests)
+	g := prometheus.NewGauge(prometheus.GaugeOpts{Name: "connections", Help: "Open connections."})
+	g.Set(connections)
+	reg.MustRegister(c, g)
+	return reg
+}
+
+// startAggregator starts an Aggregator listening on a socket in a temporary
+// directory and returns the socket path.
+func startAggregator(t *testing.T, a *Aggregator) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "metrics.sock")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- a.ListenAndServe(ctx, path) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+	})
+	return path
+}
+
+// startReporter runs a Reporter until the returned function is called.
+func startReporter(t *testing.T, reg *prometheus.Registry, path, id string) (stop func()) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	r := NewReporter(reg, ReporterOpts{Path: path, ID: id, Interval: 10 * time.Millisecond})
+	go func() {
+		r.Run(ctx)
+		close(done)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func waitForMetrics(t *testing.T, g prometheus.Gatherer, expected string) {
+	t.Helper()
+	var err error
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		if err = testutil.GatherAndCompare(g, strings.NewReader(expected)); err == nil {
+			return
+		}
+	}
+	t.Fatal(err)
+}
+
+func TestAggregatorPerProcess(t *testing.T) {
+	a := NewAggregator(AggregatorOpts{})
+	path := startAggregator(t, a)
+	stop1 := startReporter(t, worker(3, 1), path, "w1")
+	stop2 := startReporter(t, worker(4, 5), path, "w2")
+	defer stop2()
+
+	waitForMetrics(t, a, `# HELP connections Open connections.
+# TYPE connections gauge
+connections{process="w1"} 1
+connections{process="w2"} 5
+# HELP requests_total Requests handled.
+# TYPE requests_total counter
+requests_total{process="w1"} 3
+requests_total{process="w2"} 4
+`)
+
+	// The metrics of a disconnected process are dropped.
+	stop1()
+	waitForMetrics(t, a, `# HELP connections Open connections.
+# TYPE connections gauge
+connections{process="w2"} 5
+# HELP requests_total Requests handled.
+# TYPE requests_total counter
+requests_total{process="w2"} 4
+`)
+}
+
+func TestAggregatorAggregate(t *testing.T) {
+	a := NewAggregator(AggregatorOpts{Aggregate: true, Retain: time.Hour})
+	path := startAggregator(t, a)
+	stop1 := startReporter(t, worker(3, 1), path, "w1")
+	stop2 := startReporter(t, worker(4, 5), path, "w2")
+	defer stop2()
+
+	expected := `# HELP connections Open connections.
+# TYPE connections gauge
+connections 6
+# HELP requests_total Requests handled.
+# TYPE requests_total counter
+requests_total 7
+`
+	waitForMetrics(t, a, expected)
+
+	// The metrics of a disconnected process are retained.
+	stop1()
+	time.Sleep(50 * time.Millisecond)
+	if err := testutil.GatherAndCompare(a, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAggregatorConflicts(t *testing.T) {
+	a := NewAggregator(AggregatorOpts{})
+	path := startAggregator(t, a)
+
+	other := prometheus.NewRegistry()
+	other.MustRegister(prometheus.NewGauge(prometheus.GaugeOpts{Name: "requests_total", Help: "Requests handled."}))
+	defer startReporter(t, worker(3, 1), path, "w1")()
+	defer startReporter(t, other, path, "w2")()
+
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		mfs, err := a.Gather()
+		if err != nil {
+			if !strings.Contains(err.Error(), "requests_total of process w2 has type GAUGE") {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if len(mfs) != 2 {
+				t.Errorf("got %d metric families, want 2", len(mfs))
+			}
+			return
+		}
+	}
+	t.Fatal("conflict not reported")
+}
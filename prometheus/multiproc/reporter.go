@@ -0,0 +1,188 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package multiproc
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	defaultReportInterval = 5 * time.Second
+	// maxFrameSize limits the size of a single metric family.
+	maxFrameSize = 64 << 20
+)
+
+// ReporterOpts defines the behavior of a Reporter created with NewReporter.
+type ReporterOpts struct {
+	// Path is the path of the Unix socket of the Aggregator. Mandatory.
+	Path string
+	// ID identifies the process, and is the value of the process label of
+	// its metrics. Defaults to the PID.
+	ID string
+	// Interval is the interval of sending snapshots. Defaults to 5s.
+	Interval time.Duration
+	// ErrorHandler, if set, is called with every error of connecting to
+	// the Aggregator, gathering, or sending.
+	ErrorHandler func(error)
+}
+
+// Reporter sends snapshots of the metrics of a Gatherer to an Aggregator,
+// typically in a worker process. Use NewReporter to create one and Run to
+// start reporting.
+type Reporter struct {
+	g           prometheus.Gatherer
+	path, id    string
+	interval    time.Duration
+	handleError func(error)
+}
+
+// NewReporter returns a Reporter for the provided Gatherer.
+func NewReporter(g prometheus.Gatherer, opts ReporterOpts) *Reporter {
+	r := &Reporter{
+		g:           g,
+		path:        opts.Path,
+		id:          opts.ID,
+		interval:    opts.Interval,
+		handleError: opts.ErrorHandler,
+	}
+	if r.id == "" {
+		r.id = strconv.Itoa(os.Getpid())
+	}
+	if r.interval <= 0 {
+		r.interval = defaultReportInterval
+	}
+	return r
+}
+
+// Run connects to the Aggregator and sends a snapshot right away and then
+// every Interval until ctx is done, reconnecting as needed. Before returning,
+// it sends a final snapshot, so that the Aggregator has the final values if it
+// retains the metrics of exited processes.
+func (r *Reporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	var conn net.Conn
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+	for {
+		done := ctx.Err() != nil
+		if conn == nil {
+			var err error
+			if conn, err = r.connect(ctx); err != nil {
+				r.error(err)
+			}
+		}
+		if conn != nil {
+			if err := r.send(conn); err != nil {
+				r.error(err)
+				conn.Close()
+				conn = nil
+			}
+		}
+		if done {
+			return
+		}
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Reporter) connect(ctx context.Context) (net.Conn, error) {
+	var d net.Dialer
+	if ctx.Err() != nil {
+		// Connect for the final snapshot anyway.
+		ctx = context.WithoutCancel(ctx)
+	}
+	conn, err := d.DialContext(ctx, "unix", r.path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.Write(appendFrame(nil, []byte(r.id))); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// send gathers and sends a snapshot. Gathering errors are reported, but the
+// gathered metrics are sent regardless.
+func (r *Reporter) send(conn net.Conn) error {
+	mfs, err := r.g.Gather()
+	if err != nil {
+		r.error(err)
+	}
+	if err := conn.SetWriteDeadline(time.Now().Add(r.interval)); err != nil {
+		return err
+	}
+	return writeSnapshot(conn, mfs)
+}
+
+func (r *Reporter) error(err error) {
+	if r.handleError != nil {
+		r.handleError(err)
+	}
+}
+
+// writeSnapshot writes the number of metric families followed by the families,
+// each encoded as a frame.
+func writeSnapshot(w io.Writer, mfs []*dto.MetricFamily) error {
+	buf := binary.AppendUvarint(nil, uint64(len(mfs)))
+	for _, mf := range mfs {
+		b, err := proto.Marshal(mf)
+		if err != nil {
+			return err
+		}
+		buf = appendFrame(buf, b)
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// appendFrame appends b prefixed with its length as uvarint to buf.
+func appendFrame(buf, b []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(b)))
+	return append(buf, b...)
+}
+
+// readFrame reads a frame written by appendFrame.
+func readFrame(r *bufio.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if n > maxFrameSize {
+		return nil, fmt.Errorf("frame of %d bytes exceeds the maximum of %d bytes", n, maxFrameSize)
+	}
+	b := make([]byte, n)
+	_, err = io.ReadFull(r, b)
+	return b, err
+}
@@ -0,0 +1,289 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package otlp provides a bridge to push Prometheus metrics to an OpenTelemetry
+// Protocol (OTLP) endpoint, e.g. an OpenTelemetry Collector, without using the
+// OpenTelemetry SDK.
+//
+// The bridge uses OTLP/HTTP with the JSON encoding, which all OTLP/HTTP
+// receivers support. OTLP/gRPC and the binary protobuf encoding would require
+// the OTLP protobuf definitions and a gRPC implementation as dependencies and
+// are thus not supported.
+//
+// Metrics are converted with cumulative temporality, or with delta temporality
+// if Config.DeltaTemporality is set: counters become monotonic sums, gauges and untyped metrics become gauges, histograms with native
+// buckets become exponential histograms, other histograms become explicit
+// bucket histograms, and summaries become summaries. Labels become data point
+// attributes. The created timestamps of counters, histograms, and summaries
+// are used as start time, or the time the Bridge was created if they are
+// missing.
+//
+// Programs that use the OpenTelemetry SDK don't need this bridge: The
+// producer in go.opentelemetry.io/contrib/bridges/prometheus exposes a
+// prometheus.Gatherer as a metric.Producer, which lets a PeriodicReader
+// include client_golang metrics in the SDK's pipeline. It is maintained with
+// the SDK rather than here, so that client_golang doesn't depend on the SDK.
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/delta"
+)
+
+const (
+	defaultInterval   = 15 * time.Second
+	defaultMaxRetries = 5
+	defaultMinBackoff = time.Second
+	defaultMaxBackoff = 30 * time.Second
+)
+
+// Config defines the OTLP bridge config.
+type Config struct {
+	// The URL of the OTLP/HTTP metrics endpoint, e.g.
+	// "http://localhost:4318/v1/metrics". Required.
+	URL string
+
+	// Additional headers sent with each request, e.g. for authentication.
+	Header http.Header
+
+	// The resource attributes identifying the pushing process, e.g.
+	// "service.name". Defaults to no attributes.
+	ResourceAttributes map[string]string
+
+	// The interval to use for pushing metrics. Defaults to 15 seconds.
+	Interval time.Duration
+
+	// The timeout for a single push attempt. Defaults to the interval.
+	Timeout time.Duration
+
+	// The maximum number of retries of a failed push. Pushes are retried
+	// after network errors and the status codes 429, 502, 503, and 504,
+	// which the OTLP specification declares retryable. Defaults to 5; a
+	// negative value disables retries.
+	MaxRetries int
+
+	// The backoff before the first retry, which is doubled for each further
+	// retry up to MaxBackoff. A Retry-After header in the response takes
+	// precedence. Defaults to 1 and 30 seconds, respectively.
+	MinBackoff, MaxBackoff time.Duration
+
+	// The Gatherer to use for metrics. Defaults to prometheus.DefaultGatherer.
+	Gatherer prometheus.Gatherer
+
+	// If true, counters and histograms are pushed with delta temporality,
+	// i.e. as their increase since the last successful push, for backends
+	// that require delta semantics. See package delta for the details of
+	// the conversion. Summaries are pushed as they are either way, as
+	// OTLP summaries have no temporality.
+	DeltaTemporality bool
+
+	// The HTTP client to push with. Defaults to http.DefaultClient.
+	Client *http.Client
+
+	// The logger that messages are written to. Defaults to no logging.
+	Logger Logger
+}
+
+// Logger is the minimal interface Bridge needs for logging. Note that
+// log.Logger from the standard library implements this interface, and it is
+// easy to implement by custom loggers, if they don't do so already anyway.
+type Logger interface {
+	Println(v ...any)
+}
+
+// Bridge pushes metrics to the configured OTLP endpoint.
+type Bridge struct {
+	url        string
+	header     http.Header
+	attributes map[string]string
+	interval   time.Duration
+	timeout    time.Duration
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	client     *http.Client
+	logger     Logger
+	start      time.Time
+	delta      *delta.Converter
+
+	g prometheus.Gatherer
+}
+
+// NewBridge returns a pointer to a new Bridge struct.
+func NewBridge(c *Config) (*Bridge, error) {
+	if c.URL == "" {
+		return nil, errors.New("missing URL")
+	}
+	b := &Bridge{
+		url:        c.URL,
+		header:     c.Header,
+		attributes: c.ResourceAttributes,
+		interval:   c.Interval,
+		timeout:    c.Timeout,
+		maxRetries: c.MaxRetries,
+		minBackoff: c.MinBackoff,
+		maxBackoff: c.MaxBackoff,
+		client:     c.Client,
+		logger:     c.Logger,
+		start:      time.Now(),
+		g:          c.Gatherer,
+	}
+	if b.g == nil {
+		b.g = prometheus.DefaultGatherer
+	}
+	if c.DeltaTemporality {
+		b.delta = delta.NewConverter()
+	}
+	if b.client == nil {
+		b.client = http.DefaultClient
+	}
+	if b.interval == 0 {
+		b.interval = defaultInterval
+	}
+	if b.timeout == 0 {
+		b.timeout = b.interval
+	}
+	switch {
+	case b.maxRetries == 0:
+		b.maxRetries = defaultMaxRetries
+	case b.maxRetries < 0:
+		b.maxRetries = 0
+	}
+	if b.minBackoff == 0 {
+		b.minBackoff = defaultMinBackoff
+	}
+	if b.maxBackoff == 0 {
+		b.maxBackoff = defaultMaxBackoff
+	}
+	return b, nil
+}
+
+// Run starts the event loop that pushes Prometheus metrics to the OTLP
+// endpoint at the configured interval.
+func (b *Bridge) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := b.Push(ctx); err != nil && b.logger != nil {
+				b.logger.Println("error pushing to OTLP endpoint:", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Push gathers the metrics and pushes them to the configured OTLP endpoint,
+// retrying as configured.
+func (b *Bridge) Push(ctx context.Context) error {
+	mfs, err := b.g.Gather()
+	if err != nil {
+		return fmt.Errorf("gathering metrics failed: %w", err)
+	}
+	c := converter{start: b.start, now: time.Now(), temporality: aggregationTemporalityCumulative}
+	commit := func() {}
+	if b.delta != nil {
+		mfs, commit = b.delta.Convert(mfs, c.now)
+		c.temporality = aggregationTemporalityDelta
+	}
+	body, err := json.Marshal(c.request(mfs, b.attributes))
+	if err != nil {
+		return err
+	}
+
+	backoff := b.minBackoff
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := b.send(ctx, body)
+		if err == nil {
+			commit()
+			return nil
+		}
+		var permanent *permanentError
+		if errors.As(err, &permanent) || attempt >= b.maxRetries {
+			return err
+		}
+		if b.logger != nil {
+			b.logger.Println("retrying failed push to OTLP endpoint:", err)
+		}
+
+		wait := backoff
+		if retryAfter > 0 {
+			wait = retryAfter
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w (last error: %w)", ctx.Err(), err)
+		case <-timer.C:
+		}
+		backoff = min(2*backoff, b.maxBackoff)
+	}
+}
+
+// permanentError is an error a push must not be retried for.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// send sends one push request and returns the delay requested by a
+// Retry-After header, if any.
+func (b *Bridge) send(ctx context.Context, body []byte) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.url, bytes.NewReader(body))
+	if err != nil {
+		return 0, &permanentError{err: err}
+	}
+	for name, values := range b.header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+	if resp.StatusCode/100 == 2 {
+		return 0, nil
+	}
+
+	err = fmt.Errorf("unexpected status code %d while pushing to %s: %s", resp.StatusCode, b.url, respBody)
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		var retryAfter time.Duration
+		if s, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil && s > 0 {
+			retryAfter = time.Duration(s) * time.Second
+		}
+		return retryAfter, err
+	}
+	return 0, &permanentError{err: err}
+}
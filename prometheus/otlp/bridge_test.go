@@ -0,0 +1,218 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestPush(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "requests_total", Help: "Requests."}, []string{"code"})
+	latency := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "latency_seconds", Help: "Latency.", Buckets: []float64{0.1, 1}})
+	temp := prometheus.NewGauge(prometheus.GaugeOpts{Name: "temperature_celsius", Help: "Temperature."})
+	reg.MustRegister(requests, latency, temp)
+	requests.WithLabelValues("200").Add(3)
+	latency.Observe(0.05)
+	latency.Observe(0.5)
+	latency.Observe(5)
+	temp.Set(21.5)
+
+	var (
+		mu       sync.Mutex
+		attempts int
+		body     map[string]any
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("got Authorization header %q", got)
+		}
+		b, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(b, &body); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+
+	b, err := NewBridge(&Config{
+		URL:                srv.URL,
+		Header:             http.Header{"Authorization": {"Bearer token"}},
+		ResourceAttributes: map[string]string{"service.name": "test"},
+		Gatherer:           reg,
+		MinBackoff:         time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Push(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 2 {
+		t.Errorf("got %d attempts, want 2", attempts)
+	}
+
+	rm := body["resourceMetrics"].([]any)[0].(map[string]any)
+	wantResource := map[string]any{"attributes": []any{map[string]any{"key": "service.name", "value": map[string]any{"stringValue": "test"}}}}
+	if !reflect.DeepEqual(rm["resource"], wantResource) {
+		t.Errorf("got resource %v, want %v", rm["resource"], wantResource)
+	}
+	metrics := map[string]map[string]any{}
+	for _, m := range rm["scopeMetrics"].([]any)[0].(map[string]any)["metrics"].([]any) {
+		m := m.(map[string]any)
+		metrics[m["name"].(string)] = m
+	}
+
+	s := metrics["requests_total"]["sum"].(map[string]any)
+	if s["aggregationTemporality"] != 2.0 || s["isMonotonic"] != true {
+		t.Errorf("got sum %v, want cumulative and monotonic", s)
+	}
+	dp := s["dataPoints"].([]any)[0].(map[string]any)
+	if dp["asDouble"] != 3.0 || dp["startTimeUnixNano"] == nil {
+		t.Errorf("got counter data point %v", dp)
+	}
+
+	dp = metrics["latency_seconds"]["histogram"].(map[string]any)["dataPoints"].([]any)[0].(map[string]any)
+	if got, want := dp["bucketCounts"], []any{"1", "1", "1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got bucket counts %v, want %v", got, want)
+	}
+	if got, want := dp["explicitBounds"], []any{0.1, 1.0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got explicit bounds %v, want %v", got, want)
+	}
+	if dp["count"] != "3" || dp["sum"] != 5.55 {
+		t.Errorf("got histogram data point %v", dp)
+	}
+
+	dp = metrics["temperature_celsius"]["gauge"].(map[string]any)["dataPoints"].([]any)[0].(map[string]any)
+	if dp["asDouble"] != 21.5 {
+		t.Errorf("got gauge data point %v", dp)
+	}
+}
+
+func TestPushDeltaTemporality(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	requests := prometheus.NewCounter(prometheus.CounterOpts{Name: "requests_total", Help: "Requests."})
+	reg.MustRegister(requests)
+
+	var (
+		mu   sync.Mutex
+		fail bool
+		sums []map[string]any
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if fail {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var body map[string]any
+		b, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(b, &body); err != nil {
+			t.Error(err)
+		}
+		rm := body["resourceMetrics"].([]any)[0].(map[string]any)
+		m := rm["scopeMetrics"].([]any)[0].(map[string]any)["metrics"].([]any)[0].(map[string]any)
+		sums = append(sums, m["sum"].(map[string]any))
+	}))
+	defer srv.Close()
+
+	b, err := NewBridge(&Config{URL: srv.URL, Gatherer: reg, DeltaTemporality: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	push := func(add float64, failing bool) {
+		t.Helper()
+		requests.Add(add)
+		mu.Lock()
+		fail = failing
+		mu.Unlock()
+		if err := b.Push(context.Background()); (err != nil) != failing {
+			t.Fatalf("got error %v", err)
+		}
+	}
+	push(3, false)
+	push(2, true)
+	// The increase of the failed push is included in the next one.
+	push(4, false)
+
+	if len(sums) != 2 {
+		t.Fatalf("got %d successful pushes, want 2", len(sums))
+	}
+	var lastTime any
+	for i, want := range []float64{3, 6} {
+		if sums[i]["aggregationTemporality"] != 1.0 {
+			t.Errorf("got sum %v, want delta temporality", sums[i])
+		}
+		dp := sums[i]["dataPoints"].([]any)[0].(map[string]any)
+		if dp["asDouble"] != want {
+			t.Errorf("got value %v in push %d, want %v", dp["asDouble"], i, want)
+		}
+		if i > 0 && dp["startTimeUnixNano"] != lastTime {
+			t.Errorf("got start time %v, want the time %v of the last push", dp["startTimeUnixNano"], lastTime)
+		}
+		lastTime = dp["timeUnixNano"]
+	}
+}
+
+func TestPushPermanentError(t *testing.T) {
+	var attempts int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	b, err := NewBridge(&Config{URL: srv.URL, Gatherer: prometheus.NewRegistry(), MinBackoff: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Push(context.Background()); err == nil {
+		t.Error("expected error")
+	}
+	if attempts != 1 {
+		t.Errorf("got %d attempts, want 1", attempts)
+	}
+}
+
+func TestExponentialBuckets(t *testing.T) {
+	// Buckets 1 and 2, and after a gap of 2, bucket 5, with counts 1, 3, 2.
+	got := exponentialBuckets([]*dto.BucketSpan{
+		{Offset: proto.Int32(1), Length: proto.Uint32(2)},
+		{Offset: proto.Int32(2), Length: proto.Uint32(1)},
+	}, []int64{1, 2, -1})
+	want := buckets{Offset: 0, BucketCounts: []uint64Str{1, 3, 0, 0, 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
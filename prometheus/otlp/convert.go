@@ -0,0 +1,382 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// The types below are the subset of the OTLP metrics data model used by the
+// bridge, in the JSON encoding of OTLP/HTTP, see
+// https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding.
+
+const (
+	// aggregationTemporalityDelta is AGGREGATION_TEMPORALITY_DELTA.
+	aggregationTemporalityDelta = 1
+	// aggregationTemporalityCumulative is AGGREGATION_TEMPORALITY_CUMULATIVE.
+	aggregationTemporalityCumulative = 2
+	scopeName                        = "github.com/prometheus/client_golang/prometheus/otlp"
+)
+
+type exportRequest struct {
+	ResourceMetrics []resourceMetrics `json:"resourceMetrics"`
+}
+
+type resourceMetrics struct {
+	Resource     resource       `json:"resource"`
+	ScopeMetrics []scopeMetrics `json:"scopeMetrics"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes,omitempty"`
+}
+
+type scopeMetrics struct {
+	Scope   scope    `json:"scope"`
+	Metrics []metric `json:"metrics"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type metric struct {
+	Name                 string                `json:"name"`
+	Description          string                `json:"description,omitempty"`
+	Unit                 string                `json:"unit,omitempty"`
+	Gauge                *gauge                `json:"gauge,omitempty"`
+	Sum                  *sum                  `json:"sum,omitempty"`
+	Histogram            *histogram            `json:"histogram,omitempty"`
+	ExponentialHistogram *exponentialHistogram `json:"exponentialHistogram,omitempty"`
+	Summary              *summary              `json:"summary,omitempty"`
+}
+
+type gauge struct {
+	DataPoints []numberDataPoint `json:"dataPoints"`
+}
+
+type sum struct {
+	DataPoints             []numberDataPoint `json:"dataPoints"`
+	AggregationTemporality int               `json:"aggregationTemporality"`
+	IsMonotonic            bool              `json:"isMonotonic"`
+}
+
+type histogram struct {
+	DataPoints             []histogramDataPoint `json:"dataPoints"`
+	AggregationTemporality int                  `json:"aggregationTemporality"`
+}
+
+type exponentialHistogram struct {
+	DataPoints             []exponentialHistogramDataPoint `json:"dataPoints"`
+	AggregationTemporality int                             `json:"aggregationTemporality"`
+}
+
+type summary struct {
+	DataPoints []summaryDataPoint `json:"dataPoints"`
+}
+
+type numberDataPoint struct {
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano uint64Str  `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      uint64Str  `json:"timeUnixNano"`
+	AsDouble          double     `json:"asDouble"`
+}
+
+type histogramDataPoint struct {
+	Attributes        []keyValue  `json:"attributes,omitempty"`
+	StartTimeUnixNano uint64Str   `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      uint64Str   `json:"timeUnixNano"`
+	Count             uint64Str   `json:"count"`
+	Sum               double      `json:"sum"`
+	BucketCounts      []uint64Str `json:"bucketCounts"`
+	ExplicitBounds    []double    `json:"explicitBounds"`
+}
+
+type exponentialHistogramDataPoint struct {
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano uint64Str  `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      uint64Str  `json:"timeUnixNano"`
+	Count             uint64Str  `json:"count"`
+	Sum               double     `json:"sum"`
+	Scale             int32      `json:"scale"`
+	ZeroCount         uint64Str  `json:"zeroCount"`
+	ZeroThreshold     double     `json:"zeroThreshold"`
+	Positive          buckets    `json:"positive"`
+	Negative          buckets    `json:"negative"`
+}
+
+type buckets struct {
+	Offset       int32       `json:"offset"`
+	BucketCounts []uint64Str `json:"bucketCounts"`
+}
+
+type summaryDataPoint struct {
+	Attributes        []keyValue        `json:"attributes,omitempty"`
+	StartTimeUnixNano uint64Str         `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      uint64Str         `json:"timeUnixNano"`
+	Count             uint64Str         `json:"count"`
+	Sum               double            `json:"sum"`
+	QuantileValues    []valueAtQuantile `json:"quantileValues"`
+}
+
+type valueAtQuantile struct {
+	Quantile double `json:"quantile"`
+	Value    double `json:"value"`
+}
+
+// uint64Str is a 64-bit integer, which the JSON encoding of protobuf
+// represents as a string.
+type uint64Str uint64
+
+func (u uint64Str) MarshalJSON() ([]byte, error) {
+	return strconv.AppendQuote(nil, strconv.FormatUint(uint64(u), 10)), nil
+}
+
+// double is a float, which the JSON encoding of protobuf represents as a
+// string for NaN and infinities.
+type double float64
+
+func (d double) MarshalJSON() ([]byte, error) {
+	f := float64(d)
+	switch {
+	case math.IsNaN(f):
+		return []byte(`"NaN"`), nil
+	case math.IsInf(f, 1):
+		return []byte(`"Infinity"`), nil
+	case math.IsInf(f, -1):
+		return []byte(`"-Infinity"`), nil
+	}
+	return strconv.AppendFloat(nil, f, 'g', -1, 64), nil
+}
+
+// converter converts metric families into OTLP metrics.
+type converter struct {
+	// start is used as start time of cumulative metrics without created
+	// timestamp.
+	start time.Time
+	// now is used as time of samples without timestamp.
+	now time.Time
+	// temporality is the aggregation temporality of sums and histograms,
+	// which have been converted by a delta.Converter if it is delta.
+	temporality int
+}
+
+func (c converter) request(mfs []*dto.MetricFamily, attrs map[string]string) exportRequest {
+	metrics := make([]metric, 0, len(mfs))
+	for _, mf := range mfs {
+		if m, ok := c.metric(mf); ok {
+			metrics = append(metrics, m)
+		}
+	}
+	return exportRequest{ResourceMetrics: []resourceMetrics{{
+		Resource:     resource{Attributes: attributes(attrs)},
+		ScopeMetrics: []scopeMetrics{{Scope: scope{Name: scopeName}, Metrics: metrics}},
+	}}}
+}
+
+func (c converter) metric(mf *dto.MetricFamily) (metric, bool) {
+	m := metric{Name: mf.GetName(), Description: mf.GetHelp(), Unit: mf.GetUnit()}
+	switch mf.GetType() {
+	case dto.MetricType_COUNTER:
+		s := &sum{AggregationTemporality: c.temporality, IsMonotonic: true}
+		for _, pb := range mf.GetMetric() {
+			s.DataPoints = append(s.DataPoints, numberDataPoint{
+				Attributes:        labelAttributes(pb),
+				StartTimeUnixNano: c.startTime(pb.GetCounter().GetCreatedTimestamp()),
+				TimeUnixNano:      c.time(pb),
+				AsDouble:          double(pb.GetCounter().GetValue()),
+			})
+		}
+		m.Sum = s
+	case dto.MetricType_GAUGE, dto.MetricType_UNTYPED:
+		g := &gauge{}
+		for _, pb := range mf.GetMetric() {
+			v := pb.GetGauge().GetValue()
+			if pb.Untyped != nil {
+				v = pb.GetUntyped().GetValue()
+			}
+			g.DataPoints = append(g.DataPoints, numberDataPoint{
+				Attributes:   labelAttributes(pb),
+				TimeUnixNano: c.time(pb),
+				AsDouble:     double(v),
+			})
+		}
+		m.Gauge = g
+	case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+		// Native histograms are converted to exponential histograms, which
+		// requires all histograms of the family to have native buckets.
+		native := len(mf.GetMetric()) > 0
+		for _, pb := range mf.GetMetric() {
+			native = native && pb.GetHistogram().Schema != nil
+		}
+		if native {
+			m.ExponentialHistogram = c.exponentialHistogram(mf)
+		} else {
+			m.Histogram = c.histogram(mf)
+		}
+	case dto.MetricType_SUMMARY:
+		s := &summary{}
+		for _, pb := range mf.GetMetric() {
+			ps := pb.GetSummary()
+			dp := summaryDataPoint{
+				Attributes:        labelAttributes(pb),
+				StartTimeUnixNano: c.startTime(ps.GetCreatedTimestamp()),
+				TimeUnixNano:      c.time(pb),
+				Count:             uint64Str(ps.GetSampleCount()),
+				Sum:               double(ps.GetSampleSum()),
+				QuantileValues:    []valueAtQuantile{},
+			}
+			for _, q := range ps.GetQuantile() {
+				dp.QuantileValues = append(dp.QuantileValues, valueAtQuantile{Quantile: double(q.GetQuantile()), Value: double(q.GetValue())})
+			}
+			s.DataPoints = append(s.DataPoints, dp)
+		}
+		m.Summary = s
+	default:
+		return metric{}, false
+	}
+	return m, true
+}
+
+func (c converter) histogram(mf *dto.MetricFamily) *histogram {
+	h := &histogram{AggregationTemporality: c.temporality}
+	for _, pb := range mf.GetMetric() {
+		ph := pb.GetHistogram()
+		dp := histogramDataPoint{
+			Attributes:        labelAttributes(pb),
+			StartTimeUnixNano: c.startTime(ph.GetCreatedTimestamp()),
+			TimeUnixNano:      c.time(pb),
+			Count:             uint64Str(ph.GetSampleCount()),
+			Sum:               double(ph.GetSampleSum()),
+			BucketCounts:      []uint64Str{},
+			ExplicitBounds:    []double{},
+		}
+		// OTLP buckets aren't cumulative, and the +Inf bucket is implicit.
+		var prev uint64
+		for _, b := range ph.GetBucket() {
+			if math.IsInf(b.GetUpperBound(), 1) {
+				continue
+			}
+			dp.ExplicitBounds = append(dp.ExplicitBounds, double(b.GetUpperBound()))
+			dp.BucketCounts = append(dp.BucketCounts, uint64Str(b.GetCumulativeCount()-prev))
+			prev = b.GetCumulativeCount()
+		}
+		dp.BucketCounts = append(dp.BucketCounts, uint64Str(ph.GetSampleCount()-prev))
+		h.DataPoints = append(h.DataPoints, dp)
+	}
+	return h
+}
+
+func (c converter) exponentialHistogram(mf *dto.MetricFamily) *exponentialHistogram {
+	h := &exponentialHistogram{AggregationTemporality: c.temporality}
+	for _, pb := range mf.GetMetric() {
+		ph := pb.GetHistogram()
+		h.DataPoints = append(h.DataPoints, exponentialHistogramDataPoint{
+			Attributes:        labelAttributes(pb),
+			StartTimeUnixNano: c.startTime(ph.GetCreatedTimestamp()),
+			TimeUnixNano:      c.time(pb),
+			Count:             uint64Str(ph.GetSampleCount()),
+			Sum:               double(ph.GetSampleSum()),
+			Scale:             ph.GetSchema(),
+			ZeroCount:         uint64Str(ph.GetZeroCount()),
+			ZeroThreshold:     double(ph.GetZeroThreshold()),
+			Positive:          exponentialBuckets(ph.GetPositiveSpan(), ph.GetPositiveDelta()),
+			Negative:          exponentialBuckets(ph.GetNegativeSpan(), ph.GetNegativeDelta()),
+		})
+	}
+	return h
+}
+
+// exponentialBuckets converts the spans and delta-encoded counts of native
+// buckets into the dense buckets of OTLP. The native bucket with index i has
+// the upper bound base^i, while the OTLP bucket with index i has the lower
+// bound base^i, so the offset is shifted by one.
+func exponentialBuckets(spans []*dto.BucketSpan, deltas []int64) buckets {
+	b := buckets{BucketCounts: []uint64Str{}}
+	var (
+		idx   int32
+		count int64
+		d     int
+	)
+	for i, span := range spans {
+		if i == 0 {
+			idx = span.GetOffset()
+			b.Offset = idx - 1
+		} else {
+			idx += span.GetOffset()
+		}
+		// Fill the gap between spans with empty buckets.
+		for int32(len(b.BucketCounts)) < idx-1-b.Offset {
+			b.BucketCounts = append(b.BucketCounts, 0)
+		}
+		for range span.GetLength() {
+			if d < len(deltas) {
+				count += deltas[d]
+				d++
+			}
+			b.BucketCounts = append(b.BucketCounts, uint64Str(count))
+			idx++
+		}
+	}
+	return b
+}
+
+// startTime returns the start time of a cumulative metric, which is its
+// created timestamp, if any.
+func (c converter) startTime(created *timestamppb.Timestamp) uint64Str {
+	if created.GetSeconds() > 0 || created.GetNanos() > 0 {
+		return uint64Str(created.AsTime().UnixNano())
+	}
+	return uint64Str(c.start.UnixNano())
+}
+
+// time returns the time of a sample, which is its timestamp, if any.
+func (c converter) time(pb *dto.Metric) uint64Str {
+	if pb.TimestampMs != nil {
+		return uint64Str(time.UnixMilli(pb.GetTimestampMs()).UnixNano())
+	}
+	return uint64Str(c.now.UnixNano())
+}
+
+func labelAttributes(pb *dto.Metric) []keyValue {
+	attrs := make([]keyValue, 0, len(pb.GetLabel()))
+	for _, lp := range pb.GetLabel() {
+		attrs = append(attrs, keyValue{Key: lp.GetName(), Value: anyValue{StringValue: lp.GetValue()}})
+	}
+	return attrs
+}
+
+func attributes(m map[string]string) []keyValue {
+	attrs := make([]keyValue, 0, len(m))
+	for k, v := range m {
+		attrs = append(attrs, keyValue{Key: k, Value: anyValue{StringValue: v}})
+	}
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].Key < attrs[j].Key })
+	return attrs
+}
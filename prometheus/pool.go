@@ -0,0 +1,98 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"sync"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// NewPooledGatherer returns a TransactionalGatherer that gathers from the
+// provided Registry like its Gather method, but takes the returned
+// MetricFamily and Metric protobufs from a pool, and returns them to the pool
+// once done is called. The MetricFamily protobufs keep the capacity of their
+// Metric slices. This reduces the allocations and thereby the GC pressure of
+// gathering registries with many series, e.g. when serving them with
+// promhttp.HandlerForTransactional:
+//
+//	http.Handle("/metrics", promhttp.HandlerForTransactional(
+//		prometheus.NewPooledGatherer(reg), promhttp.HandlerOpts{},
+//	))
+//
+// The caller owns the returned protobufs only until it calls done. Neither
+// they nor any part of them may be retained or accessed afterwards, as they
+// will be reused by subsequent gathers. Note that the pooled protobufs are
+// reset upon reuse, so that parts of them provided by Metric.Write
+// implementations, like the shared label pairs of the metrics of a vector, are
+// never modified.
+func NewPooledGatherer(r *Registry) TransactionalGatherer {
+	return &pooledGatherer{r: r, pool: &dtoPool{}}
+}
+
+type pooledGatherer struct {
+	r    *Registry
+	pool *dtoPool
+}
+
+// Gather implements TransactionalGatherer.
+func (g *pooledGatherer) Gather() (_ []*dto.MetricFamily, done func(), err error) {
+	mfs, err := g.r.gather(g.pool)
+	return mfs, func() { g.pool.put(mfs) }, err
+}
+
+// dtoPool pools MetricFamily and Metric protobufs. All methods can be called
+// on a nil *dtoPool, in which case new protobufs are allocated.
+type dtoPool struct {
+	families, metrics sync.Pool
+}
+
+func (p *dtoPool) getFamily() *dto.MetricFamily {
+	if p == nil {
+		return &dto.MetricFamily{}
+	}
+	if mf, ok := p.families.Get().(*dto.MetricFamily); ok {
+		return mf
+	}
+	return &dto.MetricFamily{}
+}
+
+func (p *dtoPool) getMetric() *dto.Metric {
+	if p == nil {
+		return &dto.Metric{}
+	}
+	if m, ok := p.metrics.Get().(*dto.Metric); ok {
+		return m
+	}
+	return &dto.Metric{}
+}
+
+// put resets the provided MetricFamily protobufs and their Metric protobufs
+// and returns them to the pool.
+func (p *dtoPool) put(mfs []*dto.MetricFamily) {
+	if p == nil {
+		return
+	}
+	for _, mf := range mfs {
+		for _, m := range mf.Metric {
+			m.Reset()
+			p.metrics.Put(m)
+		}
+		clear(mf.Metric)
+		metrics := mf.Metric[:0]
+		mf.Reset()
+		mf.Metric = metrics
+		p.families.Put(mf)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"strconv"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestPooledGatherer(t *testing.T) {
+	reg := NewRegistry()
+	counters := NewCounterVec(CounterOpts{Name: "requests_total", Help: "Requests."}, []string{"code"})
+	gauge := NewGauge(GaugeOpts{Name: "temperature", Help: "Temperature."})
+	histogram := NewHistogram(HistogramOpts{Name: "latency_seconds", Help: "Latency.", Buckets: []float64{1, 2}})
+	reg.MustRegister(counters, gauge, histogram)
+	g := NewPooledGatherer(reg)
+
+	for i := 0; i < 3; i++ {
+		counters.WithLabelValues(strconv.Itoa(200 + i)).Add(float64(i))
+		gauge.Set(float64(i))
+		histogram.Observe(float64(i))
+
+		want, err := reg.Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, done, err := g.Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("gather %d: got %d metric families, want %d", i, len(got), len(want))
+		}
+		for j := range want {
+			if !proto.Equal(got[j], want[j]) {
+				t.Errorf("gather %d: got %v, want %v", i, got[j], want[j])
+			}
+		}
+		done()
+	}
+
+	// Releasing the gathered protobufs must not modify the label pairs
+	// shared with the metrics.
+	var m dto.Metric
+	if err := counters.WithLabelValues("200").Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.GetLabel(); len(got) != 1 || got[0].GetName() != "code" || got[0].GetValue() != "200" {
+		t.Errorf("got label pairs %v after releasing, want code=200", got)
+	}
+}
+
+func BenchmarkRegistryGather(b *testing.B) {
+	reg := NewRegistry()
+	vec := NewCounterVec(CounterOpts{Name: "requests_total", Help: "Requests."}, []string{"id"})
+	reg.MustRegister(vec)
+	for i := 0; i < 10000; i++ {
+		vec.WithLabelValues(strconv.Itoa(i)).Inc()
+	}
+
+	b.Run("Gather", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := reg.Gather(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("PooledGatherer", func(b *testing.B) {
+		g := NewPooledGatherer(reg)
+		b.ReportAllocs()
+		for b.Loop() {
+			_, done, err := g.Gather()
+			if err != nil {
+				b.Fatal(err)
+			}
+			done()
+		}
+	})
+}
@@ -32,6 +32,8 @@ type processCollector struct {
 	rss               *Desc
 	startTime         *Desc
 	inBytes, outBytes *Desc
+	openFDsByType     *Desc
+	fdsByType         bool
 }
 
 // ProcessCollectorOpts defines the behavior of a process metrics collector
@@ -53,6 +55,14 @@ type ProcessCollectorOpts struct {
 	// metrics are nice to have, but failing to collect them should not
 	// disrupt the collection of the remaining metrics.
 	ReportErrors bool
+	// If true, the collector additionally exports the number of open file
+	// descriptors broken down by their type ("socket", "pipe", "file",
+	// "eventfd", or "other") as process_open_fds_by_type. Determining the
+	// type requires resolving every open file descriptor upon each
+	// collection, which is considerably more expensive than just counting
+	// them. This option is only supported on Linux and ignored on other
+	// operating systems.
+	FDsByType bool
 }
 
 // NewProcessCollector is the obsolete version of collectors.NewProcessCollector.
@@ -67,6 +77,7 @@ func NewProcessCollector(opts ProcessCollectorOpts) Collector {
 
 	c := &processCollector{
 		reportErrors: opts.ReportErrors,
+		fdsByType:    opts.FDsByType,
 		cpuTotal: NewDesc(
 			ns+"process_cpu_seconds_total",
 			"Total user and system CPU time spent in seconds.",
@@ -112,6 +123,11 @@ func NewProcessCollector(opts ProcessCollectorOpts) Collector {
 			"Number of bytes sent by the process over the network.",
 			nil, nil,
 		),
+		openFDsByType: NewDesc(
+			ns+"process_open_fds_by_type",
+			"Number of open file descriptors by type.",
+			[]string{"type"}, nil,
+		),
 	}
 
 	if opts.PidFn == nil {
@@ -17,6 +17,8 @@
 package prometheus
 
 import (
+	"strings"
+
 	"github.com/prometheus/procfs"
 )
 
@@ -57,6 +59,23 @@ func (c *processCollector) processCollect(ch chan<- Metric) {
 		c.reportError(ch, c.openFDs, err)
 	}
 
+	if c.fdsByType {
+		if targets, err := p.FileDescriptorTargets(); err == nil {
+			counts := map[string]int{}
+			for _, t := range fdTypes {
+				counts[t] = 0
+			}
+			for _, target := range targets {
+				counts[fdType(target)]++
+			}
+			for t, n := range counts {
+				ch <- MustNewConstMetric(c.openFDsByType, GaugeValue, float64(n), t)
+			}
+		} else {
+			c.reportError(ch, c.openFDsByType, err)
+		}
+	}
+
 	if limits, err := p.Limits(); err == nil {
 		ch <- MustNewConstMetric(c.maxFDs, GaugeValue, float64(limits.OpenFiles))
 		ch <- MustNewConstMetric(c.maxVsize, GaugeValue, float64(limits.AddressSpace))
@@ -93,4 +112,29 @@ func (c *processCollector) describe(ch chan<- *Desc) {
 	ch <- c.startTime
 	ch <- c.inBytes
 	ch <- c.outBytes
+	if c.fdsByType {
+		ch <- c.openFDsByType
+	}
+}
+
+// fdTypes lists all types reported by process_open_fds_by_type, so that each
+// of them is exported even if no file descriptor of that type is open.
+var fdTypes = []string{"socket", "pipe", "file", "eventfd", "other"}
+
+// fdType classifies a file descriptor by the target of its /proc/<pid>/fd
+// symlink, e.g. "socket:[12345]", "pipe:[67890]", "anon_inode:[eventfd]", or
+// an absolute path for files.
+func fdType(target string) string {
+	switch {
+	case strings.HasPrefix(target, "socket:"):
+		return "socket"
+	case strings.HasPrefix(target, "pipe:"):
+		return "pipe"
+	case target == "anon_inode:[eventfd]":
+		return "eventfd"
+	case strings.HasPrefix(target, "/"):
+		return "file"
+	default:
+		return "other"
+	}
 }
@@ -219,3 +219,64 @@ func TestDescribeAndCollectAlignment(t *testing.T) {
 		}
 	}
 }
+
+func TestProcessCollectorFDsByType(t *testing.T) {
+	if _, err := procfs.Self(); err != nil {
+		t.Skipf("skipping TestProcessCollectorFDsByType, procfs not available: %s", err)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	registry := NewPedanticRegistry()
+	if err := registry.Register(NewProcessCollector(ProcessCollectorOpts{
+		ReportErrors: true,
+		FDsByType:    true,
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	mfs, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	for _, mf := range mfs {
+		if _, err := expfmt.MetricFamilyToText(&buf, mf); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, re := range []*regexp.Regexp{
+		regexp.MustCompile("\nprocess_open_fds_by_type{type=\"pipe\"} [2-9]"),
+		regexp.MustCompile("\nprocess_open_fds_by_type{type=\"file\"} [0-9]"),
+		regexp.MustCompile("\nprocess_open_fds_by_type{type=\"socket\"} [0-9]"),
+		regexp.MustCompile("\nprocess_open_fds_by_type{type=\"eventfd\"} [0-9]"),
+		regexp.MustCompile("\nprocess_open_fds_by_type{type=\"other\"} [0-9]"),
+	} {
+		if !re.Match(buf.Bytes()) {
+			t.Errorf("want body to match %s\n%s", re, buf.String())
+		}
+	}
+}
+
+func TestFDType(t *testing.T) {
+	for target, want := range map[string]string{
+		"socket:[12345]":         "socket",
+		"pipe:[67890]":           "pipe",
+		"anon_inode:[eventfd]":   "eventfd",
+		"anon_inode:[eventpoll]": "other",
+		"/dev/null":              "file",
+		"/var/log/app.log":       "file",
+		"net:[4026531840]":       "other",
+	} {
+		if got := fdType(target); got != want {
+			t.Errorf("fdType(%q) = %q, want %q", target, got, want)
+		}
+	}
+}
@@ -184,6 +184,22 @@ func NewCounterFunc(opts prometheus.CounterOpts, function func() float64) promet
 	return With(prometheus.DefaultRegisterer).NewCounterFunc(opts, function)
 }
 
+// NewIntCounter works like the function of the same name in the prometheus
+// package but it automatically registers the IntCounter with the
+// prometheus.DefaultRegisterer. If the registration fails, NewIntCounter
+// panics.
+func NewIntCounter(opts prometheus.CounterOpts) prometheus.IntCounter {
+	return With(prometheus.DefaultRegisterer).NewIntCounter(opts)
+}
+
+// NewIntCounterVec works like the function of the same name in the prometheus
+// package but it automatically registers the IntCounterVec with the
+// prometheus.DefaultRegisterer. If the registration fails, NewIntCounterVec
+// panics.
+func NewIntCounterVec(opts prometheus.CounterOpts, labelNames []string) *prometheus.IntCounterVec {
+	return With(prometheus.DefaultRegisterer).NewIntCounterVec(opts, labelNames)
+}
+
 // NewGauge works like the function of the same name in the prometheus package
 // but it automatically registers the Gauge with the
 // prometheus.DefaultRegisterer. If the registration fails, NewGauge panics.
@@ -289,6 +305,28 @@ func (f Factory) NewCounterFunc(opts prometheus.CounterOpts, function func() flo
 	return c
 }
 
+// NewIntCounter works like the function of the same name in the prometheus
+// package but it automatically registers the IntCounter with the Factory's
+// Registerer.
+func (f Factory) NewIntCounter(opts prometheus.CounterOpts) prometheus.IntCounter {
+	c := prometheus.NewIntCounter(opts)
+	if f.r != nil {
+		f.r.MustRegister(c)
+	}
+	return c
+}
+
+// NewIntCounterVec works like the function of the same name in the prometheus
+// package but it automatically registers the IntCounterVec with the Factory's
+// Registerer.
+func (f Factory) NewIntCounterVec(opts prometheus.CounterOpts, labelNames []string) *prometheus.IntCounterVec {
+	c := prometheus.NewIntCounterVec(opts, labelNames)
+	if f.r != nil {
+		f.r.MustRegister(c)
+	}
+	return c
+}
+
 // NewGauge works like the function of the same name in the prometheus package
 // but it automatically registers the Gauge with the Factory's Registerer.
 func (f Factory) NewGauge(opts prometheus.GaugeOpts) prometheus.Gauge {
@@ -0,0 +1,82 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promhttp
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/common/expfmt"
+)
+
+// responseCache holds the most recently encoded (uncompressed) response of a
+// handler per negotiated format, see HandlerOpts.CacheTTL.
+type responseCache struct {
+	ttl time.Duration
+
+	mtx     sync.Mutex
+	entries map[expfmt.Format]*cacheEntry
+}
+
+// cacheEntry is a response in a responseCache. Its body is only valid once
+// done is closed.
+type cacheEntry struct {
+	done    chan struct{}
+	expires time.Time
+	body    []byte
+	ok      bool // False if gathering or encoding failed.
+}
+
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{ttl: ttl, entries: map[expfmt.Format]*cacheEntry{}}
+}
+
+// acquire returns the cached or currently encoded response for the provided
+// format. If there is neither, it creates a new entry and returns true, in
+// which case the caller has to encode the response and call release.
+func (c *responseCache) acquire(format expfmt.Format) (*cacheEntry, bool) {
+	now := time.Now()
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if e, ok := c.entries[format]; ok {
+		select {
+		case <-e.done:
+			if e.ok && now.Before(e.expires) {
+				return e, false
+			}
+		default:
+			// Still encoding.
+			return e, false
+		}
+	}
+	// Freshness is counted from the start of gathering.
+	e := &cacheEntry{done: make(chan struct{}), expires: now.Add(c.ttl)}
+	c.entries[format] = e
+	return e, true
+}
+
+// release completes an entry returned by acquire with the encoded body. If ok is
+// false, the entry is removed from the cache, so that the next request gathers
+// anew.
+func (c *responseCache) release(format expfmt.Format, e *cacheEntry, body []byte, ok bool) {
+	c.mtx.Lock()
+	e.body, e.ok = body, ok
+	if !ok && c.entries[format] == e {
+		delete(c.entries, format)
+	}
+	c.mtx.Unlock()
+	close(e.done)
+}
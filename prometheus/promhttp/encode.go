@@ -0,0 +1,193 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promhttp
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"sync"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// writerBufSize is the size of the buffer between the encoders and the
+// (compressing) response writer.
+const writerBufSize = 32 << 10
+
+var (
+	encodeBufPool = sync.Pool{
+		New: func() interface{} {
+			return &bytes.Buffer{}
+		},
+	}
+	writerBufPool = sync.Pool{
+		New: func() interface{} {
+			return bufio.NewWriterSize(nil, writerBufSize)
+		},
+	}
+	marshalBufPool = sync.Pool{
+		New: func() interface{} {
+			return new([]byte)
+		},
+	}
+	// bodyBufPools pool the buffers for whole encoded responses by the type
+	// of their format, as the sizes of the responses differ considerably
+	// between formats. Buffers only grown by one format are thereby not
+	// retained for another.
+	bodyBufPools = map[expfmt.FormatType]*sync.Pool{
+		expfmt.TypeProtoCompact: {},
+		expfmt.TypeProtoDelim:   {},
+		expfmt.TypeProtoText:    {},
+		expfmt.TypeTextPlain:    {},
+		expfmt.TypeOpenMetrics:  {},
+	}
+)
+
+// getBodyBuf returns an empty buffer for a whole response encoded in a format
+// of the provided type.
+func getBodyBuf(t expfmt.FormatType) *bytes.Buffer {
+	if pool, ok := bodyBufPools[t]; ok {
+		if buf, ok := pool.Get().(*bytes.Buffer); ok {
+			buf.Reset()
+			return buf
+		}
+	}
+	return &bytes.Buffer{}
+}
+
+// putBodyBuf returns a buffer obtained by getBodyBuf to its pool.
+func putBodyBuf(t expfmt.FormatType, buf *bytes.Buffer) {
+	if pool, ok := bodyBufPools[t]; ok {
+		pool.Put(buf)
+	}
+}
+
+// newExpfmtEncoder returns an encoder for the provided format like
+// expfmt.NewEncoder. The returned encoder for the delimited protobuf format
+// marshals into pooled buffers instead of allocating new ones for each metric
+// family, and writes each metric family with a single Write call.
+func newExpfmtEncoder(w io.Writer, format expfmt.Format, options ...expfmt.EncoderOption) expfmt.Encoder {
+	if format.FormatType() != expfmt.TypeProtoDelim {
+		return expfmt.NewEncoder(w, format, options...)
+	}
+	return protoDelimEncoder{w: w, escapingScheme: format.ToEscapingScheme()}
+}
+
+// protoDelimEncoder encodes metric families in the delimited protobuf format,
+// i.e. as varint length-prefixed protobuf messages.
+type protoDelimEncoder struct {
+	w              io.Writer
+	escapingScheme model.EscapingScheme
+}
+
+// Encode implements expfmt.Encoder.
+func (e protoDelimEncoder) Encode(mf *dto.MetricFamily) error {
+	mf = model.EscapeMetricFamily(mf, e.escapingScheme)
+	opts := proto.MarshalOptions{UseCachedSize: true}
+	bp := marshalBufPool.Get().(*[]byte)
+	defer marshalBufPool.Put(bp)
+
+	b := protowire.AppendVarint((*bp)[:0], uint64(opts.Size(mf)))
+	b, err := opts.MarshalAppend(b, mf)
+	*bp = b
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(b)
+	return err
+}
+
+// encodedFamily is the result of encoding a metric family.
+type encodedFamily struct {
+	buf *bytes.Buffer
+	err error
+}
+
+// encodeConcurrently encodes mfs with the provided number of workers, each
+// metric family with its own encoder created by newEncoder into its own
+// buffer, and writes the buffers to w in the order of mfs. At most twice the
+// number of workers of encoded metric families are buffered at any time. It
+// doesn't close the encoders, which is left to the caller.
+//
+// Encoding and writing errors are passed to handleError. If it returns true,
+// encodeConcurrently stops and returns the error. A metric family that failed
+// to encode is skipped, so that no partially encoded metric family is written.
+func encodeConcurrently(
+	w io.Writer,
+	mfs []*dto.MetricFamily,
+	workers int,
+	newEncoder func(io.Writer) expfmt.Encoder,
+	handleError func(error) bool,
+) error {
+	var (
+		indexes = make(chan int)
+		window  = make(chan struct{}, 2*workers)
+		stop    = make(chan struct{})
+		results = make([]chan encodedFamily, len(mfs))
+	)
+	for i := range results {
+		// Buffered, so that workers never block on sending a result.
+		results[i] = make(chan encodedFamily, 1)
+	}
+	defer close(stop)
+
+	go func() {
+		defer close(indexes)
+		for i := range mfs {
+			select {
+			case window <- struct{}{}:
+			case <-stop:
+				return
+			}
+			select {
+			case indexes <- i:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	for range min(workers, len(mfs)) {
+		go func() {
+			for i := range indexes {
+				buf := encodeBufPool.Get().(*bytes.Buffer)
+				buf.Reset()
+				err := newEncoder(buf).Encode(mfs[i])
+				results[i] <- encodedFamily{buf: buf, err: err}
+			}
+		}()
+	}
+
+	for _, result := range results {
+		r := <-result
+		<-window
+		if r.err != nil {
+			encodeBufPool.Put(r.buf)
+			if handleError(r.err) {
+				return r.err
+			}
+			continue
+		}
+		_, err := w.Write(r.buf.Bytes())
+		encodeBufPool.Put(r.buf)
+		if handleError(err) {
+			return err
+		}
+	}
+	return nil
+}
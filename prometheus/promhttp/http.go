@@ -18,7 +18,8 @@
 // prometheus.DefaultGatherer. With HandlerFor, you can create a handler for a
 // custom registry or anything that implements the Gatherer interface. It also
 // allows the creation of handlers that act differently on errors or allow to
-// log errors.
+// log errors. JSONHandlerFor serves the same metrics as JSON for debug UIs and
+// other consumers that cannot parse the Prometheus exposition formats.
 //
 // Second, the package provides tooling to instrument instances of http.Handler
 // via middleware. Middleware wrappers follow the naming scheme
@@ -32,16 +33,26 @@
 package promhttp
 
 import (
+	"bufio"
+	"bytes"
 	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/klauspost/compress/zstd"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/common/expfmt"
 
 	"github.com/prometheus/client_golang/internal/github.com/golang/gddo/httputil"
@@ -53,6 +64,9 @@ const (
 	contentEncodingHeader  = "Content-Encoding"
 	acceptEncodingHeader   = "Accept-Encoding"
 	processStartTimeHeader = "Process-Start-Time-Unix"
+	etagHeader             = "ETag"
+	reprDigestHeader       = "Repr-Digest"
+	ifNoneMatchHeader      = "If-None-Match"
 )
 
 // Compression represents the content encodings handlers support for the HTTP
@@ -73,9 +87,14 @@ var gzipPool = sync.Pool{
 	},
 }
 
+// zstdPool pools zstd encoders, which allocate considerable state upon
+// creation. It has no New function, as creating an encoder can fail.
+var zstdPool sync.Pool
+
 // Handler returns an http.Handler for the prometheus.DefaultGatherer, using
-// default HandlerOpts, i.e. it reports the first error as an HTTP error, it has
-// no error logging, and it applies compression if requested by the client.
+// default HandlerOpts, i.e. it reports the first error as an HTTP error, it
+// logs errors only if a logger is set with SetDefaultErrorSlog, and it applies
+// compression if requested by the client.
 //
 // The returned http.Handler is already instrumented using the
 // InstrumentMetricHandler function and the prometheus.DefaultRegisterer. If you
@@ -99,14 +118,36 @@ func Handler() http.Handler {
 // Gatherers, with non-default HandlerOpts, and/or with custom (or no)
 // instrumentation. Use the InstrumentMetricHandler function to apply the same
 // kind of instrumentation as it is used by the Handler function.
+//
+// If the Gatherer implements prometheus.ProtoGatherer (as the
+// prometheus.Registry does) and the delimited protobuf format is negotiated,
+// the metrics are encoded while gathering without creating MetricFamily
+// protobufs first, which saves most of the allocations of serving them.
 func HandlerFor(reg prometheus.Gatherer, opts HandlerOpts) http.Handler {
-	return HandlerForTransactional(prometheus.ToTransactionalGatherer(reg), opts)
+	if sg, ok := reg.(prometheus.StreamingGatherer); ok && opts.EnableStreaming && !opts.EnableETag && opts.CacheTTL <= 0 {
+		return handlerFor(nil, sg, nil, opts)
+	}
+	pg, _ := reg.(prometheus.ProtoGatherer)
+	return handlerFor(prometheus.ToTransactionalGatherer(reg), nil, pg, opts)
 }
 
 // HandlerForTransactional is like HandlerFor, but it uses transactional gather, which
 // can safely change in-place returned *dto.MetricFamily before call to `Gather` and after
 // call to `done` of that `Gather`.
 func HandlerForTransactional(reg prometheus.TransactionalGatherer, opts HandlerOpts) http.Handler {
+	return handlerFor(reg, nil, nil, opts)
+}
+
+// handlerFor implements HandlerFor and HandlerForTransactional. If sg is not
+// nil, it gathers from sg while encoding, and reg is ignored. If pg is not nil,
+// it is used instead of reg to gather directly into the delimited protobuf
+// format if negotiated.
+func handlerFor(
+	reg prometheus.TransactionalGatherer,
+	sg prometheus.StreamingGatherer,
+	pg prometheus.ProtoGatherer,
+	opts HandlerOpts,
+) http.Handler {
 	var (
 		inFlightSem chan struct{}
 		errCnt      = prometheus.NewCounterVec(
@@ -121,6 +162,10 @@ func HandlerForTransactional(reg prometheus.TransactionalGatherer, opts HandlerO
 	if opts.MaxRequestsInFlight > 0 {
 		inFlightSem = make(chan struct{}, opts.MaxRequestsInFlight)
 	}
+	var cache *responseCache
+	if opts.CacheTTL > 0 {
+		cache = newResponseCache(opts.CacheTTL)
+	}
 	if opts.Registry != nil {
 		// Initialize all possibilities that can occur below.
 		errCnt.WithLabelValues("gathering")
@@ -162,58 +207,126 @@ func HandlerForTransactional(reg prometheus.TransactionalGatherer, opts HandlerO
 				return
 			}
 		}
-		mfs, done, err := reg.Gather()
-		defer done()
-		if err != nil {
-			if opts.ErrorLog != nil {
-				opts.ErrorLog.Println("error gathering metrics:", err)
+
+		var contentType expfmt.Format
+		if opts.EnableOpenMetrics {
+			contentType = expfmt.NegotiateIncludingOpenMetrics(req.Header)
+		} else {
+			contentType = expfmt.Negotiate(req.Header)
+		}
+
+		var (
+			// body is the complete encoded response if buffered
+			// is true.
+			body     []byte
+			buffered bool
+			// entry is the cache entry to fill if this request has
+			// to gather and encode on behalf of the cache.
+			entry *cacheEntry
+			// failed is set if any error occurred, in which case
+			// the response is not cached.
+			failed bool
+		)
+		if cache != nil {
+			e, fill := cache.acquire(contentType)
+			if fill {
+				entry = e
+				defer func() {
+					if entry != nil {
+						// Gathering or encoding has failed.
+						cache.release(contentType, entry, nil, false)
+					}
+				}()
+			} else {
+				select {
+				case <-e.done:
+				case <-req.Context().Done():
+					return
+				}
+				// If gathering or encoding has failed for the
+				// request filling the entry, we gather ourselves
+				// to report the error.
+				body, buffered = e.body, e.ok
 			}
+		}
+
+		// pooledBuf is returned to its pool once the response is sent.
+		var pooledBuf *bytes.Buffer
+		defer func() {
+			if pooledBuf != nil {
+				putBodyBuf(contentType.FormatType(), pooledBuf)
+			}
+		}()
+		// newBodyBuf returns a buffer to encode the whole response into.
+		newBodyBuf := func() *bytes.Buffer {
+			if entry != nil {
+				// The cached body outlives the request.
+				return &bytes.Buffer{}
+			}
+			pooledBuf = getBodyBuf(contentType.FormatType())
+			return pooledBuf
+		}
+		// setBody sets the whole encoded response and passes it to the
+		// cache entry to fill, if any.
+		setBody := func(b []byte) {
+			body, buffered = b, true
+			if entry != nil {
+				cache.release(contentType, entry, body, !failed)
+				entry = nil
+			}
+		}
+
+		// handleGatherError handles an error returned by gathering
+		// according to opts.ErrorHandling and returns true if we have
+		// to abort after the handling. empty is whether no metrics have
+		// been gathered at all.
+		handleGatherError := func(err error, empty bool) bool {
+			failed = true
+			logError(req.Context(), opts, "error gathering metrics", "gathering", err)
 			errCnt.WithLabelValues("gathering").Inc()
 			switch opts.ErrorHandling {
 			case PanicOnError:
 				panic(err)
 			case ContinueOnError:
-				if len(mfs) == 0 {
+				if empty {
 					// Still report the error if no metrics have been gathered.
 					httpError(rsp, err)
-					return
+					return true
 				}
 			case HTTPErrorOnError:
 				httpError(rsp, err)
-				return
+				return true
 			}
+			return false
 		}
 
-		var contentType expfmt.Format
-		if opts.EnableOpenMetrics {
-			contentType = expfmt.NegotiateIncludingOpenMetrics(req.Header)
-		} else {
-			contentType = expfmt.Negotiate(req.Header)
-		}
-		rsp.Header().Set(contentTypeHeader, string(contentType))
-
-		w, encodingHeader, closeWriter, err := negotiateEncodingWriter(req, rsp, compressions)
-		if err != nil {
-			if opts.ErrorLog != nil {
-				opts.ErrorLog.Println("error getting writer", err)
+		var mfs []*dto.MetricFamily
+		switch {
+		case sg != nil || buffered:
+			// Gathered while encoding below, or served from the cache.
+		case pg != nil && contentType.FormatType() == expfmt.TypeProtoDelim:
+			// Encode the metrics while gathering them, without
+			// creating MetricFamily protobufs.
+			buf := newBodyBuf()
+			b, err := pg.AppendProtoDelimited(buf.AvailableBuffer(), contentType.ToEscapingScheme())
+			buf.Write(b)
+			if err != nil && handleGatherError(err, buf.Len() == 0) {
+				return
+			}
+			setBody(buf.Bytes())
+		default:
+			var (
+				done func()
+				err  error
+			)
+			mfs, done, err = reg.Gather()
+			defer done()
+			if err != nil && handleGatherError(err, len(mfs) == 0) {
+				return
 			}
-			w = io.Writer(rsp)
-			encodingHeader = string(Identity)
-		}
-
-		defer closeWriter()
-
-		// Set Content-Encoding only when data is compressed
-		if encodingHeader != string(Identity) {
-			rsp.Header().Set(contentEncodingHeader, encodingHeader)
 		}
 
-		var enc expfmt.Encoder
-		if opts.EnableOpenMetricsTextCreatedSamples {
-			enc = expfmt.NewEncoder(w, contentType, expfmt.WithCreatedLines())
-		} else {
-			enc = expfmt.NewEncoder(w, contentType)
-		}
+		rsp.Header().Set(contentTypeHeader, string(contentType))
 
 		// handleError handles the error according to opts.ErrorHandling
 		// and returns true if we have to abort after the handling.
@@ -221,9 +334,8 @@ func HandlerForTransactional(reg prometheus.TransactionalGatherer, opts HandlerO
 			if err == nil {
 				return false
 			}
-			if opts.ErrorLog != nil {
-				opts.ErrorLog.Println("error encoding and sending metric family:", err)
-			}
+			failed = true
+			logError(req.Context(), opts, "error encoding and sending metric family", "encoding", err)
 			errCnt.WithLabelValues("encoding").Inc()
 			switch opts.ErrorHandling {
 			case PanicOnError:
@@ -239,17 +351,114 @@ func HandlerForTransactional(reg prometheus.TransactionalGatherer, opts HandlerO
 			return false
 		}
 
-		for _, mf := range mfs {
-			if handleError(enc.Encode(mf)) {
+		// encode encodes the metric families to w and returns the error
+		// if we have to abort.
+		encode := func(w io.Writer) error {
+			newEncoder := func(w io.Writer) expfmt.Encoder {
+				if opts.EnableOpenMetricsTextCreatedSamples {
+					return newExpfmtEncoder(w, contentType, expfmt.WithCreatedLines())
+				}
+				return newExpfmtEncoder(w, contentType)
+			}
+			enc := newEncoder(w)
+			switch {
+			case sg != nil:
+				var encodeErr error
+				err := sg.GatherFunc(func(mf *dto.MetricFamily) error {
+					if err := enc.Encode(mf); handleError(err) {
+						encodeErr = err
+						return err
+					}
+					return nil
+				})
+				if encodeErr != nil {
+					return encodeErr
+				}
+				if err != nil {
+					// Too late for an HTTP error, see
+					// HandlerOpts.EnableStreaming.
+					logError(req.Context(), opts, "error gathering metrics", "gathering", err)
+					errCnt.WithLabelValues("gathering").Inc()
+					if opts.ErrorHandling == PanicOnError {
+						panic(err)
+					}
+				}
+			case opts.EncodingConcurrency > 1 && len(mfs) > 1:
+				if err := encodeConcurrently(w, mfs, opts.EncodingConcurrency, newEncoder, handleError); err != nil {
+					return err
+				}
+			default:
+				for _, mf := range mfs {
+					if err := enc.Encode(mf); handleError(err) {
+						return err
+					}
+				}
+			}
+			if closer, ok := enc.(expfmt.Closer); ok {
+				// This in particular takes care of the final "# EOF\n" line for OpenMetrics.
+				if err := closer.Close(); handleError(err) {
+					return err
+				}
+			}
+			return nil
+		}
+
+		if !buffered && (entry != nil || opts.EnableETag) {
+			buf := newBodyBuf()
+			if err := encode(buf); err != nil {
+				// Nothing has been sent yet, so we can send an error.
+				httpError(rsp, err)
 				return
 			}
+			setBody(buf.Bytes())
 		}
-		if closer, ok := enc.(expfmt.Closer); ok {
-			// This in particular takes care of the final "# EOF\n" line for OpenMetrics.
-			if handleError(closer.Close()) {
+		if buffered && opts.EnableETag {
+			digest := sha256.Sum256(body)
+			etag := `W/"` + hex.EncodeToString(digest[:16]) + `"`
+			rsp.Header().Set(etagHeader, etag)
+			rsp.Header().Set(reprDigestHeader, "sha-256=:"+base64.StdEncoding.EncodeToString(digest[:])+":")
+			if etagMatches(req.Header.Get(ifNoneMatchHeader), etag) {
+				rsp.WriteHeader(http.StatusNotModified)
 				return
 			}
 		}
+
+		w, encodingHeader, closeWriter, err := negotiateEncodingWriter(req, rsp, compressions)
+		if err != nil {
+			logError(req.Context(), opts, "error getting writer", "compression", err)
+			w = io.Writer(rsp)
+			encodingHeader = string(Identity)
+		}
+
+		defer closeWriter()
+
+		// Set Content-Encoding only when data is compressed
+		if encodingHeader != string(Identity) {
+			rsp.Header().Set(contentEncodingHeader, encodingHeader)
+		}
+
+		if buffered {
+			if _, err := w.Write(body); err != nil {
+				handleError(err)
+			}
+			return
+		}
+		// Buffer the many small writes of the encoders.
+		bw := writerBufPool.Get().(*bufio.Writer)
+		bw.Reset(w)
+		defer func() {
+			bw.Reset(nil)
+			writerBufPool.Put(bw)
+		}()
+		if err := encode(bw); err != nil {
+			// Still send what has been encoded before the error, which
+			// has been handled already.
+			_ = bw.Flush()
+			return
+		}
+		if err := bw.Flush(); err != nil {
+			handleError(err)
+		}
 	})
 
 	if opts.Timeout <= 0 {
@@ -350,16 +559,68 @@ type Logger interface {
 	Println(v ...interface{})
 }
 
+// defaultErrorSlog is the logger set by SetDefaultErrorSlog.
+var defaultErrorSlog atomic.Pointer[slog.Logger]
+
+// SetDefaultErrorSlog sets the logger for errors collecting and serving
+// metrics of all handlers whose HandlerOpts have neither an ErrorLog nor an
+// ErrorSlog, including the handler returned by Handler. The errors are logged
+// like with HandlerOpts.ErrorSlog. If l is nil, those handlers don't log
+// errors, which is the default.
+func SetDefaultErrorSlog(l *slog.Logger) {
+	defaultErrorSlog.Store(l)
+}
+
+// logError logs err to the loggers configured in opts. The cause is added as
+// an attribute to the records logged to a slog.Logger, and the errors
+// contained in a prometheus.MultiError are logged as separate records, with
+// the name of the affected metric, if known.
+func logError(ctx context.Context, opts HandlerOpts, msg, cause string, err error) {
+	if opts.ErrorLog != nil {
+		opts.ErrorLog.Println(msg+":", err)
+	}
+	l := opts.ErrorSlog
+	if l == nil && opts.ErrorLog == nil {
+		l = defaultErrorSlog.Load()
+	}
+	if l == nil {
+		return
+	}
+	errs := prometheus.MultiError{err}
+	if me, ok := err.(prometheus.MultiError); ok {
+		errs = me
+	}
+	for _, err := range errs {
+		attrs := []slog.Attr{slog.String("cause", cause), slog.Any("err", err)}
+		var me *prometheus.MetricError
+		if errors.As(err, &me) && me.FQName != "" {
+			attrs = append(attrs, slog.String("metric", me.FQName))
+		}
+		l.LogAttrs(ctx, slog.LevelError, msg, attrs...)
+	}
+}
+
 // HandlerOpts specifies options how to serve metrics via an http.Handler. The
 // zero value of HandlerOpts is a reasonable default.
 type HandlerOpts struct {
 	// ErrorLog specifies an optional Logger for errors collecting and
-	// serving metrics. If nil, errors are not logged at all. Note that the
+	// serving metrics. If nil, errors are only logged to ErrorSlog or the
+	// logger set with SetDefaultErrorSlog, if any. Note that the
 	// type of a reported error is often prometheus.MultiError, which
 	// formats into a multi-line error string. If you want to avoid the
 	// latter, create a Logger implementation that detects a
 	// prometheus.MultiError and formats the contained errors into one line.
 	ErrorLog Logger
+	// ErrorSlog specifies an optional slog.Logger for the same errors as
+	// ErrorLog, which can be set in addition. The errors are logged at
+	// level error with the attribute "cause" (like the label of the
+	// "promhttp_metric_handler_errors_total" counter, or "compression")
+	// and "err". A prometheus.MultiError is logged as one record per
+	// contained error, with the attribute "metric" set to the name of the
+	// affected metric if known (see prometheus.MetricError). If neither
+	// ErrorLog nor ErrorSlog is set, the logger set with
+	// SetDefaultErrorSlog is used.
+	ErrorSlog *slog.Logger
 	// ErrorHandling defines how errors are handled. Note that errors are
 	// logged regardless of the configured ErrorHandling provided ErrorLog
 	// is not nil.
@@ -437,6 +698,55 @@ type HandlerOpts struct {
 	// NOTE: This feature is experimental and not covered by OpenMetrics or Prometheus
 	// exposition format.
 	ProcessStartTime time.Time
+	// If true, the handler sets an ETag header derived from the encoded
+	// metrics, and a Repr-Digest header with their SHA-256 digest (see
+	// RFC 9530), and it responds to requests with a matching If-None-Match
+	// header with 304 Not Modified and no body. This allows consumers and
+	// caching proxies to detect cheaply whether the metrics have changed.
+	// The ETag is weak, as it doesn't depend on the compression of the
+	// response. Note that the metrics are encoded into a buffer before
+	// sending, which requires memory for the whole uncompressed response.
+	// Note also that any change of a value or timestamp changes the ETag,
+	// so this is most useful for slowly changing metrics, e.g. of batch
+	// jobs or metadata.
+	EnableETag bool
+	// EncodingConcurrency is the number of goroutines encoding metric
+	// families concurrently. If it is greater than 1, each metric family is
+	// encoded into its own buffer by a pool of workers, and the buffers are
+	// written to the response in the order of the gathered metric families,
+	// overlapping the encoding with the compression and sending of the
+	// response. This reduces the latency of serving large registries on
+	// multicore hosts at the cost of additional CPU and memory, as up to
+	// twice the EncodingConcurrency of encoded metric families are
+	// buffered. The default of 0 (or 1) encodes sequentially.
+	EncodingConcurrency int
+	// If true, and the Gatherer passed to HandlerFor implements
+	// prometheus.StreamingGatherer (as the prometheus.Registry does), the
+	// handler encodes and sends each metric family as soon as it is
+	// gathered instead of gathering all metric families first, so that
+	// the memory required for serving doesn't scale with the total number
+	// of series. The metric families are then not necessarily sent in
+	// lexicographic order of their names. As gathering errors are only
+	// known after the response has been sent, they are logged and counted,
+	// but never result in an HTTP error, i.e. HTTPErrorOnError behaves like
+	// ContinueOnError. EnableStreaming is ignored if EnableETag or CacheTTL
+	// is set or if the handler is created with HandlerForTransactional,
+	// and EncodingConcurrency is ignored if streaming.
+	EnableStreaming bool
+	// If CacheTTL is positive, the handler keeps the encoded response for
+	// each negotiated format (content type) for up to CacheTTL, counted
+	// from the start of gathering, and serves it to subsequent requests
+	// for the same format instead of gathering and encoding anew.
+	// Concurrent requests for the same format wait for a single gathering.
+	// This reduces the collection cost if the metrics are scraped several
+	// times in quick succession, e.g. by a highly available pair of
+	// Prometheus servers, at the price of the served metrics being up to
+	// CacheTTL old. Compression is still applied per request. Responses
+	// are only cached if no error occurred while gathering or encoding
+	// them. Like with EnableETag, the metrics are encoded into a buffer
+	// before sending, and the cache holds one uncompressed response per
+	// format.
+	CacheTTL time.Duration
 }
 
 // httpError removes any content-encoding header and then calls http.Error with
@@ -452,6 +762,21 @@ func httpError(rsp http.ResponseWriter, err error) {
 	)
 }
 
+// etagMatches returns whether the value of an If-None-Match header matches the
+// provided ETag, using the weak comparison of RFC 9110.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
 // negotiateEncodingWriter reads the Accept-Encoding header from a request and
 // selects the right compression based on an allow-list of supported
 // compressions. It returns a writer implementing the compression and an the
@@ -466,14 +791,18 @@ func negotiateEncodingWriter(r *http.Request, rw io.Writer, compressions []strin
 
 	switch selected {
 	case "zstd":
-		// TODO(mrueg): Replace klauspost/compress with stdlib implementation once https://github.com/golang/go/issues/62513 is implemented.
-		z, err := zstd.NewWriter(rw, zstd.WithEncoderLevel(zstd.SpeedFastest))
-		if err != nil {
-			return nil, "", func() {}, err
+		z, ok := zstdPool.Get().(*zstd.Encoder)
+		if !ok {
+			// TODO(mrueg): Replace klauspost/compress with stdlib implementation once https://github.com/golang/go/issues/62513 is implemented.
+			var err error
+			z, err = zstd.NewWriter(rw, zstd.WithEncoderLevel(zstd.SpeedFastest))
+			if err != nil {
+				return nil, "", func() {}, err
+			}
 		}
 
 		z.Reset(rw)
-		return z, selected, func() { _ = z.Close() }, nil
+		return z, selected, func() { _ = z.Close(); zstdPool.Put(z) }, nil
 	case "gzip":
 		gz := gzipPool.Get().(*gzip.Writer)
 		gz.Reset(rw)
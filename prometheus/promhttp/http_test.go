@@ -20,14 +20,20 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/klauspost/compress/zstd"
 	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -245,6 +251,60 @@ the_count 0
 	panicHandler.ServeHTTP(writer, request)
 }
 
+func TestHandlerErrorSlog(t *testing.T) {
+	// Two gatherers failing result in a MultiError.
+	reg1 := prometheus.NewRegistry()
+	reg1.MustRegister(errorCollector{})
+	reg2 := prometheus.NewRegistry()
+	reg2.MustRegister(errorCollector{})
+	reg := prometheus.Gatherers{reg1, reg2}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	handler := HandlerFor(reg, HandlerOpts{ErrorSlog: logger, ErrorHandling: ContinueOnError})
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d log lines, want 2:\n%s", len(lines), buf.String())
+	}
+	for _, line := range lines {
+		for _, want := range []string{
+			`level=ERROR msg="error gathering metrics" cause=gathering err=`,
+			`error collecting metric Desc{fqName: \"invalid_metric\"`,
+			` metric=invalid_metric`,
+		} {
+			if !strings.Contains(line, want) {
+				t.Errorf("log line doesn't contain %q: %s", want, line)
+			}
+		}
+	}
+
+	// The default logger is only used without ErrorLog and ErrorSlog.
+	buf.Reset()
+	SetDefaultErrorSlog(logger)
+	defer SetDefaultErrorSlog(nil)
+	HandlerFor(reg, HandlerOpts{ErrorLog: log.New(io.Discard, "", 0)}).ServeHTTP(
+		httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil),
+	)
+	if buf.Len() != 0 {
+		t.Errorf("default logger used despite ErrorLog:\n%s", buf.String())
+	}
+	HandlerFor(reg, HandlerOpts{}).ServeHTTP(
+		httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil),
+	)
+	if !strings.Contains(buf.String(), "cause=gathering") {
+		t.Errorf("default logger not used:\n%s", buf.String())
+	}
+}
+
 func TestInstrumentMetricHandler(t *testing.T) {
 	reg := prometheus.NewRegistry()
 	mReg := &mockTransactionGatherer{g: reg}
@@ -639,3 +699,384 @@ func BenchmarkCompression(b *testing.B) {
 		}
 	}
 }
+
+func TestHandlerETag(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	g := prometheus.NewGauge(prometheus.GaugeOpts{Name: "g", Help: "A gauge."})
+	reg.MustRegister(g)
+	handler := HandlerFor(reg, HandlerOpts{EnableETag: true})
+
+	get := func(ifNoneMatch, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(acceptHeader, acceptTextPlain)
+		req.Header.Set(acceptEncodingHeader, acceptEncoding)
+		if ifNoneMatch != "" {
+			req.Header.Set(ifNoneMatchHeader, ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := get("", "")
+	etag := first.Header().Get(etagHeader)
+	if first.Code != http.StatusOK || !strings.HasPrefix(etag, `W/"`) {
+		t.Fatalf("got status %d and ETag %q", first.Code, etag)
+	}
+	if !strings.HasPrefix(first.Header().Get(reprDigestHeader), "sha-256=:") {
+		t.Errorf("got Repr-Digest %q", first.Header().Get(reprDigestHeader))
+	}
+
+	// The ETag doesn't depend on the compression.
+	for _, encoding := range []string{"", "gzip"} {
+		rec := get(`"other", `+etag, encoding)
+		if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+			t.Errorf("got status %d and %d bytes for a matching ETag with encoding %q", rec.Code, rec.Body.Len(), encoding)
+		}
+	}
+	if rec := get("*", ""); rec.Code != http.StatusNotModified {
+		t.Errorf("got status %d for If-None-Match *", rec.Code)
+	}
+
+	g.Set(1)
+	second := get(etag, "gzip")
+	if second.Code != http.StatusOK || second.Header().Get(etagHeader) == etag {
+		t.Errorf("got status %d and ETag %q after a change", second.Code, second.Header().Get(etagHeader))
+	}
+	if second.Header().Get(contentEncodingHeader) != "gzip" {
+		t.Errorf("got Content-Encoding %q, want gzip", second.Header().Get(contentEncodingHeader))
+	}
+}
+
+func TestHandlerEncodingConcurrency(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	for i := 0; i < 50; i++ {
+		c := prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: fmt.Sprintf("counter_%02d_total", i),
+			Help: "A counter.",
+		}, []string{"l"})
+		c.WithLabelValues("a").Add(float64(i))
+		c.WithLabelValues("b").Add(float64(2 * i))
+		reg.MustRegister(c)
+	}
+
+	for _, accept := range []string{
+		acceptTextPlain,
+		"application/openmetrics-text; version=1.0.0",
+		"application/vnd.google.protobuf; proto=io.prometheus.client.MetricFamily; encoding=delimited",
+	} {
+		get := func(opts HandlerOpts) []byte {
+			opts.EnableOpenMetrics = true
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(acceptHeader, accept)
+			rec := httptest.NewRecorder()
+			HandlerFor(reg, opts).ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("got status %d for %s", rec.Code, accept)
+			}
+			return rec.Body.Bytes()
+		}
+		want := get(HandlerOpts{})
+		if got := get(HandlerOpts{EncodingConcurrency: 4}); !bytes.Equal(got, want) {
+			t.Errorf("got for %s:\n%s\nwant:\n%s", accept, got, want)
+		}
+	}
+}
+
+func TestHandlerEncodingConcurrencyErrors(t *testing.T) {
+	g := prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		mfs := make([]*dto.MetricFamily, 20)
+		for i := range mfs {
+			mfs[i] = &dto.MetricFamily{
+				Name:   proto.String(fmt.Sprintf("gauge_%02d", i)),
+				Type:   dto.MetricType_GAUGE.Enum(),
+				Metric: []*dto.Metric{{Gauge: &dto.Gauge{Value: proto.Float64(1)}}},
+			}
+		}
+		// A gauge without value fails to encode.
+		mfs[10].Metric[0].Gauge = nil
+		return mfs, nil
+	})
+
+	for _, tc := range []struct {
+		errorHandling HandlerErrorHandling
+		wantFamilies  int
+	}{
+		{ContinueOnError, 19},
+		{HTTPErrorOnError, 10},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(acceptHeader, acceptTextPlain)
+		rec := httptest.NewRecorder()
+		HandlerFor(g, HandlerOpts{ErrorHandling: tc.errorHandling, EncodingConcurrency: 3}).ServeHTTP(rec, req)
+		body := rec.Body.String()
+		if got := strings.Count(body, "# TYPE"); got != tc.wantFamilies {
+			t.Errorf("got %d metric families with error handling %d, want %d:\n%s", got, tc.errorHandling, tc.wantFamilies, body)
+		}
+		if strings.Contains(body, "gauge_10") {
+			t.Errorf("partially encoded metric family written with error handling %d:\n%s", tc.errorHandling, body)
+		}
+	}
+}
+
+func TestHandlerStreaming(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	for i := 0; i < 20; i++ {
+		c := prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: fmt.Sprintf("counter_%02d_total", i),
+			Help: "A counter.",
+		}, []string{"l"})
+		c.WithLabelValues("a").Add(float64(i))
+		c.WithLabelValues("b").Add(float64(2 * i))
+		reg.MustRegister(c)
+	}
+
+	for _, accept := range []string{
+		acceptTextPlain,
+		"application/openmetrics-text; version=1.0.0",
+		"application/vnd.google.protobuf; proto=io.prometheus.client.MetricFamily; encoding=delimited",
+	} {
+		get := func(opts HandlerOpts) []byte {
+			opts.EnableOpenMetrics = true
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(acceptHeader, accept)
+			rec := httptest.NewRecorder()
+			HandlerFor(reg, opts).ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("got status %d for %s", rec.Code, accept)
+			}
+			return rec.Body.Bytes()
+		}
+		// Each collector has its own metric name, so the metric families
+		// are streamed in the same order as gathered.
+		want := get(HandlerOpts{})
+		if got := get(HandlerOpts{EnableStreaming: true}); !bytes.Equal(got, want) {
+			t.Errorf("got for %s:\n%s\nwant:\n%s", accept, got, want)
+		}
+	}
+}
+
+func TestHandlerStreamingErrors(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	errReg := prometheus.NewRegistry()
+	reg.MustRegister(prometheus.NewGauge(prometheus.GaugeOpts{Name: "gauge", Help: "A gauge."}))
+	reg.MustRegister(errorCollector{})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(acceptHeader, acceptTextPlain)
+	rec := httptest.NewRecorder()
+	HandlerFor(reg, HandlerOpts{EnableStreaming: true, Registry: errReg}).ServeHTTP(rec, req)
+	// The response has been started before the gathering error is known.
+	if rec.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusOK)
+	}
+	if !strings.Contains(rec.Body.String(), "gauge 0") {
+		t.Errorf("gauge missing in body:\n%s", rec.Body.String())
+	}
+
+	mfs, err := errReg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gatheringErrors float64
+	for _, m := range mfs[0].GetMetric() {
+		if m.GetLabel()[0].GetValue() == "gathering" {
+			gatheringErrors = m.GetCounter().GetValue()
+		}
+	}
+	if gatheringErrors != 1 {
+		t.Errorf("got %v gathering errors, want 1", gatheringErrors)
+	}
+}
+
+func TestHandlerProtoGatherer(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	counters := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "counter_total", Help: "A counter."}, []string{"l"})
+	counters.WithLabelValues("b").Add(2)
+	counters.WithLabelValues("a").Inc()
+	reg.MustRegister(
+		counters,
+		prometheus.NewGauge(prometheus.GaugeOpts{Name: "utf8.gauge", Help: "A gauge."}),
+		prometheus.NewSummary(prometheus.SummaryOpts{Name: "summary", Help: "A summary."}),
+	)
+	errReg := prometheus.NewRegistry()
+	errReg.MustRegister(counters, errorCollector{})
+
+	for _, accept := range []string{
+		"application/vnd.google.protobuf; proto=io.prometheus.client.MetricFamily; encoding=delimited",
+		"application/vnd.google.protobuf; proto=io.prometheus.client.MetricFamily; encoding=delimited; escaping=allow-utf-8",
+	} {
+		for _, s := range []struct {
+			reg  *prometheus.Registry
+			opts HandlerOpts
+		}{
+			{reg, HandlerOpts{}},
+			{reg, HandlerOpts{CacheTTL: time.Hour}},
+			{errReg, HandlerOpts{ErrorHandling: ContinueOnError}},
+			{errReg, HandlerOpts{ErrorHandling: HTTPErrorOnError}},
+		} {
+			get := func(handler http.Handler) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.Header.Set(acceptHeader, accept)
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+				return rec
+			}
+			// HandlerForTransactional always uses Gather.
+			want := get(HandlerForTransactional(prometheus.ToTransactionalGatherer(s.reg), s.opts))
+			got := get(HandlerFor(s.reg, s.opts))
+			if got.Code != want.Code || got.Header().Get(contentTypeHeader) != want.Header().Get(contentTypeHeader) {
+				t.Errorf("got status %d and Content-Type %q, want %d and %q",
+					got.Code, got.Header().Get(contentTypeHeader), want.Code, want.Header().Get(contentTypeHeader))
+			}
+			if !bytes.Equal(got.Body.Bytes(), want.Body.Bytes()) {
+				t.Errorf("got body for %s:\n%q\nwant:\n%q", accept, got.Body.Bytes(), want.Body.Bytes())
+			}
+		}
+	}
+}
+
+func TestHandlerCache(t *testing.T) {
+	var collects atomic.Int64
+	newRegistry := func() *prometheus.Registry {
+		reg := prometheus.NewRegistry()
+		reg.MustRegister(prometheus.NewGaugeFunc(
+			prometheus.GaugeOpts{Name: "collects", Help: "Number of collections."},
+			func() float64 { return float64(collects.Add(1)) },
+		))
+		return reg
+	}
+	get := func(handler http.Handler, accept, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(acceptHeader, accept)
+		req.Header.Set(acceptEncodingHeader, acceptEncoding)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	wantCollects := func(want int64) {
+		t.Helper()
+		if got := collects.Swap(0); got != want {
+			t.Errorf("got %d collections, want %d", got, want)
+		}
+	}
+
+	handler := HandlerFor(newRegistry(), HandlerOpts{CacheTTL: time.Hour})
+	first := get(handler, acceptTextPlain, "")
+	second := get(handler, acceptTextPlain, "")
+	wantCollects(1)
+	if first.Code != http.StatusOK || first.Body.String() != second.Body.String() {
+		t.Errorf("got status %d and body %q, then body %q", first.Code, first.Body.String(), second.Body.String())
+	}
+	// The compression is applied to the cached response.
+	if rec := get(handler, acceptTextPlain, "gzip"); rec.Header().Get(contentEncodingHeader) != "gzip" {
+		t.Errorf("got Content-Encoding %q, want gzip", rec.Header().Get(contentEncodingHeader))
+	}
+	wantCollects(0)
+	// Other formats are cached separately.
+	rec := get(handler, "application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited", "")
+	if got := rec.Header().Get(contentTypeHeader); !strings.HasPrefix(got, "application/vnd.google.protobuf") {
+		t.Errorf("got Content-Type %q, want protobuf", got)
+	}
+	get(handler, acceptTextPlain, "")
+	wantCollects(1)
+
+	// Cached responses expire.
+	handler = HandlerFor(newRegistry(), HandlerOpts{CacheTTL: time.Millisecond})
+	get(handler, acceptTextPlain, "")
+	time.Sleep(10 * time.Millisecond)
+	get(handler, acceptTextPlain, "")
+	wantCollects(2)
+
+	// Responses with errors aren't cached.
+	reg := newRegistry()
+	reg.MustRegister(errorCollector{})
+	handler = HandlerFor(reg, HandlerOpts{CacheTTL: time.Hour, ErrorHandling: ContinueOnError})
+	for i := 0; i < 2; i++ {
+		if rec := get(handler, acceptTextPlain, ""); rec.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", rec.Code, http.StatusOK)
+		}
+	}
+	wantCollects(2)
+}
+
+func TestHandlerCacheConcurrent(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	c := blockingCollector{Block: make(chan struct{}), CollectStarted: make(chan struct{}, 1)}
+	reg.MustRegister(c)
+	handler := HandlerFor(reg, HandlerOpts{CacheTTL: time.Hour})
+
+	var wg sync.WaitGroup
+	recs := make([]*httptest.ResponseRecorder, 3)
+	for i := range recs {
+		recs[i] = httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(acceptHeader, acceptTextPlain)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			handler.ServeHTTP(recs[i], req)
+		}()
+	}
+	<-c.CollectStarted
+	// Give the other requests time to wait for the collection. Requests
+	// arriving later are served from the cache, so there must be no
+	// further collection either way.
+	time.Sleep(10 * time.Millisecond)
+	close(c.Block)
+	wg.Wait()
+	for _, rec := range recs {
+		if rec.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", rec.Code, http.StatusOK)
+		}
+	}
+	select {
+	case <-c.CollectStarted:
+		t.Error("collected more than once")
+	default:
+	}
+}
+
+func TestProtoDelimEncoder(t *testing.T) {
+	mfs := []*dto.MetricFamily{
+		{
+			Name: proto.String("counter_total"),
+			Help: proto.String("A counter."),
+			Type: dto.MetricType_COUNTER.Enum(),
+			Metric: []*dto.Metric{{
+				Label:   []*dto.LabelPair{{Name: proto.String("code"), Value: proto.String("200")}},
+				Counter: &dto.Counter{Value: proto.Float64(42)},
+			}},
+		},
+		{
+			Name: proto.String("gauge.with.dots"),
+			Help: proto.String(strings.Repeat("A long help string. ", 20)),
+			Type: dto.MetricType_GAUGE.Enum(),
+			Metric: []*dto.Metric{
+				{Gauge: &dto.Gauge{Value: proto.Float64(1)}},
+				{Label: []*dto.LabelPair{{Name: proto.String("a.b"), Value: proto.String("c")}}, Gauge: &dto.Gauge{Value: proto.Float64(2)}},
+			},
+		},
+	}
+
+	for _, format := range []expfmt.Format{
+		expfmt.NewFormat(expfmt.TypeProtoDelim),
+		expfmt.NewFormat(expfmt.TypeProtoDelim).WithEscapingScheme(model.UnderscoreEscaping),
+		expfmt.NewFormat(expfmt.TypeProtoDelim).WithEscapingScheme(model.NoEscaping),
+	} {
+		var got, want bytes.Buffer
+		gotEnc := newExpfmtEncoder(&got, format)
+		wantEnc := expfmt.NewEncoder(&want, format)
+		for _, mf := range mfs {
+			if err := gotEnc.Encode(mf); err != nil {
+				t.Fatal(err)
+			}
+			if err := wantEnc.Encode(mf); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if !bytes.Equal(got.Bytes(), want.Bytes()) {
+			t.Errorf("got encoding %x for format %s, want %x", got.Bytes(), format, want.Bytes())
+		}
+	}
+}
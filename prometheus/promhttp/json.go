@@ -0,0 +1,289 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// JSONFamily is a metric family as served by the handler returned by
+// JSONHandlerFor.
+type JSONFamily struct {
+	Name string `json:"name"`
+	Help string `json:"help"`
+	// Type is the metric type as in the text format, i.e. "counter",
+	// "gauge", "summary", "untyped", "histogram", or "gaugehistogram".
+	Type   string       `json:"type"`
+	Unit   string       `json:"unit,omitempty"`
+	Series []JSONSeries `json:"series"`
+}
+
+// JSONSeries is a single metric of a JSONFamily. Sample values and counts are
+// formatted as strings, like in the Prometheus HTTP API, so that NaN and
+// infinite values as well as the float counts of histograms can be
+// represented. Depending on the type of the family, Value or the
+// histogram or summary fields are set.
+type JSONSeries struct {
+	Labels map[string]string `json:"labels"`
+	// TimestampMs is the explicit timestamp of the metric in milliseconds
+	// since the epoch, if any.
+	TimestampMs int64  `json:"timestamp_ms,omitempty"`
+	Value       string `json:"value,omitempty"`
+
+	Count     string         `json:"count,omitempty"`
+	Sum       string         `json:"sum,omitempty"`
+	Buckets   []JSONBucket   `json:"buckets,omitempty"`
+	Quantiles []JSONQuantile `json:"quantiles,omitempty"`
+
+	// The fields of native histograms. The buckets have absolute counts
+	// and are keyed by their index.
+	Schema          *int32           `json:"schema,omitempty"`
+	ZeroThreshold   string           `json:"zero_threshold,omitempty"`
+	ZeroCount       string           `json:"zero_count,omitempty"`
+	PositiveBuckets map[int32]string `json:"positive_buckets,omitempty"`
+	NegativeBuckets map[int32]string `json:"negative_buckets,omitempty"`
+}
+
+// JSONBucket is a cumulative bucket of a classic histogram.
+type JSONBucket struct {
+	UpperBound string `json:"upper_bound"`
+	Count      string `json:"count"`
+}
+
+// JSONQuantile is a quantile of a summary.
+type JSONQuantile struct {
+	Quantile string `json:"quantile"`
+	Value    string `json:"value"`
+}
+
+// JSONHandlerFor returns an uninstrumented http.Handler that serves the
+// metrics of the provided Gatherer as a JSON array of JSONFamily objects. It is
+// meant for debug UIs and other consumers that cannot parse the Prometheus
+// exposition formats; Prometheus servers cannot scrape it.
+//
+// The ErrorLog, ErrorSlog, ErrorHandling, DisableCompression,
+// OfferedCompressions, MaxRequestsInFlight, and Timeout fields of HandlerOpts
+// are honored like by HandlerFor. As the whole response is encoded before it is sent, encoding
+// errors are always reported as HTTP errors.
+func JSONHandlerFor(reg prometheus.Gatherer, opts HandlerOpts) http.Handler {
+	var inFlightSem chan struct{}
+	if opts.MaxRequestsInFlight > 0 {
+		inFlightSem = make(chan struct{}, opts.MaxRequestsInFlight)
+	}
+	var compressions []string
+	if !opts.DisableCompression {
+		offers := defaultCompressionFormats
+		if len(opts.OfferedCompressions) > 0 {
+			offers = opts.OfferedCompressions
+		}
+		for _, comp := range offers {
+			compressions = append(compressions, string(comp))
+		}
+	}
+
+	h := http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		if inFlightSem != nil {
+			select {
+			case inFlightSem <- struct{}{}: // All good, carry on.
+				defer func() { <-inFlightSem }()
+			default:
+				http.Error(rsp, fmt.Sprintf(
+					"Limit of concurrent requests reached (%d), try again later.", opts.MaxRequestsInFlight,
+				), http.StatusServiceUnavailable)
+				return
+			}
+		}
+		mfs, err := reg.Gather()
+		if err != nil {
+			logError(req.Context(), opts, "error gathering metrics", "gathering", err)
+			switch opts.ErrorHandling {
+			case PanicOnError:
+				panic(err)
+			case ContinueOnError:
+				if len(mfs) == 0 {
+					// Still report the error if no metrics have been gathered.
+					httpError(rsp, err)
+					return
+				}
+			case HTTPErrorOnError:
+				httpError(rsp, err)
+				return
+			}
+		}
+
+		families := make([]JSONFamily, 0, len(mfs))
+		for _, mf := range mfs {
+			families = append(families, jsonFamily(mf))
+		}
+		body, err := json.Marshal(families)
+		if err != nil {
+			logError(req.Context(), opts, "error encoding metric families", "encoding", err)
+			if opts.ErrorHandling == PanicOnError {
+				panic(err)
+			}
+			httpError(rsp, err)
+			return
+		}
+
+		rsp.Header().Set(contentTypeHeader, "application/json")
+		w, encodingHeader, closeWriter, err := negotiateEncodingWriter(req, rsp, compressions)
+		if err != nil {
+			logError(req.Context(), opts, "error getting writer", "compression", err)
+			w = io.Writer(rsp)
+			encodingHeader = string(Identity)
+		}
+		defer closeWriter()
+		if encodingHeader != string(Identity) {
+			rsp.Header().Set(contentEncodingHeader, encodingHeader)
+		}
+		if _, err := w.Write(body); err != nil {
+			logError(req.Context(), opts, "error sending metric families", "encoding", err)
+		}
+	})
+
+	if opts.Timeout <= 0 {
+		return h
+	}
+	return http.TimeoutHandler(h, opts.Timeout, fmt.Sprintf(
+		"Exceeded configured timeout of %v.\n",
+		opts.Timeout,
+	))
+}
+
+func jsonFamily(mf *dto.MetricFamily) JSONFamily {
+	f := JSONFamily{
+		Name:   mf.GetName(),
+		Help:   mf.GetHelp(),
+		Type:   strings.ToLower(strings.ReplaceAll(mf.GetType().String(), "_", "")),
+		Unit:   mf.GetUnit(),
+		Series: make([]JSONSeries, 0, len(mf.GetMetric())),
+	}
+	for _, m := range mf.GetMetric() {
+		s := JSONSeries{
+			Labels:      make(map[string]string, len(m.GetLabel())),
+			TimestampMs: m.GetTimestampMs(),
+		}
+		for _, lp := range m.GetLabel() {
+			s.Labels[lp.GetName()] = lp.GetValue()
+		}
+		switch {
+		case m.Counter != nil:
+			s.Value = formatJSONFloat(m.GetCounter().GetValue())
+		case m.Gauge != nil:
+			s.Value = formatJSONFloat(m.GetGauge().GetValue())
+		case m.Untyped != nil:
+			s.Value = formatJSONFloat(m.GetUntyped().GetValue())
+		case m.Summary != nil:
+			sum := m.GetSummary()
+			s.Count, s.Sum = formatJSONCount(sum.GetSampleCount()), formatJSONFloat(sum.GetSampleSum())
+			for _, q := range sum.GetQuantile() {
+				s.Quantiles = append(s.Quantiles, JSONQuantile{
+					Quantile: formatJSONFloat(q.GetQuantile()),
+					Value:    formatJSONFloat(q.GetValue()),
+				})
+			}
+		case m.Histogram != nil:
+			setJSONHistogram(&s, m.GetHistogram())
+		}
+		f.Series = append(f.Series, s)
+	}
+	return f
+}
+
+func setJSONHistogram(s *JSONSeries, h *dto.Histogram) {
+	s.Count, s.Sum = formatJSONCount(h.GetSampleCount()), formatJSONFloat(h.GetSampleSum())
+	if h.SampleCountFloat != nil {
+		s.Count = formatJSONFloat(h.GetSampleCountFloat())
+	}
+	for _, b := range h.GetBucket() {
+		bc := formatJSONCount(b.GetCumulativeCount())
+		if b.CumulativeCountFloat != nil {
+			bc = formatJSONFloat(b.GetCumulativeCountFloat())
+		}
+		s.Buckets = append(s.Buckets, JSONBucket{
+			UpperBound: formatJSONFloat(b.GetUpperBound()),
+			Count:      bc,
+		})
+	}
+	if h.Schema == nil {
+		return
+	}
+	s.Schema, s.ZeroThreshold = ptr(h.GetSchema()), formatJSONFloat(h.GetZeroThreshold())
+	s.ZeroCount = formatJSONCount(h.GetZeroCount())
+	if h.ZeroCountFloat != nil {
+		s.ZeroCount = formatJSONFloat(h.GetZeroCountFloat())
+	}
+	s.PositiveBuckets = decodeNativeBuckets(h.GetPositiveSpan(), h.GetPositiveDelta(), h.GetPositiveCount())
+	s.NegativeBuckets = decodeNativeBuckets(h.GetNegativeSpan(), h.GetNegativeDelta(), h.GetNegativeCount())
+}
+
+// decodeNativeBuckets returns the absolute counts of the native histogram
+// buckets described by spans and either delta-encoded integer counts or
+// absolute float counts.
+func decodeNativeBuckets(spans []*dto.BucketSpan, deltas []int64, counts []float64) map[int32]string {
+	if len(spans) == 0 {
+		return nil
+	}
+	buckets := map[int32]string{}
+	var (
+		idx   int32
+		i     int
+		count int64
+	)
+	for _, span := range spans {
+		idx += span.GetOffset()
+		for j := uint32(0); j < span.GetLength(); j++ {
+			switch {
+			case i < len(deltas):
+				count += deltas[i]
+				buckets[idx] = formatJSONCount(uint64(count))
+			case i < len(counts):
+				buckets[idx] = formatJSONFloat(counts[i])
+			}
+			idx++
+			i++
+		}
+	}
+	return buckets
+}
+
+// formatJSONCount formats the integer count c.
+func formatJSONCount(c uint64) string {
+	return strconv.FormatUint(c, 10)
+}
+
+// formatJSONFloat formats f like the Prometheus HTTP API does.
+func formatJSONFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func ptr[T any](v T) *T { return &v }
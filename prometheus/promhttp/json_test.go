@@ -0,0 +1,162 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestJSONHandler(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	cv := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "requests_total",
+		Help: "Requests.",
+	}, []string{"code"})
+	cv.WithLabelValues("200").Add(3)
+	g := prometheus.NewGauge(prometheus.GaugeOpts{Name: "temperature", Help: "Temperature."})
+	g.Set(math.Inf(-1))
+	h := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:                        "latency_seconds",
+		Help:                        "Latency.",
+		Buckets:                     []float64{0.5},
+		NativeHistogramBucketFactor: 2,
+	})
+	h.Observe(0.25)
+	h.Observe(0)
+	s := prometheus.NewSummary(prometheus.SummaryOpts{
+		Name:       "size_bytes",
+		Help:       "Size.",
+		Objectives: map[float64]float64{0.5: 0.05},
+	})
+	s.Observe(10)
+	reg.MustRegister(cv, g, h, s)
+
+	rec := httptest.NewRecorder()
+	JSONHandlerFor(reg, HandlerOpts{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got, want := rec.Code, http.StatusOK; got != want {
+		t.Fatalf("got status %d, want %d", got, want)
+	}
+	if got, want := rec.Header().Get(contentTypeHeader), "application/json"; got != want {
+		t.Errorf("got Content-Type %q, want %q", got, want)
+	}
+
+	var got []JSONFamily
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	for i := range got {
+		// The zero threshold of native histograms depends on the
+		// defaults of the histogram implementation.
+		for j := range got[i].Series {
+			got[i].Series[j].ZeroThreshold = ""
+		}
+	}
+	want := []JSONFamily{
+		{
+			Name: "latency_seconds", Help: "Latency.", Type: "histogram",
+			Series: []JSONSeries{{
+				Labels: map[string]string{},
+				Count:  "2", Sum: "0.25",
+				Buckets:         []JSONBucket{{UpperBound: "0.5", Count: "2"}},
+				Schema:          ptr(int32(0)),
+				ZeroCount:       "1",
+				PositiveBuckets: map[int32]string{-2: "1"},
+			}},
+		},
+		{
+			Name: "requests_total", Help: "Requests.", Type: "counter",
+			Series: []JSONSeries{{Labels: map[string]string{"code": "200"}, Value: "3"}},
+		},
+		{
+			Name: "size_bytes", Help: "Size.", Type: "summary",
+			Series: []JSONSeries{{
+				Labels: map[string]string{},
+				Count:  "1", Sum: "10",
+				Quantiles: []JSONQuantile{{Quantile: "0.5", Value: "10"}},
+			}},
+		},
+		{
+			Name: "temperature", Help: "Temperature.", Type: "gauge",
+			Series: []JSONSeries{{Labels: map[string]string{}, Value: "-Inf"}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		gotJSON, _ := json.MarshalIndent(got, "", "  ")
+		wantJSON, _ := json.MarshalIndent(want, "", "  ")
+		t.Errorf("got:\n%s\nwant:\n%s", gotJSON, wantJSON)
+	}
+}
+
+func TestJSONHandlerErrorHandling(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(prometheus.NewGauge(prometheus.GaugeOpts{Name: "up", Help: "Up."}))
+	gatherer := prometheus.Gatherers{reg, prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		return nil, errors.New("collect failed")
+	})}
+
+	rec := httptest.NewRecorder()
+	JSONHandlerFor(gatherer, HandlerOpts{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got, want := rec.Code, http.StatusInternalServerError; got != want {
+		t.Errorf("got status %d, want %d", got, want)
+	}
+
+	rec = httptest.NewRecorder()
+	JSONHandlerFor(gatherer, HandlerOpts{ErrorHandling: ContinueOnError}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got, want := rec.Code, http.StatusOK; got != want {
+		t.Errorf("got status %d, want %d", got, want)
+	}
+	var got []JSONFamily
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Name != "up" {
+		t.Errorf("unexpected families %+v", got)
+	}
+}
+
+func TestSetJSONHistogramFloatCounts(t *testing.T) {
+	h := &dto.Histogram{
+		SampleCountFloat: proto.Float64(1.5),
+		SampleSum:        proto.Float64(0.75),
+		Bucket:           []*dto.Bucket{{UpperBound: proto.Float64(1), CumulativeCountFloat: proto.Float64(1.5)}},
+		Schema:           proto.Int32(0),
+		ZeroCountFloat:   proto.Float64(0.5),
+		PositiveSpan:     []*dto.BucketSpan{{Offset: proto.Int32(1), Length: proto.Uint32(2)}},
+		PositiveCount:    []float64{0.25, 0.75},
+	}
+	var got JSONSeries
+	setJSONHistogram(&got, h)
+	want := JSONSeries{
+		Count: "1.5", Sum: "0.75",
+		Buckets:         []JSONBucket{{UpperBound: "1", Count: "1.5"}},
+		Schema:          ptr(int32(0)),
+		ZeroThreshold:   "0",
+		ZeroCount:       "0.5",
+		PositiveBuckets: map[int32]string{1: "0.25", 2: "0.75"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promhttp
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+)
+
+// systemdFirstFD is the first file descriptor passed by systemd socket
+// activation, see sd_listen_fds(3).
+const systemdFirstFD = 3
+
+// ListenUnix returns a listener on a Unix domain socket at the provided path,
+// whose file mode is set to perm, e.g. 0o660 to allow scraping only to the
+// owner and group of the socket. Serve the metrics handler on it with
+// http.Serve, e.g.:
+//
+//	l, err := promhttp.ListenUnix("/run/myapp/metrics.sock", 0o660)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	log.Fatal(http.Serve(l, promhttp.Handler()))
+//
+// A stale socket at path, e.g. left behind by a crashed process, is removed
+// first, while any other file at path results in an error. The socket is
+// removed when the listener is closed. To not expose the socket with a more
+// permissive mode until it is changed, create it in a directory that is not
+// accessible by others.
+func ListenUnix(path string, perm os.FileMode) (net.Listener, error) {
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode().Type() != fs.ModeSocket {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, perm); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// SystemdListeners returns the listeners passed by systemd socket activation,
+// in the order of the ListenStream= directives of the socket unit, e.g. for a
+// unit like:
+//
+//	[Socket]
+//	ListenStream=/run/myapp/metrics.sock
+//	SocketMode=0660
+//
+// It returns no listeners and no error if the process wasn't started by socket
+// activation. The environment variables of socket activation are unset, so
+// that they aren't inherited by child processes, and thus only the first call
+// returns the listeners.
+func SystemdListeners() ([]net.Listener, error) {
+	return systemdListeners(systemdFirstFD)
+}
+
+func systemdListeners(firstFD int) ([]net.Listener, error) {
+	pid, fds := os.Getenv("LISTEN_PID"), os.Getenv("LISTEN_FDS")
+	defer func() {
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+	}()
+	if pid == "" || fds == "" {
+		return nil, nil
+	}
+	if p, err := strconv.Atoi(pid); err != nil || p != os.Getpid() {
+		// Passed to another process.
+		return nil, nil
+	}
+	n, err := strconv.Atoi(fds)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid LISTEN_FDS %q", fds)
+	}
+
+	listeners := make([]net.Listener, 0, n)
+	var errs []error
+	for fd := firstFD; fd < firstFD+n; fd++ {
+		f := os.NewFile(uintptr(fd), "LISTEN_FD_"+strconv.Itoa(fd))
+		// FileListener duplicates the file descriptor, so that the
+		// original can be closed either way.
+		l, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("file descriptor %d: %w", fd, err))
+			continue
+		}
+		listeners = append(listeners, l)
+	}
+	if err := errors.Join(errs...); err != nil {
+		for _, l := range listeners {
+			l.Close()
+		}
+		return nil, err
+	}
+	return listeners, nil
+}
@@ -0,0 +1,131 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	defaultProbeTimeout       = 10 * time.Second
+	defaultProbeTimeoutOffset = 500 * time.Millisecond
+	scrapeTimeoutHeader       = "X-Prometheus-Scrape-Timeout-Seconds"
+)
+
+// ProbeFunc probes a target and registers the metrics describing the target
+// with reg, which is a registry created for the probe. The params are the
+// query parameters of the probe request, which can select e.g. a module to
+// probe the target with. A returned error fails the probe. The probe must
+// return once ctx is done; after that, it must not touch reg anymore.
+type ProbeFunc func(ctx context.Context, target string, params url.Values, reg prometheus.Registerer) error
+
+// ProbeOpts specifies options for ProbeHandler. The zero value of ProbeOpts is
+// a reasonable default.
+type ProbeOpts struct {
+	// Timeout is the maximum duration of a probe. If the scrape request
+	// has an X-Prometheus-Scrape-Timeout-Seconds header, as sent by
+	// Prometheus, the probe also times out TimeoutOffset before the scrape
+	// timeout, to leave time to respond. Defaults to 10s.
+	Timeout time.Duration
+	// TimeoutOffset is subtracted from the scrape timeout of the request.
+	// Defaults to 500ms.
+	TimeoutOffset time.Duration
+	// HandlerOpts are the options for serving the metrics of a probe.
+	// Errors of failed probes are logged like gathering errors.
+	HandlerOpts HandlerOpts
+}
+
+// ProbeHandler returns an http.Handler for the blackbox-style probe endpoint
+// of a multi-target exporter, i.e. an exporter that exposes the metrics of
+// the target passed with each request instead of its own, as in
+//
+//	/probe?target=example.org:443&module=tls
+//
+// For each request, the handler runs probe against the value of the "target"
+// query parameter with a fresh registry, and serves the metrics registered
+// with it, together with the probe_success gauge (1 if probe returned nil, 0
+// otherwise) and the probe_duration_seconds gauge. A probe that doesn't return
+// within its timeout fails. Requests without target are responded to with
+// 400 Bad Request.
+//
+// The handler is not instrumented; use the instrumentation middlewares of this
+// package for that, or HandlerOpts.Registry for the errors of serving the
+// metrics.
+func ProbeHandler(probe ProbeFunc, opts ProbeOpts) http.Handler {
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultProbeTimeout
+	}
+	if opts.TimeoutOffset <= 0 {
+		opts.TimeoutOffset = defaultProbeTimeoutOffset
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		params := req.URL.Query()
+		target := params.Get("target")
+		if target == "" {
+			http.Error(w, "target parameter is missing", http.StatusBadRequest)
+			return
+		}
+		timeout := opts.Timeout
+		if v := req.Header.Get(scrapeTimeoutHeader); v != "" {
+			seconds, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid %s header: %v", scrapeTimeoutHeader, err), http.StatusBadRequest)
+				return
+			}
+			if d := time.Duration(seconds*float64(time.Second)) - opts.TimeoutOffset; d > 0 && d < timeout {
+				timeout = d
+			}
+		}
+
+		successGauge := prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "probe_success",
+			Help: "Whether the probe succeeded.",
+		})
+		durationGauge := prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "probe_duration_seconds",
+			Help: "Duration of the probe in seconds.",
+		})
+		reg := prometheus.NewRegistry()
+		reg.MustRegister(successGauge, durationGauge)
+
+		ctx, cancel := context.WithTimeout(req.Context(), timeout)
+		defer cancel()
+		start := time.Now()
+		done := make(chan error, 1)
+		go func() {
+			done <- probe(ctx, target, params, reg)
+		}()
+		var err error
+		select {
+		case err = <-done:
+		case <-ctx.Done():
+			err = fmt.Errorf("probe timed out after %v: %w", timeout, ctx.Err())
+		}
+		durationGauge.Set(time.Since(start).Seconds())
+		if err != nil {
+			logError(req.Context(), opts.HandlerOpts, "error probing target "+target, "probe", err)
+		} else {
+			successGauge.Set(1)
+		}
+
+		HandlerFor(reg, opts.HandlerOpts).ServeHTTP(w, req)
+	})
+}
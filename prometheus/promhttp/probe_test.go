@@ -0,0 +1,117 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promhttp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestProbeHandler(t *testing.T) {
+	probe := func(ctx context.Context, target string, params url.Values, reg prometheus.Registerer) error {
+		switch target {
+		case "slow":
+			<-ctx.Done()
+			return ctx.Err()
+		case "broken":
+			return errors.New("connection refused")
+		}
+		g := prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "probe_module_info",
+			Help:        "The module of the probe.",
+			ConstLabels: prometheus.Labels{"module": params.Get("module")},
+		})
+		g.Set(1)
+		reg.MustRegister(g)
+		return nil
+	}
+	var logged bytes.Buffer
+	h := ProbeHandler(probe, ProbeOpts{
+		Timeout:     50 * time.Millisecond,
+		HandlerOpts: HandlerOpts{ErrorLog: log.New(&logged, "", 0)},
+	})
+
+	for _, tc := range []struct {
+		query   string
+		header  string
+		code    int
+		want    []string
+		errLogs int
+	}{
+		{
+			query: "target=ok&module=http",
+			code:  http.StatusOK,
+			want:  []string{"probe_success 1", `probe_module_info{module="http"} 1`, "probe_duration_seconds "},
+		},
+		{
+			query:   "target=broken",
+			code:    http.StatusOK,
+			want:    []string{"probe_success 0"},
+			errLogs: 1,
+		},
+		{
+			query:   "target=slow",
+			code:    http.StatusOK,
+			want:    []string{"probe_success 0"},
+			errLogs: 1,
+		},
+		{
+			query:   "target=slow",
+			header:  "0.51",
+			code:    http.StatusOK,
+			want:    []string{"probe_success 0"},
+			errLogs: 1,
+		},
+		{
+			query: "module=http",
+			code:  http.StatusBadRequest,
+		},
+		{
+			query:  "target=ok",
+			header: "soon",
+			code:   http.StatusBadRequest,
+		},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			logged.Reset()
+			req := httptest.NewRequest(http.MethodGet, "/probe?"+tc.query, nil)
+			if tc.header != "" {
+				req.Header.Set("X-Prometheus-Scrape-Timeout-Seconds", tc.header)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tc.code {
+				t.Fatalf("got status %d, want %d", rec.Code, tc.code)
+			}
+			for _, w := range tc.want {
+				if !strings.Contains(rec.Body.String(), w) {
+					t.Errorf("response lacks %q:\n%s", w, rec.Body)
+				}
+			}
+			if got := strings.Count(logged.String(), "error probing target"); got != tc.errLogs {
+				t.Errorf("got %d logged errors, want %d:\n%s", got, tc.errLogs, &logged)
+			}
+		})
+	}
+}
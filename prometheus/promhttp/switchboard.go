@@ -0,0 +1,275 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Switchboard is a prometheus.Gatherer that allows to disable and enable
+// metric families and collectors of another Gatherer at runtime, e.g. to shed
+// the cardinality of an exploding metric in an emergency without a restart.
+// Serve the metrics with HandlerFor(switchboard, opts) and the switchboard
+// itself with its Handler on an admin endpoint.
+//
+// Disabled metric families are still collected but dropped upon gathering.
+// To save the cost of collecting expensive metrics, too, wrap their
+// Collector with the Collector method, which turns the Collector into a no-op
+// while disabled. It is safe to use a Switchboard from multiple goroutines.
+type Switchboard struct {
+	g prometheus.Gatherer
+
+	mtx        sync.RWMutex
+	families   map[string]bool // Disabled metric families.
+	collectors map[string]*switchedCollector
+}
+
+// NewSwitchboard returns a Switchboard for the provided Gatherer with all
+// metric families and collectors enabled.
+func NewSwitchboard(g prometheus.Gatherer) *Switchboard {
+	return &Switchboard{
+		g:          g,
+		families:   map[string]bool{},
+		collectors: map[string]*switchedCollector{},
+	}
+}
+
+// Gather implements prometheus.Gatherer. It omits the disabled metric
+// families.
+func (s *Switchboard) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := s.g.Gather()
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	if len(s.families) == 0 {
+		return mfs, err
+	}
+	enabled := make([]*dto.MetricFamily, 0, len(mfs))
+	for _, mf := range mfs {
+		if !s.families[mf.GetName()] {
+			enabled = append(enabled, mf)
+		}
+	}
+	return enabled, err
+}
+
+// Collector returns a Collector that collects like c while enabled and
+// doesn't collect anything while disabled. Register it instead of c. The name
+// identifies the Collector in DisableCollector, EnableCollector, and the
+// Handler. Calling Collector with a name used before panics.
+func (s *Switchboard) Collector(name string, c prometheus.Collector) prometheus.Collector {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if _, ok := s.collectors[name]; ok {
+		panic(fmt.Errorf("collector %q already added to the switchboard", name))
+	}
+	sc := &switchedCollector{Collector: c}
+	s.collectors[name] = sc
+	return sc
+}
+
+// DisableFamily disables the metric family with the provided name. The name
+// doesn't need to be known yet.
+func (s *Switchboard) DisableFamily(name string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.families[name] = true
+}
+
+// EnableFamily enables the metric family with the provided name again.
+func (s *Switchboard) EnableFamily(name string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	delete(s.families, name)
+}
+
+// DisableCollector disables the Collector added with the provided name. It
+// returns false if there is no such Collector.
+func (s *Switchboard) DisableCollector(name string) bool {
+	return s.setCollector(name, true)
+}
+
+// EnableCollector enables the Collector added with the provided name again.
+// It returns false if there is no such Collector.
+func (s *Switchboard) EnableCollector(name string) bool {
+	return s.setCollector(name, false)
+}
+
+func (s *Switchboard) setCollector(name string, disabled bool) bool {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	sc, ok := s.collectors[name]
+	if !ok {
+		return false
+	}
+	sc.mtx.Lock()
+	sc.disabled = disabled
+	sc.mtx.Unlock()
+	return true
+}
+
+// SwitchboardState is the state of a Switchboard as served by its Handler.
+type SwitchboardState struct {
+	Families   []SwitchboardFamily    `json:"families"`
+	Collectors []SwitchboardCollector `json:"collectors"`
+}
+
+// SwitchboardFamily is a metric family in a SwitchboardState. Disabled
+// families are listed even if they are currently not collected, with Type,
+// Help, and Series left empty.
+type SwitchboardFamily struct {
+	Name     string `json:"name"`
+	Type     string `json:"type,omitempty"`
+	Help     string `json:"help,omitempty"`
+	Series   int    `json:"series"`
+	Disabled bool   `json:"disabled"`
+}
+
+// SwitchboardCollector is a Collector in a SwitchboardState.
+type SwitchboardCollector struct {
+	Name     string `json:"name"`
+	Disabled bool   `json:"disabled"`
+}
+
+// State returns the metric families gathered from the underlying Gatherer
+// and the Collectors added to the Switchboard, sorted by name, with their
+// disabled state. A gathering error is ignored, as the successfully gathered
+// metric families are listed anyway.
+func (s *Switchboard) State() SwitchboardState {
+	mfs, _ := s.g.Gather()
+
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	state := SwitchboardState{
+		Families:   []SwitchboardFamily{},
+		Collectors: []SwitchboardCollector{},
+	}
+	seen := map[string]bool{}
+	for _, mf := range mfs {
+		seen[mf.GetName()] = true
+		state.Families = append(state.Families, SwitchboardFamily{
+			Name:     mf.GetName(),
+			Type:     mf.GetType().String(),
+			Help:     mf.GetHelp(),
+			Series:   len(mf.GetMetric()),
+			Disabled: s.families[mf.GetName()],
+		})
+	}
+	for name := range s.families {
+		if !seen[name] {
+			state.Families = append(state.Families, SwitchboardFamily{Name: name, Disabled: true})
+		}
+	}
+	sort.Slice(state.Families, func(i, j int) bool { return state.Families[i].Name < state.Families[j].Name })
+	for name, sc := range s.collectors {
+		sc.mtx.RLock()
+		state.Collectors = append(state.Collectors, SwitchboardCollector{Name: name, Disabled: sc.disabled})
+		sc.mtx.RUnlock()
+	}
+	sort.Slice(state.Collectors, func(i, j int) bool { return state.Collectors[i].Name < state.Collectors[j].Name })
+	return state
+}
+
+// Handler returns an http.Handler to administer the Switchboard. A GET request
+// is responded to with the SwitchboardState as JSON. A POST request with the
+// form parameter "action" set to "disable" or "enable" and either the
+// parameter "family" or "collector" set to a name changes the state
+// accordingly and is responded to with the new state, e.g.:
+//
+//	curl -X POST -d action=disable -d family=http_requests_total http://localhost:8080/admin/metrics
+//
+// Every request is only handled if authorize returns true for it, and is
+// responded to with 403 Forbidden otherwise. If authorize is nil, all
+// requests are forbidden, so that the handler is never exposed
+// unintentionally.
+func (s *Switchboard) Handler(authorize func(*http.Request) bool) http.Handler {
+	return http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		if authorize == nil || !authorize(req) {
+			http.Error(rsp, "Forbidden", http.StatusForbidden)
+			return
+		}
+		switch req.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			if code, err := s.handlePost(req); err != nil {
+				http.Error(rsp, err.Error(), code)
+				return
+			}
+		default:
+			rsp.Header().Set("Allow", "GET, POST")
+			http.Error(rsp, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		rsp.Header().Set(contentTypeHeader, "application/json")
+		json.NewEncoder(rsp).Encode(s.State())
+	})
+}
+
+// handlePost applies the change requested by a POST request to the Handler.
+// It returns the HTTP status code to respond with upon an error.
+func (s *Switchboard) handlePost(req *http.Request) (int, error) {
+	var disable bool
+	switch action := req.FormValue("action"); action {
+	case "disable":
+		disable = true
+	case "enable":
+	default:
+		return http.StatusBadRequest, fmt.Errorf("invalid action %q, want \"disable\" or \"enable\"", action)
+	}
+	family, collector := req.FormValue("family"), req.FormValue("collector")
+	switch {
+	case family != "" && collector != "", family == "" && collector == "":
+		return http.StatusBadRequest, errors.New("exactly one of the parameters \"family\" and \"collector\" required")
+	case family != "":
+		if disable {
+			s.DisableFamily(family)
+		} else {
+			s.EnableFamily(family)
+		}
+	default:
+		setCollector := s.EnableCollector
+		if disable {
+			setCollector = s.DisableCollector
+		}
+		if !setCollector(collector) {
+			return http.StatusNotFound, fmt.Errorf("unknown collector %q", collector)
+		}
+	}
+	return 0, nil
+}
+
+// switchedCollector is a Collector that can be disabled by a Switchboard.
+type switchedCollector struct {
+	prometheus.Collector
+
+	mtx      sync.RWMutex
+	disabled bool
+}
+
+func (c *switchedCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mtx.RLock()
+	disabled := c.disabled
+	c.mtx.RUnlock()
+	if !disabled {
+		c.Collector.Collect(ch)
+	}
+}
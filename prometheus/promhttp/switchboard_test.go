@@ -0,0 +1,152 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestSwitchboard(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	s := NewSwitchboard(reg)
+
+	var collected int
+	reg.MustRegister(s.Collector("expensive", prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "expensive",
+		Help: "Expensive to collect.",
+	}, func() float64 {
+		collected++
+		return 1
+	})))
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "requests_total",
+		Help: "Requests.",
+	}, []string{"path"})
+	requests.WithLabelValues("/a").Inc()
+	requests.WithLabelValues("/b").Inc()
+	reg.MustRegister(requests)
+
+	handler := s.Handler(func(r *http.Request) bool {
+		return r.Header.Get("Authorization") == "Bearer secret"
+	})
+	do := func(method string, form url.Values, authorized bool) (int, SwitchboardState) {
+		t.Helper()
+		req := httptest.NewRequest(method, "/", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if authorized {
+			req.Header.Set("Authorization", "Bearer secret")
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		var state SwitchboardState
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &state); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return rec.Code, state
+	}
+	names := func() []string {
+		t.Helper()
+		mfs, err := s.Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, mf := range mfs {
+			names = append(names, mf.GetName())
+		}
+		return names
+	}
+
+	if code, _ := do(http.MethodGet, nil, false); code != http.StatusForbidden {
+		t.Errorf("got status %d for an unauthorized request, want %d", code, http.StatusForbidden)
+	}
+	code, state := do(http.MethodGet, nil, true)
+	if code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", code, http.StatusOK)
+	}
+	want := SwitchboardState{
+		Families: []SwitchboardFamily{
+			{Name: "expensive", Type: "GAUGE", Help: "Expensive to collect.", Series: 1},
+			{Name: "requests_total", Type: "COUNTER", Help: "Requests.", Series: 2},
+		},
+		Collectors: []SwitchboardCollector{{Name: "expensive"}},
+	}
+	if !reflect.DeepEqual(state, want) {
+		t.Errorf("got state %+v, want %+v", state, want)
+	}
+
+	// Disable the family and the collector.
+	if code, _ := do(http.MethodPost, url.Values{"action": {"disable"}, "family": {"requests_total"}}, true); code != http.StatusOK {
+		t.Fatalf("got status %d disabling a family, want %d", code, http.StatusOK)
+	}
+	if code, _ := do(http.MethodPost, url.Values{"action": {"disable"}, "collector": {"expensive"}}, true); code != http.StatusOK {
+		t.Fatalf("got status %d disabling a collector, want %d", code, http.StatusOK)
+	}
+	collected = 0
+	if got := names(); len(got) != 0 {
+		t.Errorf("got metric families %v with everything disabled, want none", got)
+	}
+	if collected != 0 {
+		t.Error("disabled collector collected")
+	}
+	_, state = do(http.MethodGet, nil, true)
+	want = SwitchboardState{
+		Families: []SwitchboardFamily{
+			{Name: "requests_total", Type: "COUNTER", Help: "Requests.", Series: 2, Disabled: true},
+		},
+		Collectors: []SwitchboardCollector{{Name: "expensive", Disabled: true}},
+	}
+	if !reflect.DeepEqual(state, want) {
+		t.Errorf("got state %+v, want %+v", state, want)
+	}
+
+	// Enable them again.
+	do(http.MethodPost, url.Values{"action": {"enable"}, "family": {"requests_total"}}, true)
+	do(http.MethodPost, url.Values{"action": {"enable"}, "collector": {"expensive"}}, true)
+	if got, want := names(), []string{"expensive", "requests_total"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got metric families %v, want %v", got, want)
+	}
+
+	for _, tc := range []struct {
+		form url.Values
+		want int
+	}{
+		{url.Values{"action": {"drop"}, "family": {"x"}}, http.StatusBadRequest},
+		{url.Values{"action": {"disable"}}, http.StatusBadRequest},
+		{url.Values{"action": {"disable"}, "family": {"x"}, "collector": {"y"}}, http.StatusBadRequest},
+		{url.Values{"action": {"disable"}, "collector": {"unknown"}}, http.StatusNotFound},
+	} {
+		if code, _ := do(http.MethodPost, tc.form, true); code != tc.want {
+			t.Errorf("got status %d for %v, want %d", code, tc.form, tc.want)
+		}
+	}
+}
+
+func TestSwitchboardHandlerWithoutAuthorize(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewSwitchboard(prometheus.NewRegistry()).Handler(nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+const (
+	pushTimeMetric        = "push_time_seconds"
+	pushFailureTimeMetric = "push_failure_time_seconds"
+)
+
+var errGroupingNoJob = errors.New("grouping key has no job label")
+
+// Admin is a client for the management API of a Pushgateway. Use NewAdmin to
+// create one and configure it with its methods. Unlike a Pusher, an Admin is
+// not bound to a particular grouping key, which makes it suitable for cleanup
+// jobs that inspect and delete the groups pushed by others.
+type Admin struct {
+	url string
+	httpConfig
+}
+
+// Group is a group of metrics on the Pushgateway as reported by Admin.Groups.
+type Group struct {
+	// Labels is the grouping key of the group, including the job label.
+	Labels map[string]string
+	// LastPushSuccessful is false if the last push or add to the group
+	// failed.
+	LastPushSuccessful bool
+	// LastPushTime is the time of the last successful push or add to the
+	// group. It is the zero time if there was none.
+	LastPushTime time.Time
+	// LastFailedPushTime is the time of the last failed push or add to the
+	// group. It is the zero time if there was none.
+	LastFailedPushTime time.Time
+	// MetricNames are the sorted names of the metrics in the group,
+	// excluding push_time_seconds and push_failure_time_seconds.
+	MetricNames []string
+}
+
+// NewAdmin creates a new Admin for the Pushgateway at the provided URL. As with
+// New, you can use just host:port or ip:port as url, in which case “http://” is
+// added automatically. Do not include the “/metrics/jobs/…” part.
+func NewAdmin(url string) *Admin {
+	return &Admin{
+		url:        normalizeURL(url),
+		httpConfig: httpConfig{client: &http.Client{}},
+	}
+}
+
+// Client sets a custom HTTP client for the Admin. For convenience, this method
+// returns a pointer to the Admin itself.
+func (a *Admin) Client(c HTTPDoer) *Admin {
+	a.client = c
+	return a
+}
+
+// Header sets a custom HTTP header for the Admin's client. For convenience,
+// this method returns a pointer to the Admin itself.
+func (a *Admin) Header(header http.Header) *Admin {
+	a.header = header
+	return a
+}
+
+// BasicAuth configures the Admin to use HTTP Basic Authentication with the
+// provided username and password. For convenience, this method returns a
+// pointer to the Admin itself.
+func (a *Admin) BasicAuth(username, password string) *Admin {
+	a.auth = basicAuth{username: username, password: password}
+	return a
+}
+
+// Groups returns all groups currently stored on the Pushgateway, sorted by
+// their grouping key.
+func (a *Admin) Groups(ctx context.Context) ([]Group, error) {
+	body, err := a.do(ctx, http.MethodGet, a.url+"/api/v1/metrics", http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Status string                       `json:"status"`
+		Data   []map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("decoding groups: %w", err)
+	}
+	if result.Status != "success" {
+		return nil, fmt.Errorf("unexpected status %q while listing groups", result.Status)
+	}
+
+	groups := make([]Group, 0, len(result.Data))
+	for _, data := range result.Data {
+		g, err := parseGroup(data)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return model.LabelsToSignature(groups[i].Labels) < model.LabelsToSignature(groups[j].Labels)
+	})
+	return groups, nil
+}
+
+// DeleteGroup deletes the group with the provided grouping key, which must
+// contain the job label. Deleting a group that doesn't exist is not an error.
+func (a *Admin) DeleteGroup(ctx context.Context, grouping map[string]string) error {
+	job, ok := grouping["job"]
+	if !ok {
+		return errGroupingNoJob
+	}
+	if job == "" {
+		return errJobEmpty
+	}
+	labels := make(map[string]string, len(grouping)-1)
+	for ln, lv := range grouping {
+		if ln == "job" {
+			continue
+		}
+		if !model.LabelName(ln).IsValid() {
+			return fmt.Errorf("grouping label has invalid name: %s", ln)
+		}
+		labels[ln] = lv
+	}
+	_, err := a.do(ctx, http.MethodDelete, groupingURL(a.url, job, labels), http.StatusAccepted)
+	return err
+}
+
+// Wipe deletes all groups on the Pushgateway. The Pushgateway must have been
+// started with the admin API enabled (--web.enable-admin-api).
+func (a *Admin) Wipe(ctx context.Context) error {
+	_, err := a.do(ctx, http.MethodPut, a.url+"/api/v1/admin/wipe", http.StatusAccepted)
+	return err
+}
+
+// do sends a request without body and returns the response body if the
+// response has the expected status code.
+func (a *Admin) do(ctx context.Context, method, url string, wantCode int) ([]byte, error) {
+	req, err := a.newRequest(ctx, method, url, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if resp.StatusCode != wantCode {
+		return nil, fmt.Errorf("unexpected status code %d while requesting %s: %s", resp.StatusCode, url, body)
+	}
+	return body, err
+}
+
+// parseGroup converts a group as returned by the Pushgateway API. Apart from the
+// labels and the push status, each entry is a metric family keyed by name.
+func parseGroup(data map[string]json.RawMessage) (Group, error) {
+	g := Group{Labels: map[string]string{}}
+	for name, raw := range data {
+		var err error
+		switch name {
+		case "labels":
+			err = json.Unmarshal(raw, &g.Labels)
+		case "last_push_successful":
+			err = json.Unmarshal(raw, &g.LastPushSuccessful)
+		case pushTimeMetric:
+			g.LastPushTime, err = parseTimestampMetric(raw)
+		case pushFailureTimeMetric:
+			g.LastFailedPushTime, err = parseTimestampMetric(raw)
+		default:
+			g.MetricNames = append(g.MetricNames, name)
+		}
+		if err != nil {
+			return Group{}, fmt.Errorf("decoding group field %q: %w", name, err)
+		}
+	}
+	sort.Strings(g.MetricNames)
+	return g, nil
+}
+
+// parseTimestampMetric extracts the time from one of the push_time_seconds or
+// push_failure_time_seconds metric families. A value of zero (meaning “never”)
+// results in the zero time.
+func parseTimestampMetric(raw json.RawMessage) (time.Time, error) {
+	var mf struct {
+		Metrics []struct {
+			Value string `json:"value"`
+		} `json:"metrics"`
+	}
+	if err := json.Unmarshal(raw, &mf); err != nil {
+		return time.Time{}, err
+	}
+	if len(mf.Metrics) == 0 {
+		return time.Time{}, nil
+	}
+	v, err := strconv.ParseFloat(mf.Metrics[0].Value, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if v == 0 {
+		return time.Time{}, nil
+	}
+	sec, frac := math.Modf(v)
+	return time.Unix(int64(sec), int64(frac*1e9)), nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const adminGroupsResponse = `{
+  "status": "success",
+  "data": [
+    {
+      "labels": {"job": "batch", "instance": "b"},
+      "last_push_successful": false,
+      "push_time_seconds": {"type": "GAUGE", "metrics": [{"labels": {"job": "batch", "instance": "b"}, "value": "0"}]},
+      "push_failure_time_seconds": {"type": "GAUGE", "metrics": [{"labels": {"job": "batch", "instance": "b"}, "value": "1700000001.5"}]}
+    },
+    {
+      "labels": {"job": "batch", "instance": "a"},
+      "last_push_successful": true,
+      "push_time_seconds": {"type": "GAUGE", "metrics": [{"labels": {"job": "batch", "instance": "a"}, "value": "1.7e+09"}]},
+      "push_failure_time_seconds": {"type": "GAUGE", "metrics": [{"labels": {"job": "batch", "instance": "a"}, "value": "0"}]},
+      "requests_total": {"type": "COUNTER", "metrics": [{"labels": {"job": "batch", "instance": "a"}, "value": "3"}]},
+      "last_run_seconds": {"type": "GAUGE", "metrics": [{"labels": {"job": "batch", "instance": "a"}, "value": "42"}]}
+    }
+  ]
+}`
+
+func TestAdmin(t *testing.T) {
+	var (
+		lastMethod string
+		lastPath   string
+	)
+	pgw := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			lastMethod = r.Method
+			lastPath = r.URL.EscapedPath()
+			if user, pass, ok := r.BasicAuth(); !ok || user != "admin" || pass != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			switch {
+			case r.Method == http.MethodGet && lastPath == "/api/v1/metrics":
+				w.Write([]byte(adminGroupsResponse))
+			case r.Method == http.MethodDelete || lastPath == "/api/v1/admin/wipe":
+				w.WriteHeader(http.StatusAccepted)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}),
+	)
+	defer pgw.Close()
+
+	ctx := context.Background()
+	admin := NewAdmin(pgw.URL+"/").BasicAuth("admin", "secret")
+
+	groups, err := admin.Groups(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Group{
+		{
+			Labels:             map[string]string{"job": "batch", "instance": "a"},
+			LastPushSuccessful: true,
+			LastPushTime:       time.Unix(1700000000, 0),
+			MetricNames:        []string{"last_run_seconds", "requests_total"},
+		},
+		{
+			Labels:             map[string]string{"job": "batch", "instance": "b"},
+			LastFailedPushTime: time.Unix(1700000001, 5e8),
+		},
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("got groups %+v, want %+v", groups, want)
+	}
+
+	if err := admin.DeleteGroup(ctx, map[string]string{"job": "batch", "instance": "a/b"}); err != nil {
+		t.Fatal(err)
+	}
+	if lastMethod != http.MethodDelete || lastPath != "/metrics/job/batch/instance@base64/YS9i" {
+		t.Errorf("got %s %s, want DELETE of the group", lastMethod, lastPath)
+	}
+	if err := admin.DeleteGroup(ctx, map[string]string{"instance": "a"}); err != errGroupingNoJob {
+		t.Errorf("got error %v, want %v", err, errGroupingNoJob)
+	}
+	if err := admin.DeleteGroup(ctx, map[string]string{"job": "batch", "": "a"}); err == nil {
+		t.Error("delete with invalid grouping label name succeeded")
+	}
+
+	if err := admin.Wipe(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if lastMethod != http.MethodPut || lastPath != "/api/v1/admin/wipe" {
+		t.Errorf("got %s %s, want PUT of the wipe endpoint", lastMethod, lastPath)
+	}
+
+	if _, err := NewAdmin(pgw.URL).Groups(ctx); err == nil {
+		t.Error("unauthorized request succeeded")
+	}
+}
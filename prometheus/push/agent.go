@@ -0,0 +1,454 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/common/expfmt"
+	"google.golang.org/protobuf/proto"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+const (
+	defaultMaxBufferSize = 64 << 20
+
+	snapshotSuffix = ".snapshot"
+	tmpSuffix      = ".tmp"
+)
+
+var (
+	errAgentStarted   = errors.New("agent already started")
+	errSnapshotTooBig = errors.New("snapshot exceeds the maximum buffer size")
+)
+
+// Agent scrapes the Gatherers of a Pusher in regular intervals and pushes the
+// metrics, like a PeriodicPusher. In addition, it buffers the snapshots it
+// failed to push in a directory and replays them, oldest first, once the
+// receiver is reachable again, which makes it suitable for devices with
+// unreliable network connections. The buffer survives restarts of the
+// process. Use NewAgent to create one, configure it with its methods, and
+// then call Start. Call Stop to stop it.
+//
+// With a Pusher configured for remote write (see Pusher.RemoteWrite), buffered
+// snapshots keep the time of their scrape as sample timestamps. As a
+// Pushgateway only keeps the latest metrics of a group anyway (and rejects
+// samples with timestamps), only the latest snapshot is buffered for a
+// Pushgateway, and it is pushed with Pusher.Push semantics.
+//
+// Snapshots the receiver rejects with a 4xx status code other than 429 are
+// dropped, as retrying them is pointless.
+type Agent struct {
+	error error
+
+	pusher        *Pusher
+	interval      time.Duration
+	dir           string
+	maxBufferSize int64
+	onError       func(error)
+
+	bufMu  sync.Mutex
+	buffer *diskBuffer
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewAgent creates a new Agent that pushes with the provided Pusher every
+// interval (which must be positive) and buffers snapshots in the provided
+// directory, which is created if needed. The directory must not be used by
+// anything else, in particular not by another Agent.
+func NewAgent(pusher *Pusher, interval time.Duration, dir string) *Agent {
+	a := &Agent{
+		pusher:        pusher,
+		interval:      interval,
+		dir:           dir,
+		maxBufferSize: defaultMaxBufferSize,
+	}
+	switch {
+	case interval <= 0:
+		a.error = errors.New("push interval must be positive")
+	case dir == "":
+		a.error = errors.New("buffer directory must not be empty")
+	}
+	return a
+}
+
+// MaxBufferSize configures the maximum total size in bytes of the buffered
+// snapshots. If buffering a snapshot exceeds it, the oldest snapshots are
+// dropped. The default is 64MiB. For convenience, this method returns a pointer
+// to the Agent itself.
+func (a *Agent) MaxBufferSize(size int64) *Agent {
+	a.maxBufferSize = size
+	return a
+}
+
+// ErrorHandler configures a function that is called with every error of the
+// Agent, e.g. failed pushes or dropped snapshots. By default, errors are
+// ignored. For convenience, this method returns a pointer to the Agent
+// itself.
+func (a *Agent) ErrorHandler(fn func(error)) *Agent {
+	a.onError = fn
+	return a
+}
+
+// Buffered returns the number of snapshots currently buffered.
+func (a *Agent) Buffered() int {
+	a.bufMu.Lock()
+	defer a.bufMu.Unlock()
+	if a.buffer == nil {
+		return 0
+	}
+	return len(a.buffer.files)
+}
+
+// Start opens the buffer and starts scraping and pushing in a background
+// goroutine. The first scrape happens immediately, and snapshots buffered by
+// a previous run are replayed after it. Start returns an error if the Agent is
+// already running, if the buffer can't be opened, or the first error
+// encountered by any method call of the Agent or the Pusher.
+func (a *Agent) Start() error {
+	if a.error != nil {
+		return a.error
+	}
+	if err := a.pusher.Error(); err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.cancel != nil {
+		return errAgentStarted
+	}
+	buffer, err := openDiskBuffer(a.dir, a.maxBufferSize)
+	if err != nil {
+		return err
+	}
+	a.bufMu.Lock()
+	a.buffer = buffer
+	a.bufMu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+	a.done = make(chan struct{})
+	go a.run(ctx, a.done)
+	return nil
+}
+
+// Stop stops the Agent and waits for the background goroutine to exit. A push
+// in progress is canceled, and its snapshot is buffered. Stop is a no-op if
+// the Agent isn't running. A stopped Agent can be started again.
+func (a *Agent) Stop() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.cancel == nil {
+		return
+	}
+	a.cancel()
+	<-a.done
+	a.cancel, a.done = nil, nil
+}
+
+func (a *Agent) run(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+	for {
+		a.cycle(ctx)
+
+		t := time.NewTimer(a.interval)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// cycle scrapes once and pushes the snapshot, or buffers it if that fails or
+// older snapshots are still buffered. In the latter case, it then replays the
+// buffered snapshots.
+func (a *Agent) cycle(ctx context.Context) {
+	scraped := time.Now()
+	gatherCtx, cancel := a.pusher.withTimeout(ctx)
+	mfs, err := a.pusher.gather(gatherCtx)
+	cancel()
+	if err == nil {
+		err = a.pusher.checkGroupingLabels(mfs)
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			a.handleError(fmt.Errorf("scraping failed: %w", err))
+		}
+		return
+	}
+
+	a.bufMu.Lock()
+	defer a.bufMu.Unlock()
+
+	if len(a.buffer.files) == 0 {
+		err := a.pushSnapshot(ctx, mfs)
+		if err == nil {
+			return
+		}
+		if rejected(err) {
+			a.handleError(fmt.Errorf("dropping rejected snapshot: %w", err))
+			return
+		}
+		if ctx.Err() == nil {
+			a.handleError(err)
+		}
+		a.bufferSnapshot(mfs, scraped)
+		return
+	}
+	a.bufferSnapshot(mfs, scraped)
+	a.replay(ctx)
+}
+
+// bufferSnapshot appends a snapshot scraped at the provided time to the
+// buffer.
+func (a *Agent) bufferSnapshot(mfs []*dto.MetricFamily, scraped time.Time) {
+	if a.pusher.remoteWrite {
+		mfs = withTimestamps(mfs, scraped)
+	} else if err := a.buffer.clear(); err != nil {
+		a.handleError(fmt.Errorf("clearing buffer: %w", err))
+	}
+	dropped, err := a.buffer.append(mfs)
+	if err != nil {
+		a.handleError(fmt.Errorf("buffering snapshot: %w", err))
+	}
+	if dropped > 0 {
+		a.handleError(fmt.Errorf("buffer full, dropped %d oldest snapshots", dropped))
+	}
+}
+
+// replay pushes the buffered snapshots, oldest first, until a push fails.
+func (a *Agent) replay(ctx context.Context) {
+	for len(a.buffer.files) > 0 && ctx.Err() == nil {
+		mfs, err := a.buffer.oldest()
+		if err != nil {
+			a.handleError(fmt.Errorf("dropping unreadable snapshot: %w", err))
+		} else if err := a.pushSnapshot(ctx, mfs); err != nil {
+			if !rejected(err) {
+				if ctx.Err() == nil {
+					a.handleError(err)
+				}
+				return
+			}
+			a.handleError(fmt.Errorf("dropping rejected snapshot: %w", err))
+		}
+		if err := a.buffer.dropOldest(); err != nil {
+			a.handleError(fmt.Errorf("removing snapshot: %w", err))
+			return
+		}
+	}
+}
+
+func (a *Agent) pushSnapshot(ctx context.Context, mfs []*dto.MetricFamily) error {
+	return a.pusher.pushWith(ctx, http.MethodPut, func(context.Context) ([]*dto.MetricFamily, error) {
+		return mfs, nil
+	})
+}
+
+func (a *Agent) handleError(err error) {
+	if a.onError != nil {
+		a.onError(err)
+	}
+}
+
+// rejected reports whether err is a response of the receiver that indicates
+// that pushing the same metrics again will fail, too.
+func rejected(err error) bool {
+	var se *statusError
+	return errors.As(err, &se) && se.code/100 == 4 && se.code != http.StatusTooManyRequests
+}
+
+// withTimestamps returns copies of the metric families in which all metrics
+// without explicit timestamp have the provided one.
+func withTimestamps(mfs []*dto.MetricFamily, ts time.Time) []*dto.MetricFamily {
+	stamped := make([]*dto.MetricFamily, 0, len(mfs))
+	for _, mf := range mfs {
+		mf = proto.Clone(mf).(*dto.MetricFamily)
+		for _, m := range mf.GetMetric() {
+			if m.TimestampMs == nil {
+				m.TimestampMs = proto.Int64(ts.UnixMilli())
+			}
+		}
+		stamped = append(stamped, mf)
+	}
+	return stamped
+}
+
+// diskBuffer is a bounded FIFO of snapshots, each stored in a file of
+// length-delimited protobuf metric families, named by a sequence number.
+type diskBuffer struct {
+	dir     string
+	maxSize int64
+	seq     uint64
+	// files are the buffered snapshots, oldest first.
+	files []bufferedFile
+	size  int64
+}
+
+type bufferedFile struct {
+	name string
+	size int64
+}
+
+// openDiskBuffer opens the buffer in dir, creating dir if needed. Snapshots
+// from a previous run are kept, incomplete ones are removed.
+func openDiskBuffer(dir string, maxSize int64) (*diskBuffer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	b := &diskBuffer{dir: dir, maxSize: maxSize}
+	for _, e := range entries {
+		name := e.Name()
+		switch {
+		case strings.HasSuffix(name, tmpSuffix):
+			if err := os.Remove(filepath.Join(dir, name)); err != nil {
+				return nil, err
+			}
+		case strings.HasSuffix(name, snapshotSuffix):
+			seq, err := strconv.ParseUint(strings.TrimSuffix(name, snapshotSuffix), 10, 64)
+			if err != nil {
+				continue
+			}
+			info, err := e.Info()
+			if err != nil {
+				return nil, err
+			}
+			b.files = append(b.files, bufferedFile{name: name, size: info.Size()})
+			b.size += info.Size()
+			b.seq = max(b.seq, seq+1)
+		}
+	}
+	// The names are zero-padded, so that their order is the order of the
+	// sequence numbers.
+	sort.Slice(b.files, func(i, j int) bool { return b.files[i].name < b.files[j].name })
+	return b, nil
+}
+
+// append writes a snapshot and drops the oldest snapshots until the buffer
+// fits into its maximum size again. It returns the number of dropped
+// snapshots.
+func (b *diskBuffer) append(mfs []*dto.MetricFamily) (int, error) {
+	var buf bytes.Buffer
+	enc := expfmt.NewEncoder(&buf, expfmt.NewFormat(expfmt.TypeProtoDelim))
+	for _, mf := range mfs {
+		if err := enc.Encode(mf); err != nil {
+			return 0, err
+		}
+	}
+	if int64(buf.Len()) > b.maxSize {
+		return 0, errSnapshotTooBig
+	}
+
+	name := fmt.Sprintf("%020d%s", b.seq, snapshotSuffix)
+	if err := writeFileSync(filepath.Join(b.dir, name), buf.Bytes()); err != nil {
+		return 0, err
+	}
+	b.seq++
+	b.files = append(b.files, bufferedFile{name: name, size: int64(buf.Len())})
+	b.size += int64(buf.Len())
+
+	dropped := 0
+	for b.size > b.maxSize {
+		if err := b.dropOldest(); err != nil {
+			return dropped, err
+		}
+		dropped++
+	}
+	return dropped, nil
+}
+
+// writeFileSync writes data to a temporary file, syncs it, and renames it to
+// path, so that a crash never leaves a partial snapshot behind.
+func writeFileSync(path string, data []byte) error {
+	tmp := path + tmpSuffix
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// oldest reads the oldest snapshot.
+func (b *diskBuffer) oldest() ([]*dto.MetricFamily, error) {
+	f, err := os.Open(filepath.Join(b.dir, b.files[0].name))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var mfs []*dto.MetricFamily
+	dec := expfmt.NewDecoder(f, expfmt.NewFormat(expfmt.TypeProtoDelim))
+	for {
+		mf := &dto.MetricFamily{}
+		if err := dec.Decode(mf); err != nil {
+			if errors.Is(err, io.EOF) {
+				return mfs, nil
+			}
+			return nil, fmt.Errorf("decoding %s: %w", b.files[0].name, err)
+		}
+		mfs = append(mfs, mf)
+	}
+}
+
+// dropOldest removes the oldest snapshot.
+func (b *diskBuffer) dropOldest() error {
+	if err := os.Remove(filepath.Join(b.dir, b.files[0].name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	b.size -= b.files[0].size
+	b.files = b.files[1:]
+	return nil
+}
+
+// clear removes all snapshots.
+func (b *diskBuffer) clear() error {
+	for len(b.files) > 0 {
+		if err := b.dropOldest(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
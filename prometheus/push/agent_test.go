@@ -0,0 +1,289 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/prometheus/common/expfmt"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// flakyReceiver is a push receiver whose status code can be changed, which
+// records the bodies of the requests it accepted.
+type flakyReceiver struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	status int
+	bodies [][]byte
+}
+
+func newFlakyReceiver(t *testing.T) *flakyReceiver {
+	r := &flakyReceiver{status: http.StatusOK}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.status/100 == 2 {
+			r.bodies = append(r.bodies, body)
+		}
+		w.WriteHeader(r.status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *flakyReceiver) setStatus(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+func (r *flakyReceiver) received() [][]byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.bodies
+}
+
+// openAgent returns an Agent with an opened buffer, whose cycles can be
+// triggered directly.
+func openAgent(t *testing.T, a *Agent) *Agent {
+	t.Helper()
+	b, err := openDiskBuffer(a.dir, a.maxBufferSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.buffer = b
+	return a
+}
+
+func TestAgentRemoteWriteReplay(t *testing.T) {
+	rw := newFlakyReceiver(t)
+	dir := t.TempDir()
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "temperature", Help: "Temperature."})
+	pusher := New(rw.URL, "sensor").RemoteWrite().Collector(gauge)
+	var errs []error
+	a := openAgent(t, NewAgent(pusher, time.Minute, dir).ErrorHandler(func(err error) {
+		errs = append(errs, err)
+	}))
+	ctx := context.Background()
+
+	gauge.Set(1)
+	a.cycle(ctx)
+	rw.setStatus(http.StatusServiceUnavailable)
+	gauge.Set(2)
+	a.cycle(ctx)
+	gauge.Set(3)
+	a.cycle(ctx)
+	if got := a.Buffered(); got != 2 {
+		t.Fatalf("got %d buffered snapshots, want 2", got)
+	}
+	if len(errs) != 2 {
+		t.Errorf("got errors %v, want one per failed push", errs)
+	}
+
+	// The buffer survives a restart.
+	a = openAgent(t, NewAgent(pusher, time.Minute, dir))
+	if got := a.Buffered(); got != 2 {
+		t.Fatalf("got %d buffered snapshots after reopening, want 2", got)
+	}
+
+	rw.setStatus(http.StatusNoContent)
+	gauge.Set(4)
+	a.cycle(ctx)
+	if got := a.Buffered(); got != 0 {
+		t.Errorf("got %d buffered snapshots after recovery, want 0", got)
+	}
+
+	var got []string
+	for _, body := range rw.received() {
+		decoded, err := snappy.Decode(nil, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		samples, _, _ := decodeWriteRequest(t, decoded)
+		got = append(got, samples...)
+	}
+	want := []string{
+		`temperature{job="sensor"} 1`,
+		`temperature{job="sensor"} 2`,
+		`temperature{job="sensor"} 3`,
+		`temperature{job="sensor"} 4`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got samples\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestAgentPushgatewayKeepsLatest(t *testing.T) {
+	pgw := newFlakyReceiver(t)
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "temperature", Help: "Temperature."})
+	a := openAgent(t, NewAgent(New(pgw.URL, "sensor").Collector(gauge), time.Minute, t.TempDir()))
+	ctx := context.Background()
+
+	pgw.setStatus(http.StatusBadGateway)
+	for _, v := range []float64{1, 2, 3} {
+		gauge.Set(v)
+		a.cycle(ctx)
+		if got := a.Buffered(); got != 1 {
+			t.Fatalf("got %d buffered snapshots, want 1", got)
+		}
+	}
+
+	pgw.setStatus(http.StatusOK)
+	gauge.Set(4)
+	a.cycle(ctx)
+	var got []float64
+	for _, body := range pgw.received() {
+		var mf dto.MetricFamily
+		if err := expfmt.NewDecoder(strings.NewReader(string(body)), expfmt.NewFormat(expfmt.TypeProtoDelim)).Decode(&mf); err != nil {
+			t.Fatal(err)
+		}
+		m := mf.GetMetric()[0]
+		if m.TimestampMs != nil {
+			t.Errorf("pushed metric with timestamp to the Pushgateway")
+		}
+		got = append(got, m.GetGauge().GetValue())
+	}
+	// The buffered latest snapshot is replayed after the new one was
+	// buffered, too, so that only the newest one is pushed.
+	if len(got) != 1 || got[0] != 4 {
+		t.Errorf("got pushed values %v, want [4]", got)
+	}
+}
+
+func TestAgentDropsRejectedSnapshots(t *testing.T) {
+	rw := newFlakyReceiver(t)
+	rw.setStatus(http.StatusBadRequest)
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "temperature", Help: "Temperature."})
+	var errs []error
+	a := openAgent(t, NewAgent(New(rw.URL, "sensor").RemoteWrite().Collector(gauge), time.Minute, t.TempDir()).
+		ErrorHandler(func(err error) { errs = append(errs, err) }))
+
+	a.cycle(context.Background())
+	if got := a.Buffered(); got != 0 {
+		t.Errorf("got %d buffered snapshots, want 0", got)
+	}
+	var se *statusError
+	if len(errs) != 1 || !errors.As(errs[0], &se) || se.code != http.StatusBadRequest {
+		t.Errorf("got errors %v, want the rejection", errs)
+	}
+}
+
+func TestAgentMaxBufferSize(t *testing.T) {
+	rw := newFlakyReceiver(t)
+	rw.setStatus(http.StatusServiceUnavailable)
+	dir := t.TempDir()
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "temperature", Help: "Temperature."})
+	var errs []error
+	a := NewAgent(New(rw.URL, "sensor").RemoteWrite().Collector(gauge), time.Minute, dir).
+		ErrorHandler(func(err error) { errs = append(errs, err) })
+
+	// Determine the size of one snapshot to allow exactly two.
+	b, err := openDiskBuffer(t.TempDir(), defaultMaxBufferSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mfs, err := a.pusher.gather(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.append(withTimestamps(mfs, time.Now())); err != nil {
+		t.Fatal(err)
+	}
+	a = openAgent(t, a.MaxBufferSize(2*b.size))
+
+	for i := 0; i < 3; i++ {
+		a.cycle(context.Background())
+	}
+	if got := a.Buffered(); got != 2 {
+		t.Errorf("got %d buffered snapshots, want 2", got)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*"+snapshotSuffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Errorf("got %d snapshot files, want 2", len(files))
+	}
+	dropped := 0
+	for _, err := range errs {
+		if strings.Contains(err.Error(), "dropped 1 oldest") {
+			dropped++
+		}
+	}
+	if dropped != 1 {
+		t.Errorf("got errors %v, want one dropped snapshot", errs)
+	}
+}
+
+func TestOpenDiskBufferRemovesIncomplete(t *testing.T) {
+	dir := t.TempDir()
+	tmp := filepath.Join(dir, "00000000000000000003"+snapshotSuffix+tmpSuffix)
+	if err := os.WriteFile(tmp, []byte("partial"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "00000000000000000007"+snapshotSuffix), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	b, err := openDiskBuffer(dir, defaultMaxBufferSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(tmp); !os.IsNotExist(err) {
+		t.Errorf("incomplete snapshot not removed: %v", err)
+	}
+	if len(b.files) != 1 || b.seq != 8 {
+		t.Errorf("got %d files and sequence %d, want 1 and 8", len(b.files), b.seq)
+	}
+}
+
+func TestAgentStartStop(t *testing.T) {
+	pgw := newFlakyReceiver(t)
+	a := NewAgent(New(pgw.URL, "sensor"), 10*time.Millisecond, t.TempDir())
+	if err := a.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Start(); err != errAgentStarted {
+		t.Errorf("got error %v, want %v", err, errAgentStarted)
+	}
+	waitFor(t, func() bool { return len(pgw.received()) >= 2 })
+	a.Stop()
+	a.Stop()
+
+	if err := NewAgent(New(pgw.URL, "sensor"), 0, t.TempDir()).Start(); err == nil {
+		t.Error("expected error for non-positive interval")
+	}
+	if err := NewAgent(New(pgw.URL, "sensor"), time.Second, "").Start(); err == nil {
+		t.Error("expected error for empty directory")
+	}
+}
@@ -0,0 +1,350 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenExpiryDelta is how long before their expiry bearer tokens are
+// refreshed, to account for clock skew and request latency.
+const tokenExpiryDelta = 10 * time.Second
+
+// authorizer adds authentication to a request. body is the request body, which
+// must not be modified.
+type authorizer interface {
+	authorize(req *http.Request, body []byte) error
+}
+
+// invalidateAuth discards any cached credentials, so that they are refreshed
+// for the next request. It is called after the server responded with 401.
+func (c *httpConfig) invalidateAuth() {
+	if i, ok := c.auth.(interface{ invalidate() }); ok {
+		i.invalidate()
+	}
+}
+
+type basicAuth struct {
+	username, password string
+}
+
+func (a basicAuth) authorize(req *http.Request, _ []byte) error {
+	req.SetBasicAuth(a.username, a.password)
+	return nil
+}
+
+// TokenFunc returns a bearer token and the time it expires. A zero expiry time
+// means that the token doesn't expire.
+type TokenFunc func(ctx context.Context) (token string, expiry time.Time, err error)
+
+// BearerToken configures the Pusher to authenticate with a bearer token
+// obtained by calling fn, replacing any other authentication method configured
+// before. The token is cached and fn is only called again once the token is
+// about to expire or after the Pushgateway rejected it with status code 401.
+// For convenience, this method returns a pointer to the Pusher itself.
+func (p *Pusher) BearerToken(fn TokenFunc) *Pusher {
+	p.auth = &bearerAuth{fetch: fn}
+	return p
+}
+
+type bearerAuth struct {
+	fetch TokenFunc
+
+	mtx    sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+func (a *bearerAuth) authorize(req *http.Request, _ []byte) error {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	if a.token == "" || (!a.expiry.IsZero() && time.Now().Add(tokenExpiryDelta).After(a.expiry)) {
+		token, expiry, err := a.fetch(req.Context())
+		if err != nil {
+			return err
+		}
+		if token == "" {
+			return errors.New("bearer token is empty")
+		}
+		a.token, a.expiry = token, expiry
+	}
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	return nil
+}
+
+func (a *bearerAuth) invalidate() {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	a.token = ""
+}
+
+// OAuth2Config is the configuration of the OAuth 2.0 client credentials flow,
+// see Pusher.OAuth2.
+type OAuth2Config struct {
+	ClientID     string
+	ClientSecret string
+	// TokenURL is the URL of the token endpoint of the authorization
+	// server. Mandatory.
+	TokenURL string
+	// Scopes to request, if any.
+	Scopes []string
+	// EndpointParams are additional parameters for requests to the token
+	// endpoint, e.g. an audience.
+	EndpointParams url.Values
+}
+
+// OAuth2 configures the Pusher to authenticate with access tokens obtained with
+// the OAuth 2.0 client credentials flow, replacing any other authentication
+// method configured before. Tokens are requested with the HTTP client of the
+// Pusher and cached like with BearerToken. For convenience, this method returns
+// a pointer to the Pusher itself.
+func (p *Pusher) OAuth2(cfg OAuth2Config) *Pusher {
+	if p.error != nil {
+		return p
+	}
+	if cfg.TokenURL == "" {
+		p.error = errors.New("OAuth2 token URL is empty")
+		return p
+	}
+	p.auth = &bearerAuth{fetch: func(ctx context.Context) (string, time.Time, error) {
+		return fetchOAuth2Token(ctx, p.client, cfg)
+	}}
+	return p
+}
+
+// fetchOAuth2Token requests an access token from the token endpoint in cfg.
+func fetchOAuth2Token(ctx context.Context, client HTTPDoer, cfg OAuth2Config) (string, time.Time, error) {
+	params := url.Values{"grant_type": {"client_credentials"}}
+	if len(cfg.Scopes) > 0 {
+		params.Set("scope", strings.Join(cfg.Scopes, " "))
+	}
+	for k, v := range cfg.EndpointParams {
+		params[k] = v
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.TokenURL, strings.NewReader(params.Encode()))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Set(contentTypeHeader, "application/x-www-form-urlencoded")
+	// See RFC 6749, section 2.3.1.
+	req.SetBasicAuth(url.QueryEscape(cfg.ClientID), url.QueryEscape(cfg.ClientSecret))
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("requesting OAuth2 token: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("reading OAuth2 token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", time.Time{}, fmt.Errorf("unexpected status code %d while requesting OAuth2 token from %s: %s", resp.StatusCode, cfg.TokenURL, body)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", time.Time{}, fmt.Errorf("decoding OAuth2 token response: %w", err)
+	}
+	if token.TokenType != "" && !strings.EqualFold(token.TokenType, "bearer") {
+		return "", time.Time{}, fmt.Errorf("unsupported OAuth2 token type %q", token.TokenType)
+	}
+	var expiry time.Time
+	if token.ExpiresIn > 0 {
+		expiry = start.Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	return token.AccessToken, expiry, nil
+}
+
+// SigV4Config is the configuration of AWS Signature Version 4 signing, see
+// Pusher.SigV4.
+type SigV4Config struct {
+	// Region is the AWS region, e.g. "us-east-1". Defaults to the
+	// AWS_REGION environment variable.
+	Region string
+	// Service is the signing name of the AWS service. Defaults to "aps"
+	// (Amazon Managed Service for Prometheus).
+	Service string
+	// AccessKey, SecretKey, and SessionToken are the AWS credentials. If
+	// AccessKey is empty, all three default to the AWS_ACCESS_KEY_ID,
+	// AWS_SECRET_ACCESS_KEY, and AWS_SESSION_TOKEN environment variables.
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+}
+
+// SigV4 configures the Pusher to sign requests with AWS Signature Version 4,
+// replacing any other authentication method configured before. This is needed
+// for endpoints compatible with Amazon Managed Service for Prometheus. For
+// convenience, this method returns a pointer to the Pusher itself.
+func (p *Pusher) SigV4(cfg SigV4Config) *Pusher {
+	if p.error != nil {
+		return p
+	}
+	if cfg.Region == "" {
+		cfg.Region = os.Getenv("AWS_REGION")
+	}
+	if cfg.Service == "" {
+		cfg.Service = "aps"
+	}
+	if cfg.AccessKey == "" {
+		cfg.AccessKey = os.Getenv("AWS_ACCESS_KEY_ID")
+		cfg.SecretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		cfg.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	switch {
+	case cfg.Region == "":
+		p.error = errors.New("SigV4 region is empty")
+	case cfg.AccessKey == "" || cfg.SecretKey == "":
+		p.error = errors.New("SigV4 credentials are incomplete")
+	default:
+		p.auth = &sigV4Auth{cfg: cfg, now: time.Now}
+	}
+	return p
+}
+
+type sigV4Auth struct {
+	cfg SigV4Config
+	now func() time.Time
+}
+
+func (a *sigV4Auth) authorize(req *http.Request, body []byte) error {
+	now := a.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if a.cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", a.cfg.SessionToken)
+	}
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	// Only sign headers that aren't modified by the transport.
+	headers := map[string]string{"host": host}
+	for _, name := range []string{"X-Amz-Date", "X-Amz-Security-Token"} {
+		if v := req.Header.Get(name); v != "" {
+			headers[strings.ToLower(name)] = v
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	bodyHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		sigV4CanonicalURI(req.URL),
+		sigV4CanonicalQuery(req.URL),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+
+	scope := strings.Join([]string{date, a.cfg.Region, a.cfg.Service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(canonicalHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+a.cfg.SecretKey), date)
+	key = hmacSHA256(key, a.cfg.Region)
+	key = hmacSHA256(key, a.cfg.Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		a.cfg.AccessKey, scope, signedHeaders, signature,
+	))
+	return nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// sigV4CanonicalURI returns the canonical URI of u. For services other than S3,
+// each path segment is encoded twice, i.e. the escaped path is escaped again,
+// like the AWS SDKs do.
+func sigV4CanonicalURI(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		segments[i] = sigV4Escape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// sigV4CanonicalQuery returns the canonical query string of u, i.e. the
+// escaped parameters sorted by name and value.
+func sigV4CanonicalQuery(u *url.URL) string {
+	var params []string
+	for k, vs := range u.Query() {
+		for _, v := range vs {
+			params = append(params, sigV4Escape(k)+"="+sigV4Escape(v))
+		}
+	}
+	sort.Strings(params)
+	return strings.Join(params, "&")
+}
+
+// sigV4Escape escapes all characters but the unreserved ones of RFC 3986.
+func sigV4Escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
@@ -0,0 +1,199 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBearerToken(t *testing.T) {
+	var (
+		fetches   int
+		validAuth = "Bearer token-2"
+	)
+	pgw := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != validAuth {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusAccepted)
+		}),
+	)
+	defer pgw.Close()
+
+	p := New(pgw.URL, "testjob").BearerToken(func(context.Context) (string, time.Time, error) {
+		fetches++
+		return fmt.Sprintf("token-%d", fetches), time.Time{}, nil
+	})
+	// The first token is rejected and thus refreshed for the next call.
+	if err := p.Delete(); err == nil {
+		t.Error("delete with rejected token succeeded")
+	}
+	for i := 0; i < 2; i++ {
+		if err := p.Delete(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if fetches != 2 {
+		t.Errorf("got %d token fetches, want 2", fetches)
+	}
+
+	// Tokens about to expire are refreshed.
+	fetches = 0
+	p = New(pgw.URL, "testjob").BearerToken(func(context.Context) (string, time.Time, error) {
+		fetches++
+		return "token-2", time.Now().Add(time.Second), nil
+	})
+	for i := 0; i < 2; i++ {
+		if err := p.Delete(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if fetches != 2 {
+		t.Errorf("got %d token fetches, want 2", fetches)
+	}
+}
+
+func TestOAuth2(t *testing.T) {
+	var fetches int
+	tokenServer := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id, secret, _ := r.BasicAuth()
+			if err := r.ParseForm(); err != nil {
+				t.Fatal(err)
+			}
+			if id != "client" || secret != "s%40cret" ||
+				r.PostForm.Get("grant_type") != "client_credentials" ||
+				r.PostForm.Get("scope") != "a b" ||
+				r.PostForm.Get("audience") != "pgw" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fetches++
+			w.Write([]byte(`{"access_token":"abc","token_type":"Bearer","expires_in":3600}`))
+		}),
+	)
+	defer tokenServer.Close()
+	pgw := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer abc" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusAccepted)
+		}),
+	)
+	defer pgw.Close()
+
+	p := New(pgw.URL, "testjob").OAuth2(OAuth2Config{
+		ClientID:       "client",
+		ClientSecret:   "s@cret",
+		TokenURL:       tokenServer.URL,
+		Scopes:         []string{"a", "b"},
+		EndpointParams: map[string][]string{"audience": {"pgw"}},
+	})
+	for i := 0; i < 2; i++ {
+		if err := p.Delete(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("got %d token fetches, want 1", fetches)
+	}
+
+	if err := New(pgw.URL, "testjob").OAuth2(OAuth2Config{ClientID: "wrong", TokenURL: tokenServer.URL}).Delete(); err == nil {
+		t.Error("delete with failing token request succeeded")
+	}
+	if err := New(pgw.URL, "testjob").OAuth2(OAuth2Config{}).Error(); err == nil {
+		t.Error("expected error for missing token URL")
+	}
+}
+
+func TestSigV4(t *testing.T) {
+	// The "get-vanilla" case of the AWS Signature Version 4 test suite.
+	auth := &sigV4Auth{
+		cfg: SigV4Config{
+			Region:    "us-east-1",
+			Service:   "service",
+			AccessKey: "AKIDEXAMPLE",
+			SecretKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		},
+		now: func() time.Time { return time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC) },
+	}
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := auth.authorize(req, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, " +
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("got Authorization %q, want %q", got, want)
+	}
+	if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+		t.Errorf("got X-Amz-Date %q, want %q", got, "20150830T123600Z")
+	}
+
+	if got, want := sigV4CanonicalURI(req.URL), "/"; got != want {
+		t.Errorf("got canonical URI %q, want %q", got, want)
+	}
+	req, err = http.NewRequest(http.MethodPut, New("example.org", "a/b").fullURL("http://example.org"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sigV4CanonicalURI(req.URL), "/metrics/job%40base64/YS9i"; got != want {
+		t.Errorf("got canonical URI %q, want %q", got, want)
+	}
+	req, err = http.NewRequest(http.MethodPut, "http://example.org/metrics/job/a=b/c%2Fd%20e", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sigV4CanonicalURI(req.URL), "/metrics/job/a%3Db/c%252Fd%2520e"; got != want {
+		t.Errorf("got canonical URI %q, want %q", got, want)
+	}
+
+	// A path with characters that EscapedPath leaves unescaped, signed
+	// with the signer of the AWS SDK for Go v2.
+	req, err = http.NewRequest(http.MethodPut, "https://example.amazonaws.com/metrics/job@base64/YS9i/instance/a=b/path/c%2Fd%20e", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := auth.authorize(req, []byte("testname 1\n")); err != nil {
+		t.Fatal(err)
+	}
+	want = "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, " +
+		"Signature=f142482dd08661a58b5443131c818c5ef7bcfe55e78f139df1ddafea2def0c4c"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("got Authorization %q, want %q", got, want)
+	}
+
+	t.Setenv("AWS_REGION", "")
+	if err := New("example.org", "testjob").SigV4(SigV4Config{AccessKey: "a", SecretKey: "b"}).Error(); err == nil {
+		t.Error("expected error for missing region")
+	}
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	if err := New("example.org", "testjob").SigV4(SigV4Config{Region: "us-east-1"}).Error(); err == nil {
+		t.Error("expected error for missing credentials")
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+
+	"github.com/klauspost/compress/snappy"
+)
+
+const contentEncodingHeader = "Content-Encoding"
+
+// Compression represents the content encodings the Pusher supports for
+// request bodies.
+type Compression string
+
+const (
+	Identity Compression = "identity"
+	Gzip     Compression = "gzip"
+	// Snappy uses the snappy block format (as used by the Prometheus
+	// remote-write protocol). It is not supported by the standard
+	// Pushgateway and only meant for custom receivers.
+	Snappy Compression = "snappy"
+)
+
+// Compression configures the Pusher to compress the request body of Push and
+// Add with the provided content encoding and to set the Content-Encoding header
+// accordingly. The default is Identity, i.e. no compression. Gzip is supported
+// by recent versions of the Pushgateway. For convenience, this method returns a
+// pointer to the Pusher itself.
+func (p *Pusher) Compression(c Compression) *Pusher {
+	switch c {
+	case Identity, Gzip, Snappy:
+		p.compression = c
+	default:
+		if p.error == nil {
+			p.error = fmt.Errorf("unsupported compression %q", c)
+		}
+	}
+	return p
+}
+
+// CompressionThreshold configures the Pusher to only compress request bodies
+// that are at least the provided number of bytes long, as compressing small
+// payloads usually isn't worth the CPU time. The default is 0, i.e. all bodies
+// are compressed if a compression is configured. For convenience, this method
+// returns a pointer to the Pusher itself.
+func (p *Pusher) CompressionThreshold(size int) *Pusher {
+	p.compressionThreshold = size
+	return p
+}
+
+// compress compresses body according to the configured compression and
+// threshold. It returns the resulting body and the content encoding to put
+// into the Content-Encoding header, which is empty if body wasn't compressed.
+func (p *Pusher) compress(body []byte) ([]byte, string, error) {
+	if len(body) < p.compressionThreshold {
+		return body, "", nil
+	}
+	switch p.compression {
+	case Gzip:
+		buf := &bytes.Buffer{}
+		gz := gzip.NewWriter(buf)
+		if _, err := gz.Write(body); err != nil {
+			return nil, "", err
+		}
+		if err := gz.Close(); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), string(Gzip), nil
+	case Snappy:
+		return snappy.Encode(nil, body), string(Snappy), nil
+	default:
+		return body, "", nil
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestPushCompression(t *testing.T) {
+	var (
+		lastEncoding string
+		lastBody     []byte
+	)
+	pgw := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			lastEncoding = r.Header.Get(contentEncodingHeader)
+			var err error
+			lastBody, err = io.ReadAll(r.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+	defer pgw.Close()
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "testname",
+		Help: "testhelp",
+	}))
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	enc := expfmt.NewEncoder(buf, expfmt.NewFormat(expfmt.TypeProtoDelim))
+	for _, mf := range mfs {
+		if err := enc.Encode(mf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wantBody := buf.Bytes()
+
+	decodeGzip := func(b []byte) ([]byte, error) {
+		gz, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		return io.ReadAll(gz)
+	}
+	decodeSnappy := func(b []byte) ([]byte, error) {
+		return snappy.Decode(nil, b)
+	}
+	identity := func(b []byte) ([]byte, error) {
+		return b, nil
+	}
+
+	for _, tc := range []struct {
+		name         string
+		compression  Compression
+		threshold    int
+		wantEncoding string
+		decode       func([]byte) ([]byte, error)
+	}{
+		{"default", "", 0, "", identity},
+		{"identity", Identity, 0, "", identity},
+		{"gzip", Gzip, 0, "gzip", decodeGzip},
+		{"snappy", Snappy, 0, "snappy", decodeSnappy},
+		{"below threshold", Gzip, len(wantBody) + 1, "", identity},
+		{"at threshold", Gzip, len(wantBody), "gzip", decodeGzip},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := New(pgw.URL, "testjob").Gatherer(reg)
+			if tc.compression != "" {
+				p.Compression(tc.compression)
+			}
+			if err := p.CompressionThreshold(tc.threshold).Push(); err != nil {
+				t.Fatal(err)
+			}
+			if lastEncoding != tc.wantEncoding {
+				t.Errorf("got Content-Encoding %q, want %q", lastEncoding, tc.wantEncoding)
+			}
+			got, err := tc.decode(lastBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, wantBody) {
+				t.Errorf("got body %v, want %v", got, wantBody)
+			}
+		})
+	}
+
+	if err := New(pgw.URL, "testjob").Compression("brotli").Push(); err == nil {
+		t.Error("push with unsupported compression succeeded")
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus/testutil/promlint"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// ValidationError is returned by Push and Add in dry-run mode if linting the
+// metrics to push found problems, see Pusher.DryRun.
+type ValidationError struct {
+	Problems []promlint.Problem
+}
+
+func (e *ValidationError) Error() string {
+	problems := make([]string, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		problems = append(problems, fmt.Sprintf("%s: %s", p.Metric, p.Text))
+	}
+	return fmt.Sprintf("metrics to push have %d lint problems: %s", len(problems), strings.Join(problems, "; "))
+}
+
+// DryRun configures the Pusher to not send any requests to the Pushgateway.
+// Instead, Push and Add gather and validate the metrics as usual and write the
+// encoded (but uncompressed) payload in the preferred format to w, if w is not
+// nil. In addition to the usual checks (like label conflicts with the grouping
+// key and the maximum payload size, see MaxPayloadSize), the metrics are
+// linted with promlint, and any problems are returned as a ValidationError
+// (after writing the payload). Delete does nothing in dry-run mode.
+//
+// This allows a CI pipeline to validate what a job would push. For
+// convenience, this method returns a pointer to the Pusher itself.
+func (p *Pusher) DryRun(w io.Writer) *Pusher {
+	p.dryRun = true
+	p.dryRunWriter = w
+	return p
+}
+
+// MaxPayloadSize limits the size of the (possibly compressed) request body of
+// Push and Add. A larger payload results in an error without sending any
+// request. A zero or negative size (the default) means no limit. For
+// convenience, this method returns a pointer to the Pusher itself.
+func (p *Pusher) MaxPayloadSize(size int) *Pusher {
+	p.maxPayloadSize = size
+	return p
+}
+
+func (p *Pusher) checkPayloadSize(size int) error {
+	if p.maxPayloadSize > 0 && size > p.maxPayloadSize {
+		return fmt.Errorf("payload of %d bytes exceeds the maximum payload size of %d bytes", size, p.maxPayloadSize)
+	}
+	return nil
+}
+
+// dryRunPush performs the dry run of a push of the provided metric families.
+func (p *Pusher) dryRunPush(ctx context.Context, mfs []*dto.MetricFamily) error {
+	buf, err := encode(ctx, mfs, p.formats[p.formatIdx.Load()])
+	if err != nil {
+		return err
+	}
+	body, _, err := p.compress(buf)
+	if err != nil {
+		return fmt.Errorf("failed to compress request body: %w", err)
+	}
+	return p.finishDryRun(mfs, buf, len(body))
+}
+
+// finishDryRun checks the size of the request body, writes the uncompressed
+// payload, and lints the metric families.
+func (p *Pusher) finishDryRun(mfs []*dto.MetricFamily, payload []byte, bodySize int) error {
+	if err := p.checkPayloadSize(bodySize); err != nil {
+		return err
+	}
+	if p.dryRunWriter != nil {
+		if _, err := p.dryRunWriter.Write(payload); err != nil {
+			return fmt.Errorf("writing payload: %w", err)
+		}
+	}
+	problems, err := promlint.NewWithMetricFamilies(mfs).Lint()
+	if err != nil {
+		return fmt.Errorf("linting metrics: %w", err)
+	}
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
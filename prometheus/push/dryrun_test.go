@@ -0,0 +1,93 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestDryRun(t *testing.T) {
+	var requests int
+	pgw := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+	defer pgw.Close()
+
+	good := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "jobs_processed_total",
+		Help: "Number of processed jobs.",
+	})
+	bad := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "jobs_failed",
+		Help: "Number of failed jobs.",
+	})
+	conflicting := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:        "jobs_running",
+		Help:        "Number of running jobs.",
+		ConstLabels: prometheus.Labels{"instance": "a"},
+	})
+	textFormat := expfmt.NewFormat(expfmt.TypeTextPlain)
+
+	var buf bytes.Buffer
+	if err := New(pgw.URL, "testjob").
+		Collector(good).
+		Format(textFormat).
+		DryRun(&buf).
+		Push(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "jobs_processed_total 0") {
+		t.Errorf("payload doesn't contain the metric:\n%s", buf.String())
+	}
+
+	var validationErr *ValidationError
+	if err := New(pgw.URL, "testjob").Collector(bad).DryRun(nil).Add(); !errors.As(err, &validationErr) {
+		t.Errorf("got error %v, want ValidationError", err)
+	} else if len(validationErr.Problems) != 1 || validationErr.Problems[0].Metric != "jobs_failed" {
+		t.Errorf("got problems %v, want one for jobs_failed", validationErr.Problems)
+	}
+
+	if err := New(pgw.URL, "testjob").Grouping("instance", "a").Collector(conflicting).DryRun(nil).Push(); err == nil {
+		t.Error("dry run with grouping label conflict succeeded")
+	}
+	if err := New(pgw.URL, "testjob").Collector(good).DryRun(nil).MaxPayloadSize(10).Push(); err == nil {
+		t.Error("dry run with too large payload succeeded")
+	}
+	if err := New(pgw.URL, "testjob").DryRun(nil).Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 0 {
+		t.Errorf("got %d requests in dry-run mode, want 0", requests)
+	}
+
+	// The payload size is limited without dry-run mode, too.
+	if err := New(pgw.URL, "testjob").Collector(good).MaxPayloadSize(10).Push(); err == nil {
+		t.Error("push with too large payload succeeded")
+	}
+	if requests != 0 {
+		t.Errorf("got %d requests for too large payload, want 0", requests)
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// SuccessPolicy determines whether a Push, Add, or Delete to multiple
+// Pushgateways (see Pusher.Targets) is successful as a whole.
+type SuccessPolicy int
+
+const (
+	// RequireAll requires the operation to succeed on all Pushgateways.
+	RequireAll SuccessPolicy = iota
+	// RequireQuorum requires the operation to succeed on more than half of
+	// the Pushgateways.
+	RequireQuorum
+	// RequireAny requires the operation to succeed on at least one
+	// Pushgateway.
+	RequireAny
+)
+
+// TargetResult is the result of a Push, Add, or Delete to one Pushgateway.
+type TargetResult struct {
+	// URL of the Pushgateway as passed to New or Targets.
+	URL string
+	// Err is nil if the operation succeeded.
+	Err error
+}
+
+// FanOutError is returned by Push, Add, and Delete of a Pusher with multiple
+// targets if the results don't satisfy the configured SuccessPolicy.
+type FanOutError struct {
+	// Results contains the results of all targets, in the order they
+	// were configured.
+	Results []TargetResult
+}
+
+func (e *FanOutError) Error() string {
+	var failed []string
+	for _, r := range e.Results {
+		if r.Err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", r.URL, r.Err))
+		}
+	}
+	return fmt.Sprintf(
+		"%d of %d Pushgateways failed: %s",
+		len(failed), len(e.Results), strings.Join(failed, "; "),
+	)
+}
+
+// Unwrap returns the errors of the failed targets.
+func (e *FanOutError) Unwrap() []error {
+	var errs []error
+	for _, r := range e.Results {
+		if r.Err != nil {
+			errs = append(errs, r.Err)
+		}
+	}
+	return errs
+}
+
+// Targets adds further Pushgateways to push to, in addition to the one
+// provided to New, e.g. the second instance of an HA pair. The URLs follow the
+// same rules as the one provided to New. Push, Add, and Delete then perform
+// their operation on all Pushgateways in parallel, gathering and encoding the
+// metrics only once. Whether the operation is successful as a whole is
+// determined by the SuccessPolicy, which defaults to RequireAll. For
+// convenience, this method returns a pointer to the Pusher itself.
+func (p *Pusher) Targets(urls ...string) *Pusher {
+	for _, url := range urls {
+		p.urls = append(p.urls, normalizeURL(url))
+	}
+	return p
+}
+
+// SuccessPolicy sets the SuccessPolicy for a Pusher with multiple targets, see
+// Targets. For convenience, this method returns a pointer to the Pusher
+// itself.
+func (p *Pusher) SuccessPolicy(policy SuccessPolicy) *Pusher {
+	p.successPolicy = policy
+	return p
+}
+
+// TargetResults sets a function that is called with the per-target results
+// after each Push, Add, or Delete, no matter whether the operation succeeded
+// as a whole. This allows to detect degraded operation, e.g. with the
+// RequireAny SuccessPolicy. The function is not called if the operation failed
+// before reaching out to the Pushgateways, e.g. while gathering. For
+// convenience, this method returns a pointer to the Pusher itself.
+func (p *Pusher) TargetResults(fn func([]TargetResult)) *Pusher {
+	p.onResults = fn
+	return p
+}
+
+// fanOut calls fn for each target URL in parallel and evaluates the results
+// according to the SuccessPolicy. With a single target, the error of fn is
+// returned as is.
+func (p *Pusher) fanOut(ctx context.Context, fn func(ctx context.Context, baseURL string) error) error {
+	results := make([]TargetResult, len(p.urls))
+	if len(p.urls) == 1 {
+		results[0] = TargetResult{URL: p.urls[0], Err: fn(ctx, p.urls[0])}
+	} else {
+		var wg sync.WaitGroup
+		for i, url := range p.urls {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i] = TargetResult{URL: url, Err: fn(ctx, url)}
+			}()
+		}
+		wg.Wait()
+	}
+	if p.onResults != nil {
+		p.onResults(results)
+	}
+
+	if len(results) == 1 {
+		return results[0].Err
+	}
+	succeeded := 0
+	for _, r := range results {
+		if r.Err == nil {
+			succeeded++
+		}
+	}
+	var ok bool
+	switch p.successPolicy {
+	case RequireAny:
+		ok = succeeded > 0
+	case RequireQuorum:
+		ok = succeeded > len(results)/2
+	default:
+		ok = succeeded == len(results)
+	}
+	if ok {
+		return nil
+	}
+	return &FanOutError{Results: results}
+}
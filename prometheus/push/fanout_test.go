@@ -0,0 +1,114 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestPushTargets(t *testing.T) {
+	var (
+		mtx    sync.Mutex
+		bodies [][]byte
+	)
+	newPgw := func(code int) *httptest.Server {
+		return httptest.NewServer(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Error(err)
+				}
+				mtx.Lock()
+				bodies = append(bodies, body)
+				mtx.Unlock()
+				w.WriteHeader(code)
+			}),
+		)
+	}
+	pgw1, pgw2, pgwDown := newPgw(http.StatusOK), newPgw(http.StatusAccepted), newPgw(http.StatusServiceUnavailable)
+	defer pgw1.Close()
+	defer pgw2.Close()
+	defer pgwDown.Close()
+
+	metric := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "testname",
+		Help: "testhelp",
+	})
+
+	// All targets receive the same payload.
+	var results []TargetResult
+	if err := New(pgw1.URL, "testjob").
+		Targets(pgw2.URL).
+		Collector(metric).
+		TargetResults(func(r []TargetResult) { results = r }).
+		Push(); err != nil {
+		t.Fatal(err)
+	}
+	if len(bodies) != 2 || !bytes.Equal(bodies[0], bodies[1]) || len(bodies[0]) == 0 {
+		t.Errorf("got bodies %q, want two equal non-empty bodies", bodies)
+	}
+	if len(results) != 2 || results[0].URL != pgw1.URL || results[1].URL != pgw2.URL ||
+		results[0].Err != nil || results[1].Err != nil {
+		t.Errorf("got results %v, want two successful results", results)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		policy   SuccessPolicy
+		targets  []string
+		wantFail bool
+	}{
+		{"all", RequireAll, []string{pgw2.URL, pgwDown.URL}, true},
+		{"quorum met", RequireQuorum, []string{pgw2.URL, pgwDown.URL}, false},
+		{"quorum missed", RequireQuorum, []string{pgwDown.URL, pgwDown.URL}, true},
+		{"any", RequireAny, []string{pgwDown.URL, pgwDown.URL}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := New(pgw1.URL, "testjob").
+				Targets(tc.targets...).
+				SuccessPolicy(tc.policy).
+				Collector(metric).
+				Add()
+			if !tc.wantFail {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			var fanOutErr *FanOutError
+			if !errors.As(err, &fanOutErr) {
+				t.Fatalf("got error %v, want FanOutError", err)
+			}
+			if len(fanOutErr.Results) != 3 || fanOutErr.Results[0].Err != nil {
+				t.Errorf("got results %v, want three with the first successful", fanOutErr.Results)
+			}
+		})
+	}
+
+	// Delete fans out, too.
+	if err := New(pgw2.URL, "testjob").Targets(pgwDown.URL).SuccessPolicy(RequireAny).Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if err := New(pgw2.URL, "testjob").Targets(pgwDown.URL).Delete(); err == nil {
+		t.Error("delete with failing target succeeded")
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/common/expfmt"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Formats configures the Pusher to use the provided encoding formats, in order
+// of preference. The first format is used initially. If the Pushgateway
+// rejects a push with status code 415 (Unsupported Media Type), the push is
+// retried with the next format. Once a format has been accepted, it is used for
+// all further pushes of the Pusher.
+//
+// Only the protobuf format (expfmt.FmtProtoDelim, the default) and the
+// OpenMetrics format (see expfmt.NewOpenMetricsFormat) are able to transport
+// exemplars, and only the protobuf format is able to transport native
+// histograms. Note that the standard Prometheus Pushgateway doesn't support
+// the OpenMetrics format. For example, to prefer OpenMetrics for a custom
+// receiver, but fall back to protobuf:
+//
+//	pusher.Formats(
+//	    expfmt.NewFormat(expfmt.TypeOpenMetrics),
+//	    expfmt.NewFormat(expfmt.TypeProtoDelim),
+//	)
+//
+// For convenience, this method returns a pointer to the Pusher itself.
+func (p *Pusher) Formats(formats ...expfmt.Format) *Pusher {
+	if p.error != nil {
+		return p
+	}
+	if len(formats) == 0 {
+		p.error = errors.New("no push format provided")
+		return p
+	}
+	for _, f := range formats {
+		if f.FormatType() == expfmt.TypeUnknown {
+			p.error = fmt.Errorf("unknown push format %q", f)
+			return p
+		}
+	}
+	p.formats = formats
+	p.formatIdx.Store(0)
+	return p
+}
+
+// encode encodes the provided metric families in the provided format,
+// including any trailer the format requires (like the "# EOF" line of
+// OpenMetrics).
+func encode(ctx context.Context, mfs []*dto.MetricFamily, format expfmt.Format) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	enc := expfmt.NewEncoder(buf, format)
+	for _, mf := range mfs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := enc.Encode(mf); err != nil {
+			return nil, fmt.Errorf(
+				"failed to encode metric family %s, error is %w",
+				mf.GetName(), err)
+		}
+	}
+	if closer, ok := enc.(expfmt.Closer); ok {
+		if err := closer.Close(); err != nil {
+			return nil, fmt.Errorf("failed to finalize encoding, error is %w", err)
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestPushFormats(t *testing.T) {
+	var (
+		contentTypes []string
+		lastBody     []byte
+	)
+	// Fake a Pushgateway that doesn't support OpenMetrics.
+	pgw := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ct := r.Header.Get(contentTypeHeader)
+			contentTypes = append(contentTypes, ct)
+			var err error
+			lastBody, err = io.ReadAll(r.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.HasPrefix(ct, expfmt.OpenMetricsType) {
+				w.WriteHeader(http.StatusUnsupportedMediaType)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+	defer pgw.Close()
+
+	counter := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "testname_total",
+		Help: "testhelp",
+	})
+	counter.(prometheus.ExemplarAdder).AddWithExemplar(1, prometheus.Labels{"trace_id": "abc"})
+	openMetrics := expfmt.NewFormat(expfmt.TypeOpenMetrics)
+	protoDelim := expfmt.NewFormat(expfmt.TypeProtoDelim)
+
+	// OpenMetrics bodies are finalized and contain exemplars.
+	pgwOM := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var err error
+			lastBody, err = io.ReadAll(r.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+	defer pgwOM.Close()
+	if err := New(pgwOM.URL, "testjob").
+		Collector(counter).
+		Format(openMetrics).
+		Push(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasSuffix(lastBody, []byte("# EOF\n")) {
+		t.Errorf("OpenMetrics body not finalized:\n%s", lastBody)
+	}
+	if !bytes.Contains(lastBody, []byte(`# {trace_id="abc"} 1`)) {
+		t.Errorf("OpenMetrics body without exemplar:\n%s", lastBody)
+	}
+
+	// Fall back to protobuf and remember it.
+	p := New(pgw.URL, "testjob").
+		Collector(counter).
+		Formats(openMetrics, protoDelim)
+	for i := 0; i < 2; i++ {
+		if err := p.Push(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{string(openMetrics), string(protoDelim), string(protoDelim)}
+	if len(contentTypes) != len(want) {
+		t.Fatalf("got content types %q, want %q", contentTypes, want)
+	}
+	for i := range want {
+		if contentTypes[i] != want[i] {
+			t.Errorf("request %d: got content type %q, want %q", i, contentTypes[i], want[i])
+		}
+	}
+
+	// No fallback left.
+	if err := New(pgw.URL, "testjob").
+		Collector(counter).
+		Format(openMetrics).
+		Push(); err == nil {
+		t.Error("push with unsupported format succeeded")
+	}
+
+	if err := New(pgw.URL, "testjob").Formats().Push(); err == nil {
+		t.Error("push without formats succeeded")
+	}
+	if err := New(pgw.URL, "testjob").Format("application/json").Push(); err == nil {
+		t.Error("push with unknown format succeeded")
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// pusherMetrics are the metrics a Pusher reports about itself, see
+// Pusher.Instrument.
+type pusherMetrics struct {
+	operations   *prometheus.CounterVec
+	requests     *prometheus.CounterVec
+	duration     *prometheus.HistogramVec
+	payloadBytes *prometheus.HistogramVec
+}
+
+// Instrument configures the Pusher to report metrics about its own operation
+// to the provided Registerer:
+//
+// push_operations_total{method, result}: Push, Add, and Delete calls by HTTP
+// method ("put", "post", or "delete") and result ("success" or "failure").
+//
+// push_requests_total{method, code}: HTTP requests sent to the Pushgateway
+// (including retries) by HTTP method and status code. The code is "error" if no
+// response was received.
+//
+// push_duration_seconds{method}: Histogram of the duration of Push, Add, and
+// Delete calls, including gathering, encoding, and retries.
+//
+// push_payload_bytes{method}: Histogram of the size of the (possibly
+// compressed) request bodies sent to the Pushgateway.
+//
+// Multiple Pushers may be instrumented with the same Registerer, in which case
+// they share the metrics. Registering the metrics with a Gatherer of the Pusher
+// itself results in them being pushed, too, each reflecting the state before the
+// respective push. For convenience, this method returns a pointer to the Pusher
+// itself.
+func (p *Pusher) Instrument(reg prometheus.Registerer) *Pusher {
+	if p.error != nil {
+		return p
+	}
+	m := &pusherMetrics{
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "push_operations_total",
+			Help: "Total number of push operations to the Pushgateway by HTTP method and result.",
+		}, []string{"method", "result"}),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "push_requests_total",
+			Help: "Total number of HTTP requests sent to the Pushgateway by HTTP method and status code.",
+		}, []string{"method", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "push_duration_seconds",
+			Help:    "Duration of push operations to the Pushgateway by HTTP method.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method"}),
+		payloadBytes: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "push_payload_bytes",
+			Help:    "Size of request bodies sent to the Pushgateway by HTTP method.",
+			Buckets: prometheus.ExponentialBuckets(256, 4, 8),
+		}, []string{"method"}),
+	}
+	var err error
+	if m.operations, err = registerCounterVec(reg, m.operations); err != nil {
+		p.error = err
+		return p
+	}
+	if m.requests, err = registerCounterVec(reg, m.requests); err != nil {
+		p.error = err
+		return p
+	}
+	if m.duration, err = registerHistogramVec(reg, m.duration); err != nil {
+		p.error = err
+		return p
+	}
+	if m.payloadBytes, err = registerHistogramVec(reg, m.payloadBytes); err != nil {
+		p.error = err
+		return p
+	}
+	p.metrics = m
+	return p
+}
+
+// registerCounterVec registers c with reg. If an equal CounterVec is already
+// registered (e.g. by another Pusher), the existing one is returned instead.
+func registerCounterVec(reg prometheus.Registerer, c *prometheus.CounterVec) (*prometheus.CounterVec, error) {
+	if err := reg.Register(c); err != nil {
+		are := &prometheus.AlreadyRegisteredError{}
+		if errors.As(err, are) {
+			if existing, ok := are.ExistingCollector.(*prometheus.CounterVec); ok {
+				return existing, nil
+			}
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+// registerHistogramVec is like registerCounterVec, but for a HistogramVec.
+func registerHistogramVec(reg prometheus.Registerer, h *prometheus.HistogramVec) (*prometheus.HistogramVec, error) {
+	if err := reg.Register(h); err != nil {
+		are := &prometheus.AlreadyRegisteredError{}
+		if errors.As(err, are) {
+			if existing, ok := are.ExistingCollector.(*prometheus.HistogramVec); ok {
+				return existing, nil
+			}
+		}
+		return nil, err
+	}
+	return h, nil
+}
+
+// observeOperation records a finished Push, Add, or Delete call.
+func (m *pusherMetrics) observeOperation(method string, start time.Time, err error) {
+	if m == nil {
+		return
+	}
+	method = strings.ToLower(method)
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	m.operations.WithLabelValues(method, result).Inc()
+	m.duration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+// observeRequest records a single HTTP request. statusCode is 0 if no
+// response was received.
+func (m *pusherMetrics) observeRequest(method string, statusCode, payloadBytes int) {
+	if m == nil {
+		return
+	}
+	method = strings.ToLower(method)
+	code := "error"
+	if statusCode != 0 {
+		code = strconv.Itoa(statusCode)
+	}
+	m.requests.WithLabelValues(method, code).Inc()
+	if method != "delete" {
+		m.payloadBytes.WithLabelValues(method).Observe(float64(payloadBytes))
+	}
+}
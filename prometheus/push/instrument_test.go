@@ -0,0 +1,138 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestPusherInstrument(t *testing.T) {
+	failures := 1
+	pgw := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if failures > 0 {
+				failures--
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			if r.Method == http.MethodDelete {
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+	defer pgw.Close()
+
+	reg := prometheus.NewPedanticRegistry()
+	metric := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "testname",
+		Help: "testhelp",
+	})
+
+	// Push with one retry.
+	if err := New(pgw.URL, "testjob").
+		Collector(metric).
+		Retry(RetryPolicy{MaxRetries: 1, InitialBackoff: time.Millisecond}).
+		Instrument(reg).
+		Push(); err != nil {
+		t.Fatal(err)
+	}
+	// Another Pusher sharing the metrics, failing.
+	failures = 1
+	if err := New(pgw.URL, "testjob").
+		Instrument(reg).
+		Delete(); err == nil {
+		t.Fatal("expected delete to fail")
+	}
+
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]*dto.MetricFamily{}
+	for _, mf := range mfs {
+		got[mf.GetName()] = mf
+	}
+
+	wantCounters := map[string]map[string]float64{
+		"push_operations_total": {
+			"method=put,result=success":    1,
+			"method=delete,result=failure": 1,
+		},
+		"push_requests_total": {
+			"code=503,method=put":    1,
+			"code=200,method=put":    1,
+			"code=503,method=delete": 1,
+		},
+	}
+	for name, want := range wantCounters {
+		mf, ok := got[name]
+		if !ok {
+			t.Fatalf("metric %s not found", name)
+		}
+		if len(mf.GetMetric()) != len(want) {
+			t.Errorf("%s: got %d series, want %d", name, len(mf.GetMetric()), len(want))
+		}
+		for _, m := range mf.GetMetric() {
+			key := labelsKey(m)
+			if v := m.GetCounter().GetValue(); v != want[key] {
+				t.Errorf("%s{%s}: got %v, want %v", name, key, v, want[key])
+			}
+		}
+	}
+
+	if mf, ok := got["push_duration_seconds"]; !ok || len(mf.GetMetric()) != 2 {
+		t.Errorf("got push_duration_seconds %v, want two series", mf)
+	}
+	mf, ok := got["push_payload_bytes"]
+	if !ok || len(mf.GetMetric()) != 1 {
+		t.Fatalf("got push_payload_bytes %v, want one series", mf)
+	}
+	if c := mf.GetMetric()[0].GetHistogram().GetSampleCount(); c != 2 {
+		t.Errorf("got %d payload observations, want 2", c)
+	}
+	if s := mf.GetMetric()[0].GetHistogram().GetSampleSum(); s <= 0 {
+		t.Errorf("got payload bytes sum %v, want positive value", s)
+	}
+}
+
+func TestPusherInstrumentConflict(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "push_operations_total",
+		Help: "Conflicting metric.",
+	}))
+	if err := New("localhost", "testjob").Instrument(reg).Error(); err == nil {
+		t.Error("expected registration error, got nil")
+	}
+}
+
+func labelsKey(m *dto.Metric) string {
+	key := ""
+	for i, lp := range m.GetLabel() {
+		if i > 0 {
+			key += ","
+		}
+		key += lp.GetName() + "=" + lp.GetValue()
+	}
+	return key
+}
@@ -0,0 +1,232 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const defaultFlushTimeout = 2 * time.Second
+
+// Environment describes the execution environment of a serverless function,
+// i.e. one instance of the function that handles invocations one after the
+// other until the platform shuts it down.
+type Environment struct {
+	// ID identifies the execution environment among all instances of the
+	// function. It is used as the "instance" grouping label.
+	ID string
+	// Function, Version, and Region are the name, the version, and the
+	// region of the function, if known.
+	Function string
+	Version  string
+	Region   string
+}
+
+// DetectEnvironment returns the Environment of the running process as far as
+// it can be told from the environment variables of AWS Lambda and of Google
+// Cloud Functions and Cloud Run. If the platform provides no ID for the
+// execution environment, a random one is generated.
+func DetectEnvironment() Environment {
+	env := Environment{
+		ID:       os.Getenv("AWS_LAMBDA_LOG_STREAM_NAME"),
+		Function: os.Getenv("AWS_LAMBDA_FUNCTION_NAME"),
+		Version:  os.Getenv("AWS_LAMBDA_FUNCTION_VERSION"),
+		Region:   os.Getenv("AWS_REGION"),
+	}
+	if env.Function == "" {
+		env.Function = os.Getenv("K_SERVICE")
+		env.Version = os.Getenv("K_REVISION")
+	}
+	if env.ID == "" {
+		b := make([]byte, 8)
+		_, _ = rand.Read(b)
+		env.ID = hex.EncodeToString(b)
+	}
+	return env
+}
+
+// InvocationPusher pushes the metrics of a serverless function (like an AWS
+// Lambda or Google Cloud Function) at the end of its invocations. Such
+// workloads can't be scraped, as the platform freezes the execution
+// environment between invocations, and shuts it down at will. Metrics keep
+// accumulating in the process across invocations, as with any other program,
+// and are flushed with the Pusher, either to a Pushgateway or, with
+// Pusher.RemoteWrite, to a remote-write endpoint.
+//
+// Use NewInvocationPusher to create one, configure it with its methods, and
+// then wrap each invocation with Invoke. If the platform notifies the
+// process before freezing or shutting down the environment (e.g. via a
+// Lambda extension, or with SIGTERM), call Flush from there.
+type InvocationPusher struct {
+	pusher        *Pusher
+	flushInterval time.Duration
+	flushTimeout  time.Duration
+	add           bool
+	onError       func(error)
+
+	invocations *prometheus.CounterVec
+	started     atomic.Bool
+
+	mu        sync.Mutex
+	lastFlush time.Time
+}
+
+// NewInvocationPusher creates a new InvocationPusher that flushes with the
+// provided Pusher after every invocation. It adds the ID of the provided
+// Environment (usually the one returned by DetectEnvironment) as "instance"
+// label to the grouping key of the Pusher, unless the grouping key already
+// has an instance label, so that the execution environments of a function
+// don't overwrite each other's metrics on a Pushgateway. Note that the groups
+// of environments shut down by the platform remain on the Pushgateway until
+// deleted.
+//
+// The following metrics are added to the Pusher:
+//
+// serverless_environment_info{function,version,region}, which is always 1
+// serverless_environment_start_timestamp_seconds
+// serverless_invocations_total{cold_start}, where cold_start is "true" for the
+// first invocation of the environment and "false" for all others
+//
+// By default, it uses Pusher.Push, i.e. it replaces all metrics of the
+// grouping key with every flush.
+func NewInvocationPusher(pusher *Pusher, env Environment) *InvocationPusher {
+	if _, ok := pusher.grouping["instance"]; !ok {
+		pusher.Grouping("instance", env.ID)
+	}
+	info := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "serverless_environment_info",
+		Help: "Information about the execution environment of the serverless function.",
+		ConstLabels: prometheus.Labels{
+			"function": env.Function,
+			"version":  env.Version,
+			"region":   env.Region,
+		},
+	})
+	info.Set(1)
+	start := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "serverless_environment_start_timestamp_seconds",
+		Help: "Unix timestamp of the start of the execution environment.",
+	})
+	start.SetToCurrentTime()
+	ip := &InvocationPusher{
+		pusher:       pusher,
+		flushTimeout: defaultFlushTimeout,
+		invocations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "serverless_invocations_total",
+			Help: "Total number of invocations of the serverless function in the execution environment.",
+		}, []string{"cold_start"}),
+	}
+	pusher.Collector(info).Collector(start).Collector(ip.invocations)
+	return ip
+}
+
+// FlushInterval configures the InvocationPusher to flush after an invocation
+// only if the previous flush is at least the provided duration ago, which
+// saves the time of a push for frequently invoked functions. Metrics of
+// invocations without flush are pushed with the next flush, which might only
+// happen with the next invocation, as the environment is frozen in between.
+// By default, the InvocationPusher flushes after every invocation. For
+// convenience, this method returns a pointer to the InvocationPusher itself.
+func (ip *InvocationPusher) FlushInterval(d time.Duration) *InvocationPusher {
+	ip.flushInterval = d
+	return ip
+}
+
+// FlushTimeout configures the maximum duration of a flush after an
+// invocation. The default is 2s. For convenience, this method returns a
+// pointer to the InvocationPusher itself.
+func (ip *InvocationPusher) FlushTimeout(d time.Duration) *InvocationPusher {
+	ip.flushTimeout = d
+	return ip
+}
+
+// UseAdd configures the InvocationPusher to use Pusher.Add rather than
+// Pusher.Push. For convenience, this method returns a pointer to the
+// InvocationPusher itself.
+func (ip *InvocationPusher) UseAdd() *InvocationPusher {
+	ip.add = true
+	return ip
+}
+
+// ErrorHandler configures a function that is called with the error of every
+// failed flush after an invocation. By default, these errors are dropped, so
+// that they don't fail the invocation. For convenience, this method returns a
+// pointer to the InvocationPusher itself.
+func (ip *InvocationPusher) ErrorHandler(fn func(error)) *InvocationPusher {
+	ip.onError = fn
+	return ip
+}
+
+// Invoke runs fn as an invocation of the function and flushes the metrics
+// afterwards (subject to FlushInterval), even if fn panics. It returns the
+// error of fn. The flush is bounded by FlushTimeout, but not by ctx, which
+// usually carries the deadline of the invocation. For example, with the AWS
+// Lambda Go runtime:
+//
+//	ip := push.NewInvocationPusher(pusher, push.DetectEnvironment())
+//	lambda.Start(func(ctx context.Context, event Event) error {
+//	    return ip.Invoke(ctx, func(ctx context.Context) error {
+//	        return handle(ctx, event)
+//	    })
+//	})
+func (ip *InvocationPusher) Invoke(ctx context.Context, fn func(context.Context) error) error {
+	coldStart := !ip.started.Swap(true)
+	ip.invocations.WithLabelValues(strconv.FormatBool(coldStart)).Inc()
+	defer func() {
+		if !ip.due() {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), ip.flushTimeout)
+		defer cancel()
+		if err := ip.Flush(ctx); err != nil && ip.onError != nil {
+			ip.onError(err)
+		}
+	}()
+	return fn(ctx)
+}
+
+// due returns whether a flush after an invocation is due.
+func (ip *InvocationPusher) due() bool {
+	ip.mu.Lock()
+	defer ip.mu.Unlock()
+	return ip.flushInterval <= 0 || time.Since(ip.lastFlush) >= ip.flushInterval
+}
+
+// Flush pushes the metrics right away, regardless of FlushInterval. Call it
+// when the platform is about to freeze or shut down the environment. A failed
+// flush doesn't count for FlushInterval, i.e. the next invocation flushes
+// again.
+func (ip *InvocationPusher) Flush(ctx context.Context) error {
+	var err error
+	if ip.add {
+		err = ip.pusher.AddContext(ctx)
+	} else {
+		err = ip.pusher.PushContext(ctx)
+	}
+	if err == nil {
+		ip.mu.Lock()
+		ip.lastFlush = time.Now()
+		ip.mu.Unlock()
+	}
+	return err
+}
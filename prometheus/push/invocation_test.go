@@ -0,0 +1,114 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/expfmt"
+)
+
+func TestInvocationPusher(t *testing.T) {
+	var (
+		paths  []string
+		bodies []string
+		fail   bool
+	)
+	pgw := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if fail {
+			http.Error(w, "fake error", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer pgw.Close()
+
+	var flushErrs []error
+	p := New(pgw.URL, "fn").Format(expfmt.NewFormat(expfmt.TypeTextPlain))
+	ip := NewInvocationPusher(p, Environment{ID: "env1", Function: "resize", Region: "eu-west-1"}).
+		FlushInterval(time.Hour).
+		ErrorHandler(func(err error) { flushErrs = append(flushErrs, err) })
+
+	invoke := func(err error) error {
+		return ip.Invoke(context.Background(), func(context.Context) error { return err })
+	}
+	errFn := errors.New("invocation failed")
+	if err := invoke(errFn); !errors.Is(err, errFn) {
+		t.Errorf("got error %v, want %v", err, errFn)
+	}
+	if len(bodies) != 1 {
+		t.Fatalf("got %d pushes after the first invocation, want 1", len(bodies))
+	}
+	if want := "/metrics/job/fn/instance/env1"; paths[0] != want {
+		t.Errorf("got path %q, want %q", paths[0], want)
+	}
+	for _, want := range []string{
+		`serverless_environment_info{function="resize",region="eu-west-1",version=""} 1`,
+		`serverless_invocations_total{cold_start="true"} 1`,
+		"serverless_environment_start_timestamp_seconds ",
+	} {
+		if !strings.Contains(bodies[0], want) {
+			t.Errorf("pushed metrics lack %q:\n%s", want, bodies[0])
+		}
+	}
+
+	// Within the flush interval, the next invocation doesn't flush, but
+	// an explicit Flush does.
+	if err := invoke(nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(bodies) != 1 {
+		t.Fatalf("got %d pushes within the flush interval, want 1", len(bodies))
+	}
+	if err := ip.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(bodies[1], `serverless_invocations_total{cold_start="false"} 1`) {
+		t.Errorf("flushed metrics lack the warm invocation:\n%s", bodies[1])
+	}
+
+	// A failed flush is reported and doesn't count for the interval.
+	ip.FlushInterval(0)
+	fail = true
+	if err := invoke(nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(flushErrs) != 1 {
+		t.Errorf("got %d flush errors, want 1", len(flushErrs))
+	}
+
+	// Flushes despite a panic.
+	fail = false
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("panic not propagated")
+			}
+		}()
+		_ = ip.Invoke(context.Background(), func(context.Context) error { panic("boom") })
+	}()
+	if len(bodies) != 4 {
+		t.Errorf("got %d pushes, want 4", len(bodies))
+	}
+}
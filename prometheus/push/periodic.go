@@ -0,0 +1,230 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var errPeriodicPusherStarted = errors.New("periodic pusher already started")
+
+// PeriodicPusher pushes metrics with a Pusher in regular intervals in a
+// background goroutine. Use NewPeriodicPusher to create one, configure it with
+// its methods, and then call Start. Call Stop to stop pushing.
+type PeriodicPusher struct {
+	error error
+
+	pusher     *Pusher
+	interval   time.Duration
+	jitter     float64
+	maxBackoff time.Duration
+	add        bool
+	onError    func(error)
+
+	lastSuccess, lastTimestamp prometheus.Gauge
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewPeriodicPusher creates a new PeriodicPusher that pushes with the provided
+// Pusher every interval (which must be positive). By default, it uses
+// Pusher.Push, i.e. it replaces all metrics of the grouping key with every
+// push.
+func NewPeriodicPusher(pusher *Pusher, interval time.Duration) *PeriodicPusher {
+	pp := &PeriodicPusher{
+		pusher:     pusher,
+		interval:   interval,
+		maxBackoff: 10 * interval,
+		lastSuccess: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "push_last_push_success",
+			Help: "Whether the last periodic push to the Pushgateway succeeded (1) or not (0).",
+		}),
+		lastTimestamp: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "push_last_push_timestamp_seconds",
+			Help: "Unix timestamp of the last periodic push attempt to the Pushgateway.",
+		}),
+	}
+	if interval <= 0 {
+		pp.error = errors.New("push interval must be positive")
+	}
+	return pp
+}
+
+// Jitter configures the PeriodicPusher to randomize each interval by the
+// provided fraction, e.g. 0.1 results in intervals between 90% and 110% of the
+// nominal interval. This avoids many processes pushing in lockstep. Values are
+// clamped to the range [0, 1]. For convenience, this method returns a pointer
+// to the PeriodicPusher itself.
+func (pp *PeriodicPusher) Jitter(fraction float64) *PeriodicPusher {
+	pp.jitter = min(max(fraction, 0), 1)
+	return pp
+}
+
+// MaxFailureBackoff configures the maximum interval between pushes after
+// failed pushes. After each consecutive failure, the interval is doubled until
+// this maximum is reached. A successful push resets the interval. The default
+// is 10 times the push interval. For convenience, this method returns a
+// pointer to the PeriodicPusher itself.
+func (pp *PeriodicPusher) MaxFailureBackoff(d time.Duration) *PeriodicPusher {
+	pp.maxBackoff = d
+	return pp
+}
+
+// UseAdd configures the PeriodicPusher to use Pusher.Add rather than
+// Pusher.Push, i.e. to only replace previously pushed metrics with the same
+// name. For convenience, this method returns a pointer to the PeriodicPusher
+// itself.
+func (pp *PeriodicPusher) UseAdd() *PeriodicPusher {
+	pp.add = true
+	return pp
+}
+
+// ErrorHandler configures a function that is called with the error of every
+// failed push. By default, errors are only reflected in the
+// push_last_push_success metric. For convenience, this method returns a pointer
+// to the PeriodicPusher itself.
+func (pp *PeriodicPusher) ErrorHandler(fn func(error)) *PeriodicPusher {
+	pp.onError = fn
+	return pp
+}
+
+// Registerer registers the metrics of the PeriodicPusher with the provided
+// Registerer:
+//
+// push_last_push_success
+// push_last_push_timestamp_seconds
+//
+// Note that registering them with a Gatherer of the Pusher results in the
+// metrics being pushed, too, each reflecting the previous push. For
+// convenience, this method returns a pointer to the PeriodicPusher itself.
+func (pp *PeriodicPusher) Registerer(reg prometheus.Registerer) *PeriodicPusher {
+	if pp.error != nil {
+		return pp
+	}
+	for _, c := range []prometheus.Collector{pp.lastSuccess, pp.lastTimestamp} {
+		if err := reg.Register(c); err != nil {
+			pp.error = err
+			return pp
+		}
+	}
+	return pp
+}
+
+// Start starts pushing in a background goroutine. The first push happens
+// immediately. Start returns an error if the PeriodicPusher is already
+// running, or the first error encountered by any method call of the
+// PeriodicPusher or the Pusher.
+func (pp *PeriodicPusher) Start() error {
+	if pp.error != nil {
+		return pp.error
+	}
+	if err := pp.pusher.Error(); err != nil {
+		return err
+	}
+
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+	if pp.cancel != nil {
+		return errPeriodicPusherStarted
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	pp.cancel = cancel
+	pp.done = make(chan struct{})
+	go pp.run(ctx, pp.done)
+	return nil
+}
+
+// Stop stops pushing and waits for the background goroutine to exit. A push
+// in progress is canceled. Stop is a no-op if the PeriodicPusher isn't
+// running. A stopped PeriodicPusher can be started again.
+func (pp *PeriodicPusher) Stop() {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+	if pp.cancel == nil {
+		return
+	}
+	pp.cancel()
+	<-pp.done
+	pp.cancel, pp.done = nil, nil
+}
+
+func (pp *PeriodicPusher) run(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+
+	failures := 0
+	for {
+		if pp.pushOnce(ctx) {
+			failures = 0
+		} else if ctx.Err() == nil {
+			failures++
+		}
+
+		t := time.NewTimer(pp.nextInterval(failures))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// pushOnce performs a single push and updates the metrics accordingly. It
+// returns whether the push succeeded.
+func (pp *PeriodicPusher) pushOnce(ctx context.Context) bool {
+	var err error
+	if pp.add {
+		err = pp.pusher.AddContext(ctx)
+	} else {
+		err = pp.pusher.PushContext(ctx)
+	}
+	if ctx.Err() != nil {
+		// Stopped, don't report the canceled push.
+		return false
+	}
+	pp.lastTimestamp.SetToCurrentTime()
+	if err != nil {
+		pp.lastSuccess.Set(0)
+		if pp.onError != nil {
+			pp.onError(err)
+		}
+		return false
+	}
+	pp.lastSuccess.Set(1)
+	return true
+}
+
+// nextInterval returns the time to wait for the next push after the provided
+// number of consecutive failures.
+func (pp *PeriodicPusher) nextInterval(failures int) time.Duration {
+	d := pp.interval
+	for i := 0; i < failures && d < pp.maxBackoff; i++ {
+		d *= 2
+	}
+	if failures > 0 {
+		d = max(min(d, pp.maxBackoff), pp.interval)
+	}
+	if pp.jitter > 0 {
+		d = time.Duration(float64(d) * (1 + pp.jitter*(2*rand.Float64()-1)))
+	}
+	return d
+}
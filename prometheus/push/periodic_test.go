@@ -0,0 +1,141 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestPeriodicPusher(t *testing.T) {
+	var (
+		pushes atomic.Int32
+		fail   atomic.Bool
+	)
+	pgw := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPut {
+				t.Errorf("got method %q, want %q", r.Method, http.MethodPut)
+			}
+			pushes.Add(1)
+			if fail.Load() {
+				http.Error(w, "fake error", http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+	defer pgw.Close()
+
+	reg := prometheus.NewRegistry()
+	var errs atomic.Int32
+	pp := NewPeriodicPusher(New(pgw.URL, "testjob"), 10*time.Millisecond).
+		Jitter(0.1).
+		ErrorHandler(func(error) { errs.Add(1) }).
+		Registerer(reg)
+	if err := pp.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := pp.Start(); !errors.Is(err, errPeriodicPusherStarted) {
+		t.Errorf("got error %v when starting twice, want %v", err, errPeriodicPusherStarted)
+	}
+
+	waitFor(t, func() bool { return pushes.Load() >= 3 })
+	waitFor(t, func() bool { return gaugeValue(t, reg, "push_last_push_success") == 1 })
+	if ts := gaugeValue(t, reg, "push_last_push_timestamp_seconds"); ts <= 0 {
+		t.Errorf("got last push timestamp %v, want positive value", ts)
+	}
+
+	fail.Store(true)
+	waitFor(t, func() bool { return errs.Load() >= 1 })
+	waitFor(t, func() bool { return gaugeValue(t, reg, "push_last_push_success") == 0 })
+
+	pp.Stop()
+	n := pushes.Load()
+	time.Sleep(50 * time.Millisecond)
+	if got := pushes.Load(); got != n {
+		t.Errorf("got %d pushes after Stop, want none", got-n)
+	}
+	pp.Stop() // No-op.
+
+	// Can be restarted.
+	fail.Store(false)
+	if err := pp.Start(); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return pushes.Load() > n })
+	pp.Stop()
+}
+
+func TestPeriodicPusherErrors(t *testing.T) {
+	if err := NewPeriodicPusher(New("localhost", "testjob"), 0).Start(); err == nil {
+		t.Error("starting with zero interval succeeded")
+	}
+	if err := NewPeriodicPusher(New("localhost", ""), time.Second).Start(); !errors.Is(err, errJobEmpty) {
+		t.Errorf("got error %v, want %v", err, errJobEmpty)
+	}
+
+	reg := prometheus.NewRegistry()
+	NewPeriodicPusher(New("localhost", "testjob"), time.Second).Registerer(reg)
+	if err := NewPeriodicPusher(New("localhost", "testjob"), time.Second).Registerer(reg).Start(); err == nil {
+		t.Error("starting with duplicate metrics registration succeeded")
+	}
+}
+
+func TestPeriodicPusherNextInterval(t *testing.T) {
+	pp := NewPeriodicPusher(New("localhost", "testjob"), time.Second).MaxFailureBackoff(5 * time.Second)
+	for failures, want := range map[int]time.Duration{
+		0:  time.Second,
+		1:  2 * time.Second,
+		2:  4 * time.Second,
+		3:  5 * time.Second,
+		10: 5 * time.Second,
+	} {
+		if got := pp.nextInterval(failures); got != want {
+			t.Errorf("%d failures: got interval %s, want %s", failures, got, want)
+		}
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func gaugeValue(t *testing.T, g prometheus.Gatherer, name string) float64 {
+	t.Helper()
+	mfs, err := g.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mf := range mfs {
+		if mf.GetName() == name {
+			return mf.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+	t.Fatalf("metric %s not found", name)
+	return 0
+}
@@ -44,11 +44,16 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/prometheus/common/expfmt"
 	"github.com/prometheus/common/model"
 
 	"github.com/prometheus/client_golang/prometheus"
+
+	dto "github.com/prometheus/client_model/go"
 )
 
 const (
@@ -65,23 +70,78 @@ type HTTPDoer interface {
 	Do(*http.Request) (*http.Response, error)
 }
 
+// httpConfig holds the HTTP client configuration shared by Pusher and Admin.
+type httpConfig struct {
+	client HTTPDoer
+	header http.Header
+	auth   authorizer
+}
+
+// newRequest creates a request with the configured headers and authentication.
+// Any headers in header are set in addition to the configured ones.
+func (c *httpConfig) newRequest(ctx context.Context, method, url string, body []byte, header http.Header) (*http.Request, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, r)
+	if err != nil {
+		return nil, err
+	}
+	if c.header != nil {
+		req.Header = c.header.Clone()
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if c.auth != nil {
+		if err := c.auth.authorize(req, body); err != nil {
+			return nil, fmt.Errorf("authorizing request: %w", err)
+		}
+	}
+	return req, nil
+}
+
 // Pusher manages a push to the Pushgateway. Use New to create one, configure it
 // with its methods, and finally use the Add or Push method to push.
 type Pusher struct {
 	error error
 
-	url, job string
+	// urls of the Pushgateways to push to, see Targets.
+	urls     []string
+	job      string
 	grouping map[string]string
 
 	gatherers  prometheus.Gatherers
 	registerer prometheus.Registerer
 
-	client             HTTPDoer
-	header             http.Header
-	useBasicAuth       bool
-	username, password string
+	// gathering is the gathering in progress, if any, see gather.
+	gatherMtx sync.Mutex
+	gathering *gatherCall
+
+	httpConfig
 
-	expfmt expfmt.Format
+	// formats in order of preference, see Formats. formatIdx is the index
+	// of the format last accepted by the Pushgateway.
+	formats   []expfmt.Format
+	formatIdx atomic.Int32
+
+	retry   RetryPolicy
+	timeout time.Duration
+
+	compression          Compression
+	compressionThreshold int
+
+	successPolicy SuccessPolicy
+	onResults     func([]TargetResult)
+
+	remoteWrite bool
+
+	dryRun         bool
+	dryRunWriter   io.Writer
+	maxPayloadSize int
+
+	metrics *pusherMetrics
 }
 
 // New creates a new Pusher to push to the provided URL with the provided job
@@ -96,21 +156,25 @@ func New(url, job string) *Pusher {
 	if job == "" {
 		err = errJobEmpty
 	}
-	if !strings.Contains(url, "://") {
-		url = "http://" + url
-	}
-	url = strings.TrimSuffix(url, "/")
-
 	return &Pusher{
 		error:      err,
-		url:        url,
+		urls:       []string{normalizeURL(url)},
 		job:        job,
 		grouping:   map[string]string{},
 		gatherers:  prometheus.Gatherers{reg},
 		registerer: reg,
-		client:     &http.Client{},
-		expfmt:     expfmt.NewFormat(expfmt.TypeProtoDelim),
+		httpConfig: httpConfig{client: &http.Client{}},
+		formats:    []expfmt.Format{expfmt.NewFormat(expfmt.TypeProtoDelim)},
+	}
+}
+
+// normalizeURL adds “http://” to url if it has no schema and removes any
+// trailing slash.
+func normalizeURL(url string) string {
+	if !strings.Contains(url, "://") {
+		url = "http://" + url
 	}
+	return strings.TrimSuffix(url, "/")
 }
 
 // Push collects/gathers all metrics from all Collectors and Gatherers added to
@@ -128,7 +192,12 @@ func (p *Pusher) Push() error {
 
 // PushContext is like Push but includes a context.
 //
-// If the context expires before HTTP request is complete, an error is returned.
+// The context applies to the whole push, i.e. gathering, encoding, and sending
+// the metrics (including any retries). If the context expires before the push
+// is complete, an error is returned. Note that Collectors and Gatherers are
+// not aware of the context. If the context expires while gathering, PushContext
+// returns immediately, but the ongoing gathering completes in the background,
+// and its result is discarded.
 func (p *Pusher) PushContext(ctx context.Context) error {
 	return p.push(ctx, http.MethodPut)
 }
@@ -142,7 +211,7 @@ func (p *Pusher) Add() error {
 
 // AddContext is like Add but includes a context.
 //
-// If the context expires before HTTP request is complete, an error is returned.
+// The context is used in the same way as for PushContext.
 func (p *Pusher) AddContext(ctx context.Context) error {
 	return p.push(ctx, http.MethodPost)
 }
@@ -210,12 +279,11 @@ func (p *Pusher) Header(header http.Header) *Pusher {
 }
 
 // BasicAuth configures the Pusher to use HTTP Basic Authentication with the
-// provided username and password. For convenience, this method returns a
-// pointer to the Pusher itself.
+// provided username and password, replacing any other authentication method
+// configured before. For convenience, this method returns a pointer to the
+// Pusher itself.
 func (p *Pusher) BasicAuth(username, password string) *Pusher {
-	p.useBasicAuth = true
-	p.username = username
-	p.password = password
+	p.auth = basicAuth{username: username, password: password}
 	return p
 }
 
@@ -225,7 +293,17 @@ func (p *Pusher) BasicAuth(username, password string) *Pusher {
 // implementations may require different formats. For convenience, this
 // method returns a pointer to the Pusher itself.
 func (p *Pusher) Format(format expfmt.Format) *Pusher {
-	p.expfmt = format
+	return p.Formats(format)
+}
+
+// Timeout sets a default timeout for each Push, Add, and Delete call (and their
+// context-aware variants) of the Pusher, covering gathering, encoding, and
+// sending the metrics, including any retries. A deadline of a context passed to
+// one of the context-aware methods still applies if it expires earlier. A zero
+// or negative timeout (the default) means no timeout. For convenience, this
+// method returns a pointer to the Pusher itself.
+func (p *Pusher) Timeout(timeout time.Duration) *Pusher {
+	p.timeout = timeout
 	return p
 }
 
@@ -237,42 +315,80 @@ func (p *Pusher) Format(format expfmt.Format) *Pusher {
 // Delete returns the first error encountered by any method call (including this
 // one) in the lifetime of the Pusher.
 func (p *Pusher) Delete() error {
+	return p.DeleteContext(context.Background())
+}
+
+// DeleteContext is like Delete but includes a context.
+//
+// If the context expires before the HTTP request (including any retries) is
+// complete, an error is returned.
+func (p *Pusher) DeleteContext(ctx context.Context) (err error) {
 	if p.error != nil {
 		return p.error
 	}
-	req, err := http.NewRequest(http.MethodDelete, p.fullURL(), nil)
-	if err != nil {
-		return err
-	}
-	if p.header != nil {
-		req.Header = p.header
+	defer func(start time.Time) {
+		p.metrics.observeOperation(http.MethodDelete, start, err)
+	}(time.Now())
+	if p.remoteWrite {
+		return errRemoteWriteDelete
 	}
-	if p.useBasicAuth {
-		req.SetBasicAuth(p.username, p.password)
+	if p.dryRun {
+		return nil
 	}
-	resp, err := p.client.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusAccepted {
-		body, _ := io.ReadAll(resp.Body) // Ignore any further error as this is for an error message only.
-		return fmt.Errorf("unexpected status code %d while deleting %s: %s", resp.StatusCode, p.fullURL(), body)
-	}
-	return nil
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	return p.fanOut(ctx, func(ctx context.Context, baseURL string) error {
+		url := p.fullURL(baseURL)
+		resp, err := p.send(ctx, http.MethodDelete, url, nil, nil)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusAccepted {
+			body, _ := io.ReadAll(resp.Body) // Ignore any further error as this is for an error message only.
+			return fmt.Errorf("unexpected status code %d while deleting %s: %s", resp.StatusCode, url, body)
+		}
+		return nil
+	})
 }
 
 func (p *Pusher) push(ctx context.Context, method string) error {
+	return p.pushWith(ctx, method, p.gather)
+}
+
+// pushWith is like push, but obtains the metric families from gather.
+func (p *Pusher) pushWith(ctx context.Context, method string, gather func(context.Context) ([]*dto.MetricFamily, error)) (err error) {
 	if p.error != nil {
 		return p.error
 	}
-	mfs, err := p.gatherers.Gather()
+	defer func(start time.Time) {
+		p.metrics.observeOperation(method, start, err)
+	}(time.Now())
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	mfs, err := gather(ctx)
 	if err != nil {
 		return err
 	}
-	buf := &bytes.Buffer{}
-	enc := expfmt.NewEncoder(buf, p.expfmt)
-	// Check for pre-existing grouping labels:
+	if err := p.checkGroupingLabels(mfs); err != nil {
+		return err
+	}
+	if p.remoteWrite {
+		return p.pushRemoteWrite(ctx, mfs)
+	}
+	if p.dryRun {
+		return p.dryRunPush(ctx, mfs)
+	}
+	// Encode each format at most once, no matter the number of targets.
+	bodies := make([]encodedBody, len(p.formats))
+	return p.fanOut(ctx, func(ctx context.Context, baseURL string) error {
+		return p.pushTo(ctx, method, p.fullURL(baseURL), mfs, bodies)
+	})
+}
+
+// checkGroupingLabels returns an error if any of the metrics already contains
+// the job label or one of the grouping labels.
+func (p *Pusher) checkGroupingLabels(mfs []*dto.MetricFamily) error {
 	for _, mf := range mfs {
 		for _, m := range mf.GetMetric() {
 			for _, l := range m.GetLabel() {
@@ -287,59 +403,223 @@ func (p *Pusher) push(ctx context.Context, method string) error {
 				}
 			}
 		}
-		if err := enc.Encode(mf); err != nil {
-			return fmt.Errorf(
-				"failed to encode metric family %s, error is %w",
-				mf.GetName(), err)
+	}
+	return nil
+}
+
+// statusError is returned if the receiver of a push responds with an
+// unexpected status code.
+type statusError struct {
+	code int
+	msg  string
+}
+
+func (e *statusError) Error() string { return e.msg }
+
+// encodedBody is a request body in one of the formats of the Pusher, encoded
+// (and compressed) on first use.
+type encodedBody struct {
+	once     sync.Once
+	body     []byte
+	encoding string
+	err      error
+}
+
+// pushTo pushes the metric families to the provided URL, falling back to the
+// next format of the Pusher if the Pushgateway doesn't support one.
+func (p *Pusher) pushTo(ctx context.Context, method, url string, mfs []*dto.MetricFamily, bodies []encodedBody) error {
+	for i := int(p.formatIdx.Load()); ; i++ {
+		format := p.formats[i]
+		b := &bodies[i]
+		b.once.Do(func() {
+			var buf []byte
+			if buf, b.err = encode(ctx, mfs, format); b.err != nil {
+				return
+			}
+			if b.body, b.encoding, b.err = p.compress(buf); b.err != nil {
+				b.err = fmt.Errorf("failed to compress request body: %w", b.err)
+				return
+			}
+			b.err = p.checkPayloadSize(len(b.body))
+		})
+		if b.err != nil {
+			return b.err
 		}
+		header := http.Header{contentTypeHeader: []string{string(format)}}
+		if b.encoding != "" {
+			header.Set(contentEncodingHeader, b.encoding)
+		}
+		resp, err := p.send(ctx, method, url, b.body, header)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode == http.StatusUnsupportedMediaType && i+1 < len(p.formats) {
+			// Fall back to the next format.
+			io.Copy(io.Discard, resp.Body) //nolint:errcheck // Only draining for connection reuse.
+			resp.Body.Close()
+			continue
+		}
+		defer resp.Body.Close()
+		// Depending on version and configuration of the PGW, StatusOK or StatusAccepted may be returned.
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+			body, _ := io.ReadAll(resp.Body) // Ignore any further error as this is for an error message only.
+			return &statusError{
+				code: resp.StatusCode,
+				msg:  fmt.Sprintf("unexpected status code %d while pushing to %s: %s", resp.StatusCode, url, body),
+			}
+		}
+		p.formatIdx.Store(int32(i))
+		return nil
 	}
-	req, err := http.NewRequestWithContext(ctx, method, p.fullURL(), buf)
-	if err != nil {
-		return err
+}
+
+// withTimeout returns a context derived from ctx that is canceled after the
+// configured timeout, if any.
+func (p *Pusher) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if p.timeout <= 0 {
+		return context.WithCancel(ctx)
 	}
-	if p.header != nil {
-		req.Header = p.header
+	return context.WithTimeout(ctx, p.timeout)
+}
+
+// gatherCall is a gathering from the Gatherers of a Pusher. Its result is set
+// before done is closed.
+type gatherCall struct {
+	done chan struct{}
+	mfs  []*dto.MetricFamily
+	err  error
+}
+
+// gather gathers from all Gatherers of the Pusher. As Gatherers are not
+// context-aware, gathering happens in a separate goroutine, so that gather can
+// return as soon as ctx is done. The abandoned gathering still runs to
+// completion, but there is at most one gathering in progress: If gather is
+// called while a previous gathering is still in progress, e.g. because a
+// Collector is stuck, it waits for the result of that gathering instead of
+// starting a new one.
+func (p *Pusher) gather(ctx context.Context) ([]*dto.MetricFamily, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
-	if p.useBasicAuth {
-		req.SetBasicAuth(p.username, p.password)
+	p.gatherMtx.Lock()
+	call := p.gathering
+	if call == nil {
+		call = &gatherCall{done: make(chan struct{})}
+		p.gathering = call
+		go func() {
+			call.mfs, call.err = p.gatherers.Gather()
+			p.gatherMtx.Lock()
+			p.gathering = nil
+			p.gatherMtx.Unlock()
+			close(call.done)
+		}()
 	}
-	req.Header.Set(contentTypeHeader, string(p.expfmt))
-	resp, err := p.client.Do(req)
-	if err != nil {
-		return err
+	p.gatherMtx.Unlock()
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("gathering metrics: %w", ctx.Err())
+	case <-call.done:
+		return call.mfs, call.err
 	}
-	defer resp.Body.Close()
-	// Depending on version and configuration of the PGW, StatusOK or StatusAccepted may be returned.
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
-		body, _ := io.ReadAll(resp.Body) // Ignore any further error as this is for an error message only.
-		return fmt.Errorf("unexpected status code %d while pushing to %s: %s", resp.StatusCode, p.fullURL(), body)
+}
+
+// send sends a request with the provided method and body to the provided URL,
+// retrying according to the configured RetryPolicy. Any headers in header are
+// set in addition to the headers configured for the Pusher. The caller is
+// responsible for closing the body of the returned response.
+func (p *Pusher) send(ctx context.Context, method, url string, body []byte, header http.Header) (*http.Response, error) {
+	if p.retry.Budget <= 0 {
+		return p.sendWithRetries(ctx, method, url, body, header)
+	}
+	// The budget also applies to reading the body of the returned
+	// response, so the context is only canceled once the body is closed.
+	ctx, cancel := context.WithTimeout(ctx, p.retry.Budget)
+	resp, err := p.sendWithRetries(ctx, method, url, body, header)
+	if resp == nil {
+		cancel()
+		return resp, err
+	}
+	resp.Body = &cancelOnCloseBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, err
+}
+
+// cancelOnCloseBody is a response body that cancels the context of its
+// request once it is closed.
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// sendWithRetries is the implementation of send. The retry budget, if any,
+// has to be applied to ctx already.
+func (p *Pusher) sendWithRetries(ctx context.Context, method, url string, body []byte, header http.Header) (*http.Response, error) {
+	start := time.Now()
+	for attempt := 0; ; attempt++ {
+		req, err := p.newRequest(ctx, method, url, body, header)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := p.client.Do(req)
+		if resp != nil {
+			p.metrics.observeRequest(method, resp.StatusCode, len(body))
+			if resp.StatusCode == http.StatusUnauthorized {
+				p.invalidateAuth()
+			}
+		} else {
+			p.metrics.observeRequest(method, 0, len(body))
+		}
+		if attempt >= p.retry.MaxRetries || !p.retry.retryable(ctx, resp, err) {
+			return resp, err
+		}
+		wait := p.retry.backoff(attempt+1, resp)
+		if p.retry.Budget > 0 && time.Since(start)+wait > p.retry.Budget {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body) //nolint:errcheck // Only draining for connection reuse.
+			resp.Body.Close()
+		}
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, err
+		}
 	}
-	return nil
 }
 
-// fullURL assembles the URL used to push/delete metrics and returns it as a
-// string. The job name and any grouping label values containing a '/' will
-// trigger a base64 encoding of the affected component and proper suffixing of
-// the preceding component. Similarly, an empty grouping label value will be
+// fullURL assembles the URL used to push/delete metrics to the Pushgateway at
+// baseURL and returns it as a string. The job name and any grouping label
+// values containing a '/' will trigger a base64 encoding of the affected
+// component and proper suffixing of the preceding component. Similarly, an empty grouping label value will be
 // encoded as base64 just with a single `=` padding character (to avoid an empty
 // path component). If the component does not contain a '/' but other special
 // characters, the usual url.QueryEscape is used for compatibility with older
 // versions of the Pushgateway and for better readability.
-func (p *Pusher) fullURL() string {
+func (p *Pusher) fullURL(baseURL string) string {
+	return groupingURL(baseURL, p.job, p.grouping)
+}
+
+// groupingURL is the implementation of fullURL for arbitrary base URLs, job
+// names, and grouping labels.
+func groupingURL(baseURL, job string, grouping map[string]string) string {
 	urlComponents := []string{}
-	if encodedJob, base64 := encodeComponent(p.job); base64 {
+	if encodedJob, base64 := encodeComponent(job); base64 {
 		urlComponents = append(urlComponents, "job"+base64Suffix, encodedJob)
 	} else {
 		urlComponents = append(urlComponents, "job", encodedJob)
 	}
-	for ln, lv := range p.grouping {
+	for ln, lv := range grouping {
 		if encodedLV, base64 := encodeComponent(lv); base64 {
 			urlComponents = append(urlComponents, ln+base64Suffix, encodedLV)
 		} else {
 			urlComponents = append(urlComponents, ln, encodedLV)
 		}
 	}
-	return fmt.Sprintf("%s/metrics/%s", p.url, strings.Join(urlComponents, "/"))
+	return fmt.Sprintf("%s/metrics/%s", baseURL, strings.Join(urlComponents, "/"))
 }
 
 // encodeComponent encodes the provided string with base64.RawURLEncoding in
@@ -15,15 +15,20 @@ package push
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/prometheus/common/expfmt"
 
 	"github.com/prometheus/client_golang/prometheus"
+
+	dto "github.com/prometheus/client_model/go"
 )
 
 func TestPush(t *testing.T) {
@@ -307,3 +312,99 @@ func TestPush(t *testing.T) {
 		t.Error("empty Authorization header")
 	}
 }
+
+func TestPushContext(t *testing.T) {
+	pgw := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodDelete {
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+	defer pgw.Close()
+
+	// Fake a Pushgateway that never responds.
+	pgwSlow := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}),
+	)
+	defer pgwSlow.Close()
+
+	unblock := make(chan struct{})
+	defer close(unblock)
+	blockingGatherer := prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		<-unblock
+		return nil, nil
+	})
+
+	// Gathering respects the timeout of the Pusher.
+	start := time.Now()
+	err := New(pgw.URL, "testjob").
+		Gatherer(blockingGatherer).
+		Timeout(50 * time.Millisecond).
+		Push()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("push took %s despite timeout", d)
+	}
+
+	// Gathering respects the context.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := New(pgw.URL, "testjob").
+		Gatherer(blockingGatherer).
+		AddContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+
+	// Sending respects the timeout of the Pusher.
+	if err := New(pgwSlow.URL, "testjob").
+		Timeout(50 * time.Millisecond).
+		Delete(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// An earlier deadline of the context wins.
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := New(pgw.URL, "testjob").
+		Gatherer(blockingGatherer).
+		Timeout(time.Hour).
+		PushContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// Pushes timing out while gathering don't pile up gatherings.
+	var gatherings atomic.Int32
+	p := New(pgw.URL, "testjob").
+		Gatherer(prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+			gatherings.Add(1)
+			<-unblock
+			return nil, nil
+		})).
+		Timeout(10 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		if err := p.Push(); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+		}
+	}
+	if got := gatherings.Load(); got != 1 {
+		t.Errorf("got %d gatherings in progress, want 1", got)
+	}
+
+	// All good with a sufficient timeout.
+	if err := New(pgw.URL, "testjob").
+		Timeout(time.Minute).
+		Push(); err != nil {
+		t.Error(err)
+	}
+	if err := New(pgw.URL, "testjob").
+		DeleteContext(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
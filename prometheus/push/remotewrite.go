@@ -0,0 +1,93 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/klauspost/compress/snappy"
+
+	"github.com/prometheus/client_golang/api/remotewrite"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+const remoteWriteVersionHeader = "X-Prometheus-Remote-Write-Version"
+
+var errRemoteWriteDelete = errors.New("delete is not supported by the remote-write protocol")
+
+// RemoteWrite configures the Pusher to send the metrics to a Prometheus
+// remote-write endpoint (protocol version 1.0) instead of a Pushgateway, which
+// lets ephemeral jobs skip the Pushgateway entirely. The URLs passed to New and
+// Targets are then used as is, i.e. they have to include the path of the
+// endpoint, e.g. “http://prometheus:9090/api/v1/write”.
+//
+// The job name and the grouping labels are added as labels to each series. The
+// samples are timestamped with the time of the push, unless the metric
+// carries an explicit timestamp. Push and Add behave the same, as remote write
+// only appends samples, while Delete always fails. The configured formats and
+// compression are ignored, as the protocol mandates snappy-compressed
+// protobuf. All other options (like retries, authentication, and dry-run mode)
+// apply as usual. The encoding is shared with the remote-write client in
+// package api/remotewrite, which also supports protocol version 2.0. For
+// convenience, this method returns a pointer to the Pusher itself.
+func (p *Pusher) RemoteWrite() *Pusher {
+	p.remoteWrite = true
+	return p
+}
+
+// pushRemoteWrite sends the provided metric families to all targets as a
+// remote-write request.
+func (p *Pusher) pushRemoteWrite(ctx context.Context, mfs []*dto.MetricFamily) error {
+	extLabels := make(map[string]string, len(p.grouping)+1)
+	extLabels["job"] = p.job
+	for ln, lv := range p.grouping {
+		extLabels[ln] = lv
+	}
+	buf, _, err := remotewrite.Marshal(remotewrite.WriteV1, mfs, remotewrite.MarshalOpts{ExternalLabels: extLabels})
+	if err != nil {
+		return err
+	}
+	body := snappy.Encode(nil, buf)
+	if p.dryRun {
+		return p.finishDryRun(mfs, buf, len(body))
+	}
+	if err := p.checkPayloadSize(len(body)); err != nil {
+		return err
+	}
+	header := http.Header{
+		contentTypeHeader:        []string{remotewrite.WriteV1.ContentType()},
+		contentEncodingHeader:    []string{string(Snappy)},
+		remoteWriteVersionHeader: []string{remotewrite.WriteV1.Version()},
+	}
+	return p.fanOut(ctx, func(ctx context.Context, url string) error {
+		resp, err := p.send(ctx, http.MethodPost, url, body, header)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			body, _ := io.ReadAll(resp.Body) // Ignore any further error as this is for an error message only.
+			return &statusError{
+				code: resp.StatusCode,
+				msg:  fmt.Sprintf("unexpected status code %d while writing to %s: %s", resp.StatusCode, url, body),
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,218 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestRemoteWrite(t *testing.T) {
+	var (
+		header http.Header
+		body   []byte
+	)
+	rw := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header = r.Header
+			compressed, err := io.ReadAll(r.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if body, err = snappy.Decode(nil, compressed); err != nil {
+				t.Fatal(err)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	)
+	defer rw.Close()
+
+	counter := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "requests_total",
+		Help: "Total requests.",
+	})
+	counter.Add(3)
+	summary := prometheus.NewSummary(prometheus.SummaryOpts{
+		Name:       "latency_seconds",
+		Help:       "Latency.",
+		Objectives: map[float64]float64{0.5: 0.05},
+	})
+	summary.Observe(2)
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "size_bytes",
+		Help:    "Size.",
+		Buckets: []float64{1, 10},
+	})
+	histogram.Observe(5)
+	native := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:                        "native_seconds",
+		Help:                        "Native.",
+		NativeHistogramBucketFactor: 1.1,
+	})
+	native.Observe(1)
+
+	if err := New(rw.URL+"/api/v1/write", "testjob").
+		Grouping("instance", "a").
+		RemoteWrite().
+		Collector(counter).
+		Collector(summary).
+		Collector(histogram).
+		Collector(native).
+		Push(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := header.Get(contentTypeHeader), "application/x-protobuf"; got != want {
+		t.Errorf("got content type %q, want %q", got, want)
+	}
+	if got := header.Get(contentEncodingHeader); got != "snappy" {
+		t.Errorf("got content encoding %q, want snappy", got)
+	}
+	if got, want := header.Get(remoteWriteVersionHeader), "0.1.0"; got != want {
+		t.Errorf("got version %q, want %q", got, want)
+	}
+
+	samples, histograms, metadata := decodeWriteRequest(t, body)
+	want := []string{
+		`latency_seconds_count{instance="a",job="testjob"} 1`,
+		`latency_seconds_sum{instance="a",job="testjob"} 2`,
+		`latency_seconds{instance="a",job="testjob",quantile="0.5"} 2`,
+		`requests_total{instance="a",job="testjob"} 3`,
+		`size_bytes_bucket{instance="a",job="testjob",le="+Inf"} 1`,
+		`size_bytes_bucket{instance="a",job="testjob",le="1"} 0`,
+		`size_bytes_bucket{instance="a",job="testjob",le="10"} 1`,
+		`size_bytes_count{instance="a",job="testjob"} 1`,
+		`size_bytes_sum{instance="a",job="testjob"} 5`,
+	}
+	if strings.Join(samples, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got samples\n%s\nwant\n%s", strings.Join(samples, "\n"), strings.Join(want, "\n"))
+	}
+	if len(histograms) != 1 || histograms[0] != `native_seconds{instance="a",job="testjob"}` {
+		t.Errorf("got histograms %q, want one for native_seconds", histograms)
+	}
+	if len(metadata) != 4 {
+		t.Errorf("got %d metadata entries, want 4", len(metadata))
+	}
+
+	if err := New(rw.URL, "testjob").RemoteWrite().Delete(); err != errRemoteWriteDelete {
+		t.Errorf("got error %v, want %v", err, errRemoteWriteDelete)
+	}
+}
+
+// Field numbers of the remote-write 1.0 protobuf messages needed for decoding.
+const (
+	rwWriteRequestMetadata = 3
+	rwTimeSeriesLabels     = 1
+	rwTimeSeriesSamples    = 2
+	rwLabelName            = 1
+	rwSampleValue          = 1
+)
+
+// decodeWriteRequest decodes the series of a WriteRequest in a text-like
+// representation, sorted for comparison.
+func decodeWriteRequest(t *testing.T, b []byte) (samples, histograms, metadata []string) {
+	t.Helper()
+	forEachField(t, b, func(num protowire.Number, v []byte, _ uint64) {
+		if num == rwWriteRequestMetadata {
+			metadata = append(metadata, string(v))
+			return
+		}
+		var (
+			labels   []string
+			name     string
+			value    float64
+			isSample bool
+		)
+		forEachField(t, v, func(num protowire.Number, v []byte, _ uint64) {
+			switch num {
+			case rwTimeSeriesLabels:
+				var ln, lv string
+				forEachField(t, v, func(num protowire.Number, v []byte, _ uint64) {
+					if num == rwLabelName {
+						ln = string(v)
+					} else {
+						lv = string(v)
+					}
+				})
+				if ln == "__name__" {
+					name = lv
+				} else {
+					labels = append(labels, ln+"="+`"`+lv+`"`)
+				}
+			case rwTimeSeriesSamples:
+				isSample = true
+				forEachField(t, v, func(num protowire.Number, _ []byte, x uint64) {
+					if num == rwSampleValue {
+						value = math.Float64frombits(x)
+					}
+				})
+			}
+		})
+		series := name + "{" + strings.Join(labels, ",") + "}"
+		if isSample {
+			samples = append(samples, series+" "+strconv.FormatFloat(value, 'g', -1, 64))
+		} else {
+			histograms = append(histograms, series)
+		}
+	})
+	sort.Strings(samples)
+	return samples, histograms, metadata
+}
+
+// forEachField calls fn for each field of the protobuf message b with either
+// the bytes of a length-delimited field or the value of a numeric field.
+func forEachField(t *testing.T, b []byte, fn func(num protowire.Number, v []byte, x uint64)) {
+	t.Helper()
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			t.Fatal(protowire.ParseError(n))
+		}
+		b = b[n:]
+		switch typ {
+		case protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				t.Fatal(protowire.ParseError(n))
+			}
+			fn(num, v, 0)
+			b = b[n:]
+		case protowire.Fixed64Type:
+			x, n := protowire.ConsumeFixed64(b)
+			if n < 0 {
+				t.Fatal(protowire.ParseError(n))
+			}
+			fn(num, nil, x)
+			b = b[n:]
+		default:
+			x, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				t.Fatal(protowire.ParseError(n))
+			}
+			fn(num, nil, x)
+			b = b[n:]
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultInitialBackoff = 100 * time.Millisecond
+	defaultMaxBackoff     = 10 * time.Second
+)
+
+// RetryPolicy defines how a Pusher retries failed requests to the
+// Pushgateway. Use it with Pusher.Retry. The zero value of RetryPolicy
+// disables retries.
+//
+// A request is retried if it failed with a network error, or if the
+// Pushgateway responded with status code 429 (Too Many Requests) or any 5xx
+// status code not listed in NonRetryableStatusCodes. Requests are never
+// retried once the context of the push has expired.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of retries after the initial
+	// attempt. Zero disables retries.
+	MaxRetries int
+	// InitialBackoff is the time to wait before the first retry. It is
+	// doubled for every further retry. Defaults to 100ms.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the time to wait between two attempts, including
+	// any wait time requested via a Retry-After header. Defaults to 10s.
+	MaxBackoff time.Duration
+	// Jitter is the fraction of the backoff that is randomized to avoid
+	// many pushers retrying in lockstep, e.g. 0.2 results in a backoff
+	// between 80% and 120% of the nominal value. Values are clamped to
+	// the range [0, 1].
+	Jitter float64
+	// Budget, if positive, limits the total time spent on a push,
+	// including all attempts and backoffs. No retry is attempted if
+	// waiting for it would exceed the budget.
+	Budget time.Duration
+	// RespectRetryAfter makes the Pusher wait at least as long as
+	// requested by a Retry-After header in a 429 or 503 response (still
+	// capped by MaxBackoff).
+	RespectRetryAfter bool
+	// NonRetryableStatusCodes lists 5xx status codes that are not
+	// retried, e.g. http.StatusNotImplemented.
+	NonRetryableStatusCodes []int
+}
+
+// Retry configures the Pusher to retry failed requests to the Pushgateway
+// according to the provided RetryPolicy. By default, requests are not
+// retried. Retries apply to Push, Add, and Delete and their context-aware
+// variants. For convenience, this method returns a pointer to the Pusher
+// itself.
+func (p *Pusher) Retry(policy RetryPolicy) *Pusher {
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = defaultInitialBackoff
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = defaultMaxBackoff
+	}
+	policy.Jitter = min(max(policy.Jitter, 0), 1)
+	p.retry = policy
+	return p
+}
+
+// retryable reports whether a request that resulted in the provided response
+// or error should be retried.
+func (r *RetryPolicy) retryable(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	if resp.StatusCode < 500 || resp.StatusCode > 599 {
+		return false
+	}
+	for _, code := range r.NonRetryableStatusCodes {
+		if resp.StatusCode == code {
+			return false
+		}
+	}
+	return true
+}
+
+// backoff returns the time to wait before the provided retry (starting at 1),
+// taking into account a Retry-After header of resp if configured.
+func (r *RetryPolicy) backoff(retry int, resp *http.Response) time.Duration {
+	d := r.InitialBackoff
+	for i := 1; i < retry && d < r.MaxBackoff; i++ {
+		d *= 2
+	}
+	if r.Jitter > 0 {
+		d = time.Duration(float64(d) * (1 + r.Jitter*(2*rand.Float64()-1)))
+	}
+	if r.RespectRetryAfter && resp != nil {
+		if ra, ok := retryAfter(resp); ok && ra > d {
+			d = ra
+		}
+	}
+	return min(d, r.MaxBackoff)
+}
+
+// retryAfter parses the Retry-After header of a 429 or 503 response, which
+// may contain either a number of seconds or an HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(max(secs, 0)) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+// sleepContext waits for the provided duration or until ctx is done,
+// whichever happens first. It returns the error of ctx in the latter case.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestPushRetry(t *testing.T) {
+	metric := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "testname",
+		Help: "testhelp",
+	})
+
+	for _, tc := range []struct {
+		name         string
+		codes        []int
+		policy       RetryPolicy
+		wantAttempts int32
+		wantErr      bool
+	}{
+		{
+			name:         "no retries by default",
+			codes:        []int{http.StatusServiceUnavailable, http.StatusOK},
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+		{
+			name:         "retry until success",
+			codes:        []int{http.StatusInternalServerError, http.StatusTooManyRequests, http.StatusOK},
+			policy:       RetryPolicy{MaxRetries: 3, InitialBackoff: time.Millisecond},
+			wantAttempts: 3,
+		},
+		{
+			name:         "retries exhausted",
+			codes:        []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusOK},
+			policy:       RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond, Jitter: 0.5},
+			wantAttempts: 3,
+			wantErr:      true,
+		},
+		{
+			name:         "client error is not retried",
+			codes:        []int{http.StatusBadRequest, http.StatusOK},
+			policy:       RetryPolicy{MaxRetries: 3, InitialBackoff: time.Millisecond},
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+		{
+			name:  "non-retryable status code",
+			codes: []int{http.StatusNotImplemented, http.StatusOK},
+			policy: RetryPolicy{
+				MaxRetries:              3,
+				InitialBackoff:          time.Millisecond,
+				NonRetryableStatusCodes: []int{http.StatusNotImplemented},
+			},
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+		{
+			name:         "budget exceeded",
+			codes:        []int{http.StatusServiceUnavailable, http.StatusOK},
+			policy:       RetryPolicy{MaxRetries: 3, InitialBackoff: time.Second, Budget: 100 * time.Millisecond},
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			pgw := httptest.NewServer(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					n := attempts.Add(1)
+					body, err := io.ReadAll(r.Body)
+					if err != nil || len(body) == 0 {
+						t.Errorf("attempt %d: got empty body, err: %v", n, err)
+					}
+					w.WriteHeader(tc.codes[n-1])
+				}),
+			)
+			defer pgw.Close()
+
+			err := New(pgw.URL, "testjob").
+				Collector(metric).
+				Retry(tc.policy).
+				Push()
+			if tc.wantErr && err == nil {
+				t.Error("expected error, got nil")
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+			if got := attempts.Load(); got != tc.wantAttempts {
+				t.Errorf("got %d attempts, want %d", got, tc.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestDeleteRetry(t *testing.T) {
+	var attempts atomic.Int32
+	pgw := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if attempts.Add(1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusAccepted)
+		}),
+	)
+	defer pgw.Close()
+
+	if err := New(pgw.URL, "testjob").
+		Retry(RetryPolicy{MaxRetries: 1, InitialBackoff: time.Millisecond}).
+		Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("got %d attempts, want 2", got)
+	}
+}
+
+func TestRetryBudgetResponseBody(t *testing.T) {
+	pgw := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			// Flush the header before writing the body, so that the
+			// body is read after the response is returned.
+			w.(http.Flusher).Flush()
+			time.Sleep(10 * time.Millisecond)
+			io.WriteString(w, "bad metrics")
+		}),
+	)
+	defer pgw.Close()
+
+	err := New(pgw.URL, "testjob").
+		Collector(prometheus.NewCounter(prometheus.CounterOpts{Name: "testname", Help: "testhelp"})).
+		Retry(RetryPolicy{MaxRetries: 1, InitialBackoff: time.Millisecond, Budget: time.Minute}).
+		Push()
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.HasSuffix(err.Error(), ": bad metrics") {
+		t.Errorf("got error %q, want response body in error", err)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := New("localhost", "testjob").Retry(RetryPolicy{
+		InitialBackoff:    time.Second,
+		MaxBackoff:        5 * time.Second,
+		RespectRetryAfter: true,
+	}).retry
+
+	for retry, want := range map[int]time.Duration{
+		1: time.Second,
+		2: 2 * time.Second,
+		3: 4 * time.Second,
+		4: 5 * time.Second,
+		9: 5 * time.Second,
+	} {
+		if got := p.backoff(retry, nil); got != want {
+			t.Errorf("retry %d: got backoff %s, want %s", retry, got, want)
+		}
+	}
+
+	resp := &http.Response{
+		StatusCode: http.StatusServiceUnavailable,
+		Header:     http.Header{"Retry-After": []string{"3"}},
+	}
+	if got, want := p.backoff(1, resp), 3*time.Second; got != want {
+		t.Errorf("got backoff %s with Retry-After, want %s", got, want)
+	}
+	resp.Header.Set("Retry-After", "120")
+	if got, want := p.backoff(1, resp), 5*time.Second; got != want {
+		t.Errorf("got backoff %s with large Retry-After, want %s", got, want)
+	}
+	resp.Header.Set("Retry-After", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+	if got, want := p.backoff(2, resp), 2*time.Second; got != want {
+		t.Errorf("got backoff %s with past Retry-After date, want %s", got, want)
+	}
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := p.backoff(1, nil); got < 500*time.Millisecond || got > 1500*time.Millisecond {
+			t.Fatalf("got backoff %s with jitter, want between 500ms and 1.5s", got)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+const defaultGracePeriod = 5 * time.Second
+
+// ShutdownAction is the final action a ShutdownHook performs.
+type ShutdownAction int
+
+const (
+	// ShutdownPush performs a final Pusher.Push.
+	ShutdownPush ShutdownAction = iota
+	// ShutdownAdd performs a final Pusher.Add.
+	ShutdownAdd
+	// ShutdownDelete performs a final Pusher.Delete, e.g. to clean up the
+	// metrics of a worker that is going away for good.
+	ShutdownDelete
+)
+
+// ShutdownOpts defines the behavior of a ShutdownHook created with OnShutdown.
+type ShutdownOpts struct {
+	// Action is the final action to perform. Defaults to ShutdownPush.
+	Action ShutdownAction
+	// Signals to perform the final action upon. Defaults to os.Interrupt
+	// and syscall.SIGTERM.
+	Signals []os.Signal
+	// GracePeriod bounds the time the final action may take. Defaults to
+	// 5s.
+	GracePeriod time.Duration
+}
+
+// ShutdownHook performs a final push (or delete) with a Pusher when the process
+// is about to terminate. Use OnShutdown to create one.
+type ShutdownHook struct {
+	done chan struct{}
+	err  error
+}
+
+// OnShutdown creates a ShutdownHook that performs the final action configured
+// in opts with the provided Pusher as soon as ctx is done or the process
+// receives one of the configured signals, whichever happens first. This
+// ensures that the last datapoints of short-lived processes like cron jobs or
+// serverless workers reach the Pushgateway.
+//
+// The configured signals are intercepted, i.e. they no longer terminate the
+// process. Thus, the process has to wait for the hook (with Wait or Done) and
+// exit afterwards, e.g.:
+//
+//	hook := push.OnShutdown(ctx, pusher, push.ShutdownOpts{})
+//	// Do the work...
+//	cancel() // Or receive SIGTERM.
+//	if err := hook.Wait(); err != nil {
+//	    log.Println("Final push failed:", err)
+//	}
+//
+// Once the first signal has been received, the signals are no longer
+// intercepted, so that a second signal terminates the process immediately.
+//
+// The final action is performed with a fresh context bounded by the grace
+// period, as ctx is already done at that point.
+func OnShutdown(ctx context.Context, p *Pusher, opts ShutdownOpts) *ShutdownHook {
+	if len(opts.Signals) == 0 {
+		opts.Signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	if opts.GracePeriod <= 0 {
+		opts.GracePeriod = defaultGracePeriod
+	}
+
+	h := &ShutdownHook{done: make(chan struct{})}
+	sigCtx, stop := signal.NotifyContext(ctx, opts.Signals...)
+	go func() {
+		defer close(h.done)
+		<-sigCtx.Done()
+		stop()
+
+		finalCtx, cancel := context.WithTimeout(context.Background(), opts.GracePeriod)
+		defer cancel()
+		switch opts.Action {
+		case ShutdownAdd:
+			h.err = p.AddContext(finalCtx)
+		case ShutdownDelete:
+			h.err = p.DeleteContext(finalCtx)
+		default:
+			h.err = p.PushContext(finalCtx)
+		}
+	}()
+	return h
+}
+
+// Done returns a channel that is closed once the final action has been
+// performed.
+func (h *ShutdownHook) Done() <-chan struct{} {
+	return h.done
+}
+
+// Wait blocks until the final action has been performed and returns its error.
+func (h *ShutdownHook) Wait() error {
+	<-h.done
+	return h.err
+}
@@ -0,0 +1,78 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOnShutdown(t *testing.T) {
+	methods := make(chan string, 1)
+	pgw := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			methods <- r.Method
+			if r.Method == http.MethodDelete {
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+	defer pgw.Close()
+
+	for _, tc := range []struct {
+		action     ShutdownAction
+		wantMethod string
+	}{
+		{ShutdownPush, http.MethodPut},
+		{ShutdownAdd, http.MethodPost},
+		{ShutdownDelete, http.MethodDelete},
+	} {
+		ctx, cancel := context.WithCancel(context.Background())
+		hook := OnShutdown(ctx, New(pgw.URL, "testjob"), ShutdownOpts{Action: tc.action})
+		select {
+		case <-hook.Done():
+			t.Fatal("hook done before shutdown")
+		case <-time.After(10 * time.Millisecond):
+		}
+		cancel()
+		if err := hook.Wait(); err != nil {
+			t.Fatal(err)
+		}
+		if got := <-methods; got != tc.wantMethod {
+			t.Errorf("got method %q, want %q", got, tc.wantMethod)
+		}
+	}
+}
+
+func TestOnShutdownGracePeriod(t *testing.T) {
+	pgw := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}),
+	)
+	defer pgw.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hook := OnShutdown(ctx, New(pgw.URL, "testjob"), ShutdownOpts{GracePeriod: 50 * time.Millisecond})
+	cancel()
+	if err := hook.Wait(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,260 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recording
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Expr is an expression evaluating to a vector of series, i.e. a set of
+// samples with distinct label sets. Exprs are built with the functions of this
+// package, e.g.
+//
+//	Div(
+//		Sum(Rate(Select("http_requests_total", prometheus.Labels{"code": "500"})), "handler"),
+//		Sum(Rate(Select("http_requests_total", nil)), "handler"),
+//	)
+type Expr interface {
+	eval(ctx *evalContext) vector
+}
+
+// evalContext is the input of an evaluation.
+type evalContext struct {
+	// series are the gathered samples by series name.
+	series map[string]vector
+	now    time.Time
+}
+
+type sample struct {
+	labels map[string]string
+	value  float64
+}
+
+type vector []sample
+
+// signature returns a string uniquely identifying the label set.
+func signature(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var sb strings.Builder
+	for _, name := range names {
+		sb.WriteString(name)
+		sb.WriteByte('\xff')
+		sb.WriteString(labels[name])
+		sb.WriteByte('\xff')
+	}
+	return sb.String()
+}
+
+type selectExpr struct {
+	name  string
+	match prometheus.Labels
+}
+
+// Select selects the gathered series with the provided name whose labels have
+// the values of match. Counters, gauges, and untyped metrics are selected by
+// their name, the counts and sums of histograms and summaries by their name
+// with the "_count" and "_sum" suffix, respectively.
+func Select(name string, match prometheus.Labels) Expr {
+	return &selectExpr{name: name, match: match}
+}
+
+func (e *selectExpr) eval(ctx *evalContext) vector {
+	var v vector
+outer:
+	for _, s := range ctx.series[e.name] {
+		for name, value := range e.match {
+			if s.labels[name] != value {
+				continue outer
+			}
+		}
+		v = append(v, s)
+	}
+	return v
+}
+
+type sumExpr struct {
+	expr Expr
+	by   []string
+}
+
+// Sum sums the series of expr grouped by the provided labels. Without labels,
+// all series are summed into one.
+func Sum(expr Expr, by ...string) Expr {
+	return &sumExpr{expr: expr, by: by}
+}
+
+func (e *sumExpr) eval(ctx *evalContext) vector {
+	in := e.expr.eval(ctx)
+	var (
+		out    vector
+		groups = map[string]int{}
+	)
+	for _, s := range in {
+		labels := make(map[string]string, len(e.by))
+		for _, name := range e.by {
+			if value, ok := s.labels[name]; ok {
+				labels[name] = value
+			}
+		}
+		sig := signature(labels)
+		if i, ok := groups[sig]; ok {
+			out[i].value += s.value
+			continue
+		}
+		groups[sig] = len(out)
+		out = append(out, sample{labels: labels, value: s.value})
+	}
+	return out
+}
+
+type rateExpr struct {
+	expr Expr
+
+	mtx  sync.Mutex
+	last map[string]rateSample
+}
+
+type rateSample struct {
+	value float64
+	time  time.Time
+}
+
+// Rate returns the per-second rate of increase of the counters of expr between
+// the previous and the current evaluation. A decrease of a counter is taken as
+// a reset. Series are omitted on the first evaluation that sees them.
+//
+// A Rate Expr keeps the values of the previous evaluation, so it must not be
+// shared by Gatherers.
+func Rate(expr Expr) Expr {
+	return &rateExpr{expr: expr, last: map[string]rateSample{}}
+}
+
+func (e *rateExpr) eval(ctx *evalContext) vector {
+	in := e.expr.eval(ctx)
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	var (
+		out  vector
+		last = make(map[string]rateSample, len(in))
+	)
+	for _, s := range in {
+		sig := signature(s.labels)
+		prev, ok := e.last[sig]
+		last[sig] = rateSample{value: s.value, time: ctx.now}
+		elapsed := ctx.now.Sub(prev.time).Seconds()
+		if !ok || elapsed <= 0 {
+			continue
+		}
+		increase := s.value - prev.value
+		if increase < 0 {
+			increase = s.value
+		}
+		out = append(out, sample{labels: s.labels, value: increase / elapsed})
+	}
+	e.last = last
+	return out
+}
+
+type binaryExpr struct {
+	lhs Expr
+	rhs Expr
+	fn  func(l, r float64) float64
+}
+
+// Add adds the series of rhs to the series of lhs with the same labels.
+// Series without a match are omitted.
+func Add(lhs, rhs Expr) Expr {
+	return &binaryExpr{lhs: lhs, rhs: rhs, fn: func(l, r float64) float64 { return l + r }}
+}
+
+// Sub subtracts the series of rhs from the series of lhs with the same labels.
+// Series without a match are omitted.
+func Sub(lhs, rhs Expr) Expr {
+	return &binaryExpr{lhs: lhs, rhs: rhs, fn: func(l, r float64) float64 { return l - r }}
+}
+
+// Mul multiplies the series of lhs by the series of rhs with the same labels.
+// Series without a match are omitted.
+func Mul(lhs, rhs Expr) Expr {
+	return &binaryExpr{lhs: lhs, rhs: rhs, fn: func(l, r float64) float64 { return l * r }}
+}
+
+// Div divides the series of lhs by the series of rhs with the same labels.
+// Series without a match are omitted. As in PromQL, a division by zero results
+// in ±Inf or NaN.
+func Div(lhs, rhs Expr) Expr {
+	return &binaryExpr{lhs: lhs, rhs: rhs, fn: func(l, r float64) float64 { return l / r }}
+}
+
+func (e *binaryExpr) eval(ctx *evalContext) vector {
+	lhs := e.lhs.eval(ctx)
+	rhs := e.rhs.eval(ctx)
+	// A scalar applies to all series of the other side.
+	switch {
+	case isScalar(e.lhs) && isScalar(e.rhs):
+		return vector{{labels: map[string]string{}, value: e.fn(lhs[0].value, rhs[0].value)}}
+	case isScalar(e.lhs):
+		out := make(vector, 0, len(rhs))
+		for _, s := range rhs {
+			out = append(out, sample{labels: s.labels, value: e.fn(lhs[0].value, s.value)})
+		}
+		return out
+	case isScalar(e.rhs):
+		out := make(vector, 0, len(lhs))
+		for _, s := range lhs {
+			out = append(out, sample{labels: s.labels, value: e.fn(s.value, rhs[0].value)})
+		}
+		return out
+	}
+
+	// The series of a vector have distinct label sets, so the matching is
+	// always one-to-one.
+	bySig := make(map[string]float64, len(rhs))
+	for _, s := range rhs {
+		bySig[signature(s.labels)] = s.value
+	}
+	var out vector
+	for _, s := range lhs {
+		if r, ok := bySig[signature(s.labels)]; ok {
+			out = append(out, sample{labels: s.labels, value: e.fn(s.value, r)})
+		}
+	}
+	return out
+}
+
+type scalarExpr float64
+
+// Scalar returns a constant. In an arithmetic Expr, it applies to all series
+// of the other operand, e.g. Mul(Select("ratio", nil), Scalar(100)).
+func Scalar(v float64) Expr {
+	return scalarExpr(v)
+}
+
+func (e scalarExpr) eval(*evalContext) vector {
+	return vector{{labels: map[string]string{}, value: float64(e)}}
+}
+
+func isScalar(e Expr) bool {
+	_, ok := e.(scalarExpr)
+	return ok
+}
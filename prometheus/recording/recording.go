@@ -0,0 +1,165 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package recording provides client-side recording rules: derived metrics
+// defined as expressions over the gathered series, like ratios, sums across
+// the series of a vector, and rates of counters between gathers. The rules are
+// evaluated at gather time and their results exposed as new gauge families.
+// For simple cases, this avoids the need for recording rules on the
+// Prometheus server.
+//
+// The expressions are a small subset of PromQL, built with the functions of
+// this package rather than parsed from a string.
+package recording
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/model"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/internal"
+)
+
+// Rule defines a derived metric.
+type Rule struct {
+	// Name is the name of the metric family of the results of Expr. It
+	// must not collide with the name of a gathered metric family.
+	Name string
+	// Help is the help string of the metric family.
+	Help string
+	// Expr is the expression the metric family is derived from.
+	Expr Expr
+}
+
+// Gatherer is a prometheus.Gatherer evaluating recording rules over the
+// metric families of another Gatherer.
+type Gatherer struct {
+	g     prometheus.Gatherer
+	rules []Rule
+	now   func() time.Time
+}
+
+// NewGatherer returns a Gatherer that gathers from g and adds the metric
+// families derived by the rules. It returns an error if a rule has an invalid
+// name or no Expr, or if the names of two rules collide.
+func NewGatherer(g prometheus.Gatherer, rules ...Rule) (*Gatherer, error) {
+	names := make(map[string]struct{}, len(rules))
+	for _, r := range rules {
+		if !model.IsValidMetricName(model.LabelValue(r.Name)) {
+			return nil, fmt.Errorf("invalid metric name %q", r.Name)
+		}
+		if r.Expr == nil {
+			return nil, fmt.Errorf("rule %q has no expression", r.Name)
+		}
+		if _, ok := names[r.Name]; ok {
+			return nil, fmt.Errorf("duplicate rule %q", r.Name)
+		}
+		names[r.Name] = struct{}{}
+	}
+	return &Gatherer{g: g, rules: rules, now: time.Now}, nil
+}
+
+// Gather implements prometheus.Gatherer. Rules colliding with a gathered
+// metric family are skipped and reported in the returned error, together with
+// the errors of the underlying Gatherer.
+func (g *Gatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := g.g.Gather()
+	var errs prometheus.MultiError
+	if err != nil {
+		var multiErr prometheus.MultiError
+		if errors.As(err, &multiErr) {
+			errs = append(errs, multiErr...)
+		} else {
+			errs = append(errs, err)
+		}
+	}
+
+	ctx := &evalContext{series: seriesByName(mfs), now: g.now()}
+	byName := make(map[string]*dto.MetricFamily, len(mfs)+len(g.rules))
+	for _, mf := range mfs {
+		byName[mf.GetName()] = mf
+	}
+	for _, r := range g.rules {
+		if _, ok := byName[r.Name]; ok {
+			errs = append(errs, fmt.Errorf("rule %q collides with a gathered metric family", r.Name))
+			continue
+		}
+		byName[r.Name] = newFamily(r, r.Expr.eval(ctx))
+	}
+	return internal.NormalizeMetricFamilies(byName), errs.MaybeUnwrap()
+}
+
+// seriesByName returns the samples of the metric families by series name.
+func seriesByName(mfs []*dto.MetricFamily) map[string]vector {
+	series := map[string]vector{}
+	for _, mf := range mfs {
+		name := mf.GetName()
+		for _, m := range mf.GetMetric() {
+			labels := make(map[string]string, len(m.GetLabel()))
+			for _, lp := range m.GetLabel() {
+				labels[lp.GetName()] = lp.GetValue()
+			}
+			add := func(name string, value float64) {
+				series[name] = append(series[name], sample{labels: labels, value: value})
+			}
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				add(name, m.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				add(name, m.GetGauge().GetValue())
+			case dto.MetricType_UNTYPED:
+				add(name, m.GetUntyped().GetValue())
+			case dto.MetricType_SUMMARY:
+				add(name+"_count", float64(m.GetSummary().GetSampleCount()))
+				add(name+"_sum", m.GetSummary().GetSampleSum())
+			case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+				h := m.GetHistogram()
+				count := float64(h.GetSampleCount())
+				if h.SampleCountFloat != nil {
+					count = h.GetSampleCountFloat()
+				}
+				add(name+"_count", count)
+				add(name+"_sum", h.GetSampleSum())
+			}
+		}
+	}
+	return series
+}
+
+// newFamily returns the gauge family of the results of a rule.
+func newFamily(r Rule, v vector) *dto.MetricFamily {
+	mf := &dto.MetricFamily{
+		Name:   proto.String(r.Name),
+		Help:   proto.String(r.Help),
+		Type:   dto.MetricType_GAUGE.Enum(),
+		Metric: make([]*dto.Metric, 0, len(v)),
+	}
+	for _, s := range v {
+		m := &dto.Metric{
+			Label: make([]*dto.LabelPair, 0, len(s.labels)),
+			Gauge: &dto.Gauge{Value: proto.Float64(s.value)},
+		}
+		for name, value := range s.labels {
+			m.Label = append(m.Label, &dto.LabelPair{Name: proto.String(name), Value: proto.String(value)})
+		}
+		sort.Sort(internal.LabelPairSorter(m.Label))
+		mf.Metric = append(mf.Metric, m)
+	}
+	return mf
+}
@@ -0,0 +1,149 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recording
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestGatherer(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "requests_total",
+		Help: "Requests handled.",
+	}, []string{"code", "handler"})
+	latency := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "latency_seconds",
+		Help:    "Latency.",
+		Buckets: []float64{1},
+	})
+	reg.MustRegister(requests, latency)
+
+	g, err := NewGatherer(reg,
+		Rule{
+			Name: "handler_error_ratio",
+			Help: "Ratio of failed requests.",
+			Expr: Div(
+				Sum(Select("requests_total", prometheus.Labels{"code": "500"}), "handler"),
+				Sum(Select("requests_total", nil), "handler"),
+			),
+		},
+		Rule{
+			Name: "requests_per_second",
+			Help: "Request rate.",
+			Expr: Sum(Rate(Select("requests_total", nil))),
+		},
+		Rule{
+			Name: "latency_average_milliseconds",
+			Help: "Average latency.",
+			Expr: Mul(Div(Select("latency_seconds_sum", nil), Select("latency_seconds_count", nil)), Scalar(1000)),
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(100, 0)
+	g.now = func() time.Time { return now }
+
+	requests.WithLabelValues("200", "a").Add(3)
+	requests.WithLabelValues("500", "a").Add(1)
+	requests.WithLabelValues("200", "b").Add(2)
+	latency.Observe(0.5)
+	latency.Observe(1.5)
+
+	const families = `
+# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="1"} 1
+latency_seconds_bucket{le="+Inf"} 2
+latency_seconds_sum 2
+latency_seconds_count 2
+# HELP requests_total Requests handled.
+# TYPE requests_total counter
+requests_total{code="200",handler="a"} 3
+requests_total{code="200",handler="b"} 2
+requests_total{code="500",handler="a"} 1
+`
+	// The first gather has no rate yet.
+	expected := `# HELP handler_error_ratio Ratio of failed requests.
+# TYPE handler_error_ratio gauge
+handler_error_ratio{handler="a"} 0.25
+# HELP latency_average_milliseconds Average latency.
+# TYPE latency_average_milliseconds gauge
+latency_average_milliseconds 1000
+` + families
+	if err := testutil.GatherAndCompare(g, strings.NewReader(expected)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reset counters count as if they started from zero.
+	now = now.Add(10 * time.Second)
+	requests.WithLabelValues("200", "a").Add(17)
+	requests.DeleteLabelValues("200", "b")
+	requests.WithLabelValues("200", "b").Add(1)
+	expected = `# HELP handler_error_ratio Ratio of failed requests.
+# TYPE handler_error_ratio gauge
+handler_error_ratio{handler="a"} 0.047619047619047616
+# HELP latency_average_milliseconds Average latency.
+# TYPE latency_average_milliseconds gauge
+latency_average_milliseconds 1000
+` + strings.Replace(strings.Replace(families,
+		`requests_total{code="200",handler="a"} 3`, `requests_total{code="200",handler="a"} 20`, 1),
+		`requests_total{code="200",handler="b"} 2`, `requests_total{code="200",handler="b"} 1`, 1) + `# HELP requests_per_second Request rate.
+# TYPE requests_per_second gauge
+requests_per_second 1.8
+`
+	if err := testutil.GatherAndCompare(g, strings.NewReader(expected)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGathererErrors(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	c := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "c_total", Help: "A counter."}, []string{"l"})
+	c.WithLabelValues("a").Inc()
+	c.WithLabelValues("b").Inc()
+	reg.MustRegister(c)
+
+	for _, rules := range [][]Rule{
+		{{Name: "", Expr: Scalar(1)}},
+		{{Name: "no_expr"}},
+		{{Name: "dup", Expr: Scalar(1)}, {Name: "dup", Expr: Scalar(2)}},
+	} {
+		if _, err := NewGatherer(reg, rules...); err == nil {
+			t.Errorf("expected error for rules %v", rules)
+		}
+	}
+
+	g, err := NewGatherer(reg,
+		Rule{Name: "c_total", Expr: Scalar(1)},
+		Rule{Name: "no_match", Expr: Div(Sum(Select("c_total", nil), "l"), Sum(Select("c_total", nil)))},
+		Rule{Name: "ok", Expr: Scalar(1)},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mfs, err := g.Gather()
+	if err == nil || !strings.Contains(err.Error(), "collides") {
+		t.Errorf("got error %v, want collision error", err)
+	}
+	if len(mfs) != 2 || mfs[0].GetName() != "c_total" || mfs[1].GetName() != "ok" {
+		t.Errorf("unexpected metric families %v", mfs)
+	}
+}
@@ -17,13 +17,14 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"maps"
 	"runtime"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"unicode/utf8"
 
 	"github.com/prometheus/client_golang/prometheus/internal"
@@ -69,6 +70,7 @@ func NewRegistry() *Registry {
 		collectorsByID:  map[uint64]Collector{},
 		descIDs:         map[uint64]struct{}{},
 		dimHashesByName: map[string]uint64{},
+		namesByID:       map[uint64][]string{},
 	}
 }
 
@@ -210,6 +212,24 @@ func (err AlreadyRegisteredError) Error() string {
 	return "duplicate metrics collector registration attempted"
 }
 
+// MetricError is the error a Gatherer reports for a collected metric that
+// could not be written or is inconsistent. It allows to attribute the error to
+// the metric, e.g. for structured logging.
+type MetricError struct {
+	// FQName is the fully-qualified name of the metric. It is empty if the
+	// metric has an invalid Desc.
+	FQName string
+	Err    error
+}
+
+func (err *MetricError) Error() string {
+	return err.Err.Error()
+}
+
+func (err *MetricError) Unwrap() error {
+	return err.Err
+}
+
 // MultiError is a slice of errors implementing the error interface. It is used
 // by a Gatherer to report multiple errors during MetricFamily gathering.
 type MultiError []error
@@ -262,8 +282,83 @@ type Registry struct {
 	collectorsByID        map[uint64]Collector // ID is a hash of the descIDs.
 	descIDs               map[uint64]struct{}
 	dimHashesByName       map[string]uint64
+	namesByID             map[uint64][]string // Sorted metric names by collector ID.
 	uncheckedCollectors   []Collector
 	pedanticChecksEnabled bool
+	// frozen is the gather plan of a frozen registry, see Freeze.
+	frozen atomic.Pointer[gatherPlan]
+}
+
+// ErrRegistryFrozen is returned by Register of a Registry that has been frozen
+// with Freeze.
+var ErrRegistryFrozen = errors.New("registry is frozen")
+
+// FreezeOpts defines the behavior of a Registry frozen with Freeze.
+type FreezeOpts struct {
+	// If true, Gather skips the checks of the gathered metrics for
+	// consistency: for duplicate series, for metric families colliding
+	// with the suffixes of summaries and histograms, and, in a pedantic
+	// registry, for metrics inconsistent with their (registered)
+	// descriptors. These checks are a large part of the CPU time of
+	// gathering registries with many series. Only skip them if the
+	// metrics are known to be consistent, e.g. because the same
+	// collectors are gathered without errors in tests. Each metric is
+	// still checked on its own, e.g. for invalid label names or label
+	// values that are not valid UTF-8.
+	SkipConsistencyChecks bool
+}
+
+// gatherPlan is what Gather needs to know about the registered Collectors.
+type gatherPlan struct {
+	checked, unchecked    []Collector
+	registeredDescIDs     map[uint64]struct{} // Only used for pedantic checks.
+	numFamilies           int                 // The expected number of metric families.
+	skipConsistencyChecks bool
+}
+
+// plan returns the gather plan for the currently registered Collectors.
+func (r *Registry) plan(skipConsistencyChecks bool) *gatherPlan {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	return r.planLocked(skipConsistencyChecks)
+}
+
+// planLocked is the implementation of plan. r.mtx has to be held.
+func (r *Registry) planLocked(skipConsistencyChecks bool) *gatherPlan {
+	p := &gatherPlan{
+		checked:               make([]Collector, 0, len(r.collectorsByID)),
+		unchecked:             append([]Collector(nil), r.uncheckedCollectors...),
+		numFamilies:           len(r.dimHashesByName),
+		skipConsistencyChecks: skipConsistencyChecks,
+	}
+	for _, collector := range r.collectorsByID {
+		p.checked = append(p.checked, collector)
+	}
+	// In case pedantic checks are enabled, we have to copy the map before
+	// giving up the lock.
+	if r.pedanticChecksEnabled && !skipConsistencyChecks {
+		p.registeredDescIDs = make(map[uint64]struct{}, len(r.descIDs))
+		for id := range r.descIDs {
+			p.registeredDescIDs[id] = struct{}{}
+		}
+	}
+	return p
+}
+
+// Freeze freezes the Registry once the registration of all its Collectors is
+// complete, typically at the end of the startup of a program whose Registry
+// never changes afterwards. From then on, Register returns ErrRegistryFrozen
+// and Unregister returns false, while Gather uses a precomputed read-only view
+// of the registered Collectors instead of computing it under a lock for each
+// call. See FreezeOpts for skipping the consistency checks of gathered
+// metrics, which reduces the CPU time of Gather the most. Freezing an already
+// frozen Registry again only updates the FreezeOpts.
+func (r *Registry) Freeze(opts FreezeOpts) {
+	// Hold the write lock so that no Register or Unregister call still in
+	// progress changes the Registry after the plan is made.
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.frozen.Store(r.planLocked(opts.SkipConsistencyChecks))
 }
 
 // Register implements Registerer.
@@ -272,6 +367,7 @@ func (r *Registry) Register(c Collector) error {
 		descChan           = make(chan *Desc, capDescChan)
 		newDescIDs         = map[uint64]struct{}{}
 		newDimHashesByName = map[string]uint64{}
+		names              = map[string]struct{}{}
 		collectorID        uint64 // All desc IDs XOR'd together.
 		duplicateDescErr   error
 	)
@@ -286,6 +382,9 @@ func (r *Registry) Register(c Collector) error {
 		}
 		r.mtx.Unlock()
 	}()
+	if r.frozen.Load() != nil {
+		return ErrRegistryFrozen
+	}
 	// Conduct various tests...
 	for desc := range descChan {
 
@@ -293,6 +392,7 @@ func (r *Registry) Register(c Collector) error {
 		if desc.err != nil {
 			return fmt.Errorf("descriptor %s is invalid: %w", desc, desc.err)
 		}
+		names[desc.fqName] = struct{}{}
 
 		// Is the descID unique?
 		// (In other words: Is the fqName + constLabel combination unique?)
@@ -353,6 +453,7 @@ func (r *Registry) Register(c Collector) error {
 
 	// Only after all tests have passed, actually register.
 	r.collectorsByID[collectorID] = c
+	r.namesByID[collectorID] = slices.Sorted(maps.Keys(names))
 	for hash := range newDescIDs {
 		r.descIDs[hash] = struct{}{}
 	}
@@ -364,6 +465,9 @@ func (r *Registry) Register(c Collector) error {
 
 // Unregister implements Registerer.
 func (r *Registry) Unregister(c Collector) bool {
+	if r.frozen.Load() != nil {
+		return false
+	}
 	var (
 		descChan    = make(chan *Desc, capDescChan)
 		descIDs     = map[uint64]struct{}{}
@@ -390,7 +494,11 @@ func (r *Registry) Unregister(c Collector) bool {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
+	if r.frozen.Load() != nil {
+		return false
+	}
 	delete(r.collectorsByID, collectorID)
+	delete(r.namesByID, collectorID)
 	for id := range descIDs {
 		delete(r.descIDs, id)
 	}
@@ -410,42 +518,62 @@ func (r *Registry) MustRegister(cs ...Collector) {
 
 // Gather implements Gatherer.
 func (r *Registry) Gather() ([]*dto.MetricFamily, error) {
-	r.mtx.RLock()
+	return r.gather(nil)
+}
 
-	if len(r.collectorsByID) == 0 && len(r.uncheckedCollectors) == 0 {
+// gather implements Gather. If pool is not nil, the returned MetricFamily and
+// Metric protobufs are taken from it.
+func (r *Registry) gather(pool *dtoPool) ([]*dto.MetricFamily, error) {
+	p := r.frozen.Load()
+	if p == nil {
+		p = r.plan(false)
+	}
+	if len(p.checked) == 0 && len(p.unchecked) == 0 {
 		// Fast path.
-		r.mtx.RUnlock()
 		return nil, nil
 	}
 
+	var metricHashes map[uint64]struct{}
+	if !p.skipConsistencyChecks {
+		metricHashes = map[uint64]struct{}{}
+	}
+	metricFamiliesByName := make(map[string]*dto.MetricFamily, p.numFamilies)
+	errs := collect(p.checked, p.unchecked, func(metric Metric, checked bool) error {
+		return processMetric(metric, metricFamiliesByName, metricHashes, p.descIDs(checked), pool)
+	})
+	return internal.NormalizeMetricFamilies(metricFamiliesByName), errs.MaybeUnwrap()
+}
+
+// descIDs returns the IDs of the registered descriptors for metrics collected
+// from checked Collectors, and nil otherwise, as expected by processMetric.
+func (p *gatherPlan) descIDs(checked bool) map[uint64]struct{} {
+	if checked {
+		return p.registeredDescIDs
+	}
+	return nil
+}
+
+// collect collects the provided checked and unchecked Collectors concurrently
+// and calls process with each collected metric and whether it was collected
+// from a checked Collector. process is never called concurrently. collect
+// returns the errors returned by process.
+func collect(checked, unchecked []Collector, process func(metric Metric, checked bool) error) MultiError {
 	var (
-		checkedMetricChan   = make(chan Metric, capMetricChan)
-		uncheckedMetricChan = make(chan Metric, capMetricChan)
-		metricHashes        = map[uint64]struct{}{}
+		checkedMetricChan   = newMetricChan(checked)
+		uncheckedMetricChan = newMetricChan(unchecked)
 		wg                  sync.WaitGroup
-		errs                MultiError          // The collected errors to return in the end.
-		registeredDescIDs   map[uint64]struct{} // Only used for pedantic checks
+		errs                MultiError // The collected errors to return in the end.
 	)
 
-	goroutineBudget := len(r.collectorsByID) + len(r.uncheckedCollectors)
-	metricFamiliesByName := make(map[string]*dto.MetricFamily, len(r.dimHashesByName))
-	checkedCollectors := make(chan Collector, len(r.collectorsByID))
-	uncheckedCollectors := make(chan Collector, len(r.uncheckedCollectors))
-	for _, collector := range r.collectorsByID {
+	goroutineBudget := len(checked) + len(unchecked)
+	checkedCollectors := make(chan Collector, len(checked))
+	uncheckedCollectors := make(chan Collector, len(unchecked))
+	for _, collector := range checked {
 		checkedCollectors <- collector
 	}
-	for _, collector := range r.uncheckedCollectors {
+	for _, collector := range unchecked {
 		uncheckedCollectors <- collector
 	}
-	// In case pedantic checks are enabled, we have to copy the map before
-	// giving up the RLock.
-	if r.pedanticChecksEnabled {
-		registeredDescIDs = make(map[uint64]struct{}, len(r.descIDs))
-		for id := range r.descIDs {
-			registeredDescIDs[id] = struct{}{}
-		}
-	}
-	r.mtx.RUnlock()
 
 	wg.Add(goroutineBudget)
 
@@ -499,21 +627,13 @@ func (r *Registry) Gather() ([]*dto.MetricFamily, error) {
 				cmc = nil
 				break
 			}
-			errs.Append(processMetric(
-				metric, metricFamiliesByName,
-				metricHashes,
-				registeredDescIDs,
-			))
+			errs.Append(process(metric, true))
 		case metric, ok := <-umc:
 			if !ok {
 				umc = nil
 				break
 			}
-			errs.Append(processMetric(
-				metric, metricFamiliesByName,
-				metricHashes,
-				nil,
-			))
+			errs.Append(process(metric, false))
 		default:
 			if goroutineBudget <= 0 || len(checkedCollectors)+len(uncheckedCollectors) == 0 {
 				// All collectors are already being worked on or
@@ -526,21 +646,13 @@ func (r *Registry) Gather() ([]*dto.MetricFamily, error) {
 						cmc = nil
 						break
 					}
-					errs.Append(processMetric(
-						metric, metricFamiliesByName,
-						metricHashes,
-						registeredDescIDs,
-					))
+					errs.Append(process(metric, true))
 				case metric, ok := <-umc:
 					if !ok {
 						umc = nil
 						break
 					}
-					errs.Append(processMetric(
-						metric, metricFamiliesByName,
-						metricHashes,
-						nil,
-					))
+					errs.Append(process(metric, false))
 				}
 				break
 			}
@@ -556,7 +668,17 @@ func (r *Registry) Gather() ([]*dto.MetricFamily, error) {
 			break
 		}
 	}
-	return internal.NormalizeMetricFamilies(metricFamiliesByName), errs.MaybeUnwrap()
+	return errs
+}
+
+// newMetricChan returns a channel to collect the provided Collectors into,
+// which is only buffered if there are any, as collect creates channels for
+// each call.
+func newMetricChan(collectors []Collector) chan Metric {
+	if len(collectors) == 0 {
+		return make(chan Metric)
+	}
+	return make(chan Metric, capMetricChan)
 }
 
 // Describe implements Collector.
@@ -590,46 +712,37 @@ func (r *Registry) Collect(ch chan<- Metric) {
 //
 // This is intended for use with the textfile collector of the node exporter.
 // Note that the node exporter expects the filename to be suffixed with ".prom".
+// See TextfileWriter for writing the file periodically.
 func WriteToTextfile(filename string, g Gatherer) error {
-	tmp, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename))
-	if err != nil {
-		return err
-	}
-	defer os.Remove(tmp.Name())
-
-	mfs, err := g.Gather()
-	if err != nil {
-		return err
-	}
-	for _, mf := range mfs {
-		if _, err := expfmt.MetricFamilyToText(tmp, mf); err != nil {
-			return err
-		}
-	}
-	if err := tmp.Close(); err != nil {
-		return err
-	}
-
-	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
-		return err
-	}
-	return os.Rename(tmp.Name(), filename)
+	return writeTextfile(filename, g, expfmt.NewFormat(expfmt.TypeTextPlain))
 }
 
 // processMetric is an internal helper method only used by the Gather method.
+// A returned error is a *MetricError. If pool is not nil, new MetricFamily and
+// Metric protobufs are taken from it. If metricHashes is nil, the consistency
+// of the metric with the other gathered metrics is not checked, i.e. neither
+// whether it was collected before nor whether its name collides with the
+// suffixes of a summary or histogram, while the metric itself is still
+// checked for valid labels.
 func processMetric(
 	metric Metric,
 	metricFamiliesByName map[string]*dto.MetricFamily,
 	metricHashes map[uint64]struct{},
 	registeredDescIDs map[uint64]struct{},
-) error {
+	pool *dtoPool,
+) (err error) {
 	desc := metric.Desc()
+	defer func() {
+		if err != nil {
+			err = &MetricError{FQName: desc.fqName, Err: err}
+		}
+	}()
 	// Wrapped metrics collected by an unchecked Collector can have an
 	// invalid Desc.
 	if desc.err != nil {
 		return desc.err
 	}
-	dtoMetric := &dto.Metric{}
+	dtoMetric := pool.getMetric()
 	if err := metric.Write(dtoMetric); err != nil {
 		return fmt.Errorf("error collecting metric %v: %w", desc, err)
 	}
@@ -682,7 +795,7 @@ func processMetric(
 			panic("encountered MetricFamily with invalid type")
 		}
 	} else { // New name.
-		metricFamily = &dto.MetricFamily{}
+		metricFamily = pool.getFamily()
 		metricFamily.Name = proto.String(desc.fqName)
 		metricFamily.Help = proto.String(desc.help)
 		// TODO(beorn7): Simplify switch once Desc has type.
@@ -700,8 +813,10 @@ func processMetric(
 		default:
 			return fmt.Errorf("empty metric collected: %s", dtoMetric)
 		}
-		if err := checkSuffixCollisions(metricFamily, metricFamiliesByName); err != nil {
-			return err
+		if metricHashes != nil {
+			if err := checkSuffixCollisions(metricFamily, metricFamiliesByName); err != nil {
+				return err
+			}
 		}
 		metricFamiliesByName[desc.fqName] = metricFamily
 	}
@@ -865,7 +980,8 @@ func checkSuffixCollisions(mf *dto.MetricFamily, mfs map[string]*dto.MetricFamil
 // checkMetricConsistency checks if the provided Metric is consistent with the
 // provided MetricFamily. It also hashes the Metric labels and the MetricFamily
 // name. If the resulting hash is already in the provided metricHashes, an error
-// is returned. If not, it is added to metricHashes.
+// is returned. If not, it is added to metricHashes. If metricHashes is nil, the
+// hash is neither computed nor checked.
 func checkMetricConsistency(
 	metricFamily *dto.MetricFamily,
 	dtoMetric *dto.Metric,
@@ -915,9 +1031,6 @@ func checkMetricConsistency(
 	}
 
 	// Is the metric unique (i.e. no other metric with the same name and the same labels)?
-	h := xxhash.New()
-	h.WriteString(name)
-	h.Write(separatorByteSlice)
 	// Make sure label pairs are sorted. We depend on it for the consistency
 	// check.
 	if !sort.IsSorted(internal.LabelPairSorter(dtoMetric.Label)) {
@@ -927,17 +1040,10 @@ func checkMetricConsistency(
 		sort.Sort(internal.LabelPairSorter(copiedLabels))
 		dtoMetric.Label = copiedLabels
 	}
-	for _, lp := range dtoMetric.Label {
-		h.WriteString(lp.GetName())
-		h.Write(separatorByteSlice)
-		h.WriteString(lp.GetValue())
-		h.Write(separatorByteSlice)
-	}
-	if dtoMetric.TimestampMs != nil {
-		h.WriteString(strconv.FormatInt(*(dtoMetric.TimestampMs), 10))
-		h.Write(separatorByteSlice)
+	if metricHashes == nil {
+		return nil
 	}
-	hSum := h.Sum64()
+	hSum := hashMetric(name, dtoMetric.Label, dtoMetric.TimestampMs)
 	if _, exists := metricHashes[hSum]; exists {
 		return fmt.Errorf(
 			"collected metric %q { %s} was collected before with the same name and label values",
@@ -948,6 +1054,25 @@ func checkMetricConsistency(
 	return nil
 }
 
+// hashMetric returns the hash identifying a metric by its name, its label
+// pairs, which have to be sorted, and its timestamp, if any.
+func hashMetric(name string, labelPairs []*dto.LabelPair, timestampMs *int64) uint64 {
+	h := xxhash.New()
+	h.WriteString(name)
+	h.Write(separatorByteSlice)
+	for _, lp := range labelPairs {
+		h.WriteString(lp.GetName())
+		h.Write(separatorByteSlice)
+		h.WriteString(lp.GetValue())
+		h.Write(separatorByteSlice)
+	}
+	if timestampMs != nil {
+		h.WriteString(strconv.FormatInt(*timestampMs, 10))
+		h.Write(separatorByteSlice)
+	}
+	return h.Sum64()
+}
+
 func checkDescConsistency(
 	metricFamily *dto.MetricFamily,
 	dtoMetric *dto.Metric,
@@ -1339,3 +1339,129 @@ func TestCheckMetricConsistency(t *testing.T) {
 	}
 	reg.Unregister(invalidCollector)
 }
+
+func TestRegistryFreeze(t *testing.T) {
+	duplicateDesc := prometheus.NewDesc("duplicate_total", "", nil, nil)
+	duplicateCollector := &customCollector{
+		collectFunc: func(ch chan<- prometheus.Metric) {
+			ch <- prometheus.MustNewConstMetric(duplicateDesc, prometheus.CounterValue, 1)
+			ch <- prometheus.MustNewConstMetric(duplicateDesc, prometheus.CounterValue, 2)
+		},
+	}
+
+	for _, skip := range []bool{false, true} {
+		t.Run(fmt.Sprintf("SkipConsistencyChecks=%t", skip), func(t *testing.T) {
+			reg := prometheus.NewPedanticRegistry()
+			counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "requests_total", Help: "Requests."}, []string{"code"})
+			counter.WithLabelValues("200").Inc()
+			counter.WithLabelValues("500").Add(2)
+			reg.MustRegister(counter)
+			reg.MustRegister(uncheckedCollector{c: prometheus.NewGauge(prometheus.GaugeOpts{Name: "unchecked", Help: "Unchecked."})})
+
+			want, err := reg.Gather()
+			if err != nil {
+				t.Fatal(err)
+			}
+			reg.Freeze(prometheus.FreezeOpts{SkipConsistencyChecks: skip})
+
+			got, err := reg.Gather()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(want) {
+				t.Fatalf("got %d metric families, want %d", len(got), len(want))
+			}
+			for i := range want {
+				if !proto.Equal(got[i], want[i]) {
+					t.Errorf("got %v, want %v", got[i], want[i])
+				}
+			}
+
+			if err := reg.Register(duplicateCollector); !errors.Is(err, prometheus.ErrRegistryFrozen) {
+				t.Errorf("got error %v registering with frozen registry, want %v", err, prometheus.ErrRegistryFrozen)
+			}
+			if reg.Unregister(counter) {
+				t.Error("unregistering from frozen registry succeeded")
+			}
+			if _, err := reg.Gather(); err != nil {
+				t.Errorf("got error %v after failed unregistering, want nil", err)
+			}
+		})
+	}
+
+	// Metrics of collectors registered before freezing are checked for
+	// consistency, unless SkipConsistencyChecks is set.
+	for _, skip := range []bool{false, true} {
+		reg := prometheus.NewRegistry()
+		reg.MustRegister(duplicateCollector)
+		reg.Freeze(prometheus.FreezeOpts{SkipConsistencyChecks: skip})
+		_, err := reg.Gather()
+		if skip && err != nil {
+			t.Errorf("got error %v gathering duplicate metrics without consistency checks, want nil", err)
+		}
+		if !skip && err == nil {
+			t.Error("gathering duplicate metrics with consistency checks should return an error")
+		}
+	}
+
+	// Each metric is still checked on its own with SkipConsistencyChecks.
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(&customCollector{
+		collectFunc: func(ch chan<- prometheus.Metric) {
+			ch <- invalidLabelMetric{prometheus.NewDesc("invalid_total", "", nil, nil)}
+		},
+	})
+	reg.Freeze(prometheus.FreezeOpts{SkipConsistencyChecks: true})
+	if _, err := reg.Gather(); err == nil {
+		t.Error("gathering a metric with an invalid label value without consistency checks should return an error")
+	}
+}
+
+// invalidLabelMetric is a counter with a label value that is not valid UTF-8.
+type invalidLabelMetric struct {
+	desc *prometheus.Desc
+}
+
+func (m invalidLabelMetric) Desc() *prometheus.Desc { return m.desc }
+
+func (m invalidLabelMetric) Write(out *dto.Metric) error {
+	out.Label = []*dto.LabelPair{{Name: proto.String("label"), Value: proto.String("\xff")}}
+	out.Counter = &dto.Counter{Value: proto.Float64(1)}
+	return nil
+}
+
+func BenchmarkRegistryFreeze(b *testing.B) {
+	newRegistry := func() *prometheus.Registry {
+		reg := prometheus.NewRegistry()
+		for i := 0; i < 100; i++ {
+			vec := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "requests_total_" + strconv.Itoa(i), Help: "Requests."}, []string{"code"})
+			for j := 0; j < 100; j++ {
+				vec.WithLabelValues(strconv.Itoa(j)).Inc()
+			}
+			reg.MustRegister(vec)
+		}
+		return reg
+	}
+
+	for _, bc := range []struct {
+		name   string
+		freeze func(*prometheus.Registry)
+	}{
+		{name: "NotFrozen", freeze: func(*prometheus.Registry) {}},
+		{name: "Frozen", freeze: func(r *prometheus.Registry) { r.Freeze(prometheus.FreezeOpts{}) }},
+		{name: "FrozenSkipConsistencyChecks", freeze: func(r *prometheus.Registry) {
+			r.Freeze(prometheus.FreezeOpts{SkipConsistencyChecks: true})
+		}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			reg := newRegistry()
+			bc.freeze(reg)
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := reg.Gather(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package resilience provides standard metric sets for circuit breakers and
+// retry policies. Resilience libraries report events through the
+// BreakerObserver and RetryObserver interfaces, which the metric sets
+// implement, so that programs get consistent metric names regardless of the
+// library they use.
+package resilience
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// BreakerState is the state of a circuit breaker.
+type BreakerState int
+
+const (
+	// Closed lets calls through.
+	Closed BreakerState = iota
+	// HalfOpen lets a limited number of trial calls through.
+	HalfOpen
+	// Open rejects calls.
+	Open
+)
+
+var breakerStates = []BreakerState{Closed, HalfOpen, Open}
+
+func (s BreakerState) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case HalfOpen:
+		return "half_open"
+	case Open:
+		return "open"
+	default:
+		return fmt.Sprintf("BreakerState(%d)", int(s))
+	}
+}
+
+// BreakerObserver is notified of the events of circuit breakers. Each circuit
+// breaker is identified by a name of bounded cardinality, e.g. the name of
+// the downstream service it protects. Implementations must be safe for
+// concurrent use.
+type BreakerObserver interface {
+	// ObserveStateChange is called when the breaker changes from one
+	// state to another.
+	ObserveStateChange(breaker string, from, to BreakerState)
+	// ObserveShortCircuit is called when the breaker rejects a call
+	// without executing it.
+	ObserveShortCircuit(breaker string)
+	// ObserveCall is called when a call let through by the breaker
+	// completes, with whether the breaker counted it as a success.
+	ObserveCall(breaker string, success bool)
+}
+
+// MetricsOpts are the options of NewBreakerMetrics and NewRetryMetrics.
+type MetricsOpts struct {
+	// Namespace and Subsystem are prefixed to the metric names, as in
+	// prometheus.Opts.
+	Namespace string
+	Subsystem string
+	// ConstLabels are added to all metrics.
+	ConstLabels prometheus.Labels
+}
+
+// BreakerMetrics is a BreakerObserver recording the events of circuit breakers
+// as the following metrics, labeled with the name of the breaker:
+//
+//   - circuit_breaker_state: 1 for the current state of the breaker and 0 for
+//     the others, labeled with state ("closed", "half_open", or "open").
+//   - circuit_breaker_transitions_total: the state changes, labeled with from
+//     and to.
+//   - circuit_breaker_short_circuited_total: the calls rejected by the breaker.
+//   - circuit_breaker_calls_total: the calls let through by the breaker,
+//     labeled with result ("success" or "failure").
+//
+// BreakerMetrics is a prometheus.Collector to be registered with a registry.
+type BreakerMetrics struct {
+	state          *prometheus.GaugeVec
+	transitions    *prometheus.CounterVec
+	shortCircuited *prometheus.CounterVec
+	calls          *prometheus.CounterVec
+}
+
+// NewBreakerMetrics returns new BreakerMetrics.
+func NewBreakerMetrics(opts MetricsOpts) *BreakerMetrics {
+	return &BreakerMetrics{
+		state: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace:   opts.Namespace,
+			Subsystem:   opts.Subsystem,
+			Name:        "circuit_breaker_state",
+			Help:        "Whether the circuit breaker is in the state.",
+			ConstLabels: opts.ConstLabels,
+		}, []string{"breaker", "state"}),
+		transitions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   opts.Namespace,
+			Subsystem:   opts.Subsystem,
+			Name:        "circuit_breaker_transitions_total",
+			Help:        "Total number of state changes of the circuit breaker.",
+			ConstLabels: opts.ConstLabels,
+		}, []string{"breaker", "from", "to"}),
+		shortCircuited: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   opts.Namespace,
+			Subsystem:   opts.Subsystem,
+			Name:        "circuit_breaker_short_circuited_total",
+			Help:        "Total number of calls rejected by the circuit breaker.",
+			ConstLabels: opts.ConstLabels,
+		}, []string{"breaker"}),
+		calls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   opts.Namespace,
+			Subsystem:   opts.Subsystem,
+			Name:        "circuit_breaker_calls_total",
+			Help:        "Total number of calls let through by the circuit breaker by result.",
+			ConstLabels: opts.ConstLabels,
+		}, []string{"breaker", "result"}),
+	}
+}
+
+// Init initializes the metrics of a breaker in the provided state, so that
+// they are exposed before the first event.
+func (m *BreakerMetrics) Init(breaker string, state BreakerState) {
+	m.setState(breaker, state)
+	m.shortCircuited.WithLabelValues(breaker)
+	m.calls.WithLabelValues(breaker, "success")
+	m.calls.WithLabelValues(breaker, "failure")
+}
+
+func (m *BreakerMetrics) setState(breaker string, state BreakerState) {
+	for _, s := range breakerStates {
+		v := 0.0
+		if s == state {
+			v = 1
+		}
+		m.state.WithLabelValues(breaker, s.String()).Set(v)
+	}
+}
+
+// ObserveStateChange implements BreakerObserver.
+func (m *BreakerMetrics) ObserveStateChange(breaker string, from, to BreakerState) {
+	m.setState(breaker, to)
+	m.transitions.WithLabelValues(breaker, from.String(), to.String()).Inc()
+}
+
+// ObserveShortCircuit implements BreakerObserver.
+func (m *BreakerMetrics) ObserveShortCircuit(breaker string) {
+	m.shortCircuited.WithLabelValues(breaker).Inc()
+}
+
+// ObserveCall implements BreakerObserver.
+func (m *BreakerMetrics) ObserveCall(breaker string, success bool) {
+	m.calls.WithLabelValues(breaker, result(success)).Inc()
+}
+
+// Describe implements prometheus.Collector.
+func (m *BreakerMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.state.Describe(ch)
+	m.transitions.Describe(ch)
+	m.shortCircuited.Describe(ch)
+	m.calls.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (m *BreakerMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.state.Collect(ch)
+	m.transitions.Collect(ch)
+	m.shortCircuited.Collect(ch)
+	m.calls.Collect(ch)
+}
+
+func result(success bool) string {
+	if success {
+		return "success"
+	}
+	return "failure"
+}
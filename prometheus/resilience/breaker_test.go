@@ -0,0 +1,59 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resilience
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _ BreakerObserver = &BreakerMetrics{}
+
+func TestBreakerMetrics(t *testing.T) {
+	m := NewBreakerMetrics(MetricsOpts{Namespace: "app"})
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(m)
+
+	m.Init("db", Closed)
+	m.ObserveCall("db", true)
+	m.ObserveCall("db", false)
+	m.ObserveStateChange("db", Closed, Open)
+	m.ObserveShortCircuit("db")
+	m.ObserveShortCircuit("db")
+	m.ObserveStateChange("db", Open, HalfOpen)
+
+	expected := `# HELP app_circuit_breaker_calls_total Total number of calls let through by the circuit breaker by result.
+# TYPE app_circuit_breaker_calls_total counter
+app_circuit_breaker_calls_total{breaker="db",result="failure"} 1
+app_circuit_breaker_calls_total{breaker="db",result="success"} 1
+# HELP app_circuit_breaker_short_circuited_total Total number of calls rejected by the circuit breaker.
+# TYPE app_circuit_breaker_short_circuited_total counter
+app_circuit_breaker_short_circuited_total{breaker="db"} 2
+# HELP app_circuit_breaker_state Whether the circuit breaker is in the state.
+# TYPE app_circuit_breaker_state gauge
+app_circuit_breaker_state{breaker="db",state="closed"} 0
+app_circuit_breaker_state{breaker="db",state="half_open"} 1
+app_circuit_breaker_state{breaker="db",state="open"} 0
+# HELP app_circuit_breaker_transitions_total Total number of state changes of the circuit breaker.
+# TYPE app_circuit_breaker_transitions_total counter
+app_circuit_breaker_transitions_total{breaker="db",from="closed",to="open"} 1
+app_circuit_breaker_transitions_total{breaker="db",from="open",to="half_open"} 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected)); err != nil {
+		t.Fatal(err)
+	}
+}
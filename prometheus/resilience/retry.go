@@ -0,0 +1,151 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resilience
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RetryObserver is notified of the events of retry policies. Each retry policy
+// is identified by a name of bounded cardinality, e.g. the operation it
+// retries. Implementations must be safe for concurrent use.
+type RetryObserver interface {
+	// ObserveAttempt is called when an attempt of a call completes, with
+	// the 1-based number of the attempt and whether it succeeded.
+	ObserveAttempt(policy string, attempt int, success bool)
+	// ObserveBackoff is called with the delay before the next attempt.
+	ObserveBackoff(policy string, delay time.Duration)
+	// ObserveGiveUp is called when the policy stops retrying a failed
+	// call, for whatever reason, e.g. as all attempts are exhausted, the
+	// error isn't retryable, or the context is canceled, with the number
+	// of attempts made.
+	ObserveGiveUp(policy string, attempts int)
+}
+
+// RetryMetrics is a RetryObserver recording the events of retry policies as
+// the following metrics, labeled with the name of the policy:
+//
+//   - retry_attempts_total: the attempts, including the first one of each
+//     call, labeled with result ("success" or "failure").
+//   - retry_retries_total: the attempts after the first one of each call.
+//   - retry_backoff_seconds: a histogram of the delays between attempts.
+//   - retry_give_ups_total: the calls that failed after all the attempts
+//     made.
+//   - retry_call_attempts: a histogram of the number of attempts of the calls
+//     that succeeded or were given up.
+//
+// RetryMetrics is a prometheus.Collector to be registered with a registry.
+type RetryMetrics struct {
+	attempts     *prometheus.CounterVec
+	retries      *prometheus.CounterVec
+	backoff      *prometheus.HistogramVec
+	giveUps      *prometheus.CounterVec
+	callAttempts *prometheus.HistogramVec
+}
+
+// NewRetryMetrics returns new RetryMetrics.
+func NewRetryMetrics(opts MetricsOpts) *RetryMetrics {
+	return &RetryMetrics{
+		attempts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   opts.Namespace,
+			Subsystem:   opts.Subsystem,
+			Name:        "retry_attempts_total",
+			Help:        "Total number of attempts by result.",
+			ConstLabels: opts.ConstLabels,
+		}, []string{"policy", "result"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   opts.Namespace,
+			Subsystem:   opts.Subsystem,
+			Name:        "retry_retries_total",
+			Help:        "Total number of attempts after the first one of a call.",
+			ConstLabels: opts.ConstLabels,
+		}, []string{"policy"}),
+		backoff: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   opts.Namespace,
+			Subsystem:   opts.Subsystem,
+			Name:        "retry_backoff_seconds",
+			Help:        "Delay before retrying a call in seconds.",
+			ConstLabels: opts.ConstLabels,
+			Buckets:     prometheus.ExponentialBuckets(0.01, 4, 7),
+		}, []string{"policy"}),
+		giveUps: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   opts.Namespace,
+			Subsystem:   opts.Subsystem,
+			Name:        "retry_give_ups_total",
+			Help:        "Total number of calls that failed after all attempts.",
+			ConstLabels: opts.ConstLabels,
+		}, []string{"policy"}),
+		callAttempts: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   opts.Namespace,
+			Subsystem:   opts.Subsystem,
+			Name:        "retry_call_attempts",
+			Help:        "Number of attempts of a completed call.",
+			ConstLabels: opts.ConstLabels,
+			Buckets:     []float64{1, 2, 3, 5, 8, 13},
+		}, []string{"policy"}),
+	}
+}
+
+// Init initializes the metrics of a policy, so that they are exposed before
+// the first event.
+func (m *RetryMetrics) Init(policy string) {
+	m.attempts.WithLabelValues(policy, "success")
+	m.attempts.WithLabelValues(policy, "failure")
+	m.retries.WithLabelValues(policy)
+	m.backoff.WithLabelValues(policy)
+	m.giveUps.WithLabelValues(policy)
+	m.callAttempts.WithLabelValues(policy)
+}
+
+// ObserveAttempt implements RetryObserver.
+func (m *RetryMetrics) ObserveAttempt(policy string, attempt int, success bool) {
+	m.attempts.WithLabelValues(policy, result(success)).Inc()
+	if attempt > 1 {
+		m.retries.WithLabelValues(policy).Inc()
+	}
+	if success {
+		m.callAttempts.WithLabelValues(policy).Observe(float64(attempt))
+	}
+}
+
+// ObserveBackoff implements RetryObserver.
+func (m *RetryMetrics) ObserveBackoff(policy string, delay time.Duration) {
+	m.backoff.WithLabelValues(policy).Observe(delay.Seconds())
+}
+
+// ObserveGiveUp implements RetryObserver.
+func (m *RetryMetrics) ObserveGiveUp(policy string, attempts int) {
+	m.giveUps.WithLabelValues(policy).Inc()
+	m.callAttempts.WithLabelValues(policy).Observe(float64(attempts))
+}
+
+// Describe implements prometheus.Collector.
+func (m *RetryMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.attempts.Describe(ch)
+	m.retries.Describe(ch)
+	m.backoff.Describe(ch)
+	m.giveUps.Describe(ch)
+	m.callAttempts.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (m *RetryMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.attempts.Collect(ch)
+	m.retries.Collect(ch)
+	m.backoff.Collect(ch)
+	m.giveUps.Collect(ch)
+	m.callAttempts.Collect(ch)
+}
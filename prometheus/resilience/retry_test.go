@@ -0,0 +1,73 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resilience
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _ RetryObserver = &RetryMetrics{}
+
+func TestRetryMetrics(t *testing.T) {
+	m := NewRetryMetrics(MetricsOpts{})
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(m)
+
+	// A call succeeding at the second attempt.
+	m.ObserveAttempt("fetch", 1, false)
+	m.ObserveBackoff("fetch", 100*time.Millisecond)
+	m.ObserveAttempt("fetch", 2, true)
+	// A call given up after three attempts.
+	m.ObserveAttempt("fetch", 1, false)
+	m.ObserveBackoff("fetch", 100*time.Millisecond)
+	m.ObserveAttempt("fetch", 2, false)
+	m.ObserveBackoff("fetch", 400*time.Millisecond)
+	m.ObserveAttempt("fetch", 3, false)
+	m.ObserveGiveUp("fetch", 3)
+
+	expected := `# HELP retry_attempts_total Total number of attempts by result.
+# TYPE retry_attempts_total counter
+retry_attempts_total{policy="fetch",result="failure"} 4
+retry_attempts_total{policy="fetch",result="success"} 1
+# HELP retry_call_attempts Number of attempts of a completed call.
+# TYPE retry_call_attempts histogram
+retry_call_attempts_bucket{policy="fetch",le="1"} 0
+retry_call_attempts_bucket{policy="fetch",le="2"} 1
+retry_call_attempts_bucket{policy="fetch",le="3"} 2
+retry_call_attempts_bucket{policy="fetch",le="5"} 2
+retry_call_attempts_bucket{policy="fetch",le="8"} 2
+retry_call_attempts_bucket{policy="fetch",le="13"} 2
+retry_call_attempts_bucket{policy="fetch",le="+Inf"} 2
+retry_call_attempts_sum{policy="fetch"} 5
+retry_call_attempts_count{policy="fetch"} 2
+# HELP retry_give_ups_total Total number of calls that failed after all attempts.
+# TYPE retry_give_ups_total counter
+retry_give_ups_total{policy="fetch"} 1
+# HELP retry_retries_total Total number of attempts after the first one of a call.
+# TYPE retry_retries_total counter
+retry_retries_total{policy="fetch"} 3
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"retry_attempts_total", "retry_call_attempts", "retry_give_ups_total", "retry_retries_total"); err != nil {
+		t.Fatal(err)
+	}
+	if got := testutil.CollectAndCount(m, "retry_backoff_seconds"); got != 1 {
+		t.Errorf("got %d backoff histograms, want 1", got)
+	}
+}
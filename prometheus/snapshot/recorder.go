@@ -0,0 +1,262 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package snapshot provides a Recorder that periodically takes snapshots of
+// the metrics of a Gatherer in the text exposition format, for post-incident
+// analysis of the metric state at times no scraper captured. The snapshots are
+// kept in an in-memory ring buffer, which the Recorder serves as an HTTP debug
+// endpoint, and optionally written to a rotating set of files.
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	defaultInterval = time.Minute
+	defaultKeep     = 10
+
+	filePrefix = "metrics-"
+	fileSuffix = ".prom"
+	// fileTimeFormat is sortable and free of characters that are invalid
+	// in file names on some systems.
+	fileTimeFormat = "20060102T150405.000Z"
+)
+
+// Config defines the Recorder config.
+type Config struct {
+	// The Gatherer to take snapshots of. Defaults to
+	// prometheus.DefaultGatherer.
+	Gatherer prometheus.Gatherer
+
+	// The interval between two snapshots. Defaults to one minute.
+	Interval time.Duration
+
+	// The number of snapshots kept in memory and, if Dir is set, on disk.
+	// Defaults to 10.
+	Keep int
+
+	// The directory the snapshots are written to, as files named like
+	// "metrics-20260102T150405.000Z.prom" with the UTC time of the
+	// snapshot. Only the newest Keep files are kept. Defaults to not
+	// writing files.
+	Dir string
+
+	// The logger that errors of Run are written to. Defaults to no logging.
+	Logger Logger
+}
+
+// Logger is the minimal interface Recorder needs for logging. Note that
+// log.Logger from the standard library implements this interface, and it is
+// easy to implement by custom loggers, if they don't do so already anyway.
+type Logger interface {
+	Println(v ...any)
+}
+
+// Snapshot is the state of the metrics at a point in time.
+type Snapshot struct {
+	Time time.Time
+	// Data are the metrics in the text exposition format.
+	Data []byte
+}
+
+// Recorder takes snapshots of the metrics of a Gatherer. It implements
+// http.Handler to serve the snapshots, see ServeHTTP.
+type Recorder struct {
+	g        prometheus.Gatherer
+	interval time.Duration
+	keep     int
+	dir      string
+	logger   Logger
+
+	mtx sync.Mutex
+	// ring holds the snapshots, oldest first.
+	ring []Snapshot
+}
+
+// NewRecorder returns a pointer to a new Recorder struct. If Dir is set, it is
+// created if needed.
+func NewRecorder(c *Config) (*Recorder, error) {
+	r := &Recorder{
+		g:        c.Gatherer,
+		interval: c.Interval,
+		keep:     c.Keep,
+		dir:      c.Dir,
+		logger:   c.Logger,
+	}
+	if r.g == nil {
+		r.g = prometheus.DefaultGatherer
+	}
+	if r.interval <= 0 {
+		r.interval = defaultInterval
+	}
+	if r.keep <= 0 {
+		r.keep = defaultKeep
+	}
+	if r.dir != "" {
+		if err := os.MkdirAll(r.dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Run takes a snapshot at the configured interval until ctx is canceled. The
+// first snapshot is taken immediately.
+func (r *Recorder) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := r.Take(); err != nil && r.logger != nil {
+			r.logger.Println("error taking metrics snapshot:", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Take takes a snapshot, adds it to the ring buffer, and writes it to a file
+// if configured. A gathering error doesn't prevent a snapshot of the
+// successfully gathered metrics, but is returned nonetheless.
+func (r *Recorder) Take() (Snapshot, error) {
+	now := time.Now()
+	mfs, gatherErr := r.g.Gather()
+
+	var buf bytes.Buffer
+	enc := expfmt.NewEncoder(&buf, expfmt.NewFormat(expfmt.TypeTextPlain))
+	for _, mf := range mfs {
+		if err := enc.Encode(mf); err != nil {
+			return Snapshot{}, err
+		}
+	}
+	s := Snapshot{Time: now, Data: buf.Bytes()}
+
+	r.mtx.Lock()
+	r.ring = append(r.ring, s)
+	if len(r.ring) > r.keep {
+		r.ring = append(r.ring[:0], r.ring[len(r.ring)-r.keep:]...)
+	}
+	r.mtx.Unlock()
+
+	var fileErr error
+	if r.dir != "" {
+		fileErr = r.writeFile(s)
+	}
+	if gatherErr != nil {
+		gatherErr = fmt.Errorf("gathering metrics failed: %w", gatherErr)
+	}
+	return s, errors.Join(gatherErr, fileErr)
+}
+
+// writeFile writes s to a new file and removes the oldest files beyond the
+// configured number.
+func (r *Recorder) writeFile(s Snapshot) error {
+	name := filepath.Join(r.dir, filePrefix+s.Time.UTC().Format(fileTimeFormat)+fileSuffix)
+	if err := os.WriteFile(name, s.Data, 0o644); err != nil {
+		return err
+	}
+	files, err := filepath.Glob(filepath.Join(r.dir, filePrefix+"*"+fileSuffix))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	var errs []error
+	for len(files) > r.keep {
+		if err := os.Remove(files[0]); err != nil {
+			errs = append(errs, err)
+		}
+		files = files[1:]
+	}
+	return errors.Join(errs...)
+}
+
+// Snapshots returns the snapshots in the ring buffer, oldest first.
+func (r *Recorder) Snapshots() []Snapshot {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return append([]Snapshot(nil), r.ring...)
+}
+
+// At returns the newest snapshot taken at or before t, or false if there is
+// none.
+func (r *Recorder) At(t time.Time) (Snapshot, bool) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	for i := len(r.ring) - 1; i >= 0; i-- {
+		if !r.ring[i].Time.After(t) {
+			return r.ring[i], true
+		}
+	}
+	return Snapshot{}, false
+}
+
+// ServeHTTP implements http.Handler. Without parameters, it responds with a
+// list of the times of the snapshots in the ring buffer, in RFC 3339 format,
+// oldest first. With the parameter "at", which is either such a time, a Unix
+// timestamp in seconds, or "latest", it responds with the newest snapshot
+// taken at or before that time.
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	at := req.URL.Query().Get("at")
+	if at == "" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		for _, s := range r.Snapshots() {
+			fmt.Fprintln(w, s.Time.UTC().Format(time.RFC3339Nano))
+		}
+		return
+	}
+
+	t, err := parseTime(at)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s, ok := r.At(t)
+	if !ok {
+		http.Error(w, "no snapshot taken at or before "+at, http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", string(expfmt.NewFormat(expfmt.TypeTextPlain)))
+	w.Header().Set("Last-Modified", s.Time.UTC().Format(http.TimeFormat))
+	w.Write(s.Data)
+}
+
+// parseTime parses the "at" parameter of ServeHTTP.
+func parseTime(s string) (time.Time, error) {
+	if s == "latest" {
+		return time.Now(), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Unix(0, int64(secs*float64(time.Second))), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: want RFC 3339, Unix seconds, or \"latest\"", s)
+}
@@ -0,0 +1,161 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func newTestRecorder(t *testing.T, dir string) (*Recorder, prometheus.Gauge) {
+	t.Helper()
+	reg := prometheus.NewRegistry()
+	g := prometheus.NewGauge(prometheus.GaugeOpts{Name: "queue_length", Help: "Queue length."})
+	reg.MustRegister(g)
+	r, err := NewRecorder(&Config{Gatherer: reg, Keep: 2, Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r, g
+}
+
+func TestRecorderRing(t *testing.T) {
+	r, g := newTestRecorder(t, "")
+	var taken []Snapshot
+	for _, v := range []float64{1, 2, 3} {
+		g.Set(v)
+		s, err := r.Take()
+		if err != nil {
+			t.Fatal(err)
+		}
+		taken = append(taken, s)
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	snapshots := r.Snapshots()
+	if len(snapshots) != 2 {
+		t.Fatalf("got %d snapshots, want 2", len(snapshots))
+	}
+	for i, want := range []string{"queue_length 2", "queue_length 3"} {
+		if !strings.Contains(string(snapshots[i].Data), want) {
+			t.Errorf("snapshot %d doesn't contain %q:\n%s", i, want, snapshots[i].Data)
+		}
+	}
+
+	if _, ok := r.At(taken[0].Time); ok {
+		t.Error("got snapshot that was dropped from the ring")
+	}
+	if s, ok := r.At(taken[2].Time.Add(-time.Nanosecond)); !ok || !s.Time.Equal(taken[1].Time) {
+		t.Errorf("got snapshot at %v, want %v", s.Time, taken[1].Time)
+	}
+}
+
+func TestRecorderFiles(t *testing.T) {
+	dir := t.TempDir()
+	r, g := newTestRecorder(t, dir)
+	for _, v := range []float64{1, 2, 3} {
+		g.Set(v)
+		if _, err := r.Take(); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, filePrefix+"*"+fileSuffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("got files %v, want 2", files)
+	}
+	data, err := os.ReadFile(files[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "queue_length 3") {
+		t.Errorf("newest file doesn't contain the newest value:\n%s", data)
+	}
+}
+
+func TestRecorderServeHTTP(t *testing.T) {
+	r, g := newTestRecorder(t, "")
+	g.Set(1)
+	first, err := r.Take()
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	g.Set(2)
+	second, err := r.Take()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(query string) (int, string) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/snapshots"+query, nil))
+		body, _ := io.ReadAll(rec.Body)
+		return rec.Code, string(body)
+	}
+
+	code, body := get("")
+	want := first.Time.UTC().Format(time.RFC3339Nano) + "\n" + second.Time.UTC().Format(time.RFC3339Nano) + "\n"
+	if code != http.StatusOK || body != want {
+		t.Errorf("got index %d %q, want %q", code, body, want)
+	}
+
+	for _, tc := range []struct {
+		at       string
+		wantCode int
+		want     string
+	}{
+		{at: "latest", wantCode: http.StatusOK, want: "queue_length 2"},
+		{at: first.Time.UTC().Format(time.RFC3339Nano), wantCode: http.StatusOK, want: "queue_length 1"},
+		{at: strconv.FormatInt(second.Time.Unix()+1, 10), wantCode: http.StatusOK, want: "queue_length 2"},
+		{at: "2001-01-01T00:00:00Z", wantCode: http.StatusNotFound},
+		{at: "yesterday", wantCode: http.StatusBadRequest},
+	} {
+		code, body := get("?at=" + tc.at)
+		if code != tc.wantCode || !strings.Contains(body, tc.want) {
+			t.Errorf("at=%s: got %d %q, want %d containing %q", tc.at, code, body, tc.wantCode, tc.want)
+		}
+	}
+}
+
+func TestRecorderRun(t *testing.T) {
+	r, _ := newTestRecorder(t, "")
+	r.interval = time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		r.Run(ctx)
+		close(done)
+	}()
+	for len(r.Snapshots()) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+}
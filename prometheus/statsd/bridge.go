@@ -0,0 +1,354 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package statsd provides a bridge that receives StatsD and DogStatsD
+// datagrams and exposes the aggregated metrics as a prometheus.Collector. It
+// is an in-process alternative to the statsd_exporter for programs that
+// contain, or are migrating away from, StatsD instrumentation.
+//
+// StatsD counters become Prometheus counters, gauges become gauges, and
+// timers, histograms, and distributions become histograms. Timer values are
+// converted from milliseconds to seconds. DogStatsD tags become labels, and
+// Mappings allow to derive metric names and labels from the components of
+// dot-separated StatsD names.
+package statsd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Config defines the StatsD bridge config.
+type Config struct {
+	// The mappings applied to incoming metrics. The first matching mapping
+	// is used. Metrics not matched by any mapping are exposed with their
+	// StatsD name, with all characters not allowed in metric names
+	// replaced by underscores.
+	Mappings []Mapping
+
+	// The buckets of histograms created for timers, histograms, and
+	// distributions. Defaults to prometheus.DefBuckets.
+	Buckets []float64
+
+	// The logger that errors of Serve and ListenAndServe are written to.
+	// Defaults to no logging.
+	Logger Logger
+}
+
+// Mapping maps StatsD metrics to Prometheus metric names and labels.
+type Mapping struct {
+	// Match is the dot-separated StatsD name to match, in which "*"
+	// matches a single component, e.g. "http.*.*.requests". Required.
+	Match string
+	// Name is the name of the Prometheus metric. It may reference the
+	// components matched by the wildcards as "$1", "$2", etc. Required.
+	Name string
+	// Labels are added to the metric, in addition to the DogStatsD tags.
+	// The values may reference the matched components like Name.
+	Labels map[string]string
+	// Help is the help text of the metric. Defaults to a generic text.
+	Help string
+	// Buckets are the buckets of the histogram, if the metric is a timer,
+	// histogram, or distribution. Defaults to Config.Buckets.
+	Buckets []float64
+}
+
+// Logger is the minimal interface Bridge needs for logging. Note that
+// log.Logger from the standard library implements this interface, and it is
+// easy to implement by custom loggers, if they don't do so already anyway.
+type Logger interface {
+	Println(v ...any)
+}
+
+// Bridge aggregates StatsD metrics and exposes them as Prometheus metrics. It
+// implements prometheus.Collector and is meant to be registered with a
+// Registry. As the metrics are only known once received, it is an unchecked
+// Collector.
+type Bridge struct {
+	mappings []Mapping
+	buckets  []float64
+	logger   Logger
+
+	mtx      sync.Mutex
+	families map[string]*family
+}
+
+// family are the series of a metric.
+type family struct {
+	desc       *prometheus.Desc
+	typ        metricType
+	labelNames []string
+	buckets    []float64
+	series     map[string]*series
+}
+
+type series struct {
+	labelValues []string
+	// value is the value of a counter or gauge.
+	value float64
+	// count, sum, and bucketCounts are the state of a histogram, with
+	// cumulative bucket counts.
+	count, sum   float64
+	bucketCounts []float64
+}
+
+// NewBridge returns a pointer to a new Bridge struct.
+func NewBridge(c *Config) (*Bridge, error) {
+	for _, m := range c.Mappings {
+		if m.Match == "" || m.Name == "" {
+			return nil, errors.New("mapping without match or name")
+		}
+	}
+	b := &Bridge{
+		mappings: c.Mappings,
+		buckets:  c.Buckets,
+		logger:   c.Logger,
+		families: map[string]*family{},
+	}
+	if b.buckets == nil {
+		b.buckets = prometheus.DefBuckets
+	}
+	return b, nil
+}
+
+// ListenAndServe listens on the provided network address, e.g. "udp" and
+// ":8125" or "unixgram" and the path of a Unix domain socket, and calls Serve.
+func (b *Bridge) ListenAndServe(ctx context.Context, network, address string) error {
+	conn, err := net.ListenPacket(network, address)
+	if err != nil {
+		return err
+	}
+	return b.Serve(ctx, conn)
+}
+
+// Serve reads datagrams from conn and handles them until ctx is canceled,
+// which closes conn. Errors of malformed datagrams are logged and don't stop
+// serving.
+func (b *Bridge) Serve(ctx context.Context, conn net.PacketConn) error {
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	buf := make([]byte, 65535)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if err := b.Handle(buf[:n]); err != nil && b.logger != nil {
+			b.logger.Println("error handling StatsD datagram:", err)
+		}
+	}
+}
+
+// Handle aggregates the metrics in the provided datagram, which contains one
+// StatsD line per metric. It returns the errors of invalid lines, which are
+// skipped.
+func (b *Bridge) Handle(datagram []byte) error {
+	var errs []error
+	for _, line := range strings.Split(string(datagram), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		s, err := parseLine(line)
+		if err == nil {
+			err = b.add(s)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (b *Bridge) add(s sample) error {
+	name, labels, help, buckets := b.mapSample(s)
+	labelNames := make([]string, 0, len(labels))
+	for ln := range labels {
+		labelNames = append(labelNames, ln)
+	}
+	sort.Strings(labelNames)
+	labelValues := make([]string, 0, len(labelNames))
+	for _, ln := range labelNames {
+		labelValues = append(labelValues, labels[ln])
+	}
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	f, ok := b.families[name]
+	if !ok {
+		desc := prometheus.NewDesc(name, help, labelNames, nil)
+		// Reject invalid names and label values here rather than
+		// failing in Collect.
+		if _, err := prometheus.NewConstMetric(desc, prometheus.UntypedValue, 0, labelValues...); err != nil {
+			return fmt.Errorf("invalid metric %s: %w", name, err)
+		}
+		f = &family{
+			desc:       desc,
+			typ:        s.typ,
+			labelNames: labelNames,
+			buckets:    buckets,
+			series:     map[string]*series{},
+		}
+		b.families[name] = f
+	}
+	if f.typ != s.typ {
+		return fmt.Errorf("metric %s received as %s, but was a %s before", name, s.typ, f.typ)
+	}
+	if strings.Join(f.labelNames, ",") != strings.Join(labelNames, ",") {
+		return fmt.Errorf("metric %s received with labels %v, but had labels %v before", name, labelNames, f.labelNames)
+	}
+
+	key := strings.Join(labelValues, "\xff")
+	ser, ok := f.series[key]
+	if !ok {
+		if _, err := prometheus.NewConstMetric(f.desc, prometheus.UntypedValue, 0, labelValues...); err != nil {
+			return fmt.Errorf("invalid metric %s: %w", name, err)
+		}
+		ser = &series{labelValues: labelValues}
+		if f.typ == timerType {
+			ser.bucketCounts = make([]float64, len(f.buckets))
+		}
+		f.series[key] = ser
+	}
+
+	switch f.typ {
+	case counterType:
+		if s.value < 0 {
+			return fmt.Errorf("counter %s received negative value %g", name, s.value)
+		}
+		ser.value += s.value / s.rate
+	case gaugeType:
+		if s.relative {
+			ser.value += s.value
+		} else {
+			ser.value = s.value
+		}
+	case timerType:
+		weight := 1 / s.rate
+		ser.count += weight
+		ser.sum += s.value * weight
+		for i, upper := range f.buckets {
+			if s.value <= upper {
+				ser.bucketCounts[i] += weight
+			}
+		}
+	}
+	return nil
+}
+
+// mapSample returns the name, labels, help, and buckets of the metric of s.
+func (b *Bridge) mapSample(s sample) (name string, labels map[string]string, help string, buckets []float64) {
+	labels = map[string]string{}
+	for k, v := range s.tags {
+		labels[strings.ReplaceAll(sanitizeName(k), ":", "_")] = v
+	}
+	name = sanitizeName(s.name)
+	help = fmt.Sprintf("StatsD %s %s.", s.typ, s.name)
+	buckets = b.buckets
+
+	components := strings.Split(s.name, ".")
+	for _, m := range b.mappings {
+		matches, ok := match(m.Match, components)
+		if !ok {
+			continue
+		}
+		name = sanitizeName(expand(m.Name, matches))
+		for k, v := range m.Labels {
+			labels[k] = expand(v, matches)
+		}
+		if m.Help != "" {
+			help = m.Help
+		}
+		if m.Buckets != nil {
+			buckets = m.Buckets
+		}
+		break
+	}
+	return name, labels, help, buckets
+}
+
+// match matches the components of a StatsD name against the pattern and
+// returns the components matched by wildcards.
+func match(pattern string, components []string) ([]string, bool) {
+	parts := strings.Split(pattern, ".")
+	if len(parts) != len(components) {
+		return nil, false
+	}
+	var matches []string
+	for i, p := range parts {
+		switch p {
+		case "*":
+			matches = append(matches, components[i])
+		case components[i]:
+		default:
+			return nil, false
+		}
+	}
+	return matches, true
+}
+
+// expand replaces the references $1, $2, etc. in s with the matches.
+func expand(s string, matches []string) string {
+	// Replace higher references first, so that $1 doesn't match $10.
+	for i := len(matches); i > 0; i-- {
+		s = strings.ReplaceAll(s, fmt.Sprintf("$%d", i), matches[i-1])
+	}
+	return s
+}
+
+// Describe implements prometheus.Collector. It describes nothing, which makes
+// the Bridge an unchecked Collector.
+func (b *Bridge) Describe(chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector.
+func (b *Bridge) Collect(ch chan<- prometheus.Metric) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	for _, f := range b.families {
+		for _, s := range f.series {
+			var (
+				m   prometheus.Metric
+				err error
+			)
+			switch f.typ {
+			case counterType:
+				m, err = prometheus.NewConstMetric(f.desc, prometheus.CounterValue, s.value, s.labelValues...)
+			case gaugeType:
+				m, err = prometheus.NewConstMetric(f.desc, prometheus.GaugeValue, s.value, s.labelValues...)
+			case timerType:
+				buckets := make(map[float64]uint64, len(f.buckets))
+				for i, upper := range f.buckets {
+					buckets[upper] = uint64(s.bucketCounts[i])
+				}
+				m, err = prometheus.NewConstHistogram(f.desc, uint64(s.count), s.sum, buckets, s.labelValues...)
+			}
+			if err != nil {
+				m = prometheus.NewInvalidMetric(f.desc, err)
+			}
+			ch <- m
+		}
+	}
+}
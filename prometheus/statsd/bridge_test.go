@@ -0,0 +1,172 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statsd
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestHandle(t *testing.T) {
+	b, err := NewBridge(&Config{
+		Buckets: []float64{0.1, 1},
+		Mappings: []Mapping{{
+			Match:  "http.*.requests",
+			Name:   "http_requests_total",
+			Labels: map[string]string{"method": "$1"},
+			Help:   "HTTP requests.",
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = b.Handle([]byte(`http.get.requests:1|c|#code:200
+http.get.requests:1|c|@0.5|#code:200
+http.post.requests:1|c|#code:500
+queue.size:10|g
+queue.size:-3|g
+request.duration:50|ms
+request.duration:500|ms|#ignored
+request.duration:5000|ms|#ignored
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const expected = `
+# HELP http_requests_total HTTP requests.
+# TYPE http_requests_total counter
+http_requests_total{code="200",method="get"} 3
+http_requests_total{code="500",method="post"} 1
+# HELP queue_size StatsD gauge queue.size.
+# TYPE queue_size gauge
+queue_size 7
+`
+	if err := testutil.CollectAndCompare(b, strings.NewReader(expected), "http_requests_total", "queue_size"); err != nil {
+		t.Error(err)
+	}
+
+	// Tags without value are ignored.
+	h := testutil.ToHistogram(collectorFor(b, "request_duration"))
+	if h.Count != 3 || h.Sum != 5.55 {
+		t.Errorf("got histogram with count %d and sum %g, want 3 and 5.55", h.Count, h.Sum)
+	}
+	if q := h.Quantile(0.5); q < 0.1 || q > 1 {
+		t.Errorf("got median %g, want between 0.1 and 1", q)
+	}
+
+	for _, line := range []string{"foo", "foo:1", "foo:1|x", "foo:x|c", "foo:1|c|@2", "http.get.requests:1|g|#code:200", "queue.size:1|g|#a:b"} {
+		if err := b.Handle([]byte(line)); err == nil {
+			t.Errorf("expected error for line %q", line)
+		}
+	}
+}
+
+func TestHandleInvalidTags(t *testing.T) {
+	b, err := NewBridge(&Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Handle([]byte("valid:1|c|#k:v")); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"invalid_value:1|c|#k:\xff",
+		"valid:1|c|#k:\xff",
+		"reserved_name:1|c|#__k:v",
+		"histogram:1|ms|#k:\xff",
+	} {
+		if err := b.Handle([]byte(line)); err == nil {
+			t.Errorf("expected error for line %q", line)
+		}
+	}
+
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(b)
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mfs) != 1 || len(mfs[0].GetMetric()) != 1 {
+		t.Errorf("got %v, want only the valid series", mfs)
+	}
+}
+
+// collectorFor returns a Collector collecting only the metric with the name.
+func collectorFor(b *Bridge, name string) prometheus.Collector {
+	return filteredCollector{b: b, name: name}
+}
+
+type filteredCollector struct {
+	b    *Bridge
+	name string
+}
+
+func (c filteredCollector) Describe(chan<- *prometheus.Desc) {}
+
+func (c filteredCollector) Collect(ch chan<- prometheus.Metric) {
+	all := make(chan prometheus.Metric)
+	go func() {
+		c.b.Collect(all)
+		close(all)
+	}()
+	for m := range all {
+		if strings.Contains(m.Desc().String(), `fqName: "`+c.name+`"`) {
+			ch <- m
+		}
+	}
+}
+
+func TestServe(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewBridge(&Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- b.Serve(ctx, conn) }()
+
+	client, err := net.Dial("udp", conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if _, err := client.Write([]byte("jobs.done:2|c")); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for testutil.CollectAndCount(b) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := testutil.ToFloat64(b); got != 2 {
+		t.Errorf("got %g, want 2", got)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+}
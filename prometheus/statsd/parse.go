@@ -0,0 +1,131 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statsd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type metricType int
+
+const (
+	counterType metricType = iota
+	gaugeType
+	timerType
+)
+
+func (t metricType) String() string {
+	switch t {
+	case counterType:
+		return "counter"
+	case gaugeType:
+		return "gauge"
+	default:
+		return "timer"
+	}
+}
+
+// sample is a parsed StatsD line.
+type sample struct {
+	name  string
+	typ   metricType
+	value float64
+	// relative is true for gauge values with sign, which are added to the
+	// current value.
+	relative bool
+	// rate is the sample rate in (0, 1].
+	rate float64
+	tags map[string]string
+}
+
+// parseLine parses a line in the StatsD format with DogStatsD extensions:
+//
+//	<name>:<value>|<type>[|@<sample rate>][|#<tag>:<value>,<tag>:<value>]
+//
+// The types c (counter), g (gauge), ms (timer), h (histogram), and
+// d (distribution) are supported. Other fields, like DogStatsD's container ID
+// or timestamp, are ignored.
+func parseLine(line string) (sample, error) {
+	name, rest, ok := strings.Cut(line, ":")
+	if !ok || name == "" {
+		return sample{}, fmt.Errorf("invalid line %q: missing name", line)
+	}
+	fields := strings.Split(rest, "|")
+	if len(fields) < 2 {
+		return sample{}, fmt.Errorf("invalid line %q: missing type", line)
+	}
+
+	s := sample{name: name, rate: 1}
+	switch fields[1] {
+	case "c":
+		s.typ = counterType
+	case "g":
+		s.typ = gaugeType
+		s.relative = strings.HasPrefix(fields[0], "+") || strings.HasPrefix(fields[0], "-")
+	case "ms", "h", "d":
+		s.typ = timerType
+	default:
+		return sample{}, fmt.Errorf("invalid line %q: unsupported type %q", line, fields[1])
+	}
+	v, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return sample{}, fmt.Errorf("invalid line %q: %w", line, err)
+	}
+	if fields[1] == "ms" {
+		// Timers are in milliseconds, Prometheus uses seconds.
+		v /= 1000
+	}
+	s.value = v
+
+	for _, f := range fields[2:] {
+		switch {
+		case strings.HasPrefix(f, "@"):
+			rate, err := strconv.ParseFloat(f[1:], 64)
+			if err != nil || rate <= 0 || rate > 1 {
+				return sample{}, fmt.Errorf("invalid line %q: invalid sample rate %q", line, f[1:])
+			}
+			s.rate = rate
+		case strings.HasPrefix(f, "#"):
+			s.tags = map[string]string{}
+			for _, tag := range strings.Split(f[1:], ",") {
+				// Tags without value can't be represented as labels,
+				// as an empty label value is equivalent to a
+				// missing label.
+				k, v, _ := strings.Cut(tag, ":")
+				if k != "" && v != "" {
+					s.tags[k] = v
+				}
+			}
+		}
+	}
+	return s, nil
+}
+
+// sanitizeName replaces all characters not allowed in legacy Prometheus
+// metric and label names with underscores, e.g. the dots of StatsD names.
+func sanitizeName(s string) string {
+	var sb strings.Builder
+	for i, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_', r == ':':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			r = '_'
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,179 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"sort"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/prometheus/client_golang/prometheus/internal"
+)
+
+// StreamingGatherer is a Gatherer that can also pass the gathered
+// MetricFamilies one by one to a callback instead of returning all of them in
+// a slice, so that the memory required for gathering doesn't scale with the
+// total number of series. The Registry implements StreamingGatherer.
+type StreamingGatherer interface {
+	Gatherer
+	// GatherFunc calls f with each gathered MetricFamily. The
+	// MetricFamilies are uniquely named and self-consistent as with
+	// Gather, but they are not necessarily passed in lexicographic order
+	// of their names. f must not retain the MetricFamily or any part of
+	// it after returning. If f returns an error, GatherFunc stops and
+	// returns that error. Otherwise, errors encountered while gathering are
+	// handled as by Gather, i.e. GatherFunc passes as many MetricFamilies
+	// as possible to f and returns the errors afterwards.
+	GatherFunc(f func(*dto.MetricFamily) error) error
+}
+
+// GatherFunc implements StreamingGatherer. The registered Collectors are
+// collected in groups, where each group consists of the Collectors sharing
+// metric names (according to their descriptors), so that only the
+// MetricFamilies of one group are held in memory at a time. The
+// MetricFamilies of a group are passed to f sorted by name, and the groups are
+// ordered by the first name of their MetricFamilies. The MetricFamilies of
+// unchecked Collectors, which can't be grouped, are gathered first and held
+// in memory until the end of the call.
+//
+// Collisions between the names of histograms or summaries and the names of
+// other metrics (e.g. "x" and "x_count") are only detected within a group.
+func (r *Registry) GatherFunc(f func(*dto.MetricFamily) error) error {
+	p := r.frozen.Load()
+	if p == nil {
+		p = r.plan(false)
+	}
+	var (
+		groups = r.collectorGroups()
+		pool   = &dtoPool{}
+		errs   MultiError
+	)
+	newMetricHashes := func() map[uint64]struct{} {
+		if p.skipConsistencyChecks {
+			return nil
+		}
+		return map[uint64]struct{}{}
+	}
+
+	uncheckedFamiliesByName := map[string]*dto.MetricFamily{}
+	if len(p.unchecked) > 0 {
+		metricHashes := newMetricHashes()
+		errs = append(errs, collect(nil, p.unchecked, func(metric Metric, _ bool) error {
+			return processMetric(metric, uncheckedFamiliesByName, metricHashes, nil, pool)
+		})...)
+	}
+
+	for _, g := range groups {
+		var (
+			metricFamiliesByName = make(map[string]*dto.MetricFamily, len(g.names))
+			metricHashes         = newMetricHashes()
+		)
+		// Merge the MetricFamilies of unchecked Collectors sharing a
+		// name with the group.
+		for _, name := range g.names {
+			mf, ok := uncheckedFamiliesByName[name]
+			if !ok {
+				continue
+			}
+			delete(uncheckedFamiliesByName, name)
+			metricFamiliesByName[name] = mf
+			if metricHashes != nil {
+				for _, m := range mf.Metric {
+					// Already checked among each other.
+					_ = checkMetricConsistency(mf, m, metricHashes)
+				}
+			}
+		}
+		errs = append(errs, collect(g.collectors, nil, func(metric Metric, _ bool) error {
+			return processMetric(metric, metricFamiliesByName, metricHashes, p.registeredDescIDs, pool)
+		})...)
+		mfs := internal.NormalizeMetricFamilies(metricFamiliesByName)
+		for _, mf := range mfs {
+			if err := f(mf); err != nil {
+				return err
+			}
+		}
+		pool.put(mfs)
+	}
+
+	for _, mf := range internal.NormalizeMetricFamilies(uncheckedFamiliesByName) {
+		if err := f(mf); err != nil {
+			return err
+		}
+	}
+	return errs.MaybeUnwrap()
+}
+
+// collectorGroup is a group of Collectors sharing metric names.
+type collectorGroup struct {
+	names      []string // Sorted.
+	collectors []Collector
+}
+
+// collectorGroups returns the checked Collectors grouped by their metric
+// names, ordered by the first name of each group.
+func (r *Registry) collectorGroups() []collectorGroup {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	var (
+		collectors  = make([]Collector, 0, len(r.collectorsByID))
+		parents     = make([]int, 0, len(r.collectorsByID))
+		firstByName = map[string]int{} // Index of the first Collector with a name.
+	)
+	// find returns the root of the union-find tree of Collector i.
+	find := func(i int) int {
+		for parents[i] != i {
+			parents[i] = parents[parents[i]]
+			i = parents[i]
+		}
+		return i
+	}
+	for id, c := range r.collectorsByID {
+		i := len(collectors)
+		collectors = append(collectors, c)
+		parents = append(parents, i)
+		for _, name := range r.namesByID[id] {
+			if j, ok := firstByName[name]; ok {
+				parents[find(i)] = find(j)
+				continue
+			}
+			firstByName[name] = i
+		}
+	}
+
+	groupByRoot := map[int]*collectorGroup{}
+	for i, c := range collectors {
+		root := find(i)
+		g, ok := groupByRoot[root]
+		if !ok {
+			g = &collectorGroup{}
+			groupByRoot[root] = g
+		}
+		g.collectors = append(g.collectors, c)
+	}
+	for name, i := range firstByName {
+		g := groupByRoot[find(i)]
+		g.names = append(g.names, name)
+	}
+	groups := make([]collectorGroup, 0, len(groupByRoot))
+	for _, g := range groupByRoot {
+		sort.Strings(g.names)
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].names[0] < groups[j].names[0]
+	})
+	return groups
+}
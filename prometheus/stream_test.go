@@ -0,0 +1,183 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+)
+
+// gatherFunc returns copies of the MetricFamilies passed by GatherFunc, sorted
+// by name.
+func gatherFunc(t *testing.T, sg StreamingGatherer) ([]*dto.MetricFamily, error) {
+	t.Helper()
+	var mfs []*dto.MetricFamily
+	err := sg.GatherFunc(func(mf *dto.MetricFamily) error {
+		mfs = append(mfs, proto.Clone(mf).(*dto.MetricFamily))
+		return nil
+	})
+	sort.Slice(mfs, func(i, j int) bool { return mfs[i].GetName() < mfs[j].GetName() })
+	return mfs, err
+}
+
+func TestRegistryGatherFunc(t *testing.T) {
+	reg := NewPedanticRegistry()
+	a1 := NewCounter(CounterOpts{Name: "a_total", Help: "A.", ConstLabels: Labels{"instance": "1"}})
+	a2 := NewCounter(CounterOpts{Name: "a_total", Help: "A.", ConstLabels: Labels{"instance": "2"}})
+	b := NewGaugeVec(GaugeOpts{Name: "b", Help: "B."}, []string{"l"})
+	c := NewHistogram(HistogramOpts{Name: "c_seconds", Help: "C.", Buckets: []float64{1}})
+	// Shares a name with the gauge vector.
+	uncheckedB := NewGauge(GaugeOpts{Name: "b", Help: "B.", ConstLabels: Labels{"l": "unchecked"}})
+	uncheckedD := NewGauge(GaugeOpts{Name: "d", Help: "D."})
+	reg.MustRegister(a1, a2, b, c, uncheckedCollector{c: uncheckedB}, uncheckedCollector{c: uncheckedD})
+
+	a1.Inc()
+	a2.Add(2)
+	for i := 0; i < 3; i++ {
+		b.WithLabelValues(strconv.Itoa(i)).Set(float64(i))
+	}
+	c.Observe(0.5)
+	uncheckedB.Set(42)
+	uncheckedD.Set(23)
+
+	want, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := gatherFunc(t, reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d metric families, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !proto.Equal(got[i], want[i]) {
+			t.Errorf("got %v, want %v", got[i], want[i])
+		}
+	}
+
+	// Errors returned by f stop gathering.
+	errStop := errors.New("stop")
+	calls := 0
+	err = reg.GatherFunc(func(*dto.MetricFamily) error {
+		calls++
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("got error %v, want %v", err, errStop)
+	}
+	if calls != 1 {
+		t.Errorf("got %d calls after error, want 1", calls)
+	}
+
+	// An inconsistent unchecked metric is reported, while all other
+	// metric families are still gathered.
+	reg.MustRegister(uncheckedCollector{c: NewGauge(GaugeOpts{Name: "b", Help: "B.", ConstLabels: Labels{"l": "0"}})})
+	got, err = gatherFunc(t, reg)
+	if err == nil {
+		t.Error("expected error for duplicate metric")
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d metric families, want %d", len(got), len(want))
+	}
+}
+
+func TestRegistryCollectorGroups(t *testing.T) {
+	reg := NewRegistry()
+	newCollector := func(names ...string) Collector {
+		var cs []Collector
+		for _, name := range names {
+			cs = append(cs, NewCounter(CounterOpts{Name: name, Help: "Help.", ConstLabels: Labels{"names": strconv.Itoa(len(cs)) + names[0]}}))
+		}
+		return collectors(cs)
+	}
+	reg.MustRegister(
+		newCollector("d", "b"),
+		newCollector("a"),
+		newCollector("b", "c"),
+		newCollector("e", "f"),
+	)
+
+	groups := reg.collectorGroups()
+	var got [][]string
+	for _, g := range groups {
+		got = append(got, g.names)
+	}
+	want := [][]string{{"a"}, {"b", "c", "d"}, {"e", "f"}}
+	if len(got) != len(want) {
+		t.Fatalf("got groups %v, want %v", got, want)
+	}
+	for i := range want {
+		if len(got[i]) != len(want[i]) {
+			t.Fatalf("got groups %v, want %v", got, want)
+		}
+		for j := range want[i] {
+			if got[i][j] != want[i][j] {
+				t.Fatalf("got groups %v, want %v", got, want)
+			}
+		}
+	}
+	if len(groups[1].collectors) != 2 {
+		t.Errorf("got %d collectors in group %v, want 2", len(groups[1].collectors), got[1])
+	}
+}
+
+// collectors is a Collector consisting of multiple Collectors.
+type collectors []Collector
+
+func (cs collectors) Describe(ch chan<- *Desc) {
+	for _, c := range cs {
+		c.Describe(ch)
+	}
+}
+
+func (cs collectors) Collect(ch chan<- Metric) {
+	for _, c := range cs {
+		c.Collect(ch)
+	}
+}
+
+func BenchmarkRegistryGatherFunc(b *testing.B) {
+	reg := NewRegistry()
+	for i := 0; i < 100; i++ {
+		vec := NewCounterVec(CounterOpts{Name: "requests_total_" + strconv.Itoa(i), Help: "Requests."}, []string{"id"})
+		for j := 0; j < 100; j++ {
+			vec.WithLabelValues(strconv.Itoa(j)).Inc()
+		}
+		reg.MustRegister(vec)
+	}
+
+	b.Run("Gather", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := reg.Gather(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("GatherFunc", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if err := reg.GatherFunc(func(*dto.MetricFamily) error { return nil }); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
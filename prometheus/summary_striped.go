@@ -0,0 +1,171 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"math"
+	"math/rand/v2"
+	"sort"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/beorn7/perks/quantile"
+)
+
+// stripedSummary is a Summary with objectives that spreads its observations
+// over several summaries, the stripes, each with its own quantile streams, to
+// avoid contention between goroutines observing at the same time. The head
+// streams of the stripes are merged when the Summary is collected. See
+// SummaryOpts.Stripes.
+type stripedSummary struct {
+	selfCollector
+	desc *Desc
+
+	stripes          []*summary
+	sortedObjectives []float64
+	labelPairs       []*dto.LabelPair
+	createdTs        *timestamppb.Timestamp
+}
+
+func newStripedSummary(desc *Desc, opts SummaryOpts, labelValues ...string) *stripedSummary {
+	stripes := opts.Stripes
+	opts.Stripes = 0
+	s := &stripedSummary{
+		desc:    desc,
+		stripes: make([]*summary, stripes),
+	}
+	for i := range s.stripes {
+		s.stripes[i] = newSummary(desc, opts, labelValues...).(*summary)
+	}
+	// All stripes have the same objectives and label pairs.
+	s.sortedObjectives = s.stripes[0].sortedObjectives
+	s.labelPairs = s.stripes[0].labelPairs
+	s.createdTs = s.stripes[0].createdTs
+	s.init(s) // Init self-collection.
+	return s
+}
+
+func (s *stripedSummary) Desc() *Desc {
+	return s.desc
+}
+
+// Observe adds v to a random stripe, see stripedHistogram.stripe.
+func (s *stripedSummary) Observe(v float64) {
+	s.stripes[rand.IntN(len(s.stripes))].Observe(v)
+}
+
+// stripeSample is a sample of the head stream of a stripe.
+type stripeSample struct {
+	quantile.Sample
+	stripe int
+}
+
+// Write merges the head streams of the stripes. The count and sum are exact.
+//
+// A quantile stream keeps samples with bounds of their ranks among the
+// observations of the stream: The rank of a sample is at least the sum of the
+// widths of the samples up to and including it (rMin), and at most rMin plus
+// its delta. The rank of any value is therefore also bounded by the samples of
+// the stream around it. Adding up these bounds over all stripes bounds the
+// rank of each sample among all observations, and each quantile is reported as
+// the sample whose rank bounds deviate least from the wanted rank.
+//
+// Around the rank of an objective, each stream keeps its samples close enough
+// that the rank bounds of a value are at most twice the objective's error
+// apart, relative to the observations of the stream. Added up, the bounds are
+// at most twice the error apart relative to all observations, which bounds
+// the error of the reported quantile. As the bounds of the stripes rarely
+// deviate in the same direction, the actual error usually stays within the
+// objective.
+func (s *stripedSummary) Write(out *dto.Metric) error {
+	var (
+		count   uint64
+		sum     float64
+		n       float64
+		stripes = make([]quantile.Samples, len(s.stripes))
+		samples []stripeSample
+	)
+	for i, stripe := range s.stripes {
+		stripeCount, stripeSum, stripeSamples := stripe.headSamples()
+		count += stripeCount
+		sum += stripeSum
+		stripes[i] = stripeSamples
+		for _, sample := range stripeSamples {
+			n += sample.Width
+			samples = append(samples, stripeSample{sample, i})
+		}
+	}
+	// A stable sort keeps the order of the samples of each stripe.
+	sort.SliceStable(samples, func(i, j int) bool {
+		return samples[i].Value < samples[j].Value
+	})
+
+	var (
+		ranks  = make([]float64, len(s.sortedObjectives))
+		errs   = make([]float64, len(s.sortedObjectives))
+		values = make([]float64, len(s.sortedObjectives))
+		// next holds the index of the next sample of each stripe to
+		// be swept.
+		next = make([]int, len(s.stripes))
+		// gap is by how much the rank of the current sample among the
+		// observations of all other stripes may exceed their added up
+		// rMin, as bounded by the next sample of each stripe.
+		gap  float64
+		rMin float64
+	)
+	for i, rank := range s.sortedObjectives {
+		ranks[i] = math.Ceil(rank * n)
+		errs[i] = math.Inf(1)
+		values[i] = math.NaN()
+	}
+	for _, stripe := range stripes {
+		if len(stripe) > 0 {
+			gap += stripe[0].Width + stripe[0].Delta - 1
+		}
+	}
+	for _, sample := range samples {
+		// sample is the next sample of its stripe.
+		stripe := stripes[sample.stripe]
+		gap -= sample.Width + sample.Delta - 1
+		rMin += sample.Width
+		rMax := rMin + sample.Delta + gap
+		for i, rank := range ranks {
+			if err := math.Max(rank-rMin, rMax-rank); err < errs[i] {
+				errs[i], values[i] = err, sample.Value
+			}
+		}
+		next[sample.stripe]++
+		if j := next[sample.stripe]; j < len(stripe) {
+			gap += stripe[j].Width + stripe[j].Delta - 1
+		}
+	}
+
+	qs := make([]*dto.Quantile, 0, len(s.sortedObjectives))
+	for i, rank := range s.sortedObjectives {
+		qs = append(qs, &dto.Quantile{
+			Quantile: proto.Float64(rank),
+			Value:    proto.Float64(values[i]),
+		})
+	}
+	out.Summary = &dto.Summary{
+		SampleCount:      proto.Uint64(count),
+		SampleSum:        proto.Float64(sum),
+		Quantile:         qs,
+		CreatedTimestamp: s.createdTs,
+	}
+	out.Label = s.labelPairs
+	return nil
+}
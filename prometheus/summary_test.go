@@ -16,6 +16,7 @@ package prometheus
 import (
 	"math"
 	"math/rand"
+	"reflect"
 	"sort"
 	"sync"
 	"testing"
@@ -407,6 +408,92 @@ func TestSummaryDecay(t *testing.T) {
 	}
 }
 
+func TestStripedSummary(t *testing.T) {
+	now := time.Now()
+	objMap := map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001}
+	opts := SummaryOpts{
+		Name:       "test_summary",
+		Help:       "helpless",
+		Objectives: objMap,
+		Stripes:    4,
+		now:        func() time.Time { return now },
+	}
+	sum := NewSummary(opts)
+	if _, ok := sum.(*stripedSummary); !ok {
+		t.Fatalf("got %T, want *stripedSummary", sum)
+	}
+
+	m := &dto.Metric{}
+	sum.Write(m)
+	for _, q := range m.Summary.Quantile {
+		if !math.IsNaN(q.GetValue()) {
+			t.Errorf("got %f for quantile %f without observations, want NaN", q.GetValue(), q.GetQuantile())
+		}
+	}
+
+	const concLevel, mutations = 8, 10000
+	var (
+		wg      sync.WaitGroup
+		allVars = make([]float64, 0, concLevel*mutations)
+	)
+	for i := 0; i < concLevel; i++ {
+		r := rand.New(rand.NewSource(int64(i)))
+		vals := make([]float64, mutations)
+		for j := range vals {
+			// Integer values keep the sum exact regardless of the
+			// order of additions.
+			vals[j] = float64(r.Intn(1e6))
+		}
+		allVars = append(allVars, vals...)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, v := range vals {
+				sum.Observe(v)
+			}
+		}()
+	}
+	wg.Wait()
+	sort.Float64s(allVars)
+	var sampleSum float64
+	for _, v := range allVars {
+		sampleSum += v
+	}
+
+	m.Reset()
+	sum.Write(m)
+	if got, want := m.Summary.GetSampleCount(), uint64(len(allVars)); got != want {
+		t.Errorf("got sample count %d, want %d", got, want)
+	}
+	if got, want := m.Summary.GetSampleSum(), sampleSum; got != want {
+		t.Errorf("got sample sum %f, want %f", got, want)
+	}
+	for i, wantQ := range []float64{0.5, 0.9, 0.99} {
+		gotQ := m.Summary.Quantile[i].GetQuantile()
+		gotV := m.Summary.Quantile[i].GetValue()
+		minBound, maxBound := getBounds(allVars, wantQ, objMap[wantQ])
+		if gotQ != wantQ {
+			t.Errorf("got quantile %f, want %f", gotQ, wantQ)
+		}
+		if gotV < minBound || gotV > maxBound {
+			t.Errorf("got %f for quantile %f, want [%f,%f]", gotV, gotQ, minBound, maxBound)
+		}
+	}
+
+	// Observations expire in all stripes.
+	now = now.Add(DefMaxAge + time.Second)
+	m.Reset()
+	sum.Write(m)
+	if got := m.Summary.Quantile[0].GetValue(); !math.IsNaN(got) {
+		t.Errorf("got %f, want NaN after expiration", got)
+	}
+
+	opts.Objectives = nil
+	if s := NewSummary(opts); reflect.TypeOf(s) != reflect.TypeOf(&noObjectivesSummary{}) {
+		t.Errorf("got %T without objectives, want *noObjectivesSummary", s)
+	}
+}
+
 func getBounds(vars []float64, q, ε float64) (minBound, maxBound float64) {
 	// TODO(beorn7): This currently tolerates an error of up to 2*ε. The
 	// error must be at most ε, but for some reason, it's sometimes slightly
@@ -0,0 +1,107 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"fmt"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// BenchmarkCollector benchmarks the Describe and Collect methods of the
+// provided Collector in the sub-benchmarks "Describe" and "Collect". The
+// Collect benchmark also writes all collected Metrics, like a gather does, and
+// reports the number of collected metrics per operation. Allocations are
+// always reported. Use it in a benchmark function of a custom Collector:
+//
+//	func BenchmarkMyCollector(b *testing.B) {
+//		testutil.BenchmarkCollector(b, newMyCollector())
+//	}
+func BenchmarkCollector(b *testing.B, c prometheus.Collector) {
+	b.Helper()
+	b.Run("Describe", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			describeAll(c)
+		}
+	})
+	b.Run("Collect", func(b *testing.B) {
+		b.ReportAllocs()
+		var n int
+		for b.Loop() {
+			var err error
+			if n, err = collectAndWriteAll(c); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(n), "metrics/op")
+	})
+}
+
+// CollectAndCheckAllocs measures the average number of allocations of
+// collecting and writing all Metrics of the provided Collector, like a gather
+// does, and returns an error if it exceeds maxAllocs. It can be used in a
+// regular test to keep a custom Collector from becoming more expensive
+// unnoticed. Note that the race detector and coverage instrumentation cause
+// additional allocations.
+func CollectAndCheckAllocs(c prometheus.Collector, maxAllocs float64) error {
+	var err error
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, writeErr := collectAndWriteAll(c); writeErr != nil {
+			err = writeErr
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if allocs > maxAllocs {
+		return fmt.Errorf("collecting allocated %g times per gather, exceeding the maximum of %g", allocs, maxAllocs)
+	}
+	return nil
+}
+
+func describeAll(c prometheus.Collector) {
+	ch := make(chan *prometheus.Desc, 16)
+	go func() {
+		c.Describe(ch)
+		close(ch)
+	}()
+	for range ch {
+	}
+}
+
+// collectAndWriteAll collects all Metrics of c, writes them, and returns their
+// number.
+func collectAndWriteAll(c prometheus.Collector) (int, error) {
+	ch := make(chan prometheus.Metric, 16)
+	go func() {
+		c.Collect(ch)
+		close(ch)
+	}()
+	var (
+		n   int
+		err error
+	)
+	for m := range ch {
+		n++
+		pb := &dto.Metric{}
+		if writeErr := m.Write(pb); writeErr != nil && err == nil {
+			err = fmt.Errorf("error happened while collecting metrics: %w", writeErr)
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,43 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func newBenchmarkVec() *prometheus.CounterVec {
+	c := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "requests_total", Help: "help"}, []string{"code"})
+	for i := range 10 {
+		c.WithLabelValues(fmt.Sprint(i)).Inc()
+	}
+	return c
+}
+
+func TestCollectAndCheckAllocs(t *testing.T) {
+	c := newBenchmarkVec()
+	if err := CollectAndCheckAllocs(c, 1e6); err != nil {
+		t.Error(err)
+	}
+	if err := CollectAndCheckAllocs(c, 1); err == nil {
+		t.Error("expected error for exceeded allocations")
+	}
+}
+
+func BenchmarkCounterVecCollector(b *testing.B) {
+	BenchmarkCollector(b, newBenchmarkVec())
+}
@@ -0,0 +1,67 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeClock is a prometheus.Clock that only advances when told to, so that
+// time-dependent behavior of metrics can be tested deterministically, e.g. the
+// decay of Summary observations after MaxAge:
+//
+//	clock := testutil.NewFakeClock(time.Unix(0, 0))
+//	s := prometheus.NewSummary(prometheus.SummaryOpts{
+//		Name:       "request_duration_seconds",
+//		Help:       "Duration of requests.",
+//		Objectives: map[float64]float64{0.5: 0.05},
+//		MaxAge:     time.Minute,
+//		Clock:      clock,
+//	})
+//	s.Observe(1)
+//	clock.Advance(2 * time.Minute)
+//	// The quantiles of s are NaN now.
+//
+// It is safe to use a FakeClock from multiple goroutines.
+type FakeClock struct {
+	mtx sync.Mutex
+	now time.Time
+}
+
+// NewFakeClock returns a FakeClock set to the provided time.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the current time of the clock.
+func (c *FakeClock) Now() time.Time {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Set sets the clock to the provided time.
+func (c *FakeClock) Set(now time.Time) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.now = now
+}
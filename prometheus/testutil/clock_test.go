@@ -0,0 +1,62 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Unix(1000, 0)
+	clock := NewFakeClock(start)
+
+	c := prometheus.NewCounter(prometheus.CounterOpts{Name: "requests_total", Help: "help", Clock: clock})
+	if got := collectOne(c).GetCounter().GetCreatedTimestamp().AsTime(); !got.Equal(start) {
+		t.Errorf("got created timestamp %v, want %v", got, start)
+	}
+	clock.Advance(time.Second)
+	c.(prometheus.ExemplarAdder).AddWithExemplar(1, prometheus.Labels{"trace_id": "abc"})
+	if got := ToExemplars(c)[0].Timestamp; !got.Equal(start.Add(time.Second)) {
+		t.Errorf("got exemplar timestamp %v, want %v", got, start.Add(time.Second))
+	}
+
+	s := prometheus.NewSummary(prometheus.SummaryOpts{
+		Name:       "request_duration_seconds",
+		Help:       "help",
+		Objectives: map[float64]float64{0.5: 0.05},
+		MaxAge:     time.Minute,
+		AgeBuckets: 2,
+		Clock:      clock,
+	})
+	s.Observe(1)
+	quantile := func() float64 {
+		return collectOne(s).GetSummary().GetQuantile()[0].GetValue()
+	}
+	if q := quantile(); q != 1 {
+		t.Errorf("got quantile %v, want 1", q)
+	}
+	clock.Advance(2 * time.Minute)
+	if q := quantile(); !math.IsNaN(q) {
+		t.Errorf("got quantile %v after MaxAge, want NaN", q)
+	}
+
+	clock.Set(start)
+	if got := clock.Now(); !got.Equal(start) {
+		t.Errorf("got time %v, want %v", got, start)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// GatherTimeoutError is returned by GatherWithTimeout and
+// CollectWithTimeout if gathering didn't finish in time. Its message contains
+// the stacks of all goroutines at the time of the timeout, which usually show
+// the lock a Collector is waiting for.
+type GatherTimeoutError struct {
+	// Timeout is the timeout that was exceeded.
+	Timeout time.Duration
+	// Stacks are the stacks of all goroutines, formatted like in a panic.
+	Stacks string
+}
+
+func (e *GatherTimeoutError) Error() string {
+	return fmt.Sprintf("gathering metrics did not finish within %v, possibly deadlocked; goroutines:\n%s", e.Timeout, e.Stacks)
+}
+
+// GatherWithTimeout gathers all metrics from the provided Gatherer and returns
+// a *GatherTimeoutError if that takes longer than timeout. It catches
+// Collectors that deadlock when Collect is called while the instrumented code
+// holds a lock the Collector needs as well, e.g. when the code under test
+// triggers a scrape. The goroutine gathering the metrics is leaked if it never
+// finishes.
+func GatherWithTimeout(g prometheus.Gatherer, timeout time.Duration) ([]*dto.MetricFamily, error) {
+	type result struct {
+		mfs []*dto.MetricFamily
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		mfs, err := g.Gather()
+		ch <- result{mfs: mfs, err: err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case r := <-ch:
+		if r.err != nil {
+			return r.mfs, fmt.Errorf("gathering metrics failed: %w", r.err)
+		}
+		return r.mfs, nil
+	case <-timer.C:
+		return nil, &GatherTimeoutError{Timeout: timeout, Stacks: allStacks()}
+	}
+}
+
+// CollectWithTimeout registers the provided Collector with a newly created
+// pedantic Registry and calls GatherWithTimeout with that Registry.
+func CollectWithTimeout(c prometheus.Collector, timeout time.Duration) ([]*dto.MetricFamily, error) {
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(c); err != nil {
+		return nil, fmt.Errorf("registering collector failed: %w", err)
+	}
+	return GatherWithTimeout(reg, timeout)
+}
+
+// allStacks returns the stacks of all goroutines.
+func allStacks() string {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return string(buf[:n])
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
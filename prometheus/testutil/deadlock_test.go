@@ -0,0 +1,64 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// lockingCollector collects a gauge while holding mu.
+type lockingCollector struct {
+	mu   *sync.Mutex
+	desc *prometheus.Desc
+}
+
+func (c lockingCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c lockingCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, 1)
+}
+
+func TestCollectWithTimeout(t *testing.T) {
+	var mu sync.Mutex
+	c := lockingCollector{mu: &mu, desc: prometheus.NewDesc("locked", "Locked.", nil, nil)}
+
+	mfs, err := CollectWithTimeout(c, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mfs) != 1 {
+		t.Errorf("got %d metric families, want 1", len(mfs))
+	}
+
+	mu.Lock()
+	_, err = CollectWithTimeout(c, 10*time.Millisecond)
+	var timeoutErr *GatherTimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected *GatherTimeoutError, got %v", err)
+	}
+	if !strings.Contains(timeoutErr.Stacks, "lockingCollector.Collect") {
+		t.Errorf("expected stacks to contain the blocked Collect, got:\n%s", timeoutErr.Stacks)
+	}
+	mu.Unlock()
+}
@@ -0,0 +1,231 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// SeriesDiff describes a series that differs between the actual and the
+// expected metrics.
+type SeriesDiff struct {
+	// Name is the name of the metric family of the series.
+	Name string
+	// Labels are the labels of the series.
+	Labels prometheus.Labels
+	// Before is the expected series, or nil if the series was not expected.
+	Before *dto.Metric
+	// After is the actual series, or nil if the series is missing.
+	After *dto.Metric
+}
+
+func (d SeriesDiff) String() string {
+	var sb strings.Builder
+	sb.WriteString(d.Name)
+	if len(d.Labels) > 0 {
+		names := make([]string, 0, len(d.Labels))
+		for name := range d.Labels {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		sb.WriteByte('{')
+		for i, name := range names {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			fmt.Fprintf(&sb, "%s=%q", name, d.Labels[name])
+		}
+		sb.WriteByte('}')
+	}
+	return sb.String()
+}
+
+// MetricsDiff is the structured result of comparing actual and expected
+// metrics. Series are identified by their metric name and labels and are
+// sorted by them.
+type MetricsDiff struct {
+	// Added are the series that are present but not expected.
+	Added []SeriesDiff
+	// Removed are the series that are expected but not present.
+	Removed []SeriesDiff
+	// Changed are the series whose values, exemplars, or timestamps differ.
+	Changed []SeriesDiff
+	// ChangedMetadata are the names of the metric families present in both
+	// but with different help or type.
+	ChangedMetadata []string
+}
+
+// Empty reports whether no differences were found.
+func (d *MetricsDiff) Empty() bool {
+	return d == nil || len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0 && len(d.ChangedMetadata) == 0
+}
+
+// String returns a summary of the differences with one line per series.
+func (d *MetricsDiff) String() string {
+	if d.Empty() {
+		return "no differences"
+	}
+	var sb strings.Builder
+	for _, s := range d.Added {
+		fmt.Fprintf(&sb, "+ %s: %s\n", s, formatValue(s.After))
+	}
+	for _, s := range d.Removed {
+		fmt.Fprintf(&sb, "- %s: %s\n", s, formatValue(s.Before))
+	}
+	for _, s := range d.Changed {
+		fmt.Fprintf(&sb, "~ %s: %s -> %s\n", s, formatValue(s.Before), formatValue(s.After))
+	}
+	for _, name := range d.ChangedMetadata {
+		fmt.Fprintf(&sb, "~ %s: help or type changed\n", name)
+	}
+	return sb.String()
+}
+
+// DiffError is the error returned by the comparison functions of this package
+// if the metrics don't match. Its message is the textual diff of both metrics
+// in the text exposition format, while Diff provides the differences for
+// further processing, e.g.:
+//
+//	var diffErr *testutil.DiffError
+//	if errors.As(err, &diffErr) {
+//		for _, s := range diffErr.Diff.Changed { ... }
+//	}
+type DiffError struct {
+	Diff *MetricsDiff
+	text string
+}
+
+func (e *DiffError) Error() string {
+	return e.text
+}
+
+// CompareMetrics compares the actual metric families got to the expected
+// metric families want, configured with the provided CompareOptions, and
+// returns their differences. The result is empty if they match.
+func CompareMetrics(got, want []*dto.MetricFamily, opts ...CompareOption) *MetricsDiff {
+	o := newCompareOptions(opts)
+	got = o.normalize(got)
+	want = o.normalize(want)
+	if o.absTolerance > 0 || o.relTolerance > 0 {
+		got = o.applyTolerance(got, want)
+	}
+	return diffMetrics(got, want)
+}
+
+type keyedMetric struct {
+	mf *dto.MetricFamily
+	m  *dto.Metric
+}
+
+func diffMetrics(got, want []*dto.MetricFamily) *MetricsDiff {
+	index := func(mfs []*dto.MetricFamily) (map[string]*dto.MetricFamily, map[string]keyedMetric) {
+		families := make(map[string]*dto.MetricFamily, len(mfs))
+		series := map[string]keyedMetric{}
+		for _, mf := range mfs {
+			families[mf.GetName()] = mf
+			for _, m := range mf.GetMetric() {
+				series[metricKey(mf, m)] = keyedMetric{mf: mf, m: m}
+			}
+		}
+		return families, series
+	}
+	gotFamilies, gotSeries := index(got)
+	wantFamilies, wantSeries := index(want)
+
+	d := &MetricsDiff{}
+	for key, g := range gotSeries {
+		w, ok := wantSeries[key]
+		switch {
+		case !ok:
+			d.Added = append(d.Added, newSeriesDiff(g.mf, nil, g.m))
+		case !equalMetrics(g.m, w.m):
+			d.Changed = append(d.Changed, newSeriesDiff(g.mf, w.m, g.m))
+		}
+	}
+	for key, w := range wantSeries {
+		if _, ok := gotSeries[key]; !ok {
+			d.Removed = append(d.Removed, newSeriesDiff(w.mf, w.m, nil))
+		}
+	}
+	for name, g := range gotFamilies {
+		if w, ok := wantFamilies[name]; ok && (g.GetHelp() != w.GetHelp() || g.GetType() != w.GetType()) {
+			d.ChangedMetadata = append(d.ChangedMetadata, name)
+		}
+	}
+
+	for _, s := range [][]SeriesDiff{d.Added, d.Removed, d.Changed} {
+		sort.Slice(s, func(i, j int) bool { return s[i].String() < s[j].String() })
+	}
+	sort.Strings(d.ChangedMetadata)
+	return d
+}
+
+// equalMetrics reports whether a and b are equal, ignoring the created
+// timestamps, which the text exposition format doesn't represent either.
+func equalMetrics(a, b *dto.Metric) bool {
+	a, b = proto.Clone(a).(*dto.Metric), proto.Clone(b).(*dto.Metric)
+	stripCreatedTimestamp(a)
+	stripCreatedTimestamp(b)
+	return proto.Equal(a, b)
+}
+
+func stripCreatedTimestamp(m *dto.Metric) {
+	if c := m.GetCounter(); c != nil {
+		c.CreatedTimestamp = nil
+	}
+	if h := m.GetHistogram(); h != nil {
+		h.CreatedTimestamp = nil
+	}
+	if s := m.GetSummary(); s != nil {
+		s.CreatedTimestamp = nil
+	}
+}
+
+func newSeriesDiff(mf *dto.MetricFamily, before, after *dto.Metric) SeriesDiff {
+	m := after
+	if m == nil {
+		m = before
+	}
+	labels := make(prometheus.Labels, len(m.GetLabel()))
+	for _, lp := range m.GetLabel() {
+		labels[lp.GetName()] = lp.GetValue()
+	}
+	return SeriesDiff{Name: mf.GetName(), Labels: labels, Before: before, After: after}
+}
+
+// formatValue returns a short representation of the value of m.
+func formatValue(m *dto.Metric) string {
+	switch {
+	case m.GetCounter() != nil:
+		return fmt.Sprint(m.GetCounter().GetValue())
+	case m.GetGauge() != nil:
+		return fmt.Sprint(m.GetGauge().GetValue())
+	case m.GetUntyped() != nil:
+		return fmt.Sprint(m.GetUntyped().GetValue())
+	case m.GetHistogram() != nil:
+		h := m.GetHistogram()
+		return fmt.Sprintf("count=%d sum=%g", h.GetSampleCount(), h.GetSampleSum())
+	case m.GetSummary() != nil:
+		s := m.GetSummary()
+		return fmt.Sprintf("count=%d sum=%g", s.GetSampleCount(), s.GetSampleSum())
+	}
+	return "<none>"
+}
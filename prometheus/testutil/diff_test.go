@@ -0,0 +1,93 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestCompareDiff(t *testing.T) {
+	c := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "requests_total", Help: "Requests."}, []string{"code"})
+	c.WithLabelValues("200").Add(3)
+	c.WithLabelValues("404").Add(1)
+	c.WithLabelValues("500").Add(2)
+
+	const expected = `
+# HELP requests_total Requests.
+# TYPE requests_total counter
+requests_total{code="200"} 3
+requests_total{code="404"} 2
+requests_total{code="503"} 1
+`
+	err := CollectAndCompare(c, strings.NewReader(expected))
+	var diffErr *DiffError
+	if !errors.As(err, &diffErr) {
+		t.Fatalf("expected *DiffError, got %v", err)
+	}
+	d := diffErr.Diff
+	if len(d.Added) != 1 || d.Added[0].Labels["code"] != "500" || d.Added[0].Before != nil {
+		t.Errorf("unexpected added series %v", d.Added)
+	}
+	if len(d.Removed) != 1 || d.Removed[0].Labels["code"] != "503" || d.Removed[0].After != nil {
+		t.Errorf("unexpected removed series %v", d.Removed)
+	}
+	if len(d.Changed) != 1 || d.Changed[0].Before.GetCounter().GetValue() != 2 || d.Changed[0].After.GetCounter().GetValue() != 1 {
+		t.Errorf("unexpected changed series %v", d.Changed)
+	}
+	if len(d.ChangedMetadata) != 0 {
+		t.Errorf("unexpected changed metadata %v", d.ChangedMetadata)
+	}
+
+	want := `+ requests_total{code="500"}: 2
+- requests_total{code="503"}: 1
+~ requests_total{code="404"}: 2 -> 1
+`
+	if got := d.String(); got != want {
+		t.Errorf("got summary:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCompareMetrics(t *testing.T) {
+	got, err := convertReaderToMetricFamily(strings.NewReader(`
+# HELP m Help.
+# TYPE m gauge
+m{a="1"} 1.05
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := convertReaderToMetricFamily(strings.NewReader(`
+# HELP m Other help.
+# TYPE m gauge
+m{a="1"} 1
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := CompareMetrics(got, want, WithAbsoluteTolerance(0.1))
+	if len(d.Changed) != 0 {
+		t.Errorf("unexpected changed series %v", d.Changed)
+	}
+	if len(d.ChangedMetadata) != 1 || d.ChangedMetadata[0] != "m" {
+		t.Errorf("got changed metadata %v, want [m]", d.ChangedMetadata)
+	}
+	if d := CompareMetrics(got, got); !d.Empty() {
+		t.Errorf("expected empty diff, got %s", d)
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Exemplar is an exemplar of a collected metric, see ToExemplars.
+type Exemplar struct {
+	Labels    prometheus.Labels
+	Value     float64
+	Timestamp time.Time
+}
+
+// ToExemplars collects all Metrics from the provided Collector. It expects that
+// this results in exactly one Metric being collected, which must be a Counter
+// or a Histogram. In all other cases, ToExemplars panics. ToExemplars returns
+// the exemplars of the Metric, i.e. the exemplar of a Counter, or the
+// exemplars of the classic buckets and the native buckets of a Histogram.
+//
+// Like ToFloat64, this function is only meant for testing.
+func ToExemplars(c prometheus.Collector) []Exemplar {
+	pb := collectOne(c)
+	if pb.Counter == nil && pb.Histogram == nil {
+		panic(fmt.Errorf("collected a non-counter/histogram metric: %s", pb))
+	}
+	return exemplarsOf(pb)
+}
+
+// CollectAndAssertExemplar collects all Metrics from the provided Collector
+// and returns an error unless at least one of their exemplars has all the
+// provided labels, e.g. "trace_id", and a value between minValue and maxValue
+// (inclusive). The values of the labels aren't checked, as they are usually
+// random IDs.
+func CollectAndAssertExemplar(c prometheus.Collector, labelNames []string, minValue, maxValue float64) error {
+	var (
+		mChan   = make(chan prometheus.Metric)
+		done    = make(chan struct{})
+		found   []string
+		matched bool
+		err     error
+	)
+	go func() {
+		defer close(done)
+		for m := range mChan {
+			pb := &dto.Metric{}
+			if writeErr := m.Write(pb); writeErr != nil {
+				err = fmt.Errorf("error happened while collecting metrics: %w", writeErr)
+				continue
+			}
+			for _, e := range exemplarsOf(pb) {
+				if e.Value >= minValue && e.Value <= maxValue && hasLabelNames(e.Labels, labelNames) {
+					matched = true
+				}
+				found = append(found, fmt.Sprintf("%v %g", e.Labels, e.Value))
+			}
+		}
+	}()
+	c.Collect(mChan)
+	close(mChan)
+	<-done
+
+	if err != nil {
+		return err
+	}
+	if matched {
+		return nil
+	}
+	if found == nil {
+		return fmt.Errorf("no exemplar with labels %q and a value in [%g, %g] found, got no exemplars", labelNames, minValue, maxValue)
+	}
+	return fmt.Errorf("no exemplar with labels %q and a value in [%g, %g] found, got: %s", labelNames, minValue, maxValue, strings.Join(found, ", "))
+}
+
+func exemplarsOf(m *dto.Metric) []Exemplar {
+	var exemplars []Exemplar
+	add := func(e *dto.Exemplar) {
+		if e == nil {
+			return
+		}
+		labels := make(prometheus.Labels, len(e.GetLabel()))
+		for _, lp := range e.GetLabel() {
+			labels[lp.GetName()] = lp.GetValue()
+		}
+		var ts time.Time
+		if e.Timestamp != nil {
+			ts = e.GetTimestamp().AsTime()
+		}
+		exemplars = append(exemplars, Exemplar{Labels: labels, Value: e.GetValue(), Timestamp: ts})
+	}
+	add(m.GetCounter().GetExemplar())
+	for _, b := range m.GetHistogram().GetBucket() {
+		add(b.GetExemplar())
+	}
+	for _, e := range m.GetHistogram().GetExemplars() {
+		add(e)
+	}
+	return exemplars
+}
+
+func hasLabelNames(labels prometheus.Labels, names []string) bool {
+	for _, name := range names {
+		if _, ok := labels[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
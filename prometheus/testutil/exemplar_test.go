@@ -0,0 +1,51 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestExemplars(t *testing.T) {
+	c := prometheus.NewCounter(prometheus.CounterOpts{Name: "requests_total", Help: "help"})
+	if err := CollectAndAssertExemplar(c, []string{"trace_id"}, 0, 10); err == nil {
+		t.Error("expected error for counter without exemplar")
+	}
+	c.(prometheus.ExemplarAdder).AddWithExemplar(2, prometheus.Labels{"trace_id": "abc"})
+	exemplars := ToExemplars(c)
+	if len(exemplars) != 1 || exemplars[0].Labels["trace_id"] != "abc" || exemplars[0].Value != 2 || exemplars[0].Timestamp.IsZero() {
+		t.Errorf("got exemplars %+v", exemplars)
+	}
+	if err := CollectAndAssertExemplar(c, []string{"trace_id"}, 1, 2); err != nil {
+		t.Error(err)
+	}
+	if err := CollectAndAssertExemplar(c, []string{"trace_id"}, 3, 10); err == nil {
+		t.Error("expected error for value out of range")
+	}
+
+	h := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "duration_seconds", Help: "help", Buckets: []float64{0.1, 1}}, []string{"code"})
+	h.WithLabelValues("200").(prometheus.ExemplarObserver).ObserveWithExemplar(0.5, prometheus.Labels{"trace_id": "def", "span_id": "1"})
+	h.WithLabelValues("200").Observe(0.05)
+	if err := CollectAndAssertExemplar(h, []string{"trace_id", "span_id"}, 0.2, 1); err != nil {
+		t.Error(err)
+	}
+	if err := CollectAndAssertExemplar(h, []string{"user_id"}, 0, 1); err == nil {
+		t.Error("expected error for missing label")
+	}
+	if got := ToExemplars(h); len(got) != 1 || got[0].Value != 0.5 {
+		t.Errorf("got exemplars %+v", got)
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/kylelemons/godebug/diff"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// scrapeAcceptHeaders are the Accept headers CheckScrapeFormats scrapes with,
+// besides the protobuf format used as reference.
+var scrapeAcceptHeaders = []string{
+	`text/plain;version=0.0.4`,
+	`application/openmetrics-text;version=0.0.1`,
+	`application/openmetrics-text;version=1.0.0`,
+	`application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited`,
+}
+
+// CheckScrapeFormats scrapes the provided handler, e.g. one created with
+// promhttp.HandlerFor, with the Accept headers of the text, OpenMetrics, and
+// protobuf formats, each with and without gzip compression, and verifies that
+// all responses are equivalent to the response in the protobuf format. It
+// returns the errors of all scrapes that failed or didn't match, joined with
+// errors.Join.
+//
+// A response matches if it is the protobuf response encoded in the format
+// (and escaping scheme) of the Content-Type of the response, so that handlers
+// not supporting a format may fall back to another one. OpenMetrics responses
+// may contain created lines or not.
+//
+// As the handler is scraped several times, the exposed metrics must not change
+// between scrapes, e.g. by using a Registry without the Go and process
+// collectors.
+func CheckScrapeFormats(h http.Handler) error {
+	reference, err := scrapeHandler(h, scrapeAcceptHeaders[len(scrapeAcceptHeaders)-1], "")
+	if err != nil {
+		return err
+	}
+	if format := expfmt.ResponseFormat(reference.header); format.FormatType() != expfmt.TypeProtoDelim {
+		return fmt.Errorf("handler responded with Content-Type %q instead of protobuf", reference.header.Get("Content-Type"))
+	}
+	var mfs []*dto.MetricFamily
+	dec := expfmt.NewDecoder(bytes.NewReader(reference.body), expfmt.NewFormat(expfmt.TypeProtoDelim))
+	for {
+		mf := &dto.MetricFamily{}
+		if err := dec.Decode(mf); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return fmt.Errorf("decoding protobuf response failed: %w", err)
+		}
+		mfs = append(mfs, mf)
+	}
+
+	var errs []error
+	for _, accept := range scrapeAcceptHeaders {
+		for _, encoding := range []string{"gzip", "identity"} {
+			resp, err := scrapeHandler(h, accept, encoding)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("Accept %q, Accept-Encoding %s: %w", accept, encoding, err))
+				continue
+			}
+			format := expfmt.Format(resp.header.Get("Content-Type"))
+			var (
+				want    []byte
+				matched bool
+			)
+			for _, opts := range [][]expfmt.EncoderOption{nil, {expfmt.WithCreatedLines()}} {
+				if want, err = encodeFormat(mfs, format, opts...); err != nil {
+					break
+				}
+				if bytes.Equal(resp.body, want) {
+					matched = true
+					break
+				}
+				if format.FormatType() != expfmt.TypeOpenMetrics {
+					break
+				}
+			}
+			switch {
+			case err != nil:
+				errs = append(errs, fmt.Errorf("Accept %q, Accept-Encoding %s: encoding reference failed: %w", accept, encoding, err))
+			case !matched:
+				errs = append(errs, fmt.Errorf("Accept %q, Accept-Encoding %s: response in format %q doesn't match protobuf response:\n%s",
+					accept, encoding, format, diff.Diff(string(resp.body), string(want))))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type scrapeResponse struct {
+	header http.Header
+	body   []byte
+}
+
+// scrapeHandler scrapes h with the Accept and Accept-Encoding headers and
+// returns the uncompressed response.
+func scrapeHandler(h http.Handler, accept, encoding string) (scrapeResponse, error) {
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Accept", accept)
+	if encoding != "" {
+		req.Header.Set("Accept-Encoding", encoding)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	resp := rec.Result()
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return scrapeResponse{}, fmt.Errorf("the handler returned a status code other than 200: %d", resp.StatusCode)
+	}
+	var body io.Reader = resp.Body
+	switch ce := resp.Header.Get("Content-Encoding"); ce {
+	case "gzip":
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return scrapeResponse{}, fmt.Errorf("decompressing response failed: %w", err)
+		}
+		defer gz.Close()
+		body = gz
+	case "", "identity":
+	default:
+		return scrapeResponse{}, fmt.Errorf("unexpected Content-Encoding %q", ce)
+	}
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return scrapeResponse{}, fmt.Errorf("reading response failed: %w", err)
+	}
+	return scrapeResponse{header: resp.Header, body: b}, nil
+}
+
+func encodeFormat(mfs []*dto.MetricFamily, format expfmt.Format, opts ...expfmt.EncoderOption) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := expfmt.NewEncoder(&buf, format, opts...)
+	for _, mf := range mfs {
+		if err := enc.Encode(mf); err != nil {
+			return nil, err
+		}
+	}
+	if closer, ok := enc.(expfmt.Closer); ok {
+		if err := closer.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
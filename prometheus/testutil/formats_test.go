@@ -0,0 +1,59 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+func TestCheckScrapeFormats(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "requests_total", Help: "Requests."}, []string{"code"})
+	latency := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "latency_seconds", Help: "Latency."})
+	reg.MustRegister(requests, latency)
+	requests.WithLabelValues("200").Add(3)
+	latency.Observe(0.2)
+
+	for _, opts := range []promhttp.HandlerOpts{
+		{},
+		{EnableOpenMetrics: true},
+		{EnableOpenMetrics: true, EnableOpenMetricsTextCreatedSamples: true},
+	} {
+		if err := CheckScrapeFormats(promhttp.HandlerFor(reg, opts)); err != nil {
+			t.Errorf("options %+v: %v", opts, err)
+		}
+	}
+
+	h := promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
+	broken := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Header.Get("Accept"), "text/plain") {
+			w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+			w.Write([]byte("requests_total{code=\"200\"} 4\n"))
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+	err := CheckScrapeFormats(broken)
+	if err == nil {
+		t.Fatal("expected error for mismatching text response")
+	}
+	if !strings.Contains(err.Error(), `Accept "text/plain;version=0.0.4"`) {
+		t.Errorf("expected error for text format, got %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// UpdateGoldenEnv is the environment variable that makes GatherAndCompareGolden
+// and CollectAndCompareGolden update the golden files if set to "1" or "true".
+const UpdateGoldenEnv = "PROMETHEUS_UPDATE_GOLDEN"
+
+// CollectAndCompareGolden is like GatherAndCompareGolden for the metrics of
+// the provided Collector.
+func CollectAndCompareGolden(c prometheus.Collector, goldenFile string, opts ...CompareOption) error {
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(c); err != nil {
+		return fmt.Errorf("registering collector failed: %w", err)
+	}
+	return GatherAndCompareGolden(reg, goldenFile, opts...)
+}
+
+// GatherAndCompareGolden gathers all metrics from the provided Gatherer and
+// compares them to the golden file, which contains the expected metrics in the
+// Prometheus text exposition format. The metrics are compared like with
+// GatherAndCompareWithOptions, i.e. sorted by name and labels.
+//
+// If the test binary was started with a boolean -update flag, which the test
+// has to define itself, or the environment variable named by UpdateGoldenEnv is
+// set to "1" or "true", the golden file is written with the gathered metrics
+// instead, after applying the WithMetricNames and WithoutTimestamps options.
+// Missing directories are created.
+func GatherAndCompareGolden(g prometheus.Gatherer, goldenFile string, opts ...CompareOption) error {
+	got, err := g.Gather()
+	if err != nil {
+		return fmt.Errorf("gathering metrics failed: %w", err)
+	}
+	o := newCompareOptions(opts)
+
+	if updateGolden() {
+		var buf bytes.Buffer
+		enc := expfmt.NewEncoder(&buf, expfmt.NewFormat(expfmt.TypeTextPlain).WithEscapingScheme(model.NoEscaping))
+		for _, mf := range o.normalize(got) {
+			if err := enc.Encode(mf); err != nil {
+				return fmt.Errorf("encoding gathered metrics failed: %w", err)
+			}
+		}
+		if err := os.MkdirAll(filepath.Dir(goldenFile), 0o755); err != nil {
+			return fmt.Errorf("updating golden file failed: %w", err)
+		}
+		if err := os.WriteFile(goldenFile, buf.Bytes(), 0o644); err != nil {
+			return fmt.Errorf("updating golden file failed: %w", err)
+		}
+		return nil
+	}
+
+	f, err := os.Open(goldenFile)
+	if err != nil {
+		return fmt.Errorf("reading golden file failed: %w", err)
+	}
+	defer f.Close()
+	wanted, err := convertReaderToMetricFamily(f)
+	if err != nil {
+		return err
+	}
+	return o.compare(got, wanted)
+}
+
+// updateGolden reports whether golden files should be updated.
+func updateGolden() bool {
+	if update, _ := strconv.ParseBool(os.Getenv(UpdateGoldenEnv)); update {
+		return true
+	}
+	if f := flag.Lookup("update"); f != nil {
+		update, _ := strconv.ParseBool(f.Value.String())
+		return update
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// timestampCollector collects a metric with the current time as timestamp.
+type timestampCollector struct{}
+
+var lastUpdateDesc = prometheus.NewDesc("last_update", "Time of the last update.", nil, nil)
+
+func (timestampCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- lastUpdateDesc
+}
+
+func (timestampCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.NewMetricWithTimestamp(time.Now(), prometheus.MustNewConstMetric(lastUpdateDesc, prometheus.GaugeValue, 42))
+}
+
+func TestGatherAndCompareGolden(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	g := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "some_gauge", Help: "A gauge."}, []string{"b", "a"})
+	g.WithLabelValues("2", "1").Set(1)
+	g.WithLabelValues("1", "2").Set(2)
+	reg.MustRegister(g)
+	reg.MustRegister(timestampCollector{})
+
+	golden := filepath.Join(t.TempDir(), "testdata", "metrics.golden")
+	if err := GatherAndCompareGolden(reg, golden); err == nil {
+		t.Error("expected error for missing golden file")
+	}
+
+	t.Setenv(UpdateGoldenEnv, "true")
+	if err := GatherAndCompareGolden(reg, golden, WithoutTimestamps()); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP last_update Time of the last update.
+# TYPE last_update gauge
+last_update 42
+# HELP some_gauge A gauge.
+# TYPE some_gauge gauge
+some_gauge{a="1",b="2"} 1
+some_gauge{a="2",b="1"} 2
+`
+	if string(b) != want {
+		t.Errorf("got golden file\n%s\nwant\n%s", b, want)
+	}
+
+	t.Setenv(UpdateGoldenEnv, "")
+	if err := GatherAndCompareGolden(reg, golden, WithoutTimestamps()); err != nil {
+		t.Errorf("unexpected comparison result:\n%s", err)
+	}
+	if err := GatherAndCompareGolden(reg, golden); err == nil {
+		t.Error("expected error for differing timestamps")
+	}
+	g.WithLabelValues("2", "1").Set(3)
+	if err := GatherAndCompareGolden(reg, golden, WithoutTimestamps()); err == nil {
+		t.Error("expected error for changed value")
+	}
+}
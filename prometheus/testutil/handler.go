@@ -0,0 +1,140 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+)
+
+// SyntheticRequest describes a request sent by DriveHandler and the response
+// of the handler it is passed to.
+type SyntheticRequest struct {
+	// Method is the request method, GET if empty.
+	Method string
+	// Path is the request path, "/" if empty.
+	Path string
+	// Header is added to the request.
+	Header http.Header
+	// RequestSize is the size of the request body in bytes.
+	RequestSize int
+
+	// StatusCode is the status code of the response. If zero, the handler
+	// doesn't call WriteHeader, so that the status code is implicitly 200.
+	StatusCode int
+	// ResponseSize is the size of the response body in bytes.
+	ResponseSize int
+	// Delay is the time the handler sleeps before responding.
+	Delay time.Duration
+	// Hijack makes the handler hijack the connection and close it instead of
+	// responding.
+	Hijack bool
+}
+
+// DriveHandler sends the provided synthetic requests to a handler responding as
+// described by each SyntheticRequest, wrapped with the provided instrument
+// function, and returns the errors of requests that couldn't be served. It
+// makes it cheap to test how handlers are instrumented with the
+// promhttp.InstrumentHandler* middlewares, e.g.
+//
+//	err := testutil.DriveHandler(func(next http.Handler) http.Handler {
+//		return promhttp.InstrumentHandlerCounter(requests, next)
+//	}, testutil.SyntheticRequest{Method: http.MethodPost, StatusCode: 404})
+//
+// The resulting metric values can then be asserted with the other functions
+// of this package, e.g. ToFloat64WithLabels or Snapshot.AssertDelta.
+//
+// The requests are served sequentially and without a network connection. The
+// response writer implements http.Flusher and http.Hijacker, so that the
+// middlewares take the same code paths as with a real server.
+func DriveHandler(instrument func(http.Handler) http.Handler, requests ...SyntheticRequest) error {
+	var errs []error
+	for i, r := range requests {
+		if err := driveRequest(instrument, r); err != nil {
+			errs = append(errs, fmt.Errorf("request %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func driveRequest(instrument func(http.Handler) http.Handler, r SyntheticRequest) error {
+	method, path := r.Method, r.Path
+	if method == "" {
+		method = http.MethodGet
+	}
+	if path == "" {
+		path = "/"
+	}
+	req := httptest.NewRequest(method, path, strings.NewReader(strings.Repeat("x", r.RequestSize)))
+	for name, values := range r.Header {
+		req.Header[name] = values
+	}
+
+	var handlerErr error
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if r.Delay > 0 {
+			time.Sleep(r.Delay)
+		}
+		if r.Hijack {
+			hj, ok := w.(http.Hijacker)
+			if !ok {
+				handlerErr = errors.New("response writer doesn't support hijacking")
+				return
+			}
+			conn, _, err := hj.Hijack()
+			if err != nil {
+				handlerErr = fmt.Errorf("hijacking failed: %w", err)
+				return
+			}
+			conn.Close()
+			return
+		}
+		if r.StatusCode != 0 {
+			w.WriteHeader(r.StatusCode)
+		}
+		if r.ResponseSize > 0 {
+			if _, err := w.Write(bytes.Repeat([]byte("x"), r.ResponseSize)); err != nil {
+				handlerErr = fmt.Errorf("writing response failed: %w", err)
+			}
+		}
+	})
+
+	w := &hijackableRecorder{ResponseRecorder: httptest.NewRecorder()}
+	instrument(next).ServeHTTP(w, req)
+	return handlerErr
+}
+
+// hijackableRecorder is a ResponseRecorder that supports hijacking with a
+// connection whose other end is closed.
+type hijackableRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (r *hijackableRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if r.hijacked {
+		return nil, nil, http.ErrHijacked
+	}
+	r.hijacked = true
+	server, client := net.Pipe()
+	client.Close()
+	return server, bufio.NewReadWriter(bufio.NewReader(server), bufio.NewWriter(server)), nil
+}
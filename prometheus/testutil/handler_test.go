@@ -0,0 +1,67 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+func TestDriveHandler(t *testing.T) {
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "requests_total"}, []string{"code", "method"})
+	sizes := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "response_size_bytes", Buckets: []float64{100}}, []string{})
+	durations := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "request_duration_seconds"}, []string{})
+	instrument := func(next http.Handler) http.Handler {
+		return promhttp.InstrumentHandlerCounter(requests,
+			promhttp.InstrumentHandlerResponseSize(sizes,
+				promhttp.InstrumentHandlerDuration(durations, next)))
+	}
+
+	err := DriveHandler(instrument,
+		SyntheticRequest{ResponseSize: 10},
+		SyntheticRequest{Method: http.MethodPost, StatusCode: http.StatusNotFound, ResponseSize: 200},
+		SyntheticRequest{Method: http.MethodPost, StatusCode: http.StatusNotFound, Delay: 10 * time.Millisecond},
+		SyntheticRequest{Hijack: true},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := ToFloat64WithLabels(requests, prometheus.Labels{"code": "200", "method": "get"}); got != 2 {
+		t.Errorf("got %g GET requests with code 200, want 2", got)
+	}
+	if got := ToFloat64WithLabels(requests, prometheus.Labels{"code": "404", "method": "post"}); got != 2 {
+		t.Errorf("got %g POST requests with code 404, want 2", got)
+	}
+	if s := ToHistogram(sizes); s.Count != 4 || s.Sum != 210 {
+		t.Errorf("got response sizes with count %d and sum %g, want 4 and 210", s.Count, s.Sum)
+	}
+	if s := ToHistogram(durations); s.Count != 4 || s.Sum < 0.01 {
+		t.Errorf("got durations with count %d and sum %g, want 4 and at least 0.01", s.Count, s.Sum)
+	}
+
+	err = DriveHandler(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Hide the Hijacker interface.
+			next.ServeHTTP(struct{ http.ResponseWriter }{w}, r)
+		})
+	}, SyntheticRequest{Hijack: true})
+	if err == nil {
+		t.Error("expected error for unsupported hijacking")
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// HistogramSnapshot is the state of a histogram at the time it was collected
+// with ToHistogram. It allows assertions that don't depend on the bucket
+// layout of the histogram, so that tests keep working if the buckets are tuned
+// or the histogram is migrated to native buckets.
+type HistogramSnapshot struct {
+	// Count is the number of observations.
+	Count uint64
+	// Sum is the sum of all observations.
+	Sum float64
+
+	buckets []snapshotBucket
+}
+
+type snapshotBucket struct {
+	lower, upper float64
+	count        float64
+}
+
+// ToHistogram collects all Metrics from the provided Collector. It expects that
+// this results in exactly one Metric being collected, which must be a
+// Histogram. In all other cases, ToHistogram panics. The Collector provided is
+// typically a Histogram or a HistogramVec with exactly one element, see
+// HistogramVec.WithLabelValues.
+//
+// If the histogram has native buckets, they are used for Quantile, otherwise
+// the classic buckets are used.
+//
+// Like ToFloat64, this function is only meant for testing.
+func ToHistogram(c prometheus.Collector) HistogramSnapshot {
+	pb := collectOne(c)
+	h := pb.GetHistogram()
+	if h == nil {
+		panic(fmt.Errorf("collected a non-histogram metric: %s", pb))
+	}
+	s := HistogramSnapshot{Count: h.GetSampleCount(), Sum: h.GetSampleSum()}
+	if h.Schema != nil {
+		s.buckets = nativeBuckets(h)
+	} else {
+		s.buckets = classicBuckets(h)
+	}
+	return s
+}
+
+// Quantile returns an estimate of the φ-quantile of the observations, with
+// 0 ≤ φ ≤ 1, like the PromQL function histogram_quantile, by linear
+// interpolation within the bucket containing the quantile. The precision of
+// the estimate depends on the bucket layout, so assertions should allow for an
+// error of the size of the buckets.
+//
+// Quantile returns NaN if there are no observations, -Inf for φ < 0, and +Inf
+// for φ > 1. If the quantile falls into the +Inf bucket of a classic
+// histogram, the upper bound of the highest finite bucket is returned.
+func (s HistogramSnapshot) Quantile(φ float64) float64 {
+	switch {
+	case φ < 0:
+		return math.Inf(-1)
+	case φ > 1:
+		return math.Inf(1)
+	}
+	var total float64
+	for _, b := range s.buckets {
+		total += b.count
+	}
+	if total == 0 {
+		return math.NaN()
+	}
+
+	rank := φ * total
+	var cum float64
+	for _, b := range s.buckets {
+		if b.count == 0 || cum+b.count < rank {
+			cum += b.count
+			continue
+		}
+		if math.IsInf(b.upper, 1) {
+			return b.lower
+		}
+		if math.IsInf(b.lower, -1) {
+			return b.upper
+		}
+		return b.lower + (b.upper-b.lower)*(rank-cum)/b.count
+	}
+	return s.buckets[len(s.buckets)-1].upper
+}
+
+// classicBuckets converts the cumulative classic buckets of h into buckets
+// with lower bounds. As in PromQL, the lowest bucket is assumed to start at
+// zero if its upper bound is positive.
+func classicBuckets(h *dto.Histogram) []snapshotBucket {
+	var (
+		buckets []snapshotBucket
+		lower   = math.Inf(-1)
+		prev    float64
+	)
+	for i, b := range h.GetBucket() {
+		upper := b.GetUpperBound()
+		count := float64(b.GetCumulativeCount())
+		if b.CumulativeCountFloat != nil {
+			count = b.GetCumulativeCountFloat()
+		}
+		if i == 0 && upper > 0 {
+			lower = 0
+		}
+		buckets = append(buckets, snapshotBucket{lower: lower, upper: upper, count: count - prev})
+		lower, prev = upper, count
+	}
+	if sampleCount := float64(h.GetSampleCount()); !math.IsInf(lower, 1) && sampleCount > prev {
+		// The +Inf bucket is implicit in the exposition.
+		buckets = append(buckets, snapshotBucket{lower: lower, upper: math.Inf(1), count: sampleCount - prev})
+	}
+	return buckets
+}
+
+// nativeBuckets returns the zero bucket and the positive and negative native
+// buckets of h, sorted by their bounds.
+func nativeBuckets(h *dto.Histogram) []snapshotBucket {
+	var buckets []snapshotBucket
+	if zc := float64(h.GetZeroCount()); zc > 0 {
+		buckets = append(buckets, snapshotBucket{lower: -h.GetZeroThreshold(), upper: h.GetZeroThreshold(), count: zc})
+	}
+	schema := h.GetSchema()
+	addSpans := func(spans []*dto.BucketSpan, deltas []int64, negative bool) {
+		var (
+			idx   int32
+			count int64
+			d     int
+		)
+		for i, span := range spans {
+			if i == 0 {
+				idx = span.GetOffset()
+			} else {
+				idx += span.GetOffset()
+			}
+			for range span.GetLength() {
+				if d < len(deltas) {
+					count += deltas[d]
+					d++
+				}
+				lower, upper := nativeBucketBounds(schema, idx)
+				if negative {
+					lower, upper = -upper, -lower
+				}
+				buckets = append(buckets, snapshotBucket{lower: lower, upper: upper, count: float64(count)})
+				idx++
+			}
+		}
+	}
+	addSpans(h.GetPositiveSpan(), h.GetPositiveDelta(), false)
+	addSpans(h.GetNegativeSpan(), h.GetNegativeDelta(), true)
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].upper < buckets[j].upper })
+	return buckets
+}
+
+// nativeBucketBounds returns the bounds of the native bucket with the index
+// in the schema. The upper bound of bucket i is 2^(i·2^-schema).
+func nativeBucketBounds(schema, idx int32) (lower, upper float64) {
+	upper = math.Exp2(float64(idx) * math.Exp2(-float64(schema)))
+	lower = math.Exp2(float64(idx-1) * math.Exp2(-float64(schema)))
+	return lower, upper
+}
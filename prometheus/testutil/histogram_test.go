@@ -0,0 +1,65 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"math"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestToHistogram(t *testing.T) {
+	for name, opts := range map[string]prometheus.HistogramOpts{
+		"classic":                     {Name: "h", Help: "help", Buckets: prometheus.LinearBuckets(10, 10, 10)},
+		"coarse":                      {Name: "h", Help: "help", Buckets: []float64{25, 50, 75, 100}},
+		"native":                      {Name: "h", Help: "help", NativeHistogramBucketFactor: 1.01},
+		"native with negative values": {Name: "h", Help: "help", NativeHistogramBucketFactor: 1.01},
+	} {
+		t.Run(name, func(t *testing.T) {
+			h := prometheus.NewHistogram(opts)
+			offset := 0.0
+			if name == "native with negative values" {
+				offset = -50
+			}
+			for i := 1; i <= 100; i++ {
+				h.Observe(float64(i) + offset)
+			}
+			s := ToHistogram(h)
+			if s.Count != 100 {
+				t.Errorf("got count %d, want 100", s.Count)
+			}
+			if want := 5050 + 100*offset; s.Sum != want {
+				t.Errorf("got sum %v, want %v", s.Sum, want)
+			}
+			for _, tc := range []struct{ q, want float64 }{
+				{0.5, 50}, {0.9, 90}, {0.99, 99},
+			} {
+				want := tc.want + offset
+				if got := s.Quantile(tc.q); math.Abs(got-want) > 2 {
+					t.Errorf("got quantile %v = %v, want %v", tc.q, got, want)
+				}
+			}
+		})
+	}
+
+	h := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "h", Help: "help", Buckets: []float64{1}})
+	if q := ToHistogram(h).Quantile(0.5); !math.IsNaN(q) {
+		t.Errorf("got quantile %v without observations, want NaN", q)
+	}
+	h.Observe(5)
+	if q := ToHistogram(h).Quantile(0.5); q != 1 {
+		t.Errorf("got quantile %v in +Inf bucket, want 1", q)
+	}
+}
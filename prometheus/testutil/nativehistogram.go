@@ -0,0 +1,170 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kylelemons/godebug/diff"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/internal"
+)
+
+// NativeHistogram is the state of a native histogram at the time it was
+// collected with ToNativeHistogram. The buckets are decoded from the spans and
+// deltas of the exposition and keyed by their index, so that the upper bound
+// of bucket i is 2^(i·2^-Schema) for positive buckets, and the lower bound of
+// bucket i is -2^(i·2^-Schema) for negative buckets.
+type NativeHistogram struct {
+	// Count is the number of observations.
+	Count uint64
+	// Sum is the sum of all observations.
+	Sum float64
+	// Schema is the resolution of the buckets.
+	Schema int32
+	// ZeroThreshold is the width of the zero bucket.
+	ZeroThreshold float64
+	// ZeroCount is the number of observations in the zero bucket.
+	ZeroCount uint64
+	// PositiveBuckets are the counts of the populated positive buckets.
+	PositiveBuckets map[int32]uint64
+	// NegativeBuckets are the counts of the populated negative buckets.
+	NegativeBuckets map[int32]uint64
+}
+
+// ToNativeHistogram collects all Metrics from the provided Collector. It
+// expects that this results in exactly one Metric being collected, which must
+// be a Histogram with native buckets, i.e. one created with
+// NativeHistogramBucketFactor set. In all other cases, ToNativeHistogram
+// panics.
+//
+// Like ToFloat64, this function is only meant for testing.
+func ToNativeHistogram(c prometheus.Collector) NativeHistogram {
+	pb := collectOne(c)
+	h := pb.GetHistogram()
+	if h == nil || h.Schema == nil {
+		panic(fmt.Errorf("collected a non-native-histogram metric: %s", pb))
+	}
+	return NativeHistogram{
+		Count:           h.GetSampleCount(),
+		Sum:             h.GetSampleSum(),
+		Schema:          h.GetSchema(),
+		ZeroThreshold:   h.GetZeroThreshold(),
+		ZeroCount:       h.GetZeroCount(),
+		PositiveBuckets: decodeSpans(h.GetPositiveSpan(), h.GetPositiveDelta()),
+		NegativeBuckets: decodeSpans(h.GetNegativeSpan(), h.GetNegativeDelta()),
+	}
+}
+
+// decodeSpans returns the counts of the populated buckets described by the
+// spans and deltas, keyed by the bucket index.
+func decodeSpans(spans []*dto.BucketSpan, deltas []int64) map[int32]uint64 {
+	buckets := map[int32]uint64{}
+	var (
+		idx   int32
+		count int64
+		d     int
+	)
+	for i, span := range spans {
+		if i == 0 {
+			idx = span.GetOffset()
+		} else {
+			idx += span.GetOffset()
+		}
+		for range span.GetLength() {
+			if d < len(deltas) {
+				count += deltas[d]
+				d++
+			}
+			if count != 0 {
+				buckets[idx] = uint64(count)
+			}
+			idx++
+		}
+	}
+	return buckets
+}
+
+// CollectAndCompareProto registers the provided Collector with a newly created
+// pedantic Registry and calls GatherAndCompareProto with that Registry.
+func CollectAndCompareProto(c prometheus.Collector, expected []*dto.MetricFamily, opts ...CompareOption) error {
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(c); err != nil {
+		return fmt.Errorf("registering collector failed: %w", err)
+	}
+	return GatherAndCompareProto(reg, expected, opts...)
+}
+
+// GatherAndCompareProto gathers all metrics from the provided Gatherer and
+// compares them to the expected metric families, configured with the provided
+// CompareOptions. Unlike GatherAndCompare, the comparison is based on the
+// protobuf representation, so that everything the text format cannot express,
+// e.g. the schema, zero threshold, and buckets of native histograms, is
+// compared as well. Created timestamps are ignored. The expected metric
+// families don't need to be sorted.
+//
+// If the metrics don't match, a *DiffError is returned, whose message contains
+// a diff of both metrics in the protobuf text format.
+func GatherAndCompareProto(g prometheus.Gatherer, expected []*dto.MetricFamily, opts ...CompareOption) error {
+	got, err := g.Gather()
+	if err != nil {
+		return fmt.Errorf("gathering metrics failed: %w", err)
+	}
+	want := make(map[string]*dto.MetricFamily, len(expected))
+	for _, mf := range expected {
+		want[mf.GetName()] = proto.Clone(mf).(*dto.MetricFamily)
+	}
+
+	o := newCompareOptions(opts)
+	got = o.normalize(withoutCreatedTimestamps(got))
+	wanted := o.normalize(withoutCreatedTimestamps(internal.NormalizeMetricFamilies(want)))
+	if o.absTolerance > 0 || o.relTolerance > 0 {
+		got = o.applyTolerance(got, wanted)
+	}
+
+	gotText, wantText := protoText(got), protoText(wanted)
+	if diffErr := diff.Diff(gotText, wantText); diffErr != "" {
+		return &DiffError{Diff: diffMetrics(got, wanted), text: diffErr}
+	}
+	return nil
+}
+
+// withoutCreatedTimestamps returns copies of the metric families without the
+// created timestamps of their counters, histograms, and summaries.
+func withoutCreatedTimestamps(mfs []*dto.MetricFamily) []*dto.MetricFamily {
+	result := make([]*dto.MetricFamily, 0, len(mfs))
+	for _, mf := range mfs {
+		mf = proto.Clone(mf).(*dto.MetricFamily)
+		for _, m := range mf.GetMetric() {
+			stripCreatedTimestamp(m)
+		}
+		result = append(result, mf)
+	}
+	return result
+}
+
+func protoText(mfs []*dto.MetricFamily) string {
+	var sb strings.Builder
+	opts := prototext.MarshalOptions{Multiline: true}
+	for _, mf := range mfs {
+		sb.WriteString(opts.Format(mf))
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
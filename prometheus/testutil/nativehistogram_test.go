@@ -0,0 +1,97 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/encoding/prototext"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func newNativeHistogram() prometheus.Histogram {
+	h := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:                        "latency_seconds",
+		Help:                        "Latency.",
+		NativeHistogramBucketFactor: 1.1,
+	})
+	for _, v := range []float64{0, 1, 1, 2, -1} {
+		h.Observe(v)
+	}
+	return h
+}
+
+func TestToNativeHistogram(t *testing.T) {
+	got := ToNativeHistogram(newNativeHistogram())
+	want := NativeHistogram{
+		Count:           5,
+		Sum:             3,
+		Schema:          3,
+		ZeroThreshold:   prometheus.DefNativeHistogramZeroThreshold,
+		ZeroCount:       1,
+		PositiveBuckets: map[int32]uint64{0: 2, 8: 1},
+		NegativeBuckets: map[int32]uint64{0: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for classic histogram")
+		}
+	}()
+	ToNativeHistogram(prometheus.NewHistogram(prometheus.HistogramOpts{Name: "classic"}))
+}
+
+func TestCollectAndCompareProto(t *testing.T) {
+	var mf dto.MetricFamily
+	err := prototext.Unmarshal([]byte(`
+		name: "latency_seconds"
+		help: "Latency."
+		type: HISTOGRAM
+		metric: {
+			histogram: {
+				sample_count: 5
+				sample_sum: 3
+				schema: 3
+				zero_threshold: 2.938735877055719e-39
+				zero_count: 1
+				positive_span: {offset: 0 length: 1}
+				positive_span: {offset: 7 length: 1}
+				positive_delta: [2, -1]
+				negative_span: {offset: 0 length: 1}
+				negative_delta: [1]
+			}
+		}
+	`), &mf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newNativeHistogram()
+	if err := CollectAndCompareProto(h, []*dto.MetricFamily{&mf}); err != nil {
+		t.Error(err)
+	}
+
+	mf.Metric[0].Histogram.Schema = nil
+	err = CollectAndCompareProto(h, []*dto.MetricFamily{&mf})
+	var diffErr *DiffError
+	if !errors.As(err, &diffErr) || len(diffErr.Diff.Changed) != 1 {
+		t.Errorf("expected diff with one changed series, got %v", err)
+	}
+}
@@ -13,7 +13,18 @@
 
 package promlint
 
-import dto "github.com/prometheus/client_model/go"
+import (
+	"errors"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/prometheus/client_golang/prometheus/testutil/promlint/validations"
+)
+
+// A Fix is a machine-readable suggestion to resolve a Problem, see
+// validations.Fix. Validations suggest fixes by returning a
+// *validations.FixError.
+type Fix = validations.Fix
 
 // A Problem is an issue detected by a linter.
 type Problem struct {
@@ -22,6 +33,9 @@ type Problem struct {
 
 	// A description of the issue for this Problem.
 	Text string
+
+	// A suggested fix for this Problem, if any.
+	Fix *Fix
 }
 
 // newProblem is helper function to create a Problem.
@@ -31,3 +45,15 @@ func newProblem(mf *dto.MetricFamily, text string) Problem {
 		Text:   text,
 	}
 }
+
+// newProblemFromError creates a Problem from an error returned by a
+// validation, including the fix suggested by a *validations.FixError.
+func newProblemFromError(mf *dto.MetricFamily, err error) Problem {
+	p := newProblem(mf, err.Error())
+	var fixErr *validations.FixError
+	if errors.As(err, &fixErr) {
+		fix := fixErr.Fix
+		p.Fix = &fix
+	}
+	return p
+}
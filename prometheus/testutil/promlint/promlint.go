@@ -35,6 +35,8 @@ type Linter struct {
 	mfs []*dto.MetricFamily
 
 	customValidations []Validation
+	rules             []Rule
+	disabled, enabled map[string]bool
 }
 
 // New creates a new Linter that reads an input stream of Prometheus metrics in
@@ -61,11 +63,65 @@ func (l *Linter) AddCustomValidations(vs ...Validation) {
 	l.customValidations = append(l.customValidations, vs...)
 }
 
+// AddRules adds rules to the linter, in addition to the built-in and the
+// registered rules. Unlike custom validations, rules can be disabled by name.
+// AddRules returns an error if a rule is invalid.
+func (l *Linter) AddRules(rules ...Rule) error {
+	for _, r := range rules {
+		if err := r.validate(); err != nil {
+			return err
+		}
+	}
+	l.rules = append(l.rules, rules...)
+	return nil
+}
+
+// DisableRules disables the rules with the provided names, see Rules for the
+// built-in and registered rules.
+func (l *Linter) DisableRules(names ...string) {
+	if l.disabled == nil {
+		l.disabled = map[string]bool{}
+	}
+	for _, name := range names {
+		l.disabled[name] = true
+		delete(l.enabled, name)
+	}
+}
+
+// EnableRules enables the rules with the provided names, i.e. opt-in rules or
+// rules disabled before with DisableRules.
+func (l *Linter) EnableRules(names ...string) {
+	if l.enabled == nil {
+		l.enabled = map[string]bool{}
+	}
+	for _, name := range names {
+		l.enabled[name] = true
+		delete(l.disabled, name)
+	}
+}
+
+// activeRules returns the rules the linter runs.
+func (l *Linter) activeRules() []Rule {
+	registeredRulesMtx.RLock()
+	all := append(append(append([]Rule(nil), defaultRules...), registeredRules...), l.rules...)
+	registeredRulesMtx.RUnlock()
+
+	active := all[:0]
+	for _, r := range all {
+		if l.disabled[r.Name] || (r.OptIn && !l.enabled[r.Name]) {
+			continue
+		}
+		active = append(active, r)
+	}
+	return active
+}
+
 // Lint performs a linting pass, returning a slice of Problems indicating any
 // issues found in the metrics stream. The slice is sorted by metric name
 // and issue description.
 func (l *Linter) Lint() ([]Problem, error) {
 	var problems []Problem
+	rules := l.activeRules()
 
 	if l.r != nil {
 		d := expfmt.NewDecoder(l.r, expfmt.NewFormat(expfmt.TypeTextPlain))
@@ -80,11 +136,11 @@ func (l *Linter) Lint() ([]Problem, error) {
 				return nil, err
 			}
 
-			problems = append(problems, l.lint(mf)...)
+			problems = append(problems, l.lint(mf, rules)...)
 		}
 	}
 	for _, mf := range l.mfs {
-		problems = append(problems, l.lint(mf)...)
+		problems = append(problems, l.lint(mf, rules)...)
 	}
 
 	// Ensure deterministic output.
@@ -99,13 +155,13 @@ func (l *Linter) Lint() ([]Problem, error) {
 }
 
 // lint is the entry point for linting a single metric.
-func (l *Linter) lint(mf *dto.MetricFamily) []Problem {
+func (l *Linter) lint(mf *dto.MetricFamily, rules []Rule) []Problem {
 	var problems []Problem
 
-	for _, fn := range defaultValidations {
-		errs := fn(mf)
+	for _, r := range rules {
+		errs := r.Validate(mf)
 		for _, err := range errs {
-			problems = append(problems, newProblem(mf, err.Error()))
+			problems = append(problems, newProblemFromError(mf, err))
 		}
 	}
 
@@ -113,7 +169,7 @@ func (l *Linter) lint(mf *dto.MetricFamily) []Problem {
 		for _, fn := range l.customValidations {
 			errs := fn(mf)
 			for _, err := range errs {
-				problems = append(problems, newProblem(mf, err.Error()))
+				problems = append(problems, newProblemFromError(mf, err))
 			}
 		}
 	}
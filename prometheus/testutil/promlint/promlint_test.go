@@ -17,12 +17,14 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
 	"strings"
 	"testing"
 
 	dto "github.com/prometheus/client_model/go"
 
 	"github.com/prometheus/client_golang/prometheus/testutil/promlint"
+	"github.com/prometheus/client_golang/prometheus/testutil/promlint/validations"
 )
 
 type test struct {
@@ -188,6 +190,7 @@ x_milliamperes 10
 			problems: []promlint.Problem{{
 				Metric: "x_milliamperes",
 				Text:   `use base unit "amperes" instead of "milliamperes"`,
+				Fix:    &promlint.Fix{Name: "x_amperes"},
 			}},
 		},
 		{
@@ -200,6 +203,7 @@ x_gigabytes 10
 			problems: []promlint.Problem{{
 				Metric: "x_gigabytes",
 				Text:   `use base unit "bytes" instead of "gigabytes"`,
+				Fix:    &promlint.Fix{Name: "x_bytes"},
 			}},
 		},
 		{
@@ -212,6 +216,7 @@ x_kilograms 10
 			problems: []promlint.Problem{{
 				Metric: "x_kilograms",
 				Text:   `use base unit "grams" instead of "kilograms"`,
+				Fix:    &promlint.Fix{Name: "x_grams"},
 			}},
 		},
 		{
@@ -224,6 +229,7 @@ x_nanocelsius 10
 			problems: []promlint.Problem{{
 				Metric: "x_nanocelsius",
 				Text:   `use base unit "celsius" instead of "nanocelsius"`,
+				Fix:    &promlint.Fix{Name: "x_celsius"},
 			}},
 		},
 		{
@@ -236,6 +242,7 @@ x_kilometers 10
 			problems: []promlint.Problem{{
 				Metric: "x_kilometers",
 				Text:   `use base unit "meters" instead of "kilometers"`,
+				Fix:    &promlint.Fix{Name: "x_meters"},
 			}},
 		},
 		{
@@ -248,6 +255,7 @@ x_picometers 10
 			problems: []promlint.Problem{{
 				Metric: "x_picometers",
 				Text:   `use base unit "meters" instead of "picometers"`,
+				Fix:    &promlint.Fix{Name: "x_meters"},
 			}},
 		},
 		{
@@ -260,6 +268,7 @@ x_microseconds 10
 			problems: []promlint.Problem{{
 				Metric: "x_microseconds",
 				Text:   `use base unit "seconds" instead of "microseconds"`,
+				Fix:    &promlint.Fix{Name: "x_seconds"},
 			}},
 		},
 		{
@@ -272,6 +281,7 @@ x_minutes 10
 			problems: []promlint.Problem{{
 				Metric: "x_minutes",
 				Text:   `use base unit "seconds" instead of "minutes"`,
+				Fix:    &promlint.Fix{Name: "x_seconds"},
 			}},
 		},
 		{
@@ -284,6 +294,7 @@ x_hours 10
 			problems: []promlint.Problem{{
 				Metric: "x_hours",
 				Text:   `use base unit "seconds" instead of "hours"`,
+				Fix:    &promlint.Fix{Name: "x_seconds"},
 			}},
 		},
 		{
@@ -296,6 +307,7 @@ x_days 10
 			problems: []promlint.Problem{{
 				Metric: "x_days",
 				Text:   `use base unit "seconds" instead of "days"`,
+				Fix:    &promlint.Fix{Name: "x_seconds"},
 			}},
 		},
 		{
@@ -308,6 +320,7 @@ x_kelvins 10
 			problems: []promlint.Problem{{
 				Metric: "x_kelvins",
 				Text:   `use base unit "kelvin" instead of "kelvins"`,
+				Fix:    &promlint.Fix{Name: "x_kelvin"},
 			}},
 		},
 		{
@@ -320,6 +333,7 @@ thermometers_fahrenheit 10
 			problems: []promlint.Problem{{
 				Metric: "thermometers_fahrenheit",
 				Text:   `use base unit "celsius" instead of "fahrenheit"`,
+				Fix:    &promlint.Fix{Name: "thermometers_celsius"},
 			}},
 		},
 		{
@@ -332,6 +346,7 @@ thermometers_rankine 10
 			problems: []promlint.Problem{{
 				Metric: "thermometers_rankine",
 				Text:   `use base unit "celsius" instead of "rankine"`,
+				Fix:    &promlint.Fix{Name: "thermometers_celsius"},
 			}},
 		},
 		{
@@ -344,6 +359,7 @@ x_inches 10
 			problems: []promlint.Problem{{
 				Metric: "x_inches",
 				Text:   `use base unit "meters" instead of "inches"`,
+				Fix:    &promlint.Fix{Name: "x_meters"},
 			}},
 		},
 		{
@@ -356,6 +372,7 @@ x_yards 10
 			problems: []promlint.Problem{{
 				Metric: "x_yards",
 				Text:   `use base unit "meters" instead of "yards"`,
+				Fix:    &promlint.Fix{Name: "x_meters"},
 			}},
 		},
 		{
@@ -368,6 +385,7 @@ x_miles 10
 			problems: []promlint.Problem{{
 				Metric: "x_miles",
 				Text:   `use base unit "meters" instead of "miles"`,
+				Fix:    &promlint.Fix{Name: "x_meters"},
 			}},
 		},
 		{
@@ -380,6 +398,7 @@ x_bits 10
 			problems: []promlint.Problem{{
 				Metric: "x_bits",
 				Text:   `use base unit "bytes" instead of "bits"`,
+				Fix:    &promlint.Fix{Name: "x_bytes"},
 			}},
 		},
 		{
@@ -392,6 +411,7 @@ x_calories 10
 			problems: []promlint.Problem{{
 				Metric: "x_calories",
 				Text:   `use base unit "joules" instead of "calories"`,
+				Fix:    &promlint.Fix{Name: "x_joules"},
 			}},
 		},
 		{
@@ -404,6 +424,7 @@ x_pounds 10
 			problems: []promlint.Problem{{
 				Metric: "x_pounds",
 				Text:   `use base unit "grams" instead of "pounds"`,
+				Fix:    &promlint.Fix{Name: "x_grams"},
 			}},
 		},
 		{
@@ -416,6 +437,7 @@ x_ounces 10
 			problems: []promlint.Problem{{
 				Metric: "x_ounces",
 				Text:   `use base unit "grams" instead of "ounces"`,
+				Fix:    &promlint.Fix{Name: "x_grams"},
 			}},
 		},
 	}
@@ -841,6 +863,62 @@ mc_something_total 10
 	})
 }
 
+func TestRules(t *testing.T) {
+	const in = `
+# HELP mc_something Test metric.
+# TYPE mc_something counter
+mc_something{instance="a"} 10
+`
+	lint := func(l *promlint.Linter) []promlint.Problem {
+		t.Helper()
+		problems, err := l.Lint()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return problems
+	}
+	counterProblem := promlint.Problem{Metric: "mc_something", Text: `counter metrics should have "_total" suffix`}
+	prefixProblem := promlint.Problem{Metric: "mc_something", Text: `metric name should start with one of ["memcached_"]`}
+	labelProblem := promlint.Problem{Metric: "mc_something", Text: `metric should have label "job"`}
+
+	// Registered opt-in rules only run if enabled, so that they don't
+	// affect the other tests.
+	promlint.MustRegisterRule(promlint.Rule{
+		Name:     "test_required_labels",
+		Validate: validations.RequiredLabels("instance", "job"),
+		OptIn:    true,
+	})
+	if err := promlint.RegisterRule(promlint.Rule{Name: promlint.RuleCounter, Validate: validations.LintCounter}); err == nil {
+		t.Error("expected error for duplicate rule name")
+	}
+
+	l := promlint.New(strings.NewReader(in))
+	if got, want := lint(l), []promlint.Problem{counterProblem}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected problems:\n- want: %v\n-  got: %v", want, got)
+	}
+
+	l = promlint.New(strings.NewReader(in))
+	if err := l.AddRules(promlint.Rule{Name: "prefix", Validate: validations.NamePrefix("memcached_")}); err != nil {
+		t.Fatal(err)
+	}
+	l.DisableRules(promlint.RuleCounter)
+	l.EnableRules("test_required_labels")
+	if got, want := lint(l), []promlint.Problem{prefixProblem, labelProblem}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected problems:\n- want: %v\n-  got: %v", want, got)
+	}
+
+	if err := l.AddRules(promlint.Rule{Name: "invalid"}); err == nil {
+		t.Error("expected error for rule without validation")
+	}
+	var names []string
+	for _, r := range promlint.Rules() {
+		names = append(names, r.Name)
+	}
+	if !slices.Contains(names, "test_required_labels") || !slices.Contains(names, promlint.RuleHelp) {
+		t.Errorf("got rules %v", names)
+	}
+}
+
 func TestLintDuplicateMetric(t *testing.T) {
 	const msg = "metric not unique"
 
@@ -864,3 +942,82 @@ not_unique_total{bar="abc", spam="xyz"} 2
 
 	runTests(t, tests)
 }
+
+func TestLintUnitMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		mf       *dto.MetricFamily
+		problems []promlint.Problem
+	}{
+		{
+			name: "matching unit",
+			mf:   &dto.MetricFamily{Name: ptr("x_seconds"), Help: ptr("Duration in seconds."), Unit: ptr("seconds")},
+		},
+		{
+			name: "non-base unit metadata",
+			mf:   &dto.MetricFamily{Name: ptr("x_seconds"), Help: ptr("Duration."), Unit: ptr("milliseconds")},
+			problems: []promlint.Problem{{
+				Metric: "x_seconds",
+				Text:   `use base unit "seconds" instead of "milliseconds" in unit metadata`,
+				Fix:    &promlint.Fix{Unit: "seconds"},
+			}},
+		},
+		{
+			name: "missing unit in name",
+			mf:   &dto.MetricFamily{Name: ptr("x_size"), Help: ptr("Size."), Unit: ptr("bytes")},
+			problems: []promlint.Problem{{
+				Metric: "x_size",
+				Text:   `metric name should contain the unit "bytes"`,
+				Fix:    &promlint.Fix{Name: "x_size_bytes"},
+			}},
+		},
+		{
+			name: "mismatched unit metadata",
+			mf:   &dto.MetricFamily{Name: ptr("x_bytes"), Help: ptr("Size."), Unit: ptr("seconds")},
+			problems: []promlint.Problem{{
+				Metric: "x_bytes",
+				Text:   `unit metadata "seconds" doesn't match unit "bytes" in metric name`,
+				Fix:    &promlint.Fix{Unit: "bytes"},
+			}},
+		},
+		{
+			name: "mismatched help",
+			mf:   &dto.MetricFamily{Name: ptr("x_seconds"), Help: ptr("Duration in milliseconds.")},
+			problems: []promlint.Problem{{
+				Metric: "x_seconds",
+				Text:   `help mentions unit "milliseconds", but the metric name uses "seconds"`,
+			}},
+		},
+		{
+			name: "percent",
+			mf:   &dto.MetricFamily{Name: ptr("x_cpu_percent"), Help: ptr("CPU usage.")},
+			problems: []promlint.Problem{{
+				Metric: "x_cpu_percent",
+				Text:   `use base unit "ratio" instead of "percent"`,
+				Fix:    &promlint.Fix{Name: "x_cpu_ratio"},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mf.Type = dto.MetricType_GAUGE.Enum()
+			tt.mf.Metric = []*dto.Metric{{Gauge: &dto.Gauge{Value: ptr(1.0)}}}
+			l := promlint.NewWithMetricFamilies([]*dto.MetricFamily{tt.mf})
+
+			problems, err := l.Lint()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if want, got := tt.problems, problems; !reflect.DeepEqual(want, got) {
+				t.Fatalf("unexpected problems:\n- want: %v\n-  got: %v",
+					want, got)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
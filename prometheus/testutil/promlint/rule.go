@@ -0,0 +1,122 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promlint
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus/testutil/promlint/validations"
+)
+
+// A Rule is a named Validation, which can be registered with RegisterRule to
+// be run by all Linters, or added to a single Linter with AddRules. Rules can
+// be disabled and enabled per Linter by name, see Linter.DisableRules and
+// Linter.EnableRules.
+//
+// The functions in the validations package can be used to create rules for
+// common conventions, e.g.
+//
+//	promlint.MustRegisterRule(promlint.Rule{
+//		Name:     "acme_prefix",
+//		Validate: validations.NamePrefix("acme_"),
+//	})
+type Rule struct {
+	// Name identifies the rule. It must not be empty.
+	Name string
+	// Validate checks a metric family and returns the problems found.
+	Validate Validation
+	// OptIn rules are only run by Linters that enabled them with
+	// EnableRules.
+	OptIn bool
+}
+
+// Names of the built-in rules, which are run by all Linters unless disabled.
+const (
+	RuleHelp                     = "help"
+	RuleMetricUnits              = "metric_units"
+	RuleCounter                  = "counter"
+	RuleHistogramSummaryReserved = "histogram_summary_reserved"
+	RuleMetricTypeInName         = "metric_type_in_name"
+	RuleReservedChars            = "reserved_chars"
+	RuleCamelCase                = "camel_case"
+	RuleUnitAbbreviations        = "unit_abbreviations"
+	RuleDuplicateMetric          = "duplicate_metric"
+	RuleUnitMetadata             = "unit_metadata"
+)
+
+var defaultRules = []Rule{
+	{Name: RuleHelp, Validate: validations.LintHelp},
+	{Name: RuleMetricUnits, Validate: validations.LintMetricUnits},
+	{Name: RuleCounter, Validate: validations.LintCounter},
+	{Name: RuleHistogramSummaryReserved, Validate: validations.LintHistogramSummaryReserved},
+	{Name: RuleMetricTypeInName, Validate: validations.LintMetricTypeInName},
+	{Name: RuleReservedChars, Validate: validations.LintReservedChars},
+	{Name: RuleCamelCase, Validate: validations.LintCamelCase},
+	{Name: RuleUnitAbbreviations, Validate: validations.LintUnitAbbreviations},
+	{Name: RuleDuplicateMetric, Validate: validations.LintDuplicateMetric},
+	{Name: RuleUnitMetadata, Validate: validations.LintUnitMetadata},
+}
+
+var (
+	registeredRulesMtx sync.RWMutex
+	registeredRules    []Rule
+)
+
+// RegisterRule registers the rule to be run by all Linters, in addition to
+// the built-in rules. It returns an error if the rule is invalid or a rule
+// with the same name is already registered.
+func RegisterRule(r Rule) error {
+	if err := r.validate(); err != nil {
+		return err
+	}
+	registeredRulesMtx.Lock()
+	defer registeredRulesMtx.Unlock()
+	for _, other := range append(defaultRules[:len(defaultRules):len(defaultRules)], registeredRules...) {
+		if other.Name == r.Name {
+			return fmt.Errorf("lint rule %q already registered", r.Name)
+		}
+	}
+	registeredRules = append(registeredRules, r)
+	return nil
+}
+
+// MustRegisterRule works like RegisterRule but panics where RegisterRule
+// would have returned an error.
+func MustRegisterRule(r Rule) {
+	if err := RegisterRule(r); err != nil {
+		panic(err)
+	}
+}
+
+// Rules returns the built-in and the registered rules, sorted by name.
+func Rules() []Rule {
+	registeredRulesMtx.RLock()
+	rules := append(append([]Rule(nil), defaultRules...), registeredRules...)
+	registeredRulesMtx.RUnlock()
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Name < rules[j].Name })
+	return rules
+}
+
+func (r Rule) validate() error {
+	if r.Name == "" {
+		return errors.New("lint rule without name")
+	}
+	if r.Validate == nil {
+		return fmt.Errorf("lint rule %q without validation", r.Name)
+	}
+	return nil
+}
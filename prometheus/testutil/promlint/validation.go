@@ -13,22 +13,6 @@
 
 package promlint
 
-import (
-	dto "github.com/prometheus/client_model/go"
-
-	"github.com/prometheus/client_golang/prometheus/testutil/promlint/validations"
-)
+import dto "github.com/prometheus/client_model/go"
 
 type Validation = func(mf *dto.MetricFamily) []error
-
-var defaultValidations = []Validation{
-	validations.LintHelp,
-	validations.LintMetricUnits,
-	validations.LintCounter,
-	validations.LintHistogramSummaryReserved,
-	validations.LintMetricTypeInName,
-	validations.LintReservedChars,
-	validations.LintCamelCase,
-	validations.LintUnitAbbreviations,
-	validations.LintDuplicateMetric,
-}
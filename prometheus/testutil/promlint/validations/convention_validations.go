@@ -0,0 +1,86 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validations
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// NamePrefix returns a validation detecting metric names that don't start with
+// any of the provided prefixes, e.g. the name of the application.
+func NamePrefix(prefixes ...string) func(mf *dto.MetricFamily) []error {
+	return func(mf *dto.MetricFamily) []error {
+		for _, p := range prefixes {
+			if strings.HasPrefix(mf.GetName(), p) {
+				return nil
+			}
+		}
+		return []error{fmt.Errorf("metric name should start with one of %q", prefixes)}
+	}
+}
+
+// ForbiddenNamePrefix returns a validation detecting metric names that start
+// with any of the provided prefixes, e.g. prefixes reserved for other
+// applications.
+func ForbiddenNamePrefix(prefixes ...string) func(mf *dto.MetricFamily) []error {
+	return func(mf *dto.MetricFamily) []error {
+		for _, p := range prefixes {
+			if strings.HasPrefix(mf.GetName(), p) {
+				return []error{fmt.Errorf("metric name should not start with %q", p)}
+			}
+		}
+		return nil
+	}
+}
+
+// NamePattern returns a validation detecting metric names that don't match the
+// regular expression.
+func NamePattern(re *regexp.Regexp) func(mf *dto.MetricFamily) []error {
+	return func(mf *dto.MetricFamily) []error {
+		if !re.MatchString(mf.GetName()) {
+			return []error{fmt.Errorf("metric name should match %q", re.String())}
+		}
+		return nil
+	}
+}
+
+// RequiredLabels returns a validation detecting metrics without any of the
+// provided labels.
+func RequiredLabels(names ...string) func(mf *dto.MetricFamily) []error {
+	return func(mf *dto.MetricFamily) []error {
+		var problems []error
+		for _, name := range names {
+			for _, m := range mf.GetMetric() {
+				if !hasLabel(m, name) {
+					problems = append(problems, fmt.Errorf("metric should have label %q", name))
+					break
+				}
+			}
+		}
+		return problems
+	}
+}
+
+func hasLabel(m *dto.Metric, name string) bool {
+	for _, lp := range m.GetLabel() {
+		if lp.GetName() == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validations
+
+// A Fix is a machine-readable suggestion to resolve a problem, for tooling to
+// apply. Empty fields are to be left unchanged.
+type Fix struct {
+	// Name is the suggested metric name.
+	Name string
+	// Unit is the suggested unit metadata.
+	Unit string
+}
+
+// FixError is a problem returned by a validation together with a suggested
+// Fix.
+type FixError struct {
+	Err error
+	Fix Fix
+}
+
+func (e *FixError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FixError) Unwrap() error {
+	return e.Err
+}
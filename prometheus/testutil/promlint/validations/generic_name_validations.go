@@ -39,11 +39,67 @@ func LintMetricUnits(mf *dto.MetricFamily) []error {
 		return nil
 	}
 
-	problems = append(problems, fmt.Errorf("use base unit %q instead of %q", base, unit))
+	problems = append(problems, &FixError{
+		Err: fmt.Errorf("use base unit %q instead of %q", base, unit),
+		Fix: Fix{Name: replaceUnit(mf.GetName(), unit, base)},
+	})
 
 	return problems
 }
 
+// LintUnitMetadata detects unit metadata that isn't a base unit or doesn't
+// match the unit in the metric name, and help texts mentioning a different
+// unit of the same quantity than the metric name, e.g. "milliseconds" for a
+// metric in seconds.
+func LintUnitMetadata(mf *dto.MetricFamily) []error {
+	var problems []error
+
+	nameUnit, nameBase, hasNameUnit := metricUnits(mf.GetName())
+	if unit := mf.GetUnit(); unit != "" {
+		base, known := lookupUnit(unit)
+		switch {
+		case known && base != unit:
+			problems = append(problems, &FixError{
+				Err: fmt.Errorf("use base unit %q instead of %q in unit metadata", base, unit),
+				Fix: Fix{Unit: base},
+			})
+		case !hasNameUnit:
+			problems = append(problems, &FixError{
+				Err: fmt.Errorf("metric name should contain the unit %q", unit),
+				Fix: Fix{Name: appendUnit(mf.GetName(), unit)},
+			})
+		case nameUnit != unit:
+			problems = append(problems, &FixError{
+				Err: fmt.Errorf("unit metadata %q doesn't match unit %q in metric name", unit, nameUnit),
+				Fix: Fix{Unit: nameUnit},
+			})
+		}
+	}
+
+	if !hasNameUnit {
+		return problems
+	}
+	words := strings.FieldsFunc(strings.ToLower(mf.GetHelp()), func(r rune) bool {
+		return r < 'a' || r > 'z'
+	})
+	for _, w := range words {
+		if base, ok := lookupUnit(w); ok && base == nameBase && w != nameUnit {
+			problems = append(problems, fmt.Errorf("help mentions unit %q, but the metric name uses %q", w, nameUnit))
+			break
+		}
+	}
+
+	return problems
+}
+
+// appendUnit adds the unit to the metric name m, before a "_total" suffix.
+func appendUnit(m, unit string) string {
+	if base, ok := strings.CutSuffix(m, "_total"); ok {
+		return base + "_" + unit + "_total"
+	}
+	return m + "_" + unit
+}
+
 // LintMetricTypeInName detects when the metric type is included in the metric name.
 func LintMetricTypeInName(mf *dto.MetricFamily) []error {
 	if mf.GetType() == dto.MetricType_UNTYPED {
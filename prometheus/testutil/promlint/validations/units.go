@@ -29,6 +29,7 @@ var (
 		"kelvin":  "kelvin", // SI base unit, used in special cases (e.g. color temperature, scientific measurements).
 		"meters":  "meters", // Both American and international spelling permitted.
 		"metres":  "metres",
+		"ratio":   "ratio",
 		"seconds": "seconds",
 		"volts":   "volts",
 
@@ -50,6 +51,8 @@ var (
 		"bits": "bytes",
 		// Energy.
 		"calories": "joules",
+		// Ratio.
+		"percent": "ratio",
 		// Mass.
 		"pounds": "grams",
 		"ounces": "grams",
@@ -101,18 +104,38 @@ func metricUnits(m string) (unit, base string, ok bool) {
 	ss := strings.Split(m, "_")
 
 	for _, s := range ss {
-		if base, found := units[s]; found {
+		if base, found := lookupUnit(s); found {
 			return s, base, true
 		}
+	}
 
-		for _, p := range unitPrefixes {
-			if strings.HasPrefix(s, p) {
-				if base, found := units[s[len(p):]]; found {
-					return s, base, true
-				}
+	return "", "", false
+}
+
+// lookupUnit returns the base unit of the unit s, which may have a prefix,
+// e.g. "seconds" for "milliseconds".
+func lookupUnit(s string) (base string, ok bool) {
+	if base, found := units[s]; found {
+		return base, true
+	}
+	for _, p := range unitPrefixes {
+		if strings.HasPrefix(s, p) {
+			if base, found := units[s[len(p):]]; found {
+				return base, true
 			}
 		}
 	}
+	return "", false
+}
 
-	return "", "", false
+// replaceUnit replaces the unit in the metric name m with the unit replacement.
+func replaceUnit(m, unit, replacement string) string {
+	ss := strings.Split(m, "_")
+	for i, s := range ss {
+		if s == unit {
+			ss[i] = replacement
+			break
+		}
+	}
+	return strings.Join(ss, "_")
 }
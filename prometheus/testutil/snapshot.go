@@ -0,0 +1,131 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"fmt"
+	"math"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Snapshot is the state of the metrics of a Gatherer at a point in time. It is
+// used to assert how much metrics changed while running the code under test,
+// rather than their absolute values, so that tests sharing a registry (like
+// prometheus.DefaultRegisterer) or running after other tests don't interfere:
+//
+//	s, err := testutil.TakeSnapshot(prometheus.DefaultGatherer)
+//	// Run the code under test.
+//	err = s.AssertDelta("http_requests_total", prometheus.Labels{"code": "200"}, 1)
+type Snapshot struct {
+	g      prometheus.Gatherer
+	before []*dto.MetricFamily
+}
+
+// TakeSnapshot gathers all metrics from the provided Gatherer and returns them
+// as a Snapshot.
+func TakeSnapshot(g prometheus.Gatherer) (*Snapshot, error) {
+	mfs, err := g.Gather()
+	if err != nil {
+		return nil, fmt.Errorf("gathering metrics failed: %w", err)
+	}
+	return &Snapshot{g: g, before: mfs}, nil
+}
+
+// Delta gathers the metrics again and returns by how much the metric with the
+// provided name changed since the snapshot was taken, summed up over all
+// series with the provided labels. Other labels of the series are ignored, so
+// that nil labels select all series of the metric. Series that didn't exist at
+// the time of the snapshot count as starting from zero.
+//
+// The change of counters, gauges, and untyped metrics is the change of their
+// value. The change of histograms and summaries is the change of their number
+// of observations, see SumDelta for the change of the sum of observations.
+func (s *Snapshot) Delta(metricName string, labels prometheus.Labels) (float64, error) {
+	return s.delta(metricName, labels, false)
+}
+
+// SumDelta is like Delta, but for histograms and summaries it returns the
+// change of the sum of observations. For other metric types, it returns the
+// same result as Delta.
+func (s *Snapshot) SumDelta(metricName string, labels prometheus.Labels) (float64, error) {
+	return s.delta(metricName, labels, true)
+}
+
+// AssertDelta returns an error if the result of Delta for the provided metric
+// name and labels differs from want.
+func (s *Snapshot) AssertDelta(metricName string, labels prometheus.Labels, want float64) error {
+	got, err := s.Delta(metricName, labels)
+	if err != nil {
+		return err
+	}
+	if got != want && !(math.IsNaN(got) && math.IsNaN(want)) {
+		return fmt.Errorf("expected %s with labels %v to change by %g, but it changed by %g", metricName, labels, want, got)
+	}
+	return nil
+}
+
+func (s *Snapshot) delta(metricName string, labels prometheus.Labels, sum bool) (float64, error) {
+	after, err := s.g.Gather()
+	if err != nil {
+		return 0, fmt.Errorf("gathering metrics failed: %w", err)
+	}
+	before := sumMetric(s.before, metricName, labels, sum)
+	return sumMetric(after, metricName, labels, sum) - before, nil
+}
+
+// sumMetric sums up the values of the series of the metric with the provided
+// name and labels.
+func sumMetric(mfs []*dto.MetricFamily, metricName string, labels prometheus.Labels, sum bool) float64 {
+	var total float64
+	for _, mf := range mfs {
+		if mf.GetName() != metricName {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			if hasLabels(m, labels) {
+				total += metricValue(m, sum)
+			}
+		}
+	}
+	return total
+}
+
+func metricValue(m *dto.Metric, sum bool) float64 {
+	switch {
+	case m.GetCounter() != nil:
+		return m.GetCounter().GetValue()
+	case m.GetGauge() != nil:
+		return m.GetGauge().GetValue()
+	case m.GetUntyped() != nil:
+		return m.GetUntyped().GetValue()
+	case m.GetHistogram() != nil:
+		h := m.GetHistogram()
+		if sum {
+			return h.GetSampleSum()
+		}
+		if h.SampleCountFloat != nil {
+			return h.GetSampleCountFloat()
+		}
+		return float64(h.GetSampleCount())
+	case m.GetSummary() != nil:
+		if sum {
+			return m.GetSummary().GetSampleSum()
+		}
+		return float64(m.GetSummary().GetSampleCount())
+	}
+	return 0
+}
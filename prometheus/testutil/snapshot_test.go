@@ -0,0 +1,73 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestSnapshot(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "requests_total"}, []string{"code"})
+	latency := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "latency_seconds"})
+	reg.MustRegister(requests, latency)
+
+	// Prior state that must not affect the deltas.
+	requests.WithLabelValues("200").Add(10)
+	latency.Observe(5)
+
+	s, err := TakeSnapshot(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests.WithLabelValues("200").Inc()
+	requests.WithLabelValues("500").Add(2)
+	latency.Observe(0.5)
+	latency.Observe(1)
+
+	for _, tc := range []struct {
+		name   string
+		labels prometheus.Labels
+		sum    bool
+		want   float64
+	}{
+		{name: "requests_total", labels: prometheus.Labels{"code": "200"}, want: 1},
+		{name: "requests_total", labels: prometheus.Labels{"code": "500"}, want: 2},
+		{name: "requests_total", want: 3},
+		{name: "latency_seconds", want: 2},
+		{name: "latency_seconds", sum: true, want: 1.5},
+		{name: "missing", want: 0},
+	} {
+		delta := s.Delta
+		if tc.sum {
+			delta = s.SumDelta
+		}
+		got, err := delta(tc.name, tc.labels)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Errorf("%s%v (sum %t): got delta %g, want %g", tc.name, tc.labels, tc.sum, got, tc.want)
+		}
+	}
+
+	if err := s.AssertDelta("requests_total", prometheus.Labels{"code": "200"}, 1); err != nil {
+		t.Error(err)
+	}
+	if err := s.AssertDelta("requests_total", prometheus.Labels{"code": "200"}, 2); err == nil {
+		t.Error("expected error for wrong delta")
+	}
+}
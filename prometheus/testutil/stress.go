@@ -0,0 +1,92 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// StressCollector registers the provided Collector with a newly created
+// pedantic Registry and gathers from it concurrently from several goroutines,
+// while each of the provided mutators is called iterations times in a goroutine
+// of its own, e.g. to increment counters, add and delete series, or change the
+// state the Collector reads from. Describe is called concurrently as well.
+//
+// It returns the errors of the registration and of all gatherings, joined
+// with errors.Join. As the Registry is pedantic, they include collected
+// Metrics that are inconsistent with the described Descs, duplicate series,
+// and invalid label values. Run the test with the race detector enabled
+// (go test -race) to also detect unsynchronized access to the state of the
+// Collector.
+func StressCollector(c prometheus.Collector, iterations int, mutators ...func()) error {
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(c); err != nil {
+		return fmt.Errorf("registering collector failed: %w", err)
+	}
+
+	var (
+		mutating  sync.WaitGroup
+		gathering sync.WaitGroup
+		done      = make(chan struct{})
+		mu        sync.Mutex
+		errs      []error
+		seen      = map[string]struct{}{}
+	)
+	// Gatherings usually fail the same way repeatedly, so only keep
+	// distinct errors.
+	addErr := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if _, ok := seen[err.Error()]; !ok {
+			seen[err.Error()] = struct{}{}
+			errs = append(errs, err)
+		}
+	}
+	for _, mutate := range mutators {
+		mutating.Go(func() {
+			for range iterations {
+				mutate()
+			}
+		})
+	}
+	for range runtime.GOMAXPROCS(0) {
+		gathering.Go(func() {
+			for {
+				describeAll(c)
+				if _, err := reg.Gather(); err != nil {
+					addErr(err)
+				}
+				select {
+				case <-done:
+					return
+				default:
+				}
+			}
+		})
+	}
+	mutating.Wait()
+	close(done)
+	gathering.Wait()
+
+	// Gather once more to check the final state.
+	if _, err := reg.Gather(); err != nil {
+		addErr(err)
+	}
+	return errors.Join(errs...)
+}
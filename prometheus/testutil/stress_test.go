@@ -0,0 +1,61 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// undescribedCollector collects a metric it doesn't describe after it was
+// mutated.
+type undescribedCollector struct {
+	desc, other *prometheus.Desc
+	mutated     atomic.Bool
+}
+
+func (c *undescribedCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *undescribedCollector) Collect(ch chan<- prometheus.Metric) {
+	desc := c.desc
+	if c.mutated.Load() {
+		desc = c.other
+	}
+	ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, 1)
+}
+
+func TestStressCollector(t *testing.T) {
+	vec := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "requests_total", Help: "Requests."}, []string{"code"})
+	var n atomic.Int64
+	err := StressCollector(vec, 100,
+		func() { vec.WithLabelValues(fmt.Sprint(n.Add(1) % 10)).Inc() },
+		func() { vec.DeleteLabelValues(fmt.Sprint(n.Load() % 10)) },
+	)
+	if err != nil {
+		t.Error(err)
+	}
+
+	c := &undescribedCollector{
+		desc:  prometheus.NewDesc("described", "Described.", nil, nil),
+		other: prometheus.NewDesc("undescribed", "Undescribed.", nil, nil),
+	}
+	if err := StressCollector(c, 1, func() { c.mutated.Store(true) }); err == nil {
+		t.Error("expected error for inconsistent Desc")
+	}
+}
@@ -23,14 +23,17 @@
 // interfaces that simply assert that the Add or Register methods have been
 // called with the expected arguments. However, this might be overkill in simple
 // scenarios. The ToFloat64 function is provided for simple inspection of a
-// single-value metric, but it has to be used with caution.
+// single-value metric, and ToHistogram for the inspection of a histogram, but
+// they have to be used with caution.
 //
 // End-to-end tests to verify all or larger parts of the metrics exposition can
 // be implemented with the CollectAndCompare or GatherAndCompare functions. The
 // most appropriate use is not so much testing instrumentation of your code, but
 // testing custom prometheus.Collector implementations and in particular whole
 // exporters, i.e. programs that retrieve telemetry data from a 3rd party source
-// and convert it into Prometheus metrics.
+// and convert it into Prometheus metrics. As the text format cannot express
+// native histograms, CollectAndCompareProto and GatherAndCompareProto compare
+// against expected metrics in the protobuf representation instead.
 //
 // In a similar pattern, CollectAndLint and GatherAndLint can be used to detect
 // metrics that have issues with their name, type, or metadata without being
@@ -39,10 +42,16 @@ package testutil
 
 import (
 	"bytes"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/kylelemons/godebug/diff"
 	dto "github.com/prometheus/client_model/go"
@@ -80,32 +89,51 @@ import (
 // considering Prometheus metrics) and then expose the number with a
 // prometheus.GaugeFunc.
 func ToFloat64(c prometheus.Collector) float64 {
-	var (
-		m      prometheus.Metric
-		mCount int
-		mChan  = make(chan prometheus.Metric)
-		done   = make(chan struct{})
-	)
+	return floatValue(collectOne(c))
+}
 
-	go func() {
-		for m = range mChan {
-			mCount++
+// ToFloat64WithLabels is like ToFloat64, but it collects all Metrics from the
+// provided Collector and returns the value of the only one that has all the
+// provided labels. If not exactly one Metric has these labels, it panics. It
+// allows reading a single child of a vector without calling WithLabelValues,
+// which would create the child if it didn't exist.
+func ToFloat64WithLabels(c prometheus.Collector, labels prometheus.Labels) float64 {
+	var found *dto.Metric
+	for _, pb := range collectAll(c) {
+		if !hasLabels(pb, labels) {
+			continue
 		}
-		close(done)
-	}()
-
-	c.Collect(mChan)
-	close(mChan)
-	<-done
-
-	if mCount != 1 {
-		panic(fmt.Errorf("collected %d metrics instead of exactly 1", mCount))
+		if found != nil {
+			panic(fmt.Errorf("collected more than one metric with labels %v", labels))
+		}
+		found = pb
 	}
+	if found == nil {
+		panic(fmt.Errorf("collected no metric with labels %v", labels))
+	}
+	return floatValue(found)
+}
 
-	pb := &dto.Metric{}
-	if err := m.Write(pb); err != nil {
-		panic(fmt.Errorf("error happened while collecting metrics: %w", err))
+// ToFloat64Map is like ToFloat64, but it collects all Metrics from the provided
+// Collector and returns their values keyed by their label signature, i.e. the
+// labels sorted by name in the text format like `{code="200",method="get"}`,
+// or `{}` for a Metric without labels. It panics if two Metrics have the same
+// labels, e.g. if the Collector collects several metrics with different names.
+func ToFloat64Map(c prometheus.Collector) map[string]float64 {
+	values := map[string]float64{}
+	for _, pb := range collectAll(c) {
+		key := formatLabels(pb)
+		if _, ok := values[key]; ok {
+			panic(fmt.Errorf("collected more than one metric with labels %s", key))
+		}
+		values[key] = floatValue(pb)
 	}
+	return values
+}
+
+// floatValue returns the value of a Gauge, Counter, or Untyped metric and panics
+// for other metric types.
+func floatValue(pb *dto.Metric) float64 {
 	if pb.Gauge != nil {
 		return pb.Gauge.GetValue()
 	}
@@ -118,6 +146,47 @@ func ToFloat64(c prometheus.Collector) float64 {
 	panic(fmt.Errorf("collected a non-gauge/counter/untyped metric: %s", pb))
 }
 
+// collectOne collects the only Metric of c. It panics if c doesn't collect
+// exactly one Metric.
+func collectOne(c prometheus.Collector) *dto.Metric {
+	pbs := collectAll(c)
+	if len(pbs) != 1 {
+		panic(fmt.Errorf("collected %d metrics instead of exactly 1", len(pbs)))
+	}
+	return pbs[0]
+}
+
+// collectAll collects and writes all Metrics of c. It panics if writing a
+// Metric fails.
+func collectAll(c prometheus.Collector) []*dto.Metric {
+	var (
+		ms    []prometheus.Metric
+		mChan = make(chan prometheus.Metric)
+		done  = make(chan struct{})
+	)
+
+	go func() {
+		for m := range mChan {
+			ms = append(ms, m)
+		}
+		close(done)
+	}()
+
+	c.Collect(mChan)
+	close(mChan)
+	<-done
+
+	pbs := make([]*dto.Metric, 0, len(ms))
+	for _, m := range ms {
+		pb := &dto.Metric{}
+		if err := m.Write(pb); err != nil {
+			panic(fmt.Errorf("error happened while collecting metrics: %w", err))
+		}
+		pbs = append(pbs, pb)
+	}
+	return pbs
+}
+
 // CollectAndCount registers the provided Collector with a newly created
 // pedantic Registry. It then calls GatherAndCount with that Registry and with
 // the provided metricNames. In the unlikely case that the registration or the
@@ -157,6 +226,98 @@ func GatherAndCount(g prometheus.Gatherer, metricNames ...string) (int, error) {
 	return result, nil
 }
 
+// CollectAndAssertAbsent registers the provided Collector with a newly created
+// pedantic Registry and calls GatherAndAssertAbsent with that Registry and the
+// provided metricNames.
+func CollectAndAssertAbsent(c prometheus.Collector, metricNames ...string) error {
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(c); err != nil {
+		return fmt.Errorf("registering collector failed: %w", err)
+	}
+	return GatherAndAssertAbsent(reg, metricNames...)
+}
+
+// GatherAndAssertAbsent gathers all metrics from the provided Gatherer and
+// returns an error listing the metrics with the provided names that were
+// gathered nonetheless. If no metricNames are provided, it returns an error
+// if any metric was gathered.
+func GatherAndAssertAbsent(g prometheus.Gatherer, metricNames ...string) error {
+	got, err := g.Gather()
+	if err != nil {
+		return fmt.Errorf("gathering metrics failed: %w", err)
+	}
+	if metricNames != nil {
+		got = filterMetrics(got, metricNames)
+	}
+	var present []string
+	for _, mf := range got {
+		if len(mf.GetMetric()) > 0 {
+			present = append(present, fmt.Sprintf("%s (%d series)", mf.GetName(), len(mf.GetMetric())))
+		}
+	}
+	if len(present) > 0 {
+		return fmt.Errorf("expected metrics to be absent, but found: %s", strings.Join(present, ", "))
+	}
+	return nil
+}
+
+// CollectAndAssertSeriesAbsent registers the provided Collector with a newly
+// created pedantic Registry and calls GatherAndAssertSeriesAbsent with that
+// Registry.
+func CollectAndAssertSeriesAbsent(c prometheus.Collector, metricName string, labels prometheus.Labels) error {
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(c); err != nil {
+		return fmt.Errorf("registering collector failed: %w", err)
+	}
+	return GatherAndAssertSeriesAbsent(reg, metricName, labels)
+}
+
+// GatherAndAssertSeriesAbsent gathers all metrics from the provided Gatherer
+// and returns an error listing the series of the metric with the provided name
+// that have all the provided labels, e.g. to verify that a series was deleted
+// from a vector with DeleteLabelValues. Other labels of the series are
+// ignored, so that labels can be omitted to match all their values.
+func GatherAndAssertSeriesAbsent(g prometheus.Gatherer, metricName string, labels prometheus.Labels) error {
+	got, err := g.Gather()
+	if err != nil {
+		return fmt.Errorf("gathering metrics failed: %w", err)
+	}
+	var present []string
+	for _, mf := range filterMetrics(got, []string{metricName}) {
+		for _, m := range mf.GetMetric() {
+			if hasLabels(m, labels) {
+				present = append(present, formatLabels(m))
+			}
+		}
+	}
+	if len(present) > 0 {
+		return fmt.Errorf("expected series of %s with labels %v to be absent, but found: %s", metricName, labels, strings.Join(present, ", "))
+	}
+	return nil
+}
+
+// hasLabels reports whether m has all the labels.
+func hasLabels(m *dto.Metric, labels prometheus.Labels) bool {
+	matched := 0
+	for _, lp := range m.GetLabel() {
+		if v, ok := labels[lp.GetName()]; ok {
+			if v != lp.GetValue() {
+				return false
+			}
+			matched++
+		}
+	}
+	return matched == len(labels)
+}
+
+func formatLabels(m *dto.Metric) string {
+	pairs := make([]string, 0, len(m.GetLabel()))
+	for _, lp := range m.GetLabel() {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", lp.GetName(), lp.GetValue()))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
 // ScrapeAndCompare calls a remote exporter's endpoint which is expected to return some metrics in
 // plain text format. Then it compares it with the results that the `expected` would return.
 // If the `metricNames` is not empty it would filter the comparison only to the given metric names.
@@ -188,6 +349,75 @@ func ScrapeAndCompare(url string, expected io.Reader, metricNames ...string) err
 	return compareMetricFamilies(scraped, wanted, metricNames...)
 }
 
+// ScrapeAndCompareWithOptions is like ScrapeAndCompare, but configured with
+// CompareOptions, including the options for the scrape request like
+// WithTLSConfig, WithBasicAuth, WithBearerToken, WithHeader, and
+// WithScrapeTimeout. Responses in the text and protobuf formats are
+// supported, so that the negotiation of the protobuf format can be tested by
+// setting the Accept header.
+func ScrapeAndCompareWithOptions(url string, expected io.Reader, opts ...CompareOption) error {
+	o := newCompareOptions(opts)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("scraping metrics failed: %w", err)
+	}
+	for name, values := range o.header {
+		req.Header[name] = values
+	}
+	if o.username != "" || o.password != "" {
+		req.SetBasicAuth(o.username, o.password)
+	}
+	client := &http.Client{Timeout: o.timeout}
+	if o.tlsConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = o.tlsConfig
+		client.Transport = transport
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("scraping metrics failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("the scraping target returned a status code other than 200: %d",
+			resp.StatusCode)
+	}
+
+	var scraped []*dto.MetricFamily
+	if format := expfmt.ResponseFormat(resp.Header); format.FormatType() == expfmt.TypeProtoDelim {
+		dec := expfmt.NewDecoder(resp.Body, format)
+		for {
+			mf := &dto.MetricFamily{}
+			if err := dec.Decode(mf); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return fmt.Errorf("decoding scraped metrics failed: %w", err)
+			}
+			scraped = append(scraped, mf)
+		}
+		scraped = internal.NormalizeMetricFamilies(toMap(scraped))
+	} else if scraped, err = convertReaderToMetricFamily(resp.Body); err != nil {
+		return err
+	}
+
+	wanted, err := convertReaderToMetricFamily(expected)
+	if err != nil {
+		return err
+	}
+	return o.compare(scraped, wanted)
+}
+
+func toMap(mfs []*dto.MetricFamily) map[string]*dto.MetricFamily {
+	m := make(map[string]*dto.MetricFamily, len(mfs))
+	for _, mf := range mfs {
+		m[mf.GetName()] = mf
+	}
+	return m
+}
+
 // CollectAndCompare collects the metrics identified by `metricNames` and compares them in the Prometheus text
 // exposition format to the data read from expected.
 //
@@ -234,6 +464,331 @@ func TransactionalGatherAndCompare(g prometheus.TransactionalGatherer, expected
 	return compareMetricFamilies(got, wanted, metricNames...)
 }
 
+// CompareOption configures the comparison of CollectAndCompareWithOptions and
+// GatherAndCompareWithOptions.
+type CompareOption func(*compareOptions)
+
+type compareOptions struct {
+	metricNames  []string
+	absTolerance float64
+	relTolerance float64
+
+	withoutTimestamps bool
+	ignoredLabels     map[string]struct{}
+	ignoredValues     map[string]struct{}
+	ignoreAllValues   bool
+	ignoreOrder       bool
+
+	// Options of ScrapeAndCompareWithOptions.
+	tlsConfig          *tls.Config
+	username, password string
+	header             http.Header
+	timeout            time.Duration
+}
+
+func newCompareOptions(opts []CompareOption) *compareOptions {
+	o := &compareOptions{header: http.Header{}}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithMetricNames restricts the comparison to the metrics with the provided
+// names, like the metricNames of CollectAndCompare. It filters both the
+// expected and the collected metrics.
+func WithMetricNames(names ...string) CompareOption {
+	return func(o *compareOptions) {
+		o.metricNames = append(o.metricNames, names...)
+	}
+}
+
+// WithAbsoluteTolerance makes sample values compare as equal if they differ by
+// at most epsilon, e.g. for durations that vary between test runs.
+func WithAbsoluteTolerance(epsilon float64) CompareOption {
+	return func(o *compareOptions) {
+		o.absTolerance = epsilon
+	}
+}
+
+// WithRelativeTolerance makes sample values compare as equal if they differ by
+// at most epsilon times the larger of their absolute values, e.g. 0.01 for
+// 1%. If combined with WithAbsoluteTolerance, values are equal if they are
+// within either tolerance.
+func WithRelativeTolerance(epsilon float64) CompareOption {
+	return func(o *compareOptions) {
+		o.relTolerance = epsilon
+	}
+}
+
+// WithoutTimestamps ignores the timestamps of the samples, e.g. for
+// collectors exposing the time of the last update of a value.
+func WithoutTimestamps() CompareOption {
+	return func(o *compareOptions) {
+		o.withoutTimestamps = true
+	}
+}
+
+// WithIgnoredLabels removes the labels with the provided names from all
+// metrics before comparing them, e.g. for labels with environment-dependent
+// values like a hostname or a process ID.
+func WithIgnoredLabels(names ...string) CompareOption {
+	return func(o *compareOptions) {
+		if o.ignoredLabels == nil {
+			o.ignoredLabels = map[string]struct{}{}
+		}
+		for _, name := range names {
+			o.ignoredLabels[name] = struct{}{}
+		}
+	}
+}
+
+// WithIgnoredValues ignores the sample values of the metrics with the provided
+// names, so that only the presence of their series is compared. For histograms
+// and summaries, the bucket and quantile layouts are still compared. Without
+// names, the values of all metrics are ignored.
+func WithIgnoredValues(metricNames ...string) CompareOption {
+	return func(o *compareOptions) {
+		if len(metricNames) == 0 {
+			o.ignoreAllValues = true
+			return
+		}
+		if o.ignoredValues == nil {
+			o.ignoredValues = map[string]struct{}{}
+		}
+		for _, name := range metricNames {
+			o.ignoredValues[name] = struct{}{}
+		}
+	}
+}
+
+// WithIgnoredOrder makes the order of the labels in the expected metrics
+// irrelevant. The metrics are always compared sorted by name and labels.
+func WithIgnoredOrder() CompareOption {
+	return func(o *compareOptions) {
+		o.ignoreOrder = true
+	}
+}
+
+// WithTLSConfig sets the TLS configuration of the scrape request of
+// ScrapeAndCompareWithOptions, e.g. with the CA of the target's certificate
+// or a client certificate.
+func WithTLSConfig(cfg *tls.Config) CompareOption {
+	return func(o *compareOptions) {
+		o.tlsConfig = cfg
+	}
+}
+
+// WithBasicAuth makes ScrapeAndCompareWithOptions authenticate the scrape
+// request with HTTP basic authentication.
+func WithBasicAuth(username, password string) CompareOption {
+	return func(o *compareOptions) {
+		o.username, o.password = username, password
+	}
+}
+
+// WithBearerToken makes ScrapeAndCompareWithOptions authenticate the scrape
+// request with the bearer token.
+func WithBearerToken(token string) CompareOption {
+	return WithHeader("Authorization", "Bearer "+token)
+}
+
+// WithHeader adds the header to the scrape request of
+// ScrapeAndCompareWithOptions, e.g. an Accept header to request a specific
+// exposition format.
+func WithHeader(name, value string) CompareOption {
+	return func(o *compareOptions) {
+		o.header.Add(name, value)
+	}
+}
+
+// WithScrapeTimeout sets the timeout of the scrape request of
+// ScrapeAndCompareWithOptions. By default, there is no timeout.
+func WithScrapeTimeout(timeout time.Duration) CompareOption {
+	return func(o *compareOptions) {
+		o.timeout = timeout
+	}
+}
+
+// CollectAndCompareWithOptions is like CollectAndCompare, but configured with
+// CompareOptions.
+func CollectAndCompareWithOptions(c prometheus.Collector, expected io.Reader, opts ...CompareOption) error {
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(c); err != nil {
+		return fmt.Errorf("registering collector failed: %w", err)
+	}
+	return GatherAndCompareWithOptions(reg, expected, opts...)
+}
+
+// GatherAndCompareWithOptions is like GatherAndCompare, but configured with
+// CompareOptions.
+func GatherAndCompareWithOptions(g prometheus.Gatherer, expected io.Reader, opts ...CompareOption) error {
+	got, err := g.Gather()
+	if err != nil {
+		return fmt.Errorf("gathering metrics failed: %w", err)
+	}
+	wanted, err := convertReaderToMetricFamily(expected)
+	if err != nil {
+		return err
+	}
+	return newCompareOptions(opts).compare(got, wanted)
+}
+
+// compare compares got and want like compare, after filtering and aligning
+// them according to the options.
+func (o *compareOptions) compare(got, wanted []*dto.MetricFamily) error {
+	got = o.normalize(got)
+	wanted = o.normalize(wanted)
+	if o.absTolerance > 0 || o.relTolerance > 0 {
+		got = o.applyTolerance(got, wanted)
+	}
+	return compare(got, wanted)
+}
+
+// normalize filters the metric families by name and strips their timestamps
+// according to the options. The provided metric families aren't modified.
+func (o *compareOptions) normalize(mfs []*dto.MetricFamily) []*dto.MetricFamily {
+	if o.metricNames != nil {
+		mfs = filterMetrics(mfs, o.metricNames)
+	}
+	if !o.withoutTimestamps && len(o.ignoredLabels) == 0 && len(o.ignoredValues) == 0 && !o.ignoreAllValues && !o.ignoreOrder {
+		return mfs
+	}
+	result := make([]*dto.MetricFamily, 0, len(mfs))
+	for _, mf := range mfs {
+		mf = proto.Clone(mf).(*dto.MetricFamily)
+		_, maskValues := o.ignoredValues[mf.GetName()]
+		maskValues = maskValues || o.ignoreAllValues
+		for _, m := range mf.GetMetric() {
+			if o.withoutTimestamps {
+				m.TimestampMs = nil
+			}
+			if len(o.ignoredLabels) > 0 {
+				m.Label = slices.DeleteFunc(m.Label, func(lp *dto.LabelPair) bool {
+					_, ignored := o.ignoredLabels[lp.GetName()]
+					return ignored
+				})
+			}
+			if maskValues {
+				maskMetricValues(m)
+			}
+			sort.Sort(internal.LabelPairSorter(m.Label))
+		}
+		sort.Sort(internal.MetricSorter(mf.Metric))
+		result = append(result, mf)
+	}
+	return result
+}
+
+// maskMetricValues sets all sample values of m to zero, keeping the bucket
+// and quantile layout.
+func maskMetricValues(m *dto.Metric) {
+	if m.Gauge != nil {
+		m.Gauge.Value = proto.Float64(0)
+	}
+	if m.Counter != nil {
+		m.Counter.Value = proto.Float64(0)
+		m.Counter.Exemplar = nil
+		m.Counter.CreatedTimestamp = nil
+	}
+	if m.Untyped != nil {
+		m.Untyped.Value = proto.Float64(0)
+	}
+	if s := m.Summary; s != nil {
+		s.SampleCount, s.SampleSum, s.CreatedTimestamp = proto.Uint64(0), proto.Float64(0), nil
+		for _, q := range s.Quantile {
+			q.Value = proto.Float64(0)
+		}
+	}
+	if h := m.Histogram; h != nil {
+		*h = dto.Histogram{
+			SampleCount:   proto.Uint64(0),
+			SampleSum:     proto.Float64(0),
+			Bucket:        h.Bucket,
+			Schema:        h.Schema,
+			ZeroThreshold: h.ZeroThreshold,
+			PositiveSpan:  h.PositiveSpan,
+			NegativeSpan:  h.NegativeSpan,
+		}
+		for _, b := range h.Bucket {
+			*b = dto.Bucket{UpperBound: b.UpperBound, CumulativeCount: proto.Uint64(0)}
+		}
+	}
+}
+
+// applyTolerance returns a copy of got in which all sample values that are
+// within the tolerance of the corresponding values in want are replaced by
+// the values in want, so that only the differing values show up in the diff.
+func (o *compareOptions) applyTolerance(got, want []*dto.MetricFamily) []*dto.MetricFamily {
+	wantMetrics := map[string]*dto.Metric{}
+	for _, mf := range want {
+		for _, m := range mf.GetMetric() {
+			wantMetrics[metricKey(mf, m)] = m
+		}
+	}
+	result := make([]*dto.MetricFamily, 0, len(got))
+	for _, mf := range got {
+		mf = proto.Clone(mf).(*dto.MetricFamily)
+		for _, m := range mf.GetMetric() {
+			if w, ok := wantMetrics[metricKey(mf, m)]; ok {
+				o.alignValues(m, w)
+			}
+		}
+		result = append(result, mf)
+	}
+	return result
+}
+
+func (o *compareOptions) alignValues(got, want *dto.Metric) {
+	if g := got.GetGauge(); g != nil {
+		o.align(g.Value, want.GetGauge().GetValue())
+	}
+	if c := got.GetCounter(); c != nil {
+		o.align(c.Value, want.GetCounter().GetValue())
+	}
+	if u := got.GetUntyped(); u != nil {
+		o.align(u.Value, want.GetUntyped().GetValue())
+	}
+	if s, ws := got.GetSummary(), want.GetSummary(); s != nil && ws != nil {
+		o.align(s.SampleSum, ws.GetSampleSum())
+		for i, q := range s.GetQuantile() {
+			if i < len(ws.GetQuantile()) && q.GetQuantile() == ws.GetQuantile()[i].GetQuantile() {
+				o.align(q.Value, ws.GetQuantile()[i].GetValue())
+			}
+		}
+	}
+	if h, wh := got.GetHistogram(), want.GetHistogram(); h != nil && wh != nil {
+		o.align(h.SampleSum, wh.GetSampleSum())
+		o.align(h.SampleCountFloat, wh.GetSampleCountFloat())
+		for i, b := range h.GetBucket() {
+			if i < len(wh.GetBucket()) && b.GetUpperBound() == wh.GetBucket()[i].GetUpperBound() {
+				o.align(b.CumulativeCountFloat, wh.GetBucket()[i].GetCumulativeCountFloat())
+			}
+		}
+	}
+}
+
+// align sets *got to want if both are within the tolerance. got may be nil.
+func (o *compareOptions) align(got *float64, want float64) {
+	if got == nil || *got == want {
+		return
+	}
+	diff := math.Abs(*got - want)
+	if diff <= o.absTolerance || diff <= o.relTolerance*math.Max(math.Abs(*got), math.Abs(want)) {
+		*got = want
+	}
+}
+
+// metricKey identifies a metric by its family name and labels.
+func metricKey(mf *dto.MetricFamily, m *dto.Metric) string {
+	labels := make([]string, 0, len(m.GetLabel()))
+	for _, lp := range m.GetLabel() {
+		labels = append(labels, lp.GetName()+"\xfe"+lp.GetValue())
+	}
+	sort.Strings(labels)
+	return mf.GetName() + "\xff" + strings.Join(labels, "\xff")
+}
+
 // CollectAndFormat collects the metrics identified by `metricNames` and returns them in the given format.
 func CollectAndFormat(c prometheus.Collector, format expfmt.FormatType, metricNames ...string) ([]byte, error) {
 	reg := prometheus.NewPedanticRegistry()
@@ -262,7 +817,7 @@ func CollectAndFormat(c prometheus.Collector, format expfmt.FormatType, metricNa
 // convertReaderToMetricFamily would read from a io.Reader object and convert it to a slice of
 // dto.MetricFamily.
 func convertReaderToMetricFamily(reader io.Reader) ([]*dto.MetricFamily, error) {
-	var tp expfmt.TextParser
+	tp := expfmt.NewTextParser(model.UTF8Validation)
 	notNormalized, err := tp.TextToMetricFamilies(reader)
 	if err != nil {
 		return nil, fmt.Errorf("converting reader to metric families failed: %w", err)
@@ -297,9 +852,9 @@ func compareMetricFamilies(got, expected []*dto.MetricFamily, metricNames ...str
 }
 
 // compare encodes both provided slices of metric families into the text format,
-// compares their string message, and returns an error if they do not match.
-// The error contains the encoded text of both the desired and the actual
-// result.
+// compares their string message, and returns a *DiffError if they do not
+// match. The error contains the encoded text of both the desired and the
+// actual result.
 func compare(got, want []*dto.MetricFamily) error {
 	var gotBuf, wantBuf bytes.Buffer
 	enc := expfmt.NewEncoder(&gotBuf, expfmt.NewFormat(expfmt.TypeTextPlain).WithEscapingScheme(model.NoEscaping))
@@ -315,7 +870,7 @@ func compare(got, want []*dto.MetricFamily) error {
 		}
 	}
 	if diffErr := diff.Diff(gotBuf.String(), wantBuf.String()); diffErr != "" {
-		return errors.New(diffErr)
+		return &DiffError{Diff: diffMetrics(got, want), text: diffErr}
 	}
 	return nil
 }
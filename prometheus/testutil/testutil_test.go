@@ -14,15 +14,19 @@
 package testutil
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/prometheus/common/expfmt"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 type untypedCollector struct{}
@@ -123,6 +127,48 @@ func TestToFloat64(t *testing.T) {
 	}
 }
 
+func TestToFloat64WithLabels(t *testing.T) {
+	vec := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "g"}, []string{"code", "method"})
+	vec.WithLabelValues("200", "get").Set(1)
+	vec.WithLabelValues("200", "post").Set(2)
+	vec.WithLabelValues("500", "get").Set(3)
+
+	if got := ToFloat64WithLabels(vec, prometheus.Labels{"code": "200", "method": "post"}); got != 2 {
+		t.Errorf("got %g, want 2", got)
+	}
+	if got := ToFloat64WithLabels(vec, prometheus.Labels{"code": "500"}); got != 3 {
+		t.Errorf("got %g, want 3", got)
+	}
+	for _, labels := range []prometheus.Labels{{"code": "200"}, {"code": "404"}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected panic for labels %v", labels)
+				}
+			}()
+			ToFloat64WithLabels(vec, labels)
+		}()
+	}
+
+	want := map[string]float64{
+		`{code="200",method="get"}`:  1,
+		`{code="200",method="post"}`: 2,
+		`{code="500",method="get"}`:  3,
+	}
+	got := ToFloat64Map(vec)
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	}
+	if got := ToFloat64Map(untypedCollector{}); got["{}"] != 2001 {
+		t.Errorf("got %v, want map[{}:2001]", got)
+	}
+}
+
 func TestCollectAndCompare(t *testing.T) {
 	const metadata = `
 		# HELP some_total A value that represents a counter.
@@ -148,6 +194,83 @@ func TestCollectAndCompare(t *testing.T) {
 	}
 }
 
+func TestCollectAndCompareWithTolerance(t *testing.T) {
+	const metadata = `
+		# HELP request_duration_seconds Duration of requests.
+		# TYPE request_duration_seconds summary
+	`
+	s := prometheus.NewSummaryVec(prometheus.SummaryOpts{
+		Name:       "request_duration_seconds",
+		Help:       "Duration of requests.",
+		Objectives: map[float64]float64{0.5: 0.05},
+	}, []string{"code", "method"})
+	s.WithLabelValues("200", "GET").Observe(0.1234)
+
+	expected := `
+		request_duration_seconds{code="200",method="GET",quantile="0.5"} 0.12
+		request_duration_seconds_sum{code="200",method="GET"} 0.12
+		request_duration_seconds_count{code="200",method="GET"} 1
+	`
+	if err := CollectAndCompareWithOptions(s, strings.NewReader(metadata+expected)); err == nil {
+		t.Error("expected error without tolerance")
+	}
+	for name, opt := range map[string]CompareOption{
+		"absolute": WithAbsoluteTolerance(0.01),
+		"relative": WithRelativeTolerance(0.05),
+	} {
+		if err := CollectAndCompareWithOptions(s, strings.NewReader(metadata+expected), opt, WithMetricNames("request_duration_seconds")); err != nil {
+			t.Errorf("%s: unexpected collecting result:\n%s", name, err)
+		}
+	}
+	if err := CollectAndCompareWithOptions(s, strings.NewReader(metadata+expected), WithAbsoluteTolerance(0.001)); err == nil {
+		t.Error("expected error for values outside of the tolerance")
+	}
+}
+
+func TestCollectAndCompareWithIgnoredLabelsAndValues(t *testing.T) {
+	const metadata = `
+		# HELP process_info Information about the process.
+		# TYPE process_info gauge
+		# HELP uptime_seconds Uptime of the process.
+		# TYPE uptime_seconds gauge
+	`
+	reg := prometheus.NewPedanticRegistry()
+	info := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "process_info", Help: "Information about the process."}, []string{"version", "hostname", "pid"})
+	info.WithLabelValues("1.0", "host-1234", "4711").Set(1)
+	uptime := prometheus.NewGauge(prometheus.GaugeOpts{Name: "uptime_seconds", Help: "Uptime of the process."})
+	uptime.Set(123.4)
+	reg.MustRegister(info, uptime)
+
+	expected := `
+		process_info{version="1.0"} 1
+		uptime_seconds 0
+	`
+	if err := GatherAndCompareWithOptions(reg, strings.NewReader(metadata+expected)); err == nil {
+		t.Error("expected error without options")
+	}
+	if err := GatherAndCompareWithOptions(reg, strings.NewReader(metadata+expected),
+		WithIgnoredLabels("hostname", "pid"), WithIgnoredValues("uptime_seconds"),
+	); err != nil {
+		t.Errorf("unexpected comparison result:\n%s", err)
+	}
+	if err := GatherAndCompareWithOptions(reg, strings.NewReader(metadata+`process_info{version="2.0"} 1`),
+		WithIgnoredLabels("hostname", "pid"), WithIgnoredValues(), WithMetricNames("process_info"),
+	); err == nil {
+		t.Error("expected error for different label value")
+	}
+
+	unordered := `
+		process_info{version="1.0",pid="4711",hostname="host-1234"} 1
+		uptime_seconds 123.4
+	`
+	if err := GatherAndCompareWithOptions(reg, strings.NewReader(metadata+unordered)); err == nil {
+		t.Error("expected error for unordered labels")
+	}
+	if err := GatherAndCompareWithOptions(reg, strings.NewReader(metadata+unordered), WithIgnoredOrder()); err != nil {
+		t.Errorf("unexpected comparison result:\n%s", err)
+	}
+}
+
 func TestCollectAndCompareNoLabel(t *testing.T) {
 	const metadata = `
 		# HELP some_total A value that represents a counter.
@@ -347,6 +470,54 @@ func TestScrapeAndCompare(t *testing.T) {
 	}
 }
 
+func TestScrapeAndCompareWithOptions(t *testing.T) {
+	const expected = `
+		# HELP some_total A value that represents a counter.
+		# TYPE some_total counter
+		some_total{label1="value1"} 1
+	`
+	reg := prometheus.NewRegistry()
+	c := prometheus.NewCounter(prometheus.CounterOpts{
+		Name:        "some_total",
+		Help:        "A value that represents a counter.",
+		ConstLabels: prometheus.Labels{"label1": "value1"},
+	})
+	c.Inc()
+	reg.MustRegister(c)
+
+	var contentType string
+	handler := promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+		contentType = w.Header().Get("Content-Type")
+	}))
+	defer ts.Close()
+	tlsConfig := &tls.Config{RootCAs: x509.NewCertPool()}
+	tlsConfig.RootCAs.AddCert(ts.Certificate())
+
+	err := ScrapeAndCompareWithOptions(ts.URL, strings.NewReader(expected),
+		WithTLSConfig(tlsConfig),
+		WithBearerToken("secret"),
+		WithHeader("Accept", `application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited`),
+		WithScrapeTimeout(time.Minute),
+	)
+	if err != nil {
+		t.Errorf("unexpected scraping result:\n%s", err)
+	}
+	if !strings.HasPrefix(contentType, "application/vnd.google.protobuf") {
+		t.Errorf("got content type %q, want protobuf", contentType)
+	}
+
+	err = ScrapeAndCompareWithOptions(ts.URL, strings.NewReader(expected), WithTLSConfig(tlsConfig), WithBasicAuth("user", "wrong"))
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("got error %v, want status code 401", err)
+	}
+}
+
 func TestScrapeAndCompareWithMultipleExpected(t *testing.T) {
 	const expected = `
 		# HELP some_total A value that represents a counter.
@@ -434,6 +605,42 @@ func TestCollectAndCount(t *testing.T) {
 	}
 }
 
+func TestCollectAndAssertAbsent(t *testing.T) {
+	c := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "some_total",
+			Help: "A value that represents a counter.",
+		},
+		[]string{"foo", "bar"},
+	)
+	if err := CollectAndAssertAbsent(c); err != nil {
+		t.Errorf("unexpected error for empty vector: %v", err)
+	}
+	c.WithLabelValues("a", "x").Inc()
+	c.WithLabelValues("b", "x").Inc()
+	if err := CollectAndAssertAbsent(c, "some_total"); err == nil {
+		t.Error("expected error for present metric")
+	}
+	if err := CollectAndAssertAbsent(c, "some_other_total"); err != nil {
+		t.Errorf("unexpected error for other metric: %v", err)
+	}
+
+	if err := CollectAndAssertSeriesAbsent(c, "some_total", prometheus.Labels{"foo": "a"}); err == nil {
+		t.Error("expected error for present series")
+	}
+	if err := CollectAndAssertSeriesAbsent(c, "some_total", prometheus.Labels{"foo": "a", "bar": "y"}); err != nil {
+		t.Errorf("unexpected error for absent series: %v", err)
+	}
+	c.DeleteLabelValues("a", "x")
+	if err := CollectAndAssertSeriesAbsent(c, "some_total", prometheus.Labels{"foo": "a"}); err != nil {
+		t.Errorf("unexpected error for deleted series: %v", err)
+	}
+	err := CollectAndAssertSeriesAbsent(c, "some_total", prometheus.Labels{"bar": "x"})
+	if want := `expected series of some_total with labels map[bar:x] to be absent, but found: {bar="x",foo="b"}`; err == nil || err.Error() != want {
+		t.Errorf("got error %v, want %s", err, want)
+	}
+}
+
 func TestCollectAndFormat(t *testing.T) {
 	const expected = `# HELP foo_bar A value that represents the number of bars in foo.
 # TYPE foo_bar counter
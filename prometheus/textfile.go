@@ -0,0 +1,257 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/expfmt"
+)
+
+const (
+	defaultTextfileInterval = time.Minute
+	textfileSuffix          = ".prom"
+	textfileTempSuffix      = ".tmp"
+)
+
+// TextfileWriterOpts defines the behavior of a TextfileWriter created with
+// NewTextfileWriter.
+type TextfileWriterOpts struct {
+	// Dir is the textfile directory of the node exporter, as configured
+	// with its --collector.textfile.directory flag. Mandatory.
+	Dir string
+	// Name is the name of the file to write in Dir. The ".prom" suffix the
+	// node exporter expects is appended if missing. Mandatory.
+	Name string
+	// Interval is the interval of writing the file. Defaults to 1m.
+	Interval time.Duration
+	// If true, the file is written in the OpenMetrics text format instead
+	// of the Prometheus text format. Only use it if the consumer of the
+	// textfile directory supports OpenMetrics.
+	OpenMetrics bool
+	// StalePattern is an optional glob pattern of other files in Dir that
+	// are deleted once they haven't been modified for StaleAfter, e.g.
+	// "myapp-*.prom" for the files of previous instances of a daemon that
+	// includes its PID in Name. The written file itself is never deleted
+	// this way.
+	StalePattern string
+	// StaleAfter is the age after which files matching StalePattern are
+	// deleted. Defaults to 10 times Interval.
+	StaleAfter time.Duration
+	// If true, the file is kept when Run returns. Otherwise, it is deleted,
+	// so that the metrics of a stopped daemon don't linger.
+	KeepOnStop bool
+	// Registerer, if set, is used to register the following metrics of the
+	// TextfileWriter:
+	//
+	// textfile_writer_errors_total
+	// textfile_writer_last_write_timestamp_seconds
+	//
+	// If the Registerer is the Gatherer the TextfileWriter writes, the
+	// metrics are written, too, each reflecting the previous write.
+	Registerer Registerer
+	// ErrorHandler, if set, is called with every error of writing the file
+	// or cleaning up stale files.
+	ErrorHandler func(error)
+}
+
+// TextfileWriter periodically writes the metrics of a Gatherer to a file in
+// the textfile directory of the node exporter, for host daemons that don't
+// serve metrics via HTTP. Each write goes to a temporary file first, which is
+// then renamed to the final name, so that the node exporter never reads a
+// partially written file. Temporary files left behind by crashed writers are
+// cleaned up. Use NewTextfileWriter to create one and Run to start writing.
+type TextfileWriter struct {
+	g           Gatherer
+	filename    string
+	interval    time.Duration
+	format      expfmt.Format
+	stale       string
+	staleAfter  time.Duration
+	keepOnStop  bool
+	handleError func(error)
+
+	errors    Counter
+	lastWrite Gauge
+}
+
+// NewTextfileWriter returns a TextfileWriter for the provided Gatherer. It
+// returns an error if Dir or Name are missing or StalePattern is malformed, or
+// if registering its metrics fails.
+func NewTextfileWriter(g Gatherer, opts TextfileWriterOpts) (*TextfileWriter, error) {
+	if opts.Dir == "" || opts.Name == "" {
+		return nil, errors.New("textfile directory and name are mandatory")
+	}
+	if _, err := filepath.Match(opts.StalePattern, ""); err != nil {
+		return nil, err
+	}
+	name := opts.Name
+	if !strings.HasSuffix(name, textfileSuffix) {
+		name += textfileSuffix
+	}
+	w := &TextfileWriter{
+		g:           g,
+		filename:    filepath.Join(opts.Dir, name),
+		interval:    opts.Interval,
+		format:      expfmt.NewFormat(expfmt.TypeTextPlain),
+		stale:       opts.StalePattern,
+		staleAfter:  opts.StaleAfter,
+		keepOnStop:  opts.KeepOnStop,
+		handleError: opts.ErrorHandler,
+		errors: NewCounter(CounterOpts{
+			Name: "textfile_writer_errors_total",
+			Help: "Total number of errors writing the textfile.",
+		}),
+		lastWrite: NewGauge(GaugeOpts{
+			Name: "textfile_writer_last_write_timestamp_seconds",
+			Help: "Unix timestamp of the last successful write of the textfile.",
+		}),
+	}
+	if w.interval <= 0 {
+		w.interval = defaultTextfileInterval
+	}
+	if w.staleAfter <= 0 {
+		w.staleAfter = 10 * w.interval
+	}
+	if opts.OpenMetrics {
+		w.format = expfmt.NewFormat(expfmt.TypeOpenMetrics)
+	}
+	if opts.Registerer != nil {
+		for _, c := range []Collector{w.errors, w.lastWrite} {
+			if err := opts.Registerer.Register(c); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return w, nil
+}
+
+// Run writes the file right away and then every Interval until ctx is done.
+// Unless KeepOnStop is set, it deletes the file before returning.
+func (w *TextfileWriter) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		if err := w.Write(); err != nil && w.handleError != nil {
+			w.handleError(err)
+		}
+		w.cleanup()
+		select {
+		case <-ctx.Done():
+			if !w.keepOnStop {
+				if err := os.Remove(w.filename); err != nil && !os.IsNotExist(err) && w.handleError != nil {
+					w.handleError(err)
+				}
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Write writes the file once. If gathering fails, the previous file is kept.
+func (w *TextfileWriter) Write() error {
+	if err := writeTextfile(w.filename, w.g, w.format); err != nil {
+		w.errors.Inc()
+		return err
+	}
+	w.lastWrite.SetToCurrentTime()
+	return nil
+}
+
+// cleanup deletes temporary files of crashed writes and stale files matching
+// the stale pattern.
+func (w *TextfileWriter) cleanup() {
+	dir, base := filepath.Dir(w.filename), filepath.Base(w.filename)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if w.handleError != nil {
+			w.handleError(err)
+		}
+		return
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || name == base {
+			continue
+		}
+		temp := strings.HasPrefix(name, "."+base+".") && strings.HasSuffix(name, textfileTempSuffix)
+		stale := false
+		if w.stale != "" {
+			stale, _ = filepath.Match(w.stale, name)
+		}
+		if !temp && !stale {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || time.Since(info.ModTime()) < w.staleAfter {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, name)); err != nil && !os.IsNotExist(err) && w.handleError != nil {
+			w.handleError(err)
+		}
+	}
+}
+
+// writeTextfile gathers from g and writes the result in the provided format to
+// a temporary file, which is renamed to filename upon success. The temporary
+// file is hidden and not suffixed with ".prom", so that the node exporter
+// ignores it.
+func writeTextfile(filename string, g Gatherer, format expfmt.Format) error {
+	dir, base := filepath.Dir(filename), filepath.Base(filename)
+	tmp, err := os.CreateTemp(dir, "."+base+".*"+textfileTempSuffix)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	enc := expfmt.NewEncoder(tmp, format)
+	if sg, ok := g.(StreamingGatherer); ok {
+		if err := sg.GatherFunc(enc.Encode); err != nil {
+			return err
+		}
+	} else {
+		mfs, err := g.Gather()
+		if err != nil {
+			return err
+		}
+		for _, mf := range mfs {
+			if err := enc.Encode(mf); err != nil {
+				return err
+			}
+		}
+	}
+	if closer, ok := enc.(expfmt.Closer); ok {
+		if err := closer.Close(); err != nil {
+			return err
+		}
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
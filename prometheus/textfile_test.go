@@ -0,0 +1,128 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestTextfileWriter(t *testing.T) {
+	dir := t.TempDir()
+	reg := NewRegistry()
+	c := NewCounter(CounterOpts{Name: "jobs_total", Help: "Jobs done."})
+	c.Add(3)
+	reg.MustRegister(c)
+
+	// A leftover temporary file and a stale file of a previous instance.
+	old := time.Now().Add(-time.Hour)
+	for _, name := range []string{".daemon-1.prom.123.tmp", "daemon-0.prom", "other.prom"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w, err := NewTextfileWriter(reg, TextfileWriterOpts{
+		Dir:          dir,
+		Name:         "daemon-1",
+		Interval:     time.Minute,
+		OpenMetrics:  true,
+		StalePattern: "daemon-*.prom",
+		Registerer:   reg,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(done)
+	}()
+
+	filename := filepath.Join(dir, "daemon-1.prom")
+	var content []byte
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		if content, err = os.ReadFile(filename); err == nil {
+			break
+		}
+	}
+	for _, want := range []string{"jobs_total 3.0\n", "# EOF\n"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("file lacks %q:\n%s", want, content)
+		}
+	}
+
+	cancel()
+	<-done
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "other.prom" {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("got files %v after stopping, want only other.prom", names)
+	}
+}
+
+func TestTextfileWriterKeepsFileOnError(t *testing.T) {
+	dir := t.TempDir()
+	fail := false
+	g := GathererFunc(func() ([]*dto.MetricFamily, error) {
+		if fail {
+			return nil, errors.New("gathering failed")
+		}
+		return nil, nil
+	})
+	reg := NewRegistry()
+	w, err := NewTextfileWriter(g, TextfileWriterOpts{Dir: dir, Name: "x.prom", Registerer: reg})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(); err != nil {
+		t.Fatal(err)
+	}
+	fail = true
+	if err := w.Write(); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "x.prom")); err != nil {
+		t.Errorf("file of the previous write is gone: %v", err)
+	}
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := mfs[0].GetMetric()[0].GetCounter().GetValue(); mfs[0].GetName() != "textfile_writer_errors_total" || got != 1 {
+		t.Errorf("got %s %v, want textfile_writer_errors_total 1", mfs[0].GetName(), got)
+	}
+
+	if _, err := NewTextfileWriter(g, TextfileWriterOpts{Dir: dir}); err == nil {
+		t.Error("expected error for missing name")
+	}
+}
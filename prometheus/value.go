@@ -95,6 +95,15 @@ func (v *valueFunc) Write(out *dto.Metric) error {
 	return populateMetric(v.valType, v.function(), v.labelPairs, nil, out, nil)
 }
 
+func (v *valueFunc) appendProto(b []byte) ([]byte, dto.MetricType, []*dto.LabelPair, bool) {
+	if v.valType != CounterValue && v.valType != GaugeValue && v.valType != UntypedValue {
+		// Leave reporting the error to Write.
+		return b, 0, nil, false
+	}
+	b, t, ok := appendValueProto(b, v.valType, v.function(), nil)
+	return b, t, v.labelPairs, ok
+}
+
 // NewConstMetric returns a metric with one fixed value that cannot be
 // changed. Users of this package will not have much use for it in regular
 // operations. However, when implementing custom Collectors, it is useful as a
@@ -168,6 +177,148 @@ func MustNewConstMetricWithCreatedTimestamp(desc *Desc, valueType ValueType, val
 	return m
 }
 
+// ConstMetricTemplate creates const metrics with one ValueType for one Desc,
+// like NewConstMetric. The Desc and the ValueType are validated, and the order
+// of the label pairs is determined, only once when creating the template, so
+// that creating a metric merely validates the label values and stores them
+// with the value. The label pairs are only created when the metric is written.
+// This is meant for custom Collectors that create many const metrics of the
+// same Desc on each collection. Create instances with NewConstMetricTemplate.
+// A ConstMetricTemplate is safe for concurrent use.
+type ConstMetricTemplate struct {
+	desc      *Desc
+	valueType ValueType
+	// labelPairs holds the label pairs of the metrics sorted by name, with
+	// nil in place of the variable labels.
+	labelPairs []*dto.LabelPair
+	// variableIdxs holds the index in labelPairs of each variable label,
+	// and variableNames its name.
+	variableIdxs  []int
+	variableNames []*string
+}
+
+// NewConstMetricTemplate returns a ConstMetricTemplate for metrics of the
+// provided Desc and ValueType. It returns an error if the Desc is invalid or
+// the ValueType is unknown.
+func NewConstMetricTemplate(desc *Desc, valueType ValueType) (*ConstMetricTemplate, error) {
+	if desc.err != nil {
+		return nil, desc.err
+	}
+	switch valueType {
+	case CounterValue, GaugeValue, UntypedValue:
+	default:
+		return nil, fmt.Errorf("encountered unknown type %v", valueType)
+	}
+
+	t := &ConstMetricTemplate{desc: desc, valueType: valueType}
+	if len(desc.variableLabels.names) == 0 {
+		t.labelPairs = MakeLabelPairs(desc, nil)
+		return t, nil
+	}
+	// Sort the label pairs like MakeLabelPairs, keeping track of where the
+	// variable labels end up.
+	type indexedLabelPair struct {
+		*dto.LabelPair
+		variableIdx int // -1 for const labels.
+	}
+	pairs := make([]indexedLabelPair, 0, len(desc.variableLabels.names)+len(desc.constLabelPairs))
+	for i, l := range desc.variableLabels.names {
+		pairs = append(pairs, indexedLabelPair{&dto.LabelPair{Name: proto.String(l)}, i})
+	}
+	for _, lp := range desc.constLabelPairs {
+		pairs = append(pairs, indexedLabelPair{lp, -1})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].GetName() < pairs[j].GetName()
+	})
+	t.labelPairs = make([]*dto.LabelPair, len(pairs))
+	t.variableIdxs = make([]int, len(desc.variableLabels.names))
+	t.variableNames = make([]*string, len(desc.variableLabels.names))
+	for i, lp := range pairs {
+		if lp.variableIdx < 0 {
+			t.labelPairs[i] = lp.LabelPair
+			continue
+		}
+		t.variableIdxs[lp.variableIdx] = i
+		t.variableNames[lp.variableIdx] = lp.Name
+	}
+	return t, nil
+}
+
+// MustNewConstMetricTemplate is a version of NewConstMetricTemplate that
+// panics where NewConstMetricTemplate would have returned an error.
+func MustNewConstMetricTemplate(desc *Desc, valueType ValueType) *ConstMetricTemplate {
+	t, err := NewConstMetricTemplate(desc, valueType)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// New returns a metric with the value and label values like NewConstMetric
+// with the Desc and ValueType of the template. It returns an error if the
+// length of labelValues is not consistent with the variable labels in the Desc
+// or a label value is not valid UTF-8.
+func (t *ConstMetricTemplate) New(value float64, labelValues ...string) (Metric, error) {
+	if err := validateLabelValues(labelValues, len(t.variableIdxs)); err != nil {
+		return nil, err
+	}
+	return &templateMetric{
+		template: t,
+		value:    value,
+		// Copy the label values, which the caller might reuse.
+		labelValues: append([]string(nil), labelValues...),
+	}, nil
+}
+
+// MustNew is a version of New that panics where New would have returned an
+// error.
+func (t *ConstMetricTemplate) MustNew(value float64, labelValues ...string) Metric {
+	m, err := t.New(value, labelValues...)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// templateMetric is a const metric created by a ConstMetricTemplate.
+type templateMetric struct {
+	template    *ConstMetricTemplate
+	value       float64
+	labelValues []string
+}
+
+func (m *templateMetric) Desc() *Desc {
+	return m.template.desc
+}
+
+func (m *templateMetric) Write(out *dto.Metric) error {
+	return populateMetric(m.template.valueType, m.value, m.labelPairs(), nil, out, nil)
+}
+
+func (m *templateMetric) appendProto(b []byte) ([]byte, dto.MetricType, []*dto.LabelPair, bool) {
+	b, t, ok := appendValueProto(b, m.template.valueType, m.value, nil)
+	return b, t, m.labelPairs(), ok
+}
+
+// labelPairs returns the label pairs of m like MakeLabelPairs, allocating the
+// label pairs of all variable labels at once.
+func (m *templateMetric) labelPairs() []*dto.LabelPair {
+	t := m.template
+	if len(t.variableIdxs) == 0 {
+		return t.labelPairs
+	}
+	labelPairs := make([]*dto.LabelPair, len(t.labelPairs))
+	copy(labelPairs, t.labelPairs)
+	variableLabelPairs := make([]dto.LabelPair, len(t.variableIdxs))
+	for i, idx := range t.variableIdxs {
+		lp := &variableLabelPairs[i]
+		lp.Name, lp.Value = t.variableNames[i], &m.labelValues[i]
+		labelPairs[idx] = lp
+	}
+	return labelPairs
+}
+
 type constMetric struct {
 	desc   *Desc
 	metric *dto.Metric
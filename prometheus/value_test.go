@@ -14,10 +14,12 @@
 package prometheus
 
 import (
+	"errors"
 	"testing"
 	"time"
 
 	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -108,3 +110,66 @@ func TestNewConstMetricWithCreatedTimestamp(t *testing.T) {
 		})
 	}
 }
+
+func TestConstMetricTemplate(t *testing.T) {
+	for _, s := range []struct {
+		name        string
+		desc        *Desc
+		labelValues []string
+	}{
+		{"no labels", NewDesc("a", "A.", nil, nil), nil},
+		{"const labels", NewDesc("a", "A.", nil, Labels{"b": "1", "a": "2"}), nil},
+		{"variable labels", NewDesc("a", "A.", []string{"c", "a", "b"}, nil), []string{"1", "2", "3"}},
+		{"mixed labels", NewDesc("a", "A.", []string{"d", "b"}, Labels{"e": "1", "c": "2", "a": "3"}), []string{"4", "5"}},
+	} {
+		t.Run(s.name, func(t *testing.T) {
+			for _, valueType := range []ValueType{CounterValue, GaugeValue, UntypedValue} {
+				template := MustNewConstMetricTemplate(s.desc, valueType)
+				labelValues := append([]string(nil), s.labelValues...)
+				m := template.MustNew(42, labelValues...)
+				if len(labelValues) > 0 {
+					// The metric must not be affected by reusing
+					// the label values.
+					labelValues[0] = "changed"
+				}
+				if m.Desc() != s.desc {
+					t.Errorf("got Desc %v, want %v", m.Desc(), s.desc)
+				}
+				var got, want dto.Metric
+				if err := m.Write(&got); err != nil {
+					t.Fatal(err)
+				}
+				if err := MustNewConstMetric(s.desc, valueType, 42, s.labelValues...).Write(&want); err != nil {
+					t.Fatal(err)
+				}
+				if !proto.Equal(&got, &want) {
+					t.Errorf("got %v, want %v", &got, &want)
+				}
+			}
+		})
+	}
+}
+
+func TestConstMetricTemplateErrors(t *testing.T) {
+	invalidDesc := NewInvalidDesc(errors.New("invalid"))
+	if _, err := NewConstMetricTemplate(invalidDesc, GaugeValue); err == nil {
+		t.Error("expected error for invalid Desc")
+	}
+	desc := NewDesc("a", "A.", []string{"a"}, nil)
+	if _, err := NewConstMetricTemplate(desc, ValueType(42)); err == nil {
+		t.Error("expected error for unknown value type")
+	}
+	expectPanic(t, func() {
+		MustNewConstMetricTemplate(desc, ValueType(42))
+	}, "expected panic for unknown value type")
+
+	template := MustNewConstMetricTemplate(desc, GaugeValue)
+	for _, labelValues := range [][]string{nil, {"1", "2"}, {"\xFF"}} {
+		if _, err := template.New(1, labelValues...); err == nil {
+			t.Errorf("expected error for label values %q", labelValues)
+		}
+		expectPanic(t, func() {
+			template.MustNew(1, labelValues...)
+		}, "expected panic for invalid label values")
+	}
+}
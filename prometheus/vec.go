@@ -16,6 +16,8 @@ package prometheus
 import (
 	"fmt"
 	"sync"
+	"sync/atomic"
+	"unique"
 
 	"github.com/prometheus/common/model"
 )
@@ -37,6 +39,11 @@ type MetricVec struct {
 	*metricMap
 
 	curry []curriedLabelValue
+	// curriedPrefix is the number of leading variable labels that are all
+	// curried, and curriedPrefixHash is the hash of their values, so that
+	// lookups in a curried vector don't have to hash them over and over.
+	curriedPrefix     int
+	curriedPrefixHash uint64
 
 	// hashAdd and hashAddByte can be replaced for testing collision handling.
 	hashAdd     func(h uint64, s string) uint64
@@ -45,12 +52,13 @@ type MetricVec struct {
 
 // NewMetricVec returns an initialized metricVec.
 func NewMetricVec(desc *Desc, newMetric func(lvs ...string) Metric) *MetricVec {
+	m := &metricMap{
+		desc:      desc,
+		newMetric: newMetric,
+	}
+	m.shards.Store(&[]metricMapShard{{}})
 	return &MetricVec{
-		metricMap: &metricMap{
-			metrics:   map[uint64][]metricWithLabelValues{},
-			desc:      desc,
-			newMetric: newMetric,
-		},
+		metricMap:   m,
 		hashAdd:     hashAdd,
 		hashAddByte: hashAddByte,
 	}
@@ -72,7 +80,8 @@ func NewMetricVec(desc *Desc, newMetric func(lvs ...string) Metric) *MetricVec {
 // with a performance overhead (for creating and processing the Labels map).
 // See also the CounterVec example.
 func (m *MetricVec) DeleteLabelValues(lvs ...string) bool {
-	lvs = constrainLabelValues(m.desc, lvs, m.curry)
+	var buf [maxStackLabelValues]string
+	lvs = constrainLabelValues(m.desc, lvs, m.curry, buf[:])
 
 	h, err := m.hashLabelValues(lvs)
 	if err != nil {
@@ -93,8 +102,10 @@ func (m *MetricVec) DeleteLabelValues(lvs ...string) bool {
 // This method is used for the same purpose as DeleteLabelValues(...string). See
 // there for pros and cons of the two methods.
 func (m *MetricVec) Delete(labels Labels) bool {
-	labels, closer := constrainLabels(m.desc, labels)
-	defer closer()
+	if constrained := constrainLabels(m.desc, labels); constrained != nil {
+		defer releaseLabels(constrained)
+		labels = constrained
+	}
 
 	h, err := m.hashLabels(labels)
 	if err != nil {
@@ -111,8 +122,10 @@ func (m *MetricVec) Delete(labels Labels) bool {
 // Note that curried labels will never be matched if deleting from the curried vector.
 // To match curried labels with DeletePartialMatch, it must be called on the base vector.
 func (m *MetricVec) DeletePartialMatch(labels Labels) int {
-	labels, closer := constrainLabels(m.desc, labels)
-	defer closer()
+	if constrained := constrainLabels(m.desc, labels); constrained != nil {
+		defer releaseLabels(constrained)
+		labels = constrained
+	}
 
 	return m.metricMap.deleteByLabels(labels, m.curry)
 }
@@ -174,12 +187,22 @@ func (m *MetricVec) CurryWith(labels Labels) (*MetricVec, error) {
 		return nil, fmt.Errorf("%d unknown label(s) found during currying", l)
 	}
 
-	return &MetricVec{
+	vec := &MetricVec{
 		metricMap:   m.metricMap,
 		curry:       newCurry,
 		hashAdd:     m.hashAdd,
 		hashAddByte: m.hashAddByte,
-	}, nil
+	}
+	vec.curriedPrefixHash = hashNew()
+	for _, c := range newCurry {
+		if c.index != vec.curriedPrefix {
+			break
+		}
+		vec.curriedPrefixHash = vec.hashAdd(vec.curriedPrefixHash, c.value)
+		vec.curriedPrefixHash = vec.hashAddByte(vec.curriedPrefixHash, model.SeparatorByte)
+		vec.curriedPrefix++
+	}
+	return vec, nil
 }
 
 // GetMetricWithLabelValues returns the Metric for the given slice of label
@@ -210,7 +233,8 @@ func (m *MetricVec) CurryWith(labels Labels) (*MetricVec, error) {
 // a wrapper around MetricVec, implementing a vector for a specific Metric
 // implementation, for example GaugeVec.
 func (m *MetricVec) GetMetricWithLabelValues(lvs ...string) (Metric, error) {
-	lvs = constrainLabelValues(m.desc, lvs, m.curry)
+	var buf [maxStackLabelValues]string
+	lvs = constrainLabelValues(m.desc, lvs, m.curry, buf[:])
 	h, err := m.hashLabelValues(lvs)
 	if err != nil {
 		return nil, err
@@ -219,6 +243,32 @@ func (m *MetricVec) GetMetricWithLabelValues(lvs ...string) (Metric, error) {
 	return m.metricMap.getOrCreateMetricWithLabelValues(h, lvs, m.curry), nil
 }
 
+// GetMetricWithLabelValuesBatch works as GetMetricWithLabelValues for each of
+// the provided slices of label values and returns the Metrics in the same
+// order. Unlike repeated calls of GetMetricWithLabelValues, it acquires the
+// locks of the MetricVec only once for all of them, which makes pre-creating
+// many Metrics, e.g. at startup, considerably faster. If any of the slices of
+// label values is invalid, an error naming its index is returned, and no Metric
+// is created at all.
+//
+// Note that GetMetricWithLabelValuesBatch is usually not called directly but
+// through a wrapper around MetricVec, implementing a vector for a specific
+// Metric implementation, for example GaugeVec.
+func (m *MetricVec) GetMetricWithLabelValuesBatch(lvss [][]string) ([]Metric, error) {
+	hashes := make([]uint64, len(lvss))
+	constrained := make([][]string, len(lvss))
+	for i, lvs := range lvss {
+		lvs = constrainLabelValues(m.desc, lvs, m.curry, nil)
+		h, err := m.hashLabelValues(lvs)
+		if err != nil {
+			return nil, fmt.Errorf("label values at index %d: %w", i, err)
+		}
+		hashes[i] = h
+		constrained[i] = lvs
+	}
+	return m.metricMap.getOrCreateMetricsWithLabelValues(hashes, constrained, m.curry), nil
+}
+
 // GetMetricWith returns the Metric for the given Labels map (the label names
 // must match those of the variable labels in Desc). If that label map is
 // accessed for the first time, a new Metric is created. Implications of
@@ -236,8 +286,10 @@ func (m *MetricVec) GetMetricWithLabelValues(lvs ...string) (Metric, error) {
 // around MetricVec, implementing a vector for a specific Metric implementation,
 // for example GaugeVec.
 func (m *MetricVec) GetMetricWith(labels Labels) (Metric, error) {
-	labels, closer := constrainLabels(m.desc, labels)
-	defer closer()
+	if constrained := constrainLabels(m.desc, labels); constrained != nil {
+		defer releaseLabels(constrained)
+		labels = constrained
+	}
 
 	h, err := m.hashLabels(labels)
 	if err != nil {
@@ -257,7 +309,10 @@ func (m *MetricVec) hashLabelValues(vals []string) (uint64, error) {
 		curry         = m.curry
 		iVals, iCurry int
 	)
-	for i := 0; i < len(m.desc.variableLabels.names); i++ {
+	if m.curriedPrefix > 0 {
+		h, iCurry = m.curriedPrefixHash, m.curriedPrefix
+	}
+	for i := iCurry; i < len(m.desc.variableLabels.names); i++ {
 		if iCurry < len(curry) && curry[iCurry].index == i {
 			h = m.hashAdd(h, curry[iCurry].value)
 			iCurry++
@@ -280,7 +335,16 @@ func (m *MetricVec) hashLabels(labels Labels) (uint64, error) {
 		curry  = m.curry
 		iCurry int
 	)
-	for i, labelName := range m.desc.variableLabels.names {
+	if m.curriedPrefix > 0 {
+		for _, labelName := range m.desc.variableLabels.names[:m.curriedPrefix] {
+			if _, ok := labels[labelName]; ok {
+				return 0, fmt.Errorf("label name %q is already curried", labelName)
+			}
+		}
+		h, iCurry = m.curriedPrefixHash, m.curriedPrefix
+	}
+	for i := iCurry; i < len(m.desc.variableLabels.names); i++ {
+		labelName := m.desc.variableLabels.names[i]
 		val, ok := labels[labelName]
 		if iCurry < len(curry) && curry[iCurry].index == i {
 			if ok {
@@ -304,6 +368,10 @@ func (m *MetricVec) hashLabels(labels Labels) (uint64, error) {
 type metricWithLabelValues struct {
 	values []string
 	metric Metric
+	// handles holds the handles of the interned values, if label values
+	// are interned. The interned values are only kept as long as their
+	// handles are reachable.
+	handles []unique.Handle[string]
 }
 
 // curriedLabelValue sets the curried value for a label at the given index.
@@ -312,13 +380,121 @@ type curriedLabelValue struct {
 	value string
 }
 
+// numMetricMapShards is the number of shards of a metricMap holding at least
+// metricMapShardingThreshold metrics. It must be a power of two.
+const numMetricMapShards = 16
+
+// metricMapShardingThreshold is the number of hash buckets from which on a
+// metricMap spreads its metrics over numMetricMapShards shards. Smaller
+// metricMaps keep their metrics in a single shard, as the shards would cost
+// most vecs, which only hold a few metrics, more memory than their metrics.
+const metricMapShardingThreshold = 16
+
 // metricMap is a helper for metricVec and shared between differently curried
-// metricVecs.
+// metricVecs. Once it holds enough metrics, the metrics are distributed over
+// shards by their hash, each with its own mutex, so that concurrent access to
+// different metrics of a hot vec scales with the number of cores rather than
+// contending on a single mutex.
 type metricMap struct {
-	mtx       sync.RWMutex // Protects metrics.
-	metrics   map[uint64][]metricWithLabelValues
+	// shards holds a single shard at first and numMetricMapShards shards
+	// once the metricMap is resharded, see reshard.
+	shards    atomic.Pointer[[]metricMapShard]
 	desc      *Desc
 	newMetric func(labelValues ...string) Metric
+	// internLabelValues is set upon creation of the vector and never
+	// changed afterwards.
+	internLabelValues bool
+}
+
+// metricMapShard holds the metrics of a metricMap whose hash maps to the
+// shard. The padding keeps the mutexes of adjacent shards off the same cache
+// line.
+type metricMapShard struct {
+	mtx     sync.RWMutex // Protects metrics and resharded.
+	metrics map[uint64][]metricWithLabelValues
+	// resharded is set once the metrics of the shard have been moved to
+	// new shards. A resharded shard must not be used anymore.
+	resharded bool
+	_         cacheLinePad
+}
+
+// cacheLinePad pads a struct to prevent false sharing with the memory that
+// follows it, like cpu.CacheLinePad.
+type cacheLinePad struct{ _ [64]byte }
+
+// lockShard returns the shard of the metrics with hash h, locked for writing.
+func (m *metricMap) lockShard(h uint64) *metricMapShard {
+	for {
+		shards := *m.shards.Load()
+		s := &shards[h&uint64(len(shards)-1)]
+		s.mtx.Lock()
+		if !s.resharded {
+			return s
+		}
+		s.mtx.Unlock()
+	}
+}
+
+// rLockShard returns the shard of the metrics with hash h, locked for reading.
+func (m *metricMap) rLockShard(h uint64) *metricMapShard {
+	for {
+		shards := *m.shards.Load()
+		s := &shards[h&uint64(len(shards)-1)]
+		s.mtx.RLock()
+		if !s.resharded {
+			return s
+		}
+		s.mtx.RUnlock()
+	}
+}
+
+// rangeShards calls f for each shard of m while holding its mutex, for writing
+// if write is true and for reading otherwise.
+func (m *metricMap) rangeShards(write bool, f func(s *metricMapShard)) {
+	shards := *m.shards.Load()
+	for i := 0; i < len(shards); i++ {
+		s := &shards[i]
+		if write {
+			s.mtx.Lock()
+		} else {
+			s.mtx.RLock()
+		}
+		resharded := s.resharded
+		if !resharded {
+			f(s)
+		}
+		if write {
+			s.mtx.Unlock()
+		} else {
+			s.mtx.RUnlock()
+		}
+		if resharded {
+			// Only the single initial shard is ever resharded, so f
+			// hasn't been called yet. Start over with the new shards.
+			shards = *m.shards.Load()
+			i = -1
+		}
+	}
+}
+
+// reshard spreads the metrics of s over numMetricMapShards new shards if s is
+// the single shard of m and holds at least metricMapShardingThreshold hash
+// buckets. Must be called while holding the mutex of s.
+func (m *metricMap) reshard(s *metricMapShard) {
+	if len(*m.shards.Load()) > 1 || len(s.metrics) < metricMapShardingThreshold {
+		return
+	}
+	shards := make([]metricMapShard, numMetricMapShards)
+	for h, metrics := range s.metrics {
+		shard := &shards[h&(numMetricMapShards-1)]
+		if shard.metrics == nil {
+			shard.metrics = map[uint64][]metricWithLabelValues{}
+		}
+		shard.metrics[h] = metrics
+	}
+	m.shards.Store(&shards)
+	s.metrics = nil
+	s.resharded = true
 }
 
 // Describe implements Collector. It will send exactly one Desc to the provided
@@ -329,24 +505,20 @@ func (m *metricMap) Describe(ch chan<- *Desc) {
 
 // Collect implements Collector.
 func (m *metricMap) Collect(ch chan<- Metric) {
-	m.mtx.RLock()
-	defer m.mtx.RUnlock()
-
-	for _, metrics := range m.metrics {
-		for _, metric := range metrics {
-			ch <- metric.metric
+	m.rangeShards(false, func(s *metricMapShard) {
+		for _, metrics := range s.metrics {
+			for _, metric := range metrics {
+				ch <- metric.metric
+			}
 		}
-	}
+	})
 }
 
 // Reset deletes all metrics in this vector.
 func (m *metricMap) Reset() {
-	m.mtx.Lock()
-	defer m.mtx.Unlock()
-
-	for h := range m.metrics {
-		delete(m.metrics, h)
-	}
+	m.rangeShards(true, func(s *metricMapShard) {
+		clear(s.metrics)
+	})
 }
 
 // deleteByHashWithLabelValues removes the metric from the hash bucket h. If
@@ -355,10 +527,10 @@ func (m *metricMap) Reset() {
 func (m *metricMap) deleteByHashWithLabelValues(
 	h uint64, lvs []string, curry []curriedLabelValue,
 ) bool {
-	m.mtx.Lock()
-	defer m.mtx.Unlock()
+	s := m.lockShard(h)
+	defer s.mtx.Unlock()
 
-	metrics, ok := m.metrics[h]
+	metrics, ok := s.metrics[h]
 	if !ok {
 		return false
 	}
@@ -368,13 +540,7 @@ func (m *metricMap) deleteByHashWithLabelValues(
 		return false
 	}
 
-	if len(metrics) > 1 {
-		old := metrics
-		m.metrics[h] = append(metrics[:i], metrics[i+1:]...)
-		old[len(old)-1] = metricWithLabelValues{}
-	} else {
-		delete(m.metrics, h)
-	}
+	s.deleteFromBucket(h, metrics, i)
 	return true
 }
 
@@ -384,10 +550,10 @@ func (m *metricMap) deleteByHashWithLabelValues(
 func (m *metricMap) deleteByHashWithLabels(
 	h uint64, labels Labels, curry []curriedLabelValue,
 ) bool {
-	m.mtx.Lock()
-	defer m.mtx.Unlock()
+	s := m.lockShard(h)
+	defer s.mtx.Unlock()
 
-	metrics, ok := m.metrics[h]
+	metrics, ok := s.metrics[h]
 	if !ok {
 		return false
 	}
@@ -396,32 +562,37 @@ func (m *metricMap) deleteByHashWithLabels(
 		return false
 	}
 
+	s.deleteFromBucket(h, metrics, i)
+	return true
+}
+
+// deleteFromBucket removes the metric at index i from the hash bucket h, which
+// holds metrics. Must be called while holding the mutex.
+func (s *metricMapShard) deleteFromBucket(h uint64, metrics []metricWithLabelValues, i int) {
 	if len(metrics) > 1 {
 		old := metrics
-		m.metrics[h] = append(metrics[:i], metrics[i+1:]...)
+		s.metrics[h] = append(metrics[:i], metrics[i+1:]...)
 		old[len(old)-1] = metricWithLabelValues{}
 	} else {
-		delete(m.metrics, h)
+		delete(s.metrics, h)
 	}
-	return true
 }
 
 // deleteByLabels deletes a metric if the given labels are present in the metric.
 func (m *metricMap) deleteByLabels(labels Labels, curry []curriedLabelValue) int {
-	m.mtx.Lock()
-	defer m.mtx.Unlock()
-
 	var numDeleted int
 
-	for h, metrics := range m.metrics {
-		i := findMetricWithPartialLabels(m.desc, metrics, labels, curry)
-		if i >= len(metrics) {
-			// Didn't find matching labels in this metric slice.
-			continue
+	m.rangeShards(true, func(s *metricMapShard) {
+		for h, metrics := range s.metrics {
+			i := findMetricWithPartialLabels(m.desc, metrics, labels, curry)
+			if i >= len(metrics) {
+				// Didn't find matching labels in this metric slice.
+				continue
+			}
+			delete(s.metrics, h)
+			numDeleted++
 		}
-		delete(m.metrics, h)
-		numDeleted++
-	}
+	})
 
 	return numDeleted
 }
@@ -485,24 +656,77 @@ func matchPartialLabels(desc *Desc, values []string, labels Labels, curry []curr
 // getOrCreateMetricWithLabelValues retrieves the metric by hash and label value
 // or creates it and returns the new one.
 //
-// This function holds the mutex.
+// This function holds the mutex of the shard of the hash.
 func (m *metricMap) getOrCreateMetricWithLabelValues(
 	hash uint64, lvs []string, curry []curriedLabelValue,
 ) Metric {
-	m.mtx.RLock()
-	metric, ok := m.getMetricWithHashAndLabelValues(hash, lvs, curry)
-	m.mtx.RUnlock()
+	s := m.rLockShard(hash)
+	metric, ok := s.getMetricWithHashAndLabelValues(hash, lvs, curry)
+	s.mtx.RUnlock()
 	if ok {
 		return metric
 	}
 
-	m.mtx.Lock()
-	defer m.mtx.Unlock()
-	metric, ok = m.getMetricWithHashAndLabelValues(hash, lvs, curry)
+	s = m.lockShard(hash)
+	defer s.mtx.Unlock()
+	metric = m.getOrCreateMetricWithLabelValuesLocked(s, hash, lvs, curry)
+	m.reshard(s)
+	return metric
+}
+
+// getOrCreateMetricsWithLabelValues works as getOrCreateMetricWithLabelValues
+// for each of the provided hashes and label values, but acquires the mutex of
+// each shard only once.
+func (m *metricMap) getOrCreateMetricsWithLabelValues(
+	hashes []uint64, lvss [][]string, curry []curriedLabelValue,
+) []Metric {
+	var (
+		metrics = make([]Metric, len(hashes))
+		shards  = *m.shards.Load()
+		byShard = make([][]int, len(shards)) // Indexes into hashes by shard.
+	)
+	for i, h := range hashes {
+		si := h & uint64(len(shards)-1)
+		byShard[si] = append(byShard[si], i)
+	}
+	for si, is := range byShard {
+		if len(is) == 0 {
+			continue
+		}
+		s := &shards[si]
+		s.mtx.Lock()
+		if s.resharded {
+			// Resharded concurrently, look up the new shards one by one.
+			s.mtx.Unlock()
+			for _, i := range is {
+				metrics[i] = m.getOrCreateMetricWithLabelValues(hashes[i], lvss[i], curry)
+			}
+			continue
+		}
+		for _, i := range is {
+			metrics[i] = m.getOrCreateMetricWithLabelValuesLocked(s, hashes[i], lvss[i], curry)
+		}
+		m.reshard(s)
+		s.mtx.Unlock()
+	}
+	return metrics
+}
+
+// getOrCreateMetricWithLabelValuesLocked retrieves the metric by hash and
+// label value from the shard s or creates it there and returns the new one.
+// Must be called while holding the mutex of s.
+func (m *metricMap) getOrCreateMetricWithLabelValuesLocked(
+	s *metricMapShard, hash uint64, lvs []string, curry []curriedLabelValue,
+) Metric {
+	metric, ok := s.getMetricWithHashAndLabelValues(hash, lvs, curry)
 	if !ok {
 		inlinedLVs := inlineLabelValues(lvs, curry)
+		var handles []unique.Handle[string]
+		if m.internLabelValues {
+			handles = internLabelValues(inlinedLVs)
+		}
 		metric = m.newMetric(inlinedLVs...)
-		m.metrics[hash] = append(m.metrics[hash], metricWithLabelValues{values: inlinedLVs, metric: metric})
+		s.add(hash, metricWithLabelValues{values: inlinedLVs, metric: metric, handles: handles})
 	}
 	return metric
 }
@@ -510,34 +734,48 @@ func (m *metricMap) getOrCreateMetricWithLabelValues(
 // getOrCreateMetricWithLabels retrieves the metric by hash and label value
 // or creates it and returns the new one.
 //
-// This function holds the mutex.
+// This function holds the mutex of the shard of the hash.
 func (m *metricMap) getOrCreateMetricWithLabels(
 	hash uint64, labels Labels, curry []curriedLabelValue,
 ) Metric {
-	m.mtx.RLock()
-	metric, ok := m.getMetricWithHashAndLabels(hash, labels, curry)
-	m.mtx.RUnlock()
+	s := m.rLockShard(hash)
+	metric, ok := s.getMetricWithHashAndLabels(m.desc, hash, labels, curry)
+	s.mtx.RUnlock()
 	if ok {
 		return metric
 	}
 
-	m.mtx.Lock()
-	defer m.mtx.Unlock()
-	metric, ok = m.getMetricWithHashAndLabels(hash, labels, curry)
+	s = m.lockShard(hash)
+	defer s.mtx.Unlock()
+	metric, ok = s.getMetricWithHashAndLabels(m.desc, hash, labels, curry)
 	if !ok {
 		lvs := extractLabelValues(m.desc, labels, curry)
+		var handles []unique.Handle[string]
+		if m.internLabelValues {
+			handles = internLabelValues(lvs)
+		}
 		metric = m.newMetric(lvs...)
-		m.metrics[hash] = append(m.metrics[hash], metricWithLabelValues{values: lvs, metric: metric})
+		s.add(hash, metricWithLabelValues{values: lvs, metric: metric, handles: handles})
+		m.reshard(s)
 	}
 	return metric
 }
 
+// add adds metric to the hash bucket h, creating the map of the shard if
+// needed. Must be called while holding the mutex.
+func (s *metricMapShard) add(h uint64, metric metricWithLabelValues) {
+	if s.metrics == nil {
+		s.metrics = map[uint64][]metricWithLabelValues{}
+	}
+	s.metrics[h] = append(s.metrics[h], metric)
+}
+
 // getMetricWithHashAndLabelValues gets a metric while handling possible
 // collisions in the hash space. Must be called while holding the read mutex.
-func (m *metricMap) getMetricWithHashAndLabelValues(
+func (s *metricMapShard) getMetricWithHashAndLabelValues(
 	h uint64, lvs []string, curry []curriedLabelValue,
 ) (Metric, bool) {
-	metrics, ok := m.metrics[h]
+	metrics, ok := s.metrics[h]
 	if ok {
 		if i := findMetricWithLabelValues(metrics, lvs, curry); i < len(metrics) {
 			return metrics[i].metric, true
@@ -548,12 +786,12 @@ func (m *metricMap) getMetricWithHashAndLabelValues(
 
 // getMetricWithHashAndLabels gets a metric while handling possible collisions in
 // the hash space. Must be called while holding read mutex.
-func (m *metricMap) getMetricWithHashAndLabels(
-	h uint64, labels Labels, curry []curriedLabelValue,
+func (s *metricMapShard) getMetricWithHashAndLabels(
+	desc *Desc, h uint64, labels Labels, curry []curriedLabelValue,
 ) (Metric, bool) {
-	metrics, ok := m.metrics[h]
+	metrics, ok := s.metrics[h]
 	if ok {
-		if i := findMetricWithLabels(m.desc, metrics, labels, curry); i < len(metrics) {
+		if i := findMetricWithLabels(desc, metrics, labels, curry); i < len(metrics) {
 			return metrics[i].metric, true
 		}
 	}
@@ -641,6 +879,20 @@ func extractLabelValues(desc *Desc, labels Labels, curry []curriedLabelValue) []
 	return labelValues
 }
 
+// internLabelValues replaces the label values in place by their interned
+// copies, so that equal label values of all metrics share their memory. It
+// returns the handles of the interned values, which have to be kept for as
+// long as the values are used, as the unique package may otherwise drop the
+// interned copies and intern equal values anew.
+func internLabelValues(lvs []string) []unique.Handle[string] {
+	handles := make([]unique.Handle[string], len(lvs))
+	for i, lv := range lvs {
+		handles[i] = unique.Make(lv)
+		lvs[i] = handles[i].Value()
+	}
+	return handles
+}
+
 func inlineLabelValues(lvs []string, curry []curriedLabelValue) []string {
 	labelValues := make([]string, len(lvs)+len(curry))
 	var iCurry, iLVs int
@@ -662,32 +914,48 @@ var labelsPool = &sync.Pool{
 	},
 }
 
-func constrainLabels(desc *Desc, labels Labels) (Labels, func()) {
+// constrainLabels returns labels with the constraints of desc applied, or nil
+// if desc has no constraints. The returned Labels are taken from a pool, and
+// the caller has to return them with releaseLabels once done.
+func constrainLabels(desc *Desc, labels Labels) Labels {
 	if len(desc.variableLabels.labelConstraints) == 0 {
 		// Fast path when there's no constraints
-		return labels, func() {}
+		return nil
 	}
 
 	constrainedLabels := labelsPool.Get().(Labels)
 	for l, v := range labels {
 		constrainedLabels[l] = desc.variableLabels.constrain(l, v)
 	}
+	return constrainedLabels
+}
 
-	return constrainedLabels, func() {
-		for k := range constrainedLabels {
-			delete(constrainedLabels, k)
-		}
-		labelsPool.Put(constrainedLabels)
-	}
+// releaseLabels returns Labels obtained from constrainLabels to the pool.
+func releaseLabels(labels Labels) {
+	clear(labels)
+	labelsPool.Put(labels)
 }
 
-func constrainLabelValues(desc *Desc, lvs []string, curry []curriedLabelValue) []string {
+// maxStackLabelValues is the number of label values for which callers of
+// constrainLabelValues provide a buffer on the stack, so that looking up an
+// existing metric doesn't allocate.
+const maxStackLabelValues = 16
+
+// constrainLabelValues returns lvs with the constraints of desc applied. The
+// constrained values are stored in buf if it is large enough. Otherwise, a new
+// slice is allocated.
+func constrainLabelValues(desc *Desc, lvs []string, curry []curriedLabelValue, buf []string) []string {
 	if len(desc.variableLabels.labelConstraints) == 0 {
 		// Fast path when there's no constraints
 		return lvs
 	}
 
-	constrainedValues := make([]string, len(lvs))
+	var constrainedValues []string
+	if len(lvs) <= len(buf) {
+		constrainedValues = buf[:len(lvs)]
+	} else {
+		constrainedValues = make([]string, len(lvs))
+	}
 	var iCurry, iLVs int
 	for i := 0; i < len(lvs)+len(curry); i++ {
 		if iCurry < len(curry) && curry[iCurry].index == i {
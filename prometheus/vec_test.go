@@ -16,8 +16,12 @@ package prometheus
 import (
 	"fmt"
 	"reflect"
+	"runtime"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"unsafe"
 
 	dto "github.com/prometheus/client_model/go"
 )
@@ -183,7 +187,7 @@ func TestDeletePartialMatchWithConstraints(t *testing.T) {
 
 func testDeletePartialMatch(t *testing.T, baseVec *GaugeVec) {
 	assertNoMetric := func(t *testing.T) {
-		if n := len(baseVec.metricMap.metrics); n != 0 {
+		if n := len(metricMapBuckets(baseVec.metricMap)); n != 0 {
 			t.Error("expected no metrics, got", n)
 		}
 	}
@@ -301,7 +305,7 @@ func testMetricVec(t *testing.T, vec *GaugeVec) {
 	}
 
 	var total int
-	for _, metrics := range vec.metricMap.metrics {
+	for _, metrics := range metricMapBuckets(vec.metricMap) {
 		for _, metric := range metrics {
 			total++
 			copy(pair[:], metric.values)
@@ -336,7 +340,7 @@ func testMetricVec(t *testing.T, vec *GaugeVec) {
 
 	vec.Reset()
 
-	if len(vec.metricMap.metrics) > 0 {
+	if len(metricMapBuckets(vec.metricMap)) > 0 {
 		t.Fatalf("reset failed")
 	}
 }
@@ -373,7 +377,7 @@ func testConstrainedMetricVec(t *testing.T, vec *GaugeVec, constrain func(string
 	}
 
 	var total int
-	for _, metrics := range vec.metricMap.metrics {
+	for _, metrics := range metricMapBuckets(vec.metricMap) {
 		for _, metric := range metrics {
 			total++
 			copy(pair[:], metric.values)
@@ -408,7 +412,7 @@ func testConstrainedMetricVec(t *testing.T, vec *GaugeVec, constrain func(string
 
 	vec.Reset()
 
-	if len(vec.metricMap.metrics) > 0 {
+	if len(metricMapBuckets(vec.metricMap)) > 0 {
 		t.Fatalf("reset failed")
 	}
 }
@@ -506,7 +510,7 @@ func TestCurryVecWithConstraints(t *testing.T) {
 func testCurryVec(t *testing.T, vec *CounterVec) {
 	assertMetrics := func(t *testing.T) {
 		n := 0
-		for _, m := range vec.metricMap.metrics {
+		for _, m := range metricMapBuckets(vec.metricMap) {
 			n += len(m)
 		}
 		if n != 2 {
@@ -533,7 +537,7 @@ func testCurryVec(t *testing.T, vec *CounterVec) {
 	}
 
 	assertNoMetric := func(t *testing.T) {
-		if n := len(vec.metricMap.metrics); n != 0 {
+		if n := len(metricMapBuckets(vec.metricMap)); n != 0 {
 			t.Error("expected no metrics, got", n)
 		}
 	}
@@ -703,7 +707,7 @@ func testCurryVec(t *testing.T, vec *CounterVec) {
 func testConstrainedCurryVec(t *testing.T, vec *CounterVec, constraint func(string) string) {
 	assertMetrics := func(t *testing.T) {
 		n := 0
-		for _, m := range vec.metricMap.metrics {
+		for _, m := range metricMapBuckets(vec.metricMap) {
 			n += len(m)
 		}
 		if n != 2 {
@@ -744,7 +748,7 @@ func testConstrainedCurryVec(t *testing.T, vec *CounterVec, constraint func(stri
 	}
 
 	assertNoMetric := func(t *testing.T) {
-		if n := len(vec.metricMap.metrics); n != 0 {
+		if n := len(metricMapBuckets(vec.metricMap)); n != 0 {
 			t.Error("expected no metrics, got", n)
 		}
 	}
@@ -905,6 +909,269 @@ func testConstrainedCurryVec(t *testing.T, vec *CounterVec, constraint func(stri
 	})
 }
 
+func TestMetricVecConcurrency(t *testing.T) {
+	vec := NewCounterVec(CounterOpts{Name: "test", Help: "helpless"}, []string{"l"})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				lv := strconv.Itoa(j % 100)
+				vec.WithLabelValues(lv).Inc()
+				if i == 0 && j%10 == 0 {
+					vec.DeleteLabelValues(lv)
+				}
+				if i == 1 && j%100 == 0 {
+					vec.DeletePartialMatch(Labels{"l": lv})
+				}
+				if i == 2 && j%100 == 0 {
+					ch := make(chan Metric, 100)
+					vec.Collect(ch)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var n int
+	for _, metrics := range metricMapBuckets(vec.metricMap) {
+		n += len(metrics)
+	}
+	ch := make(chan Metric, n)
+	vec.Collect(ch)
+	if len(ch) != n {
+		t.Errorf("collected %d metrics, want %d", len(ch), n)
+	}
+	vec.Reset()
+	if n := len(metricMapBuckets(vec.metricMap)); n != 0 {
+		t.Error("expected no metrics after reset, got", n)
+	}
+}
+
+func TestMetricVecReshard(t *testing.T) {
+	vec := NewCounterVec(CounterOpts{Name: "test", Help: "helpless"}, []string{"l"})
+	for i := 0; i < metricMapShardingThreshold-1; i++ {
+		vec.WithLabelValues(strconv.Itoa(i)).Inc()
+	}
+	if n := len(*vec.metricMap.shards.Load()); n != 1 {
+		t.Fatalf("got %d shards below the sharding threshold, want 1", n)
+	}
+	vec.With(Labels{"l": strconv.Itoa(metricMapShardingThreshold - 1)}).Inc()
+	if n := len(*vec.metricMap.shards.Load()); n != numMetricMapShards {
+		t.Fatalf("got %d shards at the sharding threshold, want %d", n, numMetricMapShards)
+	}
+
+	for i := 0; i < metricMapShardingThreshold; i++ {
+		vec.WithLabelValues(strconv.Itoa(i)).Inc()
+	}
+	ch := make(chan Metric, 2*metricMapShardingThreshold)
+	vec.Collect(ch)
+	close(ch)
+	var n int
+	for m := range ch {
+		var pb dto.Metric
+		if err := m.Write(&pb); err != nil {
+			t.Fatal(err)
+		}
+		if got := pb.GetCounter().GetValue(); got != 2 {
+			t.Errorf("got value %v for %v, want 2", got, pb.GetLabel())
+		}
+		n++
+	}
+	if n != metricMapShardingThreshold {
+		t.Errorf("collected %d metrics, want %d", n, metricMapShardingThreshold)
+	}
+}
+
+func TestMetricVecLookupAllocs(t *testing.T) {
+	if testing.CoverMode() != "" {
+		t.Skip("coverage instrumentation causes allocations")
+	}
+	constraint := func(s string) string { return strings.TrimPrefix(s, "x") }
+	vec := V2.NewCounterVec(CounterVecOpts{
+		CounterOpts: CounterOpts{Name: "test", Help: "helpless"},
+		VariableLabels: ConstrainedLabels{
+			{Name: "one"},
+			{Name: "two", Constraint: constraint},
+			{Name: "three"},
+		},
+	})
+	curried := vec.MustCurryWith(Labels{"one": "1"})
+	unconstrained := NewHistogramVec(HistogramOpts{Name: "test", Help: "helpless"}, []string{"one", "two"})
+
+	for name, lookup := range map[string]func(){
+		"WithLabelValues":             func() { unconstrained.WithLabelValues("1", "2") },
+		"With":                        func() { unconstrained.With(Labels{"one": "1", "two": "2"}) },
+		"WithLabelValues constrained": func() { vec.WithLabelValues("1", "xtwo", "3") },
+		"With constrained":            func() { vec.With(Labels{"one": "1", "two": "xtwo", "three": "3"}) },
+		"WithLabelValues curried":     func() { curried.WithLabelValues("xtwo", "3") },
+		"With curried":                func() { curried.With(Labels{"two": "xtwo", "three": "3"}) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			lookup() // Create the metric.
+			if allocs := testing.AllocsPerRun(100, lookup); allocs != 0 {
+				t.Errorf("looking up an existing metric allocated %g times, want 0", allocs)
+			}
+		})
+	}
+}
+
+func TestMetricVecInternLabelValues(t *testing.T) {
+	newVec := func(name string, intern bool) *CounterVec {
+		return V2.NewCounterVec(CounterVecOpts{
+			CounterOpts:    CounterOpts{Name: name, Help: "helpless", InternLabelValues: intern},
+			VariableLabels: UnconstrainedLabels{"tenant"},
+		})
+	}
+	// tenant returns a new copy of the same label value each time, like a
+	// value parsed from a request.
+	tenant := func() string { return strings.Repeat("acme", 4) }
+	labelValueData := func(vec *CounterVec) *byte {
+		var m dto.Metric
+		if err := vec.WithLabelValues(tenant()).Write(&m); err != nil {
+			t.Fatal(err)
+		}
+		return unsafe.StringData(m.GetLabel()[0].GetValue())
+	}
+
+	interned1, interned2 := newVec("a", true), newVec("b", true)
+	interned1.WithLabelValues(tenant()).Inc()
+	// The interned values must survive garbage collections while in use.
+	runtime.GC()
+	runtime.GC()
+	interned2.With(Labels{"tenant": tenant()}).Inc()
+	if labelValueData(interned1) != labelValueData(interned2) {
+		t.Error("interned label values don't share their memory")
+	}
+	for _, metrics := range metricMapBuckets(interned2.metricMap) {
+		if unsafe.StringData(metrics[0].values[0]) != labelValueData(interned1) {
+			t.Error("interned label values of the metric map don't share their memory")
+		}
+	}
+
+	plain1, plain2 := newVec("c", false), newVec("d", false)
+	plain1.WithLabelValues(tenant()).Inc()
+	plain2.WithLabelValues(tenant()).Inc()
+	if labelValueData(plain1) == labelValueData(plain2) {
+		t.Error("label values share their memory without interning")
+	}
+}
+
+func TestMetricVecWithLabelValuesBatch(t *testing.T) {
+	vec := V2.NewCounterVec(CounterVecOpts{
+		CounterOpts: CounterOpts{Name: "test", Help: "helpless"},
+		VariableLabels: ConstrainedLabels{
+			{Name: "one"},
+			{Name: "two", Constraint: func(s string) string { return strings.TrimPrefix(s, "x") }},
+		},
+	})
+	curried := vec.MustCurryWith(Labels{"one": "1"})
+
+	var lvss, curriedLVSs [][]string
+	for i := 0; i < 100; i++ {
+		lvss = append(lvss, []string{strconv.Itoa(i), "x" + strconv.Itoa(i)})
+		curriedLVSs = append(curriedLVSs, []string{strconv.Itoa(i)})
+	}
+	counters, err := vec.WithLabelValuesBatch(lvss)
+	if err != nil {
+		t.Fatal(err)
+	}
+	curriedCounters, err := curried.WithLabelValuesBatch(curriedLVSs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, lvs := range lvss {
+		if got, want := counters[i], vec.WithLabelValues(lvs...); got != want {
+			t.Errorf("got counter %v for label values %v, want %v", got, lvs, want)
+		}
+		if got, want := curriedCounters[i], vec.WithLabelValues("1", strconv.Itoa(i)); got != want {
+			t.Errorf("got curried counter %v for label value %d, want %v", got, i, want)
+		}
+	}
+	// The counter {one="1",two="1"} is shared between both batches.
+	if got, want := len(metricMapBuckets(vec.metricMap)), 2*len(lvss)-1; got != want {
+		t.Errorf("got %d metrics, want %d", got, want)
+	}
+
+	// An invalid slice of label values fails the whole batch.
+	vec.Reset()
+	if _, err := vec.WithLabelValuesBatch([][]string{{"a", "b"}, {"c"}}); err == nil {
+		t.Error("expected error for inconsistent label cardinality")
+	}
+	if got := len(metricMapBuckets(vec.metricMap)); got != 0 {
+		t.Errorf("got %d metrics after failed batch, want 0", got)
+	}
+}
+
+func TestCurryVecHash(t *testing.T) {
+	vec := NewCounterVec(CounterOpts{Name: "test", Help: "helpless"}, []string{"one", "two", "three"})
+	want, err := vec.hashLabelValues([]string{"1", "2", "3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		curry      Labels
+		lvs        []string
+		labels     Labels
+		wantPrefix int
+	}{
+		{Labels{"one": "1"}, []string{"2", "3"}, Labels{"two": "2", "three": "3"}, 1},
+		{Labels{"one": "1", "two": "2"}, []string{"3"}, Labels{"three": "3"}, 2},
+		{Labels{"one": "1", "three": "3"}, []string{"2"}, Labels{"two": "2"}, 1},
+		{Labels{"two": "2"}, []string{"1", "3"}, Labels{"one": "1", "three": "3"}, 0},
+		{Labels{"one": "1", "two": "2", "three": "3"}, nil, Labels{}, 3},
+	} {
+		curried := vec.MustCurryWith(tc.curry)
+		if curried.curriedPrefix != tc.wantPrefix {
+			t.Errorf("curried with %v: got curried prefix %d, want %d", tc.curry, curried.curriedPrefix, tc.wantPrefix)
+		}
+		if got, err := curried.hashLabelValues(tc.lvs); err != nil || got != want {
+			t.Errorf("curried with %v: got hash %d (error %v) for label values, want %d", tc.curry, got, err, want)
+		}
+		if got, err := curried.hashLabels(tc.labels); err != nil || got != want {
+			t.Errorf("curried with %v: got hash %d (error %v) for labels, want %d", tc.curry, got, err, want)
+		}
+	}
+
+	// Currying a curried vector extends the prefix.
+	curried := vec.MustCurryWith(Labels{"two": "2"}).MustCurryWith(Labels{"one": "1"})
+	if curried.curriedPrefix != 2 {
+		t.Errorf("got curried prefix %d, want 2", curried.curriedPrefix)
+	}
+	if got, err := curried.hashLabelValues([]string{"3"}); err != nil || got != want {
+		t.Errorf("got hash %d (error %v), want %d", got, err, want)
+	}
+}
+
+// metricMapBuckets returns the hash buckets of all shards of m.
+func metricMapBuckets(m *metricMap) map[uint64][]metricWithLabelValues {
+	buckets := map[uint64][]metricWithLabelValues{}
+	m.rangeShards(false, func(s *metricMapShard) {
+		for h, metrics := range s.metrics {
+			buckets[h] = metrics
+		}
+	})
+	return buckets
+}
+
+func BenchmarkMetricVecWithLabelValuesParallel(b *testing.B) {
+	vec := NewGaugeVec(GaugeOpts{Name: "test", Help: "helpless"}, []string{"l"})
+	values := make([]string, 100)
+	for i := range values {
+		values[i] = fmt.Sprintf("value-%v", i)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			vec.WithLabelValues(values[i%len(values)]).Inc()
+		}
+	})
+}
+
 func BenchmarkMetricVecWithBasic(b *testing.B) {
 	benchmarkMetricVecWith(b, Labels{
 		"l1": "onevalue",
@@ -1004,3 +1271,48 @@ func benchmarkMetricVecWithLabelValues(b *testing.B, labels map[string][]string)
 		vec.WithLabelValues(values...)
 	}
 }
+
+func BenchmarkMetricVecWithLabelValuesBatch(b *testing.B) {
+	lvss := make([][]string, 10000)
+	for i := range lvss {
+		lvss[i] = []string{strconv.Itoa(i % 100), strconv.Itoa(i / 100)}
+	}
+
+	b.Run("WithLabelValues", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			vec := NewCounterVec(CounterOpts{Name: "test", Help: "helpless"}, []string{"one", "two"})
+			for _, lvs := range lvss {
+				vec.WithLabelValues(lvs...)
+			}
+		}
+	})
+	b.Run("WithLabelValuesBatch", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			vec := NewCounterVec(CounterOpts{Name: "test", Help: "helpless"}, []string{"one", "two"})
+			if _, err := vec.WithLabelValuesBatch(lvss); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkMetricVecCurriedWithLabelValues(b *testing.B) {
+	vec := NewCounterVec(CounterOpts{Name: "test", Help: "helpless"}, []string{"handler", "code", "method"})
+	curried := vec.MustCurryWith(Labels{"handler": "/api/v1/query_range"})
+	vec.WithLabelValues("/api/v1/query_range", "200", "GET")
+
+	b.Run("Direct", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			vec.WithLabelValues("/api/v1/query_range", "200", "GET")
+		}
+	})
+	b.Run("Curried", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			curried.WithLabelValues("200", "GET")
+		}
+	})
+}
@@ -0,0 +1,478 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/model"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ProtoGatherer is a Gatherer that can also encode the gathered MetricFamilies
+// directly in the length-delimited protobuf exposition format, without
+// creating the MetricFamily and (for most metrics) the Metric protobufs first.
+// The Registry implements ProtoGatherer.
+type ProtoGatherer interface {
+	Gatherer
+	// AppendProtoDelimited gathers like Gather and appends the result to
+	// b, encoded like by an expfmt.Encoder for the delimited protobuf
+	// format with the provided escaping scheme. It returns the extended
+	// buffer and the errors encountered while gathering, which are
+	// handled as by Gather, i.e. as many MetricFamilies as possible are
+	// appended.
+	AppendProtoDelimited(b []byte, scheme model.EscapingScheme) ([]byte, error)
+}
+
+// protoMetric is implemented by Metrics that can encode their value directly
+// in the protobuf wire format instead of writing a dto.Metric, see
+// Registry.AppendProtoDelimited. As the label pairs of a protoMetric are not
+// checked when gathering, they have to be sorted, valid, and consistent with
+// the Desc of the Metric, as they are by MakeLabelPairs.
+type protoMetric interface {
+	Metric
+	// appendProto appends the value field of the dto.Metric that Write
+	// would write (e.g. the Counter field) to b in the protobuf wire
+	// format and returns the type and the label pairs of the Metric. If ok
+	// is false, the Metric has to be written with Write instead, and b is
+	// returned unchanged.
+	appendProto(b []byte) (_ []byte, t dto.MetricType, labelPairs []*dto.LabelPair, ok bool)
+}
+
+// Field numbers of the dto messages, see metrics.proto in client_model.
+const (
+	familyNameField   protowire.Number = 1
+	familyHelpField   protowire.Number = 2
+	familyTypeField   protowire.Number = 3
+	familyMetricField protowire.Number = 4
+
+	metricLabelField     protowire.Number = 1
+	metricGaugeField     protowire.Number = 2
+	metricCounterField   protowire.Number = 3
+	metricSummaryField   protowire.Number = 4
+	metricUntypedField   protowire.Number = 5
+	metricTimestampField protowire.Number = 6
+	metricHistogramField protowire.Number = 7
+
+	labelNameField  protowire.Number = 1
+	labelValueField protowire.Number = 2
+
+	// The value field of the Counter, Gauge, and Untyped messages.
+	valueField                   protowire.Number = 1
+	counterCreatedTimestampField protowire.Number = 3
+
+	timestampSecondsField protowire.Number = 1
+	timestampNanosField   protowire.Number = 2
+)
+
+// AppendProtoDelimited implements ProtoGatherer. Counters, Gauges, and the
+// metrics created by NewCounterFunc, NewGaugeFunc, and NewUntypedFunc encode
+// their values directly, while all other metrics are written to a dto.Metric
+// first, which is then encoded field by field. The gathered metrics are checked
+// for consistency like by Gather and reported with the same errors.
+func (r *Registry) AppendProtoDelimited(b []byte, scheme model.EscapingScheme) ([]byte, error) {
+	p := r.frozen.Load()
+	if p == nil {
+		p = r.plan(false)
+	}
+	if len(p.checked) == 0 && len(p.unchecked) == 0 {
+		// Fast path.
+		return b, nil
+	}
+
+	g := protoGatherPool.Get().(*protoGather)
+	defer g.reset()
+	g.registeredDescIDs = p.registeredDescIDs
+	if !p.skipConsistencyChecks {
+		g.metricHashes = g.hashes
+	}
+	errs := collect(p.checked, p.unchecked, g.process)
+	return g.appendTo(b, scheme), errs.MaybeUnwrap()
+}
+
+var protoGatherPool = sync.Pool{
+	New: func() interface{} {
+		return &protoGather{
+			metricFamiliesByName: map[string]*dto.MetricFamily{},
+			families:             map[*dto.MetricFamily]*protoFamily{},
+			hashes:               map[uint64]struct{}{},
+		}
+	},
+}
+
+// protoGather holds the state of an AppendProtoDelimited call. It is pooled
+// to reuse the memory of its buffers and maps.
+type protoGather struct {
+	// buf holds the encoded fields of all gathered metrics but their
+	// labels.
+	buf []byte
+	// metrics holds the gathered metrics in the order of collection, and
+	// sorted holds them ordered by family.
+	metrics, sorted []protoEncodedMetric
+	// metricFamiliesByName holds MetricFamilies without their metrics
+	// for the consistency checks, see processMetric.
+	metricFamiliesByName map[string]*dto.MetricFamily
+	families             map[*dto.MetricFamily]*protoFamily
+	// metricHashes is hashes if the consistency is checked, and nil
+	// otherwise.
+	metricHashes, hashes map[uint64]struct{}
+	registeredDescIDs    map[uint64]struct{}
+	// sizes holds the sizes of the Metric messages of the family being
+	// encoded.
+	sizes []int
+}
+
+// protoFamily is a MetricFamily gathered by AppendProtoDelimited.
+type protoFamily struct {
+	mf *dto.MetricFamily
+	// n is the number of metrics of the family, and start the index of
+	// its first metric in protoGather.sorted.
+	n, start int
+}
+
+// protoEncodedMetric is a gathered Metric whose fields but the labels are
+// encoded in protoGather.buf.
+type protoEncodedMetric struct {
+	family      *protoFamily
+	labelPairs  []*dto.LabelPair
+	timestampMs *int64
+	start, end  int
+}
+
+// reset resets g and returns it to protoGatherPool.
+func (g *protoGather) reset() {
+	g.buf = g.buf[:0]
+	clear(g.metrics)
+	g.metrics = g.metrics[:0]
+	clear(g.sorted)
+	g.sorted = g.sorted[:0]
+	clear(g.metricFamiliesByName)
+	clear(g.families)
+	clear(g.hashes)
+	g.metricHashes = nil
+	g.registeredDescIDs = nil
+	g.sizes = g.sizes[:0]
+	protoGatherPool.Put(g)
+}
+
+// process adds a collected metric, see collect.
+func (g *protoGather) process(metric Metric, checked bool) error {
+	var registeredDescIDs map[uint64]struct{}
+	if checked {
+		registeredDescIDs = g.registeredDescIDs
+	}
+	if pm, ok := metric.(protoMetric); ok && g.processProtoMetric(pm, registeredDescIDs) {
+		return nil
+	}
+
+	// Process all other metrics like Gather does, which also reports the
+	// errors of protoMetrics that failed a check.
+	if err := processMetric(metric, g.metricFamiliesByName, g.metricHashes, registeredDescIDs, nil); err != nil {
+		return err
+	}
+	desc := metric.Desc()
+	mf := g.metricFamiliesByName[desc.fqName]
+	m := mf.Metric[len(mf.Metric)-1]
+	mf.Metric = mf.Metric[:len(mf.Metric)-1]
+	start := len(g.buf)
+	b, err := appendMetricProto(g.buf, m)
+	if err != nil {
+		g.buf = g.buf[:start]
+		return &MetricError{FQName: desc.fqName, Err: fmt.Errorf("error encoding metric %v: %w", desc, err)}
+	}
+	g.buf = b
+	g.add(mf, protoEncodedMetric{labelPairs: m.Label, timestampMs: m.TimestampMs, start: start, end: len(g.buf)})
+	return nil
+}
+
+// processProtoMetric encodes pm and performs the checks of processMetric that
+// can fail for a protoMetric. If any of them fails, it returns false and
+// leaves pm to be processed by processMetric.
+func (g *protoGather) processProtoMetric(pm protoMetric, registeredDescIDs map[uint64]struct{}) bool {
+	desc := pm.Desc()
+	if desc.err != nil {
+		return false
+	}
+	if registeredDescIDs != nil {
+		if _, exist := registeredDescIDs[desc.id]; !exist {
+			return false
+		}
+	}
+	start := len(g.buf)
+	b, t, labelPairs, ok := pm.appendProto(g.buf)
+	if !ok {
+		return false
+	}
+	g.buf = b
+
+	mf, exists := g.metricFamiliesByName[desc.fqName]
+	switch {
+	case exists && (mf.GetHelp() != desc.help || mf.GetType() != t):
+		g.buf = g.buf[:start]
+		return false
+	case !exists:
+		mf = &dto.MetricFamily{
+			Name: proto.String(desc.fqName),
+			Help: proto.String(desc.help),
+			Type: t.Enum(),
+		}
+		if g.metricHashes != nil && checkSuffixCollisions(mf, g.metricFamiliesByName) != nil {
+			g.buf = g.buf[:start]
+			return false
+		}
+	}
+	if g.metricHashes != nil {
+		h := hashMetric(desc.fqName, labelPairs, nil)
+		if _, dup := g.metricHashes[h]; dup {
+			g.buf = g.buf[:start]
+			return false
+		}
+		g.metricHashes[h] = struct{}{}
+	}
+	if !exists {
+		g.metricFamiliesByName[desc.fqName] = mf
+	}
+	g.add(mf, protoEncodedMetric{labelPairs: labelPairs, start: start, end: len(g.buf)})
+	return true
+}
+
+func (g *protoGather) add(mf *dto.MetricFamily, m protoEncodedMetric) {
+	f, ok := g.families[mf]
+	if !ok {
+		f = &protoFamily{mf: mf}
+		g.families[mf] = f
+	}
+	f.n++
+	m.family = f
+	g.metrics = append(g.metrics, m)
+}
+
+// appendTo appends the gathered MetricFamilies to b, each as a varint length
+// followed by the MetricFamily message, sorted like by
+// internal.NormalizeMetricFamilies.
+func (g *protoGather) appendTo(b []byte, scheme model.EscapingScheme) []byte {
+	families := make([]*protoFamily, 0, len(g.families))
+	for _, f := range g.families {
+		families = append(families, f)
+	}
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].mf.GetName() < families[j].mf.GetName()
+	})
+	// Order the metrics by family.
+	start := 0
+	for _, f := range families {
+		f.start = start
+		start += f.n
+		f.n = 0
+	}
+	g.sorted = append(g.sorted[:0], g.metrics...)
+	for _, m := range g.metrics {
+		g.sorted[m.family.start+m.family.n] = m
+		m.family.n++
+	}
+
+	for _, f := range families {
+		metrics := g.sorted[f.start : f.start+f.n]
+		sort.Sort(protoEncodedMetricSorter(metrics))
+
+		var (
+			name = escapeName(f.mf.GetName(), scheme)
+			help = f.mf.GetHelp()
+			t    = uint64(f.mf.GetType())
+			size = protowire.SizeTag(familyNameField) + protowire.SizeBytes(len(name)) +
+				protowire.SizeTag(familyHelpField) + protowire.SizeBytes(len(help)) +
+				protowire.SizeTag(familyTypeField) + protowire.SizeVarint(t)
+		)
+		g.sizes = g.sizes[:0]
+		for _, m := range metrics {
+			metricSize := m.end - m.start
+			for _, lp := range m.labelPairs {
+				metricSize += protowire.SizeTag(metricLabelField) + protowire.SizeBytes(labelPairSize(lp, scheme))
+			}
+			g.sizes = append(g.sizes, metricSize)
+			size += protowire.SizeTag(familyMetricField) + protowire.SizeBytes(metricSize)
+		}
+
+		b = protowire.AppendVarint(b, uint64(size))
+		b = protowire.AppendTag(b, familyNameField, protowire.BytesType)
+		b = protowire.AppendString(b, name)
+		b = protowire.AppendTag(b, familyHelpField, protowire.BytesType)
+		b = protowire.AppendString(b, help)
+		b = protowire.AppendTag(b, familyTypeField, protowire.VarintType)
+		b = protowire.AppendVarint(b, t)
+		for i, m := range metrics {
+			b = protowire.AppendTag(b, familyMetricField, protowire.BytesType)
+			b = protowire.AppendVarint(b, uint64(g.sizes[i]))
+			for _, lp := range m.labelPairs {
+				name, value := escapeLabelPair(lp, scheme)
+				b = protowire.AppendTag(b, metricLabelField, protowire.BytesType)
+				b = protowire.AppendVarint(b, uint64(labelPairSize(lp, scheme)))
+				b = protowire.AppendTag(b, labelNameField, protowire.BytesType)
+				b = protowire.AppendString(b, name)
+				b = protowire.AppendTag(b, labelValueField, protowire.BytesType)
+				b = protowire.AppendString(b, value)
+			}
+			b = append(b, g.buf[m.start:m.end]...)
+		}
+	}
+	return b
+}
+
+// protoEncodedMetricSorter sorts metrics like internal.MetricSorter.
+type protoEncodedMetricSorter []protoEncodedMetric
+
+func (s protoEncodedMetricSorter) Len() int {
+	return len(s)
+}
+
+func (s protoEncodedMetricSorter) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+func (s protoEncodedMetricSorter) Less(i, j int) bool {
+	if len(s[i].labelPairs) != len(s[j].labelPairs) {
+		return len(s[i].labelPairs) < len(s[j].labelPairs)
+	}
+	for n, lp := range s[i].labelPairs {
+		vi := lp.GetValue()
+		vj := s[j].labelPairs[n].GetValue()
+		if vi != vj {
+			return vi < vj
+		}
+	}
+	if s[i].timestampMs == nil {
+		return false
+	}
+	if s[j].timestampMs == nil {
+		return true
+	}
+	return *s[i].timestampMs < *s[j].timestampMs
+}
+
+// escapeName escapes a metric name like model.EscapeMetricFamily.
+func escapeName(name string, scheme model.EscapingScheme) string {
+	if scheme == model.NoEscaping || model.IsValidLegacyMetricName(name) {
+		return name
+	}
+	return model.EscapeName(name, scheme)
+}
+
+// escapeLabelPair returns the name and value of a label pair escaped like by
+// model.EscapeMetricFamily.
+func escapeLabelPair(lp *dto.LabelPair, scheme model.EscapingScheme) (name, value string) {
+	if lp.GetName() == model.MetricNameLabel {
+		return lp.GetName(), escapeName(lp.GetValue(), scheme)
+	}
+	return escapeName(lp.GetName(), scheme), lp.GetValue()
+}
+
+// labelPairSize returns the size of the encoded LabelPair message of lp.
+func labelPairSize(lp *dto.LabelPair, scheme model.EscapingScheme) int {
+	name, value := escapeLabelPair(lp, scheme)
+	return protowire.SizeTag(labelNameField) + protowire.SizeBytes(len(name)) +
+		protowire.SizeTag(labelValueField) + protowire.SizeBytes(len(value))
+}
+
+// appendValueProto appends the Counter, Gauge, or Untyped field of a
+// dto.Metric as set by populateMetric (without an exemplar) to b.
+func appendValueProto(b []byte, t ValueType, v float64, ct *timestamppb.Timestamp) (_ []byte, _ dto.MetricType, ok bool) {
+	var (
+		field protowire.Number
+		mt    dto.MetricType
+	)
+	switch t {
+	case CounterValue:
+		field, mt = metricCounterField, dto.MetricType_COUNTER
+	case GaugeValue:
+		field, mt = metricGaugeField, dto.MetricType_GAUGE
+		ct = nil
+	case UntypedValue:
+		field, mt = metricUntypedField, dto.MetricType_UNTYPED
+		ct = nil
+	default:
+		// Left to Write to report.
+		return b, mt, false
+	}
+
+	size := protowire.SizeTag(valueField) + protowire.SizeFixed64()
+	var ctSize int
+	if ct != nil {
+		if s := ct.GetSeconds(); s != 0 {
+			ctSize += protowire.SizeTag(timestampSecondsField) + protowire.SizeVarint(uint64(s))
+		}
+		if n := ct.GetNanos(); n != 0 {
+			ctSize += protowire.SizeTag(timestampNanosField) + protowire.SizeVarint(uint64(n))
+		}
+		size += protowire.SizeTag(counterCreatedTimestampField) + protowire.SizeBytes(ctSize)
+	}
+
+	b = protowire.AppendTag(b, field, protowire.BytesType)
+	b = protowire.AppendVarint(b, uint64(size))
+	b = protowire.AppendTag(b, valueField, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, math.Float64bits(v))
+	if ct != nil {
+		b = protowire.AppendTag(b, counterCreatedTimestampField, protowire.BytesType)
+		b = protowire.AppendVarint(b, uint64(ctSize))
+		if s := ct.GetSeconds(); s != 0 {
+			b = protowire.AppendTag(b, timestampSecondsField, protowire.VarintType)
+			b = protowire.AppendVarint(b, uint64(s))
+		}
+		if n := ct.GetNanos(); n != 0 {
+			b = protowire.AppendTag(b, timestampNanosField, protowire.VarintType)
+			b = protowire.AppendVarint(b, uint64(n))
+		}
+	}
+	return b, mt, true
+}
+
+// appendMetricProto appends the fields of m but its labels to b, in the order
+// proto.Marshal encodes them.
+func appendMetricProto(b []byte, m *dto.Metric) ([]byte, error) {
+	var err error
+	appendMessage := func(field protowire.Number, msg proto.Message) {
+		if err != nil {
+			return
+		}
+		opts := proto.MarshalOptions{UseCachedSize: true}
+		b = protowire.AppendTag(b, field, protowire.BytesType)
+		b = protowire.AppendVarint(b, uint64(opts.Size(msg)))
+		b, err = opts.MarshalAppend(b, msg)
+	}
+	if m.Gauge != nil {
+		appendMessage(metricGaugeField, m.Gauge)
+	}
+	if m.Counter != nil {
+		appendMessage(metricCounterField, m.Counter)
+	}
+	if m.Summary != nil {
+		appendMessage(metricSummaryField, m.Summary)
+	}
+	if m.Untyped != nil {
+		appendMessage(metricUntypedField, m.Untyped)
+	}
+	if m.TimestampMs != nil {
+		b = protowire.AppendTag(b, metricTimestampField, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(*m.TimestampMs))
+	}
+	if m.Histogram != nil {
+		appendMessage(metricHistogramField, m.Histogram)
+	}
+	return b, err
+}
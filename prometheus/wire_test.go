@@ -0,0 +1,205 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+)
+
+// encodeProtoDelimited gathers from reg and encodes the result like
+// AppendProtoDelimited should.
+func encodeProtoDelimited(t *testing.T, reg *Registry, scheme model.EscapingScheme) ([]byte, error) {
+	t.Helper()
+	mfs, gatherErr := reg.Gather()
+	var buf bytes.Buffer
+	enc := expfmt.NewEncoder(&buf, expfmt.NewFormat(expfmt.TypeProtoDelim).WithEscapingScheme(scheme))
+	for _, mf := range mfs {
+		if err := enc.Encode(mf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes(), gatherErr
+}
+
+func TestRegistryAppendProtoDelimited(t *testing.T) {
+	now := time.Unix(1700000000, 123456789)
+	reg := NewPedanticRegistry()
+
+	counter := NewCounter(CounterOpts{Name: "counter_total", Help: "A counter.", now: func() time.Time { return now }})
+	counter.Add(42)
+	exemplarCounter := NewCounter(CounterOpts{Name: "exemplar_total", Help: "A counter with exemplar.", now: func() time.Time { return now }})
+	exemplarCounter.(ExemplarAdder).AddWithExemplar(1, Labels{"trace_id": "abc"})
+	intCounters := NewIntCounterVec(CounterOpts{Name: "int_total", Help: "Int counters.", ConstLabels: Labels{"const": "x"}}, []string{"code"})
+	for _, code := range []string{"500", "200", "404"} {
+		intCounters.WithLabelValues(code).Add(7)
+	}
+	gauges := NewGaugeVec(GaugeOpts{Name: "gauge", Help: "Gauges."}, []string{"b", "a"})
+	gauges.WithLabelValues("2", "1").Set(-1.5)
+	gauges.WithLabelValues("1", "2").Set(0)
+	// A UTF-8 name, escaped depending on the scheme.
+	utf8Gauge := NewGauge(GaugeOpts{Name: "utf8.gauge", Help: "", ConstLabels: Labels{"label.name": "v"}})
+	histogram := NewHistogram(HistogramOpts{
+		Name: "histogram_seconds", Help: "A histogram.", Buckets: []float64{1, 2},
+		NativeHistogramBucketFactor: 1.1, now: func() time.Time { return now },
+	})
+	histogram.Observe(1.5)
+	summary := NewSummary(SummaryOpts{Name: "summary_seconds", Help: "A summary.", Objectives: map[float64]float64{0.5: 0.05}})
+	summary.Observe(3)
+
+	reg.MustRegister(
+		counter, exemplarCounter, intCounters, gauges, utf8Gauge, histogram, summary,
+		NewGaugeFunc(GaugeOpts{Name: "gauge_func", Help: "A gauge func."}, func() float64 { return 1 }),
+		NewCounterFunc(CounterOpts{Name: "counter_func_total", Help: "A counter func."}, func() float64 { return 2 }),
+		NewUntypedFunc(UntypedOpts{Name: "untyped_func", Help: "An untyped func."}, func() float64 { return 3 }),
+		uncheckedCollector{c: collectorFunc(func(ch chan<- Metric) {
+			desc := NewDesc("const", "Const metrics.", []string{"l"}, nil)
+			for i := 0; i < 3; i++ {
+				m := MustNewConstMetric(desc, GaugeValue, float64(i), strconv.Itoa(2-i))
+				ch <- NewMetricWithTimestamp(now, m)
+			}
+		})},
+		uncheckedCollector{c: collectorFunc(func(ch chan<- Metric) {
+			desc := NewDesc("template", "Template metrics.", []string{"b", "a"}, Labels{"c": "x"})
+			template := MustNewConstMetricTemplate(desc, CounterValue)
+			for i := 0; i < 3; i++ {
+				ch <- template.MustNew(float64(i), strconv.Itoa(2-i), strconv.Itoa(i))
+			}
+		})},
+	)
+
+	for _, scheme := range []model.EscapingScheme{model.NoEscaping, model.UnderscoreEscaping, model.DotsEscaping, model.ValueEncodingEscaping} {
+		t.Run(scheme.String(), func(t *testing.T) {
+			want, err := encodeProtoDelimited(t, reg, scheme)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := reg.AppendProtoDelimited([]byte("prefix"), scheme)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, append([]byte("prefix"), want...)) {
+				t.Errorf("got\n%q\nwant\n%q", got, want)
+			}
+		})
+	}
+}
+
+func TestRegistryAppendProtoDelimitedErrors(t *testing.T) {
+	for _, s := range []struct {
+		name       string
+		collectors []Collector
+	}{
+		{
+			name: "duplicate",
+			collectors: []Collector{
+				uncheckedCollector{c: NewGauge(GaugeOpts{Name: "a", Help: "A."})},
+				uncheckedCollector{c: NewGauge(GaugeOpts{Name: "a", Help: "A."})},
+				NewCounter(CounterOpts{Name: "b_total", Help: "B."}),
+			},
+		},
+		{
+			name: "inconsistent help",
+			collectors: []Collector{
+				uncheckedCollector{c: NewGauge(GaugeOpts{Name: "a", Help: "A.", ConstLabels: Labels{"l": "1"}})},
+				uncheckedCollector{c: NewGauge(GaugeOpts{Name: "a", Help: "Other A.", ConstLabels: Labels{"l": "2"}})},
+			},
+		},
+		{
+			name: "inconsistent type",
+			collectors: []Collector{
+				uncheckedCollector{c: NewGauge(GaugeOpts{Name: "a", Help: "A.", ConstLabels: Labels{"l": "1"}})},
+				uncheckedCollector{c: NewCounter(CounterOpts{Name: "a", Help: "A.", ConstLabels: Labels{"l": "2"}})},
+			},
+		},
+		{
+			name: "suffix collision",
+			collectors: []Collector{
+				uncheckedCollector{c: NewHistogram(HistogramOpts{Name: "a", Help: "A."})},
+				uncheckedCollector{c: NewGauge(GaugeOpts{Name: "a_count", Help: "A."})},
+			},
+		},
+	} {
+		t.Run(s.name, func(t *testing.T) {
+			reg := NewRegistry()
+			reg.MustRegister(s.collectors...)
+			want, wantErr := encodeProtoDelimited(t, reg, model.NoEscaping)
+			if wantErr == nil {
+				t.Fatal("expected error from Gather")
+			}
+			got, err := reg.AppendProtoDelimited(nil, model.NoEscaping)
+			if err == nil || err.Error() != wantErr.Error() {
+				t.Errorf("got error %v, want %v", err, wantErr)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("got\n%q\nwant\n%q", got, want)
+			}
+		})
+	}
+}
+
+// collectorFunc is a Collector without descriptors collecting by calling
+// itself.
+type collectorFunc func(chan<- Metric)
+
+func (f collectorFunc) Describe(chan<- *Desc) {}
+
+func (f collectorFunc) Collect(ch chan<- Metric) {
+	f(ch)
+}
+
+func BenchmarkRegistryAppendProtoDelimited(b *testing.B) {
+	reg := NewRegistry()
+	for i := 0; i < 100; i++ {
+		vec := NewCounterVec(CounterOpts{Name: "requests_total_" + strconv.Itoa(i), Help: "Requests."}, []string{"id"})
+		for j := 0; j < 100; j++ {
+			vec.WithLabelValues(strconv.Itoa(j)).Inc()
+		}
+		reg.MustRegister(vec)
+	}
+	format := expfmt.NewFormat(expfmt.TypeProtoDelim)
+
+	b.Run("Gather", func(b *testing.B) {
+		var buf bytes.Buffer
+		b.ReportAllocs()
+		for b.Loop() {
+			buf.Reset()
+			mfs, err := reg.Gather()
+			if err != nil {
+				b.Fatal(err)
+			}
+			enc := expfmt.NewEncoder(&buf, format)
+			for _, mf := range mfs {
+				if err := enc.Encode(mf); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("AppendProtoDelimited", func(b *testing.B) {
+		var buf []byte
+		b.ReportAllocs()
+		for b.Loop() {
+			var err error
+			if buf, err = reg.AppendProtoDelimited(buf[:0], format.ToEscapingScheme()); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}